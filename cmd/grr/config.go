@@ -3,17 +3,23 @@ package main
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/go-clix/cli"
 	"github.com/grafana/grizzly/pkg/config"
 	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
 	"github.com/spf13/viper"
 )
 
 // LoggingOpts contains logging options (used in all commands)
 type LoggingOpts struct {
-	LogLevel string
+	LogLevel    string
+	NoColor     bool
+	NoPager     bool
+	DumpHTTP    string
+	LowPriority bool
 }
 
 // Opts contains options for most Grizzly commands
@@ -21,23 +27,47 @@ type Opts struct {
 	LoggingOpts
 	Directory    bool // Deprecated: now is gathered with os.Stat(<resource-path>)
 	JsonnetPaths []string
+	// Used for passing external variables and top-level arguments to Jsonnet, as name=value
+	ExtStr       []string
+	ExtCode      []string
+	TLAStr       []string
+	TLACode      []string
+	JsonnetVars  grizzly.JsonnetVars
 	Targets      []string
 	OutputFormat string
 	DisableStats bool
+	EventsFormat string
 	IsDir        bool // used internally to denote that the resource path argument pointed at a directory
 
+	// Used for selecting an environment, or a context
+	Environment       string
+	EnvironmentConfig *config.Environment
+	Context           string
+
 	// Used for supporting resources without envelopes
 	OnlySpec     bool
 	HasOnlySpec  bool
 	FolderUID    string
 	ResourceKind string
 
+	// Used for selecting resources by label
+	Selector []string
+
+	// Used for stamping resources with the git commit they are applied from
+	GitProvenance    bool
+	HasGitProvenance bool
+
 	// Used for supporting the proxy server
 	OpenBrowser bool
 	ProxyPort   int
 	CanSave     bool
 	Watch       bool
 	WatchScript string
+	WatchRemote time.Duration
+
+	// Used for previewing resources offline
+	MockDatasources   string
+	RecordDatasources bool
 }
 
 func configPathCmd() *cli.Command {
@@ -49,7 +79,7 @@ func configPathCmd() *cli.Command {
 	var opts LoggingOpts
 
 	cmd.Run = func(cmd *cli.Command, args []string) error {
-		fmt.Println(viper.ConfigFileUsed())
+		notifier.Print(viper.ConfigFileUsed())
 		return nil
 	}
 	return initialiseLogging(cmd, &opts)
@@ -81,7 +111,7 @@ func currentContextCmd() *cli.Command {
 		if err != nil {
 			return err
 		}
-		fmt.Println(conf.Name)
+		notifier.Print(conf.Name)
 		return nil
 	}
 	return initialiseLogging(cmd, &opts)
@@ -122,10 +152,36 @@ func getContextsCmd() *cli.Command {
 
 		for _, context := range contexts {
 			if context == currentContext.Name {
-				fmt.Printf("* %s\n", context)
+				notifier.Printf("* %s\n", context)
 			} else {
-				fmt.Printf("  %s\n", context)
+				notifier.Printf("  %s\n", context)
+			}
+		}
+		return nil
+	}
+	return initialiseLogging(cmd, &opts)
+}
+
+func getEnvironmentsCmd() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "get-environments",
+		Short: "list configured environments, with their context",
+		Args:  cli.ArgsNone(),
+	}
+	var opts LoggingOpts
+
+	cmd.Run = func(cmd *cli.Command, args []string) error {
+		environments, err := config.GetEnvironments()
+		if err != nil {
+			return err
+		}
+
+		for _, name := range environments {
+			environment, err := config.GetEnvironment(name)
+			if err != nil {
+				return err
 			}
+			notifier.Printf("%s\t%s\n", name, environment.Context)
 		}
 		return nil
 	}
@@ -151,7 +207,7 @@ func getConfigCmd() *cli.Command {
 		if err != nil {
 			return err
 		}
-		fmt.Println(val)
+		notifier.Print(val)
 		return nil
 	}
 	return initialiseLogging(cmd, &opts)
@@ -204,6 +260,9 @@ func checkCmd(registry grizzly.Registry) *cli.Command {
 		Short: "Create a configuration context",
 	}
 	var opts LoggingOpts
+	// the context of the environment is selected in main(), before the registry is created
+	cmd.Flags().String(environmentFlag, "", "environment whose context is checked")
+	cmd.Flags().String(contextFlag, "", "context checked instead of the current one")
 
 	cmd.Run = func(cmd *cli.Command, args []string) error {
 		red := color.New(color.FgRed).SprintfFunc()
@@ -215,12 +274,12 @@ func checkCmd(registry grizzly.Registry) *cli.Command {
 			return err
 		}
 
-		fmt.Printf("Configuration file: %s\n", green(viper.ConfigFileUsed()))
-		fmt.Printf("Current context: %s\n\n", green(gCtx.Name))
+		notifier.Printf("Configuration file: %s\n", green(viper.ConfigFileUsed()))
+		notifier.Printf("Current context: %s\n\n", green(gCtx.Name))
 
 		for i, provider := range registry.Providers {
-			fmt.Println(yellow(provider.Name()))
-			fmt.Println(yellow(strings.Repeat("=", len(provider.Name()))))
+			notifier.Print(yellow(provider.Name()))
+			notifier.Print(yellow(strings.Repeat("=", len(provider.Name()))))
 
 			status := provider.Status()
 
@@ -228,7 +287,7 @@ func checkCmd(registry grizzly.Registry) *cli.Command {
 			if !status.Active {
 				activeMsg = fmt.Sprintf("%s - %s", red("false"), status.ActiveReason)
 			}
-			fmt.Printf("Active: %s\n", activeMsg)
+			notifier.Printf("Active: %s\n", activeMsg)
 
 			onlineMsg := green("true")
 			if !status.Active || !status.Online {
@@ -237,10 +296,10 @@ func checkCmd(registry grizzly.Registry) *cli.Command {
 			if status.Active && !status.Online {
 				onlineMsg = fmt.Sprintf("%s - %s", red("false"), status.OnlineReason)
 			}
-			fmt.Printf("Online: %s\n", onlineMsg)
+			notifier.Printf("Online: %s\n", onlineMsg)
 
 			if i != len(registry.Providers)-1 {
-				fmt.Printf("\n")
+				notifier.Printf("\n")
 			}
 		}
 
@@ -2,14 +2,21 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"strings"
 
 	"github.com/go-clix/cli"
 	"github.com/grafana/grizzly/internal/logger"
+	"github.com/grafana/grizzly/pkg/cloud"
 	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/frontendobservability"
 	"github.com/grafana/grizzly/pkg/grafana"
 	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/loki"
 	"github.com/grafana/grizzly/pkg/mimir"
+	"github.com/grafana/grizzly/pkg/oncall"
+	"github.com/grafana/grizzly/pkg/plugin"
 	"github.com/grafana/grizzly/pkg/syntheticmonitoring"
 	log "github.com/sirupsen/logrus"
 )
@@ -45,6 +52,10 @@ func main() {
 		log.Fatalln(err)
 	}
 
+	if err := selectContext(os.Args[1:]); err != nil {
+		log.Fatalln(err)
+	}
+
 	context, err := config.CurrentContext()
 	if err != nil {
 		log.Fatalln(err)
@@ -56,37 +67,137 @@ func main() {
 	// workflow commands
 	rootCmd.AddCommand(
 		getCmd(registry),
+		whenceCmd(registry),
 		listCmd(registry),
+		inventoryCmd(registry),
 		pullCmd(registry),
 		showCmd(registry),
 		diffCmd(registry),
+		planCmd(registry),
 		applyCmd(registry),
+		deleteCmd(registry),
+		rollbackCmd(registry),
+		editCmd(registry),
 		watchCmd(registry),
 		exportCmd(registry),
 		snapshotCmd(registry),
+		previewCmd(registry),
+		staleCmd(registry),
+		statusCmd(registry),
 		providersCmd(registry),
+		explainCmd(registry),
 		configCmd(registry),
 		serveCmd(registry),
+		initCmd(),
+		convertCmd(registry),
+		importCmd(registry),
+		checkLinksCmd(registry),
+		validateCmd(registry),
+		verifyUIDsCmd(registry),
+		maintenanceCmd(registry),
+		tagsCmd(registry),
+		alertsCmd(registry),
+		libraryCmd(registry),
+		stateCmd(registry),
+		tenantsCmd(),
 		selfUpdateCmd(),
 	)
 
 	// Run!
-	if err = rootCmd.Execute(); err != nil {
+	err = rootCmd.Execute()
+	plugin.CleanupClients()
+	if err != nil {
 		if errors.Is(err, silentError{}) {
 			log.Debugf("Silent error: %s", err)
 			os.Exit(1)
 		} else {
-			log.Fatalln(err)
+			entry := log.NewEntry(log.StandardLogger())
+			if code := grizzly.ErrorCodeOf(err); code != grizzly.ErrorCodeUnknown {
+				entry = entry.WithField("code", code)
+			}
+			if hint := grizzly.ErrorHintOf(err); hint != "" {
+				entry = entry.WithField("hint", hint)
+			}
+			entry.Fatalln(err)
+		}
+	}
+}
+
+const (
+	environmentFlag = "env"
+	contextFlag     = "context"
+)
+
+// selectContext selects the context of the command, given by --env or
+// --context among its arguments, if any
+func selectContext(args []string) error {
+	environment, contextName := flagFromArgs(args, environmentFlag), flagFromArgs(args, contextFlag)
+	if environment != "" && contextName != "" {
+		return fmt.Errorf("--%s can't be used with --%s, which selects the context of the environment", contextFlag, environmentFlag)
+	}
+	if environment != "" {
+		if _, err := config.UseEnvironment(environment); err != nil {
+			return err
+		}
+	}
+	if contextName != "" {
+		return config.UseContextFor(contextName)
+	}
+	return nil
+}
+
+// flagFromArgs returns the value of a flag selecting the context of the
+// command, such as --env or --context, which is needed before the commands
+// are created, as they are given a registry created from that context
+func flagFromArgs(args []string, flag string) string {
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return ""
+		case arg == "--"+flag && i+1 < len(args):
+			return args[i+1]
+		case strings.HasPrefix(arg, "--"+flag+"="):
+			return strings.TrimPrefix(arg, "--"+flag+"=")
+		}
+	}
+	return ""
+}
+
+// configuredSecrets returns the secrets of the current context, and of every
+// other context, as HTTP dumps of a command can span several contexts
+func configuredSecrets() []string {
+	var secrets []string
+	if context, err := config.CurrentContext(); err == nil {
+		secrets = append(secrets, context.Secrets()...)
+	}
+	names, _ := config.GetContexts()
+	for _, name := range names {
+		if context, err := config.GetContext(name); err == nil {
+			secrets = append(secrets, context.Secrets()...)
 		}
 	}
+	return secrets
 }
 
 func createRegistry(context *config.Context) grizzly.Registry {
 	providers := []grizzly.Provider{
+		// PDC networks are applied before the datasources reaching through them
+		cloud.NewProvider(&context.Cloud),
 		grafana.NewProvider(&context.Grafana),
 		mimir.NewProvider(&context.Mimir),
+		loki.NewProvider(&context.Loki),
 		syntheticmonitoring.NewProvider(&context.SyntheticMonitoring),
+		frontendobservability.NewProvider(&context.FrontendObservability),
+		oncall.NewProvider(&context.OnCall),
 	}
+	providers = plugin.LoadProviders(providers, context.PluginsDir(), context.PluginsCacheFile(), context.Plugins.Settings)
 
-	return grizzly.NewRegistry(providers)
+	registry := grizzly.NewRegistry(providers)
+	registry.AnnotationTargets = context.Annotations
+	registry.YAMLStyle = context.YAML
+	registry.RuleIntervals = context.RuleIntervals
+	registry.DiffTool = context.DiffTool
+	registry.Filenames = context.Filenames
+	registry.PathTemplate = context.PathTemplate
+	return registry
 }
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlagFromArgs(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{name: "flag and value", args: []string{"apply", "--context", "prod", "dashboards"}, expected: "prod"},
+		{name: "flag with value", args: []string{"apply", "--context=prod", "dashboards"}, expected: "prod"},
+		{name: "empty value", args: []string{"apply", "--context="}, expected: ""},
+		{name: "flag without value", args: []string{"apply", "--context"}, expected: ""},
+		{name: "flag after --", args: []string{"apply", "--", "--context", "prod"}, expected: ""},
+		{name: "flag before --", args: []string{"apply", "--context=prod", "--", "--context=dev"}, expected: "prod"},
+		{name: "other flags", args: []string{"apply", "--contexts", "prod", "--context-dir=prod"}, expected: ""},
+		{name: "no flag", args: []string{"apply", "dashboards"}, expected: ""},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, flagFromArgs(test.args, contextFlag))
+		})
+	}
+}
+
+func TestSelectContext(t *testing.T) {
+	mockConfig(t, map[string]any{
+		"contexts.prod.name":           "prod",
+		"contexts.staging.name":        "staging",
+		"environments.preview.context": "staging",
+	})
+	// the selection outlives the configuration, unless cleared
+	t.Cleanup(func() { config.Mock(nil) })
+
+	require.NoError(t, selectContext([]string{"apply", "dashboards"}))
+	current, err := config.CurrentContext()
+	require.NoError(t, err)
+	require.Equal(t, "default", current.Name, "the current context is used unless another one is selected")
+
+	require.NoError(t, selectContext([]string{"apply", "--context", "prod", "dashboards"}))
+	current, err = config.CurrentContext()
+	require.NoError(t, err)
+	require.Equal(t, "prod", current.Name)
+
+	require.NoError(t, selectContext([]string{"apply", "--env=preview", "dashboards"}))
+	current, err = config.CurrentContext()
+	require.NoError(t, err)
+	require.Equal(t, "staging", current.Name, "environments select their context")
+
+	require.EqualError(t, selectContext([]string{"apply", "--context=missing"}), "context missing not found")
+	require.EqualError(t, selectContext([]string{"apply", "--env=missing"}), "environment missing not found")
+	require.EqualError(t, selectContext([]string{"apply", "--env", "preview", "--context", "prod"}), "--context can't be used with --env, which selects the context of the environment")
+}
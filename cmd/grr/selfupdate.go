@@ -9,6 +9,7 @@ import (
 	"github.com/go-clix/cli"
 	"github.com/grafana/grizzly/internal/grizzly"
 	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
 )
 
 func selfUpdateCmd() *cli.Command {
@@ -27,14 +28,14 @@ func selfUpdateCmd() *cli.Command {
 			return fmt.Errorf("self-update aborted as the next version (%[1]s) is a major bump from the current one (%[2]s). Please update manually: https://github.com/grafana/grizzly/releases/tag/%[1]s", newVersion, config.Version)
 		}
 		if errors.Is(err, grizzly.ErrCurrentVersionIsLatest) {
-			fmt.Printf("Current version is the latest: %s\n", config.Version)
+			notifier.Printf("Current version is the latest: %s\n", config.Version)
 			return nil
 		}
 		if err != nil {
 			return err
 		}
 
-		fmt.Printf("Successfully updated to version %s\n", newVersion)
+		notifier.Printf("Successfully updated to version %s\n", newVersion)
 
 		return nil
 	}
@@ -1,5 +1,6 @@
 {
   access: 'proxy',
+  name: 'prometheus',
   isDefault: true,
   jsonData: {
     httpMethod: 'GET',
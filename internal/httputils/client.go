@@ -10,6 +10,13 @@ import (
 var defaultTimeout = 10 * time.Second
 
 func NewHTTPClient() (*http.Client, error) {
+	return NewHTTPClientWithTransport(nil)
+}
+
+// NewHTTPClientWithTransport creates a client sending requests with the given
+// transport, such as a transport with a custom TLS configuration. Nil means
+// http.DefaultTransport.
+func NewHTTPClientWithTransport(base http.RoundTripper) (*http.Client, error) {
 	timeout := defaultTimeout
 
 	// TODO: Move this configuration to the global configuration
@@ -22,8 +29,36 @@ func NewHTTPClient() (*http.Client, error) {
 		timeout = time.Duration(timeoutSeconds) * time.Second
 	}
 
+	transport := base
+	if transport == nil {
+		transport = configuredTransport()
+	}
+
+	if compressRequests() {
+		transport = &GzipRoundTripper{DecoratedTransport: transport}
+	}
+
+	// dumps show request bodies before they're compressed
+	if httpDump.dir != "" {
+		transport = &DumpRoundTripper{DecoratedTransport: transport}
+	}
+
+	// the time spent waiting for throttled requests to be sent doesn't count
+	// towards the timeout of the client
+	if currentThrottle() != nil {
+		transport = &ThrottleRoundTripper{DecoratedTransport: transport, Timeout: timeout}
+		timeout = 0
+	}
+
+	// retries are sent at the rate of the throttle, and each attempt gets the
+	// whole timeout
+	if currentRetry() != nil {
+		transport = &RetryRoundTripper{DecoratedTransport: transport, Timeout: timeout}
+		timeout = 0
+	}
+
 	return &http.Client{
 		Timeout:   timeout,
-		Transport: &LoggedHTTPRoundTripper{},
+		Transport: &LoggedHTTPRoundTripper{DecoratedTransport: transport},
 	}, nil
 }
@@ -0,0 +1,86 @@
+package httputils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// minCompressedBodySize is the size below which request bodies are sent as-is,
+// as compressing them isn't worth it.
+const minCompressedBodySize = 1024
+
+// compression holds whether every HTTP client compresses request bodies
+var compression struct {
+	sync.Mutex
+	enabled bool
+}
+
+// EnableRequestCompression compresses the request bodies of the HTTP clients
+// created afterwards. Not every endpoint accepts compressed request bodies,
+// so this is opt-in.
+func EnableRequestCompression(enabled bool) {
+	compression.Lock()
+	defer compression.Unlock()
+	compression.enabled = enabled
+}
+
+// compressRequests returns whether request bodies are compressed
+func compressRequests() bool {
+	compression.Lock()
+	defer compression.Unlock()
+	return compression.enabled
+}
+
+// GzipRoundTripper compresses request bodies with gzip.
+// Responses are decompressed transparently by http.Transport, which always
+// advertises gzip support unless told otherwise.
+type GzipRoundTripper struct {
+	DecoratedTransport http.RoundTripper
+}
+
+func (rt GzipRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := http.DefaultTransport
+	if rt.DecoratedTransport != nil {
+		transport = rt.DecoratedTransport
+	}
+
+	if req.Body == nil || req.Body == http.NoBody || req.Header.Get("Content-Encoding") != "" {
+		return transport.RoundTrip(req)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Body.Close(); err != nil {
+		return nil, err
+	}
+
+	if len(body) < minCompressedBodySize {
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		return transport.RoundTrip(req)
+	}
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Content-Encoding", "gzip")
+	req.ContentLength = int64(compressed.Len())
+	req.Body = io.NopCloser(bytes.NewReader(compressed.Bytes()))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(compressed.Bytes())), nil
+	}
+
+	return transport.RoundTrip(req)
+}
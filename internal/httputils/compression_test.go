@@ -0,0 +1,133 @@
+package httputils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// received is a request as received by a test server
+type received struct {
+	encoding      string
+	contentLength int64
+	body          string
+}
+
+func newCompressionTestServer(t *testing.T) (*httptest.Server, *received) {
+	t.Helper()
+	last := &received{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		*last = received{encoding: r.Header.Get("Content-Encoding"), contentLength: r.ContentLength, body: string(body)}
+	}))
+	t.Cleanup(server.Close)
+	return server, last
+}
+
+func gunzip(t *testing.T, compressed string) string {
+	t.Helper()
+	reader, err := gzip.NewReader(strings.NewReader(compressed))
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestGzipRoundTripper(t *testing.T) {
+	server, last := newCompressionTestServer(t)
+	client := &http.Client{Transport: GzipRoundTripper{}}
+	large := strings.Repeat(`{"title": "dashboard"}`, 100)
+
+	t.Run("large bodies are compressed", func(t *testing.T) {
+		resp, err := client.Post(server.URL, "application/json", strings.NewReader(large))
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		require.Equal(t, "gzip", last.encoding)
+		require.Less(t, len(last.body), len(large))
+		require.EqualValues(t, len(last.body), last.contentLength)
+		require.Equal(t, large, gunzip(t, last.body))
+	})
+
+	t.Run("small bodies are sent as-is", func(t *testing.T) {
+		resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"title": "dashboard"}`))
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		require.Empty(t, last.encoding)
+		require.Equal(t, `{"title": "dashboard"}`, last.body)
+	})
+
+	t.Run("requests without a body are sent as-is", func(t *testing.T) {
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		require.Empty(t, last.encoding)
+		require.Empty(t, last.body)
+	})
+
+	t.Run("encoded bodies aren't compressed again", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(large))
+		require.NoError(t, err)
+		req.Header.Set("Content-Encoding", "br")
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		require.Equal(t, "br", last.encoding)
+		require.Equal(t, large, last.body)
+	})
+
+	t.Run("compressed bodies can be sent again", func(t *testing.T) {
+		var bodies [][]byte
+		transport := GzipRoundTripper{DecoratedTransport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			for range 2 {
+				body, err := req.GetBody()
+				require.NoError(t, err)
+				content, err := io.ReadAll(body)
+				require.NoError(t, err)
+				bodies = append(bodies, content)
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		})}
+		req, err := http.NewRequest(http.MethodPost, server.URL, bytes.NewReader([]byte(large)))
+		require.NoError(t, err)
+		_, err = transport.RoundTrip(req)
+		require.NoError(t, err)
+
+		require.Len(t, bodies, 2)
+		require.Equal(t, bodies[0], bodies[1])
+		require.Equal(t, large, gunzip(t, string(bodies[1])))
+	})
+}
+
+func TestEnableRequestCompression(t *testing.T) {
+	t.Cleanup(func() { EnableRequestCompression(false) })
+	server, last := newCompressionTestServer(t)
+	large := strings.Repeat("a", 2*minCompressedBodySize)
+
+	for _, enabled := range []bool{false, true} {
+		EnableRequestCompression(enabled)
+		client, err := NewHTTPClient()
+		require.NoError(t, err)
+		resp, err := client.Post(server.URL, "text/plain", strings.NewReader(large))
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		require.Equal(t, enabled, last.encoding == "gzip")
+	}
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,155 @@
+package httputils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// dumpedHeaders are the headers written as-is to HTTP dumps, the values of
+// any other header are redacted
+var dumpedHeaders = map[string]bool{
+	"Accept":               true,
+	"Accept-Encoding":      true,
+	"Cache-Control":        true,
+	"Content-Encoding":     true,
+	"Content-Length":       true,
+	"Content-Type":         true,
+	"Date":                 true,
+	"Etag":                 true,
+	"If-Match":             true,
+	"If-None-Match":        true,
+	"Location":             true,
+	"Retry-After":          true,
+	"Transfer-Encoding":    true,
+	"User-Agent":           true,
+	"X-Disable-Provenance": true,
+	"X-Grafana-Org-Id":     true,
+	"X-Request-Id":         true,
+	"X-Scope-Orgid":        true,
+}
+
+const redacted = "**REDACTED**"
+
+var unsafeDumpCharacters = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// httpDump holds where requests are dumped, if anywhere
+var httpDump struct {
+	dir     string
+	secrets []string
+	count   atomic.Int64
+}
+
+// EnableHTTPDump writes every request sent, and response received, by the
+// HTTP clients created afterwards to its own file of dir. Only the values of
+// well-known headers are kept, and the given secrets are redacted from bodies.
+func EnableHTTPDump(dir string, secrets []string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	httpDump.dir = dir
+	httpDump.secrets = secrets
+	return nil
+}
+
+// DumpRoundTripper writes requests and their responses to files, as
+// configured with EnableHTTPDump
+type DumpRoundTripper struct {
+	DecoratedTransport http.RoundTripper
+}
+
+func (rt DumpRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := http.DefaultTransport
+	if rt.DecoratedTransport != nil {
+		transport = rt.DecoratedTransport
+	}
+	if httpDump.dir == "" {
+		return transport.RoundTrip(req)
+	}
+
+	var requestBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		if err := req.Body.Close(); err != nil {
+			return nil, err
+		}
+		requestBody = body
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	var dump bytes.Buffer
+	fmt.Fprintf(&dump, "> %s %s\n", req.Method, req.URL.Redacted())
+	writeDumpHeaders(&dump, "> ", req.Header)
+	dump.WriteString(">\n")
+	dump.Write(requestBody)
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		fmt.Fprintf(&dump, "\n\n< error: %s\n", err)
+		writeDump(req, dump.Bytes())
+		return resp, err
+	}
+
+	responseBody, readErr := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	fmt.Fprintf(&dump, "\n\n< %s\n", resp.Status)
+	writeDumpHeaders(&dump, "< ", resp.Header)
+	dump.WriteString("<\n")
+	dump.Write(responseBody)
+	if readErr != nil {
+		fmt.Fprintf(&dump, "\n< error reading the body: %s", readErr)
+	}
+	dump.WriteString("\n")
+	writeDump(req, dump.Bytes())
+
+	return resp, readErr
+}
+
+func writeDumpHeaders(dump *bytes.Buffer, prefix string, headers http.Header) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range headers[name] {
+			if !dumpedHeaders[http.CanonicalHeaderKey(name)] {
+				value = redacted
+			}
+			fmt.Fprintf(dump, "%s%s: %s\n", prefix, name, value)
+		}
+	}
+}
+
+// writeDump writes an exchange to a file named after the order it happened
+// in and its request, such as 0012-POST-api-dashboards-db.txt
+func writeDump(req *http.Request, dump []byte) {
+	content := string(dump)
+	for _, secret := range httpDump.secrets {
+		content = strings.ReplaceAll(content, secret, redacted)
+	}
+
+	name := strings.Trim(unsafeDumpCharacters.ReplaceAllString(req.URL.Path, "-"), "-")
+	if len(name) > 80 {
+		name = name[:80]
+	}
+	file := filepath.Join(httpDump.dir, fmt.Sprintf("%04d-%s-%s.txt", httpDump.count.Add(1), req.Method, name))
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		log.Warnf("Could not dump HTTP request: %s", err)
+	}
+}
@@ -0,0 +1,145 @@
+package httputils
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// enableTestHTTPDump dumps HTTP exchanges to a temporary directory for the
+// duration of a test
+func enableTestHTTPDump(t *testing.T, secrets ...string) string {
+	t.Helper()
+	t.Cleanup(func() {
+		httpDump.dir, httpDump.secrets = "", nil
+		httpDump.count.Store(0)
+	})
+	dir := filepath.Join(t.TempDir(), "dumps")
+	require.NoError(t, EnableHTTPDump(dir, secrets))
+	return dir
+}
+
+func readDumps(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	dumps := map[string]string{}
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		require.NoError(t, err)
+		dumps[entry.Name()] = string(content)
+	}
+	return dumps
+}
+
+func TestWriteDumpHeaders(t *testing.T) {
+	var dump bytes.Buffer
+	writeDumpHeaders(&dump, "> ", http.Header{
+		"Authorization":    {"Bearer glsa_secret"},
+		"Cookie":           {"grafana_session=secret"},
+		"X-Grafana-Org-Id": {"2"},
+		"Content-Type":     {"application/json"},
+		"x-scope-orgid":    {"tenant"},
+		"Accept":           {"application/json", "text/plain"},
+	})
+
+	require.Equal(t, strings.Join([]string{
+		"> Accept: application/json",
+		"> Accept: text/plain",
+		"> Authorization: **REDACTED**",
+		"> Content-Type: application/json",
+		"> Cookie: **REDACTED**",
+		"> X-Grafana-Org-Id: 2",
+		"> x-scope-orgid: tenant",
+		"",
+	}, "\n"), dump.String(), "headers are sorted, and only well-known ones are written as-is")
+}
+
+func TestDumpRoundTripper(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		received = string(body)
+		w.Header().Set("Set-Cookie", "grafana_session=secret")
+		w.Header().Set("X-Request-Id", "request-1")
+		_, _ = w.Write([]byte(`{"token": "glsa_secret"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	dir := enableTestHTTPDump(t, "glsa_secret")
+	client := &http.Client{Transport: DumpRoundTripper{}}
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/api/dashboards/db", strings.NewReader(`{"key": "glsa_secret"}`))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer glsa_secret")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	require.Equal(t, `{"key": "glsa_secret"}`, received, "the request body is sent once dumped")
+	require.Equal(t, `{"token": "glsa_secret"}`, string(body), "the response body is read once dumped")
+
+	dumps := readDumps(t, dir)
+	require.Contains(t, dumps, "0001-POST-api-dashboards-db.txt")
+	dump := dumps["0001-POST-api-dashboards-db.txt"]
+	require.NotContains(t, dump, "glsa_secret")
+	require.NotContains(t, dump, "grafana_session")
+	require.Contains(t, dump, "> POST "+server.URL+"/api/dashboards/db\n")
+	require.Contains(t, dump, "> Authorization: **REDACTED**\n")
+	require.Contains(t, dump, `{"key": "**REDACTED**"}`)
+	require.Contains(t, dump, "< 200 OK\n")
+	require.Contains(t, dump, "< Set-Cookie: **REDACTED**\n")
+	require.Contains(t, dump, "< X-Request-Id: request-1\n")
+	require.Contains(t, dump, `{"token": "**REDACTED**"}`)
+
+	t.Run("files are named after the order and the request", func(t *testing.T) {
+		resp, err := client.Get(server.URL + "/api/folders/" + strings.Repeat("a", 100) + "?query=x")
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		resp, err = client.Get(server.URL + "/")
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		dumps := readDumps(t, dir)
+		long := "0002-GET-" + ("api-folders-" + strings.Repeat("a", 100))[:80] + ".txt"
+		require.Contains(t, dumps, long, "long paths are truncated")
+		require.Contains(t, dumps, "0003-GET-.txt")
+	})
+
+	t.Run("failed requests are dumped", func(t *testing.T) {
+		failing := &http.Client{Transport: DumpRoundTripper{DecoratedTransport: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+			return nil, io.ErrUnexpectedEOF
+		})}}
+		_, err := failing.Get(server.URL + "/api/health")
+		require.Error(t, err)
+
+		dump := readDumps(t, dir)["0004-GET-api-health.txt"]
+		require.Contains(t, dump, "< error: unexpected EOF")
+	})
+}
+
+func TestDumpRoundTripperDisabled(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+	}))
+	t.Cleanup(server.Close)
+
+	client := &http.Client{Transport: DumpRoundTripper{}}
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("body"))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, "body", received)
+	require.Zero(t, httpDump.count.Load(), "nothing is dumped unless enabled")
+}
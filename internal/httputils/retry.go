@@ -0,0 +1,149 @@
+package httputils
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Retry retries the requests that failed for transient reasons, such as
+// remote endpoints being rate limited or restarted
+type Retry struct {
+	// Retries is how many times failed requests are retried
+	Retries int
+	// MinBackoff is how long to wait before the first retry. The wait doubles
+	// after each attempt.
+	MinBackoff time.Duration
+	// MaxBackoff is how long to wait between attempts at most, unless asked
+	// otherwise with a Retry-After header
+	MaxBackoff time.Duration
+	// Jitter spreads retries by delaying each of them randomly, up to this long
+	Jitter time.Duration
+}
+
+// retrying holds the retry settings shared by every HTTP client, if any
+var retrying struct {
+	sync.Mutex
+	retry *Retry
+}
+
+// EnableRetries retries the failed requests of the HTTP clients created
+// afterwards. Retries are disabled with no retries.
+func EnableRetries(retry Retry) {
+	retrying.Lock()
+	defer retrying.Unlock()
+	if retry.Retries <= 0 {
+		retrying.retry = nil
+		return
+	}
+	retrying.retry = &retry
+}
+
+// currentRetry returns the settings requests are retried with, if any
+func currentRetry() *Retry {
+	retrying.Lock()
+	defer retrying.Unlock()
+	return retrying.retry
+}
+
+// transientStatuses are the statuses of responses worth retrying, and
+// whether requests that can't safely be sent twice are retried too: 429 and
+// 503 reject requests before processing them, while a 502 or 504 may come
+// from a proxy giving up on a request that is still processed
+var transientStatuses = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         false,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     false,
+}
+
+// RetryRoundTripper retries, with exponential backoff, the requests failing
+// with a transient status, and the idempotent requests failing to be sent or
+// failing with a 502 or 504, as configured with EnableRetries. Requests
+// throttled with a 429 are left to the ThrottleRoundTripper when throttling
+// is enabled. Timeout applies to each attempt, rather than to the time spent
+// waiting.
+type RetryRoundTripper struct {
+	DecoratedTransport http.RoundTripper
+	Timeout            time.Duration
+}
+
+func (rt RetryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := http.DefaultTransport
+	if rt.DecoratedTransport != nil {
+		transport = rt.DecoratedTransport
+	}
+	retry := currentRetry()
+	if retry == nil {
+		return attemptWithin(transport, req, rt.Timeout)
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := attemptWithin(transport, req, rt.Timeout)
+		if !rt.retriable(req, resp, err) ||
+			attempt >= retry.Retries ||
+			// requests whose body can't be read again can't be retried
+			(req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+			return resp, err
+		}
+
+		wait := retry.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp); ok {
+				wait = after
+			}
+			log.Debugf("%s %s failed with %s, retrying in %s", req.Method, req.URL.Redacted(), resp.Status, wait)
+			_ = resp.Body.Close()
+		} else {
+			log.Debugf("%s %s failed: %s, retrying in %s", req.Method, req.URL.Redacted(), err, wait)
+		}
+		if err := sleep(req, wait); err != nil {
+			return nil, err
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// retriable returns whether a request failed for a transient reason
+func (rt RetryRoundTripper) retriable(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		// the request may have been processed when the connection broke,
+		// so only requests that can be processed twice are retried
+		return req.Context().Err() == nil && isIdempotent(req.Method)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests && currentThrottle() != nil {
+		return false
+	}
+	rejected, transient := transientStatuses[resp.StatusCode]
+	return transient && (rejected || isIdempotent(req.Method))
+}
+
+// backoff returns how long to wait after an attempt failed
+func (r Retry) backoff(attempt int) time.Duration {
+	wait := r.MinBackoff << min(attempt, 16)
+	if r.MaxBackoff > 0 && (wait > r.MaxBackoff || wait <= 0) {
+		wait = r.MaxBackoff
+	}
+	if r.Jitter > 0 {
+		wait += rand.N(r.Jitter)
+	}
+	return wait
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
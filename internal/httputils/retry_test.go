@@ -0,0 +1,123 @@
+package httputils
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// enableTestRetries retries requests for the duration of a test
+func enableTestRetries(t *testing.T, retry Retry) {
+	t.Helper()
+	t.Cleanup(func() { EnableRetries(Retry{}) })
+	EnableRetries(retry)
+}
+
+// newFailingTestServer returns a server failing the first requests with the
+// given status, and the number of requests it received
+func newFailingTestServer(t *testing.T, failures, status int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	received := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if int(received.Add(1)) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status": "success"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func TestRetryRoundTripper(t *testing.T) {
+	enableTestRetries(t, Retry{Retries: 2, MinBackoff: time.Millisecond})
+	client := &http.Client{Transport: RetryRoundTripper{}}
+
+	for _, test := range []struct {
+		name     string
+		method   string
+		status   int
+		requests int32
+	}{
+		{name: "GET after a 502", method: http.MethodGet, status: http.StatusBadGateway, requests: 2},
+		{name: "GET after a 504", method: http.MethodGet, status: http.StatusGatewayTimeout, requests: 2},
+		{name: "PUT after a 502", method: http.MethodPut, status: http.StatusBadGateway, requests: 2},
+		{name: "POST after a 429", method: http.MethodPost, status: http.StatusTooManyRequests, requests: 2},
+		{name: "POST after a 503", method: http.MethodPost, status: http.StatusServiceUnavailable, requests: 2},
+		// the request may still be processed behind the proxy
+		{name: "POST after a 502", method: http.MethodPost, status: http.StatusBadGateway, requests: 1},
+		{name: "POST after a 504", method: http.MethodPost, status: http.StatusGatewayTimeout, requests: 1},
+		{name: "GET after a 500", method: http.MethodGet, status: http.StatusInternalServerError, requests: 1},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			server, received := newFailingTestServer(t, 1, test.status)
+			req, err := http.NewRequest(test.method, server.URL, strings.NewReader(`{"title": "dashboard"}`))
+			require.NoError(t, err)
+
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			require.Equal(t, test.requests, received.Load())
+		})
+	}
+
+	t.Run("requests are retried a limited number of times", func(t *testing.T) {
+		server, received := newFailingTestServer(t, 5, http.StatusServiceUnavailable)
+
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		require.EqualValues(t, 3, received.Load())
+	})
+
+	t.Run("429s are left to throttling when enabled", func(t *testing.T) {
+		enableTestThrottling(t, Throttle{})
+		server, received := newFailingTestServer(t, 1, http.StatusTooManyRequests)
+
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		require.EqualValues(t, 1, received.Load())
+	})
+
+	t.Run("requests failing to be sent are retried when idempotent", func(t *testing.T) {
+		var attempts int
+		client := &http.Client{Transport: RetryRoundTripper{DecoratedTransport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			return nil, &net.OpError{Op: "read", Err: syscall.ECONNRESET}
+		})}}
+
+		_, err := client.Get("http://grafana.example")
+		require.Error(t, err)
+		require.Equal(t, 3, attempts)
+
+		attempts = 0
+		_, err = client.Post("http://grafana.example", "application/json", strings.NewReader(`{}`))
+		require.Error(t, err)
+		require.Equal(t, 1, attempts, "requests that may have been processed aren't sent again")
+	})
+}
+
+func TestRetryBackoff(t *testing.T) {
+	retry := Retry{MinBackoff: time.Second, MaxBackoff: 30 * time.Second}
+	require.Equal(t, time.Second, retry.backoff(0))
+	require.Equal(t, 4*time.Second, retry.backoff(2))
+	require.Equal(t, 30*time.Second, retry.backoff(5), "backing off is capped")
+	require.Equal(t, 30*time.Second, retry.backoff(100))
+
+	retry.Jitter = 500 * time.Millisecond
+	for range 20 {
+		wait := retry.backoff(0)
+		require.GreaterOrEqual(t, wait, time.Second)
+		require.Less(t, wait, 1500*time.Millisecond)
+	}
+}
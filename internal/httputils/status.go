@@ -0,0 +1,44 @@
+package httputils
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// StatusError is returned when an API responds with an unsuccessful status
+type StatusError struct {
+	// API names the API that responded, such as "Loki" or "the OnCall API"
+	API        string
+	StatusCode int
+	Body       string
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("%s responded with %d: %s", e.API, e.StatusCode, e.Body)
+}
+
+// IsCode reports whether the response had the given status code
+func (e StatusError) IsCode(code int) bool {
+	return e.StatusCode == code
+}
+
+// Do sends a request to an API, and returns the body of the response. A
+// response with an unsuccessful status is returned as a StatusError.
+func Do(client *http.Client, req *http.Request, api string) ([]byte, error) {
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %w", api, err)
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read response body: %w", err)
+	}
+	if res.StatusCode >= 300 {
+		return nil, StatusError{API: api, StatusCode: res.StatusCode, Body: strings.TrimSpace(string(data))}
+	}
+	return data, nil
+}
@@ -0,0 +1,42 @@
+package httputils
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.Error(w, "group not found", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status": "success"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	do := func(path string) ([]byte, error) {
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		return Do(server.Client(), req, "Mimir")
+	}
+
+	t.Run("the body of successful responses is returned", func(t *testing.T) {
+		data, err := do("/found")
+		require.NoError(t, err)
+		require.JSONEq(t, `{"status": "success"}`, string(data))
+	})
+
+	t.Run("unsuccessful responses are returned as a status error naming the API", func(t *testing.T) {
+		_, err := do("/missing")
+		require.EqualError(t, err, "Mimir responded with 404: group not found")
+
+		var statusErr StatusError
+		require.True(t, errors.As(err, &statusErr))
+		require.True(t, statusErr.IsCode(http.StatusNotFound))
+	})
+}
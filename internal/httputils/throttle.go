@@ -0,0 +1,202 @@
+package httputils
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// maxSlowdown is how many times slower than their rate requests are sent at
+// most, after being throttled by remote endpoints
+const maxSlowdown = 16
+
+// Throttle limits the requests sent by HTTP clients, so that long-running
+// jobs don't compete with interactive users of the remote endpoints
+type Throttle struct {
+	// RequestsPerSecond is the rate requests are sent at, at most
+	RequestsPerSecond float64
+	// Jitter spreads requests by delaying each of them randomly, up to this long
+	Jitter time.Duration
+	// Retries is how many times requests throttled with a 429 are retried
+	Retries int
+}
+
+// throttling holds the throttle shared by every HTTP client, if any
+var throttling struct {
+	sync.Mutex
+	throttle *Throttle
+	// next is when the next request can be sent
+	next time.Time
+	// slowdown divides the rate of requests, and doubles each time a request
+	// is throttled
+	slowdown float64
+}
+
+// EnableThrottling throttles the requests sent by the HTTP clients created
+// afterwards. The rate is shared by all of them.
+func EnableThrottling(throttle Throttle) {
+	throttling.Lock()
+	defer throttling.Unlock()
+	throttling.throttle = &throttle
+	throttling.slowdown = 1
+}
+
+// currentThrottle returns the throttle requests are sent with, if any
+func currentThrottle() *Throttle {
+	throttling.Lock()
+	defer throttling.Unlock()
+	return throttling.throttle
+}
+
+// ThrottleRoundTripper sends requests at the rate configured with
+// EnableThrottling, and retries the requests remote endpoints throttle.
+// Timeout applies to each attempt, rather than to the time spent waiting.
+type ThrottleRoundTripper struct {
+	DecoratedTransport http.RoundTripper
+	Timeout            time.Duration
+}
+
+func (rt ThrottleRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := http.DefaultTransport
+	if rt.DecoratedTransport != nil {
+		transport = rt.DecoratedTransport
+	}
+	throttle := currentThrottle()
+	if throttle == nil {
+		return transport.RoundTrip(req)
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := sleep(req, reserveSlot(throttle)); err != nil {
+			return nil, err
+		}
+		resp, err := attemptWithin(transport, req, rt.Timeout)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+			if err == nil {
+				unthrottled()
+			}
+			return resp, err
+		}
+
+		wait := throttled(resp, attempt)
+		// requests whose body can't be read again can't be retried
+		if attempt >= throttle.Retries || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+			return resp, nil
+		}
+		_ = resp.Body.Close()
+		log.Debugf("%s %s was throttled, retrying in %s", req.Method, req.URL.Redacted(), wait)
+		if err := sleep(req, wait); err != nil {
+			return nil, err
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// attemptWithin sends a request once, within a timeout, if any
+func attemptWithin(transport http.RoundTripper, req *http.Request, timeout time.Duration) (*http.Response, error) {
+	if timeout <= 0 {
+		return transport.RoundTrip(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	resp, err := transport.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose cancels the context of a request once its response is read
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (body cancelOnClose) Close() error {
+	defer body.cancel()
+	return body.ReadCloser.Close()
+}
+
+// reserveSlot returns how long to wait before sending a request, so that
+// requests are sent at the rate of the throttle
+func reserveSlot(throttle *Throttle) time.Duration {
+	throttling.Lock()
+	defer throttling.Unlock()
+
+	now := time.Now()
+	slot := throttling.next
+	if slot.Before(now) {
+		slot = now
+	}
+	if throttle.RequestsPerSecond > 0 {
+		interval := time.Duration(float64(time.Second) * throttling.slowdown / throttle.RequestsPerSecond)
+		throttling.next = slot.Add(interval)
+	}
+	wait := slot.Sub(now)
+	if throttle.Jitter > 0 {
+		wait += rand.N(throttle.Jitter)
+	}
+	return wait
+}
+
+// throttled slows requests down after a 429, and returns how long to wait
+// before retrying: as long as the Retry-After header asks, if set, or longer
+// after each attempt otherwise
+func throttled(resp *http.Response, attempt int) time.Duration {
+	throttling.Lock()
+	throttling.slowdown = min(throttling.slowdown*2, maxSlowdown)
+	throttling.Unlock()
+
+	if wait, ok := retryAfter(resp); ok {
+		return wait
+	}
+	return time.Second << min(attempt, 6)
+}
+
+// retryAfter returns how long the Retry-After header of a response asks to
+// wait before retrying, if set
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(resp.Header.Get("Retry-After")); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// unthrottled speeds requests back up, progressively, after they were throttled
+func unthrottled() {
+	throttling.Lock()
+	defer throttling.Unlock()
+	throttling.slowdown = max(throttling.slowdown*0.9, 1)
+}
+
+// sleep waits for the given duration, unless the request is canceled first
+func sleep(req *http.Request, duration time.Duration) error {
+	if duration <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.Context().Done():
+		return req.Context().Err()
+	}
+}
@@ -0,0 +1,232 @@
+package httputils
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// enableTestThrottling throttles requests for the duration of a test
+func enableTestThrottling(t *testing.T, throttle Throttle) {
+	t.Helper()
+	reset := func() {
+		throttling.Lock()
+		defer throttling.Unlock()
+		throttling.throttle, throttling.next, throttling.slowdown = nil, time.Time{}, 0
+	}
+	reset()
+	t.Cleanup(reset)
+	EnableThrottling(throttle)
+}
+
+// newThrottledTestServer returns a server answering the first throttled
+// requests with a 429, with the given Retry-After header, and the number of
+// requests it received
+func newThrottledTestServer(t *testing.T, throttled int, retryAfter string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	received := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		if int(received.Add(1)) <= throttled {
+			w.Header().Set("Retry-After", retryAfter)
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func TestReserveSlot(t *testing.T) {
+	enableTestThrottling(t, Throttle{RequestsPerSecond: 10})
+	throttle := currentThrottle()
+
+	require.Zero(t, reserveSlot(throttle), "the first request is sent right away")
+	require.InDelta(t, 100*time.Millisecond, reserveSlot(throttle), float64(5*time.Millisecond))
+	require.InDelta(t, 200*time.Millisecond, reserveSlot(throttle), float64(5*time.Millisecond), "requests are spread at the rate of the throttle")
+
+	throttling.Lock()
+	throttling.next, throttling.slowdown = time.Time{}, 4
+	throttling.Unlock()
+	reserveSlot(throttle)
+	require.InDelta(t, 400*time.Millisecond, reserveSlot(throttle), float64(5*time.Millisecond), "the rate is divided by the slowdown")
+
+	t.Run("jitter delays requests randomly", func(t *testing.T) {
+		enableTestThrottling(t, Throttle{Jitter: 50 * time.Millisecond})
+		throttle := currentThrottle()
+		for range 20 {
+			wait := reserveSlot(throttle)
+			require.GreaterOrEqual(t, wait, time.Duration(0))
+			require.Less(t, wait, 50*time.Millisecond)
+		}
+	})
+}
+
+func TestThrottled(t *testing.T) {
+	enableTestThrottling(t, Throttle{RequestsPerSecond: 1})
+	resp := &http.Response{Header: http.Header{}}
+
+	require.Equal(t, time.Second, throttled(resp, 0))
+	require.Equal(t, 4*time.Second, throttled(resp, 2), "retries without Retry-After back off exponentially")
+	require.Equal(t, 64*time.Second, throttled(resp, 10), "backing off is capped")
+
+	throttling.Lock()
+	require.EqualValues(t, 8, throttling.slowdown, "the rate halves with each 429")
+	throttling.Unlock()
+	throttled(resp, 0)
+	throttled(resp, 0)
+	throttling.Lock()
+	require.EqualValues(t, maxSlowdown, throttling.slowdown)
+	throttling.Unlock()
+
+	unthrottled()
+	throttling.Lock()
+	require.InDelta(t, maxSlowdown*0.9, throttling.slowdown, 0.001, "the rate recovers progressively")
+	throttling.slowdown = 1
+	throttling.Unlock()
+	unthrottled()
+	throttling.Lock()
+	require.EqualValues(t, 1, throttling.slowdown, "requests aren't sent faster than the rate")
+	throttling.Unlock()
+
+	resp.Header.Set("Retry-After", "3")
+	require.Equal(t, 3*time.Second, throttled(resp, 5), "Retry-After wins over backing off")
+}
+
+func TestRetryAfter(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		retryAfter string
+		wait       time.Duration
+		ok         bool
+	}{
+		{name: "seconds", retryAfter: "120", wait: 2 * time.Minute, ok: true},
+		{name: "zero", retryAfter: "0", wait: 0, ok: true},
+		{name: "past date", retryAfter: time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), wait: 0, ok: true},
+		{name: "negative", retryAfter: "-1"},
+		{name: "invalid", retryAfter: "soon"},
+		{name: "unset"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if test.retryAfter != "" {
+				resp.Header.Set("Retry-After", test.retryAfter)
+			}
+			wait, ok := retryAfter(resp)
+			require.Equal(t, test.ok, ok)
+			require.Equal(t, test.wait, wait)
+		})
+	}
+
+	t.Run("future date", func(t *testing.T) {
+		resp := &http.Response{Header: http.Header{"Retry-After": {time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)}}}
+		wait, ok := retryAfter(resp)
+		require.True(t, ok)
+		require.InDelta(t, time.Minute, wait, float64(2*time.Second))
+	})
+}
+
+func TestThrottleRoundTripper(t *testing.T) {
+	t.Run("requests aren't throttled unless enabled", func(t *testing.T) {
+		server, received := newThrottledTestServer(t, 1, "0")
+		client := &http.Client{Transport: ThrottleRoundTripper{}}
+
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		require.EqualValues(t, 1, received.Load())
+	})
+
+	t.Run("requests are sent at the rate of the throttle", func(t *testing.T) {
+		enableTestThrottling(t, Throttle{RequestsPerSecond: 20})
+		server, _ := newThrottledTestServer(t, 0, "")
+		client := &http.Client{Transport: ThrottleRoundTripper{}}
+
+		start := time.Now()
+		for range 5 {
+			resp, err := client.Get(server.URL)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+		}
+		require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	})
+
+	t.Run("throttled requests are retried with their body", func(t *testing.T) {
+		enableTestThrottling(t, Throttle{Retries: 2})
+		server, received := newThrottledTestServer(t, 2, "0")
+		client := &http.Client{Transport: ThrottleRoundTripper{}}
+
+		resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"title": "dashboard"}`))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, `{"title": "dashboard"}`, string(body))
+		require.EqualValues(t, 3, received.Load())
+
+		throttling.Lock()
+		require.Greater(t, throttling.slowdown, 1.0, "requests are slowed down after a 429")
+		throttling.Unlock()
+	})
+
+	t.Run("retries are limited", func(t *testing.T) {
+		enableTestThrottling(t, Throttle{Retries: 1})
+		server, received := newThrottledTestServer(t, 5, "0")
+		client := &http.Client{Transport: ThrottleRoundTripper{}}
+
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		require.EqualValues(t, 2, received.Load())
+	})
+
+	t.Run("bodies that can't be read again aren't retried", func(t *testing.T) {
+		enableTestThrottling(t, Throttle{Retries: 2})
+		server, received := newThrottledTestServer(t, 1, "0")
+
+		req, err := http.NewRequest(http.MethodPost, server.URL, io.NopCloser(strings.NewReader(`{}`)))
+		require.NoError(t, err)
+		require.Nil(t, req.GetBody)
+		resp, err := ThrottleRoundTripper{}.RoundTrip(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		require.EqualValues(t, 1, received.Load())
+	})
+
+	t.Run("waiting stops when requests are canceled", func(t *testing.T) {
+		enableTestThrottling(t, Throttle{Retries: 1})
+		server, received := newThrottledTestServer(t, 1, "60")
+
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		ctx, cancel := context.WithTimeout(req.Context(), 50*time.Millisecond)
+		defer cancel()
+		_, err = ThrottleRoundTripper{}.RoundTrip(req.WithContext(ctx))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.EqualValues(t, 1, received.Load())
+	})
+
+	t.Run("the timeout applies to each attempt", func(t *testing.T) {
+		enableTestThrottling(t, Throttle{Retries: 1})
+		server, _ := newThrottledTestServer(t, 1, "1")
+		client := &http.Client{Transport: ThrottleRoundTripper{Timeout: 500 * time.Millisecond}}
+
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err, "waiting to retry isn't part of the timeout")
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
@@ -0,0 +1,104 @@
+package httputils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// Transport configures how requests reach remote endpoints, such as through a
+// corporate proxy, trusting an internal CA
+type Transport struct {
+	// Proxy is the URL of the proxy requests are sent through. Empty means the
+	// proxies of the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+	Proxy string
+	// NoProxy lists the hosts requests are sent to directly, rather than
+	// through Proxy, as in NO_PROXY. Ex: localhost,.internal.example.com
+	NoProxy string
+	// CAPath is a PEM bundle of CA certificates, trusted on top of the system ones
+	CAPath string
+	// InsecureSkipVerify doesn't verify the certificates of remote endpoints
+	InsecureSkipVerify bool
+}
+
+// transportSettings holds the transport settings shared by every HTTP
+// client, if any
+var transportSettings struct {
+	sync.Mutex
+	transport *http.Transport
+}
+
+// ConfigureTransport sends the requests of the HTTP clients created
+// afterwards with the given settings. The zero Transport restores the
+// default transport.
+func ConfigureTransport(settings Transport) error {
+	transportSettings.Lock()
+	defer transportSettings.Unlock()
+	if settings == (Transport{}) {
+		transportSettings.transport = nil
+		return nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if settings.Proxy != "" {
+		if _, err := url.Parse(settings.Proxy); err != nil {
+			return fmt.Errorf("invalid proxy: %w", err)
+		}
+		proxy := (&httpproxy.Config{
+			HTTPProxy:  settings.Proxy,
+			HTTPSProxy: settings.Proxy,
+			NoProxy:    settings.NoProxy,
+		}).ProxyFunc()
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			return proxy(req.URL)
+		}
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: settings.InsecureSkipVerify}
+	if settings.CAPath != "" {
+		certPool, err := x509.SystemCertPool()
+		if err != nil {
+			return err
+		}
+		caCertPEM, err := os.ReadFile(settings.CAPath)
+		if err != nil {
+			return err
+		}
+		if !certPool.AppendCertsFromPEM(caCertPEM) {
+			return fmt.Errorf("could not append ca-bundle at path %s to existing certificates", settings.CAPath)
+		}
+		tlsConfig.RootCAs = certPool
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	transportSettings.transport = transport
+	return nil
+}
+
+// NewTransport returns a transport with the configured settings, for clients
+// that need to customise it further, such as with client certificates
+func NewTransport() *http.Transport {
+	transportSettings.Lock()
+	defer transportSettings.Unlock()
+	if transportSettings.transport == nil {
+		return http.DefaultTransport.(*http.Transport).Clone()
+	}
+	return transportSettings.transport.Clone()
+}
+
+// configuredTransport returns the transport requests are sent with when no
+// other is given, if one was configured
+func configuredTransport() http.RoundTripper {
+	transportSettings.Lock()
+	defer transportSettings.Unlock()
+	if transportSettings.transport == nil {
+		return nil
+	}
+	return transportSettings.transport
+}
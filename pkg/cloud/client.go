@@ -0,0 +1,187 @@
+package cloud
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grizzly/internal/httputils"
+)
+
+const (
+	defaultURL             = "https://grafana.com"
+	accessPoliciesEndpoint = "/api/v1/accesspolicies"
+	tokensEndpoint         = "/api/v1/tokens"
+	// pdcScope is the scope of the access policies of PDC networks, signing
+	// the certificates of the agents of the network
+	pdcScope = "set:pdc-signing"
+)
+
+// AccessPolicy is a Grafana Cloud access policy. PDC networks are access
+// policies with the pdc-signing scope, on the realm of a stack.
+type AccessPolicy struct {
+	ID          string   `json:"id,omitempty"`
+	Name        string   `json:"name"`
+	DisplayName string   `json:"displayName,omitempty"`
+	Scopes      []string `json:"scopes"`
+	Realms      []Realm  `json:"realms"`
+}
+
+// Realm is what an access policy grants access to, such as a stack
+type Realm struct {
+	Type       string `json:"type"`
+	Identifier string `json:"identifier"`
+}
+
+// Token authenticates as an access policy, such as the PDC agents of a network
+type Token struct {
+	ID             string `json:"id,omitempty"`
+	AccessPolicyID string `json:"accessPolicyId"`
+	Name           string `json:"name"`
+	DisplayName    string `json:"displayName,omitempty"`
+	ExpiresAt      string `json:"expiresAt,omitempty"`
+	// Token is only returned when the token is created
+	Token string `json:"token,omitempty"`
+}
+
+// page is a page of the items listed by the Grafana Cloud API
+type page[T any] struct {
+	Items    []T `json:"items"`
+	Metadata struct {
+		Pagination struct {
+			NextPage string `json:"nextPage"`
+		} `json:"pagination"`
+	} `json:"metadata"`
+}
+
+// StatusError is returned when the Grafana Cloud API responds with an unsuccessful status
+type StatusError = httputils.StatusError
+
+// stackRealm is the realm of the stack of the provider
+func (p *Provider) stackRealm() Realm {
+	return Realm{Type: "stack", Identifier: strconv.FormatInt(p.config.StackID, 10)}
+}
+
+// listPDCNetworks retrieves the access policies of the PDC networks of the stack
+func (p *Provider) listPDCNetworks() ([]AccessPolicy, error) {
+	realm := p.stackRealm()
+	policies, err := list[AccessPolicy](p, accessPoliciesEndpoint, url.Values{
+		"realmType":       {realm.Type},
+		"realmIdentifier": {realm.Identifier},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var networks []AccessPolicy
+	for _, policy := range policies {
+		if slices.Contains(policy.Scopes, pdcScope) && slices.Contains(policy.Realms, realm) {
+			networks = append(networks, policy)
+		}
+	}
+	return networks, nil
+}
+
+func (p *Provider) createAccessPolicy(policy AccessPolicy) (AccessPolicy, error) {
+	var created AccessPolicy
+	if err := p.doRequest(http.MethodPost, p.url(accessPoliciesEndpoint, nil), policy, &created); err != nil {
+		return AccessPolicy{}, err
+	}
+	return created, nil
+}
+
+func (p *Provider) updateAccessPolicy(policy AccessPolicy) error {
+	return p.doRequest(http.MethodPost, p.url(accessPoliciesEndpoint+"/"+url.PathEscape(policy.ID), nil), policy, nil)
+}
+
+func (p *Provider) deleteAccessPolicy(id string) error {
+	return p.doRequest(http.MethodDelete, p.url(accessPoliciesEndpoint+"/"+url.PathEscape(id), nil), nil, nil)
+}
+
+func (p *Provider) listTokens(accessPolicyID string) ([]Token, error) {
+	return list[Token](p, tokensEndpoint, url.Values{"accessPolicyId": {accessPolicyID}})
+}
+
+func (p *Provider) createToken(token Token) (Token, error) {
+	var created Token
+	if err := p.doRequest(http.MethodPost, p.url(tokensEndpoint, nil), token, &created); err != nil {
+		return Token{}, err
+	}
+	return created, nil
+}
+
+func (p *Provider) deleteToken(id string) error {
+	return p.doRequest(http.MethodDelete, p.url(tokensEndpoint+"/"+url.PathEscape(id), nil), nil, nil)
+}
+
+// list retrieves every item of an endpoint, following the pages of the results
+func list[T any](p *Provider, path string, query url.Values) ([]T, error) {
+	items := []T{}
+	next := p.url(path, query)
+	for next != "" {
+		var results page[T]
+		if err := p.doRequest(http.MethodGet, next, nil, &results); err != nil {
+			return nil, err
+		}
+		items = append(items, results.Items...)
+		next = ""
+		if nextPage := results.Metadata.Pagination.NextPage; nextPage != "" {
+			nextURL, err := url.Parse(nextPage)
+			if err != nil {
+				return nil, err
+			}
+			next = p.url(nextURL.Path, nextURL.Query())
+		}
+	}
+	return items, nil
+}
+
+// url returns the address of an endpoint of the API, in the region of the stack
+func (p *Provider) url(path string, query url.Values) string {
+	base := p.config.URL
+	if base == "" {
+		base = defaultURL
+	}
+	values := url.Values{}
+	for key, value := range query {
+		values[key] = value
+	}
+	values.Set("region", p.config.Region)
+	return strings.TrimSuffix(base, "/") + path + "?" + values.Encode()
+}
+
+// doRequest sends a request to the Grafana Cloud API, and decodes its
+// response into result, when given
+func (p *Provider) doRequest(method, address string, body, result any) error {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, address, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.config.Token)
+
+	client, err := httputils.NewHTTPClientWithTransport(p.transport)
+	if err != nil {
+		return err
+	}
+	data, err := httputils.Do(client, req, "the Grafana Cloud API")
+	if err != nil {
+		return err
+	}
+	if result == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, result)
+}
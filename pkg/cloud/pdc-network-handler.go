@@ -0,0 +1,360 @@
+package cloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
+)
+
+const PDCNetworkKind = "PDCNetwork"
+
+var _ grizzly.Handler = &PDCNetworkHandler{}
+var _ grizzly.ErrorHinter = &PDCNetworkHandler{}
+
+// PDCNetworkHandler is a Grizzly Handler for the Private Data source Connect
+// networks of a Grafana Cloud stack, through which datasources reach the
+// hosts of a private network. The tokens the PDC agents of a network
+// authenticate with are managed with it, as its tokens field.
+type PDCNetworkHandler struct {
+	grizzly.BaseHandler
+	provider *Provider
+}
+
+// NewPDCNetworkHandler returns a Grizzly Handler for PDC networks
+func NewPDCNetworkHandler(provider *Provider) *PDCNetworkHandler {
+	return &PDCNetworkHandler{
+		BaseHandler: grizzly.NewBaseHandler(provider, PDCNetworkKind, false).WithListCache(),
+		provider:    provider,
+	}
+}
+
+const (
+	pdcNetworkPattern = "cloud/pdc-network-%s.%s"
+)
+
+// pdcNetworkSpec is the spec of PDCNetwork resources
+type pdcNetworkSpec struct {
+	Name        string         `json:"name"`
+	DisplayName string         `json:"displayName,omitempty"`
+	Tokens      []pdcTokenSpec `json:"tokens,omitempty"`
+}
+
+// pdcTokenSpec is a token PDC agents authenticate with. The token itself is
+// only known once created, and is sent to the secret sink.
+type pdcTokenSpec struct {
+	Name      string `json:"name"`
+	ExpiresAt string `json:"expiresAt,omitempty"`
+}
+
+// ErrorHint implements grizzly.ErrorHinter
+func (h *PDCNetworkHandler) ErrorHint(code grizzly.ErrorCode) string {
+	switch code {
+	case grizzly.ErrorCodeAuthFailed:
+		return "check cloud.token with `grr config check`, it must be a Cloud access policy token"
+	case grizzly.ErrorCodePermissionDenied:
+		return "the token lacks the accesspolicies:read, accesspolicies:write or accesspolicies:delete scopes, or cloud.region isn't the region of the stack"
+	default:
+		return ""
+	}
+}
+
+// ResourceFilePath returns the location on disk where a resource should be updated
+func (h *PDCNetworkHandler) ResourceFilePath(resource grizzly.Resource, filetype string) string {
+	filename := strings.ReplaceAll(resource.Name(), string(os.PathSeparator), "-")
+	return fmt.Sprintf(pdcNetworkPattern, filename, filetype)
+}
+
+// Unprepare removes unnecessary elements from a remote resource ready for presentation/comparison
+func (h *PDCNetworkHandler) Unprepare(resource grizzly.Resource) *grizzly.Resource {
+	resource.DeleteSpecKey("id")
+	return &resource
+}
+
+// Prepare gets a resource ready for dispatch to the remote endpoint. Access
+// policies are displayed with their name when they have no display name,
+// and tokens are listed by name, so resources are given the same defaults.
+func (h *PDCNetworkHandler) Prepare(existing *grizzly.Resource, resource grizzly.Resource) *grizzly.Resource {
+	spec, err := h.specToNetwork(resource)
+	if err != nil {
+		return &resource
+	}
+	if _, exists := resource.GetSpecString("name"); !exists {
+		resource.SetSpecString("name", resource.Name())
+	}
+	if _, exists := resource.GetSpecString("displayName"); !exists {
+		resource.SetSpecString("displayName", resource.Name())
+	}
+	if len(spec.Tokens) > 0 {
+		tokens := make([]any, 0, len(spec.Tokens))
+		for _, token := range sortTokens(spec.Tokens) {
+			tokens = append(tokens, tokenToSpec(token))
+		}
+		resource.SetSpecValue("tokens", tokens)
+	}
+	return &resource
+}
+
+// Validate checks that the name of the network matches its metadata, and
+// that its tokens have a name, and expire at a valid time
+func (h *PDCNetworkHandler) Validate(resource grizzly.Resource) error {
+	name, exist := resource.GetSpecString("name")
+	if exist && name != resource.Name() {
+		return fmt.Errorf("name '%s' and metadata name '%s', don't match", name, resource.Name())
+	}
+	spec, err := h.specToNetwork(resource)
+	if err != nil {
+		return err
+	}
+	names := map[string]bool{}
+	for i, token := range spec.Tokens {
+		if token.Name == "" {
+			return fmt.Errorf("tokens[%d] of %s has no name", i, resource.Ref())
+		}
+		if names[token.Name] {
+			return fmt.Errorf("token '%s' of %s is listed twice", token.Name, resource.Ref())
+		}
+		names[token.Name] = true
+		if _, err := normalizeExpiry(token.ExpiresAt); err != nil {
+			return fmt.Errorf("expiresAt of token '%s' of %s should be a time such as 2027-01-01T00:00:00Z", token.Name, resource.Ref())
+		}
+	}
+	return nil
+}
+
+// GetUID returns the UID for a resource
+func (h *PDCNetworkHandler) GetUID(resource grizzly.Resource) (string, error) {
+	return resource.Name(), nil
+}
+
+func (h *PDCNetworkHandler) GetSpecUID(resource grizzly.Resource) (string, error) {
+	name, _ := resource.GetSpecString("name")
+	return name, nil
+}
+
+// GetByUID retrieves JSON for a resource from an endpoint, by UID
+func (h *PDCNetworkHandler) GetByUID(uid string) (*grizzly.Resource, error) {
+	network, err := h.getNetwork(uid)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := h.provider.listTokens(network.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := pdcNetworkSpec{Name: network.Name, DisplayName: network.DisplayName}
+	if spec.DisplayName == "" {
+		spec.DisplayName = network.Name
+	}
+	for _, token := range tokens {
+		expiresAt, _ := normalizeExpiry(token.ExpiresAt)
+		spec.Tokens = append(spec.Tokens, pdcTokenSpec{Name: token.Name, ExpiresAt: expiresAt})
+	}
+	spec.Tokens = sortTokens(spec.Tokens)
+	return h.networkToResource(spec)
+}
+
+// GetRemote retrieves a PDC network as a Resource
+func (h *PDCNetworkHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
+	return h.GetByUID(resource.Name())
+}
+
+// ListRemote retrieves as list of UIDs of all remote resources
+func (h *PDCNetworkHandler) ListRemote() ([]string, error) {
+	networks, err := h.listNetworks()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(networks))
+	for _, network := range networks {
+		names = append(names, network.Name)
+	}
+	return names, nil
+}
+
+// Add creates a PDC network, then the tokens of its agents, whose secrets
+// are sent to the secret sink. Datasources are pointed at the network by its
+// ID, which is only known once created, so it is reported.
+func (h *PDCNetworkHandler) Add(resource grizzly.Resource) error {
+	spec, err := h.specToNetwork(resource)
+	if err != nil {
+		return err
+	}
+	created, err := h.provider.createAccessPolicy(h.networkToPolicy(spec))
+	if err != nil {
+		return err
+	}
+	h.InvalidateListCache()
+	notifier.InfoStderr(resource, fmt.Sprintf("datasources reach the network with jsonData.enableSecureSocksProxy: true and jsonData.secureSocksProxyUsername: %s", created.ID))
+	return h.syncTokens(resource, created.ID, nil, spec.Tokens)
+}
+
+// Update pushes an updated PDC network to Grafana Cloud. Tokens missing from
+// the network are created, and the ones removed from it are revoked. Tokens
+// can't be changed, so the ones whose expiry changes are replaced.
+func (h *PDCNetworkHandler) Update(existing, resource grizzly.Resource) error {
+	remote, err := h.getNetwork(resource.Name())
+	if err != nil {
+		return err
+	}
+	spec, err := h.specToNetwork(resource)
+	if err != nil {
+		return err
+	}
+	policy := h.networkToPolicy(spec)
+	policy.ID = remote.ID
+	if err := h.provider.updateAccessPolicy(policy); err != nil {
+		return err
+	}
+	h.InvalidateListCache()
+
+	tokens, err := h.provider.listTokens(remote.ID)
+	if err != nil {
+		return err
+	}
+	return h.syncTokens(resource, remote.ID, tokens, spec.Tokens)
+}
+
+// Delete removes a PDC network, revoking the tokens of its agents
+func (h *PDCNetworkHandler) Delete(resource grizzly.Resource) error {
+	remote, err := h.getNetwork(resource.Name())
+	if err != nil {
+		return err
+	}
+	if err := h.provider.deleteAccessPolicy(remote.ID); err != nil {
+		return err
+	}
+	h.InvalidateListCache()
+	return nil
+}
+
+// syncTokens revokes the existing tokens of a network that aren't wanted,
+// and creates the wanted ones that don't exist, sending their secrets to the
+// secret sink
+func (h *PDCNetworkHandler) syncTokens(resource grizzly.Resource, networkID string, existing []Token, wanted []pdcTokenSpec) error {
+	kept := map[string]bool{}
+	for _, token := range existing {
+		expiresAt, _ := normalizeExpiry(token.ExpiresAt)
+		keep := false
+		for _, wantedToken := range wanted {
+			wantedExpiry, _ := normalizeExpiry(wantedToken.ExpiresAt)
+			if wantedToken.Name == token.Name && wantedExpiry == expiresAt {
+				keep = true
+			}
+		}
+		if keep {
+			kept[token.Name] = true
+			continue
+		}
+		if err := h.provider.deleteToken(token.ID); err != nil {
+			return err
+		}
+	}
+
+	for _, token := range wanted {
+		if kept[token.Name] {
+			continue
+		}
+		created, err := h.provider.createToken(Token{AccessPolicyID: networkID, Name: token.Name, ExpiresAt: token.ExpiresAt})
+		if err != nil {
+			return err
+		}
+		if err := grizzly.StoreSecret(resource, token.Name, created.Token); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listNetworks retrieves the PDC networks of the stack, cached for the run
+func (h *PDCNetworkHandler) listNetworks() ([]AccessPolicy, error) {
+	return grizzly.CachedList(&h.BaseHandler, "pdc-networks", h.provider.listPDCNetworks)
+}
+
+func (h *PDCNetworkHandler) getNetwork(name string) (AccessPolicy, error) {
+	networks, err := h.listNetworks()
+	if err != nil {
+		return AccessPolicy{}, err
+	}
+	for _, network := range networks {
+		if network.Name == name {
+			return network, nil
+		}
+	}
+	return AccessPolicy{}, grizzly.ErrNotFound
+}
+
+// networkToPolicy returns the access policy of a PDC network of the stack
+func (h *PDCNetworkHandler) networkToPolicy(spec pdcNetworkSpec) AccessPolicy {
+	return AccessPolicy{
+		Name:        spec.Name,
+		DisplayName: spec.DisplayName,
+		Scopes:      []string{pdcScope},
+		Realms:      []Realm{h.provider.stackRealm()},
+	}
+}
+
+func (h *PDCNetworkHandler) specToNetwork(resource grizzly.Resource) (pdcNetworkSpec, error) {
+	data, err := json.Marshal(resource.Spec())
+	if err != nil {
+		return pdcNetworkSpec{}, err
+	}
+	var spec pdcNetworkSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return pdcNetworkSpec{}, fmt.Errorf("%s: %w", resource.Ref(), err)
+	}
+	spec.Name = resource.Name()
+	if spec.DisplayName == "" {
+		spec.DisplayName = resource.Name()
+	}
+	return spec, nil
+}
+
+func (h *PDCNetworkHandler) networkToResource(spec pdcNetworkSpec) (*grizzly.Resource, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	specMap := map[string]any{}
+	if err := json.Unmarshal(data, &specMap); err != nil {
+		return nil, err
+	}
+	resource, err := grizzly.NewResource(h.APIVersion(), h.Kind(), spec.Name, specMap)
+	if err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+func tokenToSpec(token pdcTokenSpec) map[string]any {
+	spec := map[string]any{"name": token.Name}
+	if expiresAt, err := normalizeExpiry(token.ExpiresAt); err == nil && expiresAt != "" {
+		spec["expiresAt"] = expiresAt
+	}
+	return spec
+}
+
+func sortTokens(tokens []pdcTokenSpec) []pdcTokenSpec {
+	sorted := append([]pdcTokenSpec{}, tokens...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
+
+// normalizeExpiry formats the expiry of a token in UTC, as the API doesn't
+// return it the way it was given
+func normalizeExpiry(expiresAt string) (string, error) {
+	if expiresAt == "" {
+		return "", nil
+	}
+	parsed, err := time.Parse(time.RFC3339, expiresAt)
+	if err != nil {
+		return "", err
+	}
+	return parsed.UTC().Format(time.RFC3339), nil
+}
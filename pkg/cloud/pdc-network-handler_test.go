@@ -0,0 +1,190 @@
+package cloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCloud is an in-memory Grafana Cloud API, listing items one per page
+type fakeCloud struct {
+	mu       sync.Mutex
+	policies []AccessPolicy
+	tokens   []Token
+	nextID   int
+}
+
+func (f *fakeCloud) id() string {
+	f.nextID++
+	return fmt.Sprintf("id-%d", f.nextID)
+}
+
+func (f *fakeCloud) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Query().Get("region") != "prod-eu-west-0" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/")
+	endpoint, id, _ := strings.Cut(path, "/")
+	switch {
+	case r.Method == http.MethodGet && endpoint == "accesspolicies":
+		writePage(w, r, f.policies)
+	case r.Method == http.MethodGet && endpoint == "tokens":
+		var tokens []Token
+		for _, token := range f.tokens {
+			if token.AccessPolicyID == r.URL.Query().Get("accessPolicyId") {
+				tokens = append(tokens, token)
+			}
+		}
+		writePage(w, r, tokens)
+	case r.Method == http.MethodPost && endpoint == "accesspolicies":
+		var policy AccessPolicy
+		_ = json.NewDecoder(r.Body).Decode(&policy)
+		if id == "" {
+			policy.ID = f.id()
+			f.policies = append(f.policies, policy)
+		} else {
+			for i := range f.policies {
+				if f.policies[i].ID == id {
+					policy.ID = id
+					f.policies[i] = policy
+				}
+			}
+		}
+		_ = json.NewEncoder(w).Encode(policy)
+	case r.Method == http.MethodPost && endpoint == "tokens":
+		var token Token
+		_ = json.NewDecoder(r.Body).Decode(&token)
+		token.ID = f.id()
+		f.tokens = append(f.tokens, token)
+		token.Token = "glc_" + token.Name
+		_ = json.NewEncoder(w).Encode(token)
+	case r.Method == http.MethodDelete && endpoint == "accesspolicies":
+		for i, policy := range f.policies {
+			if policy.ID == id {
+				f.policies = append(f.policies[:i], f.policies[i+1:]...)
+				break
+			}
+		}
+	case r.Method == http.MethodDelete && endpoint == "tokens":
+		for i, token := range f.tokens {
+			if token.ID == id {
+				f.tokens = append(f.tokens[:i], f.tokens[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func writePage[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	var index int
+	_, _ = fmt.Sscan(r.URL.Query().Get("pageCursor"), &index)
+	response := page[T]{Items: []T{}}
+	if index < len(items) {
+		response.Items = items[index : index+1]
+	}
+	if index+1 < len(items) {
+		query := r.URL.Query()
+		query.Del("region")
+		query.Set("pageCursor", fmt.Sprint(index+1))
+		response.Metadata.Pagination.NextPage = r.URL.Path + "?" + query.Encode()
+	}
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func TestPDCNetworkHandler(t *testing.T) {
+	fake := &fakeCloud{policies: []AccessPolicy{
+		{ID: "other-stack", Name: "elsewhere", Scopes: []string{pdcScope}, Realms: []Realm{{Type: "stack", Identifier: "2"}}},
+		{ID: "metrics", Name: "metrics-writer", Scopes: []string{"metrics:write"}, Realms: []Realm{{Type: "stack", Identifier: "1"}}},
+	}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	secrets := t.TempDir()
+	grizzly.ConfigureSecretSink(grizzly.DirectorySecretSink{Directory: secrets})
+	defer grizzly.ConfigureSecretSink(nil)
+
+	provider := NewProvider(&config.CloudConfig{URL: server.URL, Token: "token", Region: "prod-eu-west-0", StackID: 1})
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+	handler := NewPDCNetworkHandler(provider)
+
+	network, err := grizzly.NewResource(provider.APIVersion(), PDCNetworkKind, "on-prem", map[string]any{
+		"tokens": []any{
+			map[string]any{"name": "dc2-agent", "expiresAt": "2027-01-01T01:00:00+01:00"},
+			map[string]any{"name": "dc1-agent"},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("networks are added with the tokens of their agents", func(t *testing.T) {
+		var out strings.Builder
+		recorder := grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText)
+		require.NoError(t, grizzly.Apply(registry, grizzly.NewResources(network), grizzly.ApplyOptions{}, recorder))
+		require.Equal(t, 1, recorder.Summary().EventCounts[grizzly.ResourceAdded], out.String())
+
+		for _, name := range []string{"dc1-agent", "dc2-agent"} {
+			secret, err := os.ReadFile(filepath.Join(secrets, "PDCNetwork.on-prem."+name))
+			require.NoError(t, err)
+			require.Equal(t, "glc_"+name, string(secret))
+		}
+	})
+
+	t.Run("only the networks of the stack are listed", func(t *testing.T) {
+		names, err := handler.ListRemote()
+		require.NoError(t, err)
+		require.Equal(t, []string{"on-prem"}, names)
+
+		remote, err := handler.GetByUID("on-prem")
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{
+			"name":        "on-prem",
+			"displayName": "on-prem",
+			"tokens": []any{
+				map[string]any{"name": "dc1-agent"},
+				map[string]any{"name": "dc2-agent", "expiresAt": "2027-01-01T00:00:00Z"},
+			},
+		}, remote.Spec())
+	})
+
+	t.Run("applying again changes nothing", func(t *testing.T) {
+		var out strings.Builder
+		recorder := grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText)
+		require.NoError(t, grizzly.Apply(registry, grizzly.NewResources(network), grizzly.ApplyOptions{}, recorder))
+		require.Equal(t, 1, recorder.Summary().EventCounts[grizzly.ResourceNotChanged], out.String())
+	})
+
+	t.Run("removed tokens are revoked", func(t *testing.T) {
+		updated := network.DeepCopy()
+		updated.SetSpecValue("tokens", []any{
+			map[string]any{"name": "dc1-agent"},
+			map[string]any{"name": "dc3-agent"},
+		})
+		require.NoError(t, handler.Update(network, updated))
+
+		var names []string
+		for _, token := range fake.tokens {
+			names = append(names, token.Name)
+		}
+		require.ElementsMatch(t, []string{"dc1-agent", "dc3-agent"}, names)
+		require.FileExists(t, filepath.Join(secrets, "PDCNetwork.on-prem.dc3-agent"))
+	})
+
+	t.Run("tokens must expire at a valid time", func(t *testing.T) {
+		broken := network.DeepCopy()
+		broken.SetSpecValue("tokens", []any{map[string]any{"name": "dc1-agent", "expiresAt": "next year"}})
+		require.EqualError(t, handler.Validate(broken), "expiresAt of token 'dc1-agent' of PDCNetwork.on-prem should be a time such as 2027-01-01T00:00:00Z")
+	})
+}
@@ -0,0 +1,90 @@
+package cloud
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+// Provider is a grizzly.Provider implementation for the Grafana Cloud API,
+// managing the resources of a stack living outside of it.
+type Provider struct {
+	config    *config.CloudConfig
+	transport http.RoundTripper
+}
+
+// NewProvider instantiates a new Provider.
+func NewProvider(config *config.CloudConfig) *Provider {
+	return &Provider{
+		config: config,
+	}
+}
+
+// WithTransport sends the requests of the provider through a transport of
+// its own, such as one signing requests or recording them
+func (p *Provider) WithTransport(transport http.RoundTripper) *Provider {
+	p.transport = transport
+	return p
+}
+
+func (p *Provider) Validate() error {
+	if p.config.Token == "" {
+		return fmt.Errorf("cloud token is not set")
+	}
+	if p.config.Region == "" {
+		return fmt.Errorf("cloud region is not set")
+	}
+	if p.config.StackID == 0 {
+		return fmt.Errorf("cloud stack id is not set")
+	}
+	return nil
+}
+
+func (p *Provider) Status() grizzly.ProviderStatus {
+	status := grizzly.ProviderStatus{}
+
+	if err := p.Validate(); err != nil {
+		status.ActiveReason = err.Error()
+		return status
+	}
+
+	status.Active = true
+
+	if _, err := p.listPDCNetworks(); err != nil {
+		status.OnlineReason = err.Error()
+		return status
+	}
+
+	status.Online = true
+
+	return status
+}
+
+func (p *Provider) Name() string {
+	return "Grafana Cloud"
+}
+
+// Group returns the group name of the Grafana Cloud provider
+func (p *Provider) Group() string {
+	return "grizzly.grafana.com"
+}
+
+// Version returns the version of this provider
+func (p *Provider) Version() string {
+	return "v1alpha1"
+}
+
+// APIVersion returns the group and version of this provider
+func (p *Provider) APIVersion() string {
+	return filepath.Join(p.Group(), p.Version())
+}
+
+// GetHandlers identifies the handlers for the Grafana Cloud provider
+func (p *Provider) GetHandlers() []grizzly.Handler {
+	return []grizzly.Handler{
+		NewPDCNetworkHandler(p),
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -19,8 +21,14 @@ import (
 const (
 	CurrentContextSetting   = "current-context"
 	DisableReportingSetting = "disable-reporting"
+	EnvironmentsSetting     = "environments"
 )
 
+// environmentContext is the context selected for the running command, directly
+// or through an environment, which overrides the current context without
+// being written
+var environmentContext string
+
 // Version is the current version of the grr command.
 // To be overwritten at build time
 var Version = "dev"
@@ -46,10 +54,28 @@ func override(v *viper.Viper) {
 		"synthetic-monitoring.metrics-id":   "GRAFANA_SM_METRICS_ID",
 		"synthetic-monitoring.url":          "GRAFANA_SM_URL",
 
-		"mimir.address":    "MIMIR_ADDRESS",
-		"mimir.tenant-id":  "MIMIR_TENANT_ID",
-		"mimir.api-key":    "MIMIR_API_KEY",
-		"mimir.auth-token": "MIMIR_AUTH_TOKEN",
+		"frontend-observability.url":      "GRAFANA_FARO_URL",
+		"frontend-observability.stack-id": "GRAFANA_FARO_STACK_ID",
+		"frontend-observability.token":    "GRAFANA_FARO_TOKEN",
+
+		"oncall.url":   "GRAFANA_ONCALL_URL",
+		"oncall.token": "GRAFANA_ONCALL_TOKEN",
+
+		"cloud.url":      "GRAFANA_CLOUD_URL",
+		"cloud.token":    "GRAFANA_CLOUD_TOKEN",
+		"cloud.region":   "GRAFANA_CLOUD_REGION",
+		"cloud.stack-id": "GRAFANA_CLOUD_STACK_ID",
+
+		"mimir.address":              "MIMIR_ADDRESS",
+		"mimir.tenant-id":            "MIMIR_TENANT_ID",
+		"mimir.api-key":              "MIMIR_API_KEY",
+		"mimir.auth-token":           "MIMIR_AUTH_TOKEN",
+		"mimir.alertmanager-address": "MIMIR_ALERTMANAGER_ADDRESS",
+
+		"loki.address":    "LOKI_ADDRESS",
+		"loki.tenant-id":  "LOKI_TENANT_ID",
+		"loki.api-key":    "LOKI_API_KEY",
+		"loki.auth-token": "LOKI_AUTH_TOKEN",
 	}
 
 	// To keep retro compatibility
@@ -90,7 +116,10 @@ func Read() error {
 	return nil
 }
 
+// Mock sets configuration values, as if read from the configuration file, and
+// clears the context selected for the running command, if any
 func Mock(values map[string]interface{}) {
+	environmentContext = ""
 	for k, v := range values {
 		viper.Set(k, v)
 	}
@@ -134,6 +163,35 @@ func GetContexts() ([]string, error) {
 	return keys, nil
 }
 
+// SelectContexts returns the names of the contexts matching patterns, in the
+// order of the patterns. Patterns are names, or globs such as prod-*.
+func SelectContexts(patterns []string) ([]string, error) {
+	contexts, err := GetContexts()
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []string
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid context pattern %s: %w", pattern, err)
+		}
+		matched := false
+		for _, context := range contexts {
+			if ok, _ := path.Match(pattern, context); ok {
+				matched = true
+				if !slices.Contains(selected, context) {
+					selected = append(selected, context)
+				}
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("no context matches %s", pattern)
+		}
+	}
+	return selected, nil
+}
+
 func UseContext(context string) error {
 	contexts := map[string]interface{}{}
 	if err := viper.UnmarshalKey("contexts", &contexts); err != nil {
@@ -148,22 +206,109 @@ func UseContext(context string) error {
 	return fmt.Errorf("context %s not found", context)
 }
 
+// GetEnvironments returns the names of the configured environments
+func GetEnvironments() ([]string, error) {
+	environments := map[string]any{}
+	if err := viper.UnmarshalKey(EnvironmentsSetting, &environments); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(environments))
+	for name := range environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// GetEnvironment returns the configuration of an environment
+func GetEnvironment(name string) (*Environment, error) {
+	settings := viper.Sub(fmt.Sprintf("%s.%s", EnvironmentsSetting, name))
+	if settings == nil {
+		return nil, fmt.Errorf("environment %s not found", name)
+	}
+	var environment Environment
+	if err := settings.Unmarshal(&environment); err != nil {
+		return nil, err
+	}
+	environment.Name = name
+
+	if environment.Context == "" {
+		return nil, fmt.Errorf("environment %s has no context", name)
+	}
+	contexts, err := GetContexts()
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(contexts, environment.Context) {
+		return nil, fmt.Errorf("context %s of environment %s not found", environment.Context, name)
+	}
+	return &environment, nil
+}
+
+// UseEnvironment selects the context of an environment for the running
+// command. Unlike UseContext, the selection isn't written to the
+// configuration.
+func UseEnvironment(name string) (*Environment, error) {
+	environment, err := GetEnvironment(name)
+	if err != nil {
+		return nil, err
+	}
+	environmentContext = environment.Context
+	return environment, nil
+}
+
+// UseContextFor selects a context for the running command. Unlike UseContext,
+// the selection isn't written to the configuration.
+func UseContextFor(name string) error {
+	contexts, err := GetContexts()
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(contexts, name) {
+		return fmt.Errorf("context %s not found", name)
+	}
+	environmentContext = name
+	return nil
+}
+
 func UsageStatsDisabled() bool {
 	return viper.GetBool(DisableReportingSetting)
 }
 
 func CurrentContext() (*Context, error) {
-	name := viper.GetString(CurrentContextSetting)
+	name := environmentContext
+	if name == "" {
+		name = viper.GetString(CurrentContextSetting)
+	}
 	if name == "" {
 		NewConfig()
 		return CurrentContext()
 	}
+	return readContext(name, true)
+}
+
+// GetContext returns the configuration of a context. Unlike CurrentContext,
+// the configuration isn't overridden by environment variables.
+func GetContext(name string) (*Context, error) {
+	contexts, err := GetContexts()
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(contexts, name) {
+		return nil, fmt.Errorf("context %s not found", name)
+	}
+	return readContext(name, false)
+}
+
+func readContext(name string, withOverrides bool) (*Context, error) {
 	contextPath := fmt.Sprintf("contexts.%s", name)
 	ctx := viper.Sub(contextPath)
 	if ctx == nil {
 		ctx = viper.New()
 	}
-	override(ctx)
+	if withOverrides {
+		override(ctx)
+	}
 	var context Context
 	if err := ctx.Unmarshal(&context); err != nil {
 		return nil, err
@@ -178,19 +323,91 @@ var acceptableKeys = map[string]string{
 	"grafana.user":                      "string",
 	"grafana.insecure-skip-verify":      "bool",
 	"grafana.tls-host":                  "string",
+	"grafana.api-mode":                  "string",
+	"grafana.namespace":                 "string",
 	"mimir.address":                     "string",
 	"mimir.tenant-id":                   "string",
 	"mimir.api-key":                     "string",
 	"mimir.auth-token":                  "string",
+	"mimir.alertmanager-address":        "string",
+	"loki.address":                      "string",
+	"loki.tenant-id":                    "string",
+	"loki.api-key":                      "string",
+	"loki.auth-token":                   "string",
 	"synthetic-monitoring.access-token": "string",
 	"synthetic-monitoring.token":        "string",
 	"synthetic-monitoring.stack-id":     "int",
 	"synthetic-monitoring.metrics-id":   "int",
 	"synthetic-monitoring.logs-id":      "int",
 	"synthetic-monitoring.url":          "string",
+	"frontend-observability.url":        "string",
+	"frontend-observability.stack-id":   "int",
+	"frontend-observability.token":      "string",
+	"oncall.url":                        "string",
+	"oncall.token":                      "string",
+	"cloud.url":                         "string",
+	"cloud.token":                       "string",
+	"cloud.region":                      "string",
+	"cloud.stack-id":                    "int",
 	"targets":                           "[]string",
 	"output-format":                     "string",
 	"only-spec":                         "bool",
+	"max-payload-size":                  "string",
+	"maintenance.duration":              "string",
+	"maintenance.matchers":              "[]string",
+	"maintenance.mute-timings":          "[]string",
+	"maintenance.rule-groups":           "[]string",
+	"annotations.dashboard":             "string",
+	"annotations.dashboardfolder":       "string",
+	"annotations.alertrulegroup":        "string",
+	"git-provenance":                    "bool",
+	"track-resources":                   "bool",
+	"deploy-annotations":                "bool",
+	"yaml.indent":                       "int",
+	"yaml.long-strings":                 "string",
+	"yaml.long-string-width":            "int",
+	"yaml.key-order":                    "string",
+	"yaml.quote":                        "string",
+	"linters.promql":                    "string",
+	"linters.logql":                     "string",
+	"linters.traceql":                   "string",
+	"linters.sql":                       "string",
+	"protected-kinds":                   "[]string",
+	"defaults.dashboard":                "[]string",
+	"defaults.dashboardfolder":          "[]string",
+	"defaults.alertrulegroup":           "[]string",
+	"defaults.prometheusrulegroup":      "[]string",
+	"defaults.lokirulegroup":            "[]string",
+	"defaults.syntheticmonitoringcheck": "[]string",
+	"pull-prune.dashboard":              "[]string",
+	"pull-prune.dashboardfolder":        "[]string",
+	"pull-prune.alertrulegroup":         "[]string",
+	"pull-prune.datasource":             "[]string",
+	"rule-intervals.min":                "string",
+	"rule-intervals.scrape-interval":    "string",
+	"difftool":                          "string",
+	"diff-summary-size":                 "string",
+	"filenames":                         "string",
+	"path-template":                     "string",
+	"low-priority.enabled":              "bool",
+	"low-priority.requests-per-second":  "float",
+	"low-priority.jitter":               "string",
+	"low-priority.retries":              "int",
+	"retry.disabled":                    "bool",
+	"retry.retries":                     "int",
+	"retry.min-backoff":                 "string",
+	"retry.max-backoff":                 "string",
+	"retry.jitter":                      "string",
+	"http.proxy":                        "string",
+	"http.no-proxy":                     "string",
+	"http.ca-path":                      "string",
+	"http.insecure-skip-verify":         "bool",
+	"http.compress-requests":            "bool",
+	"remote-cache.enabled":              "bool",
+	"remote-cache.path":                 "string",
+	"secret-sink.directory":             "string",
+	"secret-sink.command":               "string",
+	"plugins.directory":                 "string",
 }
 
 func Hash() (string, error) {
@@ -268,6 +485,12 @@ func Set(path string, value string) error {
 					return fmt.Errorf("key %s should be an integer: %s", key, err)
 				}
 				val = intValue
+			case "float":
+				floatValue, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					return fmt.Errorf("key %s should be a number: %s", key, err)
+				}
+				val = floatValue
 			default:
 				return fmt.Errorf("unknown config key type %s for key %s", typ, key)
 			}
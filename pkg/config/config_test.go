@@ -0,0 +1,27 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUseContextFor(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	NewConfig()
+	Mock(map[string]any{"contexts.prod.name": "prod"})
+	t.Cleanup(func() { environmentContext = "" })
+
+	require.NoError(t, UseContextFor("prod"))
+	current, err := CurrentContext()
+	require.NoError(t, err)
+	require.Equal(t, "prod", current.Name)
+	require.Equal(t, "default", viper.GetString(CurrentContextSetting), "the selection isn't written to the configuration")
+
+	require.EqualError(t, UseContextFor("missing"), "context missing not found")
+	current, err = CurrentContext()
+	require.NoError(t, err)
+	require.Equal(t, "prod", current.Name, "unknown contexts leave the selection as it was")
+}
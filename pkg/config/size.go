@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxPayloadSize is the payload size above which a warning is emitted
+// when no limit is configured. Gateways in front of Grafana Cloud commonly
+// reject larger requests.
+const DefaultMaxPayloadSize = 10 * 1000 * 1000
+
+// DefaultDiffSummarySize is the size above which resources are diffed as a
+// summary of their changes, such as large generated dashboards whose diffs
+// would flood CI logs
+const DefaultDiffSummarySize = 500 * 1000
+
+var sizeUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	// Longest suffixes first, so that "KiB" isn't read as "B"
+	{"KiB", 1 << 10},
+	{"MiB", 1 << 20},
+	{"GiB", 1 << 30},
+	{"KB", 1000},
+	{"MB", 1000 * 1000},
+	{"GB", 1000 * 1000 * 1000},
+	{"K", 1000},
+	{"M", 1000 * 1000},
+	{"G", 1000 * 1000 * 1000},
+	{"B", 1},
+}
+
+// ParseSize parses a human readable size, such as 512KB or 10MiB, into bytes.
+// Sizes without a unit are in bytes.
+func ParseSize(input string) (int64, error) {
+	size := strings.TrimSpace(input)
+
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(strings.ToUpper(size), strings.ToUpper(unit.suffix)) {
+			multiplier = unit.multiplier
+			size = strings.TrimSpace(size[:len(size)-len(unit.suffix)])
+			break
+		}
+	}
+
+	value, err := strconv.ParseFloat(size, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size %q, expected a number of bytes optionally followed by a unit such as KB, MB or MiB", input)
+	}
+
+	return int64(value * float64(multiplier)), nil
+}
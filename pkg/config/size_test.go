@@ -0,0 +1,50 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSize(t *testing.T) {
+	for input, expected := range map[string]int64{
+		"0":        0,
+		"512":      512,
+		"512B":     512,
+		"512KB":    512 * 1000,
+		"512kb":    512 * 1000,
+		"1.5MB":    1500 * 1000,
+		"10MiB":    10 << 20,
+		"1KiB":     1024,
+		"2GiB":     2 << 30,
+		"1G":       1000 * 1000 * 1000,
+		"20M":      20 * 1000 * 1000,
+		" 10 MB ":  10 * 1000 * 1000,
+		"3 K":      3000,
+		"0.5 GB":   500 * 1000 * 1000,
+		"100 B":    100,
+		"1.25 KiB": 1280,
+	} {
+		size, err := ParseSize(input)
+		require.NoError(t, err, input)
+		require.Equal(t, expected, size, input)
+	}
+
+	for _, input := range []string{"", "MB", "ten", "-1MB", "10TB", "10 MB MB"} {
+		_, err := ParseSize(input)
+		require.ErrorContains(t, err, "invalid size", input)
+	}
+}
+
+func TestPayloadSizeLimit(t *testing.T) {
+	limit, err := Context{}.PayloadSizeLimit()
+	require.NoError(t, err)
+	require.EqualValues(t, DefaultMaxPayloadSize, limit)
+
+	limit, err = Context{MaxPayloadSize: "20MB"}.PayloadSizeLimit()
+	require.NoError(t, err)
+	require.EqualValues(t, 20*1000*1000, limit)
+
+	_, err = Context{MaxPayloadSize: "lots"}.PayloadSizeLimit()
+	require.Error(t, err)
+}
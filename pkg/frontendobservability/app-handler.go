@@ -0,0 +1,228 @@
+package frontendobservability
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
+)
+
+const FrontendObservabilityAppKind = "FrontendObservabilityApp"
+
+var _ grizzly.Handler = &AppHandler{}
+var _ grizzly.ErrorHinter = &AppHandler{}
+
+// AppHandler is a Grizzly Handler for the apps of Grafana Cloud Frontend
+// Observability, which the Faro SDK of web applications sends telemetry as
+type AppHandler struct {
+	grizzly.BaseHandler
+	provider *Provider
+}
+
+// NewAppHandler returns a Grizzly Handler for Frontend Observability apps
+func NewAppHandler(provider *Provider) *AppHandler {
+	return &AppHandler{
+		BaseHandler: grizzly.NewBaseHandler(provider, FrontendObservabilityAppKind, false).WithListCache(),
+		provider:    provider,
+	}
+}
+
+const (
+	frontendObservabilityAppPattern = "frontend-observability/app-%s.%s"
+)
+
+// ErrorHint implements grizzly.ErrorHinter
+func (h *AppHandler) ErrorHint(code grizzly.ErrorCode) string {
+	switch code {
+	case grizzly.ErrorCodeAuthFailed:
+		return "check frontend-observability.stack-id and frontend-observability.token with `grr config check`"
+	case grizzly.ErrorCodePermissionDenied:
+		return "the token lacks the frontend-observability:read or frontend-observability:write scopes"
+	case grizzly.ErrorCodeVersionUnsupported:
+		return "the apps API wasn't found, check that frontend-observability.url is the Frontend Observability API of the stack, ending with /faro"
+	default:
+		return ""
+	}
+}
+
+// ResourceFilePath returns the location on disk where a resource should be updated
+func (h *AppHandler) ResourceFilePath(resource grizzly.Resource, filetype string) string {
+	filename := strings.ReplaceAll(resource.Name(), string(os.PathSeparator), "-")
+	return fmt.Sprintf(frontendObservabilityAppPattern, filename, filetype)
+}
+
+// Unprepare removes unnecessary elements from a remote resource ready for presentation/comparison
+func (h *AppHandler) Unprepare(resource grizzly.Resource) *grizzly.Resource {
+	for _, key := range []string{"id", "appKey", "collectEndpointURL"} {
+		resource.DeleteSpecKey(key)
+	}
+	return &resource
+}
+
+// Prepare gets a resource ready for dispatch to the remote endpoint
+func (h *AppHandler) Prepare(existing *grizzly.Resource, resource grizzly.Resource) *grizzly.Resource {
+	if _, exists := resource.GetSpecString("name"); !exists {
+		resource.SetSpecString("name", resource.Name())
+	}
+	return &resource
+}
+
+// Validate checks that the name of the app matches its metadata, and that
+// its allowed origins are URLs
+func (h *AppHandler) Validate(resource grizzly.Resource) error {
+	name, exist := resource.GetSpecString("name")
+	if exist && name != resource.Name() {
+		return fmt.Errorf("name '%s' and metadata name '%s', don't match", name, resource.Name())
+	}
+	app, err := h.specToApp(resource)
+	if err != nil {
+		return err
+	}
+	for _, origin := range app.CORSOrigins {
+		parsed, err := url.Parse(origin.URL)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("allowed origin '%s' of %s should be a URL, such as https://app.example.com", origin.URL, resource.Ref())
+		}
+	}
+	return nil
+}
+
+// GetUID returns the UID for a resource
+func (h *AppHandler) GetUID(resource grizzly.Resource) (string, error) {
+	return resource.Name(), nil
+}
+
+func (h *AppHandler) GetSpecUID(resource grizzly.Resource) (string, error) {
+	name, _ := resource.GetSpecString("name")
+	return name, nil
+}
+
+// GetByUID retrieves JSON for a resource from an endpoint, by UID
+func (h *AppHandler) GetByUID(uid string) (*grizzly.Resource, error) {
+	app, err := h.getApp(uid)
+	if err != nil {
+		return nil, err
+	}
+	return h.appToResource(app)
+}
+
+// GetRemote retrieves an app as a Resource
+func (h *AppHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
+	return h.GetByUID(resource.Name())
+}
+
+// ListRemote retrieves as list of UIDs of all remote resources
+func (h *AppHandler) ListRemote() ([]string, error) {
+	apps, err := h.listApps()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(apps))
+	for _, app := range apps {
+		names = append(names, app.Name)
+	}
+	return names, nil
+}
+
+// Add creates an app. The endpoint the app sends its telemetry to is only
+// known once created, so it is reported for the Faro SDK to be configured with.
+func (h *AppHandler) Add(resource grizzly.Resource) error {
+	app, err := h.specToApp(resource)
+	if err != nil {
+		return err
+	}
+	created, err := h.provider.createApp(app)
+	if err != nil {
+		return err
+	}
+	h.InvalidateListCache()
+	if created.CollectEndpointURL != "" {
+		notifier.InfoStderr(resource, fmt.Sprintf("the app sends its telemetry to %s", created.CollectEndpointURL))
+	}
+	return nil
+}
+
+// Update pushes an updated app to the Frontend Observability API
+func (h *AppHandler) Update(existing, resource grizzly.Resource) error {
+	remote, err := h.getApp(resource.Name())
+	if err != nil {
+		return err
+	}
+	app, err := h.specToApp(resource)
+	if err != nil {
+		return err
+	}
+	app.ID = remote.ID
+
+	if err := h.provider.updateApp(app); err != nil {
+		return err
+	}
+	h.InvalidateListCache()
+	return nil
+}
+
+// Delete removes an app, and the collector it sends telemetry to
+func (h *AppHandler) Delete(resource grizzly.Resource) error {
+	remote, err := h.getApp(resource.Name())
+	if err != nil {
+		return err
+	}
+	if err := h.provider.deleteApp(remote.ID); err != nil {
+		return err
+	}
+	h.InvalidateListCache()
+	return nil
+}
+
+// listApps retrieves the apps of the stack, cached for the run
+func (h *AppHandler) listApps() ([]App, error) {
+	return grizzly.CachedList(&h.BaseHandler, "apps", h.provider.listApps)
+}
+
+func (h *AppHandler) getApp(name string) (App, error) {
+	apps, err := h.listApps()
+	if err != nil {
+		return App{}, err
+	}
+	for _, app := range apps {
+		if app.Name == name {
+			return app, nil
+		}
+	}
+	return App{}, grizzly.ErrNotFound
+}
+
+func (h *AppHandler) appToResource(app App) (*grizzly.Resource, error) {
+	data, err := json.Marshal(app)
+	if err != nil {
+		return nil, err
+	}
+	var specmap map[string]interface{}
+	if err := json.Unmarshal(data, &specmap); err != nil {
+		return nil, err
+	}
+	resource, err := grizzly.NewResource(h.APIVersion(), h.Kind(), app.Name, specmap)
+	if err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+func (h *AppHandler) specToApp(resource grizzly.Resource) (App, error) {
+	var app App
+	data, err := json.Marshal(resource.Spec())
+	if err != nil {
+		return app, err
+	}
+	if err := json.Unmarshal(data, &app); err != nil {
+		return app, fmt.Errorf("input file is invalid: %v", err)
+	}
+	app.Name = resource.Name()
+	// the collector of the app is assigned by the API
+	app.ID, app.AppKey, app.CollectEndpointURL = 0, "", ""
+	return app, nil
+}
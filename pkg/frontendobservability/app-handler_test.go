@@ -0,0 +1,95 @@
+package frontendobservability
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppHandler(t *testing.T) {
+	apps := []App{{
+		ID:                 7,
+		Name:               "shop",
+		CORSOrigins:        []AllowedOrigin{{URL: "https://shop.example.com"}},
+		ExtraLogLabels:     map[string]string{"team": "web"},
+		AppKey:             "abc123",
+		CollectEndpointURL: "https://faro-collector.grafana.net/collect/abc123",
+	}}
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer 42:token", r.Header.Get("Authorization"))
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.Method + " " + r.URL.Path {
+		case "GET /faro/api/v1/app":
+			require.NoError(t, json.NewEncoder(w).Encode(apps))
+		case "POST /faro/api/v1/app", "PUT /faro/api/v1/app/7":
+			var app App
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&app))
+			require.Zero(t, app.AppKey, "collectors are assigned by the API")
+			app.ID = 8
+			require.NoError(t, json.NewEncoder(w).Encode(app))
+		case "DELETE /faro/api/v1/app/7":
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewProvider(&config.FrontendObservabilityConfig{URL: server.URL + "/faro/", StackID: 42, Token: "token"})
+	handler := NewAppHandler(provider)
+
+	t.Run("apps are listed by name", func(t *testing.T) {
+		names, err := handler.ListRemote()
+		require.NoError(t, err)
+		require.Equal(t, []string{"shop"}, names)
+
+		_, err = handler.GetByUID("blog")
+		require.ErrorIs(t, err, grizzly.ErrNotFound)
+	})
+
+	t.Run("collectors of apps are removed", func(t *testing.T) {
+		remote, err := handler.GetByUID("shop")
+		require.NoError(t, err)
+
+		spec := handler.Unprepare(*remote).Spec()
+		require.Equal(t, "shop", spec["name"])
+		require.Equal(t, map[string]any{"team": "web"}, spec["extraLogLabels"])
+		for _, key := range []string{"id", "appKey", "collectEndpointURL"} {
+			require.NotContains(t, spec, key)
+		}
+	})
+
+	t.Run("apps are added, updated and deleted", func(t *testing.T) {
+		resource, err := grizzly.NewResource(provider.APIVersion(), FrontendObservabilityAppKind, "shop", map[string]any{
+			"corsOrigins": []any{map[string]any{"url": "https://shop.example.com"}, map[string]any{"url": "https://checkout.example.com"}},
+			"appKey":      "stale",
+		})
+		require.NoError(t, err)
+		require.NoError(t, handler.Validate(resource))
+
+		requests = nil
+		require.NoError(t, handler.Add(resource))
+		require.NoError(t, handler.Update(resource, resource))
+		require.NoError(t, handler.Delete(resource))
+		require.Equal(t, []string{
+			"POST /faro/api/v1/app",
+			"GET /faro/api/v1/app",
+			"PUT /faro/api/v1/app/7",
+			"GET /faro/api/v1/app",
+			"DELETE /faro/api/v1/app/7",
+		}, requests)
+	})
+
+	t.Run("allowed origins must be URLs", func(t *testing.T) {
+		resource, err := grizzly.NewResource(provider.APIVersion(), FrontendObservabilityAppKind, "shop", map[string]any{
+			"corsOrigins": []any{map[string]any{"url": "shop.example.com"}},
+		})
+		require.NoError(t, err)
+		require.EqualError(t, handler.Validate(resource), "allowed origin 'shop.example.com' of FrontendObservabilityApp.shop should be a URL, such as https://app.example.com")
+	})
+}
@@ -0,0 +1,93 @@
+package frontendobservability
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/grafana/grizzly/internal/httputils"
+)
+
+const appsEndpoint = "/api/v1/app"
+
+// App is a frontend application sending its telemetry to the Faro collector
+// of the stack
+type App struct {
+	ID   int64  `json:"id,omitempty"`
+	Name string `json:"name"`
+	// CORSOrigins are the origins the app is allowed to send telemetry from
+	CORSOrigins    []AllowedOrigin   `json:"corsOrigins"`
+	ExtraLogLabels map[string]string `json:"extraLogLabels,omitempty"`
+	Settings       map[string]string `json:"settings,omitempty"`
+	// AppKey and CollectEndpointURL are set by the API, and identify the
+	// collector the app sends telemetry to
+	AppKey             string `json:"appKey,omitempty"`
+	CollectEndpointURL string `json:"collectEndpointURL,omitempty"`
+}
+
+// AllowedOrigin is an origin an app is allowed to send telemetry from
+type AllowedOrigin struct {
+	URL string `json:"url"`
+}
+
+// StatusError is returned when the Frontend Observability API responds with an unsuccessful status
+type StatusError = httputils.StatusError
+
+func (p *Provider) listApps() ([]App, error) {
+	var apps []App
+	if err := p.doRequest(http.MethodGet, appsEndpoint, nil, &apps); err != nil {
+		return nil, err
+	}
+	return apps, nil
+}
+
+func (p *Provider) createApp(app App) (App, error) {
+	var created App
+	if err := p.doRequest(http.MethodPost, appsEndpoint, app, &created); err != nil {
+		return App{}, err
+	}
+	return created, nil
+}
+
+func (p *Provider) updateApp(app App) error {
+	return p.doRequest(http.MethodPut, fmt.Sprintf("%s/%d", appsEndpoint, app.ID), app, nil)
+}
+
+func (p *Provider) deleteApp(id int64) error {
+	return p.doRequest(http.MethodDelete, fmt.Sprintf("%s/%d", appsEndpoint, id), nil, nil)
+}
+
+// doRequest sends a request to the API, authenticated as the stack, and
+// decodes its response into result, when given
+func (p *Provider) doRequest(method, path string, body, result any) error {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(p.config.URL, "/")+path, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %d:%s", p.config.StackID, p.config.Token))
+
+	client, err := httputils.NewHTTPClientWithTransport(p.transport)
+	if err != nil {
+		return err
+	}
+	data, err := httputils.Do(client, req, "the Frontend Observability API")
+	if err != nil {
+		return err
+	}
+	if result == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, result)
+}
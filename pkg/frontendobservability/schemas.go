@@ -0,0 +1,19 @@
+package frontendobservability
+
+import (
+	"embed"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+// schemas documents the fields of the kinds of the provider, one file per kind
+//
+//go:embed schemas/*.yaml
+var schemas embed.FS
+
+var _ grizzly.SchemaDescriber = &AppHandler{}
+
+// Schema implements grizzly.SchemaDescriber
+func (h *AppHandler) Schema() (*grizzly.Schema, error) {
+	return grizzly.LoadSchema(schemas, h.Kind())
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana-openapi-client-go/client/provisioning"
 	"github.com/grafana/grafana-openapi-client-go/models"
@@ -16,10 +17,17 @@ const AlertRuleGroupKind = "AlertRuleGroup"
 
 var _ grizzly.Handler = &AlertRuleGroupHandler{}
 var _ grizzly.ProxyConfiguratorProvider = &AlertRuleGroupHandler{}
+var _ grizzly.RuleGroupHandler = &AlertRuleGroupHandler{}
 
 // AlertRuleGroupHandler is a Grizzly Handler for Grafana alertRuleGroups
 type AlertRuleGroupHandler struct {
 	grizzly.BaseHandler
+
+	// remoteDashboards caches the dashboards looked up when validating links, by UID
+	remoteDashboards    map[string]map[string]any
+	allRemoteDashboards bool
+	// contactPoints caches the contact points looked up when validating links
+	contactPoints contactPoints
 }
 
 // NewAlertRuleGroupHandler returns a new Grizzly Handler for Grafana alertRuleGroups
@@ -29,6 +37,11 @@ func NewAlertRuleGroupHandler(provider grizzly.Provider) *AlertRuleGroupHandler
 	}
 }
 
+// ErrorHint implements grizzly.ErrorHinter
+func (h *AlertRuleGroupHandler) ErrorHint(code grizzly.ErrorCode) string {
+	return permissionsHint(code, "alert.provisioning")
+}
+
 const (
 	alertRuleGroupPattern = "alert-rules/alertRuleGroup-%s.%s"
 )
@@ -64,6 +77,20 @@ func (h *AlertRuleGroupHandler) Validate(resource grizzly.Resource) error {
 	return nil
 }
 
+// EvaluationInterval implements grizzly.RuleGroupHandler. Intervals are set in seconds.
+func (h *AlertRuleGroupHandler) EvaluationInterval(resource grizzly.Resource) (time.Duration, bool, error) {
+	switch interval := resource.GetSpecValue("interval").(type) {
+	case nil:
+		return 0, false, nil
+	case int:
+		return time.Duration(interval) * time.Second, true, nil
+	case float64:
+		return time.Duration(interval) * time.Second, true, nil
+	default:
+		return 0, false, fmt.Errorf("interval should be a number of seconds, got %v", interval)
+	}
+}
+
 func (h *AlertRuleGroupHandler) GetSpecUID(resource grizzly.Resource) (string, error) {
 	name, ok := resource.GetSpecString("name")
 	if !ok {
@@ -97,6 +124,19 @@ func (h *AlertRuleGroupHandler) Update(existing, resource grizzly.Resource) erro
 	return h.putAlertRuleGroup(existing, resource)
 }
 
+// Delete removes a alertRuleGroup, and all its rules, from Grafana via the API
+func (h *AlertRuleGroupHandler) Delete(resource grizzly.Resource) error {
+	folder, group := h.splitUID(resource.Name())
+
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Provisioning.DeleteAlertRuleGroup(group, folder)
+	return err
+}
+
 // getRemoteAlertRuleGroup retrieves a alertRuleGroup object from Grafana
 func (h *AlertRuleGroupHandler) getRemoteAlertRuleGroup(uid string) (*grizzly.Resource, error) {
 	folder, group := h.splitUID(uid)
@@ -176,8 +216,12 @@ func (h *AlertRuleGroupHandler) createAlertRuleGroup(resource grizzly.Resource)
 		return err
 	}
 
+	// rules are identified by UID rather than by group, so that the rules of
+	// a renamed group, or of a group moved to another folder, are moved to it
+	// rather than duplicated
+	adoptAlertRules(&group)
 	for _, r := range group.Rules {
-		if err := h.createAlertRule(r); err != nil {
+		if err := h.updateAlertRule(r); err != nil {
 			return fmt.Errorf("creating rule for group %s: %w", resource.Name(), err)
 		}
 	}
@@ -258,13 +302,23 @@ func fillAlertRuleGroupUIDs(existing, resource grizzly.Resource) (*models.AlertR
 	}
 
 	for _, rule := range updatedGroup.Rules {
-		if uid, ok := t[*rule.Title]; ok {
+		if uid, ok := t[*rule.Title]; ok && rule.UID == "" {
 			rule.UID = uid
 		}
 	}
+	adoptAlertRules(updatedGroup)
 	return updatedGroup, nil
 }
 
+// adoptAlertRules sets the folder and group of the rules of a group, which
+// may still be the former ones of a renamed or moved group
+func adoptAlertRules(group *models.AlertRuleGroup) {
+	for _, rule := range group.Rules {
+		rule.FolderUID = &group.FolderUID
+		rule.RuleGroup = &group.Title
+	}
+}
+
 func (h *AlertRuleGroupHandler) putAlertRuleGroup(existing, resource grizzly.Resource) error {
 	group, err := fillAlertRuleGroupUIDs(existing, resource)
 	if err != nil {
@@ -1,8 +1,12 @@
 package grafana
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/grafana/grizzly/pkg/config"
 	"github.com/grafana/grizzly/pkg/grizzly"
 	"github.com/stretchr/testify/require"
 )
@@ -19,3 +23,53 @@ func TestAlertRuleGroupHandler_ResourceFilePath(t *testing.T) {
 		req.Equal("alert-rules/alertRuleGroup-some-alert-group.yaml", handler.ResourceFilePath(resource, "yaml"))
 	})
 }
+
+func TestAlertRuleGroupHandler_MovedRules(t *testing.T) {
+	var created []string
+	moved := map[string]map[string]any{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/provisioning/alert-rules/{uid}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("uid") != "cpu-uid" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"uid": "cpu-uid", "title": "HighCPU", "folderUID": "infra", "ruleGroup": "cpu"}`))
+	})
+	mux.HandleFunc("PUT /api/v1/provisioning/alert-rules/{uid}", func(w http.ResponseWriter, r *http.Request) {
+		var rule map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&rule))
+		moved[r.PathValue("uid")] = rule
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("POST /api/v1/provisioning/alert-rules", func(w http.ResponseWriter, r *http.Request) {
+		var rule map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&rule))
+		created = append(created, rule["title"].(string))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("PUT /api/v1/provisioning/folder/platform/rule-groups/compute", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mux.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	handler := NewAlertRuleGroupHandler(NewProvider(&config.GrafanaConfig{URL: server.URL}))
+
+	resource, err := grizzly.NewResource(handler.APIVersion(), handler.Kind(), "platform.compute", map[string]any{
+		"title":     "compute",
+		"folderUid": "platform",
+		"rules": []any{
+			map[string]any{"uid": "cpu-uid", "title": "HighCPU", "folderUID": "infra", "ruleGroup": "cpu"},
+			map[string]any{"title": "HighLoad"},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, handler.Add(resource))
+
+	require.Equal(t, []string{"HighLoad"}, created, "rules with a remote UID aren't created again")
+	require.Equal(t, "platform", moved["cpu-uid"]["folderUID"])
+	require.Equal(t, "compute", moved["cpu-uid"]["ruleGroup"])
+}
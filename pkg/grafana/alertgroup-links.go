@@ -0,0 +1,232 @@
+package grafana
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+const (
+	dashboardUIDAnnotation = "__dashboardUid__"
+	panelIDAnnotation      = "__panelId__"
+)
+
+var _ grizzly.LinkValidator = &AlertRuleGroupHandler{}
+
+// linkedPanel identifies a panel an alert rule can be linked to
+type linkedPanel struct {
+	dashboardUID string
+	dashboard    string
+	panelID      string
+}
+
+func (p linkedPanel) String() string {
+	return fmt.Sprintf("panel %s of dashboard %s (%s)", p.panelID, p.dashboardUID, p.dashboard)
+}
+
+// ValidateLinks implements grizzly.LinkValidator, checking that the dashboards
+// and panels alert rules are linked to exist, locally or else remotely, as do
+// the contact points rules send their alerts to. Fixes are suggested by
+// looking up panels titled like the rules.
+func (h *AlertRuleGroupHandler) ValidateLinks(resource grizzly.Resource, local grizzly.Resources) ([]grizzly.BrokenLink, *grizzly.Resource, error) {
+	spec := grizzly.NormaliseJSON(resource.Spec())
+	rules, _ := spec["rules"].([]any)
+
+	var broken []grizzly.BrokenLink
+	fixed := false
+	for i, item := range rules {
+		rule, _ := item.(map[string]any)
+		title, _ := rule["title"].(string)
+
+		settings, _ := rule["notification_settings"].(map[string]any)
+		if receiver, _ := settings["receiver"].(string); receiver != "" {
+			exists, err := h.contactPoints.exists(h.Provider, local, receiver)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !exists {
+				broken = append(broken, grizzly.BrokenLink{
+					Field:   fmt.Sprintf("rules[%d].notification_settings.receiver", i),
+					Message: fmt.Sprintf("rule '%s' sends its alerts to contact point '%s', which doesn't exist", title, receiver),
+				})
+			}
+		}
+
+		annotations, _ := rule["annotations"].(map[string]any)
+		dashboardUID, _ := annotations[dashboardUIDAnnotation].(string)
+		if dashboardUID == "" {
+			continue
+		}
+		field := fmt.Sprintf("rules[%d].annotations", i)
+
+		dashboard, err := h.linkedDashboard(local, dashboardUID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var link grizzly.BrokenLink
+		var candidates []linkedPanel
+		if dashboard == nil {
+			link = grizzly.BrokenLink{
+				Field:   field + "." + dashboardUIDAnnotation,
+				Message: fmt.Sprintf("rule '%s' is linked to dashboard %s, which doesn't exist", title, dashboardUID),
+			}
+			candidates, err = h.panelsTitled(local, title)
+			if err != nil {
+				return nil, nil, err
+			}
+		} else {
+			panelID, ok := annotations[panelIDAnnotation]
+			if !ok || findPanel(dashboard, fmt.Sprint(panelID)) != nil {
+				continue
+			}
+			link = grizzly.BrokenLink{
+				Field:   field + "." + panelIDAnnotation,
+				Message: fmt.Sprintf("rule '%s' is linked to panel %v of dashboard %s, which doesn't exist", title, panelID, dashboardUID),
+			}
+			candidates = panelsTitled(dashboardUID, dashboard, title)
+		}
+
+		switch len(candidates) {
+		case 0:
+		case 1:
+			link.Suggestion = "link to " + candidates[0].String()
+			annotations[dashboardUIDAnnotation] = candidates[0].dashboardUID
+			annotations[panelIDAnnotation] = candidates[0].panelID
+			fixed = true
+		default:
+			names := make([]string, 0, len(candidates))
+			for _, candidate := range candidates {
+				names = append(names, candidate.String())
+			}
+			link.Message += fmt.Sprintf(". Several panels are titled '%s': %s", title, strings.Join(names, ", "))
+		}
+		broken = append(broken, link)
+	}
+
+	if !fixed {
+		return broken, nil, nil
+	}
+
+	fixedResource := resource
+	fixedResource.Body = make(map[string]any, len(resource.Body))
+	for key, value := range resource.Body {
+		fixedResource.Body[key] = value
+	}
+	fixedResource.SetSpec(spec)
+
+	return broken, &fixedResource, nil
+}
+
+// linkedDashboard returns the spec of a dashboard, local or remote, or nil if it doesn't exist
+func (h *AlertRuleGroupHandler) linkedDashboard(local grizzly.Resources, uid string) (map[string]any, error) {
+	if dashboard, ok := local.Find(grizzly.NewResourceRef(DashboardKind, uid)); ok {
+		return grizzly.NormaliseJSON(dashboard.Spec()), nil
+	}
+
+	if dashboard, ok := h.remoteDashboards[uid]; ok {
+		return dashboard, nil
+	}
+
+	var spec map[string]any
+	remote, err := NewDashboardHandler(h.Provider).GetByUID(uid)
+	if err != nil && !errors.Is(err, grizzly.ErrNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		spec = grizzly.NormaliseJSON(remote.Spec())
+	}
+
+	if h.remoteDashboards == nil {
+		h.remoteDashboards = map[string]map[string]any{}
+	}
+	h.remoteDashboards[uid] = spec
+	return spec, nil
+}
+
+// panelsTitled looks up the panels with the given title in all dashboards, local and remote
+func (h *AlertRuleGroupHandler) panelsTitled(local grizzly.Resources, title string) ([]linkedPanel, error) {
+	if title == "" {
+		return nil, nil
+	}
+
+	dashboards := map[string]map[string]any{}
+	for _, dashboard := range local.OfKind(DashboardKind).AsList() {
+		dashboards[dashboard.Name()] = grizzly.NormaliseJSON(dashboard.Spec())
+	}
+
+	if !h.allRemoteDashboards {
+		if err := h.loadRemoteDashboards(); err != nil {
+			return nil, err
+		}
+	}
+	for uid, dashboard := range h.remoteDashboards {
+		if _, ok := dashboards[uid]; !ok && dashboard != nil {
+			dashboards[uid] = dashboard
+		}
+	}
+
+	var panels []linkedPanel
+	for uid, dashboard := range dashboards {
+		panels = append(panels, panelsTitled(uid, dashboard, title)...)
+	}
+	sort.Slice(panels, func(i, j int) bool {
+		return panels[i].String() < panels[j].String()
+	})
+
+	return panels, nil
+}
+
+func (h *AlertRuleGroupHandler) loadRemoteDashboards() error {
+	handler := NewDashboardHandler(h.Provider)
+	uids, err := handler.ListRemote()
+	if err != nil {
+		return err
+	}
+
+	if h.remoteDashboards == nil {
+		h.remoteDashboards = map[string]map[string]any{}
+	}
+	var missing []string
+	for _, uid := range uids {
+		if _, ok := h.remoteDashboards[uid]; !ok {
+			missing = append(missing, uid)
+		}
+	}
+	for _, result := range handler.GetByUIDs(missing) {
+		if result.Err != nil {
+			return result.Err
+		}
+		h.remoteDashboards[result.UID] = grizzly.NormaliseJSON(result.Resource.Spec())
+	}
+
+	h.allRemoteDashboards = true
+	return nil
+}
+
+func findPanel(dashboard map[string]any, id string) map[string]any {
+	for _, panel := range dashboardPanels(dashboard) {
+		if panel["id"] != nil && fmt.Sprint(panel["id"]) == id {
+			return panel
+		}
+	}
+	return nil
+}
+
+func panelsTitled(uid string, dashboard map[string]any, title string) []linkedPanel {
+	var panels []linkedPanel
+	for _, panel := range dashboardPanels(dashboard) {
+		if panel["title"] == title && panel["id"] != nil {
+			dashboardTitle, _ := dashboard["title"].(string)
+			panels = append(panels, linkedPanel{
+				dashboardUID: uid,
+				dashboard:    dashboardTitle,
+				panelID:      fmt.Sprint(panel["id"]),
+			})
+		}
+	}
+	return panels
+}
@@ -0,0 +1,81 @@
+package grafana
+
+import (
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertRuleGroupLinks(t *testing.T) {
+	provider := NewProvider(&config.GrafanaConfig{URL: "http://localhost:3000"})
+
+	dashboard, err := grizzly.NewResource(provider.APIVersion(), DashboardKind, "service", map[string]any{
+		"uid":   "service",
+		"title": "Service",
+		"panels": []any{
+			map[string]any{"id": 4, "title": "Errors"},
+			map[string]any{"id": 5, "title": "Latency"},
+		},
+	})
+	require.NoError(t, err)
+
+	newGroup := func(t *testing.T, annotations ...map[string]any) grizzly.Resource {
+		t.Helper()
+		var rules []any
+		for i, annotation := range annotations {
+			rules = append(rules, map[string]any{"title": []string{"Errors", "Latency", "Saturation"}[i], "annotations": annotation})
+		}
+		group, err := grizzly.NewResource(provider.APIVersion(), AlertRuleGroupKind, "folder.group", map[string]any{"rules": rules})
+		require.NoError(t, err)
+		return group
+	}
+
+	t.Run("valid links", func(t *testing.T) {
+		handler := NewAlertRuleGroupHandler(provider)
+		group := newGroup(t, map[string]any{dashboardUIDAnnotation: "service", panelIDAnnotation: "4"})
+
+		broken, fixed, err := handler.ValidateLinks(group, grizzly.NewResources(dashboard))
+		require.NoError(t, err)
+		require.Empty(t, broken)
+		require.Nil(t, fixed)
+	})
+
+	t.Run("broken panel links are fixed by title", func(t *testing.T) {
+		handler := NewAlertRuleGroupHandler(provider)
+		group := newGroup(t,
+			map[string]any{dashboardUIDAnnotation: "service", panelIDAnnotation: "1"},
+			map[string]any{dashboardUIDAnnotation: "deleted", panelIDAnnotation: "1"},
+		)
+		handler.remoteDashboards = map[string]map[string]any{"deleted": nil}
+		handler.allRemoteDashboards = true
+
+		broken, fixed, err := handler.ValidateLinks(group, grizzly.NewResources(dashboard))
+		require.NoError(t, err)
+		require.Len(t, broken, 2)
+		require.Equal(t, "rules[0].annotations.__panelId__", broken[0].Field)
+		require.Equal(t, "link to panel 4 of dashboard service (Service)", broken[0].Suggestion)
+		require.Equal(t, "rules[1].annotations.__dashboardUid__", broken[1].Field)
+		require.Equal(t, "link to panel 5 of dashboard service (Service)", broken[1].Suggestion)
+
+		rules := fixed.Spec()["rules"].([]any)
+		require.Equal(t, map[string]any{dashboardUIDAnnotation: "service", panelIDAnnotation: "4"}, rules[0].(map[string]any)["annotations"])
+		require.Equal(t, map[string]any{dashboardUIDAnnotation: "service", panelIDAnnotation: "5"}, rules[1].(map[string]any)["annotations"])
+	})
+
+	t.Run("links without a match are reported", func(t *testing.T) {
+		handler := NewAlertRuleGroupHandler(provider)
+		group := newGroup(t,
+			map[string]any{dashboardUIDAnnotation: "service", panelIDAnnotation: "4"},
+			map[string]any{dashboardUIDAnnotation: "service", panelIDAnnotation: "5"},
+			map[string]any{dashboardUIDAnnotation: "service", panelIDAnnotation: "6"},
+		)
+
+		broken, fixed, err := handler.ValidateLinks(group, grizzly.NewResources(dashboard))
+		require.NoError(t, err)
+		require.Len(t, broken, 1)
+		require.Empty(t, broken[0].Suggestion)
+		require.Nil(t, fixed)
+	})
+}
@@ -0,0 +1,92 @@
+package grafana
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+var _ grizzly.RuleStateHandler = &AlertRuleGroupHandler{}
+
+const rulesStatePath = "/prometheus/grafana/api/v1/rules"
+
+type ruleStateResponse struct {
+	Data struct {
+		Groups []ruleStateGroup `json:"groups"`
+	} `json:"data"`
+}
+
+type ruleStateGroup struct {
+	Name string `json:"name"`
+	// FolderUID is only returned by recent versions of Grafana
+	FolderUID string          `json:"folderUid"`
+	Rules     []ruleStateRule `json:"rules"`
+}
+
+type ruleStateRule struct {
+	Name           string    `json:"name"`
+	State          string    `json:"state"`
+	Health         string    `json:"health"`
+	LastError      string    `json:"lastError"`
+	LastEvaluation time.Time `json:"lastEvaluation"`
+}
+
+// RuleStates implements grizzly.RuleStateHandler, using the Prometheus
+// compatible rules API of Grafana
+func (h *AlertRuleGroupHandler) RuleStates(resource grizzly.Resource) ([]grizzly.RuleState, error) {
+	folder, group := h.splitUID(resource.Name())
+
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+
+	var result ruleStateResponse
+	_, err = client.Transport.Submit(&runtime.ClientOperation{
+		ID:                 "RuleStates",
+		Method:             http.MethodGet,
+		PathPattern:        rulesStatePath,
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"http", "https"},
+		Params: runtime.ClientRequestWriterFunc(func(req runtime.ClientRequest, _ strfmt.Registry) error {
+			// Older versions of Grafana ignore these filters, hence the groups being filtered below as well
+			if err := req.SetQueryParam("folder_uid", folder); err != nil {
+				return err
+			}
+			return req.SetQueryParam("rule_group", group)
+		}),
+		Reader: runtime.ClientResponseReaderFunc(func(response runtime.ClientResponse, _ runtime.Consumer) (any, error) {
+			if response.Code() >= 300 {
+				return nil, runtime.NewAPIError(fmt.Sprintf("[GET %s]", rulesStatePath), response, response.Code())
+			}
+			return nil, json.NewDecoder(response.Body()).Decode(&result)
+		}),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, g := range result.Data.Groups {
+		if g.Name != group || (g.FolderUID != "" && g.FolderUID != folder) {
+			continue
+		}
+		states := make([]grizzly.RuleState, 0, len(g.Rules))
+		for _, rule := range g.Rules {
+			states = append(states, grizzly.RuleState{
+				Rule:           rule.Name,
+				State:          rule.State,
+				Health:         rule.Health,
+				LastError:      rule.LastError,
+				LastEvaluation: rule.LastEvaluation,
+			})
+		}
+		return states, nil
+	}
+	return nil, grizzly.ErrNotFound
+}
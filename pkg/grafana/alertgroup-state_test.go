@@ -0,0 +1,128 @@
+package grafana
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyRuleStates(t *testing.T) {
+	newServer := func(t *testing.T, lastEvaluation func() time.Time) *httptest.Server {
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /api/v1/provisioning/folder/folder/rule-groups/{group}", func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"title":     r.PathValue("group"),
+				"folderUID": "folder",
+				"interval":  60,
+				"rules":     []any{map[string]any{"uid": r.PathValue("group"), "title": "Old title"}},
+			})
+		})
+		mux.HandleFunc("GET /api/v1/provisioning/alert-rules/{uid}", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{}`))
+		})
+		mux.HandleFunc("PUT /api/v1/provisioning/alert-rules/{uid}", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{}`))
+		})
+		mux.HandleFunc("PUT /api/v1/provisioning/folder/folder/rule-groups/{group}", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{}`))
+		})
+		mux.HandleFunc("GET /api/prometheus/grafana/api/v1/rules", func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "folder", r.URL.Query().Get("folder_uid"))
+			evaluated := lastEvaluation()
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"status": "success",
+				"data": map[string]any{
+					"groups": []any{
+						map[string]any{"name": "cpu", "folderUid": "folder", "rules": []any{
+							map[string]any{"name": "HighCPU", "state": "inactive", "health": "ok", "lastEvaluation": evaluated},
+						}},
+						map[string]any{"name": "disk", "folderUid": "folder", "rules": []any{
+							map[string]any{"name": "DiskFull", "state": "inactive", "health": "error", "lastError": "invalid expression", "lastEvaluation": evaluated},
+						}},
+						map[string]any{"name": "cpu", "folderUid": "other", "rules": []any{
+							map[string]any{"name": "Other", "state": "firing", "health": "ok", "lastEvaluation": evaluated},
+						}},
+					},
+				},
+			})
+		})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			mux.ServeHTTP(w, r)
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	apply := func(t *testing.T, server *httptest.Server, groups []string, opts grizzly.ApplyOptions) (string, error) {
+		provider := NewProvider(&config.GrafanaConfig{URL: server.URL})
+		registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+
+		var resources []grizzly.Resource
+		for _, group := range groups {
+			resource, err := grizzly.NewResource(provider.APIVersion(), AlertRuleGroupKind, "folder."+group, map[string]any{
+				"title":     group,
+				"folderUID": "folder",
+				"interval":  60,
+				"rules":     []any{map[string]any{"uid": group, "title": "New title"}},
+			})
+			require.NoError(t, err)
+			resources = append(resources, resource)
+		}
+
+		var out bytes.Buffer
+		opts.ContinueOnError = true
+		err := grizzly.Apply(registry, grizzly.NewResources(resources...), opts, grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText))
+		return out.String(), err
+	}
+
+	t.Run("evaluated rules are reported", func(t *testing.T) {
+		server := newServer(t, time.Now)
+
+		out, err := apply(t, server, []string{"cpu"}, grizzly.ApplyOptions{RuleEvaluationTimeout: time.Minute})
+		require.NoError(t, err)
+		require.Contains(t, out, "AlertRuleGroup.folder.cpu updated\n")
+		require.Contains(t, out, "AlertRuleGroup.folder.cpu evaluated: HighCPU inactive\n")
+	})
+
+	t.Run("rules failing their evaluation fail the apply", func(t *testing.T) {
+		server := newServer(t, time.Now)
+
+		out, err := apply(t, server, []string{"cpu", "disk"}, grizzly.ApplyOptions{RuleEvaluationTimeout: time.Minute})
+		require.ErrorContains(t, err, "AlertRuleGroup.folder.disk: 1 rules failed their evaluation")
+		require.Contains(t, out, "AlertRuleGroup.folder.cpu evaluated: HighCPU inactive\n")
+		require.Contains(t, out, "AlertRuleGroup.folder.disk failed evaluation: DiskFull inactive (invalid expression)\n")
+	})
+
+	t.Run("rules not evaluated in time are reported", func(t *testing.T) {
+		server := newServer(t, func() time.Time { return time.Now().Add(-time.Hour) })
+
+		out, err := apply(t, server, []string{"cpu"}, grizzly.ApplyOptions{RuleEvaluationTimeout: 10 * time.Millisecond})
+		require.NoError(t, err)
+		require.Contains(t, out, "AlertRuleGroup.folder.cpu not evaluated yet: HighCPU\n")
+	})
+	t.Run("evaluation errors are reported once while tailing", func(t *testing.T) {
+		server := newServer(t, time.Now)
+
+		out, err := apply(t, server, []string{"cpu", "disk"}, grizzly.ApplyOptions{RuleTailWindow: 20 * time.Millisecond})
+		require.ErrorContains(t, err, "AlertRuleGroup.folder.disk: 1 rules failed their evaluation within 20ms of being applied")
+		require.NotContains(t, err.Error(), "AlertRuleGroup.folder.cpu")
+		require.Equal(t, 1, strings.Count(out, "AlertRuleGroup.folder.disk failed evaluation: DiskFull inactive (invalid expression)\n"))
+	})
+
+	t.Run("errors of earlier evaluations aren't tailed", func(t *testing.T) {
+		server := newServer(t, func() time.Time { return time.Now().Add(-time.Hour) })
+
+		out, err := apply(t, server, []string{"disk"}, grizzly.ApplyOptions{RuleTailWindow: 10 * time.Millisecond})
+		require.NoError(t, err)
+		require.NotContains(t, out, "failed evaluation")
+	})
+}
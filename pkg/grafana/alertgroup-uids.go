@@ -0,0 +1,80 @@
+package grafana
+
+import (
+	"slices"
+
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+var _ grizzly.NestedUIDHandler = &AlertRuleGroupHandler{}
+
+// NestedUIDs implements grizzly.NestedUIDHandler, returning the rules of a
+// group by title
+func (h *AlertRuleGroupHandler) NestedUIDs(resource grizzly.Resource) []grizzly.NestedUID {
+	rules, _ := resource.GetSpecValue("rules").([]any)
+	uids := make([]grizzly.NestedUID, 0, len(rules))
+	for _, rule := range rules {
+		rule, _ := rule.(map[string]any)
+		title, _ := rule["title"].(string)
+		uid, _ := rule["uid"].(string)
+		uids = append(uids, grizzly.NestedUID{Name: title, UID: uid})
+	}
+	return uids
+}
+
+// PinNestedUIDs implements grizzly.NestedUIDHandler. Rules are given the UID
+// of the remote rule of the same title in the group, or else in another
+// group, as when the group was renamed or moved to another folder since it
+// was applied. Other rules are given a UID derived from their group and title.
+func (h *AlertRuleGroupHandler) PinNestedUIDs(resource grizzly.Resource, titles []string, taken map[string]bool) (grizzly.Resource, error) {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return resource, err
+	}
+	alertRulesOk, err := client.Provisioning.GetAlertRules()
+	if err != nil {
+		return resource, err
+	}
+	remoteRules := alertRulesOk.GetPayload()
+
+	folder, group := h.splitUID(resource.Name())
+	rules, _ := resource.GetSpecValue("rules").([]any)
+	for _, rule := range rules {
+		rule, ok := rule.(map[string]any)
+		if !ok {
+			continue
+		}
+		title, _ := rule["title"].(string)
+		if !slices.Contains(titles, title) {
+			continue
+		}
+		uid := matchRemoteRule(remoteRules, folder, group, title, taken)
+		if uid == "" {
+			uid = grizzly.StableUID(resource.Name(), title)
+		}
+		rule["uid"] = uid
+		taken[uid] = true
+	}
+	return resource, nil
+}
+
+// matchRemoteRule returns the UID of the remote rule a local rule matches by
+// title: in its group, or else in a single other group. UIDs taken by other
+// local rules aren't matched.
+func matchRemoteRule(remoteRules models.ProvisionedAlertRules, folder, group, title string, taken map[string]bool) string {
+	var elsewhere []string
+	for _, remote := range remoteRules {
+		if remote.Title == nil || *remote.Title != title || taken[remote.UID] {
+			continue
+		}
+		if remote.FolderUID != nil && *remote.FolderUID == folder && remote.RuleGroup != nil && *remote.RuleGroup == group {
+			return remote.UID
+		}
+		elsewhere = append(elsewhere, remote.UID)
+	}
+	if len(elsewhere) == 1 {
+		return elsewhere[0]
+	}
+	return ""
+}
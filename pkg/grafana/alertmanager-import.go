@@ -0,0 +1,315 @@
+package grafana
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+type alertmanagerConfig struct {
+	Global            map[string]any             `yaml:"global"`
+	Route             *alertmanagerRoute         `yaml:"route"`
+	Receivers         []alertmanagerReceiver     `yaml:"receivers"`
+	MuteTimeIntervals []alertmanagerTimeInterval `yaml:"mute_time_intervals"`
+	TimeIntervals     []alertmanagerTimeInterval `yaml:"time_intervals"`
+	InhibitRules      []any                      `yaml:"inhibit_rules"`
+	Templates         []string                   `yaml:"templates"`
+}
+
+type alertmanagerRoute struct {
+	Receiver            string               `yaml:"receiver"`
+	GroupBy             []string             `yaml:"group_by"`
+	Continue            bool                 `yaml:"continue"`
+	Match               map[string]string    `yaml:"match"`
+	MatchRE             map[string]string    `yaml:"match_re"`
+	Matchers            []string             `yaml:"matchers"`
+	GroupWait           string               `yaml:"group_wait"`
+	GroupInterval       string               `yaml:"group_interval"`
+	RepeatInterval      string               `yaml:"repeat_interval"`
+	MuteTimeIntervals   []string             `yaml:"mute_time_intervals"`
+	ActiveTimeIntervals []string             `yaml:"active_time_intervals"`
+	Routes              []*alertmanagerRoute `yaml:"routes"`
+}
+
+type alertmanagerReceiver struct {
+	Name string `yaml:"name"`
+	// Integrations maps the <type>_configs keys to the integrations they configure
+	Integrations map[string][]map[string]any `yaml:",inline"`
+}
+
+type alertmanagerTimeInterval struct {
+	Name          string `yaml:"name"`
+	TimeIntervals []any  `yaml:"time_intervals"`
+}
+
+// integrationConverter converts the configuration of an Alertmanager
+// integration to the settings of a Grafana contact point
+type integrationConverter struct {
+	// grafanaType is the type of the contact point
+	grafanaType string
+	// settings maps Alertmanager settings to Grafana settings
+	settings map[string]string
+	// globals maps global Alertmanager settings to Grafana settings, used
+	// when the integration doesn't set them
+	globals map[string]string
+	// sendResolved is the default of send_resolved
+	sendResolved bool
+}
+
+var integrationConverters = map[string]integrationConverter{
+	"email_configs": {
+		grafanaType: "email",
+		settings:    map[string]string{"to": "addresses"},
+	},
+	"slack_configs": {
+		grafanaType:  "slack",
+		settings:     map[string]string{"api_url": "url", "channel": "recipient", "username": "username", "icon_emoji": "icon_emoji", "icon_url": "icon_url", "title": "title", "text": "text"},
+		globals:      map[string]string{"slack_api_url": "url"},
+		sendResolved: true,
+	},
+	"webhook_configs": {
+		grafanaType:  "webhook",
+		settings:     map[string]string{"url": "url", "max_alerts": "maxAlerts"},
+		sendResolved: true,
+	},
+	"pagerduty_configs": {
+		grafanaType:  "pagerduty",
+		settings:     map[string]string{"routing_key": "integrationKey", "service_key": "integrationKey", "severity": "severity", "class": "class", "component": "component", "group": "group", "client": "client", "client_url": "client_url", "description": "summary"},
+		sendResolved: true,
+	},
+	"opsgenie_configs": {
+		grafanaType:  "opsgenie",
+		settings:     map[string]string{"api_key": "apiKey", "api_url": "apiUrl", "message": "message", "description": "description"},
+		globals:      map[string]string{"opsgenie_api_key": "apiKey", "opsgenie_api_url": "apiUrl"},
+		sendResolved: true,
+	},
+	"telegram_configs": {
+		grafanaType:  "telegram",
+		settings:     map[string]string{"bot_token": "bottoken", "chat_id": "chatid", "message": "message", "parse_mode": "parse_mode", "disable_notifications": "disable_notifications"},
+		sendResolved: true,
+	},
+	"discord_configs": {
+		grafanaType:  "discord",
+		settings:     map[string]string{"webhook_url": "url", "title": "title", "message": "message"},
+		sendResolved: true,
+	},
+	"msteams_configs": {
+		grafanaType:  "teams",
+		settings:     map[string]string{"webhook_url": "url", "title": "title", "text": "message"},
+		sendResolved: true,
+	},
+	"pushover_configs": {
+		grafanaType:  "pushover",
+		settings:     map[string]string{"user_key": "userKey", "token": "apiToken", "title": "title", "message": "message", "priority": "priority", "sound": "sound"},
+		sendResolved: true,
+	},
+}
+
+// ConvertAlertmanager converts the configuration of a classic Alertmanager
+// (alertmanager.yml) to Grafana Alerting resources: the route tree becomes the
+// notification policy, each integration of the receivers becomes a contact
+// point named after its receiver, and time intervals become mute timings.
+// Settings that Grafana doesn't support are reported as warnings.
+func ConvertAlertmanager(apiVersion string, config []byte) (grizzly.Resources, error) {
+	var amConfig alertmanagerConfig
+	if err := yaml.Unmarshal(config, &amConfig); err != nil {
+		return grizzly.Resources{}, fmt.Errorf("reading Alertmanager configuration: %w", err)
+	}
+	if amConfig.Route == nil {
+		return grizzly.Resources{}, fmt.Errorf("the Alertmanager configuration has no route")
+	}
+	if len(amConfig.InhibitRules) > 0 {
+		log.Warnf("%d inhibit rules skipped: Grafana Alerting doesn't support them", len(amConfig.InhibitRules))
+	}
+	if len(amConfig.Templates) > 0 {
+		log.Warnf("notification templates aren't converted, review the templates used by contact points")
+	}
+
+	resources := grizzly.NewResources()
+
+	for _, interval := range append(amConfig.MuteTimeIntervals, amConfig.TimeIntervals...) {
+		resource, err := grizzly.NewResource(apiVersion, AlertMuteTimingKind, interval.Name, map[string]any{
+			"name":           interval.Name,
+			"time_intervals": interval.TimeIntervals,
+		})
+		if err != nil {
+			return grizzly.Resources{}, err
+		}
+		resources.Add(resource)
+	}
+
+	for _, receiver := range amConfig.Receivers {
+		contactPoints, err := convertReceiver(apiVersion, receiver, amConfig.Global)
+		if err != nil {
+			return grizzly.Resources{}, fmt.Errorf("receiver %s: %w", receiver.Name, err)
+		}
+		for _, contactPoint := range contactPoints {
+			if _, exists := resources.Find(contactPoint.Ref()); exists {
+				return grizzly.Resources{}, fmt.Errorf("receiver %s: contact point UID %s is already used by another receiver", receiver.Name, contactPoint.Name())
+			}
+			resources.Add(contactPoint)
+		}
+	}
+
+	route, err := convertRoute(amConfig.Route, "route")
+	if err != nil {
+		return grizzly.Resources{}, err
+	}
+	policy, err := grizzly.NewResource(apiVersion, AlertNotificationPolicyKind, GlobalAlertNotificationPolicyName, route)
+	if err != nil {
+		return grizzly.Resources{}, err
+	}
+	resources.Add(policy)
+
+	return resources, nil
+}
+
+func convertReceiver(apiVersion string, receiver alertmanagerReceiver, global map[string]any) ([]grizzly.Resource, error) {
+	keys := make([]string, 0, len(receiver.Integrations))
+	for key := range receiver.Integrations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var contactPoints []grizzly.Resource
+	for _, key := range keys {
+		converter, ok := integrationConverters[key]
+		if !ok {
+			log.Warnf("receiver %s: %s skipped, the integration isn't supported", receiver.Name, key)
+			continue
+		}
+
+		for _, integration := range receiver.Integrations[key] {
+			settings := map[string]any{}
+			for amKey, value := range integration {
+				grafanaKey, ok := converter.settings[amKey]
+				switch {
+				case ok:
+					settings[grafanaKey] = value
+				case amKey == "send_resolved":
+				case strings.HasSuffix(amKey, "_file"):
+					log.Warnf("receiver %s: %s.%s skipped, set the secret it points to in the contact point", receiver.Name, key, amKey)
+				default:
+					log.Warnf("receiver %s: %s.%s skipped, the setting isn't supported", receiver.Name, key, amKey)
+				}
+			}
+			for amKey, grafanaKey := range converter.globals {
+				if _, set := settings[grafanaKey]; !set && global[amKey] != nil {
+					settings[grafanaKey] = global[amKey]
+				}
+			}
+
+			sendResolved := converter.sendResolved
+			if value, ok := integration["send_resolved"].(bool); ok {
+				sendResolved = value
+			}
+
+			uid := contactPointUID(receiver.Name, len(contactPoints))
+			contactPoint, err := grizzly.NewResource(apiVersion, AlertContactPointKind, uid, map[string]any{
+				"uid":                   uid,
+				"name":                  receiver.Name,
+				"type":                  converter.grafanaType,
+				"settings":              settings,
+				"disableResolveMessage": !sendResolved,
+			})
+			if err != nil {
+				return nil, err
+			}
+			contactPoints = append(contactPoints, contactPoint)
+		}
+	}
+
+	if len(contactPoints) == 0 {
+		log.Warnf("receiver %s has no supported integration, create its contact point by hand", receiver.Name)
+	}
+	return contactPoints, nil
+}
+
+// contactPointUID derives the UID of the contact point of an integration
+// from the name of its receiver and the index of the integration
+func contactPointUID(receiver string, index int) string {
+	suffix := fmt.Sprintf("-%d", index)
+	uid := strings.Trim(invalidUIDChars.ReplaceAllString(receiver, "-"), "-")
+	if len(uid)+len(suffix) > maxUIDLength {
+		uid = uid[:maxUIDLength-len(suffix)]
+	}
+	return uid + suffix
+}
+
+func convertRoute(route *alertmanagerRoute, path string) (map[string]any, error) {
+	policy := map[string]any{}
+	if route.Receiver != "" {
+		policy["receiver"] = route.Receiver
+	}
+	if len(route.GroupBy) > 0 {
+		policy["group_by"] = route.GroupBy
+	}
+	if route.Continue {
+		policy["continue"] = true
+	}
+	for key, value := range map[string]string{"group_wait": route.GroupWait, "group_interval": route.GroupInterval, "repeat_interval": route.RepeatInterval} {
+		if value != "" {
+			policy[key] = value
+		}
+	}
+	if len(route.MuteTimeIntervals) > 0 {
+		policy["mute_time_intervals"] = route.MuteTimeIntervals
+	}
+	if len(route.ActiveTimeIntervals) > 0 {
+		log.Warnf("%s: active_time_intervals skipped, use mute timings instead", path)
+	}
+
+	var matchers [][]string
+	for _, name := range sortedKeys(route.Match) {
+		matchers = append(matchers, []string{name, "=", route.Match[name]})
+	}
+	for _, name := range sortedKeys(route.MatchRE) {
+		matchers = append(matchers, []string{name, "=~", route.MatchRE[name]})
+	}
+	for _, matcher := range route.Matchers {
+		parsed, err := grizzly.ParseMatcher(matcher)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		operator := "="
+		switch {
+		case parsed.IsRegex && parsed.IsEqual:
+			operator = "=~"
+		case parsed.IsRegex:
+			operator = "!~"
+		case !parsed.IsEqual:
+			operator = "!="
+		}
+		matchers = append(matchers, []string{parsed.Name, operator, parsed.Value})
+	}
+	if len(matchers) > 0 {
+		policy["object_matchers"] = matchers
+	}
+
+	if len(route.Routes) > 0 {
+		routes := make([]any, 0, len(route.Routes))
+		for i, child := range route.Routes {
+			converted, err := convertRoute(child, fmt.Sprintf("%s.routes[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			routes = append(routes, converted)
+		}
+		policy["routes"] = routes
+	}
+
+	return policy, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,130 @@
+package grafana
+
+import (
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+const alertmanagerConfigYAML = `
+global:
+  slack_api_url: https://hooks.slack.com/services/global
+route:
+  receiver: default
+  group_by: [alertname, cluster]
+  group_wait: 30s
+  routes:
+    - receiver: database-team
+      match:
+        team: db
+      match_re:
+        service: mysql|postgres
+      continue: true
+      mute_time_intervals: [weekends]
+      routes:
+        - receiver: pager
+          matchers:
+            - severity="critical"
+            - env!~"dev|staging"
+receivers:
+  - name: default
+    email_configs:
+      - to: oncall@example.com
+  - name: database-team
+    slack_configs:
+      - channel: '#db-alerts'
+        send_resolved: false
+      - channel: '#db-alerts-archive'
+  - name: pager
+    pagerduty_configs:
+      - routing_key: secret-key
+        severity: critical
+    sns_configs:
+      - topic_arn: arn:aws:sns:eu-west-1:123456789012:alerts
+mute_time_intervals:
+  - name: weekends
+    time_intervals:
+      - weekdays: [saturday, sunday]
+inhibit_rules:
+  - source_matchers: [severity="critical"]
+    target_matchers: [severity="warning"]
+`
+
+func TestConvertAlertmanager(t *testing.T) {
+	resources, err := ConvertAlertmanager("grizzly.grafana.com/v1alpha1", []byte(alertmanagerConfigYAML))
+	require.NoError(t, err)
+	require.Equal(t, 6, resources.Len())
+
+	t.Run("time intervals become mute timings", func(t *testing.T) {
+		muteTiming, ok := resources.Find(grizzly.NewResourceRef(AlertMuteTimingKind, "weekends"))
+		require.True(t, ok)
+		require.Equal(t, "weekends", muteTiming.GetSpecValue("name"))
+		require.Len(t, muteTiming.GetSpecValue("time_intervals"), 1)
+	})
+
+	t.Run("integrations become contact points named after their receiver", func(t *testing.T) {
+		email, ok := resources.Find(grizzly.NewResourceRef(AlertContactPointKind, "default-0"))
+		require.True(t, ok)
+		require.Equal(t, "default", email.GetSpecValue("name"))
+		require.Equal(t, "email", email.GetSpecValue("type"))
+		require.Equal(t, map[string]any{"addresses": "oncall@example.com"}, email.GetSpecValue("settings"))
+		require.Equal(t, true, email.GetSpecValue("disableResolveMessage"))
+
+		slack, ok := resources.Find(grizzly.NewResourceRef(AlertContactPointKind, "database-team-0"))
+		require.True(t, ok)
+		require.Equal(t, "database-team", slack.GetSpecValue("name"))
+		require.Equal(t, map[string]any{"recipient": "#db-alerts", "url": "https://hooks.slack.com/services/global"}, slack.GetSpecValue("settings"))
+		require.Equal(t, true, slack.GetSpecValue("disableResolveMessage"))
+
+		slack, ok = resources.Find(grizzly.NewResourceRef(AlertContactPointKind, "database-team-1"))
+		require.True(t, ok)
+		require.Equal(t, false, slack.GetSpecValue("disableResolveMessage"))
+
+		pager, ok := resources.Find(grizzly.NewResourceRef(AlertContactPointKind, "pager-0"))
+		require.True(t, ok)
+		require.Equal(t, "pagerduty", pager.GetSpecValue("type"))
+		require.Equal(t, map[string]any{"integrationKey": "secret-key", "severity": "critical"}, pager.GetSpecValue("settings"))
+
+		_, ok = resources.Find(grizzly.NewResourceRef(AlertContactPointKind, "pager-1"))
+		require.False(t, ok, "unsupported integrations are skipped")
+	})
+
+	t.Run("the route tree becomes the notification policy", func(t *testing.T) {
+		policy, ok := resources.Find(grizzly.NewResourceRef(AlertNotificationPolicyKind, GlobalAlertNotificationPolicyName))
+		require.True(t, ok)
+		require.Equal(t, map[string]any{
+			"receiver":   "default",
+			"group_by":   []string{"alertname", "cluster"},
+			"group_wait": "30s",
+			"routes": []any{
+				map[string]any{
+					"receiver":            "database-team",
+					"continue":            true,
+					"mute_time_intervals": []string{"weekends"},
+					"object_matchers": [][]string{
+						{"team", "=", "db"},
+						{"service", "=~", "mysql|postgres"},
+					},
+					"routes": []any{
+						map[string]any{
+							"receiver": "pager",
+							"object_matchers": [][]string{
+								{"severity", "=", "critical"},
+								{"env", "!~", "dev|staging"},
+							},
+						},
+					},
+				},
+			},
+		}, policy.Spec())
+
+		h := NewAlertNotificationPolicyHandler(nil)
+		require.NoError(t, h.ValidateSchema(policy))
+	})
+}
+
+func TestContactPointUID(t *testing.T) {
+	require.Equal(t, "team-a-slack-0", contactPointUID("team a/slack", 0))
+	require.Len(t, contactPointUID("a-very-long-receiver-name-that-goes-on-and-on", 12), maxUIDLength)
+}
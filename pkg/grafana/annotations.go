@@ -0,0 +1,181 @@
+package grafana
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+// Where annotations can be exposed in Grafana resources
+const (
+	annotationsAsTags        = "tags"
+	annotationsAsDescription = "description"
+	annotationsAsLabels      = "labels"
+	annotationsAsAnnotations = "annotations"
+)
+
+var _ grizzly.AnnotationHandler = &DashboardHandler{}
+var _ grizzly.AnnotationHandler = &FolderHandler{}
+var _ grizzly.AnnotationHandler = &AlertRuleGroupHandler{}
+
+// AnnotationTargets implements grizzly.AnnotationHandler
+func (h *DashboardHandler) AnnotationTargets() []string {
+	return []string{annotationsAsTags, annotationsAsDescription}
+}
+
+// ExposeAnnotations implements grizzly.AnnotationHandler, adding annotations
+// to the tags of dashboards as key:value, or to their description
+func (h *DashboardHandler) ExposeAnnotations(resource *grizzly.Resource, annotations map[string]string, target string) error {
+	if target == annotationsAsDescription {
+		exposeAnnotationsInDescription(resource, annotations)
+		return nil
+	}
+
+	dashboard, err := grizzly.DecodeSpec[DashboardSpec](*resource)
+	if err != nil {
+		return err
+	}
+
+	keys := sortedAnnotationKeys(annotations)
+	var tags []string
+	for _, tag := range dashboard.Spec.Tags {
+		// tags previously set from annotations are replaced
+		if !slices.ContainsFunc(keys, func(key string) bool { return strings.HasPrefix(tag, key+":") }) {
+			tags = append(tags, tag)
+		}
+	}
+	for _, key := range keys {
+		tags = append(tags, key+":"+annotations[key])
+	}
+	dashboard.Spec.Tags = tags
+	return dashboard.Apply(resource)
+}
+
+// ReadAnnotations implements grizzly.AnnotationHandler
+func (h *DashboardHandler) ReadAnnotations(resource grizzly.Resource, target string) map[string]string {
+	if target == annotationsAsDescription {
+		return readAnnotationsFromDescription(resource)
+	}
+
+	annotations := map[string]string{}
+	dashboard, err := grizzly.DecodeSpec[DashboardSpec](resource)
+	if err != nil {
+		return annotations
+	}
+	for _, tag := range dashboard.Spec.Tags {
+		if key, value, found := strings.Cut(tag, ":"); found {
+			annotations[key] = value
+		}
+	}
+	return annotations
+}
+
+// AnnotationTargets implements grizzly.AnnotationHandler
+func (h *FolderHandler) AnnotationTargets() []string {
+	return []string{annotationsAsDescription}
+}
+
+// ExposeAnnotations implements grizzly.AnnotationHandler, adding annotations
+// to the description of folders
+func (h *FolderHandler) ExposeAnnotations(resource *grizzly.Resource, annotations map[string]string, _ string) error {
+	exposeAnnotationsInDescription(resource, annotations)
+	return nil
+}
+
+// ReadAnnotations implements grizzly.AnnotationHandler
+func (h *FolderHandler) ReadAnnotations(resource grizzly.Resource, _ string) map[string]string {
+	return readAnnotationsFromDescription(resource)
+}
+
+// AnnotationTargets implements grizzly.AnnotationHandler
+func (h *AlertRuleGroupHandler) AnnotationTargets() []string {
+	return []string{annotationsAsLabels, annotationsAsAnnotations}
+}
+
+// ExposeAnnotations implements grizzly.AnnotationHandler, adding annotations
+// to the labels, or annotations, of every rule of a group. Labels are
+// attached to the alerts fired by the rules, and can be used for routing.
+func (h *AlertRuleGroupHandler) ExposeAnnotations(resource *grizzly.Resource, annotations map[string]string, target string) error {
+	rules, _ := resource.GetSpecValue("rules").([]any)
+	for i, item := range rules {
+		rule, ok := item.(map[string]any)
+		if !ok {
+			return fmt.Errorf("rule %d of %s isn't an object", i, resource.Ref())
+		}
+		values, _ := rule[target].(map[string]any)
+		if values == nil {
+			values = map[string]any{}
+		}
+		for key, value := range annotations {
+			values[key] = value
+		}
+		rule[target] = values
+	}
+	return nil
+}
+
+// ReadAnnotations implements grizzly.AnnotationHandler. All the rules of a
+// group are stamped alike, so the first one is representative.
+func (h *AlertRuleGroupHandler) ReadAnnotations(resource grizzly.Resource, target string) map[string]string {
+	annotations := map[string]string{}
+	rules, _ := resource.GetSpecValue("rules").([]any)
+	if len(rules) == 0 {
+		return annotations
+	}
+	rule, _ := rules[0].(map[string]any)
+	values, _ := rule[target].(map[string]any)
+	for key, value := range values {
+		annotations[key] = fmt.Sprint(value)
+	}
+	return annotations
+}
+
+// exposeAnnotationsInDescription appends annotations to the description of a
+// resource, one per line. Lines previously set from annotations are replaced.
+func exposeAnnotationsInDescription(resource *grizzly.Resource, annotations map[string]string) {
+	keys := sortedAnnotationKeys(annotations)
+
+	var lines []string
+	description, _ := resource.GetSpecValue("description").(string)
+	for _, line := range strings.Split(description, "\n") {
+		if !slices.ContainsFunc(keys, func(key string) bool { return strings.HasPrefix(line, key+": ") }) {
+			lines = append(lines, line)
+		}
+	}
+	if description := strings.TrimSpace(strings.Join(lines, "\n")); description != "" {
+		lines = []string{description, ""}
+	} else {
+		lines = nil
+	}
+
+	for _, key := range keys {
+		lines = append(lines, key+": "+annotations[key])
+	}
+	resource.SetSpecValue("description", strings.Join(lines, "\n"))
+}
+
+// readAnnotationsFromDescription reads the key: value lines of the last paragraph of a description
+func readAnnotationsFromDescription(resource grizzly.Resource) map[string]string {
+	annotations := map[string]string{}
+	description, _ := resource.GetSpecValue("description").(string)
+	paragraphs := strings.Split(description, "\n\n")
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		if key, value, found := strings.Cut(line, ": "); found {
+			annotations[key] = value
+		}
+	}
+	return annotations
+}
+
+// sortedAnnotationKeys returns the keys of annotations in a stable order, so
+// that exposing them doesn't change remote resources on every apply
+func sortedAnnotationKeys(annotations map[string]string) []string {
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,77 @@
+package grafana
+
+import (
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExposeAnnotations(t *testing.T) {
+	newResource := func(t *testing.T, kind string, spec map[string]any) grizzly.Resource {
+		resource, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", kind, "sample", spec)
+		require.NoError(t, err)
+		resource.Body["metadata"].(map[string]any)["annotations"] = map[string]any{
+			"repo":    "github.com/example/dashboards",
+			"git-sha": "1a2b3c4",
+		}
+		return resource
+	}
+	newRegistry := func(targets map[string]string) grizzly.Registry {
+		registry := grizzly.NewRegistry([]grizzly.Provider{NewProvider(&config.GrafanaConfig{})})
+		registry.AnnotationTargets = targets
+		return registry
+	}
+
+	t.Run("dashboards expose annotations as tags", func(t *testing.T) {
+		resource := newResource(t, DashboardKind, map[string]any{"tags": []any{"team", "git-sha:0000000"}})
+
+		exposed, err := grizzly.ExposeAnnotations(newRegistry(nil), resource)
+		require.NoError(t, err)
+		require.Equal(t, []any{"team", "git-sha:1a2b3c4", "repo:github.com/example/dashboards"}, exposed.GetSpecValue("tags"))
+		require.False(t, exposed.HasMetadata("annotations"))
+		require.True(t, resource.HasMetadata("annotations"), "the original resource is left untouched")
+		require.Equal(t, []any{"team", "git-sha:0000000"}, resource.GetSpecValue("tags"))
+	})
+
+	t.Run("targets are configurable per kind", func(t *testing.T) {
+		resource := newResource(t, DashboardKind, map[string]any{"description": "Overview"})
+
+		exposed, err := grizzly.ExposeAnnotations(newRegistry(map[string]string{"dashboard": "description"}), resource)
+		require.NoError(t, err)
+		require.Equal(t, "Overview\n\ngit-sha: 1a2b3c4\nrepo: github.com/example/dashboards", exposed.GetSpecValue("description"))
+		require.Nil(t, exposed.GetSpecValue("tags"))
+	})
+
+	t.Run("alert rules expose annotations as labels", func(t *testing.T) {
+		resource := newResource(t, AlertRuleGroupKind, map[string]any{
+			"rules": []any{
+				map[string]any{"title": "first", "labels": map[string]any{"severity": "critical"}},
+				map[string]any{"title": "second"},
+			},
+		})
+
+		exposed, err := grizzly.ExposeAnnotations(newRegistry(nil), resource)
+		require.NoError(t, err)
+		rules := exposed.GetSpecValue("rules").([]any)
+		require.Equal(t, map[string]any{"severity": "critical", "git-sha": "1a2b3c4", "repo": "github.com/example/dashboards"}, rules[0].(map[string]any)["labels"])
+		require.Equal(t, map[string]any{"git-sha": "1a2b3c4", "repo": "github.com/example/dashboards"}, rules[1].(map[string]any)["labels"])
+	})
+
+	t.Run("annotations can be disabled", func(t *testing.T) {
+		resource := newResource(t, DashboardFolderKind, map[string]any{"title": "Sample"})
+
+		exposed, err := grizzly.ExposeAnnotations(newRegistry(map[string]string{"dashboardfolder": grizzly.AnnotationsDisabled}), resource)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"title": "Sample"}, exposed.Spec())
+		require.False(t, exposed.HasMetadata("annotations"))
+	})
+
+	t.Run("unknown targets are refused", func(t *testing.T) {
+		resource := newResource(t, DashboardFolderKind, map[string]any{"title": "Sample"})
+
+		_, err := grizzly.ExposeAnnotations(newRegistry(map[string]string{"dashboardfolder": "tags"}), resource)
+		require.ErrorContains(t, err, "DashboardFolder annotations can't be exposed as tags, expected one of: description, none")
+	})
+}
@@ -0,0 +1,380 @@
+package grafana
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// APIModeAuto uses the App Platform APIs for the kinds Grafana serves with them, and legacy APIs otherwise
+	APIModeAuto = "auto"
+	// APIModeLegacy only uses the legacy /api endpoints
+	APIModeLegacy = "legacy"
+	// APIModeAppPlatform requires the App Platform APIs for the kinds supporting them
+	APIModeAppPlatform = "app-platform"
+
+	dashboardAPIGroup = "dashboard.grafana.app"
+	folderAPIGroup    = "folder.grafana.app"
+
+	// folderAnnotation holds the UID of the folder containing an App Platform resource
+	folderAnnotation = "grafana.app/folder"
+
+	defaultNamespace = "default"
+	appPlatformLimit = 500
+)
+
+// appPlatformVersions lists, per API group, the versions Grizzly can use,
+// most preferred first. Other versions use different schemas.
+var appPlatformVersions = map[string][]string{
+	dashboardAPIGroup: {"v1beta1", "v0alpha1"},
+	folderAPIGroup:    {"v1beta1", "v0alpha1"},
+}
+
+// appPlatformProvider is implemented by providers able to reach Grafana's App Platform APIs
+type appPlatformProvider interface {
+	appPlatformResource(group, resource string) (*appPlatformClient, error)
+}
+
+// appPlatformDiscovery records the App Platform APIs available in a Grafana instance
+type appPlatformDiscovery struct {
+	// versions holds the version negotiated for each available group
+	versions  map[string]string
+	namespace string
+}
+
+type apiGroupList struct {
+	Groups []struct {
+		Name     string `json:"name"`
+		Versions []struct {
+			Version string `json:"version"`
+		} `json:"versions"`
+		PreferredVersion struct {
+			Version string `json:"version"`
+		} `json:"preferredVersion"`
+	} `json:"groups"`
+}
+
+// k8sObject is a resource, as served by the App Platform APIs
+type k8sObject struct {
+	APIVersion string         `json:"apiVersion"`
+	Kind       string         `json:"kind"`
+	Metadata   k8sObjectMeta  `json:"metadata"`
+	Spec       map[string]any `json:"spec"`
+}
+
+type k8sObjectMeta struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace,omitempty"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+	// CreationTimestamp is set by Grafana, and ignored when saving resources
+	CreationTimestamp string            `json:"creationTimestamp,omitempty"`
+	Annotations       map[string]string `json:"annotations,omitempty"`
+	Labels            map[string]string `json:"labels,omitempty"`
+}
+
+type k8sList struct {
+	Metadata struct {
+		Continue string `json:"continue,omitempty"`
+	} `json:"metadata"`
+	Items []k8sObject `json:"items"`
+}
+
+type k8sStatus struct {
+	Message string `json:"message"`
+	Reason  string `json:"reason"`
+}
+
+// appPlatformError is returned when an App Platform API responds with an error status
+type appPlatformError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func (e appPlatformError) Error() string {
+	return fmt.Sprintf("[%s %s][%d] %s", e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+// IsCode reports whether the request failed with the given HTTP status
+func (e appPlatformError) IsCode(code int) bool {
+	return e.StatusCode == code
+}
+
+func (p *Provider) apiMode() string {
+	if p.config.APIMode == "" {
+		return APIModeAuto
+	}
+	return p.config.APIMode
+}
+
+// discoverAppPlatform negotiates, once, the version of each App Platform API
+// group with Grafana, and finds the namespace of the organisation or stack.
+func (p *Provider) discoverAppPlatform() (*appPlatformDiscovery, error) {
+	p.appPlatformMu.Lock()
+	defer p.appPlatformMu.Unlock()
+	if p.appPlatform != nil {
+		return p.appPlatform, nil
+	}
+
+	discovery := &appPlatformDiscovery{
+		versions:  map[string]string{},
+		namespace: p.config.Namespace,
+	}
+	mode := p.apiMode()
+	switch mode {
+	case APIModeLegacy:
+		p.appPlatform = discovery
+		return discovery, nil
+	case APIModeAuto, APIModeAppPlatform:
+	default:
+		return nil, fmt.Errorf("unknown API mode %s, expected one of %s, %s or %s", mode, APIModeAuto, APIModeLegacy, APIModeAppPlatform)
+	}
+
+	var groups apiGroupList
+	if err := p.appPlatformRequest(http.MethodGet, "/apis", nil, nil, &groups); err != nil {
+		if mode == APIModeAppPlatform {
+			return nil, fmt.Errorf("discovering App Platform APIs: %w", err)
+		}
+		log.Debugf("App Platform APIs unavailable, using legacy APIs: %s", err)
+		p.appPlatform = discovery
+		return discovery, nil
+	}
+	for _, group := range groups.Groups {
+		offered := make([]string, 0, len(group.Versions))
+		for _, version := range group.Versions {
+			offered = append(offered, version.Version)
+		}
+		if version := negotiateVersion(appPlatformVersions[group.Name], group.PreferredVersion.Version, offered); version != "" {
+			log.Debugf("Using App Platform API %s/%s", group.Name, version)
+			discovery.versions[group.Name] = version
+		}
+	}
+
+	if discovery.namespace == "" {
+		discovery.namespace = p.discoverNamespace()
+	}
+
+	p.appPlatform = discovery
+	return discovery, nil
+}
+
+// negotiateVersion picks the version preferred by Grafana if Grizzly supports
+// it, or else the version offered that Grizzly prefers.
+func negotiateVersion(supported []string, preferred string, offered []string) string {
+	if slices.Contains(supported, preferred) {
+		return preferred
+	}
+	for _, version := range supported {
+		if slices.Contains(offered, version) {
+			return version
+		}
+	}
+	return ""
+}
+
+// discoverNamespace reads the namespace of the current organisation or stack
+// from the frontend settings, which expose it since the App Platform APIs exist.
+func (p *Provider) discoverNamespace() string {
+	var settings struct {
+		Namespace string `json:"namespace"`
+	}
+	if err := p.appPlatformRequest(http.MethodGet, "/api/frontend/settings", nil, nil, &settings); err != nil || settings.Namespace == "" {
+		log.Debugf("Could not discover the App Platform namespace, using %s", defaultNamespace)
+		return defaultNamespace
+	}
+	return settings.Namespace
+}
+
+// appPlatformResource returns a client for a resource of an App Platform API
+// group, or nil if the legacy APIs should be used instead.
+func (p *Provider) appPlatformResource(group, resource string) (*appPlatformClient, error) {
+	discovery, err := p.discoverAppPlatform()
+	if err != nil {
+		return nil, err
+	}
+
+	version, ok := discovery.versions[group]
+	if !ok {
+		if p.apiMode() == APIModeAppPlatform {
+			return nil, fmt.Errorf("the %s App Platform API isn't available in this version of Grafana, in a version supported by Grizzly (%s)", group, strings.Join(appPlatformVersions[group], ", "))
+		}
+		return nil, nil
+	}
+
+	return &appPlatformClient{
+		provider:  p,
+		group:     group,
+		version:   version,
+		namespace: discovery.namespace,
+		resource:  resource,
+	}, nil
+}
+
+// appPlatformRequest calls an endpoint of Grafana outside of the /api base
+// path of the generated client, with the same authentication and TLS settings.
+func (p *Provider) appPlatformRequest(method, path string, query url.Values, body any, result any) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	target := strings.TrimSuffix(p.config.URL, "/") + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, target, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil && method == http.MethodPatch {
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+	} else if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if authorization := p.authorizationHeader(); authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	httpClient, err := p.newHTTPClient()
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Server-side field validation reports ignored fields as warnings
+	for _, warning := range resp.Header.Values("Warning") {
+		log.Warnf("%s %s: %s", method, path, warning)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		apiErr := appPlatformError{Method: method, Path: path, StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(content))}
+		var status k8sStatus
+		if json.Unmarshal(content, &status) == nil && status.Message != "" {
+			apiErr.Message = status.Message
+		}
+		return apiErr
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(content, result)
+}
+
+// appPlatformClient manages the resources of a given kind through an App Platform API
+type appPlatformClient struct {
+	provider  *Provider
+	group     string
+	version   string
+	namespace string
+	resource  string
+}
+
+func (c *appPlatformClient) apiVersion() string {
+	return c.group + "/" + c.version
+}
+
+func (c *appPlatformClient) path(name string) string {
+	path := fmt.Sprintf("/apis/%s/%s/namespaces/%s/%s", c.group, c.version, c.namespace, c.resource)
+	if name != "" {
+		path += "/" + url.PathEscape(name)
+	}
+	return path
+}
+
+func (c *appPlatformClient) get(name string) (*k8sObject, error) {
+	var object k8sObject
+	if err := c.provider.appPlatformRequest(http.MethodGet, c.path(name), nil, nil, &object); err != nil {
+		if grizzly.ErrorCodeOf(err) == grizzly.ErrorCodeNotFound {
+			return nil, grizzly.ErrNotFound
+		}
+		return nil, err
+	}
+	return &object, nil
+}
+
+func (c *appPlatformClient) list() ([]k8sObject, error) {
+	var objects []k8sObject
+	query := url.Values{"limit": {fmt.Sprint(appPlatformLimit)}}
+	for {
+		var list k8sList
+		if err := c.provider.appPlatformRequest(http.MethodGet, c.path(""), query, nil, &list); err != nil {
+			return nil, err
+		}
+		objects = append(objects, list.Items...)
+		if list.Metadata.Continue == "" {
+			return objects, nil
+		}
+		query.Set("continue", list.Metadata.Continue)
+	}
+}
+
+// save creates or replaces a resource, letting Grafana reject unknown fields
+func (c *appPlatformClient) save(object k8sObject) error {
+	return c.write(object, url.Values{"fieldValidation": {"Strict"}})
+}
+
+// validate sends a resource as save does, in a dry run: Grafana validates it,
+// and runs its admission checks, without persisting it
+func (c *appPlatformClient) validate(object k8sObject) error {
+	return c.write(object, url.Values{"fieldValidation": {"Strict"}, "dryRun": {"All"}})
+}
+
+func (c *appPlatformClient) write(object k8sObject, query url.Values) error {
+	object.APIVersion = c.apiVersion()
+
+	existing, err := c.get(object.Metadata.Name)
+	if errors.Is(err, grizzly.ErrNotFound) {
+		return c.provider.appPlatformRequest(http.MethodPost, c.path(""), query, object, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	object.Metadata.ResourceVersion = existing.Metadata.ResourceVersion
+	return c.provider.appPlatformRequest(http.MethodPut, c.path(object.Metadata.Name), query, object, nil)
+}
+
+// patchAnnotations sets, or removes when nil, annotations of a resource
+// without replacing it
+func (c *appPlatformClient) patchAnnotations(name string, annotations map[string]*string) error {
+	patch := map[string]any{"metadata": map[string]any{"annotations": annotations}}
+	return c.provider.appPlatformRequest(http.MethodPatch, c.path(name), nil, patch, nil)
+}
+
+func (c *appPlatformClient) delete(name string) error {
+	return c.provider.appPlatformRequest(http.MethodDelete, c.path(name), nil, nil, nil)
+}
+
+// appPlatformFor returns a client for the App Platform API of a kind, or nil
+// if the legacy APIs should be used.
+func appPlatformFor(provider grizzly.Provider, group, resource string) (*appPlatformClient, error) {
+	appProvider, ok := provider.(appPlatformProvider)
+	if !ok {
+		return nil, nil
+	}
+	return appProvider.appPlatformResource(group, resource)
+}
@@ -0,0 +1,156 @@
+package grafana
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateVersion(t *testing.T) {
+	supported := []string{"v1beta1", "v0alpha1"}
+
+	require.Equal(t, "v1beta1", negotiateVersion(supported, "v1beta1", []string{"v1beta1", "v0alpha1"}))
+	require.Equal(t, "v0alpha1", negotiateVersion(supported, "v0alpha1", []string{"v1beta1", "v0alpha1"}))
+	require.Equal(t, "v1beta1", negotiateVersion(supported, "v2alpha1", []string{"v2alpha1", "v1beta1", "v0alpha1"}))
+	require.Equal(t, "", negotiateVersion(supported, "v2alpha1", []string{"v2alpha1"}))
+}
+
+func TestAppPlatformDashboards(t *testing.T) {
+	objects := map[string]k8sObject{}
+	var queries []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /apis", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"groups": [{
+			"name": "dashboard.grafana.app",
+			"versions": [{"version": "v2alpha1"}, {"version": "v1beta1"}, {"version": "v0alpha1"}],
+			"preferredVersion": {"version": "v2alpha1"}
+		}]}`))
+	})
+	mux.HandleFunc("GET /api/frontend/settings", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"namespace": "stacks-12"}`))
+	})
+	base := "/apis/dashboard.grafana.app/v1beta1/namespaces/stacks-12/dashboards"
+	mux.HandleFunc("GET "+base, func(w http.ResponseWriter, r *http.Request) {
+		list := k8sList{}
+		for _, object := range objects {
+			list.Items = append(list.Items, object)
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	})
+	mux.HandleFunc("GET "+base+"/{name}", func(w http.ResponseWriter, r *http.Request) {
+		object, ok := objects[r.PathValue("name")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"kind": "Status", "message": "dashboards.dashboard.grafana.app \"missing\" not found", "reason": "NotFound"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(object)
+	})
+	save := func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		var object k8sObject
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&object))
+		require.Equal(t, "dashboard.grafana.app/v1beta1", object.APIVersion)
+		object.Metadata.ResourceVersion += "1"
+		objects[object.Metadata.Name] = object
+		_ = json.NewEncoder(w).Encode(object)
+	}
+	mux.HandleFunc("POST "+base, save)
+	mux.HandleFunc("PUT "+base+"/{name}", save)
+	mux.HandleFunc("PATCH "+base+"/{name}", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/merge-patch+json", r.Header.Get("Content-Type"))
+		var patch struct {
+			Metadata struct {
+				Annotations map[string]*string `json:"annotations"`
+			} `json:"metadata"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&patch))
+		object := objects[r.PathValue("name")]
+		for key, value := range patch.Metadata.Annotations {
+			if value == nil {
+				delete(object.Metadata.Annotations, key)
+				continue
+			}
+			object.Metadata.Annotations[key] = *value
+		}
+		objects[object.Metadata.Name] = object
+		_ = json.NewEncoder(w).Encode(object)
+	})
+	mux.HandleFunc("DELETE "+base+"/{name}", func(w http.ResponseWriter, r *http.Request) {
+		delete(objects, r.PathValue("name"))
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mux.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL})
+	handler := NewDashboardHandler(provider)
+
+	_, err := handler.GetByUID("missing")
+	require.ErrorIs(t, err, grizzly.ErrNotFound)
+
+	resource, err := grizzly.NewResource(provider.APIVersion(), DashboardKind, "my-dashboard", map[string]any{"title": "My dashboard"})
+	require.NoError(t, err)
+	resource.SetMetadata("folder", "my-folder")
+	require.NoError(t, handler.Add(resource))
+	require.Equal(t, "my-folder", objects["my-dashboard"].Metadata.Annotations[folderAnnotation])
+
+	resource.SetSpecValue("title", "Renamed")
+	require.NoError(t, handler.Update(resource, resource))
+	require.Equal(t, "11", objects["my-dashboard"].Metadata.ResourceVersion)
+	require.Equal(t, []string{"fieldValidation=Strict", "fieldValidation=Strict"}, queries)
+
+	moved := resource.DeepCopy()
+	moved.SetMetadata("folder", "other-folder")
+	updated, err := handler.UpdateMetadata(resource, moved)
+	require.NoError(t, err)
+	require.True(t, updated)
+	require.Equal(t, "other-folder", objects["my-dashboard"].Metadata.Annotations[folderAnnotation])
+	require.Equal(t, "11", objects["my-dashboard"].Metadata.ResourceVersion, "no new version is saved")
+
+	remote, err := handler.GetByUID("my-dashboard")
+	require.NoError(t, err)
+	require.Equal(t, "other-folder", remote.GetMetadata("folder"))
+	require.Equal(t, "Renamed", remote.GetSpecValue("title"))
+	require.Equal(t, "my-dashboard", remote.GetSpecValue("uid"))
+
+	uids, err := handler.ListRemote()
+	require.NoError(t, err)
+	require.Equal(t, []string{"my-dashboard"}, uids)
+
+	require.NoError(t, handler.Delete(resource))
+	require.Empty(t, objects)
+}
+
+func TestAppPlatformModes(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	t.Run("auto falls back to legacy APIs", func(t *testing.T) {
+		provider := NewProvider(&config.GrafanaConfig{URL: server.URL})
+		api, err := provider.appPlatformResource(dashboardAPIGroup, "dashboards")
+		require.NoError(t, err)
+		require.Nil(t, api)
+	})
+
+	t.Run("app-platform requires the APIs", func(t *testing.T) {
+		provider := NewProvider(&config.GrafanaConfig{URL: server.URL, APIMode: APIModeAppPlatform})
+		_, err := provider.appPlatformResource(dashboardAPIGroup, "dashboards")
+		require.ErrorContains(t, err, "discovering App Platform APIs")
+	})
+
+	t.Run("legacy doesn't discover APIs", func(t *testing.T) {
+		provider := NewProvider(&config.GrafanaConfig{URL: "http://unreachable.invalid", APIMode: APIModeLegacy})
+		api, err := provider.appPlatformResource(dashboardAPIGroup, "dashboards")
+		require.NoError(t, err)
+		require.Nil(t, api)
+	})
+}
@@ -12,6 +12,8 @@ import (
 const AlertContactPointKind = "AlertContactPoint"
 
 var _ grizzly.Handler = &AlertContactPointHandler{}
+var _ grizzly.LegacyKindsHandler = &AlertContactPointHandler{}
+var _ grizzly.UIDHandler = &AlertContactPointHandler{}
 
 // AlertContactPointHandler is a Grizzly Handler for Grafana contactPoints
 type AlertContactPointHandler struct {
@@ -25,10 +27,20 @@ func NewAlertContactPointHandler(provider grizzly.Provider) *AlertContactPointHa
 	}
 }
 
+// ErrorHint implements grizzly.ErrorHinter
+func (h *AlertContactPointHandler) ErrorHint(code grizzly.ErrorCode) string {
+	return permissionsHint(code, "alert.provisioning")
+}
+
 const (
 	contactPointPattern = "alert-contact-points/contactPoint-%s.%s"
 )
 
+// LegacyKinds returns the kind names previously used for contact point resources
+func (h *AlertContactPointHandler) LegacyKinds() []string {
+	return []string{"ContactPoint"}
+}
+
 // ResourceFilePath returns the location on disk where a resource should be updated
 func (h *AlertContactPointHandler) ResourceFilePath(resource grizzly.Resource, filetype string) string {
 	return fmt.Sprintf(contactPointPattern, resource.Name(), filetype)
@@ -60,6 +72,16 @@ func (h *AlertContactPointHandler) GetSpecUID(resource grizzly.Resource) (string
 	return uid, nil
 }
 
+// UIDConstraints implements grizzly.UIDHandler
+func (h *AlertContactPointHandler) UIDConstraints() grizzly.UIDConstraints {
+	return uidConstraints
+}
+
+// SetUID implements grizzly.UIDHandler
+func (h *AlertContactPointHandler) SetUID(resource grizzly.Resource, uid string) grizzly.Resource {
+	return setSpecUID(resource, uid)
+}
+
 // GetByUID retrieves JSON for a resource from an endpoint, by UID
 func (h *AlertContactPointHandler) GetByUID(uid string) (*grizzly.Resource, error) {
 	return h.getRemoteContactPoint(uid)
@@ -85,6 +107,17 @@ func (h *AlertContactPointHandler) Update(existing, resource grizzly.Resource) e
 	return h.putContactPoint(resource)
 }
 
+// Delete removes a contact point from Grafana via the API
+func (h *AlertContactPointHandler) Delete(resource grizzly.Resource) error {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Provisioning.DeleteContactpoints(resource.Name())
+	return err
+}
+
 // getRemoteContactPoint retrieves a contactPoint object from Grafana
 func (h *AlertContactPointHandler) getRemoteContactPoint(uid string) (*grizzly.Resource, error) {
 	client, err := h.Provider.(ClientProvider).Client()
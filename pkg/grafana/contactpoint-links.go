@@ -0,0 +1,75 @@
+package grafana
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana-openapi-client-go/client/provisioning"
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+var _ grizzly.LinkValidator = &AlertNotificationPolicyHandler{}
+
+// contactPoints looks up the contact points alerts are sent to, by name,
+// locally or else remotely. Remote contact points are listed once.
+type contactPoints struct {
+	remote map[string]bool
+}
+
+// exists reports whether a contact point with the given name exists
+func (c *contactPoints) exists(provider grizzly.Provider, local grizzly.Resources, name string) (bool, error) {
+	for _, contactPoint := range local.OfKind(AlertContactPointKind).AsList() {
+		if localName, _ := contactPoint.GetSpecString("name"); localName == name {
+			return true, nil
+		}
+	}
+
+	if c.remote == nil {
+		client, err := provider.(ClientProvider).Client()
+		if err != nil {
+			return false, err
+		}
+		contactPointsOk, err := client.Provisioning.GetContactpoints(provisioning.NewGetContactpointsParams())
+		if err != nil {
+			return false, err
+		}
+		c.remote = map[string]bool{}
+		for _, contactPoint := range contactPointsOk.GetPayload() {
+			c.remote[contactPoint.Name] = true
+		}
+	}
+	return c.remote[name], nil
+}
+
+// ValidateLinks implements grizzly.LinkValidator, checking that the contact
+// points the routes of the policy tree send alerts to exist, locally or else
+// remotely
+func (h *AlertNotificationPolicyHandler) ValidateLinks(resource grizzly.Resource, local grizzly.Resources) ([]grizzly.BrokenLink, *grizzly.Resource, error) {
+	return h.validateRoute(grizzly.NormaliseJSON(resource.Spec()), "", local)
+}
+
+func (h *AlertNotificationPolicyHandler) validateRoute(route map[string]any, field string, local grizzly.Resources) ([]grizzly.BrokenLink, *grizzly.Resource, error) {
+	var broken []grizzly.BrokenLink
+	if receiver, _ := route["receiver"].(string); receiver != "" {
+		exists, err := h.contactPoints.exists(h.Provider, local, receiver)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !exists {
+			broken = append(broken, grizzly.BrokenLink{
+				Field:   field + "receiver",
+				Message: fmt.Sprintf("alerts are sent to contact point '%s', which doesn't exist", receiver),
+			})
+		}
+	}
+
+	routes, _ := route["routes"].([]any)
+	for i, item := range routes {
+		nested, _ := item.(map[string]any)
+		links, _, err := h.validateRoute(nested, fmt.Sprintf("%sroutes[%d].", field, i), local)
+		if err != nil {
+			return nil, nil, err
+		}
+		broken = append(broken, links...)
+	}
+	return broken, nil, nil
+}
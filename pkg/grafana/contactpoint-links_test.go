@@ -0,0 +1,77 @@
+package grafana
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContactPointLinks(t *testing.T) {
+	var listed int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v1/provisioning/contact-points", r.URL.Path)
+		listed++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"uid": "oncall", "name": "On-call", "type": "webhook", "settings": {}}]`))
+	}))
+	defer server.Close()
+
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL})
+	contactPoint, err := grizzly.NewResource(provider.APIVersion(), AlertContactPointKind, "team-a", map[string]any{
+		"uid":  "team-a",
+		"name": "Team A",
+		"type": "email",
+	})
+	require.NoError(t, err)
+	local := grizzly.NewResources(contactPoint)
+
+	t.Run("policies route alerts to existing contact points", func(t *testing.T) {
+		listed = 0
+		handler := NewAlertNotificationPolicyHandler(provider)
+		policy, err := grizzly.NewResource(provider.APIVersion(), AlertNotificationPolicyKind, GlobalAlertNotificationPolicyName, map[string]any{
+			"receiver": "On-call",
+			"routes": []any{
+				map[string]any{"receiver": "Team A", "object_matchers": []any{[]any{"team", "=", "a"}}},
+				map[string]any{"object_matchers": []any{[]any{"team", "=", "b"}}, "routes": []any{
+					map[string]any{"receiver": "On-call"},
+					map[string]any{"receiver": "Team B"},
+				}},
+				map[string]any{"receiver": "Team C"},
+			},
+		})
+		require.NoError(t, err)
+
+		broken, fixed, err := handler.ValidateLinks(policy, local)
+		require.NoError(t, err)
+		require.Nil(t, fixed)
+		require.Equal(t, []grizzly.BrokenLink{
+			{Field: "routes[1].routes[1].receiver", Message: "alerts are sent to contact point 'Team B', which doesn't exist"},
+			{Field: "routes[2].receiver", Message: "alerts are sent to contact point 'Team C', which doesn't exist"},
+		}, broken)
+		require.Equal(t, 1, listed, "remote contact points are listed once")
+	})
+
+	t.Run("rules send alerts to existing contact points", func(t *testing.T) {
+		handler := NewAlertRuleGroupHandler(provider)
+		group, err := grizzly.NewResource(provider.APIVersion(), AlertRuleGroupKind, "folder.group", map[string]any{
+			"rules": []any{
+				map[string]any{"title": "Errors", "notification_settings": map[string]any{"receiver": "Team A"}},
+				map[string]any{"title": "Latency", "notification_settings": map[string]any{"receiver": "On-call"}},
+				map[string]any{"title": "Saturation", "notification_settings": map[string]any{"receiver": "Team B"}},
+				map[string]any{"title": "Routed", "labels": map[string]any{"team": "a"}},
+			},
+		})
+		require.NoError(t, err)
+
+		broken, fixed, err := handler.ValidateLinks(group, local)
+		require.NoError(t, err)
+		require.Nil(t, fixed)
+		require.Equal(t, []grizzly.BrokenLink{
+			{Field: "rules[2].notification_settings.receiver", Message: "rule 'Saturation' sends its alerts to contact point 'Team B', which doesn't exist"},
+		}, broken)
+	})
+}
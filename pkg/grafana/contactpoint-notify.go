@@ -0,0 +1,172 @@
+package grafana
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/grafana/grafana-openapi-client-go/client/provisioning"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+const testReceiversPath = "/alertmanager/grafana/config/api/v1/receivers/test"
+
+// redactedValue replaces the secure settings of contact points retrieved
+// from Grafana
+const redactedValue = "[REDACTED]"
+
+// ContactPointTestResult is the outcome of sending a test notification
+// through a contact point
+type ContactPointTestResult struct {
+	UID   string
+	Name  string
+	Type  string
+	Error string
+}
+
+func (result ContactPointTestResult) String() string {
+	return fmt.Sprintf("%s.%s", AlertContactPointKind, result.UID)
+}
+
+type testReceivers struct {
+	Receivers []testReceiver `json:"receivers"`
+}
+
+type testReceiver struct {
+	Name    string               `json:"name"`
+	Configs []testReceiverConfig `json:"grafana_managed_receiver_configs"`
+}
+
+type testReceiverConfig struct {
+	UID                   string         `json:"uid"`
+	Name                  string         `json:"name"`
+	Type                  string         `json:"type,omitempty"`
+	Settings              map[string]any `json:"settings,omitempty"`
+	DisableResolveMessage bool           `json:"disableResolveMessage"`
+	Status                string         `json:"status,omitempty"`
+	Error                 string         `json:"error,omitempty"`
+}
+
+// TestContactPoint sends a test notification through the remote contact
+// point with the given UID, or through every integration of the contact point
+// with the given name. Secure settings, such as credentials, are those stored
+// by Grafana, so that their rotation can be verified.
+func TestContactPoint(registry grizzly.Registry, contactPoint string) ([]ContactPointTestResult, error) {
+	handler, err := registry.GetHandler(AlertContactPointKind)
+	if err != nil {
+		return nil, err
+	}
+	contactPointHandler, ok := handler.(*AlertContactPointHandler)
+	if !ok {
+		return nil, fmt.Errorf("kind %s isn't handled by Grafana", AlertContactPointKind)
+	}
+	results, err := contactPointHandler.testContactPoint(contactPoint)
+	if err != nil {
+		return nil, grizzly.ClassifyError(handler, err)
+	}
+	return results, nil
+}
+
+func (h *AlertContactPointHandler) testContactPoint(contactPoint string) ([]ContactPointTestResult, error) {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+	contactPointsOk, err := client.Provisioning.GetContactpoints(provisioning.NewGetContactpointsParams())
+	if err != nil {
+		return nil, err
+	}
+	integrations := selectContactPoint(contactPointsOk.GetPayload(), contactPoint)
+	if len(integrations) == 0 {
+		return nil, fmt.Errorf("contact point %s: %w", contactPoint, grizzly.ErrNotFound)
+	}
+
+	receiver := testReceiver{Name: integrations[0].Name}
+	for _, integration := range integrations {
+		receiver.Configs = append(receiver.Configs, testReceiverConfig{
+			UID:                   integration.UID,
+			Name:                  integration.Name,
+			Type:                  stringValue(integration.Type),
+			Settings:              withoutRedactedSettings(integration.Settings),
+			DisableResolveMessage: integration.DisableResolveMessage,
+		})
+	}
+
+	var result testReceivers
+	_, err = client.Transport.Submit(&runtime.ClientOperation{
+		ID:                 "TestReceivers",
+		Method:             http.MethodPost,
+		PathPattern:        testReceiversPath,
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"http", "https"},
+		Params: runtime.ClientRequestWriterFunc(func(req runtime.ClientRequest, _ strfmt.Registry) error {
+			return req.SetBodyParam(testReceivers{Receivers: []testReceiver{receiver}})
+		}),
+		Reader: runtime.ClientResponseReaderFunc(func(response runtime.ClientResponse, _ runtime.Consumer) (any, error) {
+			// some notifications failing, or timing out, is reported per integration
+			switch response.Code() {
+			case http.StatusOK, http.StatusMultiStatus, http.StatusRequestTimeout:
+				return nil, json.NewDecoder(response.Body()).Decode(&result)
+			default:
+				return nil, runtime.NewAPIError(fmt.Sprintf("[POST %s]", testReceiversPath), response, response.Code())
+			}
+		}),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var results []ContactPointTestResult
+	for _, tested := range result.Receivers {
+		for _, config := range tested.Configs {
+			testResult := ContactPointTestResult{UID: config.UID, Name: tested.Name, Type: config.Type, Error: config.Error}
+			if testResult.Error == "" && config.Status != "" && config.Status != "ok" {
+				testResult.Error = config.Status
+			}
+			results = append(results, testResult)
+		}
+	}
+	return results, nil
+}
+
+// selectContactPoint returns the integration with the given UID or, when
+// there's none, the integrations of the contact point with the given name
+func selectContactPoint(contactPoints []*models.EmbeddedContactPoint, contactPoint string) []*models.EmbeddedContactPoint {
+	var named []*models.EmbeddedContactPoint
+	for _, integration := range contactPoints {
+		if integration.UID == contactPoint {
+			return []*models.EmbeddedContactPoint{integration}
+		}
+		if integration.Name == contactPoint {
+			named = append(named, integration)
+		}
+	}
+	return named
+}
+
+// withoutRedactedSettings removes the redacted secure settings of a contact
+// point, so that Grafana uses the ones it stores instead
+func withoutRedactedSettings(settings any) map[string]any {
+	values, ok := settings.(map[string]any)
+	if !ok {
+		return nil
+	}
+	kept := make(map[string]any, len(values))
+	for key, value := range values {
+		if value != redactedValue {
+			kept[key] = value
+		}
+	}
+	return kept
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
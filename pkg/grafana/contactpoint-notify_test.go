@@ -0,0 +1,64 @@
+package grafana
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestContactPoint(t *testing.T) {
+	var tested testReceivers
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/provisioning/contact-points", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"uid": "oncall-slack", "name": "oncall", "type": "slack", "settings": {"recipient": "#oncall", "token": "[REDACTED]"}},
+			{"uid": "oncall-email", "name": "oncall", "type": "email", "settings": {"addresses": "oncall@example.com"}},
+			{"uid": "team-a", "name": "team-a", "type": "email", "settings": {"addresses": "a@example.com"}}
+		]`))
+	})
+	mux.HandleFunc("POST /api/alertmanager/grafana/config/api/v1/receivers/test", func(w http.ResponseWriter, r *http.Request) {
+		tested = testReceivers{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&tested))
+		response := tested
+		for i, config := range response.Receivers[0].Configs {
+			response.Receivers[0].Configs[i].Status = "ok"
+			if config.Type == "slack" {
+				response.Receivers[0].Configs[i].Status = "failed"
+				response.Receivers[0].Configs[i].Error = "invalid_auth"
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMultiStatus)
+		require.NoError(t, json.NewEncoder(w).Encode(response))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	registry := grizzly.NewRegistry([]grizzly.Provider{NewProvider(&config.GrafanaConfig{URL: server.URL})})
+
+	t.Run("by name, with the stored secure settings", func(t *testing.T) {
+		results, err := TestContactPoint(registry, "oncall")
+		require.NoError(t, err)
+		require.Equal(t, []ContactPointTestResult{
+			{UID: "oncall-slack", Name: "oncall", Type: "slack", Error: "invalid_auth"},
+			{UID: "oncall-email", Name: "oncall", Type: "email"},
+		}, results)
+		require.Equal(t, map[string]any{"recipient": "#oncall"}, tested.Receivers[0].Configs[0].Settings)
+	})
+
+	t.Run("by UID", func(t *testing.T) {
+		results, err := TestContactPoint(registry, "oncall-email")
+		require.NoError(t, err)
+		require.Equal(t, []ContactPointTestResult{{UID: "oncall-email", Name: "oncall", Type: "email"}}, results)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := TestContactPoint(registry, "missing")
+		require.ErrorIs(t, err, grizzly.ErrNotFound)
+	})
+}
@@ -0,0 +1,216 @@
+package grafana
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+// dashboardIgnoredFields are managed by Grafana, and not worth reporting
+var dashboardIgnoredFields = map[string]bool{
+	"id":      true,
+	"uid":     true,
+	"version": true,
+	"panels":  true,
+}
+
+// panelChanges names the changes made to well-known panel fields. Fields not
+// listed here are reported by name.
+var panelChanges = []struct {
+	field       string
+	description string
+}{
+	{field: "targets", description: "query changed"},
+	{field: "datasource", description: "datasource changed"},
+	{field: "transformations", description: "transformations changed"},
+	{field: "options", description: "options changed"},
+	{field: "gridPos", description: "layout changed"},
+	{field: "description", description: "description changed"},
+	{field: "links", description: "links changed"},
+}
+
+// DiffSummary implements grizzly.DiffSummarizer, listing the changes made to
+// each panel of a dashboard
+func (h *DashboardHandler) DiffSummary(local, remote grizzly.Resource) []string {
+	return dashboardDiffSummary(grizzly.NormaliseJSON(local.Spec()), grizzly.NormaliseJSON(remote.Spec()))
+}
+
+func dashboardDiffSummary(local, remote map[string]any) []string {
+	var summary []string
+
+	var dashboardChanges []string
+	for _, key := range changedKeys(local, remote) {
+		if !dashboardIgnoredFields[key] {
+			dashboardChanges = append(dashboardChanges, key)
+		}
+	}
+	if len(dashboardChanges) > 0 {
+		summary = append(summary, fmt.Sprintf("dashboard: %s changed", strings.Join(dashboardChanges, ", ")))
+	}
+
+	localPanels := dashboardPanels(local)
+	remotePanels := dashboardPanels(remote)
+	matched := map[int]bool{}
+
+	for _, panel := range localPanels {
+		index := matchPanel(panel, remotePanels, matched)
+		if index < 0 {
+			summary = append(summary, fmt.Sprintf("panel %s: added", panelName(panel)))
+			continue
+		}
+		matched[index] = true
+
+		if changes := panelDiff(panel, remotePanels[index]); len(changes) > 0 {
+			summary = append(summary, fmt.Sprintf("panel %s: %s", panelName(panel), strings.Join(changes, ", ")))
+		}
+	}
+
+	for i, panel := range remotePanels {
+		if !matched[i] {
+			summary = append(summary, fmt.Sprintf("panel %s: removed", panelName(panel)))
+		}
+	}
+
+	return summary
+}
+
+// dashboardPanels lists the panels of a dashboard, including those nested in collapsed rows
+func dashboardPanels(spec map[string]any) []map[string]any {
+	var panels []map[string]any
+
+	list, _ := spec["panels"].([]any)
+	for _, item := range list {
+		panel, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		panels = append(panels, panel)
+
+		nested, _ := panel["panels"].([]any)
+		for _, child := range nested {
+			if childPanel, ok := child.(map[string]any); ok {
+				panels = append(panels, childPanel)
+			}
+		}
+	}
+
+	return panels
+}
+
+// matchPanel finds the remote version of a panel, by ID or else by title
+func matchPanel(panel map[string]any, candidates []map[string]any, matched map[int]bool) int {
+	if id, ok := panel["id"]; ok {
+		for i, candidate := range candidates {
+			if !matched[i] && candidate["id"] == id {
+				return i
+			}
+		}
+	}
+
+	title, _ := panel["title"].(string)
+	if title == "" {
+		return -1
+	}
+	for i, candidate := range candidates {
+		if !matched[i] && candidate["title"] == title {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func panelDiff(local, remote map[string]any) []string {
+	var changes []string
+
+	if local["title"] != remote["title"] {
+		changes = append(changes, fmt.Sprintf("renamed from %s", panelName(remote)))
+	}
+	if local["type"] != remote["type"] {
+		changes = append(changes, fmt.Sprintf("visualization changed from %v to %v", remote["type"], local["type"]))
+	}
+
+	reported := map[string]bool{"id": true, "title": true, "type": true, "fieldConfig": true, "panels": true}
+	for _, change := range panelChanges {
+		reported[change.field] = true
+		if !reflect.DeepEqual(local[change.field], remote[change.field]) {
+			changes = append(changes, change.description)
+		}
+	}
+
+	localFieldConfig, _ := local["fieldConfig"].(map[string]any)
+	remoteFieldConfig, _ := remote["fieldConfig"].(map[string]any)
+	if !reflect.DeepEqual(thresholds(localFieldConfig), thresholds(remoteFieldConfig)) {
+		changes = append(changes, "thresholds changed")
+	}
+	if !reflect.DeepEqual(withoutThresholds(localFieldConfig), withoutThresholds(remoteFieldConfig)) {
+		changes = append(changes, "field config changed")
+	}
+
+	for _, key := range changedKeys(local, remote) {
+		if !reported[key] {
+			changes = append(changes, key+" changed")
+		}
+	}
+
+	return changes
+}
+
+func thresholds(fieldConfig map[string]any) any {
+	defaults, _ := fieldConfig["defaults"].(map[string]any)
+	return defaults["thresholds"]
+}
+
+// withoutThresholds copies a field config, leaving out its default thresholds
+func withoutThresholds(fieldConfig map[string]any) map[string]any {
+	if fieldConfig == nil {
+		return nil
+	}
+
+	result := make(map[string]any, len(fieldConfig))
+	for key, value := range fieldConfig {
+		result[key] = value
+	}
+	if defaults, ok := fieldConfig["defaults"].(map[string]any); ok {
+		copied := make(map[string]any, len(defaults))
+		for key, value := range defaults {
+			if key != "thresholds" {
+				copied[key] = value
+			}
+		}
+		result["defaults"] = copied
+	}
+
+	return result
+}
+
+// changedKeys lists, sorted, the keys whose values differ between two objects
+func changedKeys(a, b map[string]any) []string {
+	keys := map[string]bool{}
+	for key := range a {
+		keys[key] = true
+	}
+	for key := range b {
+		keys[key] = true
+	}
+
+	var changed []string
+	for key := range keys {
+		if !reflect.DeepEqual(a[key], b[key]) {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+
+	return changed
+}
+
+func panelName(panel map[string]any) string {
+	if title, ok := panel["title"].(string); ok && title != "" {
+		return fmt.Sprintf("'%s'", title)
+	}
+	return fmt.Sprintf("#%v", panel["id"])
+}
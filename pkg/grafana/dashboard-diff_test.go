@@ -0,0 +1,70 @@
+package grafana
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboardDiffSummary(t *testing.T) {
+	remote := map[string]any{
+		"title":   "Service",
+		"version": float64(3),
+		"panels": []any{
+			map[string]any{
+				"id":      float64(1),
+				"title":   "P99 latency",
+				"type":    "timeseries",
+				"targets": []any{map[string]any{"expr": "histogram_quantile(0.99, rate(x[5m]))"}},
+				"fieldConfig": map[string]any{
+					"defaults": map[string]any{
+						"thresholds": map[string]any{"steps": []any{float64(1)}},
+						"unit":       "s",
+					},
+				},
+			},
+			map[string]any{"id": float64(2), "title": "Saturation", "type": "stat"},
+			map[string]any{
+				"id":        float64(3),
+				"title":     "Details",
+				"type":      "row",
+				"collapsed": true,
+				"panels":    []any{map[string]any{"id": float64(4), "title": "Old name", "type": "table"}},
+			},
+		},
+	}
+	local := map[string]any{
+		"title":   "Service overview",
+		"version": float64(1),
+		"panels": []any{
+			map[string]any{
+				"id":      float64(1),
+				"title":   "P99 latency",
+				"type":    "timeseries",
+				"targets": []any{map[string]any{"expr": "histogram_quantile(0.99, rate(x[1m]))"}},
+				"fieldConfig": map[string]any{
+					"defaults": map[string]any{
+						"thresholds": map[string]any{"steps": []any{float64(2)}},
+						"unit":       "s",
+					},
+				},
+			},
+			map[string]any{"id": float64(5), "title": "Errors", "type": "timeseries"},
+			map[string]any{
+				"id":        float64(3),
+				"title":     "Details",
+				"type":      "row",
+				"collapsed": true,
+				"panels":    []any{map[string]any{"id": float64(4), "title": "New name", "type": "table"}},
+			},
+		},
+	}
+
+	require.Equal(t, []string{
+		"dashboard: title changed",
+		"panel 'P99 latency': query changed, thresholds changed",
+		"panel 'Errors': added",
+		"panel 'New name': renamed from 'Old name'",
+		"panel 'Saturation': removed",
+	}, dashboardDiffSummary(local, remote))
+}
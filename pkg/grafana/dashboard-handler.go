@@ -2,15 +2,21 @@ package grafana
 
 import (
 	_ "embed"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana-openapi-client-go/client/dashboards"
 	"github.com/grafana/grafana-openapi-client-go/client/search"
+	"github.com/grafana/grafana-openapi-client-go/client/snapshots"
 	"github.com/grafana/grafana-openapi-client-go/models"
 	"github.com/grafana/grizzly/pkg/grizzly"
 	"github.com/grafana/grizzly/pkg/grizzly/notifier"
+	"golang.org/x/sync/errgroup"
 )
 
 // Moved from utils.go
@@ -20,7 +26,14 @@ const generalFolderUID = "general"
 const DashboardKind = "Dashboard"
 
 var _ grizzly.Handler = &DashboardHandler{}
+var _ grizzly.BatchGetHandler = &DashboardHandler{}
+var _ grizzly.RemoteVersioner = &DashboardHandler{}
+var _ grizzly.PreviewHandler = &DashboardHandler{}
 var _ grizzly.ProxyConfiguratorProvider = &DashboardHandler{}
+var _ grizzly.TagHandler = &DashboardHandler{}
+var _ grizzly.UIDHandler = &DashboardHandler{}
+var _ grizzly.MetadataUpdater = &DashboardHandler{}
+var _ grizzly.RemoteValidator = &DashboardHandler{}
 
 // DashboardHandler is a Grizzly Handler for Grafana dashboards
 type DashboardHandler struct {
@@ -34,8 +47,16 @@ func NewDashboardHandler(provider grizzly.Provider) *DashboardHandler {
 	}
 }
 
+// ErrorHint implements grizzly.ErrorHinter
+func (h *DashboardHandler) ErrorHint(code grizzly.ErrorCode) string {
+	return permissionsHint(code, "dashboards")
+}
+
 const (
 	dashboardPattern = "dashboards/%s/dashboard-%s.%s"
+
+	// dashboardBatchConcurrency is the number of dashboards retrieved in parallel by GetByUIDs
+	dashboardBatchConcurrency = 10
 )
 
 // ProxyConfigurator provides a configurator object describing how to proxy dashboards.
@@ -54,23 +75,87 @@ func (h *DashboardHandler) ResourceFilePath(resource grizzly.Resource, filetype
 func (h *DashboardHandler) Unprepare(resource grizzly.Resource) *grizzly.Resource {
 	resource.DeleteSpecKey("id")
 	resource.DeleteSpecKey("version")
+	stripLibraryPanelMeta(resource)
 	return &resource
 }
 
 // Prepare gets a resource ready for dispatch to the remote endpoint
 func (h *DashboardHandler) Prepare(existing *grizzly.Resource, resource grizzly.Resource) *grizzly.Resource {
-	if !resource.HasSpecString("uid") {
+	if _, ok := dashboardUID(resource); !ok {
 		resource.SetSpecString("uid", resource.Name())
 	}
 	if !resource.HasMetadata("folder") {
 		resource.SetMetadata("folder", generalFolderUID)
 	}
+	stripLibraryPanelMeta(resource)
 	return &resource
 }
 
+// Tags implements grizzly.TagHandler
+func (h *DashboardHandler) Tags(resource grizzly.Resource) []string {
+	dashboard, ok := decodeDashboard(resource)
+	if !ok {
+		return nil
+	}
+	return dashboard.Spec.Tags
+}
+
+// SetTags implements grizzly.TagHandler
+func (h *DashboardHandler) SetTags(resource grizzly.Resource, tags []string) grizzly.Resource {
+	if dashboard, ok := decodeDashboard(resource); ok {
+		dashboard.Spec.Tags = tags
+		if err := dashboard.Apply(&resource); err == nil {
+			return resource
+		}
+	}
+	list := make([]any, len(tags))
+	for i, tag := range tags {
+		list[i] = tag
+	}
+	resource.SetSpecValue("tags", list)
+	return resource
+}
+
+// decodeDashboard decodes the spec of a dashboard as a DashboardSpec. Specs
+// that don't match it are still applied as they are, so callers fall back to
+// the untyped spec when it can't be decoded.
+func decodeDashboard(resource grizzly.Resource) (*grizzly.TypedSpec[DashboardSpec], bool) {
+	dashboard, err := grizzly.DecodeSpec[DashboardSpec](resource)
+	if err != nil {
+		return nil, false
+	}
+	return dashboard, true
+}
+
+// dashboardUID returns the UID in the spec of a dashboard
+func dashboardUID(resource grizzly.Resource) (string, bool) {
+	if dashboard, ok := decodeDashboard(resource); ok {
+		return dashboard.Spec.UID, dashboard.Spec.UID != ""
+	}
+	return resource.GetSpecString("uid")
+}
+
+// stripLibraryPanelMeta reduces the library panels of a dashboard to the UID and
+// name they are connected by. Grafana adds the metadata of the library element
+// (connected dashboards, authors, timestamps, version...) when returning
+// dashboards, which would otherwise always show up as changes.
+func stripLibraryPanelMeta(resource grizzly.Resource) {
+	for _, panel := range dashboardPanels(resource.Spec()) {
+		libraryPanel, ok := panel["libraryPanel"].(map[string]any)
+		if !ok {
+			continue
+		}
+		for key := range libraryPanel {
+			if key != "uid" && key != "name" {
+				delete(libraryPanel, key)
+			}
+		}
+	}
+}
+
 // Validate returns the uid of resource
 func (h *DashboardHandler) Validate(resource grizzly.Resource) error {
-	uid, exist := resource.GetSpecString("uid")
+	uid, exist := dashboardUID(resource)
 	if resource.Name() != uid && exist {
 		return fmt.Errorf("uid '%s' and name '%s', don't match", uid, resource.Name())
 	}
@@ -78,13 +163,39 @@ func (h *DashboardHandler) Validate(resource grizzly.Resource) error {
 }
 
 func (h *DashboardHandler) GetSpecUID(resource grizzly.Resource) (string, error) {
-	uid, ok := resource.GetSpecString("uid")
+	uid, ok := dashboardUID(resource)
 	if !ok {
 		return "", fmt.Errorf("UID not specified")
 	}
 	return uid, nil
 }
 
+// UIDConstraints implements grizzly.UIDHandler
+func (h *DashboardHandler) UIDConstraints() grizzly.UIDConstraints {
+	return uidConstraints
+}
+
+// SetUID implements grizzly.UIDHandler
+func (h *DashboardHandler) SetUID(resource grizzly.Resource, uid string) grizzly.Resource {
+	return setSpecUID(resource, uid)
+}
+
+// UpdateMetadata implements grizzly.MetadataUpdater. With the App Platform
+// API, dashboards are moved by patching their folder annotation, so that no
+// new version is saved. The legacy API requires a full update.
+func (h *DashboardHandler) UpdateMetadata(existing, resource grizzly.Resource) (bool, error) {
+	api, err := h.appPlatform()
+	if err != nil || api == nil {
+		return false, err
+	}
+
+	var folder *string
+	if folderUID := resource.GetMetadata("folder"); folderUID != "" && !strings.EqualFold(folderUID, DefaultFolder) {
+		folder = &folderUID
+	}
+	return true, api.patchAnnotations(resource.Name(), map[string]*string{folderAnnotation: folder})
+}
+
 // GetByUID retrieves JSON for a resource from an endpoint, by UID
 func (h *DashboardHandler) GetByUID(uid string) (*grizzly.Resource, error) {
 	resource, err := h.getRemoteDashboard(uid)
@@ -94,15 +205,122 @@ func (h *DashboardHandler) GetByUID(uid string) (*grizzly.Resource, error) {
 	return resource, nil
 }
 
+// GetByUIDs retrieves dashboards concurrently, as Grafana doesn't provide a
+// bulk export endpoint for them
+func (h *DashboardHandler) GetByUIDs(uids []string) []grizzly.BatchGetResult {
+	results := make([]grizzly.BatchGetResult, len(uids))
+
+	// Ensure the client is initialised before it is shared between goroutines
+	if _, err := h.Provider.(ClientProvider).Client(); err != nil {
+		for i, uid := range uids {
+			results[i] = grizzly.BatchGetResult{UID: uid, Err: err}
+		}
+		return results
+	}
+
+	var group errgroup.Group
+	group.SetLimit(dashboardBatchConcurrency)
+	for i, uid := range uids {
+		group.Go(func() error {
+			resource, err := h.GetByUID(uid)
+			results[i] = grizzly.BatchGetResult{UID: uid, Resource: resource, Err: err}
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	return results
+}
+
+// RemoteVersion implements grizzly.RemoteVersioner, with the latest version
+// in the history of the dashboard, much smaller than the dashboard itself,
+// and its folder: moving dashboards through the App Platform API doesn't save
+// a new version
+func (h *DashboardHandler) RemoteVersion(uid string) (string, error) {
+	provider, ok := h.Provider.(*Provider)
+	if !ok {
+		return "", nil
+	}
+	var history json.RawMessage
+	if err := provider.appPlatformRequest(http.MethodGet, "/api/dashboards/uid/"+url.PathEscape(uid)+"/versions", url.Values{"limit": {"1"}}, nil, &history); err != nil {
+		return "", err
+	}
+
+	// Grafana 12 pages the history of dashboards, which older versions list
+	type dashboardVersion struct {
+		DashboardID int64 `json:"dashboardId"`
+		Version     int64 `json:"version"`
+	}
+	var versions []dashboardVersion
+	if err := json.Unmarshal(history, &versions); err != nil {
+		var paged struct {
+			Versions []dashboardVersion `json:"versions"`
+		}
+		if err := json.Unmarshal(history, &paged); err != nil {
+			return "", err
+		}
+		versions = paged.Versions
+	}
+	if len(versions) == 0 {
+		return "", nil
+	}
+
+	var hits []struct {
+		FolderUID string `json:"folderUid"`
+	}
+	if err := provider.appPlatformRequest(http.MethodGet, "/api/search", url.Values{"dashboardUIDs": {uid}, "type": {"dash-db"}}, nil, &hits); err != nil {
+		return "", err
+	}
+	if len(hits) == 0 {
+		return "", nil
+	}
+	return dashboardVersionOf(provider.config.URL, versions[0].DashboardID, versions[0].Version, hits[0].FolderUID), nil
+}
+
+// ResourceVersion implements grizzly.RemoteVersioner, with the ID, the
+// version and the folder of the dashboard retrieved
+func (h *DashboardHandler) ResourceVersion(resource grizzly.Resource) string {
+	provider, ok := h.Provider.(*Provider)
+	if !ok {
+		return ""
+	}
+	dashboard, ok := decodeDashboard(resource)
+	if !ok || dashboard.Spec.Version == 0 {
+		return ""
+	}
+	return dashboardVersionOf(provider.config.URL, dashboard.Spec.ID, dashboard.Spec.Version, resource.GetMetadata("folder"))
+}
+
+// dashboardVersionOf identifies a version of a dashboard of a Grafana
+// instance, in a folder. Dashboards deleted and created again restart at
+// version 1, but get another ID.
+func dashboardVersionOf(grafanaURL string, id, version int64, folderUID string) string {
+	// the General folder has no UID in search results
+	if folderUID == "" || strings.EqualFold(folderUID, DefaultFolder) {
+		folderUID = generalFolderUID
+	}
+	return fmt.Sprintf("%s#%d/%d/%s", strings.TrimSuffix(grafanaURL, "/"), id, version, folderUID)
+}
+
 // GetRemote retrieves a dashboard as a resource
 func (h *DashboardHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
-	uid, _ := resource.GetSpecString("uid")
+	uid, _ := dashboardUID(resource)
 	if uid != resource.Name() {
 		return nil, fmt.Errorf("uid '%s' and name '%s', don't match", uid, resource.Name())
 	}
 	return h.getRemoteDashboard(resource.Name())
 }
 
+// DescribeRemote returns the inventory entry of a dashboard, including when
+// and by whom it was last changed
+func (h *DashboardHandler) DescribeRemote(uid string) (*grizzly.InventoryEntry, error) {
+	resource, changes, err := h.getRemoteDashboardWithChanges(uid)
+	if err != nil {
+		return nil, err
+	}
+	return changes.describe(*h.Unprepare(*resource)), nil
+}
+
 // ListRemote retrieves as list of UIDs of all remote resources
 func (h *DashboardHandler) ListRemote() ([]string, error) {
 	return h.getRemoteDashboardList()
@@ -120,6 +338,42 @@ func (h *DashboardHandler) Update(existing, resource grizzly.Resource) error {
 	return h.postDashboard(resource)
 }
 
+// ValidateRemote implements grizzly.RemoteValidator. Grafana validates
+// dashboards with dry-run requests of the App Platform API. The legacy API has
+// no equivalent, so only the folder of dashboards is checked with it.
+func (h *DashboardHandler) ValidateRemote(existing *grizzly.Resource, resource grizzly.Resource) error {
+	resource = *h.Unprepare(resource)
+	api, err := h.appPlatform()
+	if err != nil {
+		return err
+	}
+	if api != nil {
+		return api.validate(h.dashboardToObject(resource))
+	}
+
+	_, err = h.dashboardFolder(resource)
+	return err
+}
+
+// Delete removes a dashboard from Grafana via the API
+func (h *DashboardHandler) Delete(resource grizzly.Resource) error {
+	api, err := h.appPlatform()
+	if err != nil {
+		return err
+	}
+	if api != nil {
+		return api.delete(resource.Name())
+	}
+
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Dashboards.DeleteDashboardByUID(resource.Name())
+	return err
+}
+
 // Snapshot pushes dashboards as snapshots
 func (h *DashboardHandler) Snapshot(resource grizzly.Resource, expiresSeconds int) error {
 	s, err := h.postSnapshot(resource, expiresSeconds)
@@ -135,35 +389,138 @@ func (h *DashboardHandler) Snapshot(resource grizzly.Resource, expiresSeconds in
 	return nil
 }
 
+// Preview publishes a dashboard as a snapshot, named after the preview label so that it can be cleaned up later
+func (h *DashboardHandler) Preview(resource grizzly.Resource, label string, expires time.Duration) (*grizzly.PublishedPreview, error) {
+	title, _ := resource.GetSpecString("title")
+	ref := resource.Ref().String()
+
+	body := models.CreateDashboardSnapshotCommand{
+		Dashboard: &models.Unstructured{Object: resource.Spec()},
+		Name:      fmt.Sprintf("%s %s: %s", previewTag(label), ref, title),
+		Expires:   int64(expires.Seconds()),
+	}
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := client.Snapshots.CreateDashboardSnapshot(&body, nil)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := response.GetPayload()
+
+	return &grizzly.PublishedPreview{
+		Resource:  ref,
+		Label:     label,
+		URL:       snapshot.URL,
+		DeleteURL: snapshot.DeleteURL,
+	}, nil
+}
+
+// DeletePreviews deletes the snapshots published as previews with the given label
+func (h *DashboardHandler) DeletePreviews(label string) ([]grizzly.PublishedPreview, error) {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+
+	tag := previewTag(label)
+	limit := int64(1000)
+	params := snapshots.NewSearchDashboardSnapshotsParams().WithQuery(&tag).WithLimit(&limit)
+	searchOk, err := client.Snapshots.SearchDashboardSnapshots(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted []grizzly.PublishedPreview
+	for _, snapshot := range searchOk.GetPayload() {
+		// The search matches names containing the tag anywhere
+		if !strings.HasPrefix(snapshot.Name, tag+" ") {
+			continue
+		}
+		if _, err := client.Snapshots.DeleteDashboardSnapshot(snapshot.Key); err != nil {
+			return deleted, fmt.Errorf("deleting snapshot %s: %w", snapshot.Key, err)
+		}
+
+		ref, _, _ := strings.Cut(strings.TrimPrefix(snapshot.Name, tag+" "), ": ")
+		deleted = append(deleted, grizzly.PublishedPreview{
+			Resource: ref,
+			Label:    label,
+		})
+	}
+
+	return deleted, nil
+}
+
+func previewTag(label string) string {
+	return fmt.Sprintf("[grizzly-preview:%s]", label)
+}
+
+// appPlatform returns a client for the App Platform dashboards API, or nil
+// if the legacy API should be used
+func (h *DashboardHandler) appPlatform() (*appPlatformClient, error) {
+	return appPlatformFor(h.Provider, dashboardAPIGroup, "dashboards")
+}
+
 // getRemoteDashboard retrieves a dashboard object from Grafana
 func (h *DashboardHandler) getRemoteDashboard(uid string) (*grizzly.Resource, error) {
+	resource, _, err := h.getRemoteDashboardWithChanges(uid)
+	return resource, err
+}
+
+// getRemoteDashboardWithChanges retrieves a dashboard, along with when and by
+// whom it was last changed
+func (h *DashboardHandler) getRemoteDashboardWithChanges(uid string) (*grizzly.Resource, remoteChanges, error) {
+	api, err := h.appPlatform()
+	if err != nil {
+		return nil, remoteChanges{}, err
+	}
+	if api != nil {
+		object, err := api.get(uid)
+		if err != nil {
+			return nil, remoteChanges{}, err
+		}
+		resource, err := h.dashboardFromObject(*object)
+		return resource, objectChanges(*object), err
+	}
+
 	client, err := h.Provider.(ClientProvider).Client()
 	if err != nil {
-		return nil, err
+		return nil, remoteChanges{}, err
 	}
 	dashboardOk, err := client.Dashboards.GetDashboardByUID(uid)
 	if err != nil {
 		var gErr *dashboards.GetDashboardByUIDNotFound
 		if errors.As(err, &gErr) {
-			return nil, grizzly.ErrNotFound
+			return nil, remoteChanges{}, grizzly.ErrNotFound
 		}
-		return nil, err
+		return nil, remoteChanges{}, err
 	}
 	dashboard := dashboardOk.GetPayload()
 
 	// TODO: Turn spec into a real models.DashboardFullWithMeta object
 	spec, err := structToMap(dashboard.Dashboard)
 	if err != nil {
-		return nil, err
+		return nil, remoteChanges{}, err
 	}
 
 	resource, err := grizzly.NewResource(h.APIVersion(), h.Kind(), uid, spec)
 	if err != nil {
-		return nil, err
+		return nil, remoteChanges{}, err
 	}
 	folderUID := extractFolderUID(client, *dashboard)
 	resource.SetMetadata("folder", folderUID)
-	return &resource, nil
+
+	changes := remoteChanges{}
+	if dashboard.Meta != nil {
+		changes = remoteChanges{
+			updated:   time.Time(dashboard.Meta.Updated),
+			updatedBy: dashboard.Meta.UpdatedBy,
+			createdBy: dashboard.Meta.CreatedBy,
+		}
+	}
+	return &resource, changes, nil
 }
 
 func (h *DashboardHandler) getRemoteDashboardList() ([]string, error) {
@@ -174,6 +531,21 @@ func (h *DashboardHandler) getRemoteDashboardList() ([]string, error) {
 		uids       []string
 	)
 
+	api, err := h.appPlatform()
+	if err != nil {
+		return nil, err
+	}
+	if api != nil {
+		objects, err := api.list()
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range objects {
+			uids = append(uids, object.Metadata.Name)
+		}
+		return uids, nil
+	}
+
 	client, err := h.Provider.(ClientProvider).Client()
 	if err != nil {
 		return nil, err
@@ -199,26 +571,23 @@ func (h *DashboardHandler) getRemoteDashboardList() ([]string, error) {
 }
 
 func (h *DashboardHandler) postDashboard(resource grizzly.Resource) error {
-	folderUID := resource.GetMetadata("folder")
-	var folderID int64
-	if !(folderUID == DefaultFolder || folderUID == strings.ToLower(DefaultFolder)) {
-		folderHandler := NewFolderHandler(h.Provider)
-		folder, err := folderHandler.getRemoteFolder(folderUID)
-		if err != nil {
-			if errors.Is(err, grizzly.ErrNotFound) {
-				return fmt.Errorf("cannot upload dashboard %s as folder %s not found", resource.Name(), folderUID)
-			} else {
-				return fmt.Errorf("cannot upload dashboard %s: %w", resource.Name(), err)
-			}
-		}
-		folderID = int64(folder.GetSpecValue("id").(float64))
-	} else {
-		folderID = generalFolderID
+	api, err := h.appPlatform()
+	if err != nil {
+		return err
+	}
+	if api != nil {
+		return api.save(h.dashboardToObject(resource))
+	}
+
+	folder, err := h.dashboardFolder(resource)
+	if err != nil {
+		return err
 	}
 
 	body := models.SaveDashboardCommand{
 		Dashboard: resource.Spec(),
-		FolderID:  folderID,
+		FolderID:  folder.ID,
+		FolderUID: folder.UID,
 		Overwrite: true,
 	}
 	client, err := h.Provider.(ClientProvider).Client()
@@ -230,6 +599,72 @@ func (h *DashboardHandler) postDashboard(resource grizzly.Resource) error {
 	return err
 }
 
+// dashboardFolderRef identifies the folder of a dashboard for the legacy API:
+// by ID, or by UID for folders without one
+type dashboardFolderRef struct {
+	ID  int64
+	UID string
+}
+
+// dashboardFolder returns the folder of a dashboard, which the legacy API
+// requires to save it. Folders served by the App Platform API have no ID, so
+// they're identified by their UID.
+func (h *DashboardHandler) dashboardFolder(resource grizzly.Resource) (dashboardFolderRef, error) {
+	folderUID := resource.GetMetadata("folder")
+	if folderUID == DefaultFolder || folderUID == strings.ToLower(DefaultFolder) {
+		return dashboardFolderRef{ID: generalFolderID}, nil
+	}
+
+	folderHandler := NewFolderHandler(h.Provider)
+	folder, err := folderHandler.getRemoteFolder(folderUID)
+	if err != nil {
+		if errors.Is(err, grizzly.ErrNotFound) {
+			return dashboardFolderRef{}, fmt.Errorf("cannot upload dashboard %s as folder %s not found", resource.Name(), folderUID)
+		}
+		return dashboardFolderRef{}, fmt.Errorf("cannot upload dashboard %s: %w", resource.Name(), err)
+	}
+	if id, ok := folder.GetSpecValue("id").(float64); ok && id > 0 {
+		return dashboardFolderRef{ID: int64(id)}, nil
+	}
+	return dashboardFolderRef{UID: folderUID}, nil
+}
+
+// dashboardFromObject converts a dashboard served by the App Platform API to a resource
+func (h *DashboardHandler) dashboardFromObject(object k8sObject) (*grizzly.Resource, error) {
+	spec := object.Spec
+	if spec == nil {
+		spec = map[string]any{}
+	}
+	spec["uid"] = object.Metadata.Name
+
+	resource, err := grizzly.NewResource(h.APIVersion(), h.Kind(), object.Metadata.Name, spec)
+	if err != nil {
+		return nil, err
+	}
+	folderUID := object.Metadata.Annotations[folderAnnotation]
+	if folderUID == "" {
+		folderUID = generalFolderUID
+	}
+	resource.SetMetadata("folder", folderUID)
+	return &resource, nil
+}
+
+// dashboardToObject converts a dashboard resource to an object of the App Platform API
+func (h *DashboardHandler) dashboardToObject(resource grizzly.Resource) k8sObject {
+	object := k8sObject{
+		Kind: h.Kind(),
+		Metadata: k8sObjectMeta{
+			Name: resource.Name(),
+		},
+		Spec: resource.Spec(),
+	}
+	folderUID := resource.GetMetadata("folder")
+	if folderUID != "" && !strings.EqualFold(folderUID, DefaultFolder) {
+		object.Metadata.Annotations = map[string]string{folderAnnotation: folderUID}
+	}
+	return object
+}
+
 func (h *DashboardHandler) postSnapshot(resource grizzly.Resource, expiresSeconds int) (*models.CreateDashboardSnapshotOKBody, error) {
 	body := models.CreateDashboardSnapshotCommand{
 		Dashboard: &models.Unstructured{Object: resource.Spec()},
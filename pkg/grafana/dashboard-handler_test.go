@@ -0,0 +1,337 @@
+package grafana
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboardLibraryPanelMeta(t *testing.T) {
+	handler := NewDashboardHandler(nil)
+
+	newDashboard := func(libraryPanel map[string]any) grizzly.Resource {
+		resource, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", DashboardKind, "service", map[string]any{
+			"uid":   "service",
+			"title": "Service",
+			"panels": []any{
+				map[string]any{"id": float64(1), "gridPos": map[string]any{"h": 8, "w": 12, "x": 0, "y": 0}, "libraryPanel": libraryPanel},
+				map[string]any{
+					"id":        float64(2),
+					"type":      "row",
+					"collapsed": true,
+					"panels":    []any{map[string]any{"id": float64(3), "libraryPanel": libraryPanel}},
+				},
+			},
+		})
+		require.NoError(t, err)
+		return resource
+	}
+	remote := func() grizzly.Resource {
+		return newDashboard(map[string]any{
+			"uid":     "latency",
+			"name":    "Latency",
+			"version": float64(4),
+			"meta": map[string]any{
+				"folderName":          "Shared",
+				"connectedDashboards": float64(12),
+				"updated":             "2024-05-02T10:00:00Z",
+			},
+		})
+	}
+	expected := newDashboard(map[string]any{"uid": "latency", "name": "Latency"})
+
+	t.Run("Grafana-added metadata is stripped on pull and diff", func(t *testing.T) {
+		require.Equal(t, expected.Spec(), handler.Unprepare(remote()).Spec())
+	})
+
+	t.Run("Grafana-added metadata isn't pushed back", func(t *testing.T) {
+		require.Equal(t, expected.Spec(), handler.Prepare(nil, remote()).Spec())
+	})
+}
+
+func TestDashboardTypedSpec(t *testing.T) {
+	handler := NewDashboardHandler(nil)
+
+	newDashboard := func(spec map[string]any) grizzly.Resource {
+		resource, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", DashboardKind, "service", spec)
+		require.NoError(t, err)
+		return resource
+	}
+
+	t.Run("fields unknown to DashboardSpec are kept when setting tags", func(t *testing.T) {
+		dashboard := newDashboard(map[string]any{
+			"uid":        "service",
+			"title":      "Service",
+			"tags":       []any{"prod"},
+			"panels":     []any{map[string]any{"id": float64(1), "pluginVersion": "11.0.0"}},
+			"timepicker": map[string]any{"hidden": true},
+		})
+
+		updated := handler.SetTags(dashboard, []string{"prod", "team:web"})
+		require.Equal(t, []string{"prod", "team:web"}, handler.Tags(updated))
+		require.Equal(t, map[string]any{"hidden": true}, updated.GetSpecValue("timepicker"))
+		require.Equal(t, dashboard.GetSpecValue("panels"), updated.GetSpecValue("panels"))
+	})
+
+	t.Run("the UID is set from the name", func(t *testing.T) {
+		prepared := handler.Prepare(nil, newDashboard(map[string]any{"title": "Service"}))
+		uid, err := handler.GetSpecUID(*prepared)
+		require.NoError(t, err)
+		require.Equal(t, "service", uid)
+	})
+
+	t.Run("dashboards not matching DashboardSpec are handled through their untyped spec", func(t *testing.T) {
+		dashboard := newDashboard(map[string]any{
+			"title":  "Service",
+			"panels": []any{map[string]any{"id": "one", "gridPos": "full"}},
+		})
+
+		prepared := handler.Prepare(nil, dashboard)
+		require.Equal(t, "service", prepared.GetSpecValue("uid"))
+		require.Equal(t, dashboard.GetSpecValue("panels"), prepared.GetSpecValue("panels"))
+		require.NoError(t, handler.Validate(*prepared))
+
+		updated := handler.SetTags(*prepared, []string{"prod"})
+		require.Equal(t, []any{"prod"}, updated.GetSpecValue("tags"))
+	})
+}
+
+func TestDashboardHandlerConformance(t *testing.T) {
+	server := newDashboardTestServer(t, map[string]map[string]any{})
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL})
+
+	dashboard, err := grizzly.NewResource(provider.APIVersion(), DashboardKind, "conformance", map[string]any{
+		"uid":   "conformance",
+		"title": "Conformance",
+	})
+	require.NoError(t, err)
+	dashboard.SetMetadata("folder", "general")
+
+	testutil.RunHandlerConformance(t, NewDashboardHandler(provider), testutil.HandlerFixture{
+		Resource: dashboard,
+		Modify: func(resource grizzly.Resource) grizzly.Resource {
+			resource.SetSpecValue("title", "Modified")
+			return resource
+		},
+	})
+}
+
+func TestDashboardGetByUIDs(t *testing.T) {
+	t.Run("dashboards are retrieved concurrently, in the order of their UIDs", func(t *testing.T) {
+		var mu sync.Mutex
+		inFlight, maxInFlight := 0, 0
+		release := make(chan struct{})
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			uid, ok := strings.CutPrefix(r.URL.Path, "/api/dashboards/uid/")
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+
+			mu.Lock()
+			inFlight++
+			maxInFlight = max(maxInFlight, inFlight)
+			if inFlight == dashboardBatchConcurrency {
+				close(release)
+			}
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+			}()
+
+			select {
+			case <-release:
+			case <-time.After(5 * time.Second):
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			if uid == "missing" {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"message": "Dashboard not found"}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"dashboard": map[string]any{"uid": uid, "title": uid},
+				"meta":      map[string]any{"folderUid": "general"},
+			})
+		}))
+		t.Cleanup(server.Close)
+
+		uids := []string{"missing"}
+		for i := range 2 * dashboardBatchConcurrency {
+			uids = append(uids, fmt.Sprintf("dashboard-%d", i))
+		}
+		handler := NewDashboardHandler(NewProvider(&config.GrafanaConfig{URL: server.URL}))
+		results := handler.GetByUIDs(uids)
+
+		require.Len(t, results, len(uids))
+		require.Equal(t, dashboardBatchConcurrency, maxInFlight, "dashboards are retrieved in parallel, up to a limit")
+		require.Equal(t, "missing", results[0].UID)
+		require.ErrorIs(t, results[0].Err, grizzly.ErrNotFound)
+		for i, result := range results[1:] {
+			require.NoError(t, result.Err)
+			require.Equal(t, uids[i+1], result.UID)
+			require.Equal(t, uids[i+1], result.Resource.Name())
+		}
+	})
+
+	t.Run("each dashboard fails when there is no client", func(t *testing.T) {
+		handler := NewDashboardHandler(NewProvider(&config.GrafanaConfig{URL: "://invalid"}))
+		results := handler.GetByUIDs([]string{"a", "b"})
+
+		require.Len(t, results, 2)
+		for i, uid := range []string{"a", "b"} {
+			require.Equal(t, uid, results[i].UID)
+			require.EqualError(t, results[i].Err, "invalid Grafana URL")
+			require.Nil(t, results[i].Resource)
+		}
+	})
+}
+
+func TestDashboardPreviews(t *testing.T) {
+	type snapshot struct {
+		Key     string
+		Name    string
+		Expires int64
+	}
+	snapshots := map[string]snapshot{
+		"other-label": {Key: "other-label", Name: "[grizzly-preview:pr-10] Dashboard.service: Service"},
+		"mentioned":   {Key: "mentioned", Name: "copy of [grizzly-preview:pr-1] Dashboard.service: Service"},
+	}
+	var query string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/snapshots", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Name    string `json:"name"`
+			Expires int64  `json:"expires"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		key := fmt.Sprintf("key-%d", len(snapshots))
+		snapshots[key] = snapshot{Key: key, Name: body.Name, Expires: body.Expires}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"key":       key,
+			"url":       "http://grafana/dashboard/snapshot/" + key,
+			"deleteUrl": "http://grafana/api/snapshots-delete/" + key,
+		})
+	})
+	mux.HandleFunc("GET /api/dashboard/snapshots", func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("query")
+		results := []map[string]any{}
+		for _, snapshot := range snapshots {
+			if strings.Contains(snapshot.Name, query) {
+				results = append(results, map[string]any{"key": snapshot.Key, "name": snapshot.Name})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(results)
+	})
+	mux.HandleFunc("DELETE /api/snapshots/{key}", func(w http.ResponseWriter, r *http.Request) {
+		delete(snapshots, r.PathValue("key"))
+		_, _ = w.Write([]byte(`{"message": "Snapshot deleted"}`))
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	handler := NewDashboardHandler(NewProvider(&config.GrafanaConfig{URL: server.URL}))
+	dashboard, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", DashboardKind, "service", map[string]any{
+		"uid":   "service",
+		"title": "Service",
+	})
+	require.NoError(t, err)
+
+	preview, err := handler.Preview(dashboard, "pr-1", time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, &grizzly.PublishedPreview{
+		Resource:  "Dashboard.service",
+		Label:     "pr-1",
+		URL:       "http://grafana/dashboard/snapshot/key-2",
+		DeleteURL: "http://grafana/api/snapshots-delete/key-2",
+	}, preview)
+	require.Equal(t, "[grizzly-preview:pr-1] Dashboard.service: Service", snapshots["key-2"].Name, "snapshots are named after the label, so that they can be cleaned up")
+	require.EqualValues(t, 3600, snapshots["key-2"].Expires)
+
+	deleted, err := handler.DeletePreviews("pr-1")
+	require.NoError(t, err)
+	require.Equal(t, "[grizzly-preview:pr-1]", query)
+	require.Equal(t, []grizzly.PublishedPreview{{Resource: "Dashboard.service", Label: "pr-1"}}, deleted)
+	require.NotContains(t, snapshots, "key-2")
+	require.Contains(t, snapshots, "other-label", "previews of other labels are kept")
+	require.Contains(t, snapshots, "mentioned", "snapshots only mentioning the label are kept")
+
+	deleted, err = handler.DeletePreviews("pr-1")
+	require.NoError(t, err)
+	require.Empty(t, deleted)
+}
+
+func TestDashboardFolder(t *testing.T) {
+	var saved map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/folders/{uid}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.PathValue("uid") {
+		case "legacy":
+			_, _ = w.Write([]byte(`{"id": 5, "uid": "legacy", "title": "Legacy"}`))
+		case "app-platform":
+			// folders of the App Platform API have no ID
+			_, _ = w.Write([]byte(`{"uid": "app-platform", "title": "App Platform"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Folder not found"}`))
+		}
+	})
+	mux.HandleFunc("POST /api/dashboards/db", func(w http.ResponseWriter, r *http.Request) {
+		saved = map[string]any{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&saved))
+		_, _ = w.Write([]byte(`{"status": "success"}`))
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	handler := NewDashboardHandler(NewProvider(&config.GrafanaConfig{URL: server.URL}))
+
+	tests := []struct {
+		folder    string
+		folderID  any
+		folderUID any
+	}{
+		{folder: DefaultFolder},
+		{folder: "legacy", folderID: float64(5)},
+		{folder: "app-platform", folderUID: "app-platform"},
+	}
+	for _, test := range tests {
+		t.Run(test.folder, func(t *testing.T) {
+			dashboard, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", DashboardKind, "service", map[string]any{
+				"uid":   "service",
+				"title": "Service",
+			})
+			require.NoError(t, err)
+			dashboard.SetMetadata("folder", test.folder)
+
+			require.NoError(t, handler.Add(dashboard))
+			require.Equal(t, test.folderID, saved["folderId"])
+			require.Equal(t, test.folderUID, saved["folderUid"])
+		})
+	}
+
+	dashboard, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", DashboardKind, "service", map[string]any{"uid": "service"})
+	require.NoError(t, err)
+	dashboard.SetMetadata("folder", "missing")
+	require.EqualError(t, handler.Add(dashboard), "cannot upload dashboard service as folder missing not found")
+}
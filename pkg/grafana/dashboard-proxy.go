@@ -45,6 +45,11 @@ func (c *dashboardProxyConfigurator) Endpoints(s grizzly.Server) []grizzly.HTTPE
 			URL:     "/api/dashboards/db/",
 			Handler: c.dashboardJSONPostHandler(s),
 		},
+		{
+			Method:  http.MethodPost,
+			URL:     "/api/ds/query",
+			Handler: datasourceQueryHandler(s),
+		},
 	}
 }
 
@@ -57,7 +62,6 @@ func (c *dashboardProxyConfigurator) StaticEndpoints() grizzly.StaticProxyConfig
 		},
 		ProxyPost: []string{
 			"/api/datasources/proxy/*",
-			"/api/ds/query",
 		},
 		MockGet: map[string]string{
 			"/api/annotations":                 "[]",
@@ -0,0 +1,95 @@
+package grafana
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+var _ grizzly.QueryHandler = &DashboardHandler{}
+
+// queryFields are the fields of panel targets holding queries, by datasource
+// type. Targets of datasources of unknown type, or referred to by variables,
+// are assumed to hold PromQL in their expr field, or else SQL in rawSql.
+var queryFields = map[string]struct {
+	field    string
+	language string
+}{
+	"prometheus":                    {field: "expr", language: grizzly.QueryLanguagePromQL},
+	"loki":                          {field: "expr", language: grizzly.QueryLanguageLogQL},
+	"tempo":                         {field: "query", language: grizzly.QueryLanguageTraceQL},
+	"postgres":                      {field: "rawSql", language: grizzly.QueryLanguageSQL},
+	"grafana-postgresql-datasource": {field: "rawSql", language: grizzly.QueryLanguageSQL},
+	"mysql":                         {field: "rawSql", language: grizzly.QueryLanguageSQL},
+	"mssql":                         {field: "rawSql", language: grizzly.QueryLanguageSQL},
+}
+
+// Queries implements grizzly.QueryHandler, listing the queries of the panels
+// of a dashboard, including those nested in collapsed rows
+func (h *DashboardHandler) Queries(resource grizzly.Resource) []grizzly.Query {
+	var queries []grizzly.Query
+
+	panels, _ := resource.GetSpecValue("panels").([]any)
+	for i, item := range panels {
+		panel, _ := item.(map[string]any)
+		queries = append(queries, panelQueries(panel, fmt.Sprintf("/panels/%d", i))...)
+
+		nested, _ := panel["panels"].([]any)
+		for j, child := range nested {
+			childPanel, _ := child.(map[string]any)
+			queries = append(queries, panelQueries(childPanel, fmt.Sprintf("/panels/%d/panels/%d", i, j))...)
+		}
+	}
+
+	return queries
+}
+
+func panelQueries(panel map[string]any, pointer string) []grizzly.Query {
+	var queries []grizzly.Query
+
+	targets, _ := panel["targets"].([]any)
+	for i, item := range targets {
+		target, _ := item.(map[string]any)
+		if target == nil {
+			continue
+		}
+		datasourceType := targetDatasourceType(panel, target)
+
+		fields, known := queryFields[datasourceType]
+		if !known {
+			fields = queryFields["prometheus"]
+			if _, ok := target[fields.field]; !ok {
+				fields = queryFields["postgres"]
+			}
+		}
+		if query, ok := target[fields.field].(string); !ok || strings.TrimSpace(query) == "" {
+			continue
+		}
+
+		refID, _ := target["refId"].(string)
+		if refID == "" {
+			refID = fmt.Sprint(i)
+		}
+		queries = append(queries, grizzly.Query{
+			Pointer:  fmt.Sprintf("%s/targets/%d/%s", pointer, i, fields.field),
+			Language: fields.language,
+			Name:     fmt.Sprintf("panel-%v-%s", panel["id"], refID),
+		})
+	}
+
+	return queries
+}
+
+// targetDatasourceType returns the type of the datasource of a panel target,
+// inherited from the panel unless set
+func targetDatasourceType(panel, target map[string]any) string {
+	for _, object := range []map[string]any{target, panel} {
+		if datasource, ok := object["datasource"].(map[string]any); ok {
+			if datasourceType, ok := datasource["type"].(string); ok && datasourceType != "" {
+				return datasourceType
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package grafana
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func queriesTestDashboard() map[string]any {
+	return map[string]any{
+		"uid":   "queries",
+		"title": "Queries",
+		"panels": []any{
+			map[string]any{
+				"id":         1,
+				"datasource": map[string]any{"type": "prometheus", "uid": "prom"},
+				"targets":    []any{map[string]any{"refId": "A", "expr": "sum(rate(http_requests_total[5m]))"}},
+			},
+			map[string]any{
+				"id":   2,
+				"type": "row",
+				"panels": []any{
+					map[string]any{
+						"id":      3,
+						"targets": []any{map[string]any{"refId": "A", "datasource": map[string]any{"type": "loki"}, "expr": `{app="api"} |= "error"`}},
+					},
+				},
+			},
+			map[string]any{
+				"id":         4,
+				"datasource": map[string]any{"type": "grafana-postgresql-datasource"},
+				"targets":    []any{map[string]any{"refId": "B", "rawSql": "SELECT count(*) FROM orders"}},
+			},
+		},
+	}
+}
+
+func TestDashboardQueries(t *testing.T) {
+	handler := NewDashboardHandler(NewProvider(&config.GrafanaConfig{}))
+	resource, err := grizzly.NewResource(handler.APIVersion(), DashboardKind, "queries", queriesTestDashboard())
+	require.NoError(t, err)
+
+	require.Equal(t, []grizzly.Query{
+		{Pointer: "/panels/0/targets/0/expr", Language: grizzly.QueryLanguagePromQL, Name: "panel-1-A"},
+		{Pointer: "/panels/1/panels/0/targets/0/expr", Language: grizzly.QueryLanguageLogQL, Name: "panel-3-A"},
+		{Pointer: "/panels/2/targets/0/rawSql", Language: grizzly.QueryLanguageSQL, Name: "panel-4-B"},
+	}, handler.Queries(resource))
+}
+
+func TestPullExtractQueries(t *testing.T) {
+	dashboards := map[string]map[string]any{"queries": queriesTestDashboard()}
+	server := newDashboardTestServer(t, dashboards)
+	registry := grizzly.NewRegistry([]grizzly.Provider{NewProvider(&config.GrafanaConfig{URL: server.URL})})
+
+	dir := t.TempDir()
+	opts := grizzly.PullOptions{OutputFormat: "yaml", Targets: []string{"Dashboard/*"}, ExtractQueries: true}
+	var out bytes.Buffer
+	require.NoError(t, grizzly.Pull(registry, dir, opts, grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText)))
+
+	query, err := os.ReadFile(filepath.Join(dir, "dashboards", "general", "queries", "queries", "panel-1-A.promql"))
+	require.NoError(t, err)
+	require.Equal(t, "sum(rate(http_requests_total[5m]))\n", string(query))
+	content, err := os.ReadFile(filepath.Join(dir, "dashboards", "general", "dashboard-queries.yaml"))
+	require.NoError(t, err)
+	require.Contains(t, string(content), "$file: queries/queries/panel-4-B.sql")
+
+	resources, err := grizzly.DefaultParser(registry, nil, nil).Parse(dir, grizzly.ParserOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, resources.Len())
+	resource := resources.First()
+	require.Equal(t, "SELECT count(*) FROM orders", resource.Spec()["panels"].([]any)[2].(map[string]any)["targets"].([]any)[0].(map[string]any)["rawSql"])
+
+	t.Run("extracted queries are linted in place", func(t *testing.T) {
+		linters := map[string]string{grizzly.QueryLanguageSQL: "grep -q SELECT", grizzly.QueryLanguagePromQL: "grep -q irate"}
+
+		err := grizzly.Validate(registry, resources, linters, "")
+		require.ErrorContains(t, err, "found 1 invalid resource")
+	})
+}
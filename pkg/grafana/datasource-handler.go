@@ -18,7 +18,9 @@ import (
 const DatasourceKind = "Datasource"
 
 var _ grizzly.Handler = &DatasourceHandler{}
+var _ grizzly.LegacyKindsHandler = &DatasourceHandler{}
 var _ grizzly.ProxyConfiguratorProvider = &DatasourceHandler{}
+var _ grizzly.UIDHandler = &DatasourceHandler{}
 
 // DatasourceHandler is a Grizzly Handler for Grafana datasources
 type DatasourceHandler struct {
@@ -32,10 +34,20 @@ func NewDatasourceHandler(provider grizzly.Provider) *DatasourceHandler {
 	}
 }
 
+// ErrorHint implements grizzly.ErrorHinter
+func (h *DatasourceHandler) ErrorHint(code grizzly.ErrorCode) string {
+	return permissionsHint(code, "datasources")
+}
+
 const (
 	datasourcePattern = "datasources/datasource-%s.%s"
 )
 
+// LegacyKinds returns the kind names previously used for datasource resources
+func (h *DatasourceHandler) LegacyKinds() []string {
+	return []string{"DataSource"}
+}
+
 // ProxyConfigurator provides a configurator object describing how to proxy datasources.
 func (h *DatasourceHandler) ProxyConfigurator() grizzly.ProxyConfigurator {
 	return &datasourceProxyConfigurator{
@@ -89,6 +101,16 @@ func (h *DatasourceHandler) GetSpecUID(resource grizzly.Resource) (string, error
 	return uid, nil
 }
 
+// UIDConstraints implements grizzly.UIDHandler
+func (h *DatasourceHandler) UIDConstraints() grizzly.UIDConstraints {
+	return uidConstraints
+}
+
+// SetUID implements grizzly.UIDHandler
+func (h *DatasourceHandler) SetUID(resource grizzly.Resource, uid string) grizzly.Resource {
+	return setSpecUID(resource, uid)
+}
+
 // GetByUID retrieves JSON for a resource from an endpoint, by UID
 func (h *DatasourceHandler) GetByUID(uid string) (*grizzly.Resource, error) {
 	return h.getRemoteDatasource(uid)
@@ -114,6 +136,24 @@ func (h *DatasourceHandler) Update(existing, resource grizzly.Resource) error {
 	return h.putDatasource(resource)
 }
 
+// Delete removes a datasource from Grafana via the API
+func (h *DatasourceHandler) Delete(resource grizzly.Resource) error {
+	// Datasources can be referenced by name, so their UID is read from the remote version
+	remote, err := h.getRemoteDatasource(resource.Name())
+	if err != nil {
+		return err
+	}
+	uid, _ := remote.GetSpecString("uid")
+
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Datasources.DeleteDataSourceByUID(uid)
+	return err
+}
+
 // getRemoteDatasource retrieves a datasource object from Grafana
 func (h *DatasourceHandler) getRemoteDatasource(uid string) (*grizzly.Resource, error) {
 	client, err := h.Provider.(ClientProvider).Client()
@@ -0,0 +1,33 @@
+package grafana
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+var _ grizzly.MetricLister = &DatasourceHandler{}
+
+// MetricNames implements grizzly.MetricLister, listing the metric names of a
+// Prometheus datasource through the datasource proxy of Grafana
+func (h *DatasourceHandler) MetricNames(uid string) ([]string, error) {
+	provider, ok := h.Provider.(*Provider)
+	if !ok {
+		return nil, fmt.Errorf("metrics can only be listed by the Grafana provider")
+	}
+
+	var response struct {
+		Status string   `json:"status"`
+		Data   []string `json:"data"`
+	}
+	path := fmt.Sprintf("/api/datasources/proxy/uid/%s/api/v1/label/__name__/values", url.PathEscape(uid))
+	if err := provider.appPlatformRequest(http.MethodGet, path, nil, nil, &response); err != nil {
+		return nil, err
+	}
+	if response.Status != "success" {
+		return nil, fmt.Errorf("datasource %s didn't list its metrics, is it a Prometheus datasource?", uid)
+	}
+	return response.Data, nil
+}
@@ -0,0 +1,61 @@
+package grafana
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/mimir"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAgainstDatasource(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/datasources/proxy/uid/prometheus/api/v1/label/__name__/values", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "success", "data": []string{"up", "http_requests_total"}})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL})
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider, mimir.NewProvider(&config.MimirConfig{})})
+
+	ruleGroup := func(name string, rules ...map[string]any) grizzly.Resource {
+		items := []any{}
+		for _, rule := range rules {
+			items = append(items, rule)
+		}
+		resource, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", mimir.PrometheusRuleGroupKind, name, map[string]any{"rules": items})
+		require.NoError(t, err)
+		resource.SetMetadata("namespace", "infra")
+		return resource
+	}
+	recorded := ruleGroup("recorded",
+		map[string]any{"record": "job:http_requests:rate5m", "expr": "sum by (job) (rate(http_requests_total[5m]))"},
+		map[string]any{"alert": "NoTraffic", "expr": "job:http_requests:rate5m == 0"},
+	)
+	misspelled := ruleGroup("misspelled",
+		map[string]any{"alert": "Down", "expr": "upp == 0"},
+	)
+
+	t.Run("metrics of the datasource and recorded ones exist", func(t *testing.T) {
+		require.NoError(t, grizzly.Validate(registry, grizzly.NewResources(recorded), nil, "prometheus"))
+	})
+
+	t.Run("rules on missing metrics are invalid", func(t *testing.T) {
+		err := grizzly.Validate(registry, grizzly.NewResources(recorded, misspelled), nil, "prometheus")
+		require.EqualError(t, err, "found 1 invalid resource")
+	})
+
+	t.Run("metrics aren't checked without a datasource", func(t *testing.T) {
+		require.NoError(t, grizzly.Validate(registry, grizzly.NewResources(misspelled), nil, ""))
+	})
+
+	t.Run("unknown datasources fail validation", func(t *testing.T) {
+		err := grizzly.Validate(registry, grizzly.NewResources(recorded), nil, "missing")
+		require.ErrorContains(t, err, "listing the metrics of datasource missing")
+	})
+}
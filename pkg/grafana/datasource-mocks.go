@@ -0,0 +1,231 @@
+package grafana
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/grafana/grizzly/internal/httputils"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// datasourceMocksLock serialises the recording of responses to the mocks file
+var datasourceMocksLock sync.Mutex
+
+// datasourceMocks holds the responses returned to datasource queries when
+// serving resources offline, recorded from datasources or written by hand
+type datasourceMocks struct {
+	Responses []datasourceMock `json:"responses" yaml:"responses"`
+}
+
+// datasourceMock is the response to the queries it matches. Empty fields
+// match any query.
+type datasourceMock struct {
+	// Datasource is the UID of the datasource queried
+	Datasource string `json:"datasource,omitempty" yaml:"datasource,omitempty"`
+	RefID      string `json:"refId,omitempty" yaml:"refId,omitempty"`
+	// Query is the expression of the query, such as a PromQL or SQL query
+	Query  string `json:"query,omitempty" yaml:"query,omitempty"`
+	Frames []any  `json:"frames" yaml:"frames"`
+}
+
+func (m datasourceMock) matches(query map[string]any) bool {
+	return (m.Datasource == "" || m.Datasource == queryDatasourceUID(query)) &&
+		(m.RefID == "" || m.RefID == queryRefID(query)) &&
+		(m.Query == "" || m.Query == queryExpression(query))
+}
+
+// find returns the frames of the first response matching a query
+func (m datasourceMocks) find(query map[string]any) ([]any, bool) {
+	for _, mock := range m.Responses {
+		if mock.matches(query) {
+			return mock.Frames, true
+		}
+	}
+	return nil, false
+}
+
+// record replaces the response recorded for the same query, or adds it
+func (m *datasourceMocks) record(mock datasourceMock) {
+	for i, existing := range m.Responses {
+		if existing.Datasource == mock.Datasource && existing.RefID == mock.RefID && existing.Query == mock.Query {
+			m.Responses[i] = mock
+			return
+		}
+	}
+	m.Responses = append(m.Responses, mock)
+}
+
+type datasourceQueryRequest struct {
+	Queries []map[string]any `json:"queries"`
+}
+
+type datasourceQueryResponse struct {
+	Results map[string]struct {
+		Frames []any `json:"frames"`
+	} `json:"results"`
+}
+
+func queryDatasourceUID(query map[string]any) string {
+	datasource, _ := query["datasource"].(map[string]any)
+	uid, _ := datasource["uid"].(string)
+	return uid
+}
+
+func queryRefID(query map[string]any) string {
+	refID, _ := query["refId"].(string)
+	return refID
+}
+
+// queryExpression returns the expression of a query, whose field depends on
+// the type of the datasource
+func queryExpression(query map[string]any) string {
+	for _, field := range []string{"expr", "query", "rawSql", "expression", "target"} {
+		if expression, ok := query[field].(string); ok && expression != "" {
+			return expression
+		}
+	}
+	return ""
+}
+
+func readDatasourceMocks(path string) (datasourceMocks, error) {
+	var mocks datasourceMocks
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return mocks, nil
+	}
+	if err != nil {
+		return mocks, err
+	}
+	if err := yaml.Unmarshal(content, &mocks); err != nil {
+		return mocks, fmt.Errorf("reading datasource mocks from %s: %w", path, err)
+	}
+	return mocks, nil
+}
+
+func writeDatasourceMocks(path string, mocks datasourceMocks) error {
+	var content []byte
+	var err error
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		content, err = yaml.Marshal(mocks)
+	default:
+		content, err = json.MarshalIndent(mocks, "", "  ")
+		content = append(content, '\n')
+	}
+	if err != nil {
+		return err
+	}
+	return grizzly.WriteFile(path, content)
+}
+
+// datasourceQueryHandler answers datasource queries with mocked responses when
+// the server mocks datasources, records the responses of datasources when it
+// records them, and proxies queries otherwise
+func datasourceQueryHandler(s grizzly.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.DatasourceMocks == "" {
+			s.ProxyRequestHandler(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			httputils.Error(w, "Error reading query", err, http.StatusBadRequest)
+			return
+		}
+		var request datasourceQueryRequest
+		if err := json.Unmarshal(body, &request); err != nil {
+			httputils.Error(w, "Error parsing JSON", err, http.StatusBadRequest)
+			return
+		}
+
+		if s.RecordDatasources {
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			recordDatasourceQueries(s, w, r, request)
+			return
+		}
+
+		mocks, err := readDatasourceMocks(s.DatasourceMocks)
+		if err != nil {
+			httputils.Error(w, err.Error(), err, http.StatusInternalServerError)
+			return
+		}
+		results := map[string]any{}
+		for _, query := range request.Queries {
+			frames, found := mocks.find(query)
+			if !found {
+				log.Debugf("No mocked response for query %s of datasource %s", queryRefID(query), queryDatasourceUID(query))
+				frames = []any{}
+			}
+			results[queryRefID(query)] = map[string]any{"status": http.StatusOK, "frames": frames}
+		}
+		httputils.WriteJSON(w, map[string]any{"results": results})
+	}
+}
+
+// recordDatasourceQueries proxies queries, and records the frames returned
+// for each of them
+func recordDatasourceQueries(s grizzly.Server, w http.ResponseWriter, r *http.Request, request datasourceQueryRequest) {
+	// let the proxy transport negotiate compression, so that the response can be read
+	r.Header.Del("Accept-Encoding")
+	recorder := &capturingResponseWriter{ResponseWriter: w, status: http.StatusOK}
+	s.ProxyRequestHandler(recorder, r)
+	if recorder.status != http.StatusOK {
+		return
+	}
+
+	var response datasourceQueryResponse
+	if err := json.Unmarshal(recorder.body.Bytes(), &response); err != nil {
+		log.Warnf("Could not record datasource response: %s", err)
+		return
+	}
+
+	datasourceMocksLock.Lock()
+	defer datasourceMocksLock.Unlock()
+	mocks, err := readDatasourceMocks(s.DatasourceMocks)
+	if err != nil {
+		log.Warn(err.Error())
+		return
+	}
+	for _, query := range request.Queries {
+		result, ok := response.Results[queryRefID(query)]
+		if !ok {
+			continue
+		}
+		mocks.record(datasourceMock{
+			Datasource: queryDatasourceUID(query),
+			RefID:      queryRefID(query),
+			Query:      queryExpression(query),
+			Frames:     result.Frames,
+		})
+	}
+	if err := writeDatasourceMocks(s.DatasourceMocks, mocks); err != nil {
+		log.Warnf("Could not record datasource response: %s", err)
+	}
+}
+
+// capturingResponseWriter keeps a copy of the response it writes
+type capturingResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *capturingResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *capturingResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
@@ -0,0 +1,25 @@
+package grafana
+
+import (
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+var _ grizzly.DeployAnnotator = &Provider{}
+
+// AnnotateDeploy implements grizzly.DeployAnnotator, with an organisation-wide
+// annotation spanning the deploy, shown on every dashboard querying it
+func (p *Provider) AnnotateDeploy(deploy grizzly.Deploy) error {
+	client, err := p.Client()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Annotations.PostAnnotation(&models.PostAnnotationsCmd{
+		Time:    deploy.StartedAt.UnixMilli(),
+		TimeEnd: deploy.EndedAt.UnixMilli(),
+		Tags:    deploy.Tags,
+		Text:    &deploy.Text,
+	})
+	return err
+}
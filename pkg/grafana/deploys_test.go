@@ -0,0 +1,44 @@
+package grafana
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotateDeploy(t *testing.T) {
+	var annotations []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "POST /api/annotations", r.Method+" "+r.URL.Path)
+		var annotation map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&annotation))
+		annotations = append(annotations, annotation)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": 1, "message": "Annotation added"}`))
+	}))
+	t.Cleanup(server.Close)
+	registry := grizzly.NewRegistry([]grizzly.Provider{NewProvider(&config.GrafanaConfig{URL: server.URL})})
+
+	startedAt := time.UnixMilli(1700000000000)
+	summary := grizzly.Summary{EventCounts: map[grizzly.EventType]int{
+		grizzly.ResourceAdded:      1,
+		grizzly.ResourceUpdated:    2,
+		grizzly.ResourceNotChanged: 10,
+	}}
+	deploy := grizzly.NewDeploy(startedAt, startedAt.Add(time.Minute), summary, "prod", map[string]string{grizzly.GitCommitAnnotation: "1a2b3c4"})
+	require.NoError(t, grizzly.AnnotateDeploy(registry, deploy))
+
+	require.Len(t, annotations, 1)
+	require.Equal(t, map[string]any{
+		"time":    float64(1700000000000),
+		"timeEnd": float64(1700000060000),
+		"tags":    []any{"grizzly", "deploy"},
+		"text":    "Applied with grr to prod from 1a2b3c4: 1 resource added, 2 resources updated, 10 resources unchanged",
+	}, annotations[0])
+}
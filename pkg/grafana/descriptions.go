@@ -0,0 +1,36 @@
+package grafana
+
+import (
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+var _ grizzly.DescriptionHandler = &DashboardHandler{}
+var _ grizzly.DescriptionHandler = &FolderHandler{}
+
+// ExposeDescription implements grizzly.DescriptionHandler
+func (h *DashboardHandler) ExposeDescription(resource *grizzly.Resource, description string) {
+	resource.SetSpecString("description", description)
+}
+
+// ReadDescription implements grizzly.DescriptionHandler
+func (h *DashboardHandler) ReadDescription(resource *grizzly.Resource) string {
+	return readSpecDescription(resource)
+}
+
+// ExposeDescription implements grizzly.DescriptionHandler. Descriptions are
+// only read back from Grafana with the App Platform API, as the legacy
+// folders API doesn't return them.
+func (h *FolderHandler) ExposeDescription(resource *grizzly.Resource, description string) {
+	resource.SetSpecString("description", description)
+}
+
+// ReadDescription implements grizzly.DescriptionHandler
+func (h *FolderHandler) ReadDescription(resource *grizzly.Resource) string {
+	return readSpecDescription(resource)
+}
+
+func readSpecDescription(resource *grizzly.Resource) string {
+	description, _ := resource.GetSpecString("description")
+	resource.DeleteSpecKey("description")
+	return description
+}
@@ -0,0 +1,89 @@
+package grafana
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExposeDescription(t *testing.T) {
+	registry := grizzly.NewRegistry([]grizzly.Provider{NewProvider(&config.GrafanaConfig{})})
+	newResource := func(t *testing.T, kind string, spec map[string]any) grizzly.Resource {
+		resource, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", kind, "sample", spec)
+		require.NoError(t, err)
+		resource.SetMetadata("description", "Owned by the payments team")
+		return resource
+	}
+
+	t.Run("folders keep descriptions in their spec", func(t *testing.T) {
+		resource := newResource(t, DashboardFolderKind, map[string]any{"title": "Payments"})
+
+		exposed, err := grizzly.ExposeDescription(registry, resource)
+		require.NoError(t, err)
+		require.Equal(t, "Owned by the payments team", exposed.GetSpecValue("description"))
+		require.False(t, exposed.HasMetadata("description"))
+		require.True(t, resource.HasMetadata("description"), "the original resource is left untouched")
+	})
+
+	t.Run("descriptions of data sources are only known locally", func(t *testing.T) {
+		resource := newResource(t, DatasourceKind, map[string]any{"name": "Payments"})
+
+		exposed, err := grizzly.ExposeDescription(registry, resource)
+		require.NoError(t, err)
+		require.False(t, exposed.HasMetadata("description"))
+		require.Nil(t, exposed.GetSpecValue("description"))
+	})
+
+	t.Run("descriptions should be strings", func(t *testing.T) {
+		resource := newResource(t, DatasourceKind, map[string]any{"name": "Payments"})
+		resource.Body["metadata"].(map[string]any)["description"] = []any{"payments"}
+
+		_, err := grizzly.ExposeDescription(registry, resource)
+		require.ErrorContains(t, err, "should be a string")
+	})
+}
+
+func TestPullKeepsDescriptions(t *testing.T) {
+	dashboards := map[string]map[string]any{
+		"described": {"uid": "described", "title": "Described", "description": "Changed in Grafana"},
+		"plain":     {"uid": "plain", "title": "Plain", "description": "Not in metadata"},
+	}
+	server := newDashboardTestServer(t, dashboards)
+	registry := grizzly.NewRegistry([]grizzly.Provider{NewProvider(&config.GrafanaConfig{URL: server.URL})})
+
+	dir := t.TempDir()
+	described := filepath.Join(dir, "dashboards", "general", "dashboard-described.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(described), 0755))
+	require.NoError(t, os.WriteFile(described, []byte(`apiVersion: grizzly.grafana.com/v1alpha1
+kind: Dashboard
+metadata:
+    description: Written locally
+    folder: general
+    name: described
+spec:
+    title: Described
+    uid: described
+`), 0644))
+
+	opts := grizzly.PullOptions{OutputFormat: "yaml", Targets: []string{"Dashboard/*"}}
+	var out bytes.Buffer
+	require.NoError(t, grizzly.Pull(registry, dir, opts, grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText)))
+
+	resources, err := grizzly.DefaultParser(registry, nil, nil).Parse(dir, grizzly.ParserOptions{})
+	require.NoError(t, err)
+
+	resource, ok := resources.Find(grizzly.NewResourceRef(DashboardKind, "described"))
+	require.True(t, ok)
+	require.Equal(t, "Changed in Grafana", resource.GetMetadata("description"))
+	require.Nil(t, resource.GetSpecValue("description"))
+
+	resource, ok = resources.Find(grizzly.NewResourceRef(DashboardKind, "plain"))
+	require.True(t, ok)
+	require.False(t, resource.HasMetadata("description"))
+	require.Equal(t, "Not in metadata", resource.GetSpecValue("description"))
+}
@@ -3,6 +3,8 @@ package grafana
 import (
 	"fmt"
 	"strings"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
 )
 
 // ErrUidsMissing reports UIDs are missing for Dashboards
@@ -17,3 +19,19 @@ type APIResponse interface {
 	Error() string
 	String() string
 }
+
+// permissionsHint returns a remediation hint for authentication and
+// authorization errors, naming the permissions required by a kind, and for
+// quota errors
+func permissionsHint(code grizzly.ErrorCode, scope string) string {
+	switch code {
+	case grizzly.ErrorCodeAuthFailed:
+		return "check grafana.token, or grafana.user and grafana.token for basic auth, with `grr config check`"
+	case grizzly.ErrorCodePermissionDenied:
+		return fmt.Sprintf("the token lacks the %[1]s:read or %[1]s:write permissions", scope)
+	case grizzly.ErrorCodeQuotaExceeded:
+		return "a quota of the organisation, or a limit of the Grafana Cloud stack (ex: dashboards per folder), was reached: remove unused resources or ask for it to be raised, or retry later if requests were rate limited"
+	default:
+		return ""
+	}
+}
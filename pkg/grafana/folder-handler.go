@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	gclient "github.com/grafana/grafana-openapi-client-go/client"
 	"github.com/grafana/grafana-openapi-client-go/client/folders"
@@ -17,7 +18,10 @@ const DefaultFolder = "General"
 const DashboardFolderKind = "DashboardFolder"
 
 var _ grizzly.Handler = &FolderHandler{}
+var _ grizzly.LegacyKindsHandler = &FolderHandler{}
 var _ grizzly.ProxyConfiguratorProvider = &FolderHandler{}
+var _ grizzly.UIDHandler = &FolderHandler{}
+var _ grizzly.ConcurrencyLimiter = &FolderHandler{}
 
 // FolderHandler is a Grizzly Handler for Grafana dashboard folders
 type FolderHandler struct {
@@ -31,10 +35,20 @@ func NewFolderHandler(provider grizzly.Provider) *FolderHandler {
 	}
 }
 
+// ErrorHint implements grizzly.ErrorHinter
+func (h *FolderHandler) ErrorHint(code grizzly.ErrorCode) string {
+	return permissionsHint(code, "folders")
+}
+
 const (
 	folderPattern = "folders/folder-%s.%s"
 )
 
+// LegacyKinds returns the kind names previously used for folder resources
+func (h *FolderHandler) LegacyKinds() []string {
+	return []string{"Folder"}
+}
+
 // ProxyConfigurator provides a configurator object describing how to proxy folders.
 func (h *FolderHandler) ProxyConfigurator() grizzly.ProxyConfigurator {
 	return &folderProxyConfigurator{
@@ -71,6 +85,9 @@ func (h *FolderHandler) Validate(resource grizzly.Resource) error {
 			return fmt.Errorf("uid '%s' and name '%s', don't match", uid, resource.Name())
 		}
 	}
+	if parentUID, _ := resource.GetSpecString("parentUid"); parentUID == resource.Name() {
+		return fmt.Errorf("folder '%s' can't be its own parent", resource.Name())
+	}
 
 	return nil
 }
@@ -83,6 +100,22 @@ func (h *FolderHandler) GetSpecUID(resource grizzly.Resource) (string, error) {
 	return uid, nil
 }
 
+// UIDConstraints implements grizzly.UIDHandler
+func (h *FolderHandler) UIDConstraints() grizzly.UIDConstraints {
+	return uidConstraints
+}
+
+// SetUID implements grizzly.UIDHandler
+func (h *FolderHandler) SetUID(resource grizzly.Resource, uid string) grizzly.Resource {
+	return setSpecUID(resource, uid)
+}
+
+// MaxConcurrency implements grizzly.ConcurrencyLimiter. Folders are applied
+// one at a time, so that parent folders exist before their children.
+func (h *FolderHandler) MaxConcurrency() int {
+	return 1
+}
+
 // Sort sorts according to handler needs
 func (h *FolderHandler) Sort(resources grizzly.Resources) grizzly.Resources {
 	result := grizzly.NewResources()
@@ -90,6 +123,7 @@ func (h *FolderHandler) Sort(resources grizzly.Resources) grizzly.Resources {
 	for _, resource := range resources.AsList() {
 		addedToResult[resource.Name()] = false
 	}
+	added := 0
 	for {
 		continueLoop := false
 		for _, resource := range resources.AsList() {
@@ -119,6 +153,17 @@ func (h *FolderHandler) Sort(resources grizzly.Resources) grizzly.Resources {
 		if !continueLoop {
 			break
 		}
+		if result.Len() == added {
+			// folders whose parents are their descendants can't be ordered,
+			// and are left for Grafana to refuse
+			for _, resource := range resources.AsList() {
+				if !addedToResult[resource.Name()] {
+					result.Add(resource)
+				}
+			}
+			break
+		}
+		added = result.Len()
 	}
 
 	return result
@@ -139,6 +184,18 @@ func (h *FolderHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource,
 	return h.getRemoteFolder(resource.Name())
 }
 
+// DescribeRemote returns the inventory entry of a folder, including when and
+// by whom it was last changed
+func (h *FolderHandler) DescribeRemote(uid string) (*grizzly.InventoryEntry, error) {
+	resource, changes, err := h.getRemoteFolderWithChanges(uid)
+	if err != nil {
+		return nil, err
+	}
+	entry := changes.describe(*h.Unprepare(*resource))
+	entry.Folder, _ = resource.GetSpecString("parentUid")
+	return entry, nil
+}
+
 // ListRemote retrieves as list of UIDs of all remote resources
 func (h *FolderHandler) ListRemote() ([]string, error) {
 	return h.getRemoteFolderList()
@@ -149,15 +206,42 @@ func (h *FolderHandler) Add(resource grizzly.Resource) error {
 	return h.postFolder(resource)
 }
 
-// Update pushes a folder to Grafana via the API
+// Update pushes a folder to Grafana via the API, moving it first when its
+// parent changed
 func (h *FolderHandler) Update(existing, resource grizzly.Resource) error {
-	return h.putFolder(resource)
+	return h.putFolder(existing, resource)
+}
+
+// Delete removes a folder, and everything it contains, from Grafana via the API
+func (h *FolderHandler) Delete(resource grizzly.Resource) error {
+	api, err := h.appPlatform()
+	if err != nil {
+		return err
+	}
+	if api != nil {
+		return api.delete(resource.Name())
+	}
+
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Folders.DeleteFolder(folders.NewDeleteFolderParams().WithFolderUID(resource.Name()))
+	return err
 }
 
 // getRemoteFolder retrieves a folder object from Grafana
 func (h *FolderHandler) getRemoteFolder(uid string) (*grizzly.Resource, error) {
+	resource, _, err := h.getRemoteFolderWithChanges(uid)
+	return resource, err
+}
+
+// getRemoteFolderWithChanges retrieves a folder, along with when and by whom
+// it was last changed
+func (h *FolderHandler) getRemoteFolderWithChanges(uid string) (*grizzly.Resource, remoteChanges, error) {
 	if uid == "" {
-		return nil, fmt.Errorf("no folder UID provided")
+		return nil, remoteChanges{}, fmt.Errorf("no folder UID provided")
 	}
 	var folder *models.Folder
 	if uid == DefaultFolder || uid == strings.ToLower(DefaultFolder) {
@@ -168,9 +252,25 @@ func (h *FolderHandler) getRemoteFolder(uid string) (*grizzly.Resource, error) {
 			// URL: ??
 		}
 	} else {
+		api, err := h.appPlatform()
+		if err != nil {
+			return nil, remoteChanges{}, err
+		}
+		if api != nil {
+			object, err := api.get(uid)
+			if err != nil {
+				if errors.Is(err, grizzly.ErrNotFound) {
+					return nil, remoteChanges{}, fmt.Errorf("couldn't fetch folder '%s' from remote: %w", uid, err)
+				}
+				return nil, remoteChanges{}, err
+			}
+			resource, err := h.folderFromObject(*object)
+			return resource, objectChanges(*object), err
+		}
+
 		client, err := h.Provider.(ClientProvider).Client()
 		if err != nil {
-			return nil, err
+			return nil, remoteChanges{}, err
 		}
 
 		folderOk, err := client.Folders.GetFolderByUID(uid)
@@ -178,9 +278,9 @@ func (h *FolderHandler) getRemoteFolder(uid string) (*grizzly.Resource, error) {
 			var gErrNotFound *folders.GetFolderByUIDNotFound
 			var gErrForbidden *folders.GetFolderByUIDForbidden
 			if errors.As(err, &gErrNotFound) || errors.As(err, &gErrForbidden) {
-				return nil, fmt.Errorf("couldn't fetch folder '%s' from remote: %w", uid, grizzly.ErrNotFound)
+				return nil, remoteChanges{}, fmt.Errorf("couldn't fetch folder '%s' from remote: %w", uid, grizzly.ErrNotFound)
 			}
-			return nil, err
+			return nil, remoteChanges{}, err
 		}
 		folder = folderOk.GetPayload()
 	}
@@ -188,14 +288,19 @@ func (h *FolderHandler) getRemoteFolder(uid string) (*grizzly.Resource, error) {
 	// TODO: Turn spec into a real models.Folder object
 	spec, err := structToMap(folder)
 	if err != nil {
-		return nil, err
+		return nil, remoteChanges{}, err
 	}
 
 	resource, err := grizzly.NewResource(h.APIVersion(), h.Kind(), uid, spec)
 	if err != nil {
-		return nil, err
+		return nil, remoteChanges{}, err
 	}
-	return &resource, nil
+	changes := remoteChanges{
+		updated:   time.Time(folder.Updated),
+		updatedBy: folder.UpdatedBy,
+		createdBy: folder.CreatedBy,
+	}
+	return &resource, changes, nil
 }
 
 func (h *FolderHandler) getRemoteFolderList() ([]string, error) {
@@ -206,6 +311,21 @@ func (h *FolderHandler) getRemoteFolderList() ([]string, error) {
 		folderType = "dash-folder"
 	)
 
+	api, err := h.appPlatform()
+	if err != nil {
+		return nil, err
+	}
+	if api != nil {
+		objects, err := api.list()
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range objects {
+			uids = append(uids, object.Metadata.Name)
+		}
+		return uids, nil
+	}
+
 	params := search.NewSearchParams().WithLimit(&limit)
 	params.Type = &folderType
 	client, err := h.Provider.(ClientProvider).Client()
@@ -252,10 +372,22 @@ func (h *FolderHandler) postFolder(resource grizzly.Resource) error {
 		return fmt.Errorf("missing title in folder spec")
 	}
 
+	// descriptions aren't part of the folder model
+	description, _ := resource.GetSpecValue("description").(string)
+
+	api, err := h.appPlatform()
+	if err != nil {
+		return err
+	}
+	if api != nil {
+		return api.save(h.folderToObject(name, folder, description))
+	}
+
 	body := models.CreateFolderCommand{
-		Title:     folder.Title,
-		UID:       folder.UID,
-		ParentUID: folder.ParentUID,
+		Title:       folder.Title,
+		UID:         folder.UID,
+		ParentUID:   folder.ParentUID,
+		Description: description,
 	}
 	client, err := h.Provider.(ClientProvider).Client()
 	if err != nil {
@@ -266,7 +398,7 @@ func (h *FolderHandler) postFolder(resource grizzly.Resource) error {
 	return err
 }
 
-func (h *FolderHandler) putFolder(resource grizzly.Resource) error {
+func (h *FolderHandler) putFolder(existing, resource grizzly.Resource) error {
 	// TODO: Turn spec into a real models.Folder object
 	data, err := json.Marshal(resource.Spec())
 	if err != nil {
@@ -282,19 +414,80 @@ func (h *FolderHandler) putFolder(resource grizzly.Resource) error {
 		return fmt.Errorf("missing title in folder spec")
 	}
 
-	body := models.UpdateFolderCommand{
-		Title:     folder.Title,
-		Overwrite: true,
+	// descriptions aren't part of the folder model
+	description, _ := resource.GetSpecValue("description").(string)
+
+	api, err := h.appPlatform()
+	if err != nil {
+		return err
 	}
+	if api != nil {
+		return api.save(h.folderToObject(resource.Name(), folder, description))
+	}
+
 	client, err := h.Provider.(ClientProvider).Client()
 	if err != nil {
 		return err
 	}
 
+	// updates leave folders where they are, moving them takes its own request
+	if existingParentUID, _ := existing.GetSpecString("parentUid"); existingParentUID != folder.ParentUID {
+		if _, err := client.Folders.MoveFolder(resource.Name(), &models.MoveFolderCommand{ParentUID: folder.ParentUID}); err != nil {
+			return fmt.Errorf("moving folder to '%s': %w", folder.ParentUID, err)
+		}
+	}
+
+	body := models.UpdateFolderCommand{
+		Title:       folder.Title,
+		Description: description,
+		Overwrite:   true,
+	}
 	_, err = client.Folders.UpdateFolder(resource.Name(), &body)
 	return err
 }
 
+// appPlatform returns a client for the App Platform folders API, or nil if
+// the legacy API should be used
+func (h *FolderHandler) appPlatform() (*appPlatformClient, error) {
+	return appPlatformFor(h.Provider, folderAPIGroup, "folders")
+}
+
+// folderFromObject converts a folder served by the App Platform API to a resource
+func (h *FolderHandler) folderFromObject(object k8sObject) (*grizzly.Resource, error) {
+	spec := object.Spec
+	if spec == nil {
+		spec = map[string]any{}
+	}
+	spec["uid"] = object.Metadata.Name
+	if parentUID := object.Metadata.Annotations[folderAnnotation]; parentUID != "" {
+		spec["parentUid"] = parentUID
+	}
+
+	resource, err := grizzly.NewResource(h.APIVersion(), h.Kind(), object.Metadata.Name, spec)
+	if err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+// folderToObject converts a folder to an object of the App Platform API
+func (h *FolderHandler) folderToObject(uid string, folder models.Folder, description string) k8sObject {
+	object := k8sObject{
+		Kind: "Folder",
+		Metadata: k8sObjectMeta{
+			Name: uid,
+		},
+		Spec: map[string]any{"title": folder.Title},
+	}
+	if description != "" {
+		object.Spec["description"] = description
+	}
+	if folder.ParentUID != "" {
+		object.Metadata.Annotations = map[string]string{folderAnnotation: folder.ParentUID}
+	}
+	return object
+}
+
 var getFolderByID = func(client *gclient.GrafanaHTTPAPI, folderId int64) (*models.Folder, error) {
 	folderOk, err := client.Folders.GetFolderByID(folderId)
 	if err != nil {
@@ -0,0 +1,379 @@
+package grafana
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
+)
+
+const FolderTreeKind = "FolderTree"
+
+var _ grizzly.Handler = &FolderTreeHandler{}
+var _ grizzly.CompositeHandler = &FolderTreeHandler{}
+var _ grizzly.ConcurrencyLimiter = &FolderTreeHandler{}
+
+// FolderTreeHandler is a Grizzly Handler for trees of nested dashboard
+// folders, described in a single resource. Applying a tree adds or updates
+// each of its folders, parents first.
+type FolderTreeHandler struct {
+	grizzly.BaseHandler
+	folders *FolderHandler
+}
+
+// NewFolderTreeHandler returns a new Grizzly Handler for folder trees
+func NewFolderTreeHandler(provider grizzly.Provider) *FolderTreeHandler {
+	return &FolderTreeHandler{
+		BaseHandler: grizzly.NewBaseHandler(provider, FolderTreeKind, false),
+		folders:     NewFolderHandler(provider),
+	}
+}
+
+// folderTree is the spec of a folder tree
+type folderTree struct {
+	Folders []folderTreeNode `json:"folders"`
+}
+
+type folderTreeNode struct {
+	UID         string `json:"uid"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	// Permissions are only managed for folders declaring them
+	Permissions []folderPermission `json:"permissions,omitempty"`
+	Folders     []folderTreeNode   `json:"folders,omitempty"`
+}
+
+// folderPermission grants a permission on a folder to a role, a team or a user
+type folderPermission struct {
+	Role       string `json:"role,omitempty"`
+	TeamID     int64  `json:"teamId,omitempty"`
+	UserID     int64  `json:"userId,omitempty"`
+	Permission string `json:"permission"`
+}
+
+var folderPermissionTypes = map[string]models.PermissionType{
+	"View":  1,
+	"Edit":  2,
+	"Admin": 4,
+}
+
+// ErrorHint implements grizzly.ErrorHinter
+func (h *FolderTreeHandler) ErrorHint(code grizzly.ErrorCode) string {
+	return permissionsHint(code, "folders")
+}
+
+// ComponentKind implements grizzly.CompositeHandler
+func (h *FolderTreeHandler) ComponentKind() string {
+	return DashboardFolderKind
+}
+
+// MaxConcurrency implements grizzly.ConcurrencyLimiter. Trees can share
+// folders, so they're applied one at a time.
+func (h *FolderTreeHandler) MaxConcurrency() int {
+	return 1
+}
+
+const (
+	folderTreePattern = "folders/tree-%s.%s"
+)
+
+// ResourceFilePath returns the location on disk where a resource should be updated
+func (h *FolderTreeHandler) ResourceFilePath(resource grizzly.Resource, filetype string) string {
+	return fmt.Sprintf(folderTreePattern, resource.Name(), filetype)
+}
+
+// Validate checks that the folders of a tree have a UID and a title, and that
+// each UID is only used once
+func (h *FolderTreeHandler) Validate(resource grizzly.Resource) error {
+	tree, err := parseFolderTree(resource)
+	if err != nil {
+		return err
+	}
+
+	seen := map[string]bool{}
+	return walkFolderTree(tree.Folders, "", func(node folderTreeNode, parentUID string) error {
+		if node.UID == "" || node.Title == "" {
+			return fmt.Errorf("folders of a tree need a uid and a title, found %q titled %q", node.UID, node.Title)
+		}
+		if seen[node.UID] {
+			return fmt.Errorf("folder %s is declared more than once", node.UID)
+		}
+		seen[node.UID] = true
+		for _, permission := range node.Permissions {
+			if _, ok := folderPermissionTypes[permission.Permission]; !ok {
+				return fmt.Errorf("folder %s: unknown permission %q, expected View, Edit or Admin", node.UID, permission.Permission)
+			}
+		}
+		return nil
+	})
+}
+
+func (h *FolderTreeHandler) GetSpecUID(resource grizzly.Resource) (string, error) {
+	return "", fmt.Errorf("folder trees are named by their metadata")
+}
+
+// GetByUID retrieves all remote folders as a single tree
+func (h *FolderTreeHandler) GetByUID(uid string) (*grizzly.Resource, error) {
+	uids, err := h.folders.ListRemote()
+	if err != nil {
+		return nil, err
+	}
+
+	folders := make([]grizzly.Resource, 0, len(uids))
+	withPermissions := map[string]bool{}
+	for _, uid := range uids {
+		folder, err := h.folders.getRemoteFolder(uid)
+		if err != nil {
+			return nil, err
+		}
+		folders = append(folders, *folder)
+		withPermissions[uid] = true
+	}
+	return h.buildTree(uid, folders, withPermissions)
+}
+
+// GetRemote retrieves the remote folders declared by a tree, nested as they
+// are remotely
+func (h *FolderTreeHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
+	tree, err := parseFolderTree(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	var folders []grizzly.Resource
+	withPermissions := map[string]bool{}
+	err = walkFolderTree(tree.Folders, "", func(node folderTreeNode, parentUID string) error {
+		folder, err := h.folders.getRemoteFolder(node.UID)
+		if errors.Is(err, grizzly.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		folders = append(folders, *folder)
+		withPermissions[node.UID] = len(node.Permissions) > 0
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(folders) == 0 {
+		return nil, fmt.Errorf("no folder of tree %s found remotely: %w", resource.Name(), grizzly.ErrNotFound)
+	}
+	return h.buildTree(resource.Name(), folders, withPermissions)
+}
+
+// ListRemote returns a single tree, holding all remote folders
+func (h *FolderTreeHandler) ListRemote() ([]string, error) {
+	return []string{"folders"}, nil
+}
+
+// Add creates the folders of a tree
+func (h *FolderTreeHandler) Add(resource grizzly.Resource) error {
+	return h.applyTree(resource)
+}
+
+// Update adds or updates the folders of a tree
+func (h *FolderTreeHandler) Update(existing, resource grizzly.Resource) error {
+	return h.applyTree(resource)
+}
+
+// applyTree expands a tree into folders, and adds or updates each of them,
+// parents first
+func (h *FolderTreeHandler) applyTree(resource grizzly.Resource) error {
+	tree, err := parseFolderTree(resource)
+	if err != nil {
+		return err
+	}
+
+	return walkFolderTree(tree.Folders, "", func(node folderTreeNode, parentUID string) error {
+		spec := map[string]any{"uid": node.UID, "title": node.Title}
+		if parentUID != "" {
+			spec["parentUid"] = parentUID
+		}
+		if node.Description != "" {
+			spec["description"] = node.Description
+		}
+		folder, err := grizzly.NewResource(h.APIVersion(), DashboardFolderKind, node.UID, spec)
+		if err != nil {
+			return err
+		}
+
+		existing, err := h.folders.GetRemote(folder)
+		switch {
+		case errors.Is(err, grizzly.ErrNotFound):
+			if err := h.folders.Add(folder); err != nil {
+				return fmt.Errorf("adding folder %s: %w", node.UID, err)
+			}
+			notifier.Info(folder, "added")
+		case err != nil:
+			return err
+		case folderChanged(*h.folders.Unprepare(*existing), folder):
+			if err := h.folders.Update(*existing, folder); err != nil {
+				return fmt.Errorf("updating folder %s: %w", node.UID, err)
+			}
+			notifier.Info(folder, "updated")
+		}
+
+		if len(node.Permissions) == 0 {
+			return nil
+		}
+		if err := h.putFolderPermissions(node.UID, node.Permissions); err != nil {
+			return fmt.Errorf("updating permissions of folder %s: %w", node.UID, err)
+		}
+		return nil
+	})
+}
+
+// folderChanged identifies whether the fields of a folder managed by trees differ
+func folderChanged(existing, folder grizzly.Resource) bool {
+	for _, key := range []string{"title", "parentUid", "description"} {
+		if fmt.Sprint(existing.GetSpecValue(key)) != fmt.Sprint(folder.GetSpecValue(key)) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildTree nests folders by their parent UID, in the order they're given.
+// Folders whose parent isn't given are roots of the tree.
+func (h *FolderTreeHandler) buildTree(name string, folders []grizzly.Resource, withPermissions map[string]bool) (*grizzly.Resource, error) {
+	nodes := map[string]*folderTreeNode{}
+	for _, folder := range folders {
+		node := &folderTreeNode{UID: folder.Name()}
+		node.Title, _ = folder.GetSpecString("title")
+		node.Description, _ = folder.GetSpecString("description")
+		if withPermissions[node.UID] {
+			permissions, err := h.getFolderPermissions(node.UID)
+			if err != nil {
+				return nil, err
+			}
+			node.Permissions = permissions
+		}
+		nodes[node.UID] = node
+	}
+
+	children := map[string][]string{}
+	var roots []string
+	for _, folder := range folders {
+		parentUID, _ := folder.GetSpecString("parentUid")
+		if _, ok := nodes[parentUID]; ok && parentUID != folder.Name() {
+			children[parentUID] = append(children[parentUID], folder.Name())
+			continue
+		}
+		roots = append(roots, folder.Name())
+	}
+	var nest func(uid string, seen map[string]bool) folderTreeNode
+	nest = func(uid string, seen map[string]bool) folderTreeNode {
+		node := *nodes[uid]
+		seen[uid] = true
+		for _, child := range children[uid] {
+			if !seen[child] {
+				node.Folders = append(node.Folders, nest(child, seen))
+			}
+		}
+		return node
+	}
+
+	var tree folderTree
+	seen := map[string]bool{}
+	for _, root := range roots {
+		tree.Folders = append(tree.Folders, nest(root, seen))
+	}
+
+	spec, err := structToMap(tree)
+	if err != nil {
+		return nil, err
+	}
+	resource, err := grizzly.NewResource(h.APIVersion(), h.Kind(), name, spec)
+	if err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+func (h *FolderTreeHandler) getFolderPermissions(uid string) ([]folderPermission, error) {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+
+	permissionsOk, err := client.FolderPermissions.GetFolderPermissionList(uid)
+	if err != nil {
+		return nil, err
+	}
+
+	var permissions []folderPermission
+	for _, item := range permissionsOk.GetPayload() {
+		if item.Inherited || (item.Role == "" && item.TeamID == 0 && item.UserID == 0) {
+			continue
+		}
+		for name, permissionType := range folderPermissionTypes {
+			if item.Permission == permissionType {
+				permissions = append(permissions, folderPermission{Role: item.Role, TeamID: item.TeamID, UserID: item.UserID, Permission: name})
+			}
+		}
+	}
+	sort.SliceStable(permissions, func(i, j int) bool {
+		a, b := permissions[i], permissions[j]
+		// roles first, then teams, then users
+		if a.Role != b.Role {
+			return b.Role == "" || (a.Role != "" && a.Role < b.Role)
+		}
+		if a.TeamID != b.TeamID {
+			return a.TeamID < b.TeamID
+		}
+		return a.UserID < b.UserID
+	})
+	return permissions, nil
+}
+
+// putFolderPermissions replaces the permissions of a folder, apart from
+// those it inherits from its parents
+func (h *FolderTreeHandler) putFolderPermissions(uid string, permissions []folderPermission) error {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+
+	body := models.UpdateDashboardACLCommand{}
+	for _, permission := range permissions {
+		body.Items = append(body.Items, &models.DashboardACLUpdateItem{
+			Role:       permission.Role,
+			TeamID:     permission.TeamID,
+			UserID:     permission.UserID,
+			Permission: folderPermissionTypes[permission.Permission],
+		})
+	}
+	_, err = client.FolderPermissions.UpdateFolderPermissions(uid, &body)
+	return err
+}
+
+func parseFolderTree(resource grizzly.Resource) (folderTree, error) {
+	var tree folderTree
+	data, err := json.Marshal(resource.Spec())
+	if err != nil {
+		return tree, err
+	}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return tree, fmt.Errorf("invalid folder tree %s: %w", resource.Name(), err)
+	}
+	return tree, nil
+}
+
+// walkFolderTree calls fn for each folder of a tree, parents first
+func walkFolderTree(nodes []folderTreeNode, parentUID string, fn func(node folderTreeNode, parentUID string) error) error {
+	for _, node := range nodes {
+		if err := fn(node, parentUID); err != nil {
+			return err
+		}
+		if err := walkFolderTree(node.Folders, node.UID, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
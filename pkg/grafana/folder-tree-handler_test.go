@@ -0,0 +1,147 @@
+package grafana
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFolderTree(t *testing.T) {
+	objects := map[string]k8sObject{}
+	permissions := map[string][]*models.DashboardACLUpdateItem{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /apis", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"groups": [{
+			"name": "folder.grafana.app",
+			"versions": [{"version": "v1beta1"}],
+			"preferredVersion": {"version": "v1beta1"}
+		}]}`))
+	})
+	mux.HandleFunc("GET /api/frontend/settings", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"namespace": "default"}`))
+	})
+	base := "/apis/folder.grafana.app/v1beta1/namespaces/default/folders"
+	mux.HandleFunc("GET "+base, func(w http.ResponseWriter, r *http.Request) {
+		list := k8sList{}
+		for _, uid := range []string{"platform", "databases", "network"} {
+			if object, ok := objects[uid]; ok {
+				list.Items = append(list.Items, object)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	})
+	mux.HandleFunc("GET "+base+"/{name}", func(w http.ResponseWriter, r *http.Request) {
+		object, ok := objects[r.PathValue("name")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"kind": "Status", "message": "not found", "reason": "NotFound"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(object)
+	})
+	save := func(w http.ResponseWriter, r *http.Request) {
+		var object k8sObject
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&object))
+		objects[object.Metadata.Name] = object
+		_ = json.NewEncoder(w).Encode(object)
+	}
+	mux.HandleFunc("POST "+base, save)
+	mux.HandleFunc("PUT "+base+"/{name}", save)
+	mux.HandleFunc("GET /api/folders/{uid}/permissions", func(w http.ResponseWriter, r *http.Request) {
+		items := []map[string]any{{"role": "Admin", "permission": 4, "inherited": true}}
+		for _, item := range permissions[r.PathValue("uid")] {
+			items = append(items, map[string]any{"role": item.Role, "teamId": item.TeamID, "userId": item.UserID, "permission": item.Permission})
+		}
+		_ = json.NewEncoder(w).Encode(items)
+	})
+	mux.HandleFunc("POST /api/folders/{uid}/permissions", func(w http.ResponseWriter, r *http.Request) {
+		var body models.UpdateDashboardACLCommand
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		permissions[r.PathValue("uid")] = body.Items
+		_, _ = w.Write([]byte(`{}`))
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mux.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL})
+	handler := NewFolderTreeHandler(provider)
+
+	spec := map[string]any{
+		"folders": []any{
+			map[string]any{
+				"uid":         "platform",
+				"title":       "Platform",
+				"permissions": []any{map[string]any{"teamId": 3, "permission": "Admin"}, map[string]any{"role": "Viewer", "permission": "View"}},
+				"folders": []any{
+					map[string]any{"uid": "databases", "title": "Databases"},
+					map[string]any{"uid": "network", "title": "Network", "description": "DNS"},
+				},
+			},
+		},
+	}
+	tree, err := grizzly.NewResource(provider.APIVersion(), FolderTreeKind, "platform", spec)
+	require.NoError(t, err)
+	require.NoError(t, handler.Validate(tree))
+
+	_, err = handler.GetRemote(tree)
+	require.ErrorIs(t, err, grizzly.ErrNotFound)
+
+	require.NoError(t, handler.Add(tree))
+	require.Len(t, objects, 3)
+	require.Equal(t, "platform", objects["network"].Metadata.Annotations[folderAnnotation])
+	require.Equal(t, "DNS", objects["network"].Spec["description"])
+	require.Len(t, permissions["platform"], 2)
+	require.Empty(t, permissions["databases"], "permissions are only managed when declared")
+
+	remote, err := handler.GetRemote(tree)
+	require.NoError(t, err)
+	require.Equal(t, []any{
+		map[string]any{
+			"uid":   "platform",
+			"title": "Platform",
+			"permissions": []any{
+				map[string]any{"role": "Viewer", "permission": "View"},
+				map[string]any{"teamId": float64(3), "permission": "Admin"},
+			},
+			"folders": []any{
+				map[string]any{"uid": "databases", "title": "Databases"},
+				map[string]any{"uid": "network", "title": "Network", "description": "DNS"},
+			},
+		},
+	}, remote.GetSpecValue("folders"))
+
+	pulled, err := handler.GetByUID("folders")
+	require.NoError(t, err)
+	require.Equal(t, "folders", pulled.Name())
+	require.Len(t, pulled.GetSpecValue("folders"), 1)
+
+	t.Run("invalid trees", func(t *testing.T) {
+		for _, folders := range [][]any{
+			{map[string]any{"uid": "a"}},
+			{map[string]any{"uid": "a", "title": "A"}, map[string]any{"uid": "a", "title": "B"}},
+			{map[string]any{"uid": "a", "title": "A", "permissions": []any{map[string]any{"role": "Viewer", "permission": "Read"}}}},
+		} {
+			invalid, err := grizzly.NewResource(provider.APIVersion(), FolderTreeKind, "invalid", map[string]any{"folders": folders})
+			require.NoError(t, err)
+			require.Error(t, handler.Validate(invalid))
+		}
+	})
+}
+
+func TestFolderTreeIsOnlyListedWhenTargeted(t *testing.T) {
+	registry := grizzly.NewRegistry([]grizzly.Provider{NewProvider(&config.GrafanaConfig{})})
+	handler, err := registry.GetHandler(FolderTreeKind)
+	require.NoError(t, err)
+	require.False(t, registry.HandlerMatchesTarget(handler, nil))
+	require.True(t, registry.HandlerMatchesTarget(handler, []string{"FolderTree"}))
+}
@@ -1,8 +1,12 @@
 package grafana
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/grafana/grizzly/pkg/config"
 	"github.com/grafana/grizzly/pkg/grizzly"
 	"github.com/stretchr/testify/require"
 )
@@ -83,6 +87,15 @@ func TestSortFolders(t *testing.T) {
 			},
 			expected: []string{"a", "b"},
 		},
+		{
+			name: "cycle",
+			folders: []grizzly.Resource{
+				folder("c", ""),
+				folder("a", "b"),
+				folder("b", "a"),
+			},
+			expected: []string{"c", "a", "b"},
+		},
 	}
 
 	for _, tc := range cases {
@@ -95,3 +108,33 @@ func TestSortFolders(t *testing.T) {
 		})
 	}
 }
+
+func TestMoveFolder(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		parentUID, _ := body["parentUid"].(string)
+		title, _ := body["title"].(string)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+parentUID+title)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"uid": "team-a"}`))
+	}))
+	t.Cleanup(server.Close)
+	handler := NewFolderHandler(NewProvider(&config.GrafanaConfig{URL: server.URL, APIMode: APIModeLegacy}))
+
+	folder := func(parentUID string) grizzly.Resource {
+		resource, err := grizzly.NewResource(handler.APIVersion(), handler.Kind(), "team-a", map[string]any{"uid": "team-a", "title": "Team A", "parentUid": parentUID})
+		require.NoError(t, err)
+		return resource
+	}
+
+	require.NoError(t, handler.Update(folder("teams"), folder("teams")))
+	require.Equal(t, []string{"PUT /api/folders/team-a Team A"}, requests)
+
+	requests = nil
+	require.NoError(t, handler.Update(folder("teams"), folder("platform")))
+	require.Equal(t, []string{"POST /api/folders/team-a/move platform", "PUT /api/folders/team-a Team A"}, requests)
+
+	require.ErrorContains(t, handler.Validate(folder("team-a")), "can't be its own parent")
+}
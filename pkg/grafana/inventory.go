@@ -0,0 +1,50 @@
+package grafana
+
+import (
+	"time"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+// Annotations recording who changed App Platform resources, and when
+const (
+	createdByAnnotation        = "grafana.app/createdBy"
+	updatedByAnnotation        = "grafana.app/updatedBy"
+	updatedTimestampAnnotation = "grafana.app/updatedTimestamp"
+)
+
+// remoteChanges records when, and by whom, a remote resource was last changed
+type remoteChanges struct {
+	updated   time.Time
+	updatedBy string
+	createdBy string
+}
+
+// objectChanges reads the changes recorded in the metadata of an App Platform resource
+func objectChanges(object k8sObject) remoteChanges {
+	changes := remoteChanges{
+		updatedBy: object.Metadata.Annotations[updatedByAnnotation],
+		createdBy: object.Metadata.Annotations[createdByAnnotation],
+	}
+	// resources that were never updated only have a creation timestamp
+	timestamp := object.Metadata.Annotations[updatedTimestampAnnotation]
+	if timestamp == "" {
+		timestamp = object.Metadata.CreationTimestamp
+	}
+	changes.updated, _ = time.Parse(time.RFC3339, timestamp)
+	if changes.updatedBy == "" {
+		changes.updatedBy = changes.createdBy
+	}
+	return changes
+}
+
+// describe returns the inventory entry of a remote resource
+func (c remoteChanges) describe(resource grizzly.Resource) *grizzly.InventoryEntry {
+	entry := grizzly.DescribeResource(resource)
+	if !c.updated.IsZero() {
+		entry.Updated = &c.updated
+	}
+	entry.UpdatedBy = c.updatedBy
+	entry.Owner = c.createdBy
+	return &entry
+}
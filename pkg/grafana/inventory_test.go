@@ -0,0 +1,65 @@
+package grafana
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInventory(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/search", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"uid": "first"}, {"uid": "second"}]`))
+	})
+	mux.HandleFunc("GET /api/dashboards/uid/first", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{
+			"dashboard": {"uid": "first", "title": "First", "tags": ["team-a", "prod"]},
+			"meta": {"folderUid": "team-a", "updated": "2024-05-01T10:00:00Z", "updatedBy": "alice", "createdBy": "bob"}
+		}`))
+	})
+	mux.HandleFunc("GET /api/dashboards/uid/second", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"dashboard": {"uid": "second", "title": "Second"}, "meta": {"folderUid": "general"}}`))
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mux.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	registry := grizzly.NewRegistry([]grizzly.Provider{NewProvider(&config.GrafanaConfig{URL: server.URL})})
+	targets := []string{"Dashboard/*"}
+
+	t.Run("csv", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, grizzly.Inventory(registry, targets, grizzly.InventoryFormatCSV, &out))
+		require.Equal(t, "kind,uid,title,folder,tags,updated,updated_by,owner\n"+
+			"Dashboard,first,First,team-a,\"team-a,prod\",2024-05-01T10:00:00Z,alice,bob\n"+
+			"Dashboard,second,Second,general,,,,\n", out.String())
+	})
+
+	t.Run("parquet", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, grizzly.Inventory(registry, targets, grizzly.InventoryFormatParquet, &out))
+
+		entries, err := parquet.Read[grizzly.InventoryEntry](bytes.NewReader(out.Bytes()), int64(out.Len()))
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		require.Equal(t, "first", entries[0].UID)
+		require.Equal(t, []string{"team-a", "prod"}, entries[0].Tags)
+		require.True(t, entries[0].Updated.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)))
+		require.Equal(t, "bob", entries[0].Owner)
+		require.Nil(t, entries[1].Updated)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		err := grizzly.Inventory(registry, targets, "xlsx", &bytes.Buffer{})
+		require.ErrorContains(t, err, "unknown inventory format xlsx")
+	})
+}
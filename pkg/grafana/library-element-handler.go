@@ -14,6 +14,7 @@ const LibraryElementKind = "LibraryElement"
 
 var _ grizzly.Handler = &LibraryElementHandler{}
 var _ grizzly.ProxyConfiguratorProvider = &LibraryElementHandler{}
+var _ grizzly.UIDHandler = &LibraryElementHandler{}
 
 // LibraryElementHandler is a Grizzly Handler for Grafana dashboard folders
 type LibraryElementHandler struct {
@@ -27,6 +28,11 @@ func NewLibraryElementHandler(provider grizzly.Provider) *LibraryElementHandler
 	}
 }
 
+// ErrorHint implements grizzly.ErrorHinter
+func (h *LibraryElementHandler) ErrorHint(code grizzly.ErrorCode) string {
+	return permissionsHint(code, "library.panels")
+}
+
 const (
 	libraryElementPattern = "library-elements/%s-%s.%s"
 )
@@ -102,6 +108,16 @@ func (h *LibraryElementHandler) GetSpecUID(resource grizzly.Resource) (string, e
 	return uid, nil
 }
 
+// UIDConstraints implements grizzly.UIDHandler
+func (h *LibraryElementHandler) UIDConstraints() grizzly.UIDConstraints {
+	return uidConstraints
+}
+
+// SetUID implements grizzly.UIDHandler
+func (h *LibraryElementHandler) SetUID(resource grizzly.Resource, uid string) grizzly.Resource {
+	return setSpecUID(resource, uid)
+}
+
 // GetByUID retrieves JSON for a resource from an endpoint, by UID
 func (h *LibraryElementHandler) GetByUID(uid string) (*grizzly.Resource, error) {
 	resource, err := h.getRemoteLibraryElement(uid)
@@ -132,6 +148,17 @@ func (h *LibraryElementHandler) Update(existing, resource grizzly.Resource) erro
 	return h.updateElement(existing, resource)
 }
 
+// Delete removes a library element from Grafana via the API
+func (h *LibraryElementHandler) Delete(resource grizzly.Resource) error {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.LibraryElements.DeleteLibraryElementByUID(resource.Name())
+	return err
+}
+
 func (h *LibraryElementHandler) listElements() ([]string, error) {
 	params := library.NewGetLibraryElementsParams()
 	client, err := h.Provider.(ClientProvider).Client()
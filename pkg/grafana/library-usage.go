@@ -0,0 +1,228 @@
+package grafana
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	library "github.com/grafana/grafana-openapi-client-go/client/library_elements"
+	"github.com/grafana/grafana-openapi-client-go/client/search"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"gopkg.in/yaml.v3"
+)
+
+// Statuses of library elements in usage reports
+const (
+	// LibraryElementOrphan is used by no dashboard, locally or remotely
+	LibraryElementOrphan = "orphan"
+	// LibraryElementNotLocal is used by remote dashboards, but isn't declared locally
+	LibraryElementNotLocal = "not-local"
+	// LibraryElementMissing is used by local dashboards, but exists neither locally nor remotely
+	LibraryElementMissing = "missing"
+)
+
+// LibraryElementUsage describes the dashboards using a library element,
+// locally and remotely
+type LibraryElementUsage struct {
+	UID              string   `yaml:"uid" json:"uid"`
+	Name             string   `yaml:"name,omitempty" json:"name,omitempty"`
+	Local            bool     `yaml:"local" json:"local"`
+	Remote           bool     `yaml:"remote" json:"remote"`
+	LocalDashboards  []string `yaml:"localDashboards,omitempty" json:"localDashboards,omitempty"`
+	RemoteDashboards []string `yaml:"remoteDashboards,omitempty" json:"remoteDashboards,omitempty"`
+	// Status flags elements needing attention before cleaning up: orphan,
+	// not-local or missing. Empty otherwise.
+	Status string `yaml:"status,omitempty" json:"status,omitempty"`
+}
+
+// LibraryUsage lists every library element, declared locally or existing
+// remotely, with the local and remote dashboards using it, by UID
+func LibraryUsage(registry grizzly.Registry, resources grizzly.Resources) ([]LibraryElementUsage, error) {
+	handler, err := registry.GetHandler(LibraryElementKind)
+	if err != nil {
+		return nil, err
+	}
+	elementHandler, ok := handler.(*LibraryElementHandler)
+	if !ok {
+		return nil, fmt.Errorf("kind %s isn't handled by Grafana", LibraryElementKind)
+	}
+
+	usages := map[string]*LibraryElementUsage{}
+	usage := func(uid string) *LibraryElementUsage {
+		if usages[uid] == nil {
+			usages[uid] = &LibraryElementUsage{UID: uid}
+		}
+		return usages[uid]
+	}
+
+	for _, resource := range resources.AsList() {
+		switch resource.Kind() {
+		case LibraryElementKind:
+			element := usage(resource.Name())
+			element.Local = true
+			element.Name, _ = resource.GetSpecString("name")
+		case DashboardKind:
+			for _, uid := range libraryPanelUIDs(resource) {
+				element := usage(uid)
+				element.LocalDashboards = append(element.LocalDashboards, resource.Name())
+			}
+		}
+	}
+
+	remote, err := elementHandler.remoteUsage()
+	if err != nil {
+		return nil, grizzly.ClassifyError(handler, err)
+	}
+	for _, remoteElement := range remote {
+		element := usage(remoteElement.UID)
+		element.Remote = true
+		if element.Name == "" {
+			element.Name = remoteElement.Name
+		}
+		element.RemoteDashboards = remoteElement.RemoteDashboards
+	}
+
+	list := make([]LibraryElementUsage, 0, len(usages))
+	for _, element := range usages {
+		element.LocalDashboards = sortedUnique(element.LocalDashboards)
+		element.RemoteDashboards = sortedUnique(element.RemoteDashboards)
+		switch {
+		case !element.Local && !element.Remote:
+			element.Status = LibraryElementMissing
+		case !element.Local && len(element.RemoteDashboards) > 0:
+			element.Status = LibraryElementNotLocal
+		case len(element.LocalDashboards) == 0 && len(element.RemoteDashboards) == 0:
+			element.Status = LibraryElementOrphan
+		}
+		list = append(list, *element)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].UID < list[j].UID })
+	return list, nil
+}
+
+// FormatLibraryUsage renders a library usage report as a table, or as JSON or YAML
+func FormatLibraryUsage(usages []LibraryElementUsage, format string) ([]byte, error) {
+	switch format {
+	case "yaml":
+		return yaml.Marshal(usages)
+	case "json":
+		return json.MarshalIndent(usages, "", "  ")
+	case "default":
+		var out bytes.Buffer
+		w := tabwriter.NewWriter(&out, 0, 0, 4, ' ', 0)
+
+		f := "%s\t%s\t%s\t%s\t%s\n"
+		fmt.Fprintf(w, f, "ELEMENT", "NAME", "LOCAL DASHBOARDS", "REMOTE DASHBOARDS", "STATUS")
+		for _, usage := range usages {
+			fmt.Fprintf(w, f, usage.UID, usage.Name, dashboardList(usage.LocalDashboards, usage.Local), dashboardList(usage.RemoteDashboards, usage.Remote), usage.Status)
+		}
+		err := w.Flush()
+		return out.Bytes(), err
+	default:
+		return nil, fmt.Errorf("unknown format %s", format)
+	}
+}
+
+// dashboardList lists dashboards in a cell, or tells whether the element
+// exists at all where no dashboard uses it
+func dashboardList(dashboards []string, exists bool) string {
+	if len(dashboards) > 0 {
+		return strings.Join(dashboards, ",")
+	}
+	if !exists {
+		return "(no element)"
+	}
+	return "-"
+}
+
+// libraryPanelUIDs returns the UIDs of the library panels a dashboard uses
+func libraryPanelUIDs(dashboard grizzly.Resource) []string {
+	var uids []string
+	for _, panel := range dashboardPanels(dashboard.Spec()) {
+		libraryPanel, ok := panel["libraryPanel"].(map[string]any)
+		if !ok {
+			continue
+		}
+		if uid, ok := libraryPanel["uid"].(string); ok && uid != "" {
+			uids = append(uids, uid)
+		}
+	}
+	return uids
+}
+
+// remoteUsage lists the remote library elements, with the UIDs of the
+// dashboards connected to each of them
+func (h *LibraryElementHandler) remoteUsage() ([]LibraryElementUsage, error) {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+
+	var usages []LibraryElementUsage
+	perPage := int64(100)
+	params := library.NewGetLibraryElementsParams().WithPerPage(&perPage)
+	for page := int64(1); ; page++ {
+		params.SetPage(&page)
+		elementsOk, err := client.LibraryElements.GetLibraryElements(params, nil)
+		if err != nil {
+			return nil, err
+		}
+		result := elementsOk.GetPayload().Result
+		for _, element := range result.Elements {
+			usages = append(usages, LibraryElementUsage{UID: element.UID, Name: element.Name, Remote: true})
+		}
+		if int64(len(result.Elements)) < perPage {
+			break
+		}
+	}
+
+	// older versions of Grafana only give the IDs of connected dashboards
+	var unknownIDs []int64
+	connectedIDs := map[string][]int64{}
+	for i, usage := range usages {
+		connectionsOk, err := client.LibraryElements.GetLibraryElementConnections(usage.UID)
+		if err != nil {
+			return nil, fmt.Errorf("listing the connections of library element %s: %w", usage.UID, err)
+		}
+		for _, connection := range connectionsOk.GetPayload().Result {
+			if connection.ConnectionUID != "" {
+				usages[i].RemoteDashboards = append(usages[i].RemoteDashboards, connection.ConnectionUID)
+				continue
+			}
+			connectedIDs[usage.UID] = append(connectedIDs[usage.UID], connection.ConnectionID)
+			unknownIDs = append(unknownIDs, connection.ConnectionID)
+		}
+	}
+	if len(unknownIDs) == 0 {
+		return usages, nil
+	}
+
+	searchType := "dash-db"
+	searchOk, err := client.Search.Search(search.NewSearchParams().WithType(&searchType).WithDashboardIds(unknownIDs), nil)
+	if err != nil {
+		return nil, err
+	}
+	uidsByID := map[int64]string{}
+	for _, hit := range searchOk.GetPayload() {
+		uidsByID[hit.ID] = hit.UID
+	}
+	for i, usage := range usages {
+		for _, id := range connectedIDs[usage.UID] {
+			uid, ok := uidsByID[id]
+			if !ok {
+				uid = fmt.Sprintf("id:%d", id)
+			}
+			usages[i].RemoteDashboards = append(usages[i].RemoteDashboards, uid)
+		}
+	}
+	return usages, nil
+}
+
+func sortedUnique(values []string) []string {
+	slices.Sort(values)
+	return slices.Compact(values)
+}
@@ -0,0 +1,81 @@
+package grafana
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLibraryUsage(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/library-elements", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result": {"elements": [
+			{"uid": "cpu", "name": "CPU"},
+			{"uid": "memory", "name": "Memory"},
+			{"uid": "legacy", "name": "Legacy"}
+		]}}`))
+	})
+	mux.HandleFunc("GET /api/library-elements/{uid}/connections/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.PathValue("uid") {
+		case "cpu":
+			_, _ = w.Write([]byte(`{"result": [{"connectionUid": "node-overview"}]}`))
+		case "legacy":
+			_, _ = w.Write([]byte(`{"result": [{"connectionId": 7}]}`))
+		default:
+			_, _ = w.Write([]byte(`{"result": []}`))
+		}
+	})
+	mux.HandleFunc("GET /api/search", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "7", r.URL.Query().Get("dashboardIds"))
+		_, _ = w.Write([]byte(`[{"id": 7, "uid": "old-dashboard", "type": "dash-db"}]`))
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL})
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+
+	element := func(uid, name string) grizzly.Resource {
+		resource, err := grizzly.NewResource(provider.APIVersion(), LibraryElementKind, uid, map[string]any{"uid": uid, "name": name, "kind": 1})
+		require.NoError(t, err)
+		return resource
+	}
+	dashboard := func(uid string, libraryPanels ...string) grizzly.Resource {
+		var panels []any
+		for _, panel := range libraryPanels {
+			panels = append(panels, map[string]any{"type": "row", "panels": []any{
+				map[string]any{"libraryPanel": map[string]any{"uid": panel}},
+			}})
+		}
+		resource, err := grizzly.NewResource(provider.APIVersion(), DashboardKind, uid, map[string]any{"uid": uid, "panels": panels})
+		require.NoError(t, err)
+		return resource
+	}
+	resources := grizzly.NewResources(
+		element("cpu", "CPU"),
+		element("memory", "Memory"),
+		element("disk", "Disk"),
+		dashboard("node-overview", "cpu", "disk"),
+		dashboard("network", "bandwidth"),
+	)
+
+	usages, err := LibraryUsage(registry, resources)
+	require.NoError(t, err)
+	require.Equal(t, []LibraryElementUsage{
+		{UID: "bandwidth", LocalDashboards: []string{"network"}, Status: LibraryElementMissing},
+		{UID: "cpu", Name: "CPU", Local: true, Remote: true, LocalDashboards: []string{"node-overview"}, RemoteDashboards: []string{"node-overview"}},
+		{UID: "disk", Name: "Disk", Local: true, LocalDashboards: []string{"node-overview"}},
+		{UID: "legacy", Name: "Legacy", Remote: true, RemoteDashboards: []string{"old-dashboard"}, Status: LibraryElementNotLocal},
+		{UID: "memory", Name: "Memory", Local: true, Remote: true, Status: LibraryElementOrphan},
+	}, usages)
+
+	output, err := FormatLibraryUsage(usages, "default")
+	require.NoError(t, err)
+	require.Contains(t, string(output), "memory       Memory    -                   -                    orphan")
+}
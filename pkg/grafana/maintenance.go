@@ -0,0 +1,300 @@
+package grafana
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/grafana/grafana-openapi-client-go/client/provisioning"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+var _ grizzly.MaintenanceProvider = &Provider{}
+
+const (
+	silencesPath = "/alertmanager/grafana/api/v2/silences"
+	silencePath  = "/alertmanager/grafana/api/v2/silence/%s"
+)
+
+type postableSilence struct {
+	Matchers  []grizzly.Matcher `json:"matchers"`
+	StartsAt  time.Time         `json:"startsAt"`
+	EndsAt    time.Time         `json:"endsAt"`
+	CreatedBy string            `json:"createdBy"`
+	Comment   string            `json:"comment"`
+}
+
+type postSilenceResponse struct {
+	SilenceID string `json:"silenceID"`
+}
+
+// StartMaintenance implements grizzly.MaintenanceProvider, silencing alerts
+// in the Grafana Alertmanager and pausing alert rule groups
+func (p *Provider) StartMaintenance(opts grizzly.MaintenanceOptions) (grizzly.MaintenanceWindow, error) {
+	now := time.Now().UTC()
+	window := grizzly.MaintenanceWindow{
+		Provider: p.Name(),
+		EndsAt:   now.Add(opts.Duration),
+	}
+
+	if len(opts.Matchers) > 0 {
+		comment := opts.Comment
+		if comment == "" {
+			comment = "Maintenance started with grr"
+		}
+		var response postSilenceResponse
+		err := p.alertmanagerRequest(http.MethodPost, silencesPath, postableSilence{
+			Matchers:  opts.Matchers,
+			StartsAt:  now,
+			EndsAt:    window.EndsAt,
+			CreatedBy: "grizzly",
+			Comment:   comment,
+		}, &response)
+		if err != nil {
+			return window, fmt.Errorf("creating silence: %w", err)
+		}
+		window.Silences = append(window.Silences, response.SilenceID)
+	}
+
+	for _, name := range opts.MuteTimings {
+		muteTiming, err := p.muteDuringWindow(name, now, window.EndsAt)
+		if err != nil {
+			return window, fmt.Errorf("setting mute timing %s: %w", name, err)
+		}
+		window.MuteTimings = append(window.MuteTimings, muteTiming)
+	}
+
+	for _, uid := range opts.RuleGroups {
+		paused, err := p.setRuleGroupPaused(uid, nil, true)
+		if len(paused) > 0 {
+			if window.PausedRules == nil {
+				window.PausedRules = map[string][]string{}
+			}
+			window.PausedRules[uid] = paused
+		}
+		if err != nil {
+			return window, fmt.Errorf("pausing rule group %s: %w", uid, err)
+		}
+	}
+
+	return window, nil
+}
+
+// StopMaintenance implements grizzly.MaintenanceProvider, expiring silences,
+// restoring mute timings and resuming the alert rules that were paused
+func (p *Provider) StopMaintenance(window grizzly.MaintenanceWindow) error {
+	for _, id := range window.Silences {
+		if err := p.alertmanagerRequest(http.MethodDelete, fmt.Sprintf(silencePath, id), nil, nil); err != nil {
+			if grizzly.ErrorCodeOf(err) == grizzly.ErrorCodeNotFound {
+				continue
+			}
+			return fmt.Errorf("expiring silence %s: %w", id, err)
+		}
+	}
+
+	for _, muteTiming := range window.MuteTimings {
+		if err := p.restoreMuteTiming(muteTiming); err != nil {
+			return fmt.Errorf("restoring mute timing %s: %w", muteTiming.Name, err)
+		}
+	}
+
+	for uid, rules := range window.PausedRules {
+		if _, err := p.setRuleGroupPaused(uid, rules, false); err != nil {
+			return fmt.Errorf("resuming rule group %s: %w", uid, err)
+		}
+	}
+
+	return nil
+}
+
+// muteDuringWindow sets the time intervals of a mute timing to a maintenance
+// window, creating the mute timing if needed. Its previous time intervals are
+// returned, so that they can be restored.
+func (p *Provider) muteDuringWindow(name string, startsAt, endsAt time.Time) (grizzly.MaintenanceMuteTiming, error) {
+	muteTiming := grizzly.MaintenanceMuteTiming{Name: name}
+	client, err := p.Client()
+	if err != nil {
+		return muteTiming, err
+	}
+	body := &models.MuteTimeInterval{Name: name, TimeIntervals: windowIntervals(startsAt, endsAt)}
+
+	existing, err := client.Provisioning.GetMuteTiming(name)
+	var notFound *provisioning.GetMuteTimingNotFound
+	switch {
+	case errors.As(err, &notFound):
+		params := provisioning.NewPostMuteTimingParams().
+			WithBody(body).
+			WithXDisableProvenance(&stringtrue)
+		if _, err := client.Provisioning.PostMuteTiming(params); err != nil {
+			return muteTiming, err
+		}
+		muteTiming.Created = true
+		return muteTiming, nil
+	case err != nil:
+		return muteTiming, err
+	}
+
+	muteTiming.Previous, err = json.Marshal(existing.GetPayload().TimeIntervals)
+	if err != nil {
+		return muteTiming, err
+	}
+	params := provisioning.NewPutMuteTimingParams().
+		WithName(name).
+		WithBody(body).
+		WithXDisableProvenance(&stringtrue)
+	_, err = client.Provisioning.PutMuteTiming(params)
+	return muteTiming, err
+}
+
+// restoreMuteTiming deletes a mute timing created for a maintenance window,
+// or restores its previous time intervals
+func (p *Provider) restoreMuteTiming(muteTiming grizzly.MaintenanceMuteTiming) error {
+	client, err := p.Client()
+	if err != nil {
+		return err
+	}
+	if muteTiming.Created {
+		_, err := client.Provisioning.DeleteMuteTiming(muteTiming.Name)
+		if err != nil && grizzly.ErrorCodeOf(err) != grizzly.ErrorCodeNotFound {
+			return err
+		}
+		return nil
+	}
+
+	var intervals []*models.TimeIntervalItem
+	if err := json.Unmarshal(muteTiming.Previous, &intervals); err != nil {
+		return err
+	}
+	params := provisioning.NewPutMuteTimingParams().
+		WithName(muteTiming.Name).
+		WithBody(&models.MuteTimeInterval{Name: muteTiming.Name, TimeIntervals: intervals}).
+		WithXDisableProvenance(&stringtrue)
+	_, err = client.Provisioning.PutMuteTiming(params)
+	return err
+}
+
+// windowIntervals returns the time intervals of a mute timing covering a
+// maintenance window, one per day of the window, in UTC. Mute timings are
+// precise to the minute: the window is widened to whole minutes.
+func windowIntervals(startsAt, endsAt time.Time) []*models.TimeIntervalItem {
+	startsAt = startsAt.UTC().Truncate(time.Minute)
+	if rounded := endsAt.UTC().Truncate(time.Minute); rounded.Equal(endsAt) {
+		endsAt = rounded
+	} else {
+		endsAt = rounded.Add(time.Minute)
+	}
+
+	var intervals []*models.TimeIntervalItem
+	day := time.Date(startsAt.Year(), startsAt.Month(), startsAt.Day(), 0, 0, 0, 0, time.UTC)
+	for ; day.Before(endsAt); day = day.AddDate(0, 0, 1) {
+		from := startsAt
+		if from.Before(day) {
+			from = day
+		}
+		endTime := endsAt.Format("15:04")
+		if next := day.AddDate(0, 0, 1); !endsAt.Before(next) {
+			endTime = "24:00"
+		}
+		if from.Format("15:04") == endTime {
+			continue
+		}
+		intervals = append(intervals, &models.TimeIntervalItem{
+			Times:       []*models.TimeIntervalTimeRange{{StartTime: from.Format("15:04"), EndTime: endTime}},
+			DaysOfMonth: []string{strconv.Itoa(day.Day())},
+			Months:      []string{strconv.Itoa(int(day.Month()))},
+			Years:       []string{strconv.Itoa(day.Year())},
+			Location:    "UTC",
+		})
+	}
+	return intervals
+}
+
+// setRuleGroupPaused pauses or resumes the given rules of a group, or all of
+// them if none are given. It returns the UIDs of the rules that were changed.
+func (p *Provider) setRuleGroupPaused(uid string, rules []string, paused bool) ([]string, error) {
+	folder, title, found := strings.Cut(uid, ".")
+	if !found {
+		return nil, fmt.Errorf("invalid rule group UID %s, expected <folder>.<group>", uid)
+	}
+
+	client, err := p.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	groupOk, err := client.Provisioning.GetAlertRuleGroup(title, folder)
+	if err != nil {
+		return nil, err
+	}
+	group := groupOk.GetPayload()
+
+	selected := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		selected[rule] = true
+	}
+
+	var changed []string
+	for _, rule := range group.Rules {
+		if rule.IsPaused == paused || (len(rules) > 0 && !selected[rule.UID]) {
+			continue
+		}
+		rule.IsPaused = paused
+		changed = append(changed, rule.UID)
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	params := provisioning.NewPutAlertRuleGroupParams().
+		WithBody(group).
+		WithGroup(title).
+		WithFolderUID(folder).
+		WithXDisableProvenance(&stringtrue)
+	if _, err := client.Provisioning.PutAlertRuleGroup(params); err != nil {
+		return nil, err
+	}
+
+	return changed, nil
+}
+
+// alertmanagerRequest calls the Alertmanager API embedded in Grafana, which the
+// generated client doesn't cover, through the transport of the client so that
+// the authentication and TLS settings are shared.
+func (p *Provider) alertmanagerRequest(method, path string, body any, result any) error {
+	client, err := p.Client()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Transport.Submit(&runtime.ClientOperation{
+		ID:                 "Alertmanager",
+		Method:             method,
+		PathPattern:        path,
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"http", "https"},
+		Params: runtime.ClientRequestWriterFunc(func(req runtime.ClientRequest, _ strfmt.Registry) error {
+			if body == nil {
+				return nil
+			}
+			return req.SetBodyParam(body)
+		}),
+		Reader: runtime.ClientResponseReaderFunc(func(response runtime.ClientResponse, _ runtime.Consumer) (any, error) {
+			if response.Code() >= 300 {
+				return nil, runtime.NewAPIError(fmt.Sprintf("[%s %s]", method, path), response, response.Code())
+			}
+			if result == nil {
+				return nil, nil
+			}
+			return nil, json.NewDecoder(response.Body()).Decode(result)
+		}),
+	})
+	return err
+}
@@ -0,0 +1,131 @@
+package grafana
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenance(t *testing.T) {
+	var silences []postableSilence
+	var deleted []string
+	group := map[string]any{
+		"title":     "group",
+		"folderUID": "folder",
+		"rules": []any{
+			map[string]any{"uid": "active", "title": "Active"},
+			map[string]any{"uid": "paused", "title": "Paused", "isPaused": true},
+		},
+	}
+
+	muteTimings := map[string]models.MuteTimeInterval{
+		"weekends": {Name: "weekends", TimeIntervals: []*models.TimeIntervalItem{{Weekdays: []string{"saturday", "sunday"}}}},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/provisioning/mute-timings/{name}", func(w http.ResponseWriter, r *http.Request) {
+		muteTiming, ok := muteTimings[r.PathValue("name")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(muteTiming)
+	})
+	mux.HandleFunc("POST /api/v1/provisioning/mute-timings", func(w http.ResponseWriter, r *http.Request) {
+		var muteTiming models.MuteTimeInterval
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&muteTiming))
+		muteTimings[muteTiming.Name] = muteTiming
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(muteTiming)
+	})
+	mux.HandleFunc("PUT /api/v1/provisioning/mute-timings/{name}", func(w http.ResponseWriter, r *http.Request) {
+		var muteTiming models.MuteTimeInterval
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&muteTiming))
+		muteTimings[r.PathValue("name")] = muteTiming
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(muteTiming)
+	})
+	mux.HandleFunc("DELETE /api/v1/provisioning/mute-timings/{name}", func(w http.ResponseWriter, r *http.Request) {
+		delete(muteTimings, r.PathValue("name"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /api/alertmanager/grafana/api/v2/silences", func(w http.ResponseWriter, r *http.Request) {
+		var silence postableSilence
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&silence))
+		silences = append(silences, silence)
+		_ = json.NewEncoder(w).Encode(postSilenceResponse{SilenceID: "silence-1"})
+	})
+	mux.HandleFunc("DELETE /api/alertmanager/grafana/api/v2/silence/{id}", func(w http.ResponseWriter, r *http.Request) {
+		deleted = append(deleted, r.PathValue("id"))
+	})
+	mux.HandleFunc("GET /api/v1/provisioning/folder/folder/rule-groups/group", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(group)
+	})
+	mux.HandleFunc("PUT /api/v1/provisioning/folder/folder/rule-groups/group", func(w http.ResponseWriter, r *http.Request) {
+		group = map[string]any{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&group))
+		_ = json.NewEncoder(w).Encode(group)
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mux.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL})
+
+	window, err := provider.StartMaintenance(grizzly.MaintenanceOptions{
+		Duration:    time.Hour,
+		Matchers:    []grizzly.Matcher{{Name: "cluster", Value: "prod", IsEqual: true}},
+		MuteTimings: []string{"weekends", "maintenance"},
+		RuleGroups:  []string{"folder.group"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"silence-1"}, window.Silences)
+	require.Equal(t, map[string][]string{"folder.group": {"active"}}, window.PausedRules)
+	require.Len(t, silences, 1)
+	require.Equal(t, "grizzly", silences[0].CreatedBy)
+	require.Equal(t, true, group["rules"].([]any)[0].(map[string]any)["isPaused"])
+	require.Len(t, window.MuteTimings, 2)
+	require.False(t, window.MuteTimings[0].Created)
+	require.True(t, window.MuteTimings[1].Created, "missing mute timings are created")
+	require.Equal(t, windowIntervals(time.Now(), window.EndsAt)[0].Years, muteTimings["weekends"].TimeIntervals[0].Years, "mute timings are set to the window")
+	require.Contains(t, muteTimings, "maintenance")
+
+	require.NoError(t, provider.StopMaintenance(window))
+	require.Equal(t, []string{"silence-1"}, deleted)
+	require.Equal(t, []string{"saturday", "sunday"}, muteTimings["weekends"].TimeIntervals[0].Weekdays, "mute timings are restored")
+	require.NotContains(t, muteTimings, "maintenance", "mute timings created for the window are deleted")
+	rules := group["rules"].([]any)
+	require.Nil(t, rules[0].(map[string]any)["isPaused"])
+	require.Equal(t, true, rules[1].(map[string]any)["isPaused"], "rules paused before the maintenance window stay paused")
+}
+
+func TestWindowIntervals(t *testing.T) {
+	startsAt := time.Date(2026, time.March, 31, 22, 30, 15, 0, time.UTC)
+	intervals := windowIntervals(startsAt, startsAt.Add(26*time.Hour))
+	require.Equal(t, []*models.TimeIntervalItem{
+		{
+			Times:       []*models.TimeIntervalTimeRange{{StartTime: "22:30", EndTime: "24:00"}},
+			DaysOfMonth: []string{"31"}, Months: []string{"3"}, Years: []string{"2026"}, Location: "UTC",
+		},
+		{
+			Times:       []*models.TimeIntervalTimeRange{{StartTime: "00:00", EndTime: "24:00"}},
+			DaysOfMonth: []string{"1"}, Months: []string{"4"}, Years: []string{"2026"}, Location: "UTC",
+		},
+		{
+			Times:       []*models.TimeIntervalTimeRange{{StartTime: "00:00", EndTime: "00:31"}},
+			DaysOfMonth: []string{"2"}, Months: []string{"4"}, Years: []string{"2026"}, Location: "UTC",
+		},
+	}, intervals, "windows are covered day by day, widened to whole minutes")
+
+	midnight := time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)
+	require.Len(t, windowIntervals(midnight.Add(-time.Hour), midnight), 1, "windows ending at midnight don't spill over the next day")
+}
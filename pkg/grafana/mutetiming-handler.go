@@ -0,0 +1,193 @@
+package grafana
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/grafana/grafana-openapi-client-go/client/provisioning"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+const AlertMuteTimingKind = "AlertMuteTiming"
+
+var _ grizzly.Handler = &AlertMuteTimingHandler{}
+
+// AlertMuteTimingHandler is a Grizzly Handler for Grafana mute timings, the
+// time intervals during which notification policies don't send notifications
+type AlertMuteTimingHandler struct {
+	grizzly.BaseHandler
+}
+
+// NewAlertMuteTimingHandler returns a new Grizzly Handler for Grafana mute timings
+func NewAlertMuteTimingHandler(provider grizzly.Provider) *AlertMuteTimingHandler {
+	return &AlertMuteTimingHandler{
+		BaseHandler: grizzly.NewBaseHandler(provider, AlertMuteTimingKind, false),
+	}
+}
+
+// ErrorHint implements grizzly.ErrorHinter
+func (h *AlertMuteTimingHandler) ErrorHint(code grizzly.ErrorCode) string {
+	return permissionsHint(code, "alert.provisioning")
+}
+
+const (
+	muteTimingPattern = "alert-mute-timings/muteTiming-%s.%s"
+)
+
+// ResourceFilePath returns the location on disk where a resource should be updated
+func (h *AlertMuteTimingHandler) ResourceFilePath(resource grizzly.Resource, filetype string) string {
+	filename := strings.ReplaceAll(resource.Name(), string(os.PathSeparator), "-")
+	filename = strings.ReplaceAll(filename, " ", "-")
+	return fmt.Sprintf(muteTimingPattern, filename, filetype)
+}
+
+// Unprepare removes unnecessary elements from a remote resource ready for presentation/comparison
+func (h *AlertMuteTimingHandler) Unprepare(resource grizzly.Resource) *grizzly.Resource {
+	resource.DeleteSpecKey("provenance")
+	return &resource
+}
+
+// Prepare gets a resource ready for dispatch to the remote endpoint
+func (h *AlertMuteTimingHandler) Prepare(existing *grizzly.Resource, resource grizzly.Resource) *grizzly.Resource {
+	if !resource.HasSpecString("name") {
+		resource.SetSpecString("name", resource.Name())
+	}
+	return &resource
+}
+
+// Validate checks that the name in the spec matches the name of the resource
+func (h *AlertMuteTimingHandler) Validate(resource grizzly.Resource) error {
+	name, exist := resource.GetSpecString("name")
+	if exist && name != resource.Name() {
+		return fmt.Errorf("name '%s' and name '%s', don't match", name, resource.Name())
+	}
+	return nil
+}
+
+func (h *AlertMuteTimingHandler) GetSpecUID(resource grizzly.Resource) (string, error) {
+	name, ok := resource.GetSpecString("name")
+	if !ok {
+		return "", fmt.Errorf("name not specified")
+	}
+	return name, nil
+}
+
+// GetByUID retrieves JSON for a resource from an endpoint, by UID
+func (h *AlertMuteTimingHandler) GetByUID(uid string) (*grizzly.Resource, error) {
+	return h.getRemoteMuteTiming(uid)
+}
+
+// GetRemote retrieves a mute timing as a Resource
+func (h *AlertMuteTimingHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
+	return h.getRemoteMuteTiming(resource.Name())
+}
+
+// ListRemote retrieves the names of all remote mute timings
+func (h *AlertMuteTimingHandler) ListRemote() ([]string, error) {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+
+	muteTimingsOk, err := client.Provisioning.GetMuteTimings()
+	if err != nil {
+		return nil, err
+	}
+	muteTimings := muteTimingsOk.GetPayload()
+	names := make([]string, len(muteTimings))
+	for i, muteTiming := range muteTimings {
+		names[i] = muteTiming.Name
+	}
+	return names, nil
+}
+
+// Add pushes a mute timing to Grafana via the API
+func (h *AlertMuteTimingHandler) Add(resource grizzly.Resource) error {
+	muteTiming, err := h.specToMuteTiming(resource)
+	if err != nil {
+		return err
+	}
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+	params := provisioning.NewPostMuteTimingParams().
+		WithBody(muteTiming).
+		WithXDisableProvenance(&stringtrue)
+	_, err = client.Provisioning.PostMuteTiming(params)
+	return err
+}
+
+// Update pushes a mute timing to Grafana via the API
+func (h *AlertMuteTimingHandler) Update(existing, resource grizzly.Resource) error {
+	muteTiming, err := h.specToMuteTiming(resource)
+	if err != nil {
+		return err
+	}
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+	params := provisioning.NewPutMuteTimingParams().
+		WithName(resource.Name()).
+		WithBody(muteTiming).
+		WithXDisableProvenance(&stringtrue)
+	_, err = client.Provisioning.PutMuteTiming(params)
+	return err
+}
+
+// Delete removes a mute timing from Grafana via the API
+func (h *AlertMuteTimingHandler) Delete(resource grizzly.Resource) error {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Provisioning.DeleteMuteTiming(resource.Name())
+	return err
+}
+
+// getRemoteMuteTiming retrieves a mute timing object from Grafana
+func (h *AlertMuteTimingHandler) getRemoteMuteTiming(name string) (*grizzly.Resource, error) {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+
+	muteTimingOk, err := client.Provisioning.GetMuteTiming(name)
+	if err != nil {
+		var gErr *provisioning.GetMuteTimingNotFound
+		if errors.As(err, &gErr) {
+			return nil, grizzly.ErrNotFound
+		}
+		return nil, err
+	}
+
+	spec, err := structToMap(muteTimingOk.GetPayload())
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := grizzly.NewResource(h.APIVersion(), h.Kind(), name, spec)
+	if err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+func (h *AlertMuteTimingHandler) specToMuteTiming(resource grizzly.Resource) (*models.MuteTimeInterval, error) {
+	data, err := json.Marshal(resource.Spec())
+	if err != nil {
+		return nil, err
+	}
+
+	var muteTiming models.MuteTimeInterval
+	if err := json.Unmarshal(data, &muteTiming); err != nil {
+		return nil, err
+	}
+	return &muteTiming, nil
+}
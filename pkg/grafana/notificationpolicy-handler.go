@@ -15,10 +15,14 @@ const (
 )
 
 var _ grizzly.Handler = &AlertNotificationPolicyHandler{}
+var _ grizzly.LegacyKindsHandler = &AlertNotificationPolicyHandler{}
 
 // AlertNotificationPolicyHandler is a Grizzly Handler for Grafana alertNotificationPolicies
 type AlertNotificationPolicyHandler struct {
 	grizzly.BaseHandler
+
+	// contactPoints caches the contact points looked up when validating links
+	contactPoints contactPoints
 }
 
 // NewAlertNotificationPolicyHandler returns a new Grizzly Handler for Grafana alertNotificationPolicies
@@ -28,10 +32,20 @@ func NewAlertNotificationPolicyHandler(provider grizzly.Provider) *AlertNotifica
 	}
 }
 
+// ErrorHint implements grizzly.ErrorHinter
+func (h *AlertNotificationPolicyHandler) ErrorHint(code grizzly.ErrorCode) string {
+	return permissionsHint(code, "alert.provisioning")
+}
+
 const (
 	alertNotificationPolicyFile = "alertNotificationPolicy.yaml"
 )
 
+// LegacyKinds returns the kind names previously used for notification policy resources
+func (h *AlertNotificationPolicyHandler) LegacyKinds() []string {
+	return []string{"NotificationPolicy"}
+}
+
 // ResourceFilePath returns the location on disk where a resource should be updated
 func (h *AlertNotificationPolicyHandler) ResourceFilePath(resource grizzly.Resource, filetype string) string {
 	return alertNotificationPolicyFile
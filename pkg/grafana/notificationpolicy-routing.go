@@ -0,0 +1,203 @@
+package grafana
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"gopkg.in/yaml.v3"
+)
+
+// PolicyRoute is a route of a notification policy tree an alert is routed to
+type PolicyRoute struct {
+	Receiver string `yaml:"receiver" json:"receiver"`
+	// Path lists the matchers of each route leading to this one, from the
+	// default policy
+	Path              []string `yaml:"path" json:"path"`
+	GroupBy           []string `yaml:"groupBy,omitempty" json:"groupBy,omitempty"`
+	MuteTimeIntervals []string `yaml:"muteTimeIntervals,omitempty" json:"muteTimeIntervals,omitempty"`
+}
+
+// policyNode is a route of a notification policy tree, as declared in the
+// spec of notification policies
+type policyNode struct {
+	Receiver          string            `json:"receiver"`
+	GroupBy           []string          `json:"group_by"`
+	Continue          bool              `json:"continue"`
+	ObjectMatchers    [][]string        `json:"object_matchers"`
+	Matchers          []any             `json:"matchers"`
+	Match             map[string]string `json:"match"`
+	MatchRe           map[string]string `json:"match_re"`
+	MuteTimeIntervals []string          `json:"mute_time_intervals"`
+	Routes            []policyNode      `json:"routes"`
+}
+
+// RouteAlert evaluates a notification policy tree as Alertmanager does, and
+// returns the routes an alert with the given labels would be sent by. Routes
+// are matched depth-first, and the first matching route of each level wins,
+// unless it's set to continue. Receivers and grouping are inherited from
+// parent routes.
+func RouteAlert(policy grizzly.Resource, labels map[string]string) ([]PolicyRoute, error) {
+	data, err := json.Marshal(policy.Spec())
+	if err != nil {
+		return nil, err
+	}
+	var root policyNode
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("invalid notification policy: %w", err)
+	}
+
+	defaultRoute := PolicyRoute{Receiver: root.Receiver, Path: []string{"default policy"}, GroupBy: root.GroupBy, MuteTimeIntervals: root.MuteTimeIntervals}
+	routes, err := routeAlert(root.Routes, defaultRoute, labels)
+	if err != nil {
+		return nil, err
+	}
+	if len(routes) == 0 {
+		return []PolicyRoute{defaultRoute}, nil
+	}
+	return routes, nil
+}
+
+func routeAlert(nodes []policyNode, parent PolicyRoute, labels map[string]string) ([]PolicyRoute, error) {
+	var routes []PolicyRoute
+	for _, node := range nodes {
+		matchers, err := node.matchers()
+		if err != nil {
+			return nil, err
+		}
+		if !matchesLabels(matchers, labels) {
+			continue
+		}
+
+		// routes without matchers match every alert
+		descriptions := []string{"*"}
+		if len(matchers) > 0 {
+			descriptions = descriptions[:0]
+		}
+		for _, matcher := range matchers {
+			descriptions = append(descriptions, matcher.String())
+		}
+		route := PolicyRoute{
+			Receiver:          parent.Receiver,
+			Path:              append(append([]string{}, parent.Path...), strings.Join(descriptions, ", ")),
+			GroupBy:           parent.GroupBy,
+			MuteTimeIntervals: node.MuteTimeIntervals,
+		}
+		if node.Receiver != "" {
+			route.Receiver = node.Receiver
+		}
+		if node.GroupBy != nil {
+			route.GroupBy = node.GroupBy
+		}
+
+		children, err := routeAlert(node.Routes, route, labels)
+		if err != nil {
+			return nil, err
+		}
+		if len(children) == 0 {
+			children = []PolicyRoute{route}
+		}
+		routes = append(routes, children...)
+
+		if !node.Continue {
+			break
+		}
+	}
+	return routes, nil
+}
+
+// matchers returns the matchers of a route, in any of the forms they can be
+// declared in
+func (node policyNode) matchers() ([]grizzly.Matcher, error) {
+	var matchers []grizzly.Matcher
+	for _, objectMatcher := range node.ObjectMatchers {
+		if len(objectMatcher) != 3 {
+			return nil, fmt.Errorf("invalid object matcher %v, expected [<label>, <operator>, <value>]", objectMatcher)
+		}
+		matcher, err := grizzly.ParseMatcher(objectMatcher[0] + objectMatcher[1] + objectMatcher[2])
+		if err != nil {
+			return nil, err
+		}
+		matcher.Value = objectMatcher[2]
+		matchers = append(matchers, matcher)
+	}
+	for _, declared := range node.Matchers {
+		switch m := declared.(type) {
+		case string:
+			matcher, err := grizzly.ParseMatcher(m)
+			if err != nil {
+				return nil, err
+			}
+			matchers = append(matchers, matcher)
+		case map[string]any:
+			name, _ := m["name"].(string)
+			value, _ := m["value"].(string)
+			isRegex, _ := m["isRegex"].(bool)
+			isEqual, _ := m["isEqual"].(bool)
+			matchers = append(matchers, grizzly.Matcher{Name: name, Value: value, IsRegex: isRegex, IsEqual: isEqual})
+		default:
+			return nil, fmt.Errorf("invalid matcher %v", declared)
+		}
+	}
+	for _, name := range sortedLabels(node.Match) {
+		matchers = append(matchers, grizzly.Matcher{Name: name, Value: node.Match[name], IsEqual: true})
+	}
+	for _, name := range sortedLabels(node.MatchRe) {
+		matchers = append(matchers, grizzly.Matcher{Name: name, Value: node.MatchRe[name], IsRegex: true, IsEqual: true})
+	}
+	return matchers, nil
+}
+
+// matchesLabels returns whether labels satisfy every matcher. Missing labels
+// are empty, as in Alertmanager.
+func matchesLabels(matchers []grizzly.Matcher, labels map[string]string) bool {
+	for _, matcher := range matchers {
+		if !matcher.Matches(labels[matcher.Name]) {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedLabels(labels map[string]string) []string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FormatPolicyRoutes renders the routes of an alert as a table, or as JSON or YAML
+func FormatPolicyRoutes(routes []PolicyRoute, format string) ([]byte, error) {
+	switch format {
+	case "yaml":
+		return yaml.Marshal(routes)
+	case "json":
+		return json.MarshalIndent(routes, "", "  ")
+	case "default":
+		var out bytes.Buffer
+		w := tabwriter.NewWriter(&out, 0, 0, 4, ' ', 0)
+
+		f := "%s\t%s\t%s\t%s\n"
+		fmt.Fprintf(w, f, "CONTACT POINT", "ROUTE", "GROUP BY", "MUTE TIMINGS")
+		for _, route := range routes {
+			fmt.Fprintf(w, f, route.Receiver, strings.Join(route.Path, " > "), listOrDash(route.GroupBy), listOrDash(route.MuteTimeIntervals))
+		}
+		err := w.Flush()
+		return out.Bytes(), err
+	default:
+		return nil, fmt.Errorf("unknown format %s", format)
+	}
+}
+
+func listOrDash(values []string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	return strings.Join(values, ",")
+}
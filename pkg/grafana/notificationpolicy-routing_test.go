@@ -0,0 +1,76 @@
+package grafana
+
+import (
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouteAlert(t *testing.T) {
+	policy, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", AlertNotificationPolicyKind, GlobalAlertNotificationPolicyName, map[string]any{
+		"receiver": "default",
+		"group_by": []any{"alertname"},
+		"routes": []any{
+			map[string]any{
+				"receiver":        "payments",
+				"object_matchers": []any{[]any{"team", "=", "payments"}},
+				"continue":        true,
+				"routes": []any{
+					map[string]any{
+						"receiver":            "payments-pager",
+						"object_matchers":     []any{[]any{"severity", "=~", "critical|page"}},
+						"mute_time_intervals": []any{"weekends"},
+					},
+				},
+			},
+			map[string]any{
+				"matchers": []any{"env!=dev"},
+				"group_by": []any{"cluster"},
+			},
+			map[string]any{
+				"receiver": "catch-all",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("continue routes to later siblings", func(t *testing.T) {
+		routes, err := RouteAlert(policy, map[string]string{"team": "payments", "severity": "critical"})
+		require.NoError(t, err)
+		require.Equal(t, []PolicyRoute{
+			{Receiver: "payments-pager", Path: []string{"default policy", `team="payments"`, `severity=~"critical|page"`}, GroupBy: []string{"alertname"}, MuteTimeIntervals: []string{"weekends"}},
+			{Receiver: "default", Path: []string{"default policy", `env!="dev"`}, GroupBy: []string{"cluster"}},
+		}, routes)
+	})
+
+	t.Run("parent route when no child matches", func(t *testing.T) {
+		routes, err := RouteAlert(policy, map[string]string{"team": "payments", "severity": "warning", "env": "dev"})
+		require.NoError(t, err)
+		require.Equal(t, []PolicyRoute{
+			{Receiver: "payments", Path: []string{"default policy", `team="payments"`}, GroupBy: []string{"alertname"}},
+			{Receiver: "catch-all", Path: []string{"default policy", "*"}, GroupBy: []string{"alertname"}},
+		}, routes)
+	})
+
+	t.Run("first matching route wins", func(t *testing.T) {
+		routes, err := RouteAlert(policy, map[string]string{"env": "prod"})
+		require.NoError(t, err)
+		require.Len(t, routes, 1)
+		require.Equal(t, "default", routes[0].Receiver)
+		require.Equal(t, []string{"cluster"}, routes[0].GroupBy)
+	})
+
+	t.Run("default policy when nothing matches", func(t *testing.T) {
+		empty, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", AlertNotificationPolicyKind, GlobalAlertNotificationPolicyName, map[string]any{
+			"receiver": "default",
+			"routes": []any{
+				map[string]any{"receiver": "team-a", "match": map[string]any{"team": "a"}},
+			},
+		})
+		require.NoError(t, err)
+		routes, err := RouteAlert(empty, map[string]string{"team": "b"})
+		require.NoError(t, err)
+		require.Equal(t, []PolicyRoute{{Receiver: "default", Path: []string{"default policy"}}}, routes)
+	})
+}
@@ -0,0 +1,180 @@
+package grafana
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/grafana/grafana-openapi-client-go/client/provisioning"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+const AlertNotificationTemplateKind = "AlertNotificationTemplate"
+
+var _ grizzly.Handler = &AlertNotificationTemplateHandler{}
+
+// AlertNotificationTemplateHandler is a Grizzly Handler for Grafana
+// notification templates, the Go templates contact points format their
+// notifications with
+type AlertNotificationTemplateHandler struct {
+	grizzly.BaseHandler
+}
+
+// NewAlertNotificationTemplateHandler returns a new Grizzly Handler for Grafana notification templates
+func NewAlertNotificationTemplateHandler(provider grizzly.Provider) *AlertNotificationTemplateHandler {
+	return &AlertNotificationTemplateHandler{
+		BaseHandler: grizzly.NewBaseHandler(provider, AlertNotificationTemplateKind, false),
+	}
+}
+
+// ErrorHint implements grizzly.ErrorHinter
+func (h *AlertNotificationTemplateHandler) ErrorHint(code grizzly.ErrorCode) string {
+	return permissionsHint(code, "alert.provisioning")
+}
+
+const (
+	notificationTemplatePattern = "alert-notification-templates/template-%s.%s"
+)
+
+// ResourceFilePath returns the location on disk where a resource should be updated
+func (h *AlertNotificationTemplateHandler) ResourceFilePath(resource grizzly.Resource, filetype string) string {
+	filename := strings.ReplaceAll(resource.Name(), string(os.PathSeparator), "-")
+	filename = strings.ReplaceAll(filename, " ", "-")
+	return fmt.Sprintf(notificationTemplatePattern, filename, filetype)
+}
+
+// Unprepare removes unnecessary elements from a remote resource ready for presentation/comparison
+func (h *AlertNotificationTemplateHandler) Unprepare(resource grizzly.Resource) *grizzly.Resource {
+	resource.DeleteSpecKey("provenance")
+	return &resource
+}
+
+// Prepare gets a resource ready for dispatch to the remote endpoint
+func (h *AlertNotificationTemplateHandler) Prepare(existing *grizzly.Resource, resource grizzly.Resource) *grizzly.Resource {
+	if !resource.HasSpecString("name") {
+		resource.SetSpecString("name", resource.Name())
+	}
+	return &resource
+}
+
+// Validate checks that the name in the spec matches the name of the resource,
+// and that there's a template
+func (h *AlertNotificationTemplateHandler) Validate(resource grizzly.Resource) error {
+	name, exist := resource.GetSpecString("name")
+	if exist && name != resource.Name() {
+		return fmt.Errorf("name '%s' and name '%s', don't match", name, resource.Name())
+	}
+	if template, _ := resource.GetSpecString("template"); strings.TrimSpace(template) == "" {
+		return fmt.Errorf("template %s is empty", resource.Name())
+	}
+	return nil
+}
+
+func (h *AlertNotificationTemplateHandler) GetSpecUID(resource grizzly.Resource) (string, error) {
+	name, ok := resource.GetSpecString("name")
+	if !ok {
+		return "", fmt.Errorf("name not specified")
+	}
+	return name, nil
+}
+
+// GetByUID retrieves JSON for a resource from an endpoint, by UID
+func (h *AlertNotificationTemplateHandler) GetByUID(uid string) (*grizzly.Resource, error) {
+	return h.getRemoteTemplate(uid)
+}
+
+// GetRemote retrieves a notification template as a Resource
+func (h *AlertNotificationTemplateHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
+	return h.getRemoteTemplate(resource.Name())
+}
+
+// ListRemote retrieves the names of all remote notification templates
+func (h *AlertNotificationTemplateHandler) ListRemote() ([]string, error) {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+
+	templatesOk, err := client.Provisioning.GetTemplates()
+	if err != nil {
+		// some versions of Grafana respond with a 404 when there are no templates
+		var gErr *provisioning.GetTemplatesNotFound
+		if errors.As(err, &gErr) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	templates := templatesOk.GetPayload()
+	names := make([]string, len(templates))
+	for i, template := range templates {
+		names[i] = template.Name
+	}
+	return names, nil
+}
+
+// Add pushes a notification template to Grafana via the API
+func (h *AlertNotificationTemplateHandler) Add(resource grizzly.Resource) error {
+	return h.putTemplate(resource)
+}
+
+// Update pushes a notification template to Grafana via the API
+func (h *AlertNotificationTemplateHandler) Update(existing, resource grizzly.Resource) error {
+	return h.putTemplate(resource)
+}
+
+// Delete removes a notification template from Grafana via the API
+func (h *AlertNotificationTemplateHandler) Delete(resource grizzly.Resource) error {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Provisioning.DeleteTemplate(resource.Name())
+	return err
+}
+
+// putTemplate creates or replaces a notification template, which are both
+// done by the same endpoint
+func (h *AlertNotificationTemplateHandler) putTemplate(resource grizzly.Resource) error {
+	template, _ := resource.GetSpecString("template")
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+	params := provisioning.NewPutTemplateParams().
+		WithName(resource.Name()).
+		WithBody(&models.NotificationTemplateContent{Template: template}).
+		WithXDisableProvenance(&stringtrue)
+	_, err = client.Provisioning.PutTemplate(params)
+	return err
+}
+
+// getRemoteTemplate retrieves a notification template object from Grafana
+func (h *AlertNotificationTemplateHandler) getRemoteTemplate(name string) (*grizzly.Resource, error) {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+
+	templateOk, err := client.Provisioning.GetTemplate(name)
+	if err != nil {
+		var gErr *provisioning.GetTemplateNotFound
+		if errors.As(err, &gErr) {
+			return nil, grizzly.ErrNotFound
+		}
+		return nil, err
+	}
+
+	spec, err := structToMap(templateOk.GetPayload())
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := grizzly.NewResource(h.APIVersion(), h.Kind(), name, spec)
+	if err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
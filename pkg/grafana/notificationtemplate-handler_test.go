@@ -0,0 +1,81 @@
+package grafana
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertNotificationTemplateHandlerConformance(t *testing.T) {
+	templates := map[string]string{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/provisioning/templates", func(w http.ResponseWriter, r *http.Request) {
+		if len(templates) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		list := []map[string]any{}
+		for name, template := range templates {
+			list = append(list, map[string]any{"name": name, "template": template, "provenance": "api"})
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	})
+	mux.HandleFunc("GET /api/v1/provisioning/templates/{name}", func(w http.ResponseWriter, r *http.Request) {
+		template, ok := templates[r.PathValue("name")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"name": r.PathValue("name"), "template": template, "provenance": "api"})
+	})
+	mux.HandleFunc("PUT /api/v1/provisioning/templates/{name}", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Template string `json:"template"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		templates[r.PathValue("name")] = body.Template
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(map[string]any{"name": r.PathValue("name"), "template": body.Template})
+	})
+	mux.HandleFunc("DELETE /api/v1/provisioning/templates/{name}", func(w http.ResponseWriter, r *http.Request) {
+		delete(templates, r.PathValue("name"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL})
+	handler := NewAlertNotificationTemplateHandler(provider)
+
+	uids, err := handler.ListRemote()
+	require.NoError(t, err)
+	require.Empty(t, uids, "no templates aren't an error")
+
+	template, err := grizzly.NewResource(provider.APIVersion(), AlertNotificationTemplateKind, "slack.title", map[string]any{
+		"name":     "slack.title",
+		"template": `{{ define "slack.title" }}{{ .CommonLabels.alertname }}{{ end }}`,
+	})
+	require.NoError(t, err)
+
+	testutil.RunHandlerConformance(t, handler, testutil.HandlerFixture{
+		Resource: template,
+		Modify: func(resource grizzly.Resource) grizzly.Resource {
+			resource.SetSpecString("template", `{{ define "slack.title" }}[{{ .Status }}] {{ .CommonLabels.alertname }}{{ end }}`)
+			return resource
+		},
+	})
+
+	t.Run("empty templates are invalid", func(t *testing.T) {
+		template.SetSpecString("template", " ")
+		require.ErrorContains(t, handler.Validate(template), "is empty")
+	})
+}
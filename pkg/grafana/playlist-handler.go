@@ -0,0 +1,336 @@
+package grafana
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/grafana/grafana-openapi-client-go/client/playlists"
+	"github.com/grafana/grafana-openapi-client-go/client/search"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	log "github.com/sirupsen/logrus"
+)
+
+const PlaylistKind = "Playlist"
+
+const (
+	playlistItemByUID = "dashboard_by_uid"
+	playlistItemByTag = "dashboard_by_tag"
+	playlistItemByID  = "dashboard_by_id"
+	// playlistItemByQuery is only known to Grizzly: it's expanded into the
+	// dashboards matching a folder or tags when playlists are applied
+	playlistItemByQuery = "dashboard_by_query"
+)
+
+var _ grizzly.Handler = &PlaylistHandler{}
+var _ grizzly.ResourceExpander = &PlaylistHandler{}
+
+// PlaylistHandler is a Grizzly Handler for Grafana playlists, whose items can
+// be queries matching the dashboards of a folder, or with given tags
+type PlaylistHandler struct {
+	grizzly.BaseHandler
+}
+
+// NewPlaylistHandler returns a new Grizzly Handler for Grafana playlists
+func NewPlaylistHandler(provider grizzly.Provider) *PlaylistHandler {
+	return &PlaylistHandler{
+		BaseHandler: grizzly.NewBaseHandler(provider, PlaylistKind, false),
+	}
+}
+
+// ErrorHint implements grizzly.ErrorHinter
+func (h *PlaylistHandler) ErrorHint(code grizzly.ErrorCode) string {
+	return permissionsHint(code, "playlists")
+}
+
+const (
+	playlistPattern = "playlists/playlist-%s.%s"
+)
+
+// playlist is a Grafana playlist, as returned by its API
+type playlist struct {
+	UID      string         `json:"uid"`
+	Name     string         `json:"name"`
+	Interval string         `json:"interval"`
+	Items    []playlistItem `json:"items"`
+}
+
+// playlistItem is an item of a playlist. Folder, Tags and Dynamic are only
+// set for queries.
+type playlistItem struct {
+	Type    string   `json:"type"`
+	Value   string   `json:"value,omitempty"`
+	Folder  string   `json:"folder,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	Dynamic bool     `json:"dynamic,omitempty"`
+}
+
+// ResourceFilePath returns the location on disk where a resource should be updated
+func (h *PlaylistHandler) ResourceFilePath(resource grizzly.Resource, filetype string) string {
+	filename := strings.ReplaceAll(resource.Name(), string(os.PathSeparator), "-")
+	filename = strings.ReplaceAll(filename, " ", "-")
+	return fmt.Sprintf(playlistPattern, filename, filetype)
+}
+
+// Unprepare removes unnecessary elements from a remote resource ready for presentation/comparison
+func (h *PlaylistHandler) Unprepare(resource grizzly.Resource) *grizzly.Resource {
+	resource.DeleteSpecKey("id")
+	return &resource
+}
+
+// Prepare gets a resource ready for dispatch to the remote endpoint
+func (h *PlaylistHandler) Prepare(existing *grizzly.Resource, resource grizzly.Resource) *grizzly.Resource {
+	if !resource.HasSpecString("uid") {
+		resource.SetSpecString("uid", resource.Name())
+	}
+	if !resource.HasSpecString("name") {
+		resource.SetSpecString("name", resource.Name())
+	}
+	if !resource.HasSpecString("interval") {
+		resource.SetSpecString("interval", "5m")
+	}
+	return &resource
+}
+
+// Validate checks that the uid in the spec matches the name of the resource,
+// and that the queries of the playlist can be expanded
+func (h *PlaylistHandler) Validate(resource grizzly.Resource) error {
+	uid, exist := resource.GetSpecString("uid")
+	if exist && uid != resource.Name() {
+		return fmt.Errorf("uid '%s' and name '%s', don't match", uid, resource.Name())
+	}
+
+	spec, err := specToPlaylist(resource)
+	if err != nil {
+		return err
+	}
+	for i, item := range spec.Items {
+		switch item.Type {
+		case playlistItemByUID, playlistItemByTag, playlistItemByID:
+			if item.Value == "" {
+				return fmt.Errorf("item %d of %s should have a value", i+1, resource.Ref())
+			}
+		case playlistItemByQuery:
+			if item.Folder == "" && len(item.Tags) == 0 {
+				return fmt.Errorf("the query of item %d of %s should match a folder, tags, or both", i+1, resource.Ref())
+			}
+			if item.Dynamic && (item.Folder != "" || len(item.Tags) != 1) {
+				return fmt.Errorf("the query of item %d of %s can only be dynamic with a single tag and no folder, as Grafana only keeps playlists current by tag", i+1, resource.Ref())
+			}
+		default:
+			return fmt.Errorf("item %d of %s has an unknown type '%s', should be one of %s, %s or %s", i+1, resource.Ref(), item.Type, playlistItemByUID, playlistItemByTag, playlistItemByQuery)
+		}
+	}
+	return nil
+}
+
+func (h *PlaylistHandler) GetSpecUID(resource grizzly.Resource) (string, error) {
+	uid, ok := resource.GetSpecString("uid")
+	if !ok {
+		return "", fmt.Errorf("UID not specified")
+	}
+	return uid, nil
+}
+
+// GetByUID retrieves JSON for a resource from an endpoint, by UID
+func (h *PlaylistHandler) GetByUID(uid string) (*grizzly.Resource, error) {
+	return h.getRemotePlaylist(uid)
+}
+
+// GetRemote retrieves a playlist as a Resource
+func (h *PlaylistHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
+	return h.getRemotePlaylist(resource.Name())
+}
+
+// ListRemote retrieves the UIDs of all remote playlists
+func (h *PlaylistHandler) ListRemote() ([]string, error) {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+
+	limit := int64(10000)
+	searchOk, err := client.Playlists.SearchPlaylists(playlists.NewSearchPlaylistsParams().WithLimit(&limit))
+	if err != nil {
+		return nil, err
+	}
+	uids := make([]string, 0, len(searchOk.GetPayload()))
+	for _, playlist := range searchOk.GetPayload() {
+		uids = append(uids, playlist.UID)
+	}
+	return uids, nil
+}
+
+// Add pushes a playlist to Grafana via the API
+func (h *PlaylistHandler) Add(resource grizzly.Resource) error {
+	spec, err := specToPlaylist(resource)
+	if err != nil {
+		return err
+	}
+	return h.request(http.MethodPost, "/api/playlists", spec, nil)
+}
+
+// Update pushes a playlist to Grafana via the API
+func (h *PlaylistHandler) Update(existing, resource grizzly.Resource) error {
+	spec, err := specToPlaylist(resource)
+	if err != nil {
+		return err
+	}
+	return h.request(http.MethodPut, "/api/playlists/"+url.PathEscape(resource.Name()), spec, nil)
+}
+
+// Delete removes a playlist from Grafana via the API
+func (h *PlaylistHandler) Delete(resource grizzly.Resource) error {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Playlists.DeletePlaylist(resource.Name())
+	return err
+}
+
+// Expand replaces the queries of a playlist with the dashboards they
+// currently match, in the order of their titles. Dashboards already in the
+// playlist aren't added again. Dynamic queries become items Grafana keeps
+// current, matching dashboards by tag.
+func (h *PlaylistHandler) Expand(resource grizzly.Resource) (grizzly.Resource, error) {
+	if err := h.Validate(resource); err != nil {
+		return resource, err
+	}
+	spec, err := specToPlaylist(resource)
+	if err != nil || !slices.ContainsFunc(spec.Items, isPlaylistQuery) {
+		return resource, err
+	}
+
+	listed := map[string]bool{}
+	for _, item := range spec.Items {
+		if item.Type == playlistItemByUID {
+			listed[item.Value] = true
+		}
+	}
+
+	items := []any{}
+	for _, item := range spec.Items {
+		switch {
+		case item.Type == playlistItemByQuery && item.Dynamic:
+			items = append(items, map[string]any{"type": playlistItemByTag, "value": item.Tags[0]})
+		case item.Type == playlistItemByQuery:
+			uids, err := h.searchDashboards(item.Folder, item.Tags)
+			if err != nil {
+				return resource, err
+			}
+			if len(uids) == 0 {
+				log.Warnf("The query of %s for the dashboards of folder '%s' with tags %v matches no dashboards", resource.Ref(), item.Folder, item.Tags)
+			}
+			for _, uid := range uids {
+				if !listed[uid] {
+					listed[uid] = true
+					items = append(items, map[string]any{"type": playlistItemByUID, "value": uid})
+				}
+			}
+		default:
+			items = append(items, map[string]any{"type": item.Type, "value": item.Value})
+		}
+	}
+
+	expanded := resource.DeepCopy()
+	expanded.SetSpecValue("items", items)
+	return expanded, nil
+}
+
+// searchDashboards returns the UIDs of the dashboards of a folder, with all
+// of the given tags
+func (h *PlaylistHandler) searchDashboards(folder string, tags []string) ([]string, error) {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		searchType       = "dash-db"
+		limit            = int64(1000)
+		page       int64 = 0
+		uids       []string
+	)
+	params := search.NewSearchParams().WithType(&searchType).WithLimit(&limit).WithTag(tags)
+	if folder != "" {
+		params.SetFolderUIDs([]string{folder})
+	}
+	for {
+		page++
+		params.SetPage(&page)
+
+		searchOk, err := client.Search.Search(params, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range searchOk.GetPayload() {
+			uids = append(uids, hit.UID)
+		}
+		if int64(len(searchOk.GetPayload())) < limit {
+			return uids, nil
+		}
+	}
+}
+
+func (h *PlaylistHandler) getRemotePlaylist(uid string) (*grizzly.Resource, error) {
+	var remote playlist
+	err := h.request(http.MethodGet, "/api/playlists/"+url.PathEscape(uid), nil, &remote)
+	var apiErr appPlatformError
+	if errors.As(err, &apiErr) && apiErr.IsCode(http.StatusNotFound) {
+		return nil, grizzly.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]any, 0, len(remote.Items))
+	for _, item := range remote.Items {
+		items = append(items, map[string]any{"type": item.Type, "value": item.Value})
+	}
+	spec := map[string]any{
+		"uid":      remote.UID,
+		"name":     remote.Name,
+		"interval": remote.Interval,
+		"items":    items,
+	}
+
+	resource, err := grizzly.NewResource(h.APIVersion(), h.Kind(), uid, spec)
+	if err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+// request calls the playlists API directly, as the generated client drops
+// the uid and items of playlists
+func (h *PlaylistHandler) request(method, path string, body, result any) error {
+	provider, ok := h.Provider.(*Provider)
+	if !ok {
+		return fmt.Errorf("playlists can only be managed by the Grafana provider")
+	}
+	return provider.appPlatformRequest(method, path, nil, body, result)
+}
+
+func specToPlaylist(resource grizzly.Resource) (playlist, error) {
+	data, err := json.Marshal(resource.Spec())
+	if err != nil {
+		return playlist{}, err
+	}
+
+	var spec playlist
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return playlist{}, fmt.Errorf("the spec of %s isn't a valid playlist: %w", resource.Ref(), err)
+	}
+	return spec, nil
+}
+
+func isPlaylistQuery(item playlistItem) bool {
+	return item.Type == playlistItemByQuery
+}
@@ -0,0 +1,140 @@
+package grafana
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaylistHandler(t *testing.T) {
+	type dashboard struct {
+		UID    string   `json:"uid"`
+		Folder string   `json:"folderUid"`
+		Tags   []string `json:"tags"`
+	}
+	var mu sync.Mutex
+	dashboards := []dashboard{
+		{UID: "api", Folder: "noc", Tags: []string{"prod"}},
+		{UID: "db", Folder: "noc", Tags: []string{"prod", "storage"}},
+		{UID: "staging", Folder: "noc", Tags: []string{"staging"}},
+		{UID: "home", Folder: "general", Tags: []string{"prod"}},
+	}
+	playlists := map[string]playlist{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/search", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		hits := []dashboard{}
+		for _, dashboard := range dashboards {
+			folders := r.URL.Query()["folderUIDs"]
+			if len(folders) > 0 && !slices.Contains(folders, dashboard.Folder) {
+				continue
+			}
+			if slices.ContainsFunc(r.URL.Query()["tag"], func(tag string) bool { return !slices.Contains(dashboard.Tags, tag) }) {
+				continue
+			}
+			hits = append(hits, dashboard)
+		}
+		_ = json.NewEncoder(w).Encode(hits)
+	})
+	mux.HandleFunc("GET /api/playlists/{uid}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		remote, ok := playlists[r.PathValue("uid")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(remote)
+	})
+	save := func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var remote playlist
+		_ = json.NewDecoder(r.Body).Decode(&remote)
+		playlists[remote.UID] = remote
+		_ = json.NewEncoder(w).Encode(remote)
+	}
+	mux.HandleFunc("POST /api/playlists", save)
+	mux.HandleFunc("PUT /api/playlists/{uid}", save)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mux.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL})
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+	handler := NewPlaylistHandler(provider)
+
+	noc, err := grizzly.NewResource(provider.APIVersion(), PlaylistKind, "noc", map[string]any{
+		"uid":      "noc",
+		"name":     "NOC",
+		"interval": "1m",
+		"items": []any{
+			map[string]any{"type": "dashboard_by_uid", "value": "home"},
+			map[string]any{"type": "dashboard_by_query", "folder": "noc", "tags": []any{"prod"}},
+			map[string]any{"type": "dashboard_by_query", "tags": []any{"staging"}, "dynamic": true},
+		},
+	})
+	require.NoError(t, err)
+
+	apply := func() grizzly.Summary {
+		var out strings.Builder
+		recorder := grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText)
+		require.NoError(t, grizzly.Apply(registry, grizzly.NewResources(noc), grizzly.ApplyOptions{}, recorder), out.String())
+		return recorder.Summary()
+	}
+	items := func() []playlistItem {
+		mu.Lock()
+		defer mu.Unlock()
+		return playlists["noc"].Items
+	}
+
+	t.Run("queries are expanded into the dashboards they match", func(t *testing.T) {
+		require.Equal(t, 1, apply().EventCounts[grizzly.ResourceAdded])
+		require.Equal(t, []playlistItem{
+			{Type: "dashboard_by_uid", Value: "home"},
+			{Type: "dashboard_by_uid", Value: "api"},
+			{Type: "dashboard_by_uid", Value: "db"},
+			{Type: "dashboard_by_tag", Value: "staging"},
+		}, items())
+	})
+
+	t.Run("expanded playlists are unchanged while dashboards are", func(t *testing.T) {
+		diffs, err := grizzly.DiffReport(registry, grizzly.NewResources(noc), grizzly.DiffReportOptions{OutputFormat: "yaml"})
+		require.NoError(t, err)
+		require.Equal(t, grizzly.DiffUnchanged, diffs[0].Change, diffs[0].Unified)
+		require.Equal(t, 1, apply().EventCounts[grizzly.ResourceNotChanged])
+	})
+
+	t.Run("dashboards added to a folder are added when applying again", func(t *testing.T) {
+		mu.Lock()
+		dashboards = append(dashboards, dashboard{UID: "web", Folder: "noc", Tags: []string{"prod"}})
+		mu.Unlock()
+
+		require.Equal(t, 1, apply().EventCounts[grizzly.ResourceUpdated])
+		require.Contains(t, items(), playlistItem{Type: "dashboard_by_uid", Value: "web"})
+	})
+
+	t.Run("queries are checked", func(t *testing.T) {
+		for item, expected := range map[string]map[string]any{
+			"should match a folder, tags, or both":      {"type": "dashboard_by_query"},
+			"can only be dynamic with a single tag":     {"type": "dashboard_by_query", "folder": "noc", "tags": []any{"prod"}, "dynamic": true},
+			"has an unknown type 'dashboard_by_folder'": {"type": "dashboard_by_folder", "value": "noc"},
+		} {
+			broken := noc.DeepCopy()
+			broken.SetSpecValue("items", []any{expected})
+			require.ErrorContains(t, handler.Validate(broken), item)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package grafana
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreflight(t *testing.T) {
+	jwt := func(expiry time.Time) string {
+		payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"sub": "grizzly", "exp": %d}`, expiry.Unix())))
+		return "eyJhbGciOiJIUzI1NiJ9." + payload + ".signature"
+	}
+	newServer := func(t *testing.T, status int) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"dashboard": {}}`))
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+	preflight := func(t *testing.T, server *httptest.Server, token string) error {
+		registry := grizzly.NewRegistry([]grizzly.Provider{NewProvider(&config.GrafanaConfig{URL: server.URL, Token: token})})
+		dashboard, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", DashboardKind, "sample", map[string]any{"uid": "sample"})
+		require.NoError(t, err)
+		return grizzly.Preflight(registry, grizzly.NewResources(dashboard))
+	}
+
+	t.Run("valid credentials are accepted", func(t *testing.T) {
+		server := newServer(t, http.StatusOK)
+
+		require.NoError(t, preflight(t, server, "glsa_opaque"))
+		require.NoError(t, preflight(t, server, jwt(time.Now().Add(time.Hour))))
+	})
+
+	t.Run("rejected credentials are reported", func(t *testing.T) {
+		server := newServer(t, http.StatusUnauthorized)
+
+		require.ErrorContains(t, preflight(t, server, "glsa_revoked"), "pre-flight check of Grafana failed")
+	})
+
+	t.Run("expired credentials are reported", func(t *testing.T) {
+		server := newServer(t, http.StatusOK)
+
+		require.ErrorContains(t, preflight(t, server, jwt(time.Now().Add(-time.Minute))), "credentials of Grafana expired at")
+	})
+
+	t.Run("providers without resources to apply aren't checked", func(t *testing.T) {
+		registry := grizzly.NewRegistry([]grizzly.Provider{NewProvider(&config.GrafanaConfig{})})
+
+		require.NoError(t, grizzly.Preflight(registry, grizzly.NewResources()))
+	})
+}
+
+func TestTokenExpiry(t *testing.T) {
+	expiry, ok := grizzly.TokenExpiry("eyJhbGciOiJIUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(`{"exp": 1700000000}`)) + ".signature")
+	require.True(t, ok)
+	require.Equal(t, time.Unix(1700000000, 0), expiry)
+
+	_, ok = grizzly.TokenExpiry("glsa_opaque_token")
+	require.False(t, ok)
+}
+
+func TestCheckQuotas(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/org/quotas", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"target": "dashboard", "limit": 3, "used": 2}, {"target": "alert_rule", "limit": -1, "used": 40}]`))
+	})
+	mux.HandleFunc("GET /api/search", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "1" {
+			_, _ = w.Write([]byte(`[{"uid": "existing", "type": "dash-db"}]`))
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	registry := grizzly.NewRegistry([]grizzly.Provider{NewProvider(&config.GrafanaConfig{URL: server.URL})})
+
+	dashboards := func(uids ...string) grizzly.Resources {
+		resources := grizzly.NewResources()
+		for _, uid := range uids {
+			dashboard, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", DashboardKind, uid, map[string]any{"uid": uid})
+			require.NoError(t, err)
+			resources.Add(dashboard)
+		}
+		return resources
+	}
+
+	require.NoError(t, grizzly.CheckQuotas(registry, dashboards("existing", "new")), "existing resources don't count against the quota")
+
+	err := grizzly.CheckQuotas(registry, dashboards("existing", "new", "other"))
+	require.ErrorContains(t, err, "adding Dashboard needs 2 dashboards, but only 1 of the 3 allowed remain")
+	require.Equal(t, grizzly.ErrorCodeQuotaExceeded, grizzly.ErrorCodeOf(err))
+
+	t.Run("unlimited quotas aren't checked", func(t *testing.T) {
+		group, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", AlertRuleGroupKind, "folder.group", map[string]any{"rules": []any{map[string]any{}}})
+		require.NoError(t, err)
+
+		require.NoError(t, grizzly.CheckQuotas(registry, grizzly.NewResources(group)))
+	})
+}
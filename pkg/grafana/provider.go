@@ -5,9 +5,13 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	gclient "github.com/grafana/grafana-openapi-client-go/client"
 	"github.com/grafana/grafana-openapi-client-go/client/dashboards"
@@ -18,8 +22,13 @@ import (
 
 // Provider is a grizzly.Provider implementation for Grafana.
 type Provider struct {
-	config *config.GrafanaConfig
-	client *gclient.GrafanaHTTPAPI
+	config    *config.GrafanaConfig
+	transport http.RoundTripper
+	client    *gclient.GrafanaHTTPAPI
+	clientMu  sync.Mutex
+
+	appPlatform   *appPlatformDiscovery
+	appPlatformMu sync.Mutex
 }
 
 type ClientProvider interface {
@@ -34,6 +43,17 @@ func NewProvider(config *config.GrafanaConfig) *Provider {
 	}
 }
 
+// WithTransport sends the requests of the provider through a transport of
+// its own, such as one signing requests or recording them. The transport is
+// in charge of TLS, so the insecure-skip-verify setting doesn't apply to it.
+func (p *Provider) WithTransport(transport http.RoundTripper) *Provider {
+	p.clientMu.Lock()
+	defer p.clientMu.Unlock()
+	p.transport = transport
+	p.client = nil
+	return p
+}
+
 func (p *Provider) Validate() error {
 	if p.config.URL == "" {
 		return fmt.Errorf("grafana URL is not set")
@@ -67,6 +87,11 @@ func (p *Provider) Status() grizzly.ProviderStatus {
 	return status
 }
 
+// CredentialsExpiry implements grizzly.CredentialsExpiryProvider, for tokens that are JWTs
+func (p *Provider) CredentialsExpiry() (time.Time, bool) {
+	return grizzly.TokenExpiry(p.config.Token)
+}
+
 func (p *Provider) Name() string {
 	return "Grafana"
 }
@@ -82,6 +107,9 @@ func (p *Provider) Version() string {
 }
 
 func (p *Provider) Client() (*gclient.GrafanaHTTPAPI, error) {
+	// resources can be applied concurrently
+	p.clientMu.Lock()
+	defer p.clientMu.Unlock()
 	if p.client != nil {
 		return p.client, nil
 	}
@@ -96,13 +124,13 @@ func (p *Provider) Client() (*gclient.GrafanaHTTPAPI, error) {
 		WithSchemes([]string{parsedURL.Scheme}).
 		WithBasePath(filepath.Join(parsedURL.Path, "api"))
 
-	httpClient, err := httputils.NewHTTPClient()
+	httpClient, err := p.newHTTPClient()
 	if err != nil {
 		return nil, err
 	}
 	transportConfig.Client = httpClient
 
-	if parsedURL.Scheme == "https" && p.config.InsecureSkipVerify {
+	if parsedURL.Scheme == "https" && p.config.InsecureSkipVerify && p.transport == nil {
 		transportConfig.TLSConfig = &tls.Config{
 			InsecureSkipVerify: true,
 			ServerName:         p.config.TLSHost,
@@ -121,6 +149,33 @@ func (p *Provider) Client() (*gclient.GrafanaHTTPAPI, error) {
 	return grafanaClient, nil
 }
 
+// newHTTPClient returns a client sending requests through the transport of
+// the provider, which skips the verification of certificates when asked to
+func (p *Provider) newHTTPClient() (*http.Client, error) {
+	if strings.HasPrefix(p.config.URL, "https") && p.config.InsecureSkipVerify && p.transport == nil {
+		transport := httputils.NewTransport()
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.InsecureSkipVerify = true
+		transport.TLSClientConfig.ServerName = p.config.TLSHost
+		return httputils.NewHTTPClientWithTransport(transport)
+	}
+	return httputils.NewHTTPClientWithTransport(p.transport)
+}
+
+// authorizationHeader returns the value of the Authorization header to send with requests to Grafana
+func (p *Provider) authorizationHeader() string {
+	if p.config.User != "" {
+		header := fmt.Sprintf("%s:%s", p.config.User, p.config.Token)
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(header))
+	}
+	if p.config.Token != "" {
+		return "Bearer " + p.config.Token
+	}
+	return ""
+}
+
 func (p *Provider) Config() *config.GrafanaConfig {
 	return p.config
 }
@@ -132,14 +187,24 @@ func (p *Provider) APIVersion() string {
 
 // GetHandlers identifies the handlers for the Grafana provider
 func (p *Provider) GetHandlers() []grizzly.Handler {
+	// resources are applied in this order, so that the resources others
+	// refer to exist first: folders, data sources, library panels, then
+	// the dashboards using them
 	return []grizzly.Handler{
-		NewDatasourceHandler(p),
 		NewFolderHandler(p),
+		NewFolderTreeHandler(p),
+		NewDatasourceHandler(p),
 		NewLibraryElementHandler(p),
 		NewDashboardHandler(p),
+		NewTeamPreferencesHandler(p),
+		NewPlaylistHandler(p),
 		NewAlertRuleGroupHandler(p),
-		NewAlertNotificationPolicyHandler(p),
+		// templates are referenced by contact points, and contact points and
+		// mute timings by the notification policy
+		NewAlertNotificationTemplateHandler(p),
 		NewAlertContactPointHandler(p),
+		NewAlertMuteTimingHandler(p),
+		NewAlertNotificationPolicyHandler(p),
 	}
 }
 
@@ -164,13 +229,7 @@ func (p *Provider) SetupProxy() (*httputil.ReverseProxy, error) {
 		Rewrite: func(r *httputil.ProxyRequest) {
 			r.SetURL(u)
 
-			if p.config.User != "" {
-				header := fmt.Sprintf("%s:%s", p.config.User, p.config.Token)
-				encoded := base64.StdEncoding.EncodeToString([]byte(header))
-				r.Out.Header.Set("Authorization", "Basic "+encoded)
-			} else {
-				r.Out.Header.Set("Authorization", "Bearer "+p.config.Token)
-			}
+			r.Out.Header.Set("Authorization", p.authorizationHeader())
 
 			r.Out.Header.Del("Origin")
 			r.Out.Header.Set("User-Agent", "Grizzly Proxy Server")
@@ -0,0 +1,58 @@
+package grafana
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+// signingTransport adds a header to requests, like request signing
+// middlewares do
+type signingTransport struct {
+	signed int
+}
+
+func (t *signingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.signed++
+	req = req.Clone(req.Context())
+	req.Header.Set("X-Signature", "signed")
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestProviderWithTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Signature") != "signed" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"dashboard": {}, "meta": {}}`))
+	}))
+	defer server.Close()
+
+	transport := &signingTransport{}
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL}).WithTransport(transport)
+
+	t.Run("the API client sends requests through the transport", func(t *testing.T) {
+		client, err := provider.Client()
+		require.NoError(t, err)
+
+		_, err = client.Dashboards.GetHomeDashboard()
+		require.NoError(t, err)
+		require.Equal(t, 1, transport.signed)
+	})
+
+	t.Run("the App Platform client sends requests through the transport", func(t *testing.T) {
+		client, err := provider.newHTTPClient()
+		require.NoError(t, err)
+
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, 2, transport.signed)
+	})
+}
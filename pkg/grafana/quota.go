@@ -0,0 +1,71 @@
+package grafana
+
+import (
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+var (
+	_ grizzly.QuotaHandler = &DashboardHandler{}
+	_ grizzly.QuotaHandler = &AlertRuleGroupHandler{}
+	_ grizzly.QuotaHandler = &DatasourceHandler{}
+)
+
+// Targets of the quotas of Grafana organisations
+const (
+	dashboardQuotaTarget  = "dashboard"
+	alertRuleQuotaTarget  = "alert_rule"
+	datasourceQuotaTarget = "data_source"
+)
+
+// orgQuota returns the quota of the current organisation for a target, or
+// nil when there is none. Reading quotas requires the orgs.quotas:read
+// permission.
+func orgQuota(provider grizzly.Provider, target, unit string) (*grizzly.Quota, error) {
+	client, err := provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := client.GetCurrentOrg.GetCurrentOrgQuota()
+	if err != nil {
+		return nil, err
+	}
+	for _, quota := range response.GetPayload() {
+		if quota.Target == target {
+			return &grizzly.Quota{Limit: quota.Limit, Used: quota.Used, Unit: unit}, nil
+		}
+	}
+	return nil, nil
+}
+
+// Quota implements grizzly.QuotaHandler
+func (h *DashboardHandler) Quota() (*grizzly.Quota, error) {
+	return orgQuota(h.Provider, dashboardQuotaTarget, "dashboards")
+}
+
+// QuotaUsage implements grizzly.QuotaHandler
+func (h *DashboardHandler) QuotaUsage(resource grizzly.Resource) int64 {
+	return 1
+}
+
+// Quota implements grizzly.QuotaHandler
+func (h *AlertRuleGroupHandler) Quota() (*grizzly.Quota, error) {
+	return orgQuota(h.Provider, alertRuleQuotaTarget, "alert rules")
+}
+
+// QuotaUsage implements grizzly.QuotaHandler, as the quota counts rules
+// rather than groups
+func (h *AlertRuleGroupHandler) QuotaUsage(resource grizzly.Resource) int64 {
+	rules, _ := resource.GetSpecValue("rules").([]any)
+	return int64(len(rules))
+}
+
+// Quota implements grizzly.QuotaHandler
+func (h *DatasourceHandler) Quota() (*grizzly.Quota, error) {
+	return orgQuota(h.Provider, datasourceQuotaTarget, "datasources")
+}
+
+// QuotaUsage implements grizzly.QuotaHandler
+func (h *DatasourceHandler) QuotaUsage(resource grizzly.Resource) int64 {
+	return 1
+}
@@ -0,0 +1,133 @@
+package grafana
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+var (
+	_ grizzly.SchemaValidator = &AlertRuleGroupHandler{}
+	_ grizzly.SchemaValidator = &AlertContactPointHandler{}
+	_ grizzly.SchemaValidator = &AlertNotificationPolicyHandler{}
+	_ grizzly.SchemaValidator = &AlertNotificationTemplateHandler{}
+	_ grizzly.SchemaValidator = &DatasourceHandler{}
+	_ grizzly.SchemaValidator = &LibraryElementHandler{}
+	_ grizzly.SchemaValidator = &DashboardHandler{}
+)
+
+// model is implemented by the models of the Grafana API client, which are
+// generated from the OpenAPI specification of Grafana
+type model interface {
+	Validate(formats strfmt.Registry) error
+}
+
+// validateSpec decodes the spec of a resource as the given API model, and
+// validates it against the constraints of the OpenAPI specification
+func validateSpec(resource grizzly.Resource, target model) error {
+	if err := decodeSpec(resource, target); err != nil {
+		return err
+	}
+	return target.Validate(strfmt.Default)
+}
+
+func decodeSpec(resource grizzly.Resource, target any) error {
+	data, err := json.Marshal(resource.Spec())
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("spec doesn't match the schema of %s: %w", resource.Kind(), err)
+	}
+	return nil
+}
+
+func schemaProblems(problems []string) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("validation failure list:\n%s", strings.Join(problems, "\n"))
+}
+
+// ValidateSchema implements grizzly.SchemaValidator
+func (h *AlertRuleGroupHandler) ValidateSchema(resource grizzly.Resource) error {
+	return validateSpec(resource, &models.AlertRuleGroup{})
+}
+
+// ValidateSchema implements grizzly.SchemaValidator. The enum of contact point
+// types in the OpenAPI specification is malformed, so only the presence of
+// the required fields is checked.
+func (h *AlertContactPointHandler) ValidateSchema(resource grizzly.Resource) error {
+	var contactPoint models.EmbeddedContactPoint
+	if err := decodeSpec(resource, &contactPoint); err != nil {
+		return err
+	}
+
+	var problems []string
+	if contactPoint.Type == nil || *contactPoint.Type == "" {
+		problems = append(problems, "type in body is required")
+	}
+	if contactPoint.Settings == nil {
+		problems = append(problems, "settings in body is required")
+	}
+	return schemaProblems(problems)
+}
+
+// ValidateSchema implements grizzly.SchemaValidator
+func (h *AlertNotificationPolicyHandler) ValidateSchema(resource grizzly.Resource) error {
+	return validateSpec(resource, &models.Route{})
+}
+
+// ValidateSchema implements grizzly.SchemaValidator
+func (h *AlertMuteTimingHandler) ValidateSchema(resource grizzly.Resource) error {
+	return validateSpec(resource, &models.MuteTimeInterval{})
+}
+
+// ValidateSchema implements grizzly.SchemaValidator
+func (h *AlertNotificationTemplateHandler) ValidateSchema(resource grizzly.Resource) error {
+	return validateSpec(resource, &models.NotificationTemplate{})
+}
+
+// ValidateSchema implements grizzly.SchemaValidator
+func (h *DatasourceHandler) ValidateSchema(resource grizzly.Resource) error {
+	return validateSpec(resource, &models.AddDataSourceCommand{})
+}
+
+// ValidateSchema implements grizzly.SchemaValidator
+func (h *LibraryElementHandler) ValidateSchema(resource grizzly.Resource) error {
+	return validateSpec(resource, &models.CreateLibraryElementCommand{})
+}
+
+// ValidateSchema implements grizzly.SchemaValidator. Dashboards are not
+// described by the OpenAPI specification, so they are checked against
+// DashboardSpec.
+func (h *DashboardHandler) ValidateSchema(resource grizzly.Resource) error {
+	var problems []string
+
+	if title, ok := resource.GetSpecValue("title").(string); !ok || title == "" {
+		problems = append(problems, "title in body is required")
+	}
+	for _, key := range []string{"panels", "tags"} {
+		value := resource.GetSpecValue(key)
+		if _, ok := value.([]any); value != nil && !ok {
+			problems = append(problems, fmt.Sprintf("%s in body must be of type array", key))
+		}
+	}
+	panels, _ := resource.GetSpecValue("panels").([]any)
+	for i, panel := range panels {
+		if _, ok := panel.(map[string]any); !ok {
+			problems = append(problems, fmt.Sprintf("panels.%d in body must be of type object", i))
+		}
+	}
+
+	if len(problems) > 0 {
+		return schemaProblems(problems)
+	}
+
+	_, err := grizzly.DecodeSpec[DashboardSpec](resource)
+	return err
+}
@@ -0,0 +1,74 @@
+package grafana
+
+import (
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSchema(t *testing.T) {
+	provider := NewProvider(nil)
+
+	tests := []struct {
+		name     string
+		handler  grizzly.SchemaValidator
+		kind     string
+		spec     map[string]any
+		expected string
+	}{
+		{
+			name:    "valid contact point",
+			handler: NewAlertContactPointHandler(provider),
+			kind:    AlertContactPointKind,
+			spec:    map[string]any{"name": "email", "type": "email", "settings": map[string]any{"addresses": "me@example.com"}},
+		},
+		{
+			name:     "contact point without type",
+			handler:  NewAlertContactPointHandler(provider),
+			kind:     AlertContactPointKind,
+			spec:     map[string]any{"name": "email", "settings": map[string]any{}},
+			expected: "type in body is required",
+		},
+		{
+			name:     "library element of unknown kind",
+			handler:  NewLibraryElementHandler(provider),
+			kind:     LibraryElementKind,
+			spec:     map[string]any{"uid": "panel", "name": "Panel", "kind": 3},
+			expected: "kind in body should be one of [1 2]",
+		},
+		{
+			name:     "malformed field",
+			handler:  NewAlertRuleGroupHandler(provider),
+			kind:     AlertRuleGroupKind,
+			spec:     map[string]any{"title": "group", "rules": "not a list"},
+			expected: "spec doesn't match the schema of AlertRuleGroup",
+		},
+		{
+			name:    "valid dashboard",
+			handler: NewDashboardHandler(provider),
+			kind:    DashboardKind,
+			spec:    map[string]any{"title": "Dashboard", "panels": []any{map[string]any{"id": 1}}},
+		},
+		{
+			name:     "dashboard with malformed panels",
+			handler:  NewDashboardHandler(provider),
+			kind:     DashboardKind,
+			spec:     map[string]any{"title": "Dashboard", "panels": []any{"panel"}},
+			expected: "panels.0 in body must be of type object",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resource, err := grizzly.NewResource(provider.APIVersion(), test.kind, "name", test.spec)
+			require.NoError(t, err)
+
+			err = test.handler.ValidateSchema(resource)
+			if test.expected == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, test.expected)
+		})
+	}
+}
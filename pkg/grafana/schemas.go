@@ -0,0 +1,85 @@
+package grafana
+
+import (
+	"embed"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+// schemas documents the fields of the kinds of the provider, one file per kind
+//
+//go:embed schemas/*.yaml
+var schemas embed.FS
+
+var _ grizzly.SchemaDescriber = &AlertRuleGroupHandler{}
+var _ grizzly.SchemaDescriber = &AlertContactPointHandler{}
+var _ grizzly.SchemaDescriber = &DashboardHandler{}
+var _ grizzly.SchemaDescriber = &DatasourceHandler{}
+var _ grizzly.SchemaDescriber = &FolderHandler{}
+var _ grizzly.SchemaDescriber = &FolderTreeHandler{}
+var _ grizzly.SchemaDescriber = &LibraryElementHandler{}
+var _ grizzly.SchemaDescriber = &AlertMuteTimingHandler{}
+var _ grizzly.SchemaDescriber = &AlertNotificationPolicyHandler{}
+var _ grizzly.SchemaDescriber = &AlertNotificationTemplateHandler{}
+var _ grizzly.SchemaDescriber = &TeamPreferencesHandler{}
+var _ grizzly.SchemaDescriber = &PlaylistHandler{}
+
+// Schema implements grizzly.SchemaDescriber
+func (h *AlertRuleGroupHandler) Schema() (*grizzly.Schema, error) {
+	return grizzly.LoadSchema(schemas, h.Kind())
+}
+
+// Schema implements grizzly.SchemaDescriber
+func (h *AlertContactPointHandler) Schema() (*grizzly.Schema, error) {
+	return grizzly.LoadSchema(schemas, h.Kind())
+}
+
+// Schema implements grizzly.SchemaDescriber
+func (h *DashboardHandler) Schema() (*grizzly.Schema, error) {
+	return grizzly.LoadSchema(schemas, h.Kind())
+}
+
+// Schema implements grizzly.SchemaDescriber
+func (h *DatasourceHandler) Schema() (*grizzly.Schema, error) {
+	return grizzly.LoadSchema(schemas, h.Kind())
+}
+
+// Schema implements grizzly.SchemaDescriber
+func (h *FolderHandler) Schema() (*grizzly.Schema, error) {
+	return grizzly.LoadSchema(schemas, h.Kind())
+}
+
+// Schema implements grizzly.SchemaDescriber
+func (h *FolderTreeHandler) Schema() (*grizzly.Schema, error) {
+	return grizzly.LoadSchema(schemas, h.Kind())
+}
+
+// Schema implements grizzly.SchemaDescriber
+func (h *LibraryElementHandler) Schema() (*grizzly.Schema, error) {
+	return grizzly.LoadSchema(schemas, h.Kind())
+}
+
+// Schema implements grizzly.SchemaDescriber
+func (h *AlertMuteTimingHandler) Schema() (*grizzly.Schema, error) {
+	return grizzly.LoadSchema(schemas, h.Kind())
+}
+
+// Schema implements grizzly.SchemaDescriber
+func (h *AlertNotificationPolicyHandler) Schema() (*grizzly.Schema, error) {
+	return grizzly.LoadSchema(schemas, h.Kind())
+}
+
+// Schema implements grizzly.SchemaDescriber
+func (h *AlertNotificationTemplateHandler) Schema() (*grizzly.Schema, error) {
+	return grizzly.LoadSchema(schemas, h.Kind())
+}
+
+// Schema implements grizzly.SchemaDescriber
+func (h *TeamPreferencesHandler) Schema() (*grizzly.Schema, error) {
+	return grizzly.LoadSchema(schemas, h.Kind())
+}
+
+// Schema implements grizzly.SchemaDescriber
+func (h *PlaylistHandler) Schema() (*grizzly.Schema, error) {
+	return grizzly.LoadSchema(schemas, h.Kind())
+}
@@ -0,0 +1,121 @@
+package grafana
+
+import (
+	"encoding/json"
+
+	"github.com/grafana/grafana-openapi-client-go/models"
+)
+
+// Typed specs of the Grafana kinds, to be decoded with grizzly.DecodeSpec. The
+// kinds described by the OpenAPI specification of Grafana use the models
+// generated from it. Dashboards and folders aren't described there, so their
+// specs mirror the dashboard and folder kind schemas of Grafana.
+//
+// The dashboard handler reads and writes dashboards through DashboardSpec, and
+// falls back to their untyped spec when they don't match it, as such specs are
+// still applied as they are. The other handlers still use untyped specs.
+type (
+	AlertRuleGroupSpec            = models.AlertRuleGroup
+	AlertContactPointSpec         = models.EmbeddedContactPoint
+	AlertNotificationPolicySpec   = models.Route
+	AlertMuteTimingSpec           = models.MuteTimeInterval
+	AlertNotificationTemplateSpec = models.NotificationTemplate
+	DatasourceSpec                = models.AddDataSourceCommand
+	LibraryElementSpec            = models.CreateLibraryElementCommand
+)
+
+// DashboardSpec is the spec of a Dashboard
+type DashboardSpec struct {
+	ID           int64          `json:"id,omitempty"`
+	UID          string         `json:"uid,omitempty"`
+	Title        string         `json:"title"`
+	Description  string         `json:"description,omitempty"`
+	Tags         []string       `json:"tags,omitempty"`
+	Timezone     string         `json:"timezone,omitempty"`
+	Editable     *bool          `json:"editable,omitempty"`
+	GraphTooltip int            `json:"graphTooltip,omitempty"`
+	Time         *DashboardTime `json:"time,omitempty"`
+	// Refresh is an interval, or false
+	Refresh              any                `json:"refresh,omitempty"`
+	FiscalYearStartMonth int                `json:"fiscalYearStartMonth,omitempty"`
+	LiveNow              bool               `json:"liveNow,omitempty"`
+	WeekStart            string             `json:"weekStart,omitempty"`
+	SchemaVersion        int                `json:"schemaVersion,omitempty"`
+	Version              int64              `json:"version,omitempty"`
+	Links                []map[string]any   `json:"links,omitempty"`
+	Templating           *DashboardVariable `json:"templating,omitempty"`
+	Annotations          *DashboardVariable `json:"annotations,omitempty"`
+	Panels               []DashboardPanel   `json:"panels,omitempty"`
+}
+
+// DashboardTime is the default time range of a dashboard
+type DashboardTime struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// DashboardVariable holds the template variables, or the annotation queries,
+// of a dashboard. Their fields depend on their type and datasource.
+type DashboardVariable struct {
+	List []map[string]any `json:"list"`
+}
+
+// DashboardPanel is a panel of a dashboard, or a row and the panels it holds
+// when collapsed. The fields of queries and options depend on the datasource
+// and on the type of the panel.
+type DashboardPanel struct {
+	ID              int64            `json:"id,omitempty"`
+	Type            string           `json:"type,omitempty"`
+	Title           string           `json:"title,omitempty"`
+	Description     string           `json:"description,omitempty"`
+	GridPos         *PanelGridPos    `json:"gridPos,omitempty"`
+	Datasource      *DataSourceRef   `json:"datasource,omitempty"`
+	Targets         []map[string]any `json:"targets,omitempty"`
+	FieldConfig     map[string]any   `json:"fieldConfig,omitempty"`
+	Options         map[string]any   `json:"options,omitempty"`
+	Transformations []map[string]any `json:"transformations,omitempty"`
+	Links           []map[string]any `json:"links,omitempty"`
+	Repeat          string           `json:"repeat,omitempty"`
+	Collapsed       bool             `json:"collapsed,omitempty"`
+	LibraryPanel    *LibraryPanelRef `json:"libraryPanel,omitempty"`
+	Panels          []DashboardPanel `json:"panels,omitempty"`
+}
+
+// PanelGridPos is the position of a panel in the grid of a dashboard
+type PanelGridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// DataSourceRef refers to a datasource, by UID or else by type
+type DataSourceRef struct {
+	Type string `json:"type,omitempty"`
+	UID  string `json:"uid,omitempty"`
+}
+
+// UnmarshalJSON accepts the datasource names, or variables, older dashboards
+// refer to datasources with
+func (ref *DataSourceRef) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*ref = DataSourceRef{UID: name}
+		return nil
+	}
+	type plain DataSourceRef
+	return json.Unmarshal(data, (*plain)(ref))
+}
+
+// LibraryPanelRef refers to the library panel a panel is linked to
+type LibraryPanelRef struct {
+	UID  string `json:"uid"`
+	Name string `json:"name,omitempty"`
+}
+
+// DashboardFolderSpec is the spec of a DashboardFolder
+type DashboardFolderSpec struct {
+	UID         string `json:"uid"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
@@ -0,0 +1,77 @@
+package grafana
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatus(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/search", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"uid": "api"}, {"uid": "db"}, {"uid": "legacy"}]`))
+	})
+	mux.HandleFunc("GET /api/dashboards/uid/api", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"dashboard": {"uid": "api", "title": "API"}, "meta": {"folderUid": "general"}}`))
+	})
+	mux.HandleFunc("GET /api/dashboards/uid/db", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"dashboard": {"uid": "db", "title": "DB"}, "meta": {"folderUid": "general"}}`))
+	})
+	mux.HandleFunc("GET /api/dashboards/uid/web", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message": "Dashboard not found"}`))
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mux.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	registry := grizzly.NewRegistry([]grizzly.Provider{NewProvider(&config.GrafanaConfig{URL: server.URL})})
+	dashboard := func(uid, title string) grizzly.Resource {
+		resource, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", DashboardKind, uid, map[string]any{"uid": uid, "title": title})
+		require.NoError(t, err)
+		resource.SetMetadata("folder", "general")
+		return resource
+	}
+	resources := grizzly.NewResources(dashboard("api", "API"), dashboard("db", "Databases"), dashboard("web", "Web"))
+
+	dir := t.TempDir()
+	lastApplyFile := filepath.Join(dir, "last-apply.json")
+	appliedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, grizzly.RecordLastApply(lastApplyFile, "prod", grizzly.LastApply{At: appliedAt, ResourcePath: "resources", Summary: "2 resources added"}))
+
+	status, err := grizzly.Status(registry, resources, grizzly.StatusOptions{
+		Context:       "prod",
+		ResourcePath:  dir,
+		LastApplyFile: lastApplyFile,
+	})
+	require.NoError(t, err)
+	require.Equal(t, grizzly.WorkspaceStatus{
+		Context:      "prod",
+		ResourcePath: dir,
+		Local:        3,
+		InSync:       1,
+		Drifted:      1,
+		Missing:      1,
+		Unmanaged:    []string{"Dashboard.legacy"},
+		LastApply:    &grizzly.LastApply{At: appliedAt, ResourcePath: "resources", Summary: "2 resources added"},
+	}, status, "the temporary directory isn't a git repository")
+
+	t.Run("the last apply of other contexts isn't reported", func(t *testing.T) {
+		status, err := grizzly.Status(registry, resources, grizzly.StatusOptions{Context: "staging", ResourcePath: dir, LastApplyFile: lastApplyFile})
+		require.NoError(t, err)
+		require.Nil(t, status.LastApply)
+
+		output, err := grizzly.FormatStatus(status, "default")
+		require.NoError(t, err)
+		require.Contains(t, string(output), "Last apply:          never recorded\n")
+		require.Contains(t, string(output), "Unmanaged remotely:\n  Dashboard.legacy\n")
+	})
+}
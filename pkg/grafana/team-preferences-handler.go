@@ -0,0 +1,220 @@
+package grafana
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-openapi-client-go/client/teams"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+const TeamPreferencesKind = "TeamPreferences"
+
+var _ grizzly.Handler = &TeamPreferencesHandler{}
+
+// TeamPreferencesHandler is a Grizzly Handler for the preferences of Grafana
+// teams: home dashboard, theme, timezone, etc. Teams are identified by name,
+// as their IDs differ from one Grafana instance to another.
+type TeamPreferencesHandler struct {
+	grizzly.BaseHandler
+}
+
+// NewTeamPreferencesHandler returns a new Grizzly Handler for Grafana team preferences
+func NewTeamPreferencesHandler(provider grizzly.Provider) *TeamPreferencesHandler {
+	return &TeamPreferencesHandler{
+		BaseHandler: grizzly.NewBaseHandler(provider, TeamPreferencesKind, false),
+	}
+}
+
+// ErrorHint implements grizzly.ErrorHinter
+func (h *TeamPreferencesHandler) ErrorHint(code grizzly.ErrorCode) string {
+	return permissionsHint(code, "teams")
+}
+
+const (
+	teamPreferencesPattern = "teams/preferences-%s.%s"
+)
+
+// ResourceFilePath returns the location on disk where a resource should be updated
+func (h *TeamPreferencesHandler) ResourceFilePath(resource grizzly.Resource, filetype string) string {
+	filename := strings.ReplaceAll(resource.Name(), string(os.PathSeparator), "-")
+	filename = strings.ReplaceAll(filename, " ", "-")
+	return fmt.Sprintf(teamPreferencesPattern, filename, filetype)
+}
+
+// Unprepare removes unnecessary elements from a remote resource ready for presentation/comparison
+func (h *TeamPreferencesHandler) Unprepare(resource grizzly.Resource) *grizzly.Resource {
+	// Deprecated in favor of homeDashboardUID, which is portable across instances
+	resource.DeleteSpecKey("homeDashboardId")
+	return &resource
+}
+
+// Prepare gets a resource ready for dispatch to the remote endpoint
+func (h *TeamPreferencesHandler) Prepare(existing *grizzly.Resource, resource grizzly.Resource) *grizzly.Resource {
+	if !resource.HasSpecString("team") {
+		resource.SetSpecValue("team", resource.Name())
+	}
+	return &resource
+}
+
+// Validate checks that the team name in the spec matches the name of the resource
+func (h *TeamPreferencesHandler) Validate(resource grizzly.Resource) error {
+	team, exist := resource.GetSpecString("team")
+	if exist && team != resource.Name() {
+		return fmt.Errorf("team '%s' and name '%s', don't match", team, resource.Name())
+	}
+	return nil
+}
+
+func (h *TeamPreferencesHandler) GetSpecUID(resource grizzly.Resource) (string, error) {
+	team, ok := resource.GetSpecString("team")
+	if !ok {
+		return "", fmt.Errorf("team not specified")
+	}
+	return team, nil
+}
+
+// GetByUID retrieves JSON for a resource from an endpoint, by UID
+func (h *TeamPreferencesHandler) GetByUID(uid string) (*grizzly.Resource, error) {
+	return h.getRemoteTeamPreferences(uid)
+}
+
+// GetRemote retrieves the preferences of a team as a Resource
+func (h *TeamPreferencesHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
+	return h.getRemoteTeamPreferences(resource.Name())
+}
+
+// ListRemote retrieves the names of all remote teams
+func (h *TeamPreferencesHandler) ListRemote() ([]string, error) {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		perPage       = int64(1000)
+		page    int64 = 0
+		names   []string
+	)
+	params := teams.NewSearchTeamsParams().WithPerpage(&perPage)
+	for {
+		page++
+		params.SetPage(&page)
+
+		searchOk, err := client.Teams.SearchTeams(params)
+		if err != nil {
+			return nil, err
+		}
+
+		result := searchOk.GetPayload()
+		for _, team := range result.Teams {
+			names = append(names, team.Name)
+		}
+		if int64(len(result.Teams)) < perPage {
+			return names, nil
+		}
+	}
+}
+
+// Add updates the preferences of a team: preferences exist as soon as a team does
+func (h *TeamPreferencesHandler) Add(resource grizzly.Resource) error {
+	return h.putTeamPreferences(resource)
+}
+
+// Update pushes the preferences of a team to Grafana via the API
+func (h *TeamPreferencesHandler) Update(existing, resource grizzly.Resource) error {
+	return h.putTeamPreferences(resource)
+}
+
+// Delete resets the preferences of a team to their defaults
+func (h *TeamPreferencesHandler) Delete(resource grizzly.Resource) error {
+	teamID, err := h.getTeamID(resource.Name())
+	if err != nil {
+		return err
+	}
+
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Teams.UpdateTeamPreferences(teamID, &models.UpdatePrefsCmd{})
+	return err
+}
+
+// getTeamID looks up a team by name
+func (h *TeamPreferencesHandler) getTeamID(name string) (string, error) {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return "", err
+	}
+
+	searchOk, err := client.Teams.SearchTeams(teams.NewSearchTeamsParams().WithName(&name))
+	if err != nil {
+		return "", err
+	}
+	for _, team := range searchOk.GetPayload().Teams {
+		if team.Name == name {
+			return strconv.FormatInt(team.ID, 10), nil
+		}
+	}
+
+	return "", grizzly.ErrNotFound
+}
+
+func (h *TeamPreferencesHandler) getRemoteTeamPreferences(name string) (*grizzly.Resource, error) {
+	teamID, err := h.getTeamID(name)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+
+	preferencesOk, err := client.Teams.GetTeamPreferences(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := structToMap(preferencesOk.GetPayload())
+	if err != nil {
+		return nil, err
+	}
+	spec["team"] = name
+
+	resource, err := grizzly.NewResource(h.APIVersion(), h.Kind(), name, spec)
+	if err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+func (h *TeamPreferencesHandler) putTeamPreferences(resource grizzly.Resource) error {
+	teamID, err := h.getTeamID(resource.Name())
+	if err != nil {
+		return fmt.Errorf("looking up team %s, which must exist before setting its preferences: %w", resource.Name(), err)
+	}
+
+	data, err := json.Marshal(resource.Spec())
+	if err != nil {
+		return err
+	}
+	var preferences models.UpdatePrefsCmd
+	if err := json.Unmarshal(data, &preferences); err != nil {
+		return err
+	}
+
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Teams.UpdateTeamPreferences(teamID, &preferences)
+	return err
+}
@@ -0,0 +1,119 @@
+package grafana
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func newTeamPreferencesTestServer(t *testing.T, teams []string, preferences map[int]map[string]any) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/teams/search", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		perPage, _ := strconv.Atoi(query.Get("perpage"))
+		page, _ := strconv.Atoi(query.Get("page"))
+
+		// like Grafana, the name is matched as a prefix
+		var matching []map[string]any
+		for id, name := range teams {
+			if strings.HasPrefix(name, query.Get("name")) {
+				matching = append(matching, map[string]any{"id": id, "name": name})
+			}
+		}
+		if perPage > 0 {
+			start := min((page-1)*perPage, len(matching))
+			matching = matching[start:min(start+perPage, len(matching))]
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"teams": matching, "totalCount": len(teams)})
+	})
+	mux.HandleFunc("GET /api/teams/{id}/preferences", func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.Atoi(r.PathValue("id"))
+		_ = json.NewEncoder(w).Encode(preferences[id])
+	})
+	mux.HandleFunc("PUT /api/teams/{id}/preferences", func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.Atoi(r.PathValue("id"))
+		body := map[string]any{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		delete(body, "cookies")
+		preferences[id] = body
+		_, _ = w.Write([]byte(`{"message": "Preferences updated"}`))
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTeamPreferencesHandler(t *testing.T) {
+	teams := []string{"Platform Ops", "Platform"}
+	for i := range 1500 {
+		teams = append(teams, fmt.Sprintf("team-%d", i))
+	}
+	preferences := map[int]map[string]any{
+		0: {"theme": "light"},
+		1: {"theme": "dark", "homeDashboardId": 12, "homeDashboardUID": "home"},
+	}
+	server := newTeamPreferencesTestServer(t, teams, preferences)
+	handler := NewTeamPreferencesHandler(NewProvider(&config.GrafanaConfig{URL: server.URL}))
+
+	t.Run("teams are looked up by name", func(t *testing.T) {
+		resource, err := handler.GetByUID("Platform")
+		require.NoError(t, err)
+		require.Equal(t, "Platform", resource.Name())
+		require.Equal(t, "dark", resource.Spec()["theme"], "teams whose name only starts with the name aren't matched")
+		require.Equal(t, "Platform", resource.Spec()["team"])
+
+		_, err = handler.GetByUID("Missing")
+		require.ErrorIs(t, err, grizzly.ErrNotFound)
+	})
+
+	t.Run("every page of teams is listed", func(t *testing.T) {
+		names, err := handler.ListRemote()
+		require.NoError(t, err)
+		require.Equal(t, teams, names)
+	})
+
+	t.Run("the ID of the home dashboard is removed", func(t *testing.T) {
+		remote, err := handler.GetByUID("Platform")
+		require.NoError(t, err)
+		spec := handler.Unprepare(*remote).Spec()
+		require.NotContains(t, spec, "homeDashboardId", "IDs differ from one instance to another")
+		require.Equal(t, "home", spec["homeDashboardUID"])
+	})
+
+	t.Run("preferences are applied to the team of the resource", func(t *testing.T) {
+		resource, err := grizzly.NewResource(handler.APIVersion(), TeamPreferencesKind, "Platform", map[string]any{
+			"theme":    "light",
+			"timezone": "utc",
+		})
+		require.NoError(t, err)
+		require.NoError(t, handler.Add(*handler.Prepare(nil, resource)))
+		require.Equal(t, map[string]any{"theme": "light", "timezone": "utc"}, preferences[1])
+		require.Equal(t, map[string]any{"theme": "light"}, preferences[0])
+
+		missing, err := grizzly.NewResource(handler.APIVersion(), TeamPreferencesKind, "Missing", map[string]any{})
+		require.NoError(t, err)
+		require.ErrorContains(t, handler.Add(missing), "looking up team Missing, which must exist before setting its preferences")
+	})
+
+	t.Run("deleting preferences resets them", func(t *testing.T) {
+		resource, err := grizzly.NewResource(handler.APIVersion(), TeamPreferencesKind, "Platform", map[string]any{"theme": "light"})
+		require.NoError(t, err)
+		require.NoError(t, handler.Delete(resource))
+		require.Empty(t, preferences[1])
+		require.Equal(t, map[string]any{"theme": "light"}, preferences[0], "other teams are left untouched")
+	})
+}
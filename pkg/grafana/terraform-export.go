@@ -0,0 +1,325 @@
+package grafana
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
+	"github.com/hashicorp/go-multierror"
+)
+
+// Formats of Terraform exports
+const (
+	// TerraformFormat exports resources as resources of the Grafana
+	// Terraform provider, along with the import blocks adopting them
+	TerraformFormat = "terraform"
+	// TerraformImportFormat only exports import blocks, for Terraform to
+	// generate the configuration of with `terraform plan -generate-config-out`
+	TerraformImportFormat = "terraform-import"
+)
+
+// terraformBlock is a resource of the Grafana Terraform provider, along with
+// the ID it's imported by
+type terraformBlock struct {
+	Type     string
+	Name     string
+	ImportID string
+	// Attributes are HCL expressions, by attribute. No resource block is
+	// written without attributes, only an import block.
+	Attributes []terraformAttribute
+}
+
+type terraformAttribute struct {
+	Name  string
+	Value string
+}
+
+// ExportTerraform writes the remote versions of resources as configuration of
+// the Grafana Terraform provider, in a file per Terraform resource type, so
+// that they can be imported into Terraform state and managed there
+func ExportTerraform(registry grizzly.Registry, exportDir string, resources grizzly.Resources, importOnly bool) error {
+	var finalErr error
+	var remotes []grizzly.Resource
+	for _, resource := range resources.AsList() {
+		handler, err := registry.GetHandler(resource.Kind())
+		if err != nil {
+			finalErr = multierror.Append(finalErr, err)
+			continue
+		}
+		remote, err := handler.GetRemote(resource)
+		if errors.Is(err, grizzly.ErrNotFound) {
+			notifier.NotFound(resource)
+			continue
+		}
+		if err != nil {
+			finalErr = multierror.Append(finalErr, fmt.Errorf("%s: %w", resource.Ref(), grizzly.ClassifyError(handler, err)))
+			continue
+		}
+		remotes = append(remotes, *remote)
+	}
+	if finalErr != nil {
+		return finalErr
+	}
+
+	files, err := TerraformConfig(remotes, importOnly)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(exportDir, 0755); err != nil {
+		return err
+	}
+	for _, name := range sortedFileNames(files) {
+		path := filepath.Join(exportDir, name)
+		existing, err := os.ReadFile(path)
+		isNotExist := os.IsNotExist(err)
+		if err != nil && !isNotExist {
+			return err
+		}
+		switch {
+		case string(existing) == string(files[name]):
+			notifier.NoChanges(notifier.SimpleString(path))
+		case isNotExist:
+			if err := os.WriteFile(path, files[name], 0644); err != nil {
+				return err
+			}
+			notifier.Added(notifier.SimpleString(path))
+		default:
+			if err := os.WriteFile(path, files[name], 0644); err != nil {
+				return err
+			}
+			notifier.Updated(notifier.SimpleString(path))
+		}
+	}
+	return nil
+}
+
+// TerraformConfig renders resources as configuration of the Grafana Terraform
+// provider, by file name. Kinds whose schema differs too much from the
+// Terraform one, such as alert rule groups, are only exported as import
+// blocks, as are all kinds when importOnly is set.
+func TerraformConfig(resources []grizzly.Resource, importOnly bool) (map[string][]byte, error) {
+	blocks := map[string][]terraformBlock{}
+	names := map[string]map[string]bool{}
+	imported := map[string]bool{}
+	for _, resource := range resources {
+		block, ok, err := terraformResource(resource)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", resource.Ref(), err)
+		}
+		if !ok {
+			notifier.NotSupported(resource, "export to Terraform")
+			continue
+		}
+		// contact points are grouped by name in Terraform
+		if imported[block.Type+"/"+block.ImportID] {
+			continue
+		}
+		imported[block.Type+"/"+block.ImportID] = true
+
+		if names[block.Type] == nil {
+			names[block.Type] = map[string]bool{}
+		}
+		block.Name = uniqueTerraformName(names[block.Type], block.Name)
+		if importOnly {
+			block.Attributes = nil
+		}
+		blocks[block.Type] = append(blocks[block.Type], block)
+	}
+
+	files := map[string][]byte{}
+	for resourceType, typeBlocks := range blocks {
+		var out bytes.Buffer
+		for i, block := range typeBlocks {
+			if i > 0 {
+				out.WriteString("\n")
+			}
+			writeTerraformBlock(&out, block)
+		}
+		files[resourceType+".tf"] = out.Bytes()
+	}
+	return files, nil
+}
+
+// terraformResource maps a resource to the Terraform resource managing it
+func terraformResource(resource grizzly.Resource) (terraformBlock, bool, error) {
+	uid, _ := resource.GetSpecString("uid")
+	block := terraformBlock{Name: resource.Name(), ImportID: uid}
+
+	switch resource.Kind() {
+	case DashboardKind:
+		block.Type = "grafana_dashboard"
+		copied := resource.DeepCopy()
+		spec := copied.Spec()
+		// managed by Grafana, and ignored by the provider
+		delete(spec, "id")
+		delete(spec, "version")
+		config, err := hclJSON(spec)
+		if err != nil {
+			return block, false, err
+		}
+		if folder := resource.GetMetadata("folder"); folder != "" && folder != generalFolderUID {
+			block.attribute("folder", hclString(folder))
+		}
+		block.attribute("config_json", config)
+	case DashboardFolderKind:
+		block.Type = "grafana_folder"
+		block.stringAttributes(resource, "uid", "uid", "title", "title", "parent_folder_uid", "parentUid")
+	case DatasourceKind:
+		block.Type = "grafana_data_source"
+		block.stringAttributes(resource, "uid", "uid", "name", "name", "type", "type", "url", "url", "access_mode", "access",
+			"database_name", "database", "username", "user", "basic_auth_username", "basicAuthUser")
+		if isDefault, _ := resource.GetSpecValue("isDefault").(bool); isDefault {
+			block.attribute("is_default", "true")
+		}
+		if basicAuth, _ := resource.GetSpecValue("basicAuth").(bool); basicAuth {
+			block.attribute("basic_auth_enabled", "true")
+		}
+		if jsonData, ok := resource.GetSpecValue("jsonData").(map[string]any); ok && len(jsonData) > 0 {
+			encoded, err := hclJSON(jsonData)
+			if err != nil {
+				return block, false, err
+			}
+			block.attribute("json_data_encoded", encoded)
+		}
+	case LibraryElementKind:
+		// only library panels are supported by the provider, not variables
+		if kind, _ := resource.GetSpecValue("kind").(float64); kind != 1 {
+			return block, false, nil
+		}
+		block.Type = "grafana_library_panel"
+		block.stringAttributes(resource, "uid", "uid", "name", "name", "folder_uid", "folderUid")
+		model, err := hclJSON(resource.GetSpecValue("model"))
+		if err != nil {
+			return block, false, err
+		}
+		block.attribute("model_json", model)
+	case AlertRuleGroupKind:
+		block.Type = "grafana_rule_group"
+		folderUID, _ := resource.GetSpecString("folderUid")
+		title, _ := resource.GetSpecString("title")
+		block.ImportID = folderUID + ":" + title
+	case AlertContactPointKind:
+		block.Type = "grafana_contact_point"
+		block.ImportID, _ = resource.GetSpecString("name")
+		block.Name = block.ImportID
+	case AlertNotificationPolicyKind:
+		block.Type = "grafana_notification_policy"
+		block.ImportID = "policy"
+	case AlertMuteTimingKind:
+		block.Type = "grafana_mute_timing"
+		block.ImportID, _ = resource.GetSpecString("name")
+	case AlertNotificationTemplateKind:
+		block.Type = "grafana_message_template"
+		block.ImportID, _ = resource.GetSpecString("name")
+	default:
+		return block, false, nil
+	}
+	return block, true, nil
+}
+
+func (b *terraformBlock) attribute(name, value string) {
+	b.Attributes = append(b.Attributes, terraformAttribute{Name: name, Value: value})
+}
+
+// stringAttributes sets attributes from spec fields, given as pairs of
+// attribute and field names, when they aren't empty
+func (b *terraformBlock) stringAttributes(resource grizzly.Resource, attributesAndFields ...string) {
+	for i := 0; i < len(attributesAndFields); i += 2 {
+		if value, _ := resource.GetSpecString(attributesAndFields[i+1]); value != "" {
+			b.attribute(attributesAndFields[i], hclString(value))
+		}
+	}
+}
+
+func writeTerraformBlock(out *bytes.Buffer, block terraformBlock) {
+	fmt.Fprintf(out, "import {\n  to = %s.%s\n  id = %s\n}\n", block.Type, block.Name, hclString(block.ImportID))
+	if len(block.Attributes) == 0 {
+		return
+	}
+
+	width := 0
+	for _, attribute := range block.Attributes {
+		width = max(width, len(attribute.Name))
+	}
+	fmt.Fprintf(out, "\nresource %q %q {\n", block.Type, block.Name)
+	for _, attribute := range block.Attributes {
+		fmt.Fprintf(out, "  %-*s = %s\n", width, attribute.Name, attribute.Value)
+	}
+	out.WriteString("}\n")
+}
+
+var invalidTerraformName = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// uniqueTerraformName turns a resource name into a Terraform identifier, not
+// used yet by another resource of the same type
+func uniqueTerraformName(used map[string]bool, name string) string {
+	name = strings.Trim(invalidTerraformName.ReplaceAllString(name, "_"), "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') || name[0] == '-' {
+		name = "_" + name
+	}
+	unique := name
+	for i := 2; used[unique]; i++ {
+		unique = fmt.Sprintf("%s_%d", name, i)
+	}
+	used[unique] = true
+	return unique
+}
+
+// hclString quotes a string as an HCL literal, escaping template sequences
+func hclString(value string) string {
+	var quoted strings.Builder
+	quoted.WriteByte('"')
+	for _, r := range value {
+		switch {
+		case r == '"' || r == '\\':
+			quoted.WriteRune('\\')
+			quoted.WriteRune(r)
+		case r == '\n':
+			quoted.WriteString(`\n`)
+		case r == '\r':
+			quoted.WriteString(`\r`)
+		case r == '\t':
+			quoted.WriteString(`\t`)
+		case r < 0x20:
+			fmt.Fprintf(&quoted, `\u%04x`, r)
+		default:
+			quoted.WriteRune(r)
+		}
+	}
+	quoted.WriteByte('"')
+	return escapeHCLTemplates(quoted.String())
+}
+
+// hclJSON renders a value as a jsonencode expression. JSON objects and arrays
+// are valid HCL expressions, once template sequences are escaped.
+func hclJSON(value any) (string, error) {
+	var encoded bytes.Buffer
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("  ", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	return "jsonencode(" + escapeHCLTemplates(strings.TrimSpace(encoded.String())) + ")", nil
+}
+
+func escapeHCLTemplates(value string) string {
+	return strings.NewReplacer("${", "$${", "%{", "%%{").Replace(value)
+}
+
+func sortedFileNames(files map[string][]byte) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
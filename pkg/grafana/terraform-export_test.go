@@ -0,0 +1,91 @@
+package grafana
+
+import (
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerraformConfig(t *testing.T) {
+	newResource := func(kind, name string, spec map[string]any) grizzly.Resource {
+		resource, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", kind, name, spec)
+		require.NoError(t, err)
+		return resource
+	}
+	dashboard := newResource(DashboardKind, "node-overview", map[string]any{
+		"id":      float64(12),
+		"uid":     "node-overview",
+		"title":   "Node ${host}",
+		"version": float64(3),
+	})
+	dashboard.SetMetadata("folder", "infra")
+	resources := []grizzly.Resource{
+		dashboard,
+		newResource(DashboardFolderKind, "infra", map[string]any{"uid": "infra", "title": "Infra \"core\""}),
+		newResource(AlertRuleGroupKind, "infra.node", map[string]any{"folderUid": "infra", "title": "node"}),
+		newResource(AlertContactPointKind, "oncall-slack", map[string]any{"uid": "oncall-slack", "name": "oncall"}),
+		newResource(AlertContactPointKind, "oncall-email", map[string]any{"uid": "oncall-email", "name": "oncall"}),
+		newResource(FolderTreeKind, "tree", map[string]any{}),
+	}
+
+	t.Run("resources and import blocks", func(t *testing.T) {
+		files, err := TerraformConfig(resources, false)
+		require.NoError(t, err)
+		require.Len(t, files, 4)
+
+		require.Equal(t, `import {
+  to = grafana_dashboard.node-overview
+  id = "node-overview"
+}
+
+resource "grafana_dashboard" "node-overview" {
+  folder      = "infra"
+  config_json = jsonencode({
+    "title": "Node $${host}",
+    "uid": "node-overview"
+  })
+}
+`, string(files["grafana_dashboard.tf"]))
+
+		require.Equal(t, `import {
+  to = grafana_folder.infra
+  id = "infra"
+}
+
+resource "grafana_folder" "infra" {
+  uid   = "infra"
+  title = "Infra \"core\""
+}
+`, string(files["grafana_folder.tf"]))
+
+		require.Equal(t, `import {
+  to = grafana_rule_group.infra_node
+  id = "infra:node"
+}
+`, string(files["grafana_rule_group.tf"]))
+
+		require.Equal(t, `import {
+  to = grafana_contact_point.oncall
+  id = "oncall"
+}
+`, string(files["grafana_contact_point.tf"]))
+	})
+
+	t.Run("import blocks only", func(t *testing.T) {
+		files, err := TerraformConfig(resources, true)
+		require.NoError(t, err)
+		require.Equal(t, `import {
+  to = grafana_folder.infra
+  id = "infra"
+}
+`, string(files["grafana_folder.tf"]))
+	})
+}
+
+func TestUniqueTerraformName(t *testing.T) {
+	used := map[string]bool{}
+	require.Equal(t, "team_a", uniqueTerraformName(used, "team a"))
+	require.Equal(t, "team_a_2", uniqueTerraformName(used, "team.a"))
+	require.Equal(t, "_1st", uniqueTerraformName(used, "1st"))
+}
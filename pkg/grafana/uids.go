@@ -0,0 +1,24 @@
+package grafana
+
+import (
+	"regexp"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+// maxUIDLength is the length limit of the UIDs of Grafana resources
+const maxUIDLength = 40
+
+var invalidUIDChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+var uidConstraints = grizzly.UIDConstraints{
+	MaxLength: maxUIDLength,
+	Pattern:   regexp.MustCompile(`^[a-zA-Z0-9_-]+$`),
+}
+
+// setSpecUID changes the UID of a resource identified by the uid field of its spec
+func setSpecUID(resource grizzly.Resource, uid string) grizzly.Resource {
+	resource.SetMetadata("name", uid)
+	resource.SetSpecString("uid", uid)
+	return resource
+}
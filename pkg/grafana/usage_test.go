@@ -0,0 +1,135 @@
+package grafana
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStale(t *testing.T) {
+	recent := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	old := time.Now().Add(-200 * 24 * time.Hour).Truncate(time.Second)
+	lastViews := map[string]int64{"recent": recent.UnixMilli(), "old": old.UnixMilli(), "never": 0}
+	lastUpdates := map[string]time.Time{"recent": recent, "old": old, "never": old}
+
+	newServer := func(t *testing.T, tracksViews bool, moved map[string]int64) *httptest.Server {
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /api/search/sorting", func(w http.ResponseWriter, r *http.Request) {
+			options := `{"name": "alpha-asc"}`
+			if tracksViews {
+				options += `, {"name": "viewed-recently"}`
+			}
+			_, _ = w.Write([]byte(`{"sortOptions": [` + options + `]}`))
+		})
+		mux.HandleFunc("GET /api/search", func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "viewed-recently", r.URL.Query().Get("sort"))
+			hits := []map[string]any{{"uid": "unmanaged", "sortMeta": 0}}
+			for uid, view := range lastViews {
+				hits = append(hits, map[string]any{"uid": uid, "sortMeta": view})
+			}
+			_ = json.NewEncoder(w).Encode(hits)
+		})
+		mux.HandleFunc("GET /api/dashboards/uid/{uid}", func(w http.ResponseWriter, r *http.Request) {
+			uid := r.PathValue("uid")
+			updated, ok := lastUpdates[uid]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = fmt.Fprintf(w, `{"dashboard": {"uid": %q, "title": %q}, "meta": {"folderUid": "general", "updated": %q}}`, uid, uid, updated.Format(time.RFC3339))
+		})
+		mux.HandleFunc("GET /api/folders/archive", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"id": 7, "uid": "archive", "title": "Archive"}`))
+		})
+		mux.HandleFunc("POST /api/dashboards/db", func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Dashboard map[string]any `json:"dashboard"`
+				FolderID  int64          `json:"folderId"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			moved[body.Dashboard["uid"].(string)] = body.FolderID
+			_, _ = w.Write([]byte(`{"status": "success"}`))
+		})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			mux.ServeHTTP(w, r)
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	parse := func(t *testing.T, registry grizzly.Registry) (string, grizzly.Resources) {
+		dir := t.TempDir()
+		for _, uid := range []string{"recent", "old", "never", "missing"} {
+			content := fmt.Sprintf("apiVersion: grizzly.grafana.com/v1alpha1\nkind: Dashboard\nmetadata:\n    folder: general\n    name: %s\nspec:\n    title: %s\n    uid: %s\n", uid, uid, uid)
+			require.NoError(t, os.WriteFile(filepath.Join(dir, uid+".yaml"), []byte(content), 0644))
+		}
+		resources, err := grizzly.DefaultParser(registry, nil, nil).Parse(dir, grizzly.ParserOptions{})
+		require.NoError(t, err)
+		return dir, resources
+	}
+
+	t.Run("dashboards not viewed recently are stale", func(t *testing.T) {
+		moved := map[string]int64{}
+		server := newServer(t, true, moved)
+		registry := grizzly.NewRegistry([]grizzly.Provider{NewProvider(&config.GrafanaConfig{URL: server.URL})})
+		_, resources := parse(t, registry)
+
+		stale, err := grizzly.Stale(registry, resources, grizzly.StaleOptions{MaxAge: 90 * 24 * time.Hour})
+		require.NoError(t, err)
+		require.Len(t, stale, 2)
+		require.Equal(t, "Dashboard.never", stale[0].Resource)
+		require.Nil(t, stale[0].LastUsed)
+		require.Equal(t, "Dashboard.old", stale[1].Resource)
+		require.True(t, old.Equal(*stale[1].LastUsed))
+		require.Equal(t, grizzly.UsageViewed, stale[1].Basis)
+		require.Empty(t, moved)
+
+		candidates, err := grizzly.FormatStale(stale, "candidates")
+		require.NoError(t, err)
+		require.Equal(t, "Dashboard.never\nDashboard.old\n", string(candidates))
+	})
+
+	t.Run("without usage insights, dashboards not updated recently are stale", func(t *testing.T) {
+		moved := map[string]int64{}
+		server := newServer(t, false, moved)
+		registry := grizzly.NewRegistry([]grizzly.Provider{NewProvider(&config.GrafanaConfig{URL: server.URL})})
+		_, resources := parse(t, registry)
+
+		stale, err := grizzly.Stale(registry, resources, grizzly.StaleOptions{MaxAge: 90 * 24 * time.Hour})
+		require.NoError(t, err)
+		require.Len(t, stale, 2)
+		require.Equal(t, "Dashboard.never", stale[0].Resource)
+		require.Equal(t, grizzly.UsageUpdated, stale[0].Basis)
+		require.Equal(t, "Dashboard.old", stale[1].Resource)
+	})
+
+	t.Run("stale dashboards are archived", func(t *testing.T) {
+		moved := map[string]int64{}
+		server := newServer(t, true, moved)
+		registry := grizzly.NewRegistry([]grizzly.Provider{NewProvider(&config.GrafanaConfig{URL: server.URL})})
+		dir, resources := parse(t, registry)
+
+		stale, err := grizzly.Stale(registry, resources, grizzly.StaleOptions{MaxAge: 90 * 24 * time.Hour, ArchiveFolder: "archive"})
+		require.NoError(t, err)
+		require.Len(t, stale, 2)
+		require.True(t, stale[0].Archived)
+		require.Equal(t, map[string]int64{"never": 7, "old": 7}, moved)
+
+		content, err := os.ReadFile(filepath.Join(dir, "old.yaml"))
+		require.NoError(t, err)
+		require.Contains(t, string(content), "folder: archive")
+		content, err = os.ReadFile(filepath.Join(dir, "recent.yaml"))
+		require.NoError(t, err)
+		require.Contains(t, string(content), "folder: general")
+	})
+}
@@ -51,6 +51,15 @@ func structToMap(s interface{}) (map[string]interface{}, error) {
 	return result, nil
 }
 
+// proxyHTTPClient returns a client sending requests through the transport of
+// the Grafana provider
+func proxyHTTPClient(provider grizzly.Provider) (*http.Client, error) {
+	if p, ok := provider.(*Provider); ok {
+		return p.newHTTPClient()
+	}
+	return httputils.NewHTTPClient()
+}
+
 func authenticateAndProxyHandler(s grizzly.Server, provider grizzly.Provider) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Add("Content-Type", "text/html")
@@ -75,7 +84,7 @@ func authenticateAndProxyHandler(s grizzly.Server, provider grizzly.Provider) ht
 
 		req.Header.Set("User-Agent", s.UserAgent)
 
-		client, err := httputils.NewHTTPClient()
+		client, err := proxyHTTPClient(provider)
 		if err != nil {
 			httputils.Error(w, http.StatusText(http.StatusInternalServerError), err, http.StatusInternalServerError)
 			return
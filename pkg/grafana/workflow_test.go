@@ -0,0 +1,816 @@
+package grafana
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func newDashboardTestServer(t *testing.T, dashboards map[string]map[string]any) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/dashboards/home", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"dashboard": {}}`))
+	})
+	mux.HandleFunc("GET /api/dashboards/uid/{uid}", func(w http.ResponseWriter, r *http.Request) {
+		dashboard, ok := dashboards[r.PathValue("uid")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Dashboard not found"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"dashboard": dashboard,
+			"meta":      map[string]any{"folderUid": "general"},
+		})
+	})
+	mux.HandleFunc("GET /api/search", func(w http.ResponseWriter, r *http.Request) {
+		results := []map[string]any{}
+		if r.URL.Query().Get("page") == "1" {
+			for uid, dashboard := range dashboards {
+				results = append(results, map[string]any{"uid": uid, "title": dashboard["title"], "type": "dash-db"})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(results)
+	})
+	mux.HandleFunc("DELETE /api/dashboards/uid/{uid}", func(w http.ResponseWriter, r *http.Request) {
+		delete(dashboards, r.PathValue("uid"))
+		_, _ = w.Write([]byte(`{"title": "deleted"}`))
+	})
+	mux.HandleFunc("POST /api/dashboards/db", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Dashboard map[string]any `json:"dashboard"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		dashboards[body.Dashboard["uid"].(string)] = body.Dashboard
+		_, _ = w.Write([]byte(`{"status": "success"}`))
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDelete(t *testing.T) {
+	dashboards := map[string]map[string]any{
+		"existing": {"uid": "existing", "title": "Existing"},
+	}
+	server := newDashboardTestServer(t, dashboards)
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL})
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+
+	existing, err := grizzly.NewResource(provider.APIVersion(), DashboardKind, "existing", map[string]any{"uid": "existing", "title": "Existing"})
+	require.NoError(t, err)
+	missing, err := grizzly.NewResource(provider.APIVersion(), DashboardKind, "missing", map[string]any{"uid": "missing", "title": "Missing"})
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	recorder := grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText)
+	err = grizzly.Delete(registry, grizzly.NewResources(existing, missing), false, recorder)
+	require.NoError(t, err)
+	require.NotContains(t, dashboards, "existing")
+	require.Equal(t, 1, recorder.Summary().EventCounts[grizzly.ResourceDeleted])
+	require.Equal(t, 1, recorder.Summary().EventCounts[grizzly.ResourceNotFound])
+}
+
+func TestApplyApproval(t *testing.T) {
+	dashboards := map[string]map[string]any{
+		"existing":  {"uid": "existing", "title": "Existing"},
+		"unchanged": {"uid": "unchanged", "title": "Unchanged"},
+	}
+	server := newDashboardTestServer(t, dashboards)
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL})
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+
+	var resources []grizzly.Resource
+	for uid, title := range map[string]string{"existing": "Changed", "unchanged": "Unchanged", "added": "Added"} {
+		resource, err := grizzly.NewResource(provider.APIVersion(), DashboardKind, uid, map[string]any{"uid": uid, "title": title})
+		require.NoError(t, err)
+		resource.SetMetadata("folder", "general")
+		resources = append(resources, resource)
+	}
+
+	var planned []string
+	opts := grizzly.ApplyOptions{
+		ProtectedKinds: []string{"dashboard"},
+		Approve: func(changes []grizzly.PlannedChange) error {
+			planned = nil
+			for _, change := range changes {
+				planned = append(planned, change.String())
+			}
+			return grizzly.ErrNotApproved
+		},
+	}
+	var out bytes.Buffer
+	err := grizzly.Apply(registry, grizzly.NewResources(resources...), opts, grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText))
+	require.ErrorIs(t, err, grizzly.ErrNotApproved)
+	require.ElementsMatch(t, []string{"Dashboard.existing will be updated", "Dashboard.added will be added"}, planned)
+	require.Equal(t, "Existing", dashboards["existing"]["title"], "nothing is applied unless approved")
+	require.NotContains(t, dashboards, "added")
+
+	t.Run("changes to other kinds aren't approved", func(t *testing.T) {
+		opts.ProtectedKinds = []string{"AlertRuleGroup"}
+
+		err := grizzly.Apply(registry, grizzly.NewResources(resources...), opts, grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText))
+		require.NoError(t, err)
+		require.Equal(t, "Changed", dashboards["existing"]["title"])
+		require.Contains(t, dashboards, "added")
+	})
+
+	t.Run("resources to prune are approved whatever their kind", func(t *testing.T) {
+		opts.Prune = []grizzly.ResourceRef{grizzly.NewResourceRef(DashboardKind, "unchanged")}
+
+		err := grizzly.Apply(registry, grizzly.NewResources(resources...), opts, grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText))
+		require.ErrorIs(t, err, grizzly.ErrNotApproved)
+		require.Equal(t, []string{"Dashboard.unchanged will be deleted"}, planned)
+	})
+}
+
+func TestApplyConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	added := map[string]bool{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/dashboards/uid/{uid}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message": "Dashboard not found"}`))
+	})
+	mux.HandleFunc("POST /api/dashboards/db", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Dashboard map[string]any `json:"dashboard"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		added[body.Dashboard["uid"].(string)] = true
+		mu.Unlock()
+
+		_, _ = w.Write([]byte(`{"status": "success"}`))
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL})
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+
+	resources := grizzly.NewResources()
+	for i := range 6 {
+		uid := fmt.Sprintf("dashboard-%d", i)
+		resource, err := grizzly.NewResource(provider.APIVersion(), DashboardKind, uid, map[string]any{"uid": uid, "title": uid})
+		require.NoError(t, err)
+		resource.SetMetadata("folder", "general")
+		resources.Add(resource)
+	}
+
+	var out bytes.Buffer
+	err := grizzly.Apply(registry, resources, grizzly.ApplyOptions{Concurrency: 3}, grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText))
+	require.NoError(t, err)
+	require.Len(t, added, 6)
+	require.Greater(t, maxInFlight, 1, "dashboards are applied concurrently")
+	require.LessOrEqual(t, maxInFlight, 3, "no more dashboards than the concurrency are applied at the same time")
+	require.Equal(t, 6, strings.Count(out.String(), "added"))
+}
+
+func TestApplyDryRun(t *testing.T) {
+	objects := map[string]k8sObject{
+		"unchanged": {Kind: DashboardKind, Metadata: k8sObjectMeta{Name: "unchanged"}, Spec: map[string]any{"title": "Unchanged"}},
+		"changed":   {Kind: DashboardKind, Metadata: k8sObjectMeta{Name: "changed"}, Spec: map[string]any{"title": "Before"}},
+	}
+	var writes []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /apis", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"groups": [{"name": "dashboard.grafana.app", "versions": [{"version": "v1beta1"}], "preferredVersion": {"version": "v1beta1"}}]}`))
+	})
+	mux.HandleFunc("GET /api/frontend/settings", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"namespace": "default"}`))
+	})
+	base := "/apis/dashboard.grafana.app/v1beta1/namespaces/default/dashboards"
+	mux.HandleFunc("GET "+base+"/{name}", func(w http.ResponseWriter, r *http.Request) {
+		object, ok := objects[r.PathValue("name")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"kind": "Status", "message": "not found", "reason": "NotFound"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(object)
+	})
+	save := func(w http.ResponseWriter, r *http.Request) {
+		writes = append(writes, r.Method+" "+r.URL.Query().Get("dryRun"))
+		var object k8sObject
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&object))
+		if object.Spec["title"] == "Rejected" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"kind": "Status", "message": "spec.panels: invalid panel", "reason": "Invalid"}`))
+			return
+		}
+		if r.URL.Query().Get("dryRun") == "" {
+			objects[object.Metadata.Name] = object
+		}
+		_ = json.NewEncoder(w).Encode(object)
+	}
+	mux.HandleFunc("POST "+base, save)
+	mux.HandleFunc("PUT "+base+"/{name}", save)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL})
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+
+	resources := grizzly.NewResources()
+	for uid, title := range map[string]string{"unchanged": "Unchanged", "changed": "After", "added": "Added"} {
+		resource, err := grizzly.NewResource(provider.APIVersion(), DashboardKind, uid, map[string]any{"uid": uid, "title": title})
+		require.NoError(t, err)
+		resource.SetMetadata("folder", "general")
+		resources.Add(resource)
+	}
+
+	var out bytes.Buffer
+	recorder := grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText)
+	err := grizzly.Apply(registry, resources, grizzly.ApplyOptions{DryRun: true}, recorder)
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "Dashboard.unchanged unchanged")
+	require.Contains(t, out.String(), "Dashboard.changed would be updated")
+	require.Contains(t, out.String(), "Dashboard.added would be added")
+	require.ElementsMatch(t, []string{"PUT All", "POST All"}, writes, "changes are validated with dry-run requests")
+	require.Equal(t, "Before", objects["changed"].Spec["title"], "nothing is persisted")
+	require.NotContains(t, objects, "added", "nothing is persisted")
+
+	t.Run("rejected resources fail", func(t *testing.T) {
+		rejected, err := grizzly.NewResource(provider.APIVersion(), DashboardKind, "rejected", map[string]any{"uid": "rejected", "title": "Rejected"})
+		require.NoError(t, err)
+		rejected.SetMetadata("folder", "general")
+
+		var out bytes.Buffer
+		err = grizzly.Apply(registry, grizzly.NewResources(rejected), grizzly.ApplyOptions{DryRun: true}, grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText))
+		require.ErrorContains(t, err, "invalid panel")
+		require.Contains(t, out.String(), "Dashboard.rejected failed")
+	})
+}
+
+func TestApplyPlan(t *testing.T) {
+	dashboards := map[string]map[string]any{
+		"existing":  {"uid": "existing", "title": "Existing"},
+		"unchanged": {"uid": "unchanged", "title": "Unchanged"},
+	}
+	server := newDashboardTestServer(t, dashboards)
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL})
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+
+	resources := grizzly.NewResources()
+	for uid, title := range map[string]string{"existing": "Changed", "unchanged": "Unchanged", "added": "Added"} {
+		resource, err := grizzly.NewResource(provider.APIVersion(), DashboardKind, uid, map[string]any{"uid": uid, "title": title})
+		require.NoError(t, err)
+		resource.SetMetadata("folder", "general")
+		resources.Add(resource)
+	}
+
+	plan, err := grizzly.MakePlan(registry, resources, "default")
+	require.NoError(t, err)
+	var planned []string
+	for _, change := range plan.Changes {
+		planned = append(planned, change.PlannedChange().String())
+	}
+	require.ElementsMatch(t, []string{"Dashboard.existing will be updated", "Dashboard.added will be added"}, planned)
+	require.NotContains(t, dashboards, "added", "nothing is applied by planning")
+
+	file := filepath.Join(t.TempDir(), "plan.json")
+	require.NoError(t, grizzly.WritePlan(file, plan))
+	require.True(t, grizzly.IsPlanFile(file))
+	read, err := grizzly.ReadPlan(file)
+	require.NoError(t, err)
+
+	t.Run("plans aren't applied once remote resources changed", func(t *testing.T) {
+		dashboards["existing"] = map[string]any{"uid": "existing", "title": "Changed elsewhere"}
+		t.Cleanup(func() {
+			dashboards["existing"] = map[string]any{"uid": "existing", "title": "Existing"}
+		})
+
+		var out bytes.Buffer
+		err := grizzly.ApplyPlan(registry, *read, grizzly.ApplyOptions{}, grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText))
+		require.ErrorIs(t, err, grizzly.ErrPlanOutdated)
+		require.ErrorContains(t, err, "Dashboard.existing was updated")
+		require.NotContains(t, dashboards, "added", "nothing is applied")
+	})
+
+	var out bytes.Buffer
+	recorder := grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText)
+	require.NoError(t, grizzly.ApplyPlan(registry, *read, grizzly.ApplyOptions{}, recorder))
+	require.Equal(t, "Changed", dashboards["existing"]["title"])
+	require.Equal(t, "Added", dashboards["added"]["title"])
+	require.Equal(t, 1, recorder.Summary().EventCounts[grizzly.ResourceAdded])
+
+	t.Run("applied plans are outdated", func(t *testing.T) {
+		err := grizzly.ApplyPlan(registry, *read, grizzly.ApplyOptions{}, grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText))
+		require.ErrorIs(t, err, grizzly.ErrPlanOutdated)
+	})
+}
+
+func TestEdit(t *testing.T) {
+	dashboards := map[string]map[string]any{
+		"existing": {"uid": "existing", "title": "Existing"},
+	}
+	server := newDashboardTestServer(t, dashboards)
+	registry := grizzly.NewRegistry([]grizzly.Provider{NewProvider(&config.GrafanaConfig{URL: server.URL})})
+	parser := grizzly.DefaultParser(registry, nil, nil)
+	// edited files are kept when applying them fails
+	t.Setenv("TMPDIR", t.TempDir())
+
+	editor := func(t *testing.T, script string) string {
+		path := filepath.Join(t.TempDir(), "editor.sh")
+		require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755))
+		return path
+	}
+
+	t.Run("unchanged resources aren't applied", func(t *testing.T) {
+		var out bytes.Buffer
+		recorder := grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText)
+		err := grizzly.Edit(registry, parser, "Dashboard.existing", grizzly.EditOptions{Editor: editor(t, "true"), OutputFormat: "yaml"}, recorder)
+		require.NoError(t, err)
+		require.Empty(t, out.String())
+	})
+
+	t.Run("edited resources are applied", func(t *testing.T) {
+		var out bytes.Buffer
+		recorder := grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText)
+		err := grizzly.Edit(registry, parser, "Dashboard.existing", grizzly.EditOptions{Editor: editor(t, `sed -i 's/title: Existing/title: Edited/' "$1"`), OutputFormat: "yaml"}, recorder)
+		require.NoError(t, err)
+		require.Equal(t, 1, recorder.Summary().EventCounts[grizzly.ResourceUpdated])
+		require.Equal(t, "Edited", dashboards["existing"]["title"])
+	})
+
+	t.Run("invalid resources are reopened with the problems found", func(t *testing.T) {
+		opened := filepath.Join(t.TempDir(), "opened")
+		script := `if [ -f "` + opened + `" ]; then
+  grep -q "title in body is required" "$1" && sed -i 's/title: ""/title: Fixed/' "$1"
+else
+  touch "` + opened + `"
+  sed -i 's/title: Edited/title: ""/' "$1"
+fi`
+
+		var out bytes.Buffer
+		recorder := grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText)
+		err := grizzly.Edit(registry, parser, "Dashboard.existing", grizzly.EditOptions{Editor: editor(t, script), OutputFormat: "yaml"}, recorder)
+		require.NoError(t, err)
+		require.Equal(t, "Fixed", dashboards["existing"]["title"])
+	})
+
+	t.Run("saving invalid resources unchanged cancels the edit", func(t *testing.T) {
+		var out bytes.Buffer
+		recorder := grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText)
+		err := grizzly.Edit(registry, parser, "Dashboard.existing", grizzly.EditOptions{Editor: editor(t, `sed -i 's/title: Fixed/title: ""/' "$1"`), OutputFormat: "yaml"}, recorder)
+		require.ErrorContains(t, err, "edit cancelled")
+		require.ErrorContains(t, err, "title in body is required")
+		require.Equal(t, "Fixed", dashboards["existing"]["title"])
+	})
+
+	t.Run("renaming resources is refused", func(t *testing.T) {
+		var out bytes.Buffer
+		recorder := grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText)
+		err := grizzly.Edit(registry, parser, "Dashboard.existing", grizzly.EditOptions{Editor: editor(t, `sed -i 's/existing/renamed/g' "$1"`), OutputFormat: "yaml"}, recorder)
+		require.ErrorContains(t, err, "isn't supported by edit")
+		require.NotContains(t, dashboards, "renamed")
+		require.ErrorContains(t, err, "Changes were kept in")
+	})
+}
+
+func TestServeRemoteChanges(t *testing.T) {
+	dashboards := map[string]map[string]any{
+		"existing": {"uid": "existing", "title": "Existing"},
+	}
+	grafana := newDashboardTestServer(t, dashboards)
+	provider := NewProvider(&config.GrafanaConfig{URL: grafana.URL})
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+
+	local, err := grizzly.NewResource(provider.APIVersion(), DashboardKind, "existing", map[string]any{"uid": "existing", "title": "Local"})
+	require.NoError(t, err)
+	local.SetMetadata("folder", "general")
+	server, err := grizzly.NewGrizzlyServer(registry, "", 8080)
+	require.NoError(t, err)
+	server.Resources.Add(local)
+	router, err := server.Router()
+	require.NoError(t, err)
+
+	get := func(path string) (int, string) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code, rec.Body.String()
+	}
+
+	_, body := get("/grizzly/Dashboard/existing/remote")
+	require.JSONEq(t, `{"changed": false}`, body)
+	_, body = get("/grizzly/Dashboard/existing/remote")
+	require.JSONEq(t, `{"changed": false}`, body)
+
+	dashboards["existing"]["title"] = "Changed remotely"
+	_, body = get("/grizzly/Dashboard/existing/remote")
+	require.JSONEq(t, `{"changed": true, "diffURL": "/grizzly/Dashboard/existing/diff"}`, body)
+
+	code, body := get("/grizzly/Dashboard/existing/diff")
+	require.Equal(t, http.StatusOK, code)
+	require.Contains(t, body, `<span class="removed">-    title: Changed remotely`)
+	require.Contains(t, body, `<span class="added">&#43;    title: Local`)
+
+	_, body = get("/grizzly/Dashboard/missing/remote")
+	require.Contains(t, body, `"error":"Dashboard.missing not found locally"`)
+}
+
+func TestPruneFanOuts(t *testing.T) {
+	dashboards := map[string]map[string]any{
+		"golden-team-a": {"uid": "golden-team-a", "title": "Team A"},
+		"golden-team-b": {"uid": "golden-team-b", "title": "Team B"},
+		"golden-team-c": {"uid": "golden-team-c", "title": "Team C"},
+	}
+	server := newDashboardTestServer(t, dashboards)
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL})
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+	stateFile := filepath.Join(t.TempDir(), "fan-out.json")
+
+	instances := func(names ...string) grizzly.Resources {
+		var list []any
+		for _, name := range names {
+			list = append(list, map[string]any{"name": name})
+		}
+		fanOut, err := grizzly.NewResource(provider.APIVersion(), grizzly.FanOutKind, "golden", map[string]any{
+			"template": map[string]any{
+				"kind":     DashboardKind,
+				"metadata": map[string]any{"name": "golden", "folder": "general"},
+				"spec":     map[string]any{"title": "${fanout.name}"},
+			},
+			"instances": list,
+		})
+		require.NoError(t, err)
+		resources, err := grizzly.ExpandFanOuts(registry, grizzly.NewResources(fanOut))
+		require.NoError(t, err)
+		return resources
+	}
+
+	require.NoError(t, grizzly.PruneFanOuts(registry, instances("team-a", "team-b", "team-c"), nil, stateFile, "prod", false))
+
+	require.NoError(t, grizzly.PruneFanOuts(registry, instances("team-a"), nil, stateFile, "prod", false))
+	require.Len(t, dashboards, 3, "removed instances are only deleted with prune")
+
+	undeclared, err := grizzly.UndeclaredFanOuts(registry, instances("team-a"), []string{"Dashboard/*-team-b"}, stateFile, "prod")
+	require.NoError(t, err)
+	require.Equal(t, []grizzly.ResourceRef{grizzly.NewResourceRef(DashboardKind, "golden-team-b")}, undeclared, "the instances to prune are the ones matching targets")
+
+	require.NoError(t, grizzly.PruneFanOuts(registry, instances("team-a"), []string{"Dashboard/*-team-b"}, stateFile, "prod", true))
+	require.NotContains(t, dashboards, "golden-team-b")
+	require.Contains(t, dashboards, "golden-team-c", "instances not matching targets are kept")
+
+	require.NoError(t, grizzly.PruneFanOuts(registry, instances("team-a"), nil, stateFile, "staging", true))
+	require.Contains(t, dashboards, "golden-team-c", "instances are recorded per context")
+
+	require.NoError(t, grizzly.PruneFanOuts(registry, instances("team-a"), nil, stateFile, "prod", true))
+	require.NotContains(t, dashboards, "golden-team-c")
+	require.Contains(t, dashboards, "golden-team-a")
+
+	state, err := os.ReadFile(stateFile)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"prod": {"golden": [{"kind": "Dashboard", "name": "golden-team-a"}]},
+		"staging": {"golden": [{"kind": "Dashboard", "name": "golden-team-a"}]}
+	}`, string(state))
+}
+
+func TestServeMockedDatasources(t *testing.T) {
+	queried := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/dashboards/home", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"dashboard": {}}`))
+	})
+	mux.HandleFunc("POST /api/ds/query", func(w http.ResponseWriter, r *http.Request) {
+		queried++
+		_, _ = w.Write([]byte(`{"results": {"A": {"status": 200, "frames": [{"schema": {"name": "up"}, "data": {"values": [[1], [1]]}}]}}}`))
+	})
+	grafana := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mux.ServeHTTP(w, r)
+	}))
+	provider := NewProvider(&config.GrafanaConfig{URL: grafana.URL})
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+	mocksFile := filepath.Join(t.TempDir(), "mocks.yaml")
+
+	query := func(server *grizzly.Server, refID, expr string) map[string]any {
+		router, err := server.Router()
+		require.NoError(t, err)
+		body := fmt.Sprintf(`{"queries": [{"refId": %q, "datasource": {"uid": "prom", "type": "prometheus"}, "expr": %q}]}`, refID, expr)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/ds/query", strings.NewReader(body)))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var response map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		return response["results"].(map[string]any)[refID].(map[string]any)
+	}
+
+	server, err := grizzly.NewGrizzlyServer(registry, "", 8080)
+	require.NoError(t, err)
+	server.MockDatasources(mocksFile, true)
+	recorded := query(server, "A", "up")
+	require.Len(t, recorded["frames"], 1)
+	require.Equal(t, 1, queried)
+
+	grafana.Close()
+	server.MockDatasources(mocksFile, false)
+	mocked := query(server, "A", "up")
+	require.Equal(t, recorded["frames"], mocked["frames"])
+	require.Equal(t, 1, queried, "mocked queries don't reach datasources")
+
+	unknown := query(server, "B", "rate(http_requests_total[5m])")
+	require.Equal(t, []any{}, unknown["frames"], "unknown queries return no data")
+
+	content, err := os.ReadFile(mocksFile)
+	require.NoError(t, err)
+	require.Contains(t, string(content), "datasource: prom\n")
+	require.Contains(t, string(content), "query: up\n")
+}
+
+func TestPruneApplied(t *testing.T) {
+	dashboards := map[string]map[string]any{
+		"overview": {"uid": "overview", "title": "Overview"},
+		"latency":  {"uid": "latency", "title": "Latency"},
+		"errors":   {"uid": "errors", "title": "Errors"},
+		"billing":  {"uid": "billing", "title": "Billing"},
+	}
+	server := newDashboardTestServer(t, dashboards)
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL})
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+	stateFile := filepath.Join(t.TempDir(), "applied.json")
+
+	applied := func(names ...string) grizzly.Resources {
+		resources := grizzly.NewResources()
+		for _, name := range names {
+			dashboard, err := grizzly.NewResource(provider.APIVersion(), DashboardKind, name, map[string]any{"uid": name, "title": name})
+			require.NoError(t, err)
+			dashboard.SetMetadata("folder", "general")
+			resources.Add(dashboard)
+		}
+		return resources
+	}
+
+	require.NoError(t, grizzly.PruneApplied(registry, applied("overview", "latency", "errors"), nil, "dashboards/", stateFile, "prod", false))
+	require.NoError(t, grizzly.PruneApplied(registry, applied("billing"), nil, "./billing", stateFile, "prod", false))
+
+	require.NoError(t, grizzly.PruneApplied(registry, applied("overview"), nil, "dashboards", stateFile, "prod", false))
+	require.Len(t, dashboards, 4, "removed resources are only deleted with prune")
+
+	require.NoError(t, grizzly.PruneApplied(registry, applied("overview"), []string{"Dashboard/latency"}, "dashboards", stateFile, "prod", true))
+	require.NotContains(t, dashboards, "latency")
+	require.Contains(t, dashboards, "errors", "resources not matching targets are kept")
+
+	require.NoError(t, grizzly.PruneApplied(registry, applied("overview"), nil, "dashboards", stateFile, "prod", true))
+	require.NotContains(t, dashboards, "errors")
+	require.Contains(t, dashboards, "overview")
+	require.Contains(t, dashboards, "billing", "resources applied from other paths are kept")
+
+	state, err := os.ReadFile(stateFile)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"prod": {
+			"dashboards": [{"kind": "Dashboard", "name": "overview"}],
+			"billing": [{"kind": "Dashboard", "name": "billing"}]
+		}
+	}`, string(state))
+}
+
+func TestPullFilenames(t *testing.T) {
+	dashboards := map[string]map[string]any{
+		"Ops":      {"uid": "Ops", "title": "Ops"},
+		"ops":      {"uid": "ops", "title": "ops"},
+		"café:ops": {"uid": "café:ops", "title": "Café"},
+	}
+	server := newDashboardTestServer(t, dashboards)
+	registry := grizzly.NewRegistry([]grizzly.Provider{NewProvider(&config.GrafanaConfig{URL: server.URL})})
+	registry.Filenames = grizzly.FilenamesEscape
+
+	dir := t.TempDir()
+	opts := grizzly.PullOptions{OutputFormat: "yaml", Targets: []string{"Dashboard/*"}, ContinueOnError: true}
+	var out bytes.Buffer
+	err := grizzly.Pull(registry, dir, opts, grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText))
+	require.ErrorContains(t, err, "shares the file")
+
+	_, err = os.Stat(filepath.Join(dir, "dashboards", "general", "dashboard-café%3Aops.yaml"))
+	require.NoError(t, err)
+
+	manifest, err := grizzly.ReadFilenameManifest(dir)
+	require.NoError(t, err)
+	require.Len(t, manifest, 2)
+	require.Equal(t, grizzly.ManifestEntry{Kind: DashboardKind, UID: "café:ops"}, manifest["dashboards/general/dashboard-café%3Aops.yaml"])
+
+	resources, err := grizzly.DefaultParser(registry, nil, nil).Parse(dir, grizzly.ParserOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 2, resources.Len())
+}
+
+func TestPullPrune(t *testing.T) {
+	dashboards := map[string]map[string]any{
+		"overview": {"uid": "overview", "title": "Overview"},
+		"latency":  {"uid": "latency", "title": "Latency"},
+		"errors":   {"uid": "errors", "title": "Errors"},
+	}
+	server := newDashboardTestServer(t, dashboards)
+	dir := t.TempDir()
+	pull := func(pathTemplate string, opts grizzly.PullOptions) string {
+		registry := grizzly.NewRegistry([]grizzly.Provider{NewProvider(&config.GrafanaConfig{URL: server.URL})})
+		registry.PathTemplate = pathTemplate
+		opts.OutputFormat = "yaml"
+		var out bytes.Buffer
+		require.NoError(t, grizzly.Pull(registry, dir, opts, grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText)))
+		return out.String()
+	}
+	exists := func(path string) bool {
+		_, err := os.Stat(filepath.Join(dir, filepath.FromSlash(path)))
+		return err == nil
+	}
+
+	pull("", grizzly.PullOptions{Targets: []string{"Dashboard"}})
+	require.True(t, exists("dashboards/general/dashboard-latency.yaml"))
+
+	delete(dashboards, "latency")
+	pull("", grizzly.PullOptions{Targets: []string{"Dashboard"}})
+	require.True(t, exists("dashboards/general/dashboard-latency.yaml"), "files are only pruned with prune")
+
+	pull("", grizzly.PullOptions{Targets: []string{"Dashboard/overview"}, Prune: true})
+	require.True(t, exists("dashboards/general/dashboard-latency.yaml"), "files of resources not targeted are kept")
+
+	out := pull("", grizzly.PullOptions{Targets: []string{"Dashboard"}, Prune: true})
+	require.Contains(t, out, "Dashboard.latency pruned")
+	require.False(t, exists("dashboards/general/dashboard-latency.yaml"))
+	require.True(t, exists("dashboards/general/dashboard-errors.yaml"))
+
+	pull("{{ .kind }}/{{ .folder }}/{{ .name }}.{{ .extension }}", grizzly.PullOptions{Targets: []string{"Dashboard"}, Prune: true})
+	require.True(t, exists("Dashboard/general/overview.yaml"))
+	require.True(t, exists("Dashboard/general/errors.yaml"))
+	require.False(t, exists("dashboards"), "files pulled to another path are pruned, along with their directories")
+
+	manifest, err := grizzly.ReadFilenameManifest(dir)
+	require.NoError(t, err)
+	require.Equal(t, map[string]grizzly.ManifestEntry{
+		"Dashboard/general/overview.yaml": {Kind: DashboardKind, UID: "overview"},
+		"Dashboard/general/errors.yaml":   {Kind: DashboardKind, UID: "errors"},
+	}, manifest)
+}
+
+func TestApplyBackup(t *testing.T) {
+	dashboards := map[string]map[string]any{
+		"existing":  {"uid": "existing", "title": "Existing"},
+		"unchanged": {"uid": "unchanged", "title": "Unchanged"},
+	}
+	server := newDashboardTestServer(t, dashboards)
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL})
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+
+	var resources []grizzly.Resource
+	for uid, title := range map[string]string{"existing": "Changed", "unchanged": "Unchanged", "added": "Added"} {
+		resource, err := grizzly.NewResource(provider.APIVersion(), DashboardKind, uid, map[string]any{"uid": uid, "title": title})
+		require.NoError(t, err)
+		resource.SetMetadata("folder", "general")
+		resources = append(resources, resource)
+	}
+
+	file := filepath.Join(t.TempDir(), "backup.json")
+	opts := grizzly.ApplyOptions{Backup: grizzly.NewBackup(file, "default")}
+	var out bytes.Buffer
+	require.NoError(t, grizzly.Apply(registry, grizzly.NewResources(resources...), opts, grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText)))
+	require.NoError(t, opts.Backup.Save())
+	require.Equal(t, "Changed", dashboards["existing"]["title"])
+	require.Contains(t, dashboards, "added")
+
+	backup, err := grizzly.ReadBackup(file)
+	require.NoError(t, err)
+	require.Equal(t, "default", backup.Context)
+	var planned []string
+	for _, change := range grizzly.RollbackChanges(backup) {
+		planned = append(planned, change.String())
+	}
+	require.Equal(t, []string{"Dashboard.existing will be updated", "Dashboard.added will be deleted"}, planned, "unchanged resources aren't backed up")
+
+	out.Reset()
+	recorder := grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText)
+	require.NoError(t, grizzly.Rollback(registry, backup, grizzly.ApplyOptions{}, recorder))
+	require.Equal(t, "Existing", dashboards["existing"]["title"])
+	require.NotContains(t, dashboards, "added")
+	require.Contains(t, dashboards, "unchanged")
+	require.Equal(t, 1, recorder.Summary().EventCounts[grizzly.ResourceUpdated])
+	require.Equal(t, 1, recorder.Summary().EventCounts[grizzly.ResourceDeleted])
+}
+
+func TestDiffRemoteCache(t *testing.T) {
+	var mu sync.Mutex
+	versions := map[string]int{"overview": 1, "latency": 3}
+	ids := map[string]int{"overview": 1, "latency": 2}
+	folders := map[string]string{"overview": "", "latency": ""}
+	retrieved := map[string]int{}
+	versionsAsked := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/dashboards/uid/{uid}/versions", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		versionsAsked++
+		uid := r.PathValue("uid")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"versions": []any{map[string]any{"dashboardId": ids[uid], "version": versions[uid]}},
+		})
+	})
+	mux.HandleFunc("GET /api/search", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		uid := r.URL.Query().Get("dashboardUIDs")
+		_ = json.NewEncoder(w).Encode([]any{map[string]any{"uid": uid, "folderUid": folders[uid]}})
+	})
+	mux.HandleFunc("GET /api/dashboards/uid/{uid}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		uid := r.PathValue("uid")
+		retrieved[uid]++
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"dashboard": map[string]any{"id": ids[uid], "uid": uid, "title": uid, "version": versions[uid]},
+			"meta":      map[string]any{"folderUid": folders[uid]},
+		})
+	})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mux.ServeHTTP(w, r)
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	newRegistry := func(url string) grizzly.Registry {
+		registry := grizzly.NewRegistry([]grizzly.Provider{NewProvider(&config.GrafanaConfig{URL: url})})
+		registry.FetchConcurrency = 4
+		registry.RemoteCache = grizzly.NewRemoteCache(cacheDir, "test")
+		return registry
+	}
+	registry := newRegistry(server.URL)
+
+	var resources []grizzly.Resource
+	for _, uid := range []string{"overview", "latency"} {
+		resource, err := grizzly.NewResource(registry.Providers[0].APIVersion(), DashboardKind, uid, map[string]any{"uid": uid, "title": uid})
+		require.NoError(t, err)
+		resource.SetMetadata("folder", "general")
+		resources = append(resources, resource)
+	}
+	diff := func(registry grizzly.Registry) {
+		_, err := grizzly.DiffReport(registry, grizzly.NewResources(resources...), grizzly.DiffReportOptions{OutputFormat: "yaml"})
+		require.NoError(t, err)
+	}
+
+	diff(registry)
+	require.Zero(t, versionsAsked, "the version of dashboards that aren't cached isn't asked for")
+	diff(registry)
+	require.Equal(t, map[string]int{"overview": 1, "latency": 1}, retrieved, "unchanged dashboards are read from the cache")
+	require.Equal(t, 2, versionsAsked)
+
+	mu.Lock()
+	versions["latency"]++
+	mu.Unlock()
+	diff(registry)
+	require.Equal(t, map[string]int{"overview": 1, "latency": 2}, retrieved, "changed dashboards are retrieved again")
+
+	mu.Lock()
+	versions["overview"], ids["overview"] = 1, 3
+	mu.Unlock()
+	diff(registry)
+	require.Equal(t, map[string]int{"overview": 2, "latency": 2}, retrieved, "dashboards deleted and created again are retrieved again, though their version starts over")
+
+	mu.Lock()
+	folders["latency"] = "ops"
+	mu.Unlock()
+	diff(registry)
+	require.Equal(t, map[string]int{"overview": 2, "latency": 3}, retrieved, "dashboards moved to another folder are retrieved again, though moving them doesn't save a version")
+	diff(registry)
+	require.Equal(t, map[string]int{"overview": 2, "latency": 3}, retrieved, "moved dashboards are cached in their new folder")
+
+	other := httptest.NewServer(handler)
+	defer other.Close()
+	diff(newRegistry(other.URL))
+	require.Equal(t, map[string]int{"overview": 3, "latency": 4}, retrieved, "dashboards of another Grafana instance aren't read from the cache")
+}
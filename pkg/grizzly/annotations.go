@@ -0,0 +1,80 @@
+package grizzly
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// AnnotationsDisabled is the annotation target that keeps annotations from being exposed remotely
+const AnnotationsDisabled = "none"
+
+// AnnotationHandler describes a handler that can expose the annotations of
+// resources (ex: the git commit they were applied from) in the remote system,
+// where they are visible to its users
+type AnnotationHandler interface {
+	// AnnotationTargets lists where annotations can be exposed in the spec of resources, the first being the default
+	AnnotationTargets() []string
+	// ExposeAnnotations writes annotations to the given target in the spec of a resource
+	ExposeAnnotations(resource *Resource, annotations map[string]string, target string) error
+	// ReadAnnotations reads back the annotations exposed at the given target of a remote resource
+	ReadAnnotations(resource Resource, target string) map[string]string
+}
+
+// ExposeAnnotations returns a copy of a resource with its annotations moved
+// from its metadata to its spec, where the target configured for its kind in
+// the registry makes them visible remotely. Annotations are dropped for kinds
+// that can't expose them, as they are only known to Grizzly.
+func ExposeAnnotations(registry Registry, resource Resource) (Resource, error) {
+	if !resource.HasMetadata("annotations") {
+		return resource, nil
+	}
+
+	annotations := resource.Annotations()
+	exposed := resource.DeepCopy()
+	delete(exposed.metadata(), "annotations")
+	if len(annotations) == 0 {
+		return exposed, nil
+	}
+
+	handler, err := registry.GetHandler(resource.Kind())
+	if err != nil {
+		return resource, err
+	}
+	annotationHandler, ok := handler.(AnnotationHandler)
+	if !ok {
+		log.Debugf("%s can't expose annotations remotely, ignoring them", resource.Kind())
+		return exposed, nil
+	}
+
+	target, err := annotationTarget(registry, annotationHandler, resource.Kind())
+	if err != nil {
+		return resource, err
+	}
+	if target == AnnotationsDisabled {
+		return exposed, nil
+	}
+
+	if err := annotationHandler.ExposeAnnotations(&exposed, annotations, target); err != nil {
+		return resource, err
+	}
+	return exposed, nil
+}
+
+// annotationTarget returns where the annotations of resources of the given
+// kind are exposed: as configured in the registry, or else the default target of their handler
+func annotationTarget(registry Registry, handler AnnotationHandler, kind string) (string, error) {
+	targets := handler.AnnotationTargets()
+	target := targets[0]
+	for configuredKind, configured := range registry.AnnotationTargets {
+		if strings.EqualFold(configuredKind, kind) {
+			target = configured
+		}
+	}
+	if target != AnnotationsDisabled && !slices.Contains(targets, target) {
+		return "", fmt.Errorf("%s annotations can't be exposed as %s, expected one of: %s, %s", kind, target, strings.Join(targets, ", "), AnnotationsDisabled)
+	}
+	return target, nil
+}
@@ -0,0 +1,89 @@
+package grizzly
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotApproved is returned when changes aren't approved
+var ErrNotApproved = errors.New("changes were not approved")
+
+// PlannedChange is a change to a remote resource, waiting to be approved
+type PlannedChange struct {
+	// Type is one of ResourceAdded, ResourceUpdated or ResourceDeleted
+	Type     EventType
+	Resource Resource
+}
+
+func (change PlannedChange) String() string {
+	return fmt.Sprintf("%s will be %s", change.Resource.Ref(), change.Type.HumanReadable)
+}
+
+// Approver is asked to approve changes before they are made, and returns an
+// error, such as ErrNotApproved, when they aren't
+type Approver func(changes []PlannedChange) error
+
+// IsProtectedKind returns whether changes to a kind need to be approved
+func IsProtectedKind(kind string, protectedKinds []string) bool {
+	for _, protected := range protectedKinds {
+		if strings.EqualFold(kind, protected) {
+			return true
+		}
+	}
+	return false
+}
+
+// DeletionChanges returns the changes deleting resources makes
+func DeletionChanges(resources Resources) []PlannedChange {
+	changes := make([]PlannedChange, 0, resources.Len())
+	for _, resource := range resources.AsList() {
+		changes = append(changes, PlannedChange{Type: ResourceDeleted, Resource: resource})
+	}
+	return changes
+}
+
+// approveApply asks for the changes applying resources of protected kinds
+// makes, and the deletion of the resources to prune, to be approved, if there
+// are any
+func approveApply(registry Registry, resources Resources, opts ApplyOptions) error {
+	var changes []PlannedChange
+	for _, resource := range resources.AsList() {
+		if !IsProtectedKind(resource.Kind(), opts.ProtectedKinds) {
+			continue
+		}
+		handler, err := registry.GetHandler(resource.Kind())
+		if err != nil {
+			return err
+		}
+		change, err := planResource(registry, handler, resource, opts)
+		if err != nil {
+			return ClassifyError(handler, fmt.Errorf("planning changes to %s: %w", resource.Ref(), err))
+		}
+		if change.Type != ResourceNotChanged {
+			changes = append(changes, PlannedChange{Type: change.Type, Resource: resource})
+		}
+	}
+	for _, ref := range opts.Prune {
+		resource, err := prunedResource(registry, ref)
+		if err != nil {
+			return err
+		}
+		changes = append(changes, PlannedChange{Type: ResourceDeleted, Resource: resource})
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+	return opts.Approve(changes)
+}
+
+// prunedResource returns a resource standing for a resource to prune, which
+// is only known by its reference
+func prunedResource(registry Registry, ref ResourceRef) (Resource, error) {
+	handler, err := registry.GetHandler(ref.Kind)
+	if err != nil {
+		return Resource{}, err
+	}
+	return NewResource(handler.APIVersion(), ref.Kind, ref.Name, map[string]any{})
+}
@@ -0,0 +1,125 @@
+package grizzly
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// Backup records the remote versions of the resources an apply overwrites,
+// and the resources it adds, so that the apply can be undone by Rollback
+type Backup struct {
+	Context   string    `json:"context"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Updated are the remote versions of the resources updated, as they were
+	// before being overwritten
+	Updated []map[string]any `json:"updated"`
+	// Added are the resources added, which rolling back deletes
+	Added []map[string]any `json:"added"`
+
+	path string
+	// mu guards the resources recorded, as resources can be applied concurrently
+	mu sync.Mutex
+}
+
+// NewBackup returns a backup of the resources applied to a context, written
+// to the given file by Save
+func NewBackup(path, context string) *Backup {
+	return &Backup{
+		Context:   context,
+		CreatedAt: time.Now().UTC(),
+		Updated:   []map[string]any{},
+		Added:     []map[string]any{},
+		path:      path,
+	}
+}
+
+// record records the change applying a resource made
+func (b *Backup) record(change resourceChange) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch change.Type {
+	case ResourceAdded:
+		b.Added = append(b.Added, change.Local.DeepCopy().Body)
+	case ResourceUpdated:
+		b.Updated = append(b.Updated, change.Remote.DeepCopy().Body)
+	}
+}
+
+// Save writes the backup to its file, as JSON
+func (b *Backup) Save() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	content, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFile(b.path, append(content, '\n'))
+}
+
+// ReadBackup reads a backup written by Backup.Save
+func ReadBackup(file string) (*Backup, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	backup := Backup{path: file}
+	if err := json.Unmarshal(content, &backup); err != nil {
+		return nil, fmt.Errorf("reading backup %s: %w", file, err)
+	}
+	for _, bodies := range [][]map[string]any{backup.Updated, backup.Added} {
+		for _, body := range bodies {
+			resource := Resource{Body: body}
+			if resource.Kind() == "" || resource.Name() == "" {
+				return nil, fmt.Errorf("invalid resource in backup %s: missing kind or name", file)
+			}
+		}
+	}
+	return &backup, nil
+}
+
+// RollbackChanges returns the changes rolling a backup back makes
+func RollbackChanges(backup *Backup) []PlannedChange {
+	changes := make([]PlannedChange, 0, len(backup.Updated)+len(backup.Added))
+	for _, body := range backup.Updated {
+		changes = append(changes, PlannedChange{Type: ResourceUpdated, Resource: Resource{Body: body}})
+	}
+	for _, body := range backup.Added {
+		changes = append(changes, PlannedChange{Type: ResourceDeleted, Resource: Resource{Body: body}})
+	}
+	return changes
+}
+
+// Rollback undoes the apply recorded by a backup: the resources it updated
+// are applied again as they were, and the resources it added are deleted, in
+// the reverse order they were added, so that resources are deleted before
+// those they depend on
+func Rollback(registry Registry, backup *Backup, opts ApplyOptions, eventsRecorder EventsRecorder) error {
+	var finalErr error
+
+	updated := NewResources()
+	for _, body := range backup.Updated {
+		updated.Add(Resource{Body: body})
+	}
+	if updated.Len() > 0 {
+		if err := Apply(registry, registry.Sort(updated), opts, eventsRecorder); err != nil {
+			finalErr = multierror.Append(finalErr, err)
+			if !opts.ContinueOnError {
+				return finalErr
+			}
+		}
+	}
+
+	added := NewResources()
+	for i := len(backup.Added) - 1; i >= 0; i-- {
+		added.Add(Resource{Body: backup.Added[i]})
+	}
+	if err := Delete(registry, added, opts.ContinueOnError, eventsRecorder); err != nil {
+		finalErr = multierror.Append(finalErr, err)
+	}
+	return finalErr
+}
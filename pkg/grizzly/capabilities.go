@@ -0,0 +1,159 @@
+package grizzly
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
+	"gopkg.in/yaml.v3"
+)
+
+// Operation names an action Grizzly can perform on the resources of a handler
+type Operation string
+
+const (
+	OperationList    Operation = "list"
+	OperationGet     Operation = "get"
+	OperationApply   Operation = "apply"
+	OperationDelete  Operation = "delete"
+	OperationPreview Operation = "preview"
+	OperationServe   Operation = "serve"
+	OperationStale   Operation = "stale"
+)
+
+// DeleteHandler describes a handler that has the ability to remove a resource
+// from the remote endpoint
+type DeleteHandler interface {
+	// Delete removes a resource from the endpoint
+	Delete(resource Resource) error
+}
+
+// HandlerOperations returns the operations supported by a handler. Operations
+// provided by optional interfaces are only included if the handler implements them.
+func HandlerOperations(handler Handler) []Operation {
+	operations := []Operation{OperationList, OperationGet, OperationApply}
+
+	if _, ok := handler.(DeleteHandler); ok {
+		operations = append(operations, OperationDelete)
+	}
+	_, canSnapshot := handler.(SnapshotHandler)
+	_, canPreview := handler.(PreviewHandler)
+	if canSnapshot || canPreview {
+		operations = append(operations, OperationPreview)
+	}
+	if _, ok := handler.(ProxyConfiguratorProvider); ok {
+		operations = append(operations, OperationServe)
+	}
+	if _, ok := handler.(UsageHandler); ok {
+		operations = append(operations, OperationStale)
+	}
+
+	return operations
+}
+
+// ProviderInfo describes a registered provider and the kinds it exposes
+type ProviderInfo struct {
+	Name       string `yaml:"name" json:"name"`
+	APIVersion string `yaml:"apiVersion" json:"apiVersion"`
+	// Active indicates that the provider is configured in the current context
+	Active       bool          `yaml:"active" json:"active"`
+	ActiveReason string        `yaml:"activeReason,omitempty" json:"activeReason,omitempty"`
+	Handlers     []HandlerInfo `yaml:"handlers" json:"handlers"`
+}
+
+// HandlerInfo describes a kind and the operations supported for it
+type HandlerInfo struct {
+	Kind       string      `yaml:"kind" json:"kind"`
+	Operations []Operation `yaml:"operations" json:"operations"`
+}
+
+// Describe returns information about each provider in the registry. Only the
+// configuration of providers is checked, no request is made to their endpoints.
+func (r *Registry) Describe() []ProviderInfo {
+	infos := make([]ProviderInfo, 0, len(r.Providers))
+
+	for _, provider := range r.Providers {
+		info := ProviderInfo{
+			Name:       provider.Name(),
+			APIVersion: provider.APIVersion(),
+			Active:     true,
+		}
+		if err := provider.Validate(); err != nil {
+			info.Active = false
+			info.ActiveReason = err.Error()
+		}
+
+		for _, handler := range provider.GetHandlers() {
+			info.Handlers = append(info.Handlers, HandlerInfo{
+				Kind:       handler.Kind(),
+				Operations: HandlerOperations(handler),
+			})
+		}
+		infos = append(infos, info)
+	}
+
+	return infos
+}
+
+// Providers outputs the registered providers, the kinds they expose and
+// the operations supported for each of them.
+func Providers(registry Registry, format string) error {
+	infos := registry.Describe()
+
+	var output []byte
+	var err error
+	switch format {
+	case formatYAML:
+		output, err = yaml.Marshal(infos)
+	case formatJSON:
+		output, err = json.MarshalIndent(infos, "", "  ")
+	case formatDefault:
+		output, err = providersTable(infos, false)
+	case formatWide:
+		output, err = providersTable(infos, true)
+	default:
+		return fmt.Errorf("unknown format %s", format)
+	}
+	if err != nil {
+		return err
+	}
+	notifier.Print(string(output))
+	return nil
+}
+
+func providersTable(infos []ProviderInfo, wide bool) ([]byte, error) {
+	var out bytes.Buffer
+	w := tabwriter.NewWriter(&out, 0, 0, 4, ' ', 0)
+
+	f := "%s\t%s\t%s\t%s\t%s\n"
+	header := []any{"PROVIDER", "API VERSION", "KIND", "ACTIVE", "OPERATIONS"}
+	if wide {
+		f = "%s\t%s\t%s\t%s\t%s\t%s\n"
+		header = append(header, "REASON")
+	}
+	fmt.Fprintf(w, f, header...)
+
+	for _, info := range infos {
+		active := "yes"
+		if !info.Active {
+			active = "no"
+		}
+		for _, handler := range info.Handlers {
+			operations := make([]string, 0, len(handler.Operations))
+			for _, operation := range handler.Operations {
+				operations = append(operations, string(operation))
+			}
+
+			row := []any{info.Name, info.APIVersion, handler.Kind, active, strings.Join(operations, ",")}
+			if wide {
+				row = append(row, info.ActiveReason)
+			}
+			fmt.Fprintf(w, f, row...)
+		}
+	}
+	err := w.Flush()
+	return out.Bytes(), err
+}
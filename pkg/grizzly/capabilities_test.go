@@ -0,0 +1,87 @@
+package grizzly_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grafana"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/mimir"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestDescribeProviders(t *testing.T) {
+	registry := grizzly.NewRegistry([]grizzly.Provider{
+		grafana.NewProvider(&config.GrafanaConfig{URL: "http://localhost:3000"}),
+		mimir.NewProvider(&config.MimirConfig{}),
+	})
+
+	infos := registry.Describe()
+	require.Len(t, infos, 2)
+
+	grafanaInfo, mimirInfo := infos[0], infos[1]
+	require.Equal(t, "Grafana", grafanaInfo.Name)
+	require.True(t, grafanaInfo.Active)
+	require.Empty(t, grafanaInfo.ActiveReason)
+	require.False(t, mimirInfo.Active, "providers without configuration aren't active")
+	require.Equal(t, "mimir address is not set", mimirInfo.ActiveReason)
+
+	for _, info := range infos {
+		for _, handler := range info.Handlers {
+			require.Equal(t, []grizzly.Operation{grizzly.OperationList, grizzly.OperationGet, grizzly.OperationApply}, handler.Operations[:3], handler.Kind)
+
+			_, deletes := registry.Handlers[handler.Kind].(grizzly.DeleteHandler)
+			require.Equal(t, deletes, containsOperation(handler.Operations, grizzly.OperationDelete), handler.Kind)
+		}
+	}
+
+	dashboard := handlerInfo(t, infos, grafana.DashboardKind)
+	require.Contains(t, dashboard.Operations, grizzly.OperationDelete)
+	require.Contains(t, dashboard.Operations, grizzly.OperationPreview)
+	require.Contains(t, dashboard.Operations, grizzly.OperationStale)
+}
+
+func TestDescribeProvidersFormats(t *testing.T) {
+	registry := grizzly.NewRegistry([]grizzly.Provider{mimir.NewProvider(&config.MimirConfig{})})
+	infos := registry.Describe()
+
+	content, err := json.Marshal(infos)
+	require.NoError(t, err)
+	var decoded []map[string]any
+	require.NoError(t, json.Unmarshal(content, &decoded))
+	require.Equal(t, "Mimir", decoded[0]["name"])
+	require.Equal(t, false, decoded[0]["active"])
+	require.Equal(t, "mimir address is not set", decoded[0]["activeReason"])
+	require.NotEmpty(t, decoded[0]["handlers"].([]any)[0].(map[string]any)["operations"])
+
+	content, err = yaml.Marshal(infos)
+	require.NoError(t, err)
+	require.Contains(t, string(content), "apiVersion: grizzly.grafana.com/v1alpha1")
+	require.Contains(t, string(content), "activeReason: mimir address is not set")
+
+	require.EqualError(t, grizzly.Providers(registry, "xml"), "unknown format xml")
+}
+
+func handlerInfo(t *testing.T, infos []grizzly.ProviderInfo, kind string) grizzly.HandlerInfo {
+	t.Helper()
+	for _, info := range infos {
+		for _, handler := range info.Handlers {
+			if handler.Kind == kind {
+				return handler
+			}
+		}
+	}
+	require.Failf(t, "kind not described", kind)
+	return grizzly.HandlerInfo{}
+}
+
+func containsOperation(operations []grizzly.Operation, operation grizzly.Operation) bool {
+	for _, candidate := range operations {
+		if candidate == operation {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package grizzly
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// ContextRecorder records events as having happened in a context, so that the
+// events of resources applied to several contexts can be told apart
+type ContextRecorder struct {
+	EventsRecorder
+	context string
+}
+
+func NewContextRecorder(recorder EventsRecorder, context string) *ContextRecorder {
+	return &ContextRecorder{
+		EventsRecorder: recorder,
+		context:        context,
+	}
+}
+
+func (r *ContextRecorder) Record(event Event) {
+	event.Context = r.context
+	r.EventsRecorder.Record(event)
+}
+
+// ContextResult is the outcome of applying resources to one of several contexts
+type ContextResult struct {
+	Context   string `json:"context"`
+	Added     int    `json:"added"`
+	Updated   int    `json:"updated"`
+	Unchanged int    `json:"unchanged"`
+	Failed    int    `json:"failed"`
+	// Error is the first line of the error applying resources failed with, if any
+	Error string `json:"error,omitempty"`
+}
+
+// NewContextResult counts the resources of each outcome in the summary of the
+// events recorded while applying resources to a context. Resources that would
+// be added or updated by a dry run are counted as added or updated.
+func NewContextResult(context string, summary Summary, err error) ContextResult {
+	result := ContextResult{
+		Context:   context,
+		Added:     summary.EventCounts[ResourceAdded] + summary.EventCounts[ResourceWouldBeAdded],
+		Updated:   summary.EventCounts[ResourceUpdated] + summary.EventCounts[ResourceWouldBeUpdated],
+		Unchanged: summary.EventCounts[ResourceNotChanged],
+		Failed:    summary.EventCounts[ResourceFailure],
+	}
+	if err != nil {
+		result.Error = firstErrorLine(err)
+	}
+	return result
+}
+
+// firstErrorLine describes an error in a line, with the first of the errors
+// aggregated in it
+func firstErrorLine(err error) string {
+	var merr *multierror.Error
+	if errors.As(err, &merr) && len(merr.Errors) > 0 {
+		line := firstErrorLine(merr.Errors[0])
+		if len(merr.Errors) > 1 {
+			line += fmt.Sprintf(" (and %d more)", len(merr.Errors)-1)
+		}
+		return line
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(err.Error()), "\n")
+	return line
+}
+
+// FormatContextResults renders the results of applying resources to several
+// contexts as a table with a row per context, or as JSON lines with the json
+// format
+func FormatContextResults(results []ContextResult, format string) ([]byte, error) {
+	if format == formatJSON {
+		var out bytes.Buffer
+		for _, result := range results {
+			content, err := json.Marshal(result)
+			if err != nil {
+				return nil, err
+			}
+			out.Write(append(content, '\n'))
+		}
+		return out.Bytes(), nil
+	}
+
+	var out bytes.Buffer
+	w := tabwriter.NewWriter(&out, 0, 0, 4, ' ', 0)
+
+	f := "%s\t%v\t%v\t%v\t%v\t%s\n"
+	fmt.Fprintf(w, f, "CONTEXT", "ADDED", "UPDATED", "UNCHANGED", "FAILED", "RESULT")
+	for _, result := range results {
+		outcome := "ok"
+		if result.Error != "" {
+			outcome = result.Error
+		}
+		fmt.Fprintf(w, f, result.Context, result.Added, result.Updated, result.Unchanged, result.Failed, outcome)
+	}
+	err := w.Flush()
+	return out.Bytes(), err
+}
@@ -0,0 +1,43 @@
+package grizzly_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextResults(t *testing.T) {
+	var out bytes.Buffer
+	recorder := grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText)
+	production := grizzly.NewContextRecorder(recorder, "prod-eu")
+	production.Record(grizzly.Event{Type: grizzly.ResourceAdded, ResourceRef: "Dashboard.golden"})
+	production.Record(grizzly.Event{Type: grizzly.ResourceNotChanged, ResourceRef: "Dashboard.other"})
+	require.Equal(t, "[prod-eu] Dashboard.golden added\n[prod-eu] Dashboard.other unchanged\n", out.String())
+	require.Contains(t, grizzly.EventToJSON(grizzly.Event{Type: grizzly.ResourceAdded, ResourceRef: "Dashboard.golden", Context: "prod-eu"}), `"context":"prod-eu"`)
+
+	results := []grizzly.ContextResult{
+		grizzly.NewContextResult("prod-eu", recorder.Summary(), nil),
+		grizzly.NewContextResult("prod-us", grizzly.Summary{}, errors.New("grafana: unreachable\nmore details")),
+	}
+	require.Equal(t, grizzly.ContextResult{Context: "prod-eu", Added: 1, Unchanged: 1}, results[0])
+	require.Equal(t, "grafana: unreachable", results[1].Error)
+	aggregated := multierror.Append(errors.New("Dashboard.golden: forbidden"), errors.New("Dashboard.other: forbidden"))
+	require.Equal(t, "Dashboard.golden: forbidden (and 1 more)", grizzly.NewContextResult("stg", grizzly.Summary{}, aggregated).Error)
+
+	table, err := grizzly.FormatContextResults(results, "text")
+	require.NoError(t, err)
+	require.Equal(t, `CONTEXT    ADDED    UPDATED    UNCHANGED    FAILED    RESULT
+prod-eu    1        0          1            0         ok
+prod-us    0        0          0            0         grafana: unreachable
+`, string(table))
+
+	lines, err := grizzly.FormatContextResults(results, "json")
+	require.NoError(t, err)
+	require.Equal(t, `{"context":"prod-eu","added":1,"updated":0,"unchanged":1,"failed":0}
+{"context":"prod-us","added":0,"updated":0,"unchanged":0,"failed":0,"error":"grafana: unreachable"}
+`, string(lines))
+}
@@ -0,0 +1,284 @@
+package grizzly
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// LegacyKindsHandler describes a handler that used to be known under other
+// kind names. Resources using one of those names are converted on parse.
+type LegacyKindsHandler interface {
+	// LegacyKinds returns the kind names previously used for this handler
+	LegacyKinds() []string
+}
+
+// Conversion describes a change made to bring a resource up to date with the
+// current envelope format.
+type Conversion struct {
+	Field string
+	From  string
+	To    string
+}
+
+func (c Conversion) String() string {
+	if c.From == "" {
+		return fmt.Sprintf("%s set to %q", c.Field, c.To)
+	}
+	return fmt.Sprintf("%s %q converted to %q", c.Field, c.From, c.To)
+}
+
+// convertEnvelope computes the conversions needed for an envelope with the
+// given kind and apiVersion.
+func (r *Registry) convertEnvelope(kind, apiVersion string) []Conversion {
+	var conversions []Conversion
+
+	if current, ok := r.LegacyKinds[kind]; ok {
+		conversions = append(conversions, Conversion{Field: "kind", From: kind, To: current})
+		kind = current
+	}
+
+	handler, ok := r.Handlers[kind]
+	if !ok {
+		return conversions
+	}
+
+	if apiVersion != handler.APIVersion() {
+		conversions = append(conversions, Conversion{Field: "apiVersion", From: apiVersion, To: handler.APIVersion()})
+	}
+
+	return conversions
+}
+
+// ConvertLegacy updates an enveloped resource written for a previous envelope
+// version or using a legacy kind name. The conversions applied are returned.
+func (r *Registry) ConvertLegacy(m map[string]any) []Conversion {
+	kind, _ := m["kind"].(string)
+	apiVersion, _ := m["apiVersion"].(string)
+
+	conversions := r.convertEnvelope(kind, apiVersion)
+	for _, conversion := range conversions {
+		m[conversion.Field] = conversion.To
+	}
+
+	return conversions
+}
+
+func (r *Registry) warnConversions(m map[string]any, conversions []Conversion, source Source) {
+	if len(conversions) == 0 {
+		return
+	}
+
+	name := ""
+	if metadata, ok := m["metadata"].(map[string]any); ok {
+		name, _ = metadata["name"].(string)
+	}
+
+	for _, conversion := range conversions {
+		log.WithField("file", source.Path).Warnf("%s.%s: %s. Run `grr convert` to update the file", m["kind"], name, conversion)
+	}
+}
+
+// Convert rewrites the JSON and YAML resource files found at the given path so
+// that they no longer rely on legacy kinds or envelope versions.
+func Convert(registry Registry, resourcePath string) error {
+	stat, err := os.Stat(resourcePath)
+	if err != nil {
+		return err
+	}
+
+	if !stat.IsDir() {
+		return convertFile(registry, resourcePath)
+	}
+
+	return filepath.WalkDir(resourcePath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || isFilenameManifest(path) {
+			return nil
+		}
+		return convertFile(registry, path)
+	})
+}
+
+func convertFile(registry Registry, file string) error {
+	var content []byte
+	var conversions []Conversion
+	var err error
+
+	switch filepath.Ext(file) {
+	case ".json":
+		content, conversions, err = convertJSONFile(registry, file)
+	case ".yaml", ".yml":
+		content, conversions, err = convertYAMLFile(registry, file)
+	case ".jsonnet", ".libsonnet":
+		notifier.Warn(notifier.SimpleString(file), "skipped: jsonnet sources must be converted by hand")
+		return nil
+	default:
+		return nil
+	}
+	if err != nil {
+		return ParseError{File: file, Err: err}
+	}
+
+	if len(conversions) == 0 {
+		notifier.NoChanges(notifier.SimpleString(file))
+		return nil
+	}
+
+	if err := WriteFile(file, content); err != nil {
+		return err
+	}
+
+	for _, conversion := range conversions {
+		notifier.Info(notifier.SimpleString(file), conversion.String())
+	}
+	return nil
+}
+
+func convertJSONFile(registry Registry, file string) ([]byte, []Conversion, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var data any
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, nil, err
+	}
+
+	conversions := convertAny(registry, data)
+	if len(conversions) == 0 {
+		return nil, nil, nil
+	}
+
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+	return append(content, '\n'), conversions, nil
+}
+
+func convertAny(registry Registry, data any) []Conversion {
+	var conversions []Conversion
+
+	switch v := data.(type) {
+	case []any:
+		for _, elem := range v {
+			conversions = append(conversions, convertAny(registry, elem)...)
+		}
+	case map[string]any:
+		if DetectEnvelope(v) {
+			return registry.ConvertLegacy(v)
+		}
+		for _, elem := range v {
+			conversions = append(conversions, convertAny(registry, elem)...)
+		}
+	}
+
+	return conversions
+}
+
+// convertYAMLFile works on YAML nodes rather than decoded values so that key
+// ordering and comments survive the conversion.
+func convertYAMLFile(registry Registry, file string) ([]byte, []Conversion, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	var documents []*yaml.Node
+	var conversions []Conversion
+
+	decoder := yaml.NewDecoder(f)
+	for {
+		var document yaml.Node
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		conversions = append(conversions, convertYAMLNode(registry, &document)...)
+		documents = append(documents, &document)
+	}
+
+	if len(conversions) == 0 {
+		return nil, nil, nil
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	for _, document := range documents {
+		if err := encoder.Encode(document); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	return out.Bytes(), conversions, nil
+}
+
+func convertYAMLNode(registry Registry, node *yaml.Node) []Conversion {
+	var conversions []Conversion
+
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			conversions = append(conversions, convertYAMLNode(registry, child)...)
+		}
+	case yaml.MappingNode:
+		fields := map[string]*yaml.Node{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			fields[node.Content[i].Value] = node.Content[i+1]
+		}
+
+		_, hasMetadata := fields["metadata"]
+		_, hasSpec := fields["spec"]
+		kindNode, hasKind := fields["kind"]
+		if !hasKind || !hasMetadata || !hasSpec {
+			for i := 1; i < len(node.Content); i += 2 {
+				conversions = append(conversions, convertYAMLNode(registry, node.Content[i])...)
+			}
+			return conversions
+		}
+
+		apiVersion := ""
+		if apiVersionNode, ok := fields["apiVersion"]; ok {
+			apiVersion = apiVersionNode.Value
+		}
+
+		conversions = registry.convertEnvelope(kindNode.Value, apiVersion)
+		for _, conversion := range conversions {
+			if conversion.Field == "kind" {
+				kindNode.Value = conversion.To
+				continue
+			}
+			if apiVersionNode, ok := fields["apiVersion"]; ok {
+				apiVersionNode.Value = conversion.To
+				continue
+			}
+			node.Content = append([]*yaml.Node{
+				{Kind: yaml.ScalarNode, Value: "apiVersion"},
+				{Kind: yaml.ScalarNode, Value: conversion.To},
+			}, node.Content...)
+		}
+	}
+
+	return conversions
+}
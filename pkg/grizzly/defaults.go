@@ -0,0 +1,99 @@
+package grizzly
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"gopkg.in/yaml.v3"
+)
+
+// FieldDefault is a field set on resources when they don't set it themselves
+type FieldDefault struct {
+	// Path locates the field, from the metadata or spec of resources. A []
+	// suffix selects every item of a list. Ex: spec.rules[].labels.env
+	Path []string
+	// Value is the default value, parsed as YAML for spec fields. Ex: false, prod, [a, b]
+	Value any
+}
+
+// ParseFieldDefault parses a field default, written <path>=<value>. Ex: spec.editable=false
+func ParseFieldDefault(fieldDefault string) (FieldDefault, error) {
+	path, value, found := strings.Cut(fieldDefault, "=")
+	if !found || path == "" {
+		return FieldDefault{}, fmt.Errorf("invalid default %q, expected <path>=<value>", fieldDefault)
+	}
+	parsed := FieldDefault{Path: strings.Split(strings.TrimSpace(path), ".")}
+	if len(parsed.Path) < 2 || (parsed.Path[0] != "metadata" && parsed.Path[0] != "spec") {
+		return FieldDefault{}, fmt.Errorf("invalid default %q, the path should start with metadata. or spec.", fieldDefault)
+	}
+	if parsed.Path[0] == "metadata" {
+		// metadata values are strings, even when they look like numbers
+		parsed.Value = strings.TrimSpace(value)
+		return parsed, nil
+	}
+	if err := yaml.Unmarshal([]byte(value), &parsed.Value); err != nil {
+		return FieldDefault{}, fmt.Errorf("invalid default %q: %w", fieldDefault, err)
+	}
+	return parsed, nil
+}
+
+// ApplyDefaults sets, on every resource of a kind, the fields configured for
+// that kind and not already set. Kinds are matched case-insensitively, as
+// configuration keys are lowercased.
+func ApplyDefaults(resources Resources, defaults map[string][]string) error {
+	var finalErr error
+
+	parsed := map[string][]FieldDefault{}
+	for kind, fieldDefaults := range defaults {
+		for _, fieldDefault := range fieldDefaults {
+			field, err := ParseFieldDefault(fieldDefault)
+			if err != nil {
+				finalErr = multierror.Append(finalErr, fmt.Errorf("defaults of %s: %w", kind, err))
+				continue
+			}
+			parsed[strings.ToLower(kind)] = append(parsed[strings.ToLower(kind)], field)
+		}
+	}
+	if finalErr != nil {
+		return finalErr
+	}
+
+	for _, resource := range resources.AsList() {
+		for _, field := range parsed[strings.ToLower(resource.Kind())] {
+			setDefault(resource.Body, field.Path, field.Value)
+		}
+	}
+	return nil
+}
+
+func setDefault(object map[string]any, path []string, value any) {
+	key, isList := strings.CutSuffix(path[0], "[]")
+
+	if isList {
+		items, _ := object[key].([]any)
+		for _, item := range items {
+			if itemObject, ok := item.(map[string]any); ok && len(path) > 1 {
+				setDefault(itemObject, path[1:], value)
+			}
+		}
+		return
+	}
+
+	if len(path) == 1 {
+		if _, set := object[key]; !set {
+			object[key] = deepCopyValue(value)
+		}
+		return
+	}
+
+	child, ok := object[key].(map[string]any)
+	if !ok {
+		if object[key] != nil {
+			return
+		}
+		child = map[string]any{}
+		object[key] = child
+	}
+	setDefault(child, path[1:], value)
+}
@@ -0,0 +1,57 @@
+package grizzly_test
+
+import (
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFieldDefault(t *testing.T) {
+	parsed, err := grizzly.ParseFieldDefault("spec.editable=false")
+	require.NoError(t, err)
+	require.Equal(t, grizzly.FieldDefault{Path: []string{"spec", "editable"}, Value: false}, parsed)
+
+	parsed, err = grizzly.ParseFieldDefault("metadata.folder=123")
+	require.NoError(t, err)
+	require.Equal(t, "123", parsed.Value, "metadata values are strings")
+
+	for _, invalid := range []string{"spec.editable", "=false", "editable=false", "status.x=1"} {
+		_, err := grizzly.ParseFieldDefault(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestApplyDefaults(t *testing.T) {
+	dashboard, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", "Dashboard", "api", map[string]any{"title": "API", "editable": true})
+	require.NoError(t, err)
+	rules, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", "AlertRuleGroup", "folder.group", map[string]any{
+		"rules": []any{
+			map[string]any{"title": "A"},
+			map[string]any{"title": "B", "labels": map[string]any{"env": "dev", "team": "db"}},
+		},
+	})
+	require.NoError(t, err)
+	resources := grizzly.NewResources(dashboard, rules)
+
+	err = grizzly.ApplyDefaults(resources, map[string][]string{
+		"dashboard":      {"spec.editable=false", "spec.graphTooltip=1", "metadata.folder=shared"},
+		"alertrulegroup": {"spec.rules[].labels.env=prod"},
+	})
+	require.NoError(t, err)
+
+	dashboard, _ = resources.Find(dashboard.Ref())
+	require.Equal(t, map[string]any{"title": "API", "editable": true, "graphTooltip": 1}, dashboard.Spec(), "fields already set are kept")
+	require.Equal(t, "shared", dashboard.GetMetadata("folder"))
+
+	rules, _ = resources.Find(rules.Ref())
+	require.Equal(t, []any{
+		map[string]any{"title": "A", "labels": map[string]any{"env": "prod"}},
+		map[string]any{"title": "B", "labels": map[string]any{"env": "dev", "team": "db"}},
+	}, rules.GetSpecValue("rules"))
+
+	t.Run("invalid defaults are reported", func(t *testing.T) {
+		err := grizzly.ApplyDefaults(resources, map[string][]string{"dashboard": {"editable"}})
+		require.ErrorContains(t, err, "defaults of dashboard")
+	})
+}
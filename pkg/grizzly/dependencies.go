@@ -0,0 +1,140 @@
+package grizzly
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DependsOn returns the resources a resource is applied after, given in its
+// dependsOn metadata as Kind/name or Kind.name references, such as
+// DashboardFolder/team-a
+func (r *Resource) DependsOn() ([]ResourceRef, error) {
+	if !r.HasMetadata("dependsOn") {
+		return nil, nil
+	}
+	values, ok := r.metadata()["dependsOn"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("the dependsOn metadata of %s should be a list of Kind/name references", r.Ref())
+	}
+
+	refs := make([]ResourceRef, 0, len(values))
+	for _, value := range values {
+		reference, _ := value.(string)
+		separator := "."
+		if strings.Contains(reference, "/") {
+			separator = "/"
+		}
+		kind, name, _ := strings.Cut(reference, separator)
+		if kind == "" || name == "" {
+			return nil, fmt.Errorf("%s depends on %v, which isn't a Kind/name reference", r.Ref(), value)
+		}
+		refs = append(refs, NewResourceRef(kind, name))
+	}
+	return refs, nil
+}
+
+// CheckDependencies reports the resources whose dependencies can't be
+// followed, because they are malformed or circular
+func (r *Registry) CheckDependencies(resources Resources) error {
+	_, err := r.orderDependencies(resources)
+	return err
+}
+
+// orderDependencies moves resources after the resources they depend on,
+// keeping the order of the others. Dependencies on resources that aren't
+// given are assumed to exist remotely already.
+func (r *Registry) orderDependencies(resources Resources) (Resources, error) {
+	ordered := NewResources()
+	visiting := map[ResourceRef]bool{}
+
+	var visit func(resource Resource, path []string) error
+	visit = func(resource Resource, path []string) error {
+		ref := resource.Ref()
+		if _, done := ordered.Find(ref); done {
+			return nil
+		}
+		path = append(path, ref.String())
+		if visiting[ref] {
+			start := 0
+			for i, visited := range path {
+				if visited == ref.String() {
+					start = i
+					break
+				}
+			}
+			return fmt.Errorf("circular dependency: %s", strings.Join(path[start:], " -> "))
+		}
+
+		dependencies, err := r.dependencies(resource)
+		if err != nil {
+			return err
+		}
+		visiting[ref] = true
+		for _, dependency := range dependencies {
+			if required, ok := resources.Find(dependency); ok {
+				if err := visit(required, path); err != nil {
+					return err
+				}
+			}
+		}
+		visiting[ref] = false
+		ordered.Add(resource)
+		return nil
+	}
+
+	for _, resource := range resources.AsList() {
+		if err := visit(resource, nil); err != nil {
+			return resources, err
+		}
+	}
+	return ordered, nil
+}
+
+// dependencies returns the resources a resource depends on, referring to
+// their kinds by their current name, case-insensitively
+func (r *Registry) dependencies(resource Resource) ([]ResourceRef, error) {
+	dependencies, err := resource.DependsOn()
+	if err != nil {
+		return nil, err
+	}
+	for i, dependency := range dependencies {
+		if handler, err := handlerForKind(*r, dependency.Kind); err == nil {
+			dependencies[i].Kind = handler.Kind()
+		}
+	}
+	return dependencies, nil
+}
+
+// dependsOnAny returns whether a resource depends on any of the given ones
+func (r *Registry) dependsOnAny(resource Resource, resources []Resource) bool {
+	dependencies, _ := r.dependencies(resource)
+	for _, dependency := range dependencies {
+		for _, other := range resources {
+			if other.Ref() == dependency {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withoutDependencies returns a copy of a resource without its dependsOn
+// metadata, which only orders applies and is unknown to remote systems
+func withoutDependencies(resource Resource) Resource {
+	if !resource.HasMetadata("dependsOn") {
+		return resource
+	}
+	copied := resource.DeepCopy()
+	delete(copied.metadata(), "dependsOn")
+	return copied
+}
+
+// keepDependencies carries the dependsOn metadata of a pulled resource over
+// from the file it's pulled to. Returns whether the resource changed.
+func keepDependencies(local Resource, resource *Resource) bool {
+	if !local.HasMetadata("dependsOn") {
+		return false
+	}
+	resource.metadata()["dependsOn"] = local.metadata()["dependsOn"]
+	return true
+}
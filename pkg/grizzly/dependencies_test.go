@@ -0,0 +1,78 @@
+package grizzly_test
+
+import (
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grafana"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDependencies(t *testing.T) {
+	registry := grizzly.NewRegistry([]grizzly.Provider{grafana.NewProvider(&config.GrafanaConfig{})})
+
+	resource := func(kind, name string, dependsOn ...any) grizzly.Resource {
+		resource, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", kind, name, map[string]any{"title": name})
+		require.NoError(t, err)
+		if len(dependsOn) > 0 {
+			resource.Body["metadata"].(map[string]any)["dependsOn"] = dependsOn
+		}
+		return resource
+	}
+	refs := func(resources grizzly.Resources) []string {
+		var refs []string
+		for _, resource := range resources.AsList() {
+			refs = append(refs, resource.Ref().String())
+		}
+		return refs
+	}
+
+	t.Run("folders are applied before the resources referring to them", func(t *testing.T) {
+		sorted := registry.Sort(grizzly.NewResources(
+			resource(grafana.DashboardKind, "overview"),
+			resource(grafana.DatasourceKind, "prometheus"),
+			resource(grafana.DashboardFolderKind, "team-a"),
+		))
+		require.Equal(t, []string{"DashboardFolder.team-a", "Datasource.prometheus", "Dashboard.overview"}, refs(sorted))
+	})
+
+	t.Run("resources are applied after their dependencies", func(t *testing.T) {
+		sorted := registry.Sort(grizzly.NewResources(
+			resource(grafana.DashboardKind, "overview", "Dashboard/drilldown", "dashboard.details"),
+			resource(grafana.DashboardKind, "home"),
+			resource(grafana.DashboardKind, "details"),
+			resource(grafana.DashboardKind, "drilldown", "AlertRuleGroup/general.cpu"),
+			resource(grafana.AlertRuleGroupKind, "general.cpu"),
+		))
+		require.Equal(t, []string{
+			"AlertRuleGroup.general.cpu",
+			"Dashboard.drilldown",
+			"Dashboard.details",
+			"Dashboard.overview",
+			"Dashboard.home",
+		}, refs(sorted))
+		require.NoError(t, registry.CheckDependencies(sorted))
+	})
+
+	t.Run("dependencies that aren't applied are ignored", func(t *testing.T) {
+		resources := grizzly.NewResources(resource(grafana.DashboardKind, "overview", "DashboardFolder/elsewhere"))
+		require.Equal(t, []string{"Dashboard.overview"}, refs(registry.Sort(resources)))
+		require.NoError(t, registry.CheckDependencies(resources))
+	})
+
+	t.Run("circular dependencies are rejected", func(t *testing.T) {
+		resources := grizzly.NewResources(
+			resource(grafana.DashboardKind, "a", "Dashboard/b"),
+			resource(grafana.DashboardKind, "b", "Dashboard/c"),
+			resource(grafana.DashboardKind, "c", "Dashboard/a"),
+		)
+		require.EqualError(t, registry.CheckDependencies(resources), "circular dependency: Dashboard.a -> Dashboard.b -> Dashboard.c -> Dashboard.a")
+		require.Equal(t, []string{"Dashboard.a", "Dashboard.b", "Dashboard.c"}, refs(registry.Sort(resources)))
+	})
+
+	t.Run("malformed dependencies are rejected", func(t *testing.T) {
+		resources := grizzly.NewResources(resource(grafana.DashboardKind, "overview", "drilldown"))
+		require.EqualError(t, registry.CheckDependencies(resources), "Dashboard.overview depends on drilldown, which isn't a Kind/name reference")
+	})
+}
@@ -0,0 +1,80 @@
+package grizzly
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
+	"github.com/hashicorp/go-multierror"
+	log "github.com/sirupsen/logrus"
+)
+
+// DeployTags are the tags of the annotations marking deploys
+var DeployTags = []string{"grizzly", "deploy"}
+
+// Deploy describes resources being applied, as shown on dashboards
+type Deploy struct {
+	StartedAt time.Time
+	EndedAt   time.Time
+	Text      string
+	Tags      []string
+}
+
+// DeployAnnotator describes a provider that can mark deploys on dashboards,
+// such as with Grafana annotations
+type DeployAnnotator interface {
+	AnnotateDeploy(deploy Deploy) error
+}
+
+// NewDeploy describes the resources applied between two times, from the
+// counts of the summary of the apply, and from the git commit they were
+// applied from, when known
+func NewDeploy(startedAt, endedAt time.Time, summary Summary, context string, provenance map[string]string) Deploy {
+	var counts []string
+	for _, eventType := range []EventType{ResourceAdded, ResourceUpdated, ResourceDeleted, ResourceNotChanged, ResourceFailure} {
+		if count := summary.EventCounts[eventType]; count > 0 {
+			counts = append(counts, fmt.Sprintf("%s %s", Pluraliser(count, "resource"), eventType.HumanReadable))
+		}
+	}
+
+	text := "Applied with grr"
+	if context != "" {
+		text += " to " + context
+	}
+	if commit := provenance[GitCommitAnnotation]; commit != "" {
+		text += " from " + commit
+	}
+	if len(counts) > 0 {
+		text += ": " + strings.Join(counts, ", ")
+	}
+
+	return Deploy{
+		StartedAt: startedAt,
+		EndedAt:   endedAt,
+		Text:      text,
+		Tags:      DeployTags,
+	}
+}
+
+// AnnotateDeploy marks a deploy with every provider supporting it
+func AnnotateDeploy(registry Registry, deploy Deploy) error {
+	var finalErr error
+	for _, provider := range registry.Providers {
+		annotator, ok := provider.(DeployAnnotator)
+		if !ok {
+			continue
+		}
+		if err := provider.Validate(); err != nil {
+			log.Debugf("Skipping %s: %s", provider.Name(), err)
+			continue
+		}
+
+		if err := annotator.AnnotateDeploy(deploy); err != nil {
+			finalErr = multierror.Append(finalErr, fmt.Errorf("%s: annotating deploy: %w", provider.Name(), err))
+			continue
+		}
+		notifier.Info(notifier.SimpleString(provider.Name()), "Deploy annotated")
+	}
+	return finalErr
+}
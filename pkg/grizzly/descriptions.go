@@ -0,0 +1,99 @@
+package grizzly
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DescriptionHandler describes a handler whose remote system keeps a
+// human-readable description of resources, so that the description given in
+// their metadata can be written to, and read back from, their spec
+type DescriptionHandler interface {
+	// ExposeDescription writes a description to the spec of a resource, where
+	// the remote system keeps it
+	ExposeDescription(resource *Resource, description string)
+	// ReadDescription returns the description kept in the spec of a remote
+	// resource, and removes it from the spec
+	ReadDescription(resource *Resource) string
+}
+
+// Description returns the description given in the metadata of a resource
+func (r *Resource) Description() (string, error) {
+	if !r.HasMetadata("description") {
+		return "", nil
+	}
+	description, ok := r.metadata()["description"].(string)
+	if !ok {
+		return "", fmt.Errorf("the description of %s should be a string", r.Ref())
+	}
+	return description, nil
+}
+
+// ExposeDescription returns a copy of a resource with its description moved
+// from its metadata to its spec, for kinds whose remote system keeps
+// descriptions. For other kinds, such as data sources and alert rule groups,
+// descriptions are only known to Grizzly, and are dropped.
+func ExposeDescription(registry Registry, resource Resource) (Resource, error) {
+	if !resource.HasMetadata("description") {
+		return resource, nil
+	}
+	description, err := resource.Description()
+	if err != nil {
+		return resource, err
+	}
+
+	exposed := resource.DeepCopy()
+	delete(exposed.metadata(), "description")
+	if description == "" {
+		return exposed, nil
+	}
+
+	handler, err := registry.GetHandler(resource.Kind())
+	if err != nil {
+		return resource, err
+	}
+	descriptionHandler, ok := handler.(DescriptionHandler)
+	if !ok {
+		log.Debugf("%s can't keep descriptions remotely, keeping it locally", resource.Kind())
+		return exposed, nil
+	}
+	descriptionHandler.ExposeDescription(&exposed, description)
+	return exposed, nil
+}
+
+// localVersion returns the version of a pulled resource in the file it's
+// pulled to, if any, so that the metadata only known locally is kept
+func localVersion(registry Registry, filename string, resource Resource) (Resource, bool) {
+	if exists, err := isFile(filename); err != nil || !exists {
+		return Resource{}, false
+	}
+	local, err := DefaultParser(registry, nil, nil).Parse(filename, ParserOptions{DefaultResourceKind: resource.Kind()})
+	if err != nil {
+		log.Debugf("Not keeping the local metadata of %s, %s can't be parsed: %s", resource.Ref(), filename, err)
+		return Resource{}, false
+	}
+	return local.Find(resource.Ref())
+}
+
+// keepDescription carries the description of a pulled resource over from its
+// local version, when the description is given in its metadata there. The
+// remote description is moved back to the metadata of kinds whose remote
+// system keeps it, and the local one is kept otherwise. Returns whether the
+// resource changed.
+func keepDescription(handler Handler, existing Resource, resource *Resource) bool {
+	if !existing.HasMetadata("description") {
+		return false
+	}
+
+	description, _ := existing.Description()
+	if descriptionHandler, ok := handler.(DescriptionHandler); ok {
+		// descriptions aren't always returned by remote systems, such as
+		// by the legacy folders API of Grafana, and are kept then
+		if remote := descriptionHandler.ReadDescription(resource); remote != "" {
+			description = remote
+		}
+	}
+	resource.SetMetadata("description", description)
+	return true
+}
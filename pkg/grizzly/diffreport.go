@@ -0,0 +1,172 @@
+package grizzly
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// Formats of diff reports, besides the default, which reports changes as
+// they're found
+const (
+	DiffFormatUnified   = "unified"
+	DiffFormatJSONPatch = "json-patch"
+	DiffFormatSummary   = "summary"
+)
+
+// Changes of resources in diff reports
+const (
+	DiffAdded     = "added"
+	DiffChanged   = "changed"
+	DiffUnchanged = "unchanged"
+	DiffRemoved   = "removed"
+)
+
+// ResourceDiff describes how a local resource differs from its remote version
+type ResourceDiff struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Change string `json:"change"`
+	// Patch turns the remote resource into the local one, when changed
+	Patch []PatchOperation `json:"patch,omitempty"`
+	// Unified is the unified diff from the remote resource to the local one
+	Unified string `json:"-"`
+}
+
+// DiffCounts counts the resources by change
+type DiffCounts struct {
+	Added     int `json:"added"`
+	Changed   int `json:"changed"`
+	Removed   int `json:"removed"`
+	Unchanged int `json:"unchanged"`
+}
+
+// DiffSummary counts the resources by change, in total and per kind
+type DiffSummary struct {
+	DiffCounts
+	Kinds map[string]DiffCounts `json:"kinds"`
+}
+
+// DiffReportOptions controls how resources are compared in diff reports
+type DiffReportOptions struct {
+	OnlySpec     bool
+	OutputFormat string
+	// Snapshot, when set, is compared with instead of remote endpoints
+	Snapshot *StateSnapshot
+	// Removed are resources that applying would remove, such as those
+	// recorded as applied before that are no longer declared
+	Removed []ResourceRef
+}
+
+// DiffReport compares resources to those at the endpoints, or in a snapshot,
+// and describes the changes applying them would make, without reporting
+// them as they're found
+func DiffReport(registry Registry, resources Resources, opts DiffReportOptions) ([]ResourceDiff, error) {
+	from := "Remote"
+	if opts.Snapshot != nil {
+		from = snapshotLabel(opts.Snapshot)
+	}
+
+	var diffs []ResourceDiff
+	err := forEachRemote(registry, resources, remoteGetter(registry, opts.Snapshot), func(handler Handler, resource Resource, remote *Resource) error {
+		resourceDiff := ResourceDiff{Kind: resource.Kind(), Name: resource.Name()}
+
+		afterRepresentation, _, _, err := Format(registry, "", &resource, opts.OutputFormat, opts.OnlySpec)
+		if err != nil {
+			return err
+		}
+		var beforeRepresentation []byte
+		if remote != nil {
+			if beforeRepresentation, _, _, err = Format(registry, "", remote, opts.OutputFormat, opts.OnlySpec); err != nil {
+				return err
+			}
+		}
+
+		switch {
+		case remote == nil:
+			resourceDiff.Change = DiffAdded
+		case string(afterRepresentation) == string(beforeRepresentation):
+			resourceDiff.Change = DiffUnchanged
+		default:
+			resourceDiff.Change = DiffChanged
+			if opts.OnlySpec {
+				resourceDiff.Patch = prefixPatch("/spec", DiffPatch(remote.Spec(), resource.Spec()))
+			} else {
+				resourceDiff.Patch = DiffPatch(remote.Body, resource.Body)
+			}
+		}
+
+		if resourceDiff.Change != DiffUnchanged {
+			resourceDiff.Unified, _ = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:        difflib.SplitLines(string(beforeRepresentation)),
+				B:        difflib.SplitLines(string(afterRepresentation)),
+				FromFile: from + "/" + resource.Ref().String(),
+				ToFile:   "Local/" + resource.Ref().String(),
+				Context:  3,
+			})
+		}
+		diffs = append(diffs, resourceDiff)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ref := range opts.Removed {
+		diffs = append(diffs, ResourceDiff{Kind: ref.Kind, Name: ref.Name, Change: DiffRemoved})
+	}
+	return diffs, nil
+}
+
+// SummarizeDiff counts the resources of a diff report by change
+func SummarizeDiff(diffs []ResourceDiff) DiffSummary {
+	summary := DiffSummary{Kinds: map[string]DiffCounts{}}
+	for _, resourceDiff := range diffs {
+		counts := summary.Kinds[resourceDiff.Kind]
+		for _, c := range []*DiffCounts{&summary.DiffCounts, &counts} {
+			switch resourceDiff.Change {
+			case DiffAdded:
+				c.Added++
+			case DiffChanged:
+				c.Changed++
+			case DiffRemoved:
+				c.Removed++
+			case DiffUnchanged:
+				c.Unchanged++
+			}
+		}
+		summary.Kinds[resourceDiff.Kind] = counts
+	}
+	return summary
+}
+
+// FormatDiffReport renders a diff report in the given format: unified,
+// json-patch or summary
+func FormatDiffReport(diffs []ResourceDiff, format string) ([]byte, error) {
+	switch format {
+	case DiffFormatUnified:
+		var unified strings.Builder
+		for _, resourceDiff := range diffs {
+			unified.WriteString(resourceDiff.Unified)
+		}
+		return []byte(unified.String()), nil
+	case DiffFormatJSONPatch:
+		if diffs == nil {
+			diffs = []ResourceDiff{}
+		}
+		return json.MarshalIndent(diffs, "", "  ")
+	case DiffFormatSummary:
+		return json.MarshalIndent(SummarizeDiff(diffs), "", "  ")
+	default:
+		return nil, fmt.Errorf("unknown diff format %s, expected %s, %s or %s", format, DiffFormatUnified, DiffFormatJSONPatch, DiffFormatSummary)
+	}
+}
+
+func prefixPatch(prefix string, operations []PatchOperation) []PatchOperation {
+	for i := range operations {
+		operations[i].Path = prefix + operations[i].Path
+	}
+	return operations
+}
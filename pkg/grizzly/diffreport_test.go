@@ -0,0 +1,68 @@
+package grizzly_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grafana"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffReport(t *testing.T) {
+	registry := grizzly.NewRegistry([]grizzly.Provider{grafana.NewProvider(&config.GrafanaConfig{URL: "http://localhost:0"})})
+
+	newDashboard := func(uid, title string) grizzly.Resource {
+		dashboard, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", grafana.DashboardKind, uid, map[string]any{"uid": uid, "title": title})
+		require.NoError(t, err)
+		dashboard.SetMetadata("folder", "general")
+		return dashboard
+	}
+	snapshot := &grizzly.StateSnapshot{
+		Context:    "prod",
+		CapturedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		Resources:  []map[string]any{newDashboard("api", "API").Body, newDashboard("db", "DB").Body},
+	}
+
+	resources := grizzly.NewResources(newDashboard("api", "API v2"), newDashboard("db", "DB"), newDashboard("web", "Web"))
+	diffs, err := grizzly.DiffReport(registry, resources, grizzly.DiffReportOptions{
+		OnlySpec:     true,
+		OutputFormat: "yaml",
+		Snapshot:     snapshot,
+		Removed:      []grizzly.ResourceRef{grizzly.NewResourceRef(grafana.DashboardKind, "old")},
+	})
+	require.NoError(t, err)
+
+	changes := map[string]string{}
+	for _, resourceDiff := range diffs {
+		changes[resourceDiff.Name] = resourceDiff.Change
+	}
+	require.Equal(t, map[string]string{"api": grizzly.DiffChanged, "db": grizzly.DiffUnchanged, "web": grizzly.DiffAdded, "old": grizzly.DiffRemoved}, changes)
+	require.Equal(t, []grizzly.PatchOperation{{Op: "replace", Path: "/spec/title", Value: "API v2"}}, diffs[0].Patch)
+
+	t.Run("unified", func(t *testing.T) {
+		output, err := grizzly.FormatDiffReport(diffs, grizzly.DiffFormatUnified)
+		require.NoError(t, err)
+		require.Contains(t, string(output), "--- Snapshot (2026-10-01T12:00:00Z)/Dashboard.api\n+++ Local/Dashboard.api\n")
+		require.Contains(t, string(output), "-title: API\n+title: API v2\n")
+		require.Contains(t, string(output), "+++ Local/Dashboard.web\n")
+		require.NotContains(t, string(output), "Dashboard.db")
+	})
+
+	t.Run("summary", func(t *testing.T) {
+		output, err := grizzly.FormatDiffReport(diffs, grizzly.DiffFormatSummary)
+		require.NoError(t, err)
+		var summary grizzly.DiffSummary
+		require.NoError(t, json.Unmarshal(output, &summary))
+		counts := grizzly.DiffCounts{Added: 1, Changed: 1, Removed: 1, Unchanged: 1}
+		require.Equal(t, counts, summary.DiffCounts)
+		require.Equal(t, map[string]grizzly.DiffCounts{grafana.DashboardKind: counts}, summary.Kinds)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := grizzly.FormatDiffReport(diffs, "side-by-side")
+		require.ErrorContains(t, err, "unknown diff format")
+	})
+}
@@ -0,0 +1,58 @@
+package grizzly
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// runDiffTool shows the differences between two versions of a resource with
+// an external command, such as delta, difft or meld, given the paths of
+// temporary files holding each version
+func runDiffTool(tool string, ref ResourceRef, before, after []byte, fromFile, toFile, extension string) error {
+	args := strings.Fields(tool)
+	if len(args) == 0 {
+		return fmt.Errorf("no difftool configured")
+	}
+
+	dir, err := os.MkdirTemp("", "grizzly-diff-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	name := unsafeFileCharacters.ReplaceAllString(ref.String(), "-") + "." + extension
+	var paths []string
+	for _, version := range []struct {
+		label   string
+		content []byte
+	}{{fromFile, before}, {toFile, after}} {
+		path := filepath.Join(dir, strings.Trim(unsafeFileCharacters.ReplaceAllString(version.label, "-"), "-"), name)
+		if err := WriteFile(path, version.content); err != nil {
+			return err
+		}
+		paths = append(paths, path)
+	}
+
+	log.Debugf("Diffing %s with %s", ref, args[0])
+	cmd := exec.Command(args[0], append(args[1:], paths...)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+
+	// diff programs conventionally exit with 1 when the files differ
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("running difftool %s: %w", args[0], err)
+	}
+	return nil
+}
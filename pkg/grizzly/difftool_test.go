@@ -0,0 +1,43 @@
+package grizzly_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grafana"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffTool(t *testing.T) {
+	dir := t.TempDir()
+	tool := filepath.Join(dir, "difftool.sh")
+	require.NoError(t, os.WriteFile(tool, []byte(`#!/bin/sh
+cp "$1" `+dir+`/before
+cp "$2" `+dir+`/after
+exit 1
+`), 0755))
+
+	remote, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", grafana.DashboardKind, "api", map[string]any{"uid": "api", "title": "API"})
+	require.NoError(t, err)
+	snapshot := &grizzly.StateSnapshot{CapturedAt: time.Now(), Resources: []map[string]any{remote.Body}}
+	local := remote.DeepCopy()
+	local.SetSpecValue("title", "Renamed")
+
+	registry := grizzly.NewRegistry([]grizzly.Provider{grafana.NewProvider(&config.GrafanaConfig{})})
+	registry.DiffTool = tool
+	require.NoError(t, grizzly.DiffAgainst(registry, grizzly.NewResources(local), snapshot, true, "yaml"), "exiting with 1 means the files differ")
+
+	before, err := os.ReadFile(filepath.Join(dir, "before"))
+	require.NoError(t, err)
+	require.Equal(t, "title: API\nuid: api\n", string(before))
+	after, err := os.ReadFile(filepath.Join(dir, "after"))
+	require.NoError(t, err)
+	require.Equal(t, "title: Renamed\nuid: api\n", string(after))
+
+	registry.DiffTool = filepath.Join(dir, "missing")
+	require.ErrorContains(t, grizzly.DiffAgainst(registry, grizzly.NewResources(local), snapshot, true, "yaml"), "running difftool")
+}
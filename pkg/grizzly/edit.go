@@ -0,0 +1,198 @@
+package grizzly
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultEditor is used to edit resources when neither $GRR_EDITOR nor $EDITOR are set
+const DefaultEditor = "vi"
+
+// EditOptions controls how a remote resource is edited
+type EditOptions struct {
+	// Editor is the command used to edit resources, with its arguments. Ex: code --wait
+	Editor string
+	// OutputFormat is the format, yaml or json, the resource is edited in
+	OutputFormat string
+}
+
+// Editor returns the editor configured in the environment
+func Editor() string {
+	for _, env := range []string{"GRR_EDITOR", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(env)); editor != "" {
+			return editor
+		}
+	}
+	return DefaultEditor
+}
+
+// editHeader is written at the top of edited files. Leading lines starting
+// with a '#' are removed before the file is parsed, whatever its format.
+const editHeader = `# Edit the resource below, then save and close the file to apply it.
+# Lines beginning with a '#' at the top of the file are ignored, and an empty
+# file cancels the edit. If the resource can't be applied, this file will be
+# reopened with the problems found.
+`
+
+// Edit retrieves a resource from a remote endpoint using its UID, opens it in
+// an editor and applies it once saved. Edited resources are validated against
+// the schema of their kind, and the editor is reopened with the problems found
+// until they are fixed or the file is saved unchanged. The edited file is then
+// kept so that changes aren't lost.
+func Edit(registry Registry, parser Parser, uid string, opts EditOptions, eventsRecorder EventsRecorder) error {
+	resource, err := GetRemoteResource(registry, uid)
+	if err != nil {
+		return err
+	}
+
+	original, _, extension, err := Format(registry, "", resource, opts.OutputFormat, false)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp("", fmt.Sprintf("grr-edit-%s-*.%s", resource.Kind(), extension))
+	if err != nil {
+		return err
+	}
+	filename := file.Name()
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	content := original
+	var editErr error
+	for {
+		if err := os.WriteFile(filename, annotateEdit(content, resource, editErr), 0644); err != nil {
+			return err
+		}
+
+		edited, err := runEditor(opts.Editor, filename)
+		if err != nil {
+			_ = os.Remove(filename)
+			return err
+		}
+		edited = stripEditAnnotations(edited)
+
+		switch {
+		case len(bytes.TrimSpace(edited)) == 0:
+			_ = os.Remove(filename)
+			notifier.Info(resource, "edit cancelled")
+			return nil
+		case bytes.Equal(bytes.TrimSpace(edited), bytes.TrimSpace(original)):
+			_ = os.Remove(filename)
+			notifier.Info(resource, "no changes made")
+			return nil
+		case editErr != nil && bytes.Equal(bytes.TrimSpace(edited), bytes.TrimSpace(content)):
+			if err := os.WriteFile(filename, content, 0644); err != nil {
+				return err
+			}
+			return fmt.Errorf("edit cancelled, no valid changes were saved. Changes were kept in %s: %w", filename, editErr)
+		}
+
+		content = edited
+		if err := os.WriteFile(filename, content, 0644); err != nil {
+			return err
+		}
+		editErr = applyEdited(registry, parser, resource, filename, eventsRecorder)
+		if editErr == nil {
+			_ = os.Remove(filename)
+			return nil
+		}
+		log.Debugf("Reopening %s: %s", filename, editErr)
+	}
+}
+
+// applyEdited parses, validates and applies an edited resource
+func applyEdited(registry Registry, parser Parser, original *Resource, filename string, eventsRecorder EventsRecorder) error {
+	resources, err := parser.Parse(filename, ParserOptions{})
+	if err != nil {
+		return err
+	}
+	if err := checkEditedResources(original, resources); err != nil {
+		return err
+	}
+
+	edited := resources.First()
+	handler, err := registry.GetHandler(edited.Kind())
+	if err != nil {
+		return err
+	}
+	if err := handler.Validate(edited); err != nil {
+		return err
+	}
+	if validator, ok := handler.(SchemaValidator); ok {
+		if err := validator.ValidateSchema(edited); err != nil {
+			return err
+		}
+	}
+
+	return Apply(registry, resources, ApplyOptions{}, eventsRecorder)
+}
+
+// annotateEdit prepends the edit header to the content of a resource, along
+// with the error that prevented the previous version from being applied.
+func annotateEdit(content []byte, resource *Resource, editErr error) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(editHeader)
+	if editErr != nil {
+		buf.WriteString("#\n")
+		fmt.Fprintf(&buf, "# %s could not be applied:\n", resource.Ref())
+		for _, line := range strings.Split(strings.TrimSpace(editErr.Error()), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				fmt.Fprintf(&buf, "#   %s\n", line)
+			}
+		}
+	}
+	buf.WriteString("#\n")
+	buf.Write(content)
+	return buf.Bytes()
+}
+
+// stripEditAnnotations removes the comment lines at the top of an edited file
+func stripEditAnnotations(content []byte) []byte {
+	for len(content) > 0 && content[0] == '#' {
+		end := bytes.IndexByte(content, '\n')
+		if end < 0 {
+			return nil
+		}
+		content = content[end+1:]
+	}
+	return content
+}
+
+// checkEditedResources ensures that an edit didn't rename the resource or
+// add others, as applying would then create new resources.
+func checkEditedResources(original *Resource, resources Resources) error {
+	if resources.Len() != 1 {
+		return fmt.Errorf("expected a single resource, found %d", resources.Len())
+	}
+	edited := resources.First()
+	if edited.Ref() != original.Ref() {
+		return fmt.Errorf("%s was renamed to %s, which isn't supported by edit", original.Ref(), edited.Ref())
+	}
+	return nil
+}
+
+func runEditor(editor string, filename string) ([]byte, error) {
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		args = []string{DefaultEditor}
+	}
+
+	log.Debugf("Editing %s with %s", filename, args[0])
+	cmd := exec.Command(args[0], append(args[1:], filename)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("running editor %s: %w", args[0], err)
+	}
+
+	return os.ReadFile(filename)
+}
@@ -3,6 +3,7 @@ package grizzly
 import (
 	"errors"
 	"fmt"
+	"net/http"
 )
 
 var (
@@ -56,3 +57,154 @@ func IsWarning(err any) bool {
 	_, ok := err.(Warning)
 	return ok
 }
+
+// ErrorCode is a stable identifier for a class of errors, meant to be relied upon by automation.
+type ErrorCode string
+
+const (
+	ErrorCodeUnknown            ErrorCode = "unknown"
+	ErrorCodeAuthFailed         ErrorCode = "auth-failed"
+	ErrorCodePermissionDenied   ErrorCode = "permission-denied"
+	ErrorCodeNotFound           ErrorCode = "not-found"
+	ErrorCodeConflict           ErrorCode = "conflict"
+	ErrorCodePayloadTooLarge    ErrorCode = "payload-too-large"
+	ErrorCodeQuotaExceeded      ErrorCode = "quota-exceeded"
+	ErrorCodeVersionUnsupported ErrorCode = "version-unsupported"
+	ErrorCodeServerError        ErrorCode = "server-error"
+)
+
+// statusErrorCodes maps the HTTP statuses of failed requests to error codes
+var statusErrorCodes = map[int]ErrorCode{
+	http.StatusUnauthorized:          ErrorCodeAuthFailed,
+	http.StatusForbidden:             ErrorCodePermissionDenied,
+	http.StatusNotFound:              ErrorCodeNotFound,
+	http.StatusConflict:              ErrorCodeConflict,
+	http.StatusPreconditionFailed:    ErrorCodeConflict,
+	http.StatusRequestEntityTooLarge: ErrorCodePayloadTooLarge,
+	http.StatusTooManyRequests:       ErrorCodeQuotaExceeded,
+	http.StatusNotImplemented:        ErrorCodeVersionUnsupported,
+	http.StatusInternalServerError:   ErrorCodeServerError,
+	http.StatusBadGateway:            ErrorCodeServerError,
+	http.StatusServiceUnavailable:    ErrorCodeServerError,
+	http.StatusGatewayTimeout:        ErrorCodeServerError,
+}
+
+// defaultErrorHints are used when handlers don't provide a more specific hint
+var defaultErrorHints = map[ErrorCode]string{
+	ErrorCodeAuthFailed:         "check the credentials of the current context with `grr config check`",
+	ErrorCodePermissionDenied:   "the credentials of the current context lack the permissions required for this resource",
+	ErrorCodePayloadTooLarge:    "the resource exceeds the payload limit of the remote endpoint, see `grr apply --max-size`",
+	ErrorCodeQuotaExceeded:      "a rate limit or quota of the remote endpoint was reached, retry later",
+	ErrorCodeVersionUnsupported: "the remote endpoint doesn't support this API, check its version",
+	ErrorCodeServerError:        "the remote endpoint failed to process the request, retry later",
+}
+
+// CodedError is an error with a stable code, and a hint on how to remediate it.
+type CodedError struct {
+	Code ErrorCode
+	Hint string
+	Err  error
+}
+
+func NewCodedError(code ErrorCode, err error, hint string) CodedError {
+	return CodedError{
+		Code: code,
+		Hint: hint,
+		Err:  err,
+	}
+}
+
+func (e CodedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e CodedError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorHinter describes a handler that gives remediation hints specific to the resources it manages
+type ErrorHinter interface {
+	// ErrorHint returns a hint for the given error code, or an empty string
+	ErrorHint(code ErrorCode) string
+}
+
+// ErrorCodeOf returns the code of an error, inferring it from the HTTP
+// status of the failed request if needed.
+func ErrorCodeOf(err error) ErrorCode {
+	var coded CodedError
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	if errors.Is(err, ErrNotFound) {
+		return ErrorCodeNotFound
+	}
+
+	code := statusErrorCode(err)
+	// Depending on the endpoint, reaching a quota is reported as forbidden, as
+	// a bad request, or with a status the API clients don't expose
+	if (code == ErrorCodeUnknown || code == ErrorCodePermissionDenied) && quotaExceededMessage.MatchString(err.Error()) {
+		return ErrorCodeQuotaExceeded
+	}
+	return code
+}
+
+func statusErrorCode(err error) ErrorCode {
+	// Errors returned by the generated API clients report their status with IsCode()
+	var statusErr interface{ IsCode(int) bool }
+	if errors.As(err, &statusErr) {
+		for status, code := range statusErrorCodes {
+			if statusErr.IsCode(status) {
+				return code
+			}
+		}
+	}
+
+	return ErrorCodeUnknown
+}
+
+// ErrorHintOf returns the remediation hint of an error, if any.
+func ErrorHintOf(err error) string {
+	var coded CodedError
+	if errors.As(err, &coded) {
+		return coded.Hint
+	}
+	return ""
+}
+
+// ClassifyError turns an error returned by a handler into a CodedError, with
+// a hint from the handler when it provides one.
+func ClassifyError(handler Handler, err error) error {
+	if err == nil {
+		return nil
+	}
+	var coded CodedError
+	if errors.As(err, &coded) {
+		return err
+	}
+
+	code := ErrorCodeOf(err)
+	if code == ErrorCodeUnknown {
+		return err
+	}
+
+	return NewCodedError(code, err, errorHint(handler, code))
+}
+
+func errorHint(handler Handler, code ErrorCode) string {
+	if hinter, ok := handler.(ErrorHinter); ok {
+		if hint := hinter.ErrorHint(code); hint != "" {
+			return hint
+		}
+	}
+	return defaultErrorHints[code]
+}
+
+// classifyListError classifies errors returned when listing resources: as
+// listing doesn't target any specific resource, a missing endpoint means that
+// the remote system doesn't support the API used by the handler.
+func classifyListError(handler Handler, err error) error {
+	if ErrorCodeOf(err) == ErrorCodeNotFound {
+		return NewCodedError(ErrorCodeVersionUnsupported, err, errorHint(handler, ErrorCodeVersionUnsupported))
+	}
+	return ClassifyError(handler, err)
+}
@@ -0,0 +1,78 @@
+package grizzly_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/mimir/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorCodes(t *testing.T) {
+	t.Run("codes are inferred from response statuses", func(t *testing.T) {
+		tests := []struct {
+			status   int
+			expected grizzly.ErrorCode
+		}{
+			{status: http.StatusUnauthorized, expected: grizzly.ErrorCodeAuthFailed},
+			{status: http.StatusForbidden, expected: grizzly.ErrorCodePermissionDenied},
+			{status: http.StatusTooManyRequests, expected: grizzly.ErrorCodeQuotaExceeded},
+			{status: http.StatusBadGateway, expected: grizzly.ErrorCodeServerError},
+			{status: http.StatusTeapot, expected: grizzly.ErrorCodeUnknown},
+		}
+		for _, test := range tests {
+			err := fmt.Errorf("wrapped: %w", client.StatusError{StatusCode: test.status})
+			require.Equal(t, test.expected, grizzly.ErrorCodeOf(err), "status %d", test.status)
+		}
+	})
+
+	t.Run("quotas are detected from error messages", func(t *testing.T) {
+		messages := []string{
+			`[POST /dashboards/db][403] postDashboardForbidden {"message": "Quota reached"}`,
+			"per-user rule groups limit (limit: 70 actual: 71) exceeded",
+			`add check request: status="400 Bad Request", msg="the maximum number of checks has been reached"`,
+		}
+		for _, message := range messages {
+			require.Equal(t, grizzly.ErrorCodeQuotaExceeded, grizzly.ErrorCodeOf(errors.New(message)), message)
+		}
+		require.Equal(t, grizzly.ErrorCodePayloadTooLarge, grizzly.ErrorCodeOf(client.StatusError{StatusCode: http.StatusRequestEntityTooLarge, Body: "body size limit exceeded"}))
+	})
+
+	t.Run("not found errors keep matching ErrNotFound", func(t *testing.T) {
+		err := grizzly.ClassifyError(nil, fmt.Errorf("getting dashboard: %w", grizzly.ErrNotFound))
+		require.Equal(t, grizzly.ErrorCodeNotFound, grizzly.ErrorCodeOf(err))
+		require.True(t, errors.Is(err, grizzly.ErrNotFound))
+	})
+
+	t.Run("classified errors carry a default hint", func(t *testing.T) {
+		err := grizzly.ClassifyError(nil, client.StatusError{StatusCode: http.StatusUnauthorized})
+		require.Equal(t, grizzly.ErrorCodeAuthFailed, grizzly.ErrorCodeOf(err))
+		require.Contains(t, grizzly.ErrorHintOf(err), "grr config check")
+	})
+
+	t.Run("unknown errors are left untouched", func(t *testing.T) {
+		original := errors.New("boom")
+		require.Equal(t, original, grizzly.ClassifyError(nil, original))
+	})
+}
+
+func TestFailureEvents(t *testing.T) {
+	err := grizzly.NewCodedError(grizzly.ErrorCodePermissionDenied, errors.New("forbidden"), "the token lacks the dashboards:write permissions")
+	event := grizzly.NewFailureEvent("Dashboard.sample", err.Error(), err)
+
+	require.Equal(t, "Dashboard.sample failed: forbidden [permission-denied]\n  hint: the token lacks the dashboards:write permissions\n", grizzly.EventToPlainText(event))
+
+	var decoded map[string]string
+	require.NoError(t, json.Unmarshal([]byte(grizzly.EventToJSON(event)), &decoded))
+	require.Equal(t, map[string]string{
+		"type":     "resource-failure",
+		"resource": "Dashboard.sample",
+		"details":  "forbidden",
+		"code":     "permission-denied",
+		"hint":     "the token lacks the dashboards:write permissions",
+	}, decoded)
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fatih/color"
@@ -37,23 +38,71 @@ var (
 	ResourceNotFound   = EventType{ID: "resource-not-found", Severity: Info, HumanReadable: "not found"}
 	ResourceUpdated    = EventType{ID: "resource-updated", Severity: Notice, HumanReadable: "updated"}
 	ResourcePulled     = EventType{ID: "resource-pulled", Severity: Notice, HumanReadable: "pulled"}
+	ResourceDeleted    = EventType{ID: "resource-deleted", Severity: Notice, HumanReadable: "deleted"}
+	ResourcePruned     = EventType{ID: "resource-pruned", Severity: Notice, HumanReadable: "pruned"}
 	ResourceFailure    = EventType{ID: "resource-failure", Severity: Error, HumanReadable: "failed"}
+
+	ResourceWouldBeAdded   = EventType{ID: "resource-would-be-added", Severity: Notice, HumanReadable: "would be added"}
+	ResourceWouldBeUpdated = EventType{ID: "resource-would-be-updated", Severity: Notice, HumanReadable: "would be updated"}
+
+	RuleEvaluated        = EventType{ID: "rule-evaluated", Severity: Notice, HumanReadable: "evaluated"}
+	RuleNotEvaluated     = EventType{ID: "rule-not-evaluated", Severity: Info, HumanReadable: "not evaluated yet"}
+	RuleEvaluationFailed = EventType{ID: "rule-evaluation-failed", Severity: Error, HumanReadable: "failed evaluation"}
 )
 
 type Event struct {
 	Type        EventType
 	ResourceRef string
 	Details     string
+	// Code and Hint describe the error behind a failure, when it is known
+	Code ErrorCode
+	Hint string
+	// Context is the context the event happened in, when resources are
+	// applied to several contexts
+	Context string
+}
+
+// NewFailureEvent describes a failure caused by the given error, along with its code and remediation hint.
+func NewFailureEvent(resourceRef string, details string, err error) Event {
+	event := Event{
+		Type:        ResourceFailure,
+		ResourceRef: resourceRef,
+		Details:     details,
+		Hint:        ErrorHintOf(err),
+	}
+	if code := ErrorCodeOf(err); code != ErrorCodeUnknown {
+		event.Code = code
+	}
+	return event
 }
 
 type EventFormatter func(event Event) string
 
 func EventToPlainText(event Event) string {
-	if event.Details == "" {
-		return fmt.Sprintf("%s %s\n", event.ResourceRef, event.Type.HumanReadable)
-	}
+	return formatTextEvent(event, event.Type.HumanReadable)
+}
 
-	return fmt.Sprintf("%s %s: %s\n", event.ResourceRef, event.Type.HumanReadable, event.Details)
+// EventToJSON renders events as JSON lines, for automation.
+func EventToJSON(event Event) string {
+	content, err := json.Marshal(struct {
+		Type     string    `json:"type"`
+		Resource string    `json:"resource"`
+		Details  string    `json:"details,omitempty"`
+		Code     ErrorCode `json:"code,omitempty"`
+		Hint     string    `json:"hint,omitempty"`
+		Context  string    `json:"context,omitempty"`
+	}{
+		Type:     event.Type.ID,
+		Resource: event.ResourceRef,
+		Details:  event.Details,
+		Code:     event.Code,
+		Hint:     event.Hint,
+		Context:  event.Context,
+	})
+	if err != nil {
+		return EventToPlainText(event)
+	}
+	return string(content) + "\n"
 }
 
 func EventToColoredText(event Event) string {
@@ -73,17 +122,39 @@ func EventToColoredText(event Event) string {
 		eventType = colorFunc(eventType)
 	}
 
-	if event.Details == "" {
-		return fmt.Sprintf("%s %s\n", event.ResourceRef, eventType)
+	return formatTextEvent(event, eventType)
+}
+
+func formatTextEvent(event Event, eventType string) string {
+	var out strings.Builder
+
+	if event.Context != "" {
+		out.WriteString("[" + event.Context + "] ")
+	}
+	out.WriteString(event.ResourceRef + " " + eventType)
+	if event.Details != "" {
+		out.WriteString(": " + event.Details)
+	}
+	if event.Code != "" {
+		out.WriteString(" [" + string(event.Code) + "]")
+	}
+	out.WriteString("\n")
+	if event.Hint != "" {
+		out.WriteString("  hint: " + event.Hint + "\n")
 	}
 
-	return fmt.Sprintf("%s %s: %s\n", event.ResourceRef, eventType, event.Details)
+	return out.String()
 }
 
 type Summary struct {
 	EventCounts map[EventType]int
 }
 
+// Changed returns whether a summary reports resources changed remotely
+func (summary Summary) Changed() bool {
+	return summary.EventCounts[ResourceAdded]+summary.EventCounts[ResourceUpdated]+summary.EventCounts[ResourceDeleted] > 0
+}
+
 func (summary Summary) AsString(resourceLabel string) string {
 	var parts []string
 
@@ -196,3 +267,40 @@ func NewUsageRecorder(wr *WriterRecorder) *UsageRecorder {
 		endpoint: "https://stats.grafana.org/grizzly-usage-report",
 	}
 }
+
+// ChangeRecorder records the resources added or updated, before passing
+// events on to another recorder
+type ChangeRecorder struct {
+	EventsRecorder
+	lock    sync.Mutex
+	changed []ResourceRef
+}
+
+func NewChangeRecorder(recorder EventsRecorder) *ChangeRecorder {
+	return &ChangeRecorder{EventsRecorder: recorder}
+}
+
+func (r *ChangeRecorder) Record(event Event) {
+	if event.Type == ResourceAdded || event.Type == ResourceUpdated {
+		// references are <kind>.<name>, and kinds have no dots
+		if kind, name, ok := strings.Cut(event.ResourceRef, "."); ok {
+			r.lock.Lock()
+			r.changed = append(r.changed, NewResourceRef(kind, name))
+			r.lock.Unlock()
+		}
+	}
+	r.EventsRecorder.Record(event)
+}
+
+// Changed returns the names of the resources of a kind added or updated
+func (r *ChangeRecorder) Changed(kind string) []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var names []string
+	for _, ref := range r.changed {
+		if ref.Kind == kind {
+			names = append(names, ref.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,29 @@
+package grizzly
+
+import "fmt"
+
+// ResourceExpander describes a handler whose resources can hold queries, such
+// as the dashboards of a folder, expanded into the remote resources they match
+// whenever resources are applied or compared with their remote version
+type ResourceExpander interface {
+	// Expand returns a copy of a resource with its queries replaced by what
+	// they currently match
+	Expand(resource Resource) (Resource, error)
+}
+
+// ExpandResource expands the queries of a resource, for kinds supporting them
+func ExpandResource(registry Registry, resource Resource) (Resource, error) {
+	handler, err := registry.GetHandler(resource.Kind())
+	if err != nil {
+		return resource, err
+	}
+	expander, ok := handler.(ResourceExpander)
+	if !ok {
+		return resource, nil
+	}
+	expanded, err := expander.Expand(resource)
+	if err != nil {
+		return resource, ClassifyError(handler, fmt.Errorf("expanding %s: %w", resource.Ref(), err))
+	}
+	return expanded, nil
+}
@@ -0,0 +1,331 @@
+package grizzly
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaDescriber describes a handler documenting the fields of its kind,
+// as shown by `grr explain`
+type SchemaDescriber interface {
+	// Schema returns the schema of the resources of the kind, describing
+	// their metadata and spec
+	Schema() (*Schema, error)
+}
+
+// Schema documents a field of a resource, and the fields it holds. It is a
+// subset of JSON Schema.
+type Schema struct {
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Type is string, integer, number, boolean, object or array, or several
+	// of them separated by |. Fields of any type have none.
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
+	// Enum lists the values the field can have, if restricted
+	Enum []any `yaml:"enum,omitempty" json:"enum,omitempty"`
+	// Required lists the fields of an object that must be set
+	Required   []string           `yaml:"required,omitempty" json:"required,omitempty"`
+	Properties map[string]*Schema `yaml:"properties,omitempty" json:"properties,omitempty"`
+	// AdditionalProperties describes the values of objects used as maps
+	AdditionalProperties *Schema `yaml:"additionalProperties,omitempty" json:"additionalProperties,omitempty"`
+	// Items describes the elements of arrays
+	Items *Schema `yaml:"items,omitempty" json:"items,omitempty"`
+}
+
+// LoadSchema reads the schema of a kind from schemas/<kind>.yaml, in a
+// filesystem embedded by its provider
+func LoadSchema(fsys fs.FS, kind string) (*Schema, error) {
+	data, err := fs.ReadFile(fsys, fmt.Sprintf("schemas/%s.yaml", kind))
+	if err != nil {
+		return nil, fmt.Errorf("no schema is embedded for %s: %w", kind, err)
+	}
+	schema := &Schema{}
+	if err := yaml.Unmarshal(data, schema); err != nil {
+		return nil, fmt.Errorf("reading the schema of %s: %w", kind, err)
+	}
+	return schema, nil
+}
+
+// TypeName returns the type of a field, as shown by `grr explain`
+func (s *Schema) TypeName() string {
+	switch {
+	case s == nil || s.Type == "":
+		return "any"
+	case s.Type == "array":
+		return "[]" + s.Items.TypeName()
+	case s.Type == "object" && len(s.Properties) == 0 && s.AdditionalProperties != nil:
+		return "map[string]" + s.AdditionalProperties.TypeName()
+	default:
+		return s.Type
+	}
+}
+
+// field returns the schema of a field of an object, looking through arrays
+// into their elements
+func (s *Schema) field(name string) (*Schema, bool) {
+	for s != nil && s.Type == "array" {
+		s = s.Items
+	}
+	if s == nil {
+		return nil, false
+	}
+	if field, ok := s.Properties[name]; ok {
+		return field, true
+	}
+	if s.AdditionalProperties != nil {
+		return s.AdditionalProperties, true
+	}
+	return nil, false
+}
+
+// fields returns the object whose fields are listed for a field, looking
+// through arrays into their elements
+func (s *Schema) fields() *Schema {
+	for s != nil && s.Type == "array" {
+		s = s.Items
+	}
+	return s
+}
+
+func (s *Schema) requires(name string) bool {
+	return slices.Contains(s.Required, name)
+}
+
+// Explanation documents a field of a kind
+type Explanation struct {
+	Kind       string `yaml:"kind" json:"kind"`
+	APIVersion string `yaml:"apiVersion" json:"apiVersion"`
+	// Path is the path of the field in resources, empty for whole resources
+	Path   string  `yaml:"path,omitempty" json:"path,omitempty"`
+	Schema *Schema `yaml:"schema" json:"schema"`
+}
+
+// Explain documents a kind, or a field of a kind given by its path, such as
+// Dashboard.spec.panels.targets. Kinds are matched case-insensitively, and
+// arrays are looked through into their elements.
+func Explain(registry Registry, path string) (Explanation, error) {
+	kind, fieldPath, _ := strings.Cut(path, ".")
+	handler, err := handlerForKind(registry, kind)
+	if err != nil {
+		return Explanation{}, err
+	}
+	describer, ok := handler.(SchemaDescriber)
+	if !ok {
+		return Explanation{}, fmt.Errorf("no schema is embedded for %s", handler.Kind())
+	}
+	kindSchema, err := describer.Schema()
+	if err != nil {
+		return Explanation{}, err
+	}
+
+	schema := resourceSchema(handler, kindSchema)
+	explanation := Explanation{Kind: handler.Kind(), APIVersion: handler.APIVersion(), Schema: schema}
+	if fieldPath == "" {
+		return explanation, nil
+	}
+
+	var walked []string
+	for _, name := range strings.Split(fieldPath, ".") {
+		field, ok := schema.field(name)
+		if !ok {
+			parent := strings.Join(append([]string{handler.Kind()}, walked...), ".")
+			return Explanation{}, fmt.Errorf("field %s doesn't exist in %s", name, parent)
+		}
+		schema = field
+		walked = append(walked, name)
+	}
+	explanation.Path = strings.Join(walked, ".")
+	explanation.Schema = schema
+	return explanation, nil
+}
+
+// handlerForKind returns the handler of a kind, matched case-insensitively,
+// and by its legacy names
+func handlerForKind(registry Registry, kind string) (Handler, error) {
+	if current, ok := registry.LegacyKinds[kind]; ok {
+		kind = current
+	}
+	for _, handler := range registry.HandlerOrder {
+		if strings.EqualFold(handler.Kind(), kind) {
+			return handler, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown kind %s, see grr providers for the known kinds", kind)
+}
+
+// commonMetadata are the metadata fields every kind accepts
+var commonMetadata = map[string]*Schema{
+	"name": {Type: "string", Description: "The UID of the resource."},
+	"annotations": {
+		Type:                 "object",
+		AdditionalProperties: &Schema{Type: "string"},
+		Description:          "Notes about the resource, exposed remotely where the kind allows it. Ex: owner: team-a",
+	},
+	"dependsOn": {
+		Type:        "array",
+		Items:       &Schema{Type: "string"},
+		Description: "Resources applied before this one, as Kind/name references. Ex: DashboardFolder/team-a",
+	},
+	"description": {
+		Type:        "string",
+		Description: "A human-readable description of the resource, kept remotely by the kinds that have one, and locally otherwise.",
+	},
+}
+
+// resourceSchema returns the schema of whole resources of a kind, from the
+// schema of their metadata and spec
+func resourceSchema(handler Handler, kindSchema *Schema) *Schema {
+	schema := *kindSchema
+	schema.Type = "object"
+	schema.Properties = map[string]*Schema{
+		"apiVersion": {Type: "string", Description: fmt.Sprintf("The API version of the kind: %s", handler.APIVersion())},
+		"kind":       {Type: "string", Description: fmt.Sprintf("The kind of the resource: %s", handler.Kind())},
+	}
+	for name, field := range kindSchema.Properties {
+		schema.Properties[name] = field
+	}
+
+	metadata := &Schema{}
+	if kindMetadata, ok := kindSchema.Properties["metadata"]; ok {
+		copied := *kindMetadata
+		metadata = &copied
+	}
+	metadata.Type = "object"
+	if metadata.Description == "" {
+		metadata.Description = "Identifies the resource, and where it belongs."
+	}
+	properties := map[string]*Schema{}
+	for name, field := range commonMetadata {
+		properties[name] = field
+	}
+	for name, field := range metadata.Properties {
+		properties[name] = field
+	}
+	metadata.Properties = properties
+	if !metadata.requires("name") {
+		metadata.Required = append([]string{"name"}, metadata.Required...)
+	}
+	schema.Properties["metadata"] = metadata
+
+	required := []string{"apiVersion", "kind", "metadata"}
+	for _, name := range kindSchema.Required {
+		if !slices.Contains(required, name) {
+			required = append(required, name)
+		}
+	}
+	schema.Required = required
+	return &schema
+}
+
+// explainWidth is the width descriptions are wrapped at
+const explainWidth = 80
+
+// FormatExplanation formats an explanation as text, like `kubectl explain`,
+// or as JSON or YAML. Recursive text explanations list the fields within
+// fields, without their descriptions.
+func FormatExplanation(explanation Explanation, format string, recursive bool) (string, error) {
+	switch format {
+	case formatJSON:
+		out, err := json.MarshalIndent(explanation, "", "  ")
+		return string(out), err
+	case formatYAML:
+		out, err := yaml.Marshal(explanation)
+		return strings.TrimSuffix(string(out), "\n"), err
+	case formatDefault:
+	default:
+		return "", fmt.Errorf("unknown format %s, expected default, json or yaml", format)
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "KIND:     %s\n", explanation.Kind)
+	fmt.Fprintf(&out, "VERSION:  %s\n\n", explanation.APIVersion)
+
+	schema := explanation.Schema
+	if explanation.Path != "" {
+		name := explanation.Path[strings.LastIndex(explanation.Path, ".")+1:]
+		fmt.Fprintf(&out, "FIELD:    %s <%s>\n\n", name, schema.TypeName())
+	}
+	if schema.Description != "" {
+		out.WriteString("DESCRIPTION:\n")
+		out.WriteString(wrapText(schema.Description, "    "))
+		out.WriteString("\n")
+	}
+	if len(schema.Enum) > 0 {
+		fmt.Fprintf(&out, "ENUM:\n    %s\n\n", formatEnum(schema.Enum))
+	}
+
+	fields := schema.fields()
+	if fields == nil || len(fields.Properties) == 0 {
+		return strings.TrimSuffix(out.String(), "\n"), nil
+	}
+	out.WriteString("FIELDS:\n")
+	writeFields(&out, fields, "  ", recursive)
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
+
+func writeFields(out *strings.Builder, schema *Schema, indent string, recursive bool) {
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		field := schema.Properties[name]
+		fmt.Fprintf(out, "%s%s\t<%s>", indent, name, field.TypeName())
+		if schema.requires(name) {
+			out.WriteString(" -required-")
+		}
+		out.WriteString("\n")
+
+		if recursive {
+			if nested := field.fields(); nested != nil && len(nested.Properties) > 0 {
+				writeFields(out, nested, indent+"  ", true)
+			}
+			continue
+		}
+		if field.Description != "" {
+			out.WriteString(wrapText(field.Description, indent+"  "))
+		}
+		if len(field.Enum) > 0 {
+			fmt.Fprintf(out, "%s  One of: %s\n", indent, formatEnum(field.Enum))
+		}
+		out.WriteString("\n")
+	}
+}
+
+func formatEnum(values []any) string {
+	formatted := make([]string, 0, len(values))
+	for _, value := range values {
+		formatted = append(formatted, fmt.Sprint(value))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// wrapText wraps the paragraphs of a text at explainWidth, indenting each line
+func wrapText(text, indent string) string {
+	var out strings.Builder
+	for i, paragraph := range strings.Split(strings.TrimSpace(text), "\n\n") {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		line := indent
+		for _, word := range strings.Fields(paragraph) {
+			if line != indent && len(line)+1+len(word) > explainWidth {
+				out.WriteString(line + "\n")
+				line = indent
+			}
+			if line != indent {
+				line += " "
+			}
+			line += word
+		}
+		out.WriteString(line + "\n")
+	}
+	return out.String()
+}
@@ -0,0 +1,103 @@
+package grizzly_test
+
+import (
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grafana"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/loki"
+	"github.com/grafana/grizzly/pkg/mimir"
+	"github.com/grafana/grizzly/pkg/syntheticmonitoring"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	registry := grizzly.NewRegistry([]grizzly.Provider{
+		grafana.NewProvider(&config.GrafanaConfig{}),
+		mimir.NewProvider(&config.MimirConfig{}),
+		loki.NewProvider(&config.LokiConfig{}),
+		syntheticmonitoring.NewProvider(&config.SyntheticMonitoringConfig{}),
+	})
+
+	t.Run("every kind is documented", func(t *testing.T) {
+		for _, handler := range registry.HandlerOrder {
+			explanation, err := grizzly.Explain(registry, handler.Kind())
+			require.NoError(t, err, handler.Kind())
+			require.NotEmpty(t, explanation.Schema.Description, handler.Kind())
+			require.Contains(t, explanation.Schema.Properties, "spec", handler.Kind())
+		}
+	})
+
+	t.Run("resources have an envelope", func(t *testing.T) {
+		explanation, err := grizzly.Explain(registry, "dashboard")
+		require.NoError(t, err)
+		require.Equal(t, grafana.DashboardKind, explanation.Kind)
+		require.Equal(t, []string{"apiVersion", "kind", "metadata", "spec"}, explanation.Schema.Required)
+
+		metadata := explanation.Schema.Properties["metadata"]
+		require.Equal(t, "object", metadata.Type)
+		require.Contains(t, metadata.Properties, "name")
+		require.Contains(t, metadata.Properties, "folder")
+	})
+
+	t.Run("fields are drilled into through arrays", func(t *testing.T) {
+		explanation, err := grizzly.Explain(registry, "Dashboard.spec.panels.targets")
+		require.NoError(t, err)
+		require.Equal(t, "spec.panels.targets", explanation.Path)
+		require.Equal(t, "[]object", explanation.Schema.TypeName())
+	})
+
+	t.Run("legacy kinds are explained", func(t *testing.T) {
+		explanation, err := grizzly.Explain(registry, "Folder")
+		require.NoError(t, err)
+		require.Equal(t, grafana.DashboardFolderKind, explanation.Kind)
+	})
+
+	t.Run("unknown kinds and fields are rejected", func(t *testing.T) {
+		_, err := grizzly.Explain(registry, "Atlantis")
+		require.ErrorContains(t, err, "unknown kind Atlantis")
+
+		_, err = grizzly.Explain(registry, "Dashboard.spec.nope")
+		require.EqualError(t, err, "field nope doesn't exist in Dashboard.spec")
+	})
+
+	t.Run("explanations are formatted like kubectl explain", func(t *testing.T) {
+		explanation, err := grizzly.Explain(registry, "Dashboard.spec.time")
+		require.NoError(t, err)
+
+		out, err := grizzly.FormatExplanation(explanation, "default", false)
+		require.NoError(t, err)
+		require.Equal(t, `KIND:     Dashboard
+VERSION:  grizzly.grafana.com/v1alpha1
+
+FIELD:    time <object>
+
+DESCRIPTION:
+    The default time range of the dashboard.
+
+FIELDS:
+  from	<string> -required-
+    The start of the time range. Ex: now-6h
+
+  to	<string> -required-
+    The end of the time range. Ex: now
+`, out)
+	})
+
+	t.Run("recursive explanations list nested fields", func(t *testing.T) {
+		explanation, err := grizzly.Explain(registry, "SyntheticMonitoringCheck.spec.labels")
+		require.NoError(t, err)
+
+		out, err := grizzly.FormatExplanation(explanation, "default", true)
+		require.NoError(t, err)
+		require.Contains(t, out, "FIELDS:\n  name\t<string> -required-\n  value\t<string> -required-")
+	})
+
+	t.Run("unknown formats are rejected", func(t *testing.T) {
+		explanation, err := grizzly.Explain(registry, "Dashboard")
+		require.NoError(t, err)
+		_, err = grizzly.FormatExplanation(explanation, "xml", false)
+		require.ErrorContains(t, err, "unknown format xml")
+	})
+}
@@ -0,0 +1,384 @@
+package grizzly
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
+	"github.com/hashicorp/go-multierror"
+)
+
+// FanOutKind is the kind of resources instantiating a template resource
+// several times, such as a copy of a dashboard per team
+const FanOutKind = "FanOut"
+
+// DefaultFanOutStateFile is where the instances applied from fan-outs are
+// recorded, relative to the working directory
+const DefaultFanOutStateFile = ".grizzly/fan-out.json"
+
+// fanOutVariable matches the placeholders substituted in the template of a
+// fan-out, such as ${fanout.team}
+var fanOutVariable = regexp.MustCompile(`\$\{fanout\.([A-Za-z0-9_-]+)\}`)
+
+// fanOutSpec is the spec of a fan-out: a whole resource used as a template,
+// and the instances created from it
+type fanOutSpec struct {
+	Template  map[string]any   `json:"template"`
+	Instances []FanOutInstance `json:"instances"`
+}
+
+// FanOutInstance is an instance of the template of a fan-out
+type FanOutInstance struct {
+	// Name identifies the instance, and is appended to the UID of the template
+	Name string `json:"name"`
+	// Folder is the folder the instance is created in, in place of the folder of the template
+	Folder string            `json:"folder,omitempty"`
+	Vars   map[string]string `json:"vars,omitempty"`
+}
+
+// FanOutParser parses resources, then replaces the fan-outs among them with
+// their instances, so that targets apply to instances
+type FanOutParser struct {
+	registry  Registry
+	decorated Parser
+}
+
+func NewFanOutParser(registry Registry, decorated Parser) *FanOutParser {
+	return &FanOutParser{
+		registry:  registry,
+		decorated: decorated,
+	}
+}
+
+func (parser *FanOutParser) Accept(file string) bool {
+	return parser.decorated.Accept(file)
+}
+
+func (parser *FanOutParser) Parse(resourcePath string, options ParserOptions) (Resources, error) {
+	resources, err := parser.decorated.Parse(resourcePath, options)
+	if err != nil {
+		return resources, err
+	}
+	return ExpandFanOuts(parser.registry, resources)
+}
+
+// ExpandFanOuts replaces the fan-outs among resources with their instances.
+// Instances are given the UID of the template suffixed with their name, which
+// stays the same from one apply to the next. They aren't rewritable, as the
+// files of the fan-outs don't describe them.
+func ExpandFanOuts(registry Registry, resources Resources) (Resources, error) {
+	fanOuts := resources.OfKind(FanOutKind)
+	if fanOuts.Len() == 0 {
+		return resources, nil
+	}
+
+	expanded := resources.Filter(func(resource Resource) bool {
+		return resource.Kind() != FanOutKind
+	})
+	for _, fanOut := range fanOuts.AsList() {
+		instances, err := expandFanOut(registry, fanOut)
+		if err != nil {
+			return resources, err
+		}
+		for _, instance := range instances {
+			if existing, found := expanded.Find(instance.Ref()); found {
+				return resources, fmt.Errorf("fan-out %s creates %s, which is also described by %s", fanOut.Name(), instance.Ref(), existing.Source.Path)
+			}
+			expanded.Add(instance)
+		}
+	}
+	return expanded, nil
+}
+
+func expandFanOut(registry Registry, fanOut Resource) ([]Resource, error) {
+	spec, err := parseFanOutSpec(fanOut)
+	if err != nil {
+		return nil, err
+	}
+	template := Resource{Body: spec.Template}
+	handler, err := registry.GetHandler(template.Kind())
+	if err != nil {
+		return nil, fmt.Errorf("fan-out %s: %w", fanOut.Name(), err)
+	}
+
+	seen := map[string]bool{}
+	instances := make([]Resource, 0, len(spec.Instances))
+	for _, instance := range spec.Instances {
+		if instance.Name == "" {
+			return nil, fmt.Errorf("invalid fan-out %s: instances need a name", fanOut.Name())
+		}
+		if seen[instance.Name] {
+			return nil, fmt.Errorf("invalid fan-out %s: instance %s is declared twice", fanOut.Name(), instance.Name)
+		}
+		seen[instance.Name] = true
+
+		vars := map[string]string{"name": instance.Name, "folder": instance.Folder}
+		for name, value := range instance.Vars {
+			vars[name] = value
+		}
+		body, err := substituteFanOutVars(deepCopyValue(spec.Template), vars)
+		if err != nil {
+			return nil, fmt.Errorf("fan-out %s, instance %s: %w", fanOut.Name(), instance.Name, err)
+		}
+
+		resource := Resource{Body: body.(map[string]any), Source: fanOut.Source}
+		resource.Source.Rewritable = false
+		resource.Source.FanOut = fanOut.Name()
+		if instance.Folder != "" {
+			if !handler.UsesFolders() {
+				return nil, fmt.Errorf("fan-out %s, instance %s: %s resources don't live in folders", fanOut.Name(), instance.Name, handler.Kind())
+			}
+			resource.SetMetadata("folder", instance.Folder)
+		}
+		instances = append(instances, setFanOutUID(handler, resource, template.Name(), instance.Name))
+	}
+	return instances, nil
+}
+
+func parseFanOutSpec(fanOut Resource) (fanOutSpec, error) {
+	var spec fanOutSpec
+	data, err := json.Marshal(fanOut.Spec())
+	if err != nil {
+		return spec, err
+	}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return spec, fmt.Errorf("invalid fan-out %s: %w", fanOut.Name(), err)
+	}
+	kind, _ := spec.Template["kind"].(string)
+	if kind == "" || (&Resource{Body: spec.Template}).Name() == "" {
+		return spec, fmt.Errorf("invalid fan-out %s: template.kind and template.metadata.name are required", fanOut.Name())
+	}
+	if _, ok := spec.Template["spec"].(map[string]any); !ok {
+		return spec, fmt.Errorf("invalid fan-out %s: template.spec is required", fanOut.Name())
+	}
+	return spec, nil
+}
+
+// substituteFanOutVars replaces the placeholders of the variables of an
+// instance in every string of a template
+func substituteFanOutVars(value any, vars map[string]string) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			substituted, err := substituteFanOutVars(child, vars)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = substituted
+		}
+		return v, nil
+	case []any:
+		for i, child := range v {
+			substituted, err := substituteFanOutVars(child, vars)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = substituted
+		}
+		return v, nil
+	case string:
+		var missing []string
+		substituted := fanOutVariable.ReplaceAllStringFunc(v, func(placeholder string) string {
+			name := fanOutVariable.FindStringSubmatch(placeholder)[1]
+			value, ok := vars[name]
+			if !ok {
+				missing = append(missing, name)
+			}
+			return value
+		})
+		if len(missing) > 0 {
+			return nil, fmt.Errorf("undefined variable %s", missing[0])
+		}
+		return substituted, nil
+	default:
+		return value, nil
+	}
+}
+
+// setFanOutUID gives an instance the UID of its template, suffixed with the
+// name of the instance, rewritten to meet the constraints of its handler
+func setFanOutUID(handler Handler, resource Resource, base, instance string) Resource {
+	uid := base + "-" + instance
+	uidHandler, ok := handler.(UIDHandler)
+	if !ok {
+		resource.SetMetadata("name", uid)
+		return resource
+	}
+
+	constraints := uidHandler.UIDConstraints()
+	if (constraints.Pattern != nil && !constraints.Pattern.MatchString(uid)) || (constraints.MaxLength > 0 && len(uid) > constraints.MaxLength) {
+		uid = rewriteUID(uid, constraints, "")
+	}
+	return uidHandler.SetUID(resource, uid)
+}
+
+// stateRef is a resource recorded in a state file, such as an instance of a
+// fan-out
+type stateRef struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// PruneFanOuts records the instances of the fan-outs among the applied
+// resources, per context, and deletes the instances applied previously that
+// no longer are declared, when prune is set. Instances not matching targets
+// are kept, as they can't be told apart from removed instances. Fan-outs that
+// weren't applied, such as fan-outs outside of the resource path, are left
+// untouched.
+func PruneFanOuts(registry Registry, resources Resources, targets []string, path, context string, prune bool) error {
+	applied := map[string][]stateRef{}
+	_ = resources.ForEach(func(resource Resource) error {
+		if resource.Source.FanOut != "" {
+			applied[resource.Source.FanOut] = append(applied[resource.Source.FanOut], stateRef{Kind: resource.Kind(), Name: resource.Name()})
+		}
+		return nil
+	})
+	if len(applied) == 0 {
+		return nil
+	}
+
+	state, err := readPruneState(path)
+	if err != nil {
+		return err
+	}
+	if state[context] == nil {
+		state[context] = map[string][]stateRef{}
+	}
+
+	names := make([]string, 0, len(applied))
+	for name := range applied {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var finalErr error
+	for _, name := range names {
+		kept, err := pruneUndeclared(registry, applied[name], state[context][name], targets, prune, "no longer declared by fan-out "+name)
+		if err != nil {
+			finalErr = multierror.Append(finalErr, err)
+		}
+		state[context][name] = kept
+	}
+
+	if err := writeContextState(path, context, state[context]); err != nil {
+		finalErr = multierror.Append(finalErr, err)
+	}
+	return finalErr
+}
+
+// UndeclaredFanOuts returns the instances applied previously to a context by
+// the fan-outs among resources, and matching targets, that are no longer
+// declared: those applying resources with --prune would delete
+func UndeclaredFanOuts(registry Registry, resources Resources, targets []string, path, context string) ([]ResourceRef, error) {
+	state, err := readPruneState(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fanOuts := map[string]bool{}
+	_ = resources.ForEach(func(resource Resource) error {
+		if resource.Source.FanOut != "" {
+			fanOuts[resource.Source.FanOut] = true
+		}
+		return nil
+	})
+	names := make([]string, 0, len(fanOuts))
+	for name := range fanOuts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var undeclared []ResourceRef
+	for _, name := range names {
+		for _, ref := range state[context][name] {
+			resourceRef := NewResourceRef(ref.Kind, ref.Name)
+			if _, declared := resources.Find(resourceRef); declared {
+				continue
+			}
+			if registry.ResourceMatchesTarget(ref.Kind, ref.Name, targets) {
+				undeclared = append(undeclared, resourceRef)
+			}
+		}
+	}
+	return undeclared, nil
+}
+
+// pruneUndeclared deletes the recorded resources no longer declared by their
+// source, when prune is set, or warns about them for the given reason. It returns the resources to
+// record: the declared ones, and the ones that were kept. Resources not
+// matching targets are kept, as they can't be told apart from removed ones.
+func pruneUndeclared(registry Registry, declared, recorded []stateRef, targets []string, prune bool, reason string) ([]stateRef, error) {
+	isDeclared := map[stateRef]bool{}
+	for _, ref := range declared {
+		isDeclared[ref] = true
+	}
+
+	var finalErr error
+	kept := declared
+	for _, ref := range recorded {
+		if isDeclared[ref] {
+			continue
+		}
+		resourceRef := NewResourceRef(ref.Kind, ref.Name)
+		if !registry.ResourceMatchesTarget(ref.Kind, ref.Name, targets) {
+			kept = append(kept, ref)
+			continue
+		}
+		if !prune {
+			notifier.Warn(resourceRef, reason+", use --prune to delete it")
+			kept = append(kept, ref)
+			continue
+		}
+		if err := deleteUndeclared(registry, resourceRef); err != nil {
+			finalErr = multierror.Append(finalErr, err)
+			kept = append(kept, ref)
+		}
+	}
+	return kept, finalErr
+}
+
+func deleteUndeclared(registry Registry, ref ResourceRef) error {
+	handler, err := registry.GetHandler(ref.Kind)
+	if err != nil {
+		return err
+	}
+	deleteHandler, ok := handler.(DeleteHandler)
+	if !ok {
+		return fmt.Errorf("%s: kind %s does not support delete", ref, ref.Kind)
+	}
+
+	remote, err := handler.GetByUID(ref.Name)
+	if errors.Is(err, ErrNotFound) {
+		notifier.Info(ref, "already deleted")
+		return nil
+	}
+	if err != nil {
+		return ClassifyError(handler, err)
+	}
+	if err := deleteHandler.Delete(*remote); err != nil {
+		return ClassifyError(handler, err)
+	}
+	notifier.Info(ref, "pruned")
+	return nil
+}
+
+// readPruneState reads the resources recorded per context and source, such as
+// the instances of each fan-out, if the state file exists
+func readPruneState(path string) (map[string]map[string][]stateRef, error) {
+	state := map[string]map[string][]stateRef{}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, fmt.Errorf("reading recorded resources from %s: %w", path, err)
+	}
+	return state, nil
+}
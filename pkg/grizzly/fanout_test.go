@@ -0,0 +1,102 @@
+package grizzly_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grafana"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFanOut(t *testing.T) {
+	registry := grizzly.NewRegistry([]grizzly.Provider{grafana.NewProvider(&config.GrafanaConfig{})})
+	file := filepath.Join(t.TempDir(), "team-overview.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`apiVersion: grizzly.grafana.com/v1alpha1
+kind: FanOut
+metadata:
+  name: team-overview
+spec:
+  template:
+    apiVersion: grizzly.grafana.com/v1alpha1
+    kind: Dashboard
+    metadata:
+      name: team-overview
+      folder: general
+    spec:
+      title: "${fanout.team} overview"
+      tags: ["${fanout.name}"]
+  instances:
+    - name: team-a
+      folder: team-a
+      vars:
+        team: Team A
+    - name: team-b
+      vars:
+        team: Team B
+`), 0644))
+
+	parse := func(targets []string) grizzly.Resources {
+		resources, err := grizzly.DefaultParser(registry, targets, nil).Parse(file, grizzly.ParserOptions{})
+		require.NoError(t, err)
+		return resources
+	}
+
+	resources := parse(nil)
+	require.Equal(t, 2, resources.Len())
+
+	teamA, found := resources.Find(grizzly.NewResourceRef(grafana.DashboardKind, "team-overview-team-a"))
+	require.True(t, found)
+	require.Equal(t, "team-overview-team-a", teamA.GetSpecValue("uid"))
+	require.Equal(t, "Team A overview", teamA.GetSpecValue("title"))
+	require.Equal(t, []any{"team-a"}, teamA.GetSpecValue("tags"))
+	require.Equal(t, "team-a", teamA.GetMetadata("folder"))
+	require.Equal(t, "team-overview", teamA.Source.FanOut)
+	require.False(t, teamA.Source.Rewritable)
+
+	teamB, found := resources.Find(grizzly.NewResourceRef(grafana.DashboardKind, "team-overview-team-b"))
+	require.True(t, found)
+	require.Equal(t, "general", teamB.GetMetadata("folder"), "instances default to the folder of the template")
+
+	require.Equal(t, 1, parse([]string{"Dashboard/*-team-b"}).Len(), "targets apply to instances")
+
+	t.Run("derived UIDs meet constraints", func(t *testing.T) {
+		fanOut, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", grizzly.FanOutKind, "long", map[string]any{
+			"template": map[string]any{
+				"kind":     grafana.DashboardKind,
+				"metadata": map[string]any{"name": strings.Repeat("golden", 7)},
+				"spec":     map[string]any{"title": "Golden"},
+			},
+			"instances": []any{map[string]any{"name": "team a"}},
+		})
+		require.NoError(t, err)
+
+		expanded, err := grizzly.ExpandFanOuts(registry, grizzly.NewResources(fanOut))
+		require.NoError(t, err)
+		again, err := grizzly.ExpandFanOuts(registry, grizzly.NewResources(fanOut))
+		require.NoError(t, err)
+
+		uid := expanded.AsList()[0].Name()
+		require.LessOrEqual(t, len(uid), 40)
+		require.NotContains(t, uid, " ")
+		require.Equal(t, uid, again.AsList()[0].Name(), "derived UIDs are stable")
+	})
+
+	t.Run("undefined variables", func(t *testing.T) {
+		fanOut, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", grizzly.FanOutKind, "broken", map[string]any{
+			"template": map[string]any{
+				"kind":     grafana.DashboardKind,
+				"metadata": map[string]any{"name": "golden"},
+				"spec":     map[string]any{"title": "${fanout.team}"},
+			},
+			"instances": []any{map[string]any{"name": "team-a"}},
+		})
+		require.NoError(t, err)
+
+		_, err = grizzly.ExpandFanOuts(registry, grizzly.NewResources(fanOut))
+		require.ErrorContains(t, err, "undefined variable team")
+	})
+}
@@ -0,0 +1,48 @@
+package grizzly
+
+// fetchConcurrency is how many remote resources of a kind can be retrieved
+// at the same time
+func fetchConcurrency(registry Registry, handler Handler) int {
+	concurrency := max(registry.FetchConcurrency, 1)
+	if limiter, ok := handler.(ConcurrencyLimiter); ok && limiter.MaxConcurrency() > 0 {
+		concurrency = min(concurrency, limiter.MaxConcurrency())
+	}
+	return concurrency
+}
+
+// fetchOrdered calls get for n items, as many at the same time as
+// concurrency, and use with each result, in order. Items aren't retrieved
+// further ahead than concurrency, so that results don't pile up in memory
+// while use is slow. Returns the first error of use, after which no more
+// items are retrieved.
+func fetchOrdered[T any](n, concurrency int, get func(i int) T, use func(i int, result T) error) error {
+	results := make([]chan T, n)
+	for i := range results {
+		results[i] = make(chan T, 1)
+	}
+	slots := make(chan struct{}, max(concurrency, 1))
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for i := 0; i < n; i++ {
+			select {
+			case slots <- struct{}{}:
+			case <-done:
+				return
+			}
+			go func(i int) {
+				results[i] <- get(i)
+			}(i)
+		}
+	}()
+
+	for i := 0; i < n; i++ {
+		result := <-results[i]
+		<-slots
+		if err := use(i, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package grizzly
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchOrdered(t *testing.T) {
+	t.Run("results are used in order, with no more items retrieved at once than allowed", func(t *testing.T) {
+		var mu sync.Mutex
+		inFlight, maxInFlight := 0, 0
+		get := func(i int) int {
+			mu.Lock()
+			inFlight++
+			maxInFlight = max(maxInFlight, inFlight)
+			mu.Unlock()
+			// earlier items take longer, so that later ones complete first
+			time.Sleep(time.Duration(10-i) * time.Millisecond)
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			return i * i
+		}
+
+		var used []int
+		require.NoError(t, fetchOrdered(10, 3, get, func(i int, result int) error {
+			require.Equal(t, i*i, result)
+			used = append(used, i)
+			return nil
+		}))
+		require.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, used)
+		require.LessOrEqual(t, maxInFlight, 3)
+	})
+
+	t.Run("items stop being retrieved after an error", func(t *testing.T) {
+		var mu sync.Mutex
+		retrieved := 0
+		get := func(i int) int {
+			mu.Lock()
+			defer mu.Unlock()
+			retrieved++
+			return i
+		}
+		err := fetchOrdered(100, 2, get, func(i int, result int) error {
+			if i == 1 {
+				return errors.New("failed")
+			}
+			return nil
+		})
+		require.EqualError(t, err, "failed")
+
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		require.Less(t, retrieved, 10)
+	})
+}
@@ -0,0 +1,251 @@
+package grizzly
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultManagedFieldsFile is where the fields applied by Grizzly are recorded, relative to the working directory.
+const DefaultManagedFieldsFile = ".grizzly/managed-fields.json"
+
+// FieldConflictError is returned when a field managed by Grizzly was modified
+// remotely since it was last applied.
+type FieldConflictError struct {
+	Ref    string
+	Fields []string
+}
+
+func (e FieldConflictError) Error() string {
+	return fmt.Sprintf("%s: fields modified outside of Grizzly since the last apply: %s. Use --force-conflicts to take ownership of them", e.Ref, strings.Join(e.Fields, ", "))
+}
+
+// FieldManager implements field level ownership, in the spirit of Kubernetes
+// server-side apply: only the fields present in the local spec are managed by
+// Grizzly, any other field set remotely is left untouched on update.
+//
+// The value of each applied field is recorded per context, so that fields
+// modified outside of Grizzly since the last apply can be reported as conflicts.
+type FieldManager struct {
+	// ForceConflicts overwrites conflicting fields, taking ownership of them
+	ForceConflicts bool
+
+	path    string
+	context string
+	// managed holds, per context and resource, the last applied value of each field
+	managed map[string]map[string]map[string]any
+	// mu guards managed, as resources can be applied concurrently
+	mu sync.Mutex
+}
+
+// NewFieldManager loads the fields previously applied in the given context
+// from the given file, which doesn't need to exist yet.
+func NewFieldManager(path, context string, forceConflicts bool) (*FieldManager, error) {
+	m := &FieldManager{
+		ForceConflicts: forceConflicts,
+		path:           path,
+		context:        context,
+		managed:        map[string]map[string]map[string]any{},
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &m.managed); err != nil {
+		return nil, fmt.Errorf("reading managed fields from %s: %w", path, err)
+	}
+
+	return m, nil
+}
+
+// Merge computes the resource to send to the remote endpoint: the remote
+// resource, with the fields of the local one applied on top of it. Fields that
+// were applied previously but are no longer present locally are removed.
+//
+// Lists are managed as a whole, without merging their elements.
+func (m *FieldManager) Merge(local, remote Resource) (*Resource, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ref := local.Ref().String()
+
+	localSpec := NormaliseJSON(local.Spec())
+	merged := NormaliseJSON(remote.Spec())
+
+	localFields := map[string]any{}
+	flattenFields(nil, localSpec, localFields)
+	remoteFields := map[string]any{}
+	flattenFields(nil, merged, remoteFields)
+
+	lastApplied, hasRecord := m.managed[m.context][ref]
+
+	var conflicts []string
+	for path, value := range localFields {
+		remoteValue, ok := remoteFields[path]
+		if !ok || reflect.DeepEqual(remoteValue, value) {
+			continue
+		}
+		// Without any record, Grizzly takes ownership of all the fields it
+		// applies, like it did before fields were managed.
+		if !hasRecord {
+			continue
+		}
+		if last, owned := lastApplied[path]; owned && reflect.DeepEqual(last, remoteValue) {
+			continue
+		}
+		conflicts = append(conflicts, path)
+	}
+
+	if len(conflicts) > 0 && !m.ForceConflicts {
+		sort.Strings(conflicts)
+		return nil, FieldConflictError{Ref: ref, Fields: conflicts}
+	}
+
+	for path, last := range lastApplied {
+		if _, ok := localFields[path]; ok {
+			continue
+		}
+		// Fields changed remotely since they were applied belong to someone else now.
+		if remoteValue, ok := remoteFields[path]; ok && reflect.DeepEqual(remoteValue, last) {
+			deleteField(merged, splitFieldPath(path))
+		}
+	}
+	for path, value := range localFields {
+		setField(merged, splitFieldPath(path), value)
+	}
+
+	resource := local
+	resource.Body = make(map[string]any, len(local.Body))
+	for key, value := range local.Body {
+		resource.Body[key] = value
+	}
+	resource.SetSpec(merged)
+
+	return &resource, nil
+}
+
+// Record stores the fields of a resource that was successfully applied.
+func (m *FieldManager) Record(resource Resource) {
+	fields := map[string]any{}
+	flattenFields(nil, NormaliseJSON(resource.Spec()), fields)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.managed[m.context] == nil {
+		m.managed[m.context] = map[string]map[string]any{}
+	}
+	m.managed[m.context][resource.Ref().String()] = fields
+}
+
+// Save writes the managed fields of the context back to disk. The fields of
+// other contexts are kept as they are on disk, as they may have been saved
+// since they were loaded.
+func (m *FieldManager) Save() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return writeContextState(m.path, m.context, m.managed[m.context])
+}
+
+// contextStateLock serialises the updates of state files, as several contexts
+// can be applied at the same time
+var contextStateLock sync.Mutex
+
+// writeContextState replaces the state of a context in a JSON file holding
+// the state of each context, such as the managed fields file
+func writeContextState(path, context string, value any) error {
+	contextStateLock.Lock()
+	defer contextStateLock.Unlock()
+
+	state := map[string]json.RawMessage{}
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		if err := json.Unmarshal(content, &state); err != nil {
+			return fmt.Errorf("reading %s: %w", path, err)
+		}
+	}
+
+	contextState, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if string(contextState) == "null" {
+		delete(state, context)
+	} else {
+		state[context] = contextState
+	}
+
+	content, err = json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0644)
+}
+
+// flattenFields lists the leaf fields of an object, keyed by their JSON pointer.
+func flattenFields(prefix []string, value map[string]any, fields map[string]any) {
+	for key, child := range value {
+		path := append(append([]string{}, prefix...), key)
+		if object, ok := child.(map[string]any); ok && len(object) > 0 {
+			flattenFields(path, object, fields)
+			continue
+		}
+		fields[joinFieldPath(path)] = child
+	}
+}
+
+var fieldPathEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+var fieldPathUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+func joinFieldPath(path []string) string {
+	escaped := make([]string, 0, len(path))
+	for _, key := range path {
+		escaped = append(escaped, fieldPathEscaper.Replace(key))
+	}
+	return "/" + strings.Join(escaped, "/")
+}
+
+func splitFieldPath(path string) []string {
+	keys := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, key := range keys {
+		keys[i] = fieldPathUnescaper.Replace(key)
+	}
+	return keys
+}
+
+func setField(object map[string]any, path []string, value any) {
+	for _, key := range path[:len(path)-1] {
+		child, ok := object[key].(map[string]any)
+		if !ok {
+			child = map[string]any{}
+			object[key] = child
+		}
+		object = child
+	}
+	object[path[len(path)-1]] = value
+}
+
+func deleteField(object map[string]any, path []string) {
+	for _, key := range path[:len(path)-1] {
+		child, ok := object[key].(map[string]any)
+		if !ok {
+			return
+		}
+		object = child
+	}
+	delete(object, path[len(path)-1])
+}
@@ -0,0 +1,84 @@
+package grizzly_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func newDashboard(t *testing.T, spec map[string]any) grizzly.Resource {
+	t.Helper()
+
+	resource, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", "Dashboard", "sample", spec)
+	require.NoError(t, err)
+	return resource
+}
+
+func TestFieldManager(t *testing.T) {
+	t.Run("fields absent locally are preserved", func(t *testing.T) {
+		manager, err := grizzly.NewFieldManager(filepath.Join(t.TempDir(), "fields.json"), "default", false)
+		require.NoError(t, err)
+
+		local := newDashboard(t, map[string]any{"title": "Local"})
+		remote := newDashboard(t, map[string]any{"title": "Remote", "tags": []any{"ui"}})
+
+		merged, err := manager.Merge(local, remote)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"title": "Local", "tags": []any{"ui"}}, merged.Spec())
+	})
+
+	t.Run("fields modified remotely since the last apply conflict", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "fields.json")
+		manager, err := grizzly.NewFieldManager(file, "default", false)
+		require.NoError(t, err)
+		manager.Record(newDashboard(t, map[string]any{"title": "Local", "graphTooltip": 0}))
+		require.NoError(t, manager.Save())
+
+		manager, err = grizzly.NewFieldManager(file, "default", false)
+		require.NoError(t, err)
+
+		local := newDashboard(t, map[string]any{"title": "Local", "graphTooltip": 1})
+		remote := newDashboard(t, map[string]any{"title": "Edited in the UI", "graphTooltip": 0})
+
+		_, err = manager.Merge(local, remote)
+		require.EqualError(t, err, "Dashboard.sample: fields modified outside of Grizzly since the last apply: /title. Use --force-conflicts to take ownership of them")
+
+		manager.ForceConflicts = true
+		merged, err := manager.Merge(local, remote)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"title": "Local", "graphTooltip": float64(1)}, merged.Spec())
+	})
+
+	t.Run("fields no longer applied are removed", func(t *testing.T) {
+		manager, err := grizzly.NewFieldManager(filepath.Join(t.TempDir(), "fields.json"), "default", false)
+		require.NoError(t, err)
+		manager.Record(newDashboard(t, map[string]any{"title": "Local", "timezone": "utc"}))
+
+		local := newDashboard(t, map[string]any{"title": "Local"})
+		remote := newDashboard(t, map[string]any{"title": "Local", "timezone": "utc", "editable": true})
+
+		merged, err := manager.Merge(local, remote)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"title": "Local", "editable": true}, merged.Spec())
+	})
+
+	t.Run("contexts are saved independently", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "fields.json")
+		staging, err := grizzly.NewFieldManager(file, "staging", false)
+		require.NoError(t, err)
+		production, err := grizzly.NewFieldManager(file, "production", false)
+		require.NoError(t, err)
+
+		staging.Record(newDashboard(t, map[string]any{"title": "Staging"}))
+		production.Record(newDashboard(t, map[string]any{"title": "Production"}))
+		require.NoError(t, staging.Save())
+		require.NoError(t, production.Save())
+
+		manager, err := grizzly.NewFieldManager(file, "staging", false)
+		require.NoError(t, err)
+		_, err = manager.Merge(newDashboard(t, map[string]any{"title": "Staging"}), newDashboard(t, map[string]any{"title": "Edited"}))
+		require.Error(t, err, "the fields of contexts saved earlier are kept")
+	})
+}
@@ -0,0 +1,182 @@
+package grizzly
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Ways of turning the UIDs of resources into filenames
+const (
+	// FilenamesLegacy leaves the names of files to handlers, which replace
+	// path separators with dashes. Different UIDs can share a file.
+	FilenamesLegacy = "legacy"
+	// FilenamesEscape percent-encodes the characters that aren't allowed in
+	// filenames on some systems, keeping unicode characters as they are
+	FilenamesEscape = "escape"
+	// FilenamesASCII percent-encodes non-ASCII characters too, for systems
+	// and tools mangling unicode filenames
+	FilenamesASCII = "ascii"
+)
+
+// FilenameManifest is the file, at the root of a resource path, mapping the
+// files written by pull to the resources they hold
+const FilenameManifest = ".grizzly-files.yaml"
+
+// ManifestEntry is the resource a file holds
+type ManifestEntry struct {
+	Kind string `yaml:"kind"`
+	UID  string `yaml:"uid"`
+}
+
+type filenameManifest struct {
+	Files map[string]ManifestEntry `yaml:"files"`
+}
+
+// SanitizeFilename turns the UID of a resource into the base of its filename.
+// Escaped filenames can be turned back into UIDs with UnescapeFilename.
+func SanitizeFilename(mode, uid string) (string, error) {
+	switch mode {
+	case "", FilenamesLegacy:
+		return uid, nil
+	case FilenamesEscape, FilenamesASCII:
+	default:
+		return "", fmt.Errorf("unknown filenames setting %q, expected %s, %s or %s", mode, FilenamesLegacy, FilenamesEscape, FilenamesASCII)
+	}
+
+	var escaped strings.Builder
+	for i, r := range uid {
+		unsafe := r < 0x20 || r == 0x7f || strings.ContainsRune(`%/\:*?"<>|`, r) ||
+			(mode == FilenamesASCII && r > 0x7f) ||
+			// hidden files, and names Windows trims
+			(i == 0 && r == '.') || (i+utf8.RuneLen(r) == len(uid) && (r == '.' || r == ' '))
+		if !unsafe {
+			escaped.WriteRune(r)
+			continue
+		}
+		for _, b := range []byte(string(r)) {
+			fmt.Fprintf(&escaped, "%%%02X", b)
+		}
+	}
+	return escaped.String(), nil
+}
+
+// UnescapeFilename turns the base of a filename written with the escape or
+// ascii setting back into the UID of its resource
+func UnescapeFilename(filename string) (string, error) {
+	return url.PathUnescape(filename)
+}
+
+// resourceFilePath returns where a resource is written, relative to its
+// resource path, with its UID sanitized as set in the registry
+func resourceFilePath(registry Registry, handler Handler, resource Resource, extension string) (string, error) {
+	filename, err := SanitizeFilename(registry.Filenames, resource.Name())
+	if err != nil {
+		return "", err
+	}
+	if registry.PathTemplate != "" {
+		return templatedFilePath(registry, resource, filename, extension)
+	}
+	if filename != resource.Name() {
+		resource = resource.DeepCopy()
+		resource.SetMetadata("name", filename)
+	}
+	return handler.ResourceFilePath(resource, extension), nil
+}
+
+// templatedFilePath returns where a resource is written by the path template
+// of the registry. The template is executed with the kind, the name, and the
+// folder, namespace and type metadata of the resource, along with the
+// extension of its file, and can call the helpers of TemplateFuncs.
+func templatedFilePath(registry Registry, resource Resource, filename, extension string) (string, error) {
+	tmpl, err := template.New("path-template").Option("missingkey=error").Funcs(TemplateFuncs()).Parse(registry.PathTemplate)
+	if err != nil {
+		return "", fmt.Errorf("parsing the path template: %w", err)
+	}
+
+	segment := func(value string) string {
+		if registry.Filenames == "" || registry.Filenames == FilenamesLegacy {
+			// like the layouts of handlers, UIDs don't create directories
+			return strings.NewReplacer("/", "-", string(os.PathSeparator), "-").Replace(value)
+		}
+		return value
+	}
+	data := map[string]string{
+		"kind":      resource.Kind(),
+		"name":      segment(filename),
+		"extension": extension,
+	}
+	for _, key := range []string{"folder", "namespace", "type"} {
+		sanitized, err := SanitizeFilename(registry.Filenames, resource.GetMetadata(key))
+		if err != nil {
+			return "", err
+		}
+		data[key] = segment(sanitized)
+	}
+
+	var path strings.Builder
+	if err := tmpl.Execute(&path, data); err != nil {
+		return "", fmt.Errorf("executing the path template for %s: %w", resource.Ref(), err)
+	}
+	cleaned := filepath.Clean(filepath.FromSlash(path.String()))
+	if filepath.IsAbs(cleaned) || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("the path template gives %s for %s, which isn't a file within the resource path", path.String(), resource.Ref())
+	}
+	return cleaned, nil
+}
+
+// isFilenameManifest returns whether a file is a manifest written by pull,
+// rather than a resource
+func isFilenameManifest(path string) bool {
+	return filepath.Base(path) == FilenameManifest
+}
+
+// ReadFilenameManifest returns the resources held by the files of a
+// resource path, by path relative to it, as recorded by pull
+func ReadFilenameManifest(resourcePath string) (map[string]ManifestEntry, error) {
+	manifest := filenameManifest{Files: map[string]ManifestEntry{}}
+	content, err := os.ReadFile(filepath.Join(resourcePath, FilenameManifest))
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest.Files, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", FilenameManifest, err)
+	}
+	if manifest.Files == nil {
+		manifest.Files = map[string]ManifestEntry{}
+	}
+	return manifest.Files, nil
+}
+
+// updateFilenameManifest records the files written by pull in the manifest of
+// a resource path, forgetting the files that no longer exist
+func updateFilenameManifest(resourcePath string, written map[string]ManifestEntry) error {
+	files, err := ReadFilenameManifest(resourcePath)
+	if err != nil {
+		return err
+	}
+	for file, entry := range written {
+		files[file] = entry
+	}
+	for file := range files {
+		if _, err := os.Stat(filepath.Join(resourcePath, filepath.FromSlash(file))); errors.Is(err, os.ErrNotExist) {
+			delete(files, file)
+		}
+	}
+
+	content, err := yaml.Marshal(filenameManifest{Files: files})
+	if err != nil {
+		return err
+	}
+	return WriteFile(filepath.Join(resourcePath, FilenameManifest), content)
+}
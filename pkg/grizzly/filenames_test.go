@@ -0,0 +1,71 @@
+package grizzly
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		mode     string
+		uid      string
+		expected string
+	}{
+		{mode: FilenamesLegacy, uid: "team/a:b", expected: "team/a:b"},
+		{mode: FilenamesEscape, uid: "team/a:b", expected: "team%2Fa%3Ab"},
+		{mode: FilenamesEscape, uid: "100%", expected: "100%25"},
+		{mode: FilenamesEscape, uid: "café", expected: "café"},
+		{mode: FilenamesASCII, uid: "café", expected: "caf%C3%A9"},
+		{mode: FilenamesEscape, uid: "..hidden.", expected: "%2E.hidden%2E"},
+		{mode: FilenamesEscape, uid: "trailing ", expected: "trailing%20"},
+	}
+	for _, test := range tests {
+		t.Run(test.mode+" "+test.uid, func(t *testing.T) {
+			filename, err := SanitizeFilename(test.mode, test.uid)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, filename)
+
+			if test.mode != FilenamesLegacy {
+				uid, err := UnescapeFilename(filename)
+				require.NoError(t, err)
+				require.Equal(t, test.uid, uid)
+			}
+		})
+	}
+
+	_, err := SanitizeFilename("slug", "a")
+	require.ErrorContains(t, err, `unknown filenames setting "slug"`)
+}
+
+func TestTemplatedFilePath(t *testing.T) {
+	resource, err := NewResource("grizzly.grafana.com/v1alpha1", "PrometheusRuleGroup", "team/a", map[string]any{})
+	require.NoError(t, err)
+	resource.SetMetadata("namespace", "infra")
+
+	path := func(pathTemplate, filenames string) (string, error) {
+		registry := Registry{PathTemplate: pathTemplate, Filenames: filenames}
+		filename, err := SanitizeFilename(filenames, resource.Name())
+		require.NoError(t, err)
+		return templatedFilePath(registry, resource, filename, "yaml")
+	}
+
+	got, err := path("{{ .kind }}/{{ .namespace }}/{{ .folder }}/{{ .name }}.{{ .extension }}", "")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join("PrometheusRuleGroup", "infra", "team-a.yaml"), got, "UIDs don't create directories")
+
+	got, err = path("rules/{{ .name }}.{{ .extension }}", FilenamesEscape)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join("rules", "team%2Fa.yaml"), got)
+
+	got, err = path("{{ slugify .kind }}/{{ .name }}.{{ .extension }}", FilenamesEscape)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join("prometheusrulegroup", "team%2Fa.yaml"), got, "helpers can be called")
+
+	_, err = path("{{ .Kind }}.yaml", "")
+	require.ErrorContains(t, err, `map has no entry for key "Kind"`)
+
+	_, err = path("../{{ .name }}.yaml", "")
+	require.ErrorContains(t, err, "isn't a file within the resource path")
+}
@@ -0,0 +1,200 @@
+package grizzly
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// fileReferenceKey is the key of objects standing for the contents of a file,
+// such as the expression of a rule kept in its own file:
+//
+//	expr:
+//	  $file: queries/latency.promql
+const fileReferenceKey = "$file"
+
+// queryFileExtensions are the extensions of the files queries are usually
+// kept in, which are skipped when parsing directories of resources
+var queryFileExtensions = []string{".promql", ".logql", ".traceql", ".sql"}
+
+func isQueryFile(path string) bool {
+	return slices.Contains(queryFileExtensions, filepath.Ext(path))
+}
+
+// inlineFileReferences replaces the file references found in the spec of a
+// resource by the contents of the files, read relatively to dir. The
+// references are recorded in the source of the resource, so that they can be
+// split out again when the resource is written back.
+func inlineFileReferences(resource *Resource, dir string) error {
+	references := map[string]string{}
+	spec := resource.Spec()
+	for key, item := range spec {
+		inlined, err := inlineFileReferencesIn(item, "/"+escapePointerToken(key), dir, references)
+		if err != nil {
+			return fmt.Errorf("%s: %w", resource.Ref(), err)
+		}
+		spec[key] = inlined
+	}
+	if len(references) > 0 {
+		resource.Source.FileReferences = references
+	}
+	return nil
+}
+
+func inlineFileReferencesIn(value any, pointer string, dir string, references map[string]string) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		if file, ok := fileReference(v); ok {
+			content, err := os.ReadFile(resolveFileReference(dir, file))
+			if err != nil {
+				return nil, fmt.Errorf("reading %s referenced at %s: %w", file, pointer, err)
+			}
+			references[pointer] = file
+			return strings.TrimSuffix(string(content), "\n"), nil
+		}
+		for key, item := range v {
+			inlined, err := inlineFileReferencesIn(item, pointer+"/"+escapePointerToken(key), dir, references)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = inlined
+		}
+	case []any:
+		for i, item := range v {
+			inlined, err := inlineFileReferencesIn(item, pointer+"/"+strconv.Itoa(i), dir, references)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = inlined
+		}
+	}
+	return value, nil
+}
+
+func fileReference(value map[string]any) (string, bool) {
+	if len(value) != 1 {
+		return "", false
+	}
+	file, ok := value[fileReferenceKey].(string)
+	return file, ok
+}
+
+// SplitFileReferences returns a copy of a resource in which the values found
+// at the given references are replaced by file references again, after
+// writing them to the referenced files, relatively to dir. References to
+// values that are no longer strings are left inline.
+func SplitFileReferences(resource Resource, references map[string]string, dir string) (Resource, error) {
+	split := resource.DeepCopy()
+	for pointer, file := range references {
+		value, set, ok := lookupPointer(split.Spec(), pointer)
+		if !ok {
+			continue
+		}
+		content, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		if !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		if err := WriteFile(resolveFileReference(dir, file), []byte(content)); err != nil {
+			return Resource{}, err
+		}
+		set(map[string]any{fileReferenceKey: file})
+	}
+	return split, nil
+}
+
+// lookupPointer finds the value a JSON pointer refers to, and returns it with
+// a function replacing it
+func lookupPointer(spec map[string]any, pointer string) (any, func(any), bool) {
+	var value any = spec
+	var set func(any)
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = unescapePointerToken(token)
+
+		switch container := value.(type) {
+		case map[string]any:
+			item, ok := container[token]
+			if !ok {
+				return nil, nil, false
+			}
+			value, set = item, func(v any) { container[token] = v }
+		case []any:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(container) {
+				return nil, nil, false
+			}
+			value, set = container[index], func(v any) { container[index] = v }
+		default:
+			return nil, nil, false
+		}
+	}
+	return value, set, true
+}
+
+func resolveFileReference(dir, file string) string {
+	if filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(dir, file)
+}
+
+func escapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func unescapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
+
+// formatWithFileReferences formats a pulled resource again if some of its
+// values are to be written to other files: those its local version, written
+// at filename, reads from other files, and its queries when extracting them
+func formatWithFileReferences(registry Registry, handler Handler, filename string, resource Resource, opts PullOptions, content []byte) ([]byte, error) {
+	references := localFileReferences(registry, filename, resource)
+	if opts.ExtractQueries {
+		if queryHandler, ok := handler.(QueryHandler); ok {
+			for _, query := range queryHandler.Queries(resource) {
+				if _, exists := references[query.Pointer]; !exists {
+					references[query.Pointer] = query.File(resource)
+				}
+			}
+		}
+	}
+	if len(references) == 0 {
+		return content, nil
+	}
+
+	split, err := SplitFileReferences(resource, references, filepath.Dir(filename))
+	if err != nil {
+		return nil, err
+	}
+	content, _, _, err = Format(registry, "", &split, opts.OutputFormat, opts.OnlySpec)
+	return content, err
+}
+
+// localFileReferences returns the file references of the local version of a
+// resource, written at filename
+func localFileReferences(registry Registry, filename string, resource Resource) map[string]string {
+	references := map[string]string{}
+	if _, err := os.Stat(filename); err != nil {
+		return references
+	}
+
+	options := ParserOptions{DefaultResourceKind: resource.Kind(), DefaultFolderUID: resource.GetMetadata("folder")}
+	local, err := DefaultParser(registry, nil, nil).Parse(filename, options)
+	if err != nil {
+		return references
+	}
+	if existing, found := local.Find(resource.Ref()); found {
+		for pointer, file := range existing.Source.FileReferences {
+			references[pointer] = file
+		}
+	}
+	return references
+}
@@ -0,0 +1,63 @@
+package grizzly_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/mimir"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileReferences(t *testing.T) {
+	registry := grizzly.NewRegistry([]grizzly.Provider{mimir.NewProvider(&config.MimirConfig{})})
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "queries"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "queries", "cpu.promql"), []byte("sum(rate(cpu_seconds_total[5m])) > 0.9\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "rules.yaml"), []byte(`apiVersion: grizzly.grafana.com/v1alpha1
+kind: PrometheusRuleGroup
+metadata:
+    name: cpu
+    namespace: team
+spec:
+    rules:
+        - alert: HighCPU
+          expr:
+            $file: queries/cpu.promql
+`), 0644))
+	expr := func(resource grizzly.Resource) any {
+		return resource.Spec()["rules"].([]any)[0].(map[string]any)["expr"]
+	}
+
+	resources, err := grizzly.DefaultParser(registry, nil, nil).Parse(dir, grizzly.ParserOptions{})
+	require.NoError(t, err, "query files are skipped when parsing directories")
+	require.Equal(t, 1, resources.Len())
+
+	resource := resources.First()
+	require.Equal(t, "sum(rate(cpu_seconds_total[5m])) > 0.9", expr(resource))
+	require.Equal(t, map[string]string{"/rules/0/expr": "queries/cpu.promql"}, resource.Source.FileReferences)
+
+	t.Run("references are split out when written back", func(t *testing.T) {
+		updated := resource.DeepCopy()
+		updated.Spec()["rules"].([]any)[0].(map[string]any)["expr"] = "sum(rate(cpu_seconds_total[10m])) > 0.8"
+
+		split, err := grizzly.SplitFileReferences(updated, resource.Source.FileReferences, dir)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"$file": "queries/cpu.promql"}, expr(split))
+		require.Equal(t, "sum(rate(cpu_seconds_total[10m])) > 0.8", expr(updated), "the resource itself is left inline")
+
+		content, err := os.ReadFile(filepath.Join(dir, "queries", "cpu.promql"))
+		require.NoError(t, err)
+		require.Equal(t, "sum(rate(cpu_seconds_total[10m])) > 0.8\n", string(content))
+	})
+
+	t.Run("missing files are reported", func(t *testing.T) {
+		require.NoError(t, os.Remove(filepath.Join(dir, "queries", "cpu.promql")))
+
+		_, err := grizzly.DefaultParser(registry, nil, nil).Parse(filepath.Join(dir, "rules.yaml"), grizzly.ParserOptions{})
+		require.ErrorContains(t, err, "reading queries/cpu.promql referenced at /rules/0/expr")
+	})
+}
@@ -1,10 +1,15 @@
 package grizzly
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
+	"github.com/grafana/grizzly/pkg/config"
 	"gopkg.in/yaml.v3"
 )
 
@@ -35,7 +40,7 @@ func Format(registry Registry, resourcePath string, resource *Resource, format s
 		content = j
 	} else {
 		extension = formatYAML
-		y, err := yaml.Marshal(spec)
+		y, err := MarshalYAML(spec, registry.YAMLStyle)
 		if err != nil {
 			return nil, "", "", err
 		}
@@ -49,12 +54,155 @@ func Format(registry Registry, resourcePath string, resource *Resource, format s
 	return content, filename, extension, nil
 }
 
+// Values of config.YAMLStyle
+const (
+	yamlLiteralStrings   = "literal"
+	yamlFoldedStrings    = "folded"
+	yamlAlphabeticalKeys = "alphabetical"
+	yamlIdentifiersFirst = "identifiers-first"
+	yamlSingleQuotes     = "single"
+	yamlDoubleQuotes     = "double"
+
+	defaultYAMLIndent          = 4
+	defaultYAMLLongStringWidth = 80
+)
+
+// yamlIdentifierKeys are written first, in this order, by the identifiers-first key order
+var yamlIdentifierKeys = []string{"apiVersion", "kind", "metadata", "spec", "name", "uid", "title", "type"}
+
+// MarshalYAML marshals a value as YAML, in the given style
+func MarshalYAML(value any, style config.YAMLStyle) ([]byte, error) {
+	if style == (config.YAMLStyle{}) {
+		return yaml.Marshal(value)
+	}
+
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return nil, err
+	}
+	if err := styleYAMLNode(&node, style); err != nil {
+		return nil, err
+	}
+
+	indent := style.Indent
+	if indent == 0 {
+		indent = defaultYAMLIndent
+	}
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(indent)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func styleYAMLNode(node *yaml.Node, style config.YAMLStyle) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		if err := sortYAMLKeys(node, style.KeyOrder); err != nil {
+			return err
+		}
+		// keys are left as they are, only values are styled
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := styleYAMLNode(node.Content[i], style); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode, yaml.DocumentNode:
+		for _, child := range node.Content {
+			if err := styleYAMLNode(child, style); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if node.Tag == "!!str" {
+			return styleYAMLString(node, style)
+		}
+	}
+	return nil
+}
+
+func styleYAMLString(node *yaml.Node, style config.YAMLStyle) error {
+	width := style.LongStringWidth
+	if width == 0 {
+		width = defaultYAMLLongStringWidth
+	}
+	long := strings.Contains(node.Value, "\n") || len(node.Value) > width
+
+	switch style.LongStrings {
+	case "":
+	case yamlLiteralStrings:
+		if long {
+			node.Style = yaml.LiteralStyle
+			return nil
+		}
+	case yamlFoldedStrings:
+		if long {
+			node.Style = yaml.FoldedStyle
+			return nil
+		}
+	default:
+		return fmt.Errorf("unknown YAML style for long strings %q, expected %s or %s", style.LongStrings, yamlLiteralStrings, yamlFoldedStrings)
+	}
+
+	switch style.Quote {
+	case "":
+	case yamlSingleQuotes:
+		node.Style = yaml.SingleQuotedStyle
+	case yamlDoubleQuotes:
+		node.Style = yaml.DoubleQuotedStyle
+	default:
+		return fmt.Errorf("unknown YAML quoting style %q, expected %s or %s", style.Quote, yamlSingleQuotes, yamlDoubleQuotes)
+	}
+	return nil
+}
+
+// sortYAMLKeys sorts the key/value pairs of a mapping node. Keys are already
+// sorted alphabetically when encoded.
+func sortYAMLKeys(node *yaml.Node, order string) error {
+	switch order {
+	case "", yamlAlphabeticalKeys:
+		return nil
+	case yamlIdentifiersFirst:
+	default:
+		return fmt.Errorf("unknown YAML key order %q, expected %s or %s", order, yamlAlphabeticalKeys, yamlIdentifiersFirst)
+	}
+
+	pairs := make([][2]*yaml.Node, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs = append(pairs, [2]*yaml.Node{node.Content[i], node.Content[i+1]})
+	}
+	rank := func(key string) int {
+		if index := slices.Index(yamlIdentifierKeys, key); index >= 0 {
+			return index
+		}
+		return len(yamlIdentifierKeys)
+	}
+	slices.SortStableFunc(pairs, func(a, b [2]*yaml.Node) int {
+		return rank(a[0].Value) - rank(b[0].Value)
+	})
+
+	node.Content = node.Content[:0]
+	for _, pair := range pairs {
+		node.Content = append(node.Content, pair[0], pair[1])
+	}
+	return nil
+}
+
 func getFilename(registry Registry, resourcePath string, resource *Resource, extension string) (string, error) {
 	handler, err := registry.GetHandler(resource.Kind())
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(resourcePath, handler.ResourceFilePath(*resource, extension)), nil
+	path, err := resourceFilePath(registry, handler, *resource, extension)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(resourcePath, path), nil
 }
 
 func WriteFile(filename string, content []byte) error {
@@ -0,0 +1,80 @@
+package grizzly_test
+
+import (
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalYAML(t *testing.T) {
+	group := map[string]any{
+		"kind": "PrometheusRuleGroup",
+		"spec": map[string]any{
+			"rules": []any{
+				map[string]any{
+					"alert": "HighCPU",
+					"expr":  `sum by (cluster) (rate(container_cpu_usage_seconds_total{job="kubelet"}[5m])) > 0.9`,
+					"for":   "5m",
+				},
+			},
+			"name": "cpu",
+		},
+	}
+
+	tests := []struct {
+		name     string
+		style    config.YAMLStyle
+		expected string
+	}{
+		{
+			name: "default style",
+			expected: `kind: PrometheusRuleGroup
+spec:
+    name: cpu
+    rules:
+        - alert: HighCPU
+          expr: sum by (cluster) (rate(container_cpu_usage_seconds_total{job="kubelet"}[5m])) > 0.9
+          for: 5m
+`,
+		},
+		{
+			name:  "literal long strings",
+			style: config.YAMLStyle{Indent: 2, LongStrings: "literal"},
+			expected: `kind: PrometheusRuleGroup
+spec:
+  name: cpu
+  rules:
+    - alert: HighCPU
+      expr: |-
+        sum by (cluster) (rate(container_cpu_usage_seconds_total{job="kubelet"}[5m])) > 0.9
+      for: 5m
+`,
+		},
+		{
+			name:  "quoted strings, identifiers first",
+			style: config.YAMLStyle{Indent: 2, LongStringWidth: 200, KeyOrder: "identifiers-first", Quote: "single"},
+			expected: `kind: 'PrometheusRuleGroup'
+spec:
+  name: 'cpu'
+  rules:
+    - alert: 'HighCPU'
+      expr: 'sum by (cluster) (rate(container_cpu_usage_seconds_total{job="kubelet"}[5m])) > 0.9'
+      for: '5m'
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out, err := grizzly.MarshalYAML(group, test.style)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, string(out))
+		})
+	}
+
+	t.Run("unknown styles are refused", func(t *testing.T) {
+		_, err := grizzly.MarshalYAML(group, config.YAMLStyle{Quote: "backticks"})
+		require.ErrorContains(t, err, `unknown YAML quoting style "backticks"`)
+	})
+}
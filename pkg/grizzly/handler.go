@@ -8,6 +8,7 @@ type BaseHandler struct {
 	Provider    Provider
 	kind        string
 	usesFolders bool
+	listCache   *ListCache
 }
 
 func NewBaseHandler(provider Provider, kind string, usesFolders bool) BaseHandler {
@@ -18,6 +19,18 @@ func NewBaseHandler(provider Provider, kind string, usesFolders bool) BaseHandle
 	}
 }
 
+// WithListCache makes the handler cache the responses of its list endpoints,
+// retrieved with CachedList
+func (h BaseHandler) WithListCache() BaseHandler {
+	h.listCache = NewListCache()
+	return h
+}
+
+// InvalidateListCache discards the list responses cached by the handler
+func (h *BaseHandler) InvalidateListCache() {
+	h.listCache.Invalidate()
+}
+
 func (h *BaseHandler) Kind() string {
 	return h.kind
 }
@@ -105,6 +118,90 @@ type SnapshotHandler interface {
 	Snapshot(resource Resource, expiresSeconds int) error
 }
 
+// BatchGetResult holds the outcome of retrieving a single resource as part of a batch
+type BatchGetResult struct {
+	UID      string
+	Resource *Resource
+	Err      error
+}
+
+// BatchGetHandler describes a handler that can retrieve many resources more
+// efficiently than with one GetByUID call per resource
+type BatchGetHandler interface {
+	// GetByUIDs retrieves resources by UID. Results are returned in the same
+	// order as the UIDs
+	GetByUIDs(UIDs []string) []BatchGetResult
+}
+
+// RemoteVersioner describes a handler that can tell the version of a remote
+// resource more cheaply than retrieving it, so that the resources it
+// retrieved can be kept in a RemoteCache
+type RemoteVersioner interface {
+	// RemoteVersion returns the version of a remote resource, which changes
+	// whenever the resource does, including when it is deleted and created
+	// again. It returns an empty version when the resource can't be cached.
+	RemoteVersion(UID string) (string, error)
+	// ResourceVersion returns the version of a remote resource once
+	// retrieved, as RemoteVersion would, or an empty version when it can't
+	// be told from the resource
+	ResourceVersion(resource Resource) string
+}
+
+// MetadataInferrer describes a handler that can fill in the metadata missing
+// from a resource based on the file it was read from
+type MetadataInferrer interface {
+	// InferMetadata sets the metadata of a parsed resource that can be derived from its source
+	InferMetadata(resource *Resource)
+}
+
+// CompositeHandler describes a handler of a convenience kind, grouping
+// resources of another kind in a single resource. Its remote resources are
+// only listed and pulled when targeted, as they duplicate the resources of
+// the kind they group.
+type CompositeHandler interface {
+	// ComponentKind returns the kind of the resources grouped by this kind
+	ComponentKind() string
+}
+
+// MetadataUpdater describes a handler able to apply changes limited to the
+// metadata of a resource, such as its folder, with lighter-weight calls than
+// pushing its whole spec again
+type MetadataUpdater interface {
+	// UpdateMetadata applies the metadata of a resource whose spec didn't
+	// change. It returns false when no lighter-weight call is available, and
+	// the resource needs a full update.
+	UpdateMetadata(existing, resource Resource) (bool, error)
+}
+
+// DiffSummarizer describes a handler that can summarise the changes between
+// two versions of a resource at a granularity meaningful to reviewers
+type DiffSummarizer interface {
+	// DiffSummary lists the changes made by the local version of a resource to its remote version
+	DiffSummary(local, remote Resource) []string
+}
+
+// SchemaValidator describes a handler that can check a resource against the
+// schema of its kind before it is sent to the remote endpoint
+type SchemaValidator interface {
+	// ValidateSchema returns the problems found in the spec of a resource, if any
+	ValidateSchema(resource Resource) error
+}
+
+// RemoteValidator describes a handler whose remote endpoint can check a
+// resource without persisting it, such as with a dry-run request
+type RemoteValidator interface {
+	// ValidateRemote returns the problems the remote endpoint finds in a
+	// prepared resource, existing being its remote version when it exists
+	ValidateRemote(existing *Resource, resource Resource) error
+}
+
+// InventoryDescriber describes a handler that knows when, and by whom, remote
+// resources were changed
+type InventoryDescriber interface {
+	// DescribeRemote returns the inventory entry of a remote resource
+	DescribeRemote(UID string) (*InventoryEntry, error)
+}
+
 // ListenHandler describes a handler that has the ability to watch a single
 // resource for changes, and write changes to that resource to a local file
 type ListenHandler interface {
@@ -160,3 +257,12 @@ type ProxyConfigurator interface {
 
 	StaticEndpoints() StaticProxyConfig
 }
+
+// ConcurrencyLimiter describes a handler whose resources can't all be applied
+// concurrently, because their remote endpoint rate limits requests, or because
+// resources of the kind depend on each other
+type ConcurrencyLimiter interface {
+	// MaxConcurrency returns how many resources of the kind can be applied at
+	// the same time. With 1, they're applied one after the other, in order.
+	MaxConcurrency() int
+}
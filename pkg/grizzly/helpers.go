@@ -0,0 +1,205 @@
+package grizzly
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/google/go-jsonnet"
+	"github.com/google/go-jsonnet/ast"
+	"golang.org/x/text/unicode/norm"
+)
+
+// The helpers below implement naming conventions, such as UIDs derived from
+// titles, once for resources written in Jsonnet, where they are native
+// functions, and for Go templates, such as the path template, so that both
+// give the same results.
+
+// stableUIDLength is the length of the UIDs returned by StableUID, like the
+// UIDs Grafana generates
+const stableUIDLength = 14
+
+// StableUID returns a UID derived from the given parts, such as the folder
+// and the title of a dashboard, which stays the same as long as they do
+func StableUID(parts ...string) string {
+	return fmt.Sprintf("%x", sha1.Sum([]byte(strings.Join(parts, "/"))))[:stableUIDLength]
+}
+
+var slugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slugify returns a lowercase version of a string, fit for UIDs and
+// filenames: accents are removed, and runs of other characters than letters
+// and digits are replaced with a dash. Ex: "Café Orders (EU)" gives
+// "cafe-orders-eu"
+func Slugify(s string) string {
+	var unaccented strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		if !unicode.Is(unicode.Mn, r) {
+			unaccented.WriteRune(r)
+		}
+	}
+	return strings.Trim(slugSeparators.ReplaceAllString(strings.ToLower(unaccented.String()), "-"), "-")
+}
+
+var (
+	durationPattern = regexp.MustCompile(`^(\d+(\.\d+)?(ns|us|µs|ms|s|m|h|d|w|y))+$`)
+	durationPart    = regexp.MustCompile(`(\d+(?:\.\d+)?)(ns|us|µs|ms|s|m|h|d|w|y)`)
+	durationDays    = map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+		"y": 365 * 24 * time.Hour,
+	}
+)
+
+// ParseDuration parses a duration written like the durations of Go, such as
+// 1h30m, or of Prometheus, which also have days, weeks and years of 365
+// days, such as 2w or 1d12h
+func ParseDuration(s string) (time.Duration, error) {
+	if s == "0" {
+		return 0, nil
+	}
+	if !durationPattern.MatchString(s) {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	var duration time.Duration
+	for _, part := range durationPart.FindAllStringSubmatch(s, -1) {
+		unit, ok := durationDays[part[2]]
+		if !ok {
+			parsed, err := time.ParseDuration(part[0])
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q: %w", s, err)
+			}
+			duration += parsed
+			continue
+		}
+		value, err := strconv.ParseFloat(part[1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: %w", s, err)
+		}
+		duration += time.Duration(value * float64(unit))
+	}
+	return duration, nil
+}
+
+// MergeLabels merges sets of labels, such as the labels of a team and those
+// of a rule. Later labels win, and labels set to null are removed.
+func MergeLabels(labels ...map[string]any) map[string]any {
+	merged := map[string]any{}
+	for _, set := range labels {
+		for name, value := range set {
+			if value == nil {
+				delete(merged, name)
+				continue
+			}
+			merged[name] = value
+		}
+	}
+	return merged
+}
+
+// TemplateFuncs returns the helpers, by name, for Go templates. Durations are
+// given in seconds, as in Jsonnet.
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"stableUID": StableUID,
+		"slugify":   Slugify,
+		"parseDuration": func(s string) (float64, error) {
+			duration, err := ParseDuration(s)
+			return duration.Seconds(), err
+		},
+		"mergeLabels": func(labels ...any) (map[string]any, error) {
+			return mergeLabelArgs(labels)
+		},
+	}
+}
+
+// helperNativeFuncs returns the helpers as Jsonnet native functions, called
+// with std.native(). Functions of several values, such as stableUID, are
+// given an array.
+func helperNativeFuncs() []*jsonnet.NativeFunction {
+	return []*jsonnet.NativeFunction{
+		{
+			Name:   "stableUID",
+			Params: ast.Identifiers{"parts"},
+			Func: func(data []any) (any, error) {
+				items, ok := data[0].([]any)
+				if !ok {
+					return nil, fmt.Errorf("stableUID expects an array of strings")
+				}
+				parts := make([]string, 0, len(items))
+				for _, item := range items {
+					part, ok := item.(string)
+					if !ok {
+						return nil, fmt.Errorf("stableUID expects an array of strings, not %s", typeOf(item))
+					}
+					parts = append(parts, part)
+				}
+				return StableUID(parts...), nil
+			},
+		},
+		{
+			Name:   "slugify",
+			Params: ast.Identifiers{"str"},
+			Func: func(data []any) (any, error) {
+				s, ok := data[0].(string)
+				if !ok {
+					return nil, fmt.Errorf("slugify expects a string, not %s", typeOf(data[0]))
+				}
+				return Slugify(s), nil
+			},
+		},
+		{
+			Name:   "parseDuration",
+			Params: ast.Identifiers{"duration"},
+			Func: func(data []any) (any, error) {
+				s, ok := data[0].(string)
+				if !ok {
+					return nil, fmt.Errorf("parseDuration expects a string, not %s", typeOf(data[0]))
+				}
+				duration, err := ParseDuration(s)
+				if err != nil {
+					return nil, err
+				}
+				return duration.Seconds(), nil
+			},
+		},
+		{
+			Name:   "mergeLabels",
+			Params: ast.Identifiers{"labels"},
+			Func: func(data []any) (any, error) {
+				sets, ok := data[0].([]any)
+				if !ok {
+					return nil, fmt.Errorf("mergeLabels expects an array of objects")
+				}
+				return mergeLabelArgs(sets)
+			},
+		},
+	}
+}
+
+// mergeLabelArgs merges the labels given to a template or Jsonnet, as maps of
+// any kind of values
+func mergeLabelArgs(args []any) (map[string]any, error) {
+	sets := make([]map[string]any, 0, len(args))
+	for _, arg := range args {
+		switch set := arg.(type) {
+		case nil:
+		case map[string]any:
+			sets = append(sets, set)
+		case map[string]string:
+			converted := make(map[string]any, len(set))
+			for name, value := range set {
+				converted[name] = value
+			}
+			sets = append(sets, converted)
+		default:
+			return nil, fmt.Errorf("mergeLabels expects objects, not %s", typeOf(arg))
+		}
+	}
+	return MergeLabels(sets...), nil
+}
@@ -0,0 +1,88 @@
+package grizzly_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grafana"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStableUID(t *testing.T) {
+	require.Equal(t, "ade1b138e0f869", grizzly.StableUID("folder", "title"))
+	require.Equal(t, grizzly.StableUID("folder", "title"), grizzly.StableUID("folder", "title"))
+	require.NotEqual(t, grizzly.StableUID("folder", "title"), grizzly.StableUID("folder", "other"))
+}
+
+func TestSlugify(t *testing.T) {
+	for input, expected := range map[string]string{
+		"Café Orders (EU)": "cafe-orders-eu",
+		"  --API_v2--  ":   "api-v2",
+		"already-a-slug":   "already-a-slug",
+		"!!!":              "",
+	} {
+		require.Equal(t, expected, grizzly.Slugify(input), input)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	for input, expected := range map[string]time.Duration{
+		"0":       0,
+		"90s":     90 * time.Second,
+		"1h30m":   90 * time.Minute,
+		"1.5h":    90 * time.Minute,
+		"2d":      48 * time.Hour,
+		"1w1d":    8 * 24 * time.Hour,
+		"1y":      365 * 24 * time.Hour,
+		"1d12h":   36 * time.Hour,
+		"250ms":   250 * time.Millisecond,
+		"1m500ms": time.Minute + 500*time.Millisecond,
+	} {
+		got, err := grizzly.ParseDuration(input)
+		require.NoError(t, err, input)
+		require.Equal(t, expected, got, input)
+	}
+
+	for _, input := range []string{"", "1", "-1h", "1 h", "1x", "h"} {
+		_, err := grizzly.ParseDuration(input)
+		require.ErrorContains(t, err, "invalid duration", input)
+	}
+}
+
+func TestMergeLabels(t *testing.T) {
+	merged := grizzly.MergeLabels(
+		map[string]any{"team": "payments", "severity": "warning", "env": "prod"},
+		map[string]any{"severity": "critical", "env": nil},
+	)
+	require.Equal(t, map[string]any{"team": "payments", "severity": "critical"}, merged)
+}
+
+func TestHelperNativeFunctions(t *testing.T) {
+	registry := grizzly.NewRegistry([]grizzly.Provider{grafana.NewProvider(&config.GrafanaConfig{})})
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "helpers.jsonnet"), []byte(`{
+  apiVersion: 'grizzly.grafana.com/v1alpha1',
+  kind: 'Dashboard',
+  metadata: { name: std.native('slugify')('Café Orders (EU)'), folder: 'general' },
+  spec: {
+    uid: std.native('stableUID')(['folder', 'title']),
+    title: 'Orders',
+    refresh: std.native('parseDuration')('1d12h'),
+    labels: std.native('mergeLabels')([{ team: 'payments', env: 'prod' }, { env: null, severity: 'critical' }]),
+  },
+}
+`), 0644))
+
+	resources, err := grizzly.DefaultParser(registry, nil, nil).Parse(filepath.Join(dir, "helpers.jsonnet"), grizzly.ParserOptions{})
+	require.NoError(t, err)
+	dashboard, found := resources.Find(grizzly.NewResourceRef(grafana.DashboardKind, "cafe-orders-eu"))
+	require.True(t, found)
+	require.Equal(t, grizzly.StableUID("folder", "title"), dashboard.Spec()["uid"])
+	require.EqualValues(t, 36*60*60, dashboard.Spec()["refresh"])
+	require.Equal(t, map[string]any{"team": "payments", "severity": "critical"}, dashboard.Spec()["labels"])
+}
@@ -0,0 +1,149 @@
+package grizzly
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	log "github.com/sirupsen/logrus"
+)
+
+// Formats of the inventories of remote resources
+const (
+	InventoryFormatCSV     = "csv"
+	InventoryFormatParquet = "parquet"
+)
+
+// InventoryEntry describes a remote resource, for analysis in spreadsheets or
+// data warehouses. Fields the kind of the resource doesn't have are empty.
+type InventoryEntry struct {
+	Kind      string     `parquet:"kind"`
+	UID       string     `parquet:"uid"`
+	Title     string     `parquet:"title,optional"`
+	Folder    string     `parquet:"folder,optional"`
+	Tags      []string   `parquet:"tags,list"`
+	Updated   *time.Time `parquet:"updated,optional"`
+	UpdatedBy string     `parquet:"updated_by,optional"`
+	Owner     string     `parquet:"owner,optional"`
+}
+
+var inventoryColumns = []string{"kind", "uid", "title", "folder", "tags", "updated", "updated_by", "owner"}
+
+// DescribeResource extracts the inventory entry of a resource from its
+// metadata and from the fields common to many kinds.
+func DescribeResource(resource Resource) InventoryEntry {
+	entry := InventoryEntry{
+		Kind:   resource.Kind(),
+		UID:    resource.Name(),
+		Folder: resource.GetMetadata("folder"),
+	}
+	if title, ok := resource.GetSpecValue("title").(string); ok {
+		entry.Title = title
+	}
+	if tags, ok := resource.GetSpecValue("tags").([]any); ok {
+		for _, tag := range tags {
+			entry.Tags = append(entry.Tags, fmt.Sprint(tag))
+		}
+	}
+	return entry
+}
+
+// Inventory lists the remote resources matching the targets, and writes their
+// metadata to out in the given format, csv or parquet.
+func Inventory(registry Registry, targets []string, format string, out io.Writer) error {
+	if format != InventoryFormatCSV && format != InventoryFormatParquet {
+		return fmt.Errorf("unknown inventory format %s, expected %s or %s", format, InventoryFormatCSV, InventoryFormatParquet)
+	}
+
+	var entries []InventoryEntry
+	for name, handler := range registry.Handlers {
+		if !registry.HandlerMatchesTarget(handler, targets) {
+			continue
+		}
+
+		log.Debugf("Listing remote values for handler %s", name)
+		UIDs, err := handler.ListRemote()
+		if err != nil {
+			return classifyListError(handler, err)
+		}
+		matchingUIDs := make([]string, 0, len(UIDs))
+		for _, UID := range UIDs {
+			if registry.ResourceMatchesTarget(handler.Kind(), UID, targets) {
+				matchingUIDs = append(matchingUIDs, UID)
+			}
+		}
+
+		handlerEntries, err := describeRemote(registry, handler, matchingUIDs)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, handlerEntries...)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Kind != entries[j].Kind {
+			return entries[i].Kind < entries[j].Kind
+		}
+		return entries[i].UID < entries[j].UID
+	})
+
+	if format == InventoryFormatParquet {
+		return parquet.Write(out, entries)
+	}
+	return writeInventoryCSV(out, entries)
+}
+
+// describeRemote builds the inventory entries of remote resources, with the
+// details only handlers implementing InventoryDescriber know about.
+func describeRemote(registry Registry, handler Handler, UIDs []string) ([]InventoryEntry, error) {
+	entries := make([]InventoryEntry, 0, len(UIDs))
+	if describer, ok := handler.(InventoryDescriber); ok {
+		for _, UID := range UIDs {
+			entry, err := describer.DescribeRemote(UID)
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, ClassifyError(handler, err)
+			}
+			entries = append(entries, *entry)
+		}
+		return entries, nil
+	}
+
+	for _, result := range getByUIDs(registry, handler, UIDs) {
+		// resources deleted since they were listed are left out
+		if errors.Is(result.Err, ErrNotFound) {
+			continue
+		}
+		if result.Err != nil {
+			return nil, ClassifyError(handler, result.Err)
+		}
+		entries = append(entries, DescribeResource(*handler.Unprepare(*result.Resource)))
+	}
+	return entries, nil
+}
+
+func writeInventoryCSV(out io.Writer, entries []InventoryEntry) error {
+	w := csv.NewWriter(out)
+	if err := w.Write(inventoryColumns); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		updated := ""
+		if entry.Updated != nil {
+			updated = entry.Updated.UTC().Format(time.RFC3339)
+		}
+		record := []string{entry.Kind, entry.UID, entry.Title, entry.Folder, strings.Join(entry.Tags, ","), updated, entry.UpdatedBy, entry.Owner}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
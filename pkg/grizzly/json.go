@@ -48,3 +48,18 @@ func (parser *JSONParser) Parse(file string, options ParserOptions) (Resources,
 
 	return parseAny(parser.registry, m, options.DefaultResourceKind, options.DefaultFolderUID, source)
 }
+
+// NormaliseJSON copies a value through JSON, converting it to the types JSON
+// is decoded to, so that values read from YAML, Jsonnet and remote endpoints
+// can be compared. Values that can't be copied are returned as they are.
+func NormaliseJSON[T any](value T) T {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalised T
+	if err := json.Unmarshal(data, &normalised); err != nil {
+		return value
+	}
+	return normalised
+}
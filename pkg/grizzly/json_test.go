@@ -0,0 +1,29 @@
+package grizzly_test
+
+import (
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormaliseJSON(t *testing.T) {
+	t.Run("specs are copied with the types JSON is decoded to", func(t *testing.T) {
+		spec := map[string]any{"version": 3, "tags": []string{"prod"}}
+
+		normalised := grizzly.NormaliseJSON(spec)
+		require.Equal(t, map[string]any{"version": float64(3), "tags": []any{"prod"}}, normalised)
+
+		normalised["version"] = float64(4)
+		require.Equal(t, 3, spec["version"])
+	})
+
+	t.Run("values compare equal when their JSON does", func(t *testing.T) {
+		require.Equal(t, grizzly.NormaliseJSON[any](map[string]string{"a": "b"}), grizzly.NormaliseJSON[any](map[string]any{"a": "b"}))
+	})
+
+	t.Run("values that can't be encoded are returned as they are", func(t *testing.T) {
+		value := map[string]any{"invalid": make(chan int)}
+		require.Equal(t, value, grizzly.NormaliseJSON(value))
+	})
+}
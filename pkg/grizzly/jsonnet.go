@@ -7,22 +7,36 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/google/go-jsonnet"
 	"github.com/google/go-jsonnet/ast"
 	log "github.com/sirupsen/logrus"
 )
 
+// JsonnetVars are the external variables, read with std.extVar(), and the
+// top-level arguments Jsonnet files are evaluated with, by name. Values are
+// strings, or Jsonnet code for ExtCode and TLACode.
+type JsonnetVars struct {
+	ExtStr  map[string]string
+	ExtCode map[string]string
+	TLAStr  map[string]string
+	TLACode map[string]string
+}
+
 type JsonnetParser struct {
 	registry     Registry
 	jsonnetPaths []string
+	vars         JsonnetVars
 	logger       *log.Entry
 }
 
-func NewJsonnetParser(registry Registry, jsonnetPaths []string) *JsonnetParser {
+func NewJsonnetParser(registry Registry, jsonnetPaths []string, vars JsonnetVars) *JsonnetParser {
 	return &JsonnetParser{
 		registry:     registry,
 		jsonnetPaths: jsonnetPaths,
+		vars:         vars,
 		logger:       log.WithField("parser", "jsonnet"),
 	}
 }
@@ -44,7 +58,7 @@ func (parser *JsonnetParser) Parse(file string, options ParserOptions) (Resource
 	if err != nil {
 		return Resources{}, err
 	}
-	result, err := evaluateJsonnet(file, currentWorkingDirectory, parser.jsonnetPaths)
+	result, err := evaluateJsonnet(file, currentWorkingDirectory, parser.jsonnetPaths, parser.vars)
 	if err != nil {
 		return Resources{}, err
 	}
@@ -77,13 +91,38 @@ type importProcessor func(contents, foundAt string) (c *jsonnet.Contents, err er
 //go:embed grizzly.jsonnet
 var script string
 
-func evaluateJsonnet(jsonnetFile, wd string, jpath []string) (string, error) {
-	s := fmt.Sprintf(script, jsonnetFile)
+// tlaVarPrefix prefixes the names of the external variables top-level
+// arguments are passed as, since the script evaluated wraps the file given
+const tlaVarPrefix = "__grizzly_tla_"
+
+func evaluateJsonnet(jsonnetFile, wd string, jpath []string, vars JsonnetVars) (string, error) {
 	vm := jsonnet.MakeVM()
+	for name, value := range vars.ExtStr {
+		vm.ExtVar(name, value)
+	}
+	for name, value := range vars.ExtCode {
+		vm.ExtCode(name, value)
+	}
+
+	var args []string
+	for name, value := range vars.TLAStr {
+		vm.ExtVar(tlaVarPrefix+name, value)
+		args = append(args, fmt.Sprintf("%s=std.extVar('%s%s')", name, tlaVarPrefix, name))
+	}
+	for name, value := range vars.TLACode {
+		vm.ExtCode(tlaVarPrefix+name, value)
+		args = append(args, fmt.Sprintf("%s=std.extVar('%s%s')", name, tlaVarPrefix, name))
+	}
+	sort.Strings(args)
+
+	s := fmt.Sprintf(script, jsonnetFile, strings.Join(args, ", "))
 	vm.Importer(newExtendedImporter(jsonnetFile, wd, jpath))
 	vm.NativeFunction(escapeStringRegexNativeFunc())
 	vm.NativeFunction(regexMatchNativeFunc())
 	vm.NativeFunction(regexSubstNativeFunc())
+	for _, native := range helperNativeFuncs() {
+		vm.NativeFunction(native)
+	}
 
 	return vm.EvaluateAnonymousSnippet(jsonnetFile, s)
 }
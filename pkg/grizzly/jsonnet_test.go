@@ -0,0 +1,66 @@
+package grizzly_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grafana"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJsonnetVars(t *testing.T) {
+	registry := grizzly.NewRegistry([]grizzly.Provider{grafana.NewProvider(&config.GrafanaConfig{})})
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "function.jsonnet"), []byte(`function(team, replicas=1) {
+  apiVersion: 'grizzly.grafana.com/v1alpha1',
+  kind: 'Dashboard',
+  metadata: { name: team.name, folder: 'general' },
+  spec: { uid: team.name, title: '%s (%s)' % [team.name, std.extVar('env')], replicas: replicas, tags: std.extVar('tags') },
+}
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "object.jsonnet"), []byte(`{
+  apiVersion: 'grizzly.grafana.com/v1alpha1',
+  kind: 'Dashboard',
+  metadata: { name: 'object', folder: 'general' },
+  spec: { uid: 'object', title: 'Object (%s)' % std.extVar('env') },
+}
+`), 0644))
+
+	vars := grizzly.JsonnetVars{
+		ExtStr:  map[string]string{"env": "prod"},
+		ExtCode: map[string]string{"tags": "['a', 'b']"},
+		TLACode: map[string]string{"team": `{"name": "payments"}`},
+		TLAStr:  map[string]string{"replicas": "3"},
+	}
+
+	t.Run("functions are called with top-level arguments", func(t *testing.T) {
+		resources, err := grizzly.DefaultParser(registry, nil, nil, grizzly.ParserJsonnetVars(vars)).Parse(filepath.Join(dir, "function.jsonnet"), grizzly.ParserOptions{})
+		require.NoError(t, err)
+		dashboard, found := resources.Find(grizzly.NewResourceRef(grafana.DashboardKind, "payments"))
+		require.True(t, found)
+		require.Equal(t, map[string]any{"uid": "payments", "title": "payments (prod)", "replicas": "3", "tags": []any{"a", "b"}}, dashboard.Spec())
+	})
+
+	t.Run("top-level arguments are ignored by objects", func(t *testing.T) {
+		resources, err := grizzly.DefaultParser(registry, nil, nil, grizzly.ParserJsonnetVars(vars)).Parse(filepath.Join(dir, "object.jsonnet"), grizzly.ParserOptions{})
+		require.NoError(t, err)
+		dashboard, found := resources.Find(grizzly.NewResourceRef(grafana.DashboardKind, "object"))
+		require.True(t, found)
+		require.Equal(t, "Object (prod)", dashboard.Spec()["title"])
+	})
+
+	t.Run("later variables win", func(t *testing.T) {
+		parser := grizzly.DefaultParser(registry, nil, nil,
+			grizzly.ParserExtVars(map[string]string{"env": "staging"}),
+			grizzly.ParserJsonnetVars(vars),
+		)
+		resources, err := parser.Parse(filepath.Join(dir, "object.jsonnet"), grizzly.ParserOptions{})
+		require.NoError(t, err)
+		dashboard, _ := resources.Find(grizzly.NewResourceRef(grafana.DashboardKind, "object"))
+		require.Equal(t, "Object (prod)", dashboard.Spec()["title"])
+	})
+}
@@ -0,0 +1,44 @@
+package grizzly
+
+import (
+	"fmt"
+)
+
+// largeDiffPaths is how many changed paths are listed in the summaries of
+// the diffs of large resources
+const largeDiffPaths = 20
+
+// summarizeLargeDiff summarises the changes between two versions of a large
+// resource as the paths changed and their counts, rather than a diff that
+// would flood the output
+func summarizeLargeDiff(before, after Resource, size int, onlySpec bool) []string {
+	beforeBody, afterBody := before.Body, after.Body
+	if onlySpec {
+		beforeBody, afterBody = before.Spec(), after.Spec()
+	}
+	operations := DiffPatch(beforeBody, afterBody)
+
+	counts := map[string]int{}
+	for _, operation := range operations {
+		counts[operation.Op]++
+	}
+	summary := []string{
+		fmt.Sprintf("%d bytes, diff summarised (use --full-diff to show it): %d paths changed, %d added, %d removed",
+			size, counts["replace"], counts["add"], counts["remove"]),
+	}
+
+	for i, operation := range operations {
+		if i == largeDiffPaths {
+			summary = append(summary, fmt.Sprintf("and %d more", len(operations)-largeDiffPaths))
+			break
+		}
+		summary = append(summary, fmt.Sprintf("%s %s", operationVerbs[operation.Op], operation.Path))
+	}
+	return summary
+}
+
+var operationVerbs = map[string]string{
+	"add":     "added",
+	"remove":  "removed",
+	"replace": "changed",
+}
@@ -0,0 +1,50 @@
+package grizzly
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeLargeDiff(t *testing.T) {
+	panels := func(title string, count int) []any {
+		var panels []any
+		for i := 0; i < count; i++ {
+			panels = append(panels, map[string]any{"title": fmt.Sprintf("%s %d", title, i)})
+		}
+		return panels
+	}
+
+	before, err := NewResource("grizzly.grafana.com/v1alpha1", "Dashboard", "huge", map[string]any{
+		"title":  "Huge",
+		"panels": panels("Panel", 30),
+		"tags":   []any{"generated"},
+	})
+	require.NoError(t, err)
+
+	t.Run("changes are counted and listed", func(t *testing.T) {
+		after := before.DeepCopy()
+		after.SetSpecValue("title", "Huger")
+		after.SetSpecValue("description", "Generated")
+		after.DeleteSpecKey("tags")
+
+		require.Equal(t, []string{
+			"1234 bytes, diff summarised (use --full-diff to show it): 1 paths changed, 1 added, 1 removed",
+			"removed /tags",
+			"added /description",
+			"changed /title",
+		}, summarizeLargeDiff(before, after, 1234, true))
+	})
+
+	t.Run("long lists of changes are truncated", func(t *testing.T) {
+		after := before.DeepCopy()
+		after.SetSpecValue("panels", panels("Renamed", 30))
+
+		summary := summarizeLargeDiff(before, after, 1234, false)
+		require.Len(t, summary, largeDiffPaths+2)
+		require.Equal(t, "1234 bytes, diff summarised (use --full-diff to show it): 30 paths changed, 0 added, 0 removed", summary[0])
+		require.Equal(t, "changed /spec/panels/0/title", summary[1])
+		require.Equal(t, "and 10 more", summary[largeDiffPaths+1])
+	})
+}
@@ -0,0 +1,98 @@
+package grizzly
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
+)
+
+// BrokenLink describes a reference from a resource to another resource that doesn't exist
+type BrokenLink struct {
+	// Field locates the reference within the resource. Ex: rules[0].annotations.__dashboardUid__
+	Field   string
+	Message string
+	// Suggestion describes how the link can be repaired, if a fix was found
+	Suggestion string
+}
+
+// LinkValidator describes a handler whose resources reference other resources,
+// and that can check that those references are valid
+type LinkValidator interface {
+	// ValidateLinks checks the references of a resource against the given
+	// local resources, or else the remote ones. It returns the broken links,
+	// and the resource with all suggested fixes applied, if any was found.
+	ValidateLinks(resource Resource, local Resources) ([]BrokenLink, *Resource, error)
+}
+
+// ValidateLinks reports the broken references between resources. When fix is
+// true, the suggested fixes are written back to the files the resources were read from.
+func ValidateLinks(registry Registry, resources Resources, fix bool) error {
+	broken := 0
+
+	for _, resource := range resources.AsList() {
+		handler, err := registry.GetHandler(resource.Kind())
+		if err != nil {
+			return err
+		}
+		validator, ok := handler.(LinkValidator)
+		if !ok {
+			continue
+		}
+
+		links, fixed, err := validator.ValidateLinks(resource, resources)
+		if err != nil {
+			return ClassifyError(handler, err)
+		}
+		for _, link := range links {
+			message := fmt.Sprintf("%s: %s", link.Field, link.Message)
+			if link.Suggestion != "" {
+				message += fmt.Sprintf(" (suggested fix: %s)", link.Suggestion)
+			}
+			notifier.Error(resource, message)
+		}
+		if len(links) == 0 {
+			continue
+		}
+
+		if !fix || fixed == nil {
+			broken += len(links)
+			continue
+		}
+		if err := rewriteResource(registry, resource, *fixed); err != nil {
+			notifier.Error(resource, fmt.Sprintf("not fixed: %s", err))
+			broken += len(links)
+			continue
+		}
+		notifier.Info(resource, "fixed")
+		for _, link := range links {
+			if link.Suggestion == "" {
+				broken++
+			}
+		}
+	}
+
+	if broken > 0 {
+		return fmt.Errorf("found %s", Pluraliser(broken, "broken link"))
+	}
+	notifier.Info(nil, "No broken links found")
+	return nil
+}
+
+// rewriteResource writes an updated resource to the file the original was read from.
+func rewriteResource(registry Registry, original Resource, updated Resource) error {
+	if !original.Source.Rewritable || original.Source.Path == "" {
+		return fmt.Errorf("the source for this %s is not rewritable", original.Kind())
+	}
+
+	updated, err := SplitFileReferences(updated, original.Source.FileReferences, filepath.Dir(original.Source.Path))
+	if err != nil {
+		return err
+	}
+	content, _, _, err := Format(registry, "", &updated, original.Source.Format, !original.Source.WithEnvelope)
+	if err != nil {
+		return err
+	}
+
+	return WriteFile(original.Source.Path, content)
+}
@@ -0,0 +1,51 @@
+package grizzly
+
+import (
+	"sync"
+)
+
+// ListCache holds the responses of list endpoints for the duration of a run.
+// APIs without an endpoint to retrieve a single resource are listed for every
+// resource retrieved, which the cache reduces to a single request.
+type ListCache struct {
+	mu      sync.Mutex
+	entries map[string]any
+}
+
+// NewListCache returns an empty ListCache
+func NewListCache() *ListCache {
+	return &ListCache{entries: map[string]any{}}
+}
+
+// Invalidate empties the cache, so that lists are retrieved again. Handlers
+// invalidate their cache after writing to their endpoint.
+func (c *ListCache) Invalidate() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]any{}
+}
+
+// CachedList returns the response of the list identified by key, calling
+// fetch when it isn't cached yet, or when the handler doesn't cache lists.
+// Errors aren't cached. Cached responses are shared, and mustn't be modified.
+func CachedList[T any](h *BaseHandler, key string, fetch func() (T, error)) (T, error) {
+	c := h.listCache
+	if c == nil {
+		return fetch()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.entries[key]; ok {
+		return cached.(T), nil
+	}
+	response, err := fetch()
+	if err != nil {
+		return response, err
+	}
+	c.entries[key] = response
+	return response, nil
+}
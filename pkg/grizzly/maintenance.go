@@ -0,0 +1,329 @@
+package grizzly
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
+	"github.com/hashicorp/go-multierror"
+	log "github.com/sirupsen/logrus"
+)
+
+// Matcher selects alerts by label. Ex: severity=critical, team=~"db|infra"
+type Matcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual bool   `json:"isEqual"`
+}
+
+var matcherPattern = regexp.MustCompile(`^\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*(=~|!~|!=|=)\s*(.*?)\s*$`)
+
+// ParseMatcher parses a label matcher, as written in Alertmanager and PromQL selectors.
+func ParseMatcher(matcher string) (Matcher, error) {
+	parts := matcherPattern.FindStringSubmatch(matcher)
+	if parts == nil {
+		return Matcher{}, fmt.Errorf("invalid matcher %q, expected <label><operator><value> with one of =, !=, =~ or !~", matcher)
+	}
+
+	parsed := Matcher{
+		Name:    parts[1],
+		Value:   strings.Trim(parts[3], `"`),
+		IsRegex: strings.HasSuffix(parts[2], "~"),
+		IsEqual: !strings.HasPrefix(parts[2], "!"),
+	}
+	if parsed.IsRegex {
+		if _, err := regexp.Compile(parsed.Value); err != nil {
+			return Matcher{}, fmt.Errorf("invalid matcher %q: %w", matcher, err)
+		}
+	}
+	return parsed, nil
+}
+
+// Matches returns whether a value satisfies the matcher. As in Prometheus,
+// regular expressions are anchored.
+func (m Matcher) Matches(value string) bool {
+	matches := value == m.Value
+	if m.IsRegex {
+		re, err := regexp.Compile("^(?:" + m.Value + ")$")
+		if err != nil {
+			return false
+		}
+		matches = re.MatchString(value)
+	}
+	return matches == m.IsEqual
+}
+
+// String writes the matcher as it's parsed. Ex: team=~"db|infra"
+func (m Matcher) String() string {
+	var operator string
+	switch {
+	case m.IsEqual && m.IsRegex:
+		operator = "=~"
+	case m.IsEqual:
+		operator = "="
+	case m.IsRegex:
+		operator = "!~"
+	default:
+		operator = "!="
+	}
+	return m.Name + operator + strconv.Quote(m.Value)
+}
+
+// MaintenanceOptions describes what to silence, mute and pause during a maintenance window
+type MaintenanceOptions struct {
+	Duration time.Duration
+	Comment  string
+	// Matchers select the alerts to silence
+	Matchers []Matcher
+	// MuteTimings lists the names of the mute timings set to the maintenance
+	// window, which mute the notification policies referencing them
+	MuteTimings []string
+	// RuleGroups lists the UIDs of the alert rule groups to pause. Ex: <folder>.<group>
+	RuleGroups []string
+}
+
+// MaintenanceWindow records the changes made by a provider when starting a
+// maintenance window, so that they can be reverted.
+type MaintenanceWindow struct {
+	Provider    string                  `json:"provider"`
+	EndsAt      time.Time               `json:"endsAt"`
+	Silences    []string                `json:"silences,omitempty"`
+	MuteTimings []MaintenanceMuteTiming `json:"muteTimings,omitempty"`
+	// PausedRules lists, per rule group, the rules that were paused
+	PausedRules map[string][]string `json:"pausedRules,omitempty"`
+}
+
+// MaintenanceMuteTiming records a mute timing set to a maintenance window
+type MaintenanceMuteTiming struct {
+	Name string `json:"name"`
+	// Created tells whether the mute timing was created for the window, and is
+	// deleted afterwards
+	Created bool `json:"created,omitempty"`
+	// Previous holds the time intervals of the mute timing before the window,
+	// in the format of the provider, which are restored afterwards
+	Previous json.RawMessage `json:"previous,omitempty"`
+}
+
+// MaintenanceProvider describes a provider that can silence alerts and pause
+// alert rules for the duration of a maintenance window
+type MaintenanceProvider interface {
+	// StartMaintenance silences alerts and pauses rule groups. The changes
+	// made are returned even on error, so that they can be reverted.
+	StartMaintenance(opts MaintenanceOptions) (MaintenanceWindow, error)
+
+	// StopMaintenance reverts the changes made when a maintenance window started
+	StopMaintenance(window MaintenanceWindow) error
+}
+
+// StartMaintenance starts a maintenance window with every provider supporting
+// them, and records it in the given file, per context.
+func StartMaintenance(registry Registry, file, context string, opts MaintenanceOptions) error {
+	windows, err := readMaintenanceWindows(file)
+	if err != nil {
+		return err
+	}
+	for _, window := range windows[context] {
+		if window.EndsAt.After(time.Now()) {
+			return fmt.Errorf("a maintenance window is already in progress in context %s, stop it with `grr maintenance stop` first", context)
+		}
+	}
+
+	var finalErr error
+	for _, provider := range registry.Providers {
+		maintenanceProvider, ok := provider.(MaintenanceProvider)
+		if !ok {
+			continue
+		}
+		if err := provider.Validate(); err != nil {
+			log.Debugf("Skipping %s: %s", provider.Name(), err)
+			continue
+		}
+
+		window, err := maintenanceProvider.StartMaintenance(opts)
+		if err != nil {
+			finalErr = multierror.Append(finalErr, fmt.Errorf("%s: %w", provider.Name(), err))
+		}
+		if len(window.Silences) == 0 && len(window.MuteTimings) == 0 && len(window.PausedRules) == 0 {
+			continue
+		}
+
+		windows[context] = append(windows[context], window)
+		notifier.Info(notifier.SimpleString(provider.Name()), fmt.Sprintf("%s until %s", describeMaintenance(window), window.EndsAt.Format(time.RFC3339)))
+	}
+
+	if err := writeMaintenanceWindows(file, windows); err != nil {
+		finalErr = multierror.Append(finalErr, err)
+	}
+
+	return finalErr
+}
+
+// StopMaintenance reverts the changes made by the maintenance window in progress in a context.
+func StopMaintenance(registry Registry, file, context string) error {
+	windows, err := readMaintenanceWindows(file)
+	if err != nil {
+		return err
+	}
+	if len(windows[context]) == 0 {
+		notifier.Info(nil, fmt.Sprintf("No maintenance window in progress in context %s", context))
+		return nil
+	}
+
+	var finalErr error
+	var remaining []MaintenanceWindow
+	for _, window := range windows[context] {
+		if err := stopMaintenanceWindow(registry, window); err != nil {
+			finalErr = multierror.Append(finalErr, err)
+			remaining = append(remaining, window)
+			continue
+		}
+		notifier.Info(notifier.SimpleString(window.Provider), fmt.Sprintf("%s removed, %s restored and %s resumed",
+			Pluraliser(len(window.Silences), "silence"), Pluraliser(len(window.MuteTimings), "mute timing"), Pluraliser(countPausedRules(window), "alert rule")))
+	}
+
+	if err := updateMaintenanceWindows(file, windows, context, remaining); err != nil {
+		finalErr = multierror.Append(finalErr, err)
+	}
+	return finalErr
+}
+
+// MaintenanceStatus describes the maintenance windows of a context. Windows
+// that ended without being stopped still have their alert rules paused, until
+// they are stopped.
+func MaintenanceStatus(file, context string) error {
+	windows, err := MaintenanceWindows(file, context)
+	if err != nil {
+		return err
+	}
+	if len(windows) == 0 {
+		notifier.Info(nil, fmt.Sprintf("No maintenance window in progress in context %s", context))
+		return nil
+	}
+
+	for _, window := range windows {
+		if window.EndsAt.After(time.Now()) {
+			notifier.Info(notifier.SimpleString(window.Provider), fmt.Sprintf("%s until %s", describeMaintenance(window), window.EndsAt.Format(time.RFC3339)))
+			continue
+		}
+		notifier.Warn(notifier.SimpleString(window.Provider), fmt.Sprintf("maintenance window ended at %s, but %s still paused: run `grr maintenance stop` to resume them",
+			window.EndsAt.Format(time.RFC3339), Pluraliser(countPausedRules(window), "alert rule")))
+	}
+	return nil
+}
+
+// MaintenanceWindows returns the maintenance windows recorded in a context
+func MaintenanceWindows(file, context string) ([]MaintenanceWindow, error) {
+	windows, err := readMaintenanceWindows(file)
+	if err != nil {
+		return nil, err
+	}
+	return windows[context], nil
+}
+
+// WaitForMaintenance waits for the end of the maintenance windows of a
+// context, or for ctx to be done, such as when grr is interrupted, then stops
+// them, so that everything is restored without running grr again.
+func WaitForMaintenance(ctx context.Context, registry Registry, file, contextName string) error {
+	windows, err := MaintenanceWindows(file, contextName)
+	if err != nil {
+		return err
+	}
+	var endsAt time.Time
+	for _, window := range windows {
+		if window.EndsAt.After(endsAt) {
+			endsAt = window.EndsAt
+		}
+	}
+
+	timer := time.NewTimer(time.Until(endsAt))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		log.Infof("Stopping the maintenance window before its end")
+	}
+	return StopMaintenance(registry, file, contextName)
+}
+
+// stopMaintenanceWindow reverts the changes made by a maintenance window, with the provider that made them
+func stopMaintenanceWindow(registry Registry, window MaintenanceWindow) error {
+	provider, ok := findMaintenanceProvider(registry, window.Provider)
+	if !ok {
+		return fmt.Errorf("provider %s not found", window.Provider)
+	}
+	if err := provider.StopMaintenance(window); err != nil {
+		return fmt.Errorf("%s: %w", window.Provider, err)
+	}
+	return nil
+}
+
+// updateMaintenanceWindows records the maintenance windows remaining in a context
+func updateMaintenanceWindows(file string, windows map[string][]MaintenanceWindow, context string, remaining []MaintenanceWindow) error {
+	windows[context] = remaining
+	if len(remaining) == 0 {
+		delete(windows, context)
+	}
+	return writeMaintenanceWindows(file, windows)
+}
+
+func findMaintenanceProvider(registry Registry, name string) (MaintenanceProvider, bool) {
+	for _, provider := range registry.Providers {
+		if provider.Name() != name {
+			continue
+		}
+		maintenanceProvider, ok := provider.(MaintenanceProvider)
+		return maintenanceProvider, ok
+	}
+	return nil, false
+}
+
+// describeMaintenance lists what a maintenance window silences, mutes and pauses
+func describeMaintenance(window MaintenanceWindow) string {
+	return fmt.Sprintf("%s, %s and %s paused",
+		Pluraliser(len(window.Silences), "silence"), Pluraliser(len(window.MuteTimings), "mute timing"), Pluraliser(countPausedRules(window), "alert rule"))
+}
+
+func countPausedRules(window MaintenanceWindow) int {
+	count := 0
+	for _, rules := range window.PausedRules {
+		count += len(rules)
+	}
+	return count
+}
+
+func readMaintenanceWindows(file string) (map[string][]MaintenanceWindow, error) {
+	windows := map[string][]MaintenanceWindow{}
+
+	content, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return windows, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &windows); err != nil {
+		return nil, fmt.Errorf("reading maintenance windows from %s: %w", file, err)
+	}
+
+	return windows, nil
+}
+
+func writeMaintenanceWindows(file string, windows map[string][]MaintenanceWindow) error {
+	content, err := json.MarshalIndent(windows, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(content, '\n'), 0644)
+}
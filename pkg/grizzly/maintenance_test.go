@@ -0,0 +1,123 @@
+package grizzly_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMatcher(t *testing.T) {
+	tests := []struct {
+		matcher  string
+		expected grizzly.Matcher
+	}{
+		{matcher: "cluster=prod-eu", expected: grizzly.Matcher{Name: "cluster", Value: "prod-eu", IsEqual: true}},
+		{matcher: `team=~"db|infra"`, expected: grizzly.Matcher{Name: "team", Value: "db|infra", IsEqual: true, IsRegex: true}},
+		{matcher: "severity != info", expected: grizzly.Matcher{Name: "severity", Value: "info"}},
+		{matcher: "alertname!~Watchdog.*", expected: grizzly.Matcher{Name: "alertname", Value: "Watchdog.*", IsRegex: true}},
+	}
+	for _, test := range tests {
+		t.Run(test.matcher, func(t *testing.T) {
+			matcher, err := grizzly.ParseMatcher(test.matcher)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, matcher)
+		})
+	}
+
+	_, err := grizzly.ParseMatcher("not a matcher")
+	require.Error(t, err)
+}
+
+// maintenanceProvider records the maintenance windows it's asked to stop
+type maintenanceProvider struct {
+	grizzly.Provider
+	stopped []grizzly.MaintenanceWindow
+}
+
+func (p *maintenanceProvider) Name() string {
+	return "Grafana"
+}
+
+func (p *maintenanceProvider) Validate() error {
+	return nil
+}
+
+func (p *maintenanceProvider) StartMaintenance(opts grizzly.MaintenanceOptions) (grizzly.MaintenanceWindow, error) {
+	return grizzly.MaintenanceWindow{
+		Provider:    p.Name(),
+		EndsAt:      time.Now().Add(opts.Duration),
+		PausedRules: map[string][]string{"folder.group": {"rule"}},
+	}, nil
+}
+
+func (p *maintenanceProvider) StopMaintenance(window grizzly.MaintenanceWindow) error {
+	p.stopped = append(p.stopped, window)
+	return nil
+}
+
+func TestWaitForMaintenance(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "maintenance.json")
+	provider := &maintenanceProvider{}
+	registry := grizzly.Registry{Providers: []grizzly.Provider{provider}}
+
+	require.NoError(t, grizzly.StartMaintenance(registry, file, "default", grizzly.MaintenanceOptions{Duration: 100 * time.Millisecond}))
+	windows, err := grizzly.MaintenanceWindows(file, "default")
+	require.NoError(t, err)
+	require.Len(t, windows, 1)
+
+	start := time.Now()
+	require.NoError(t, grizzly.WaitForMaintenance(context.Background(), registry, file, "default"))
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond, "windows are stopped once they end")
+	require.Len(t, provider.stopped, 1)
+	require.Equal(t, windows[0].PausedRules, provider.stopped[0].PausedRules)
+	windows, err = grizzly.MaintenanceWindows(file, "default")
+	require.NoError(t, err)
+	require.Empty(t, windows)
+
+	t.Run("interrupted windows are stopped before their end", func(t *testing.T) {
+		require.NoError(t, grizzly.StartMaintenance(registry, file, "default", grizzly.MaintenanceOptions{Duration: time.Hour}))
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		require.NoError(t, grizzly.WaitForMaintenance(ctx, registry, file, "default"))
+		require.Len(t, provider.stopped, 2)
+	})
+}
+
+func TestMaintenanceWindows(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "maintenance.json")
+	ended := grizzly.MaintenanceWindow{
+		Provider:    "Grafana",
+		EndsAt:      time.Now().Add(-time.Minute).UTC().Truncate(time.Second),
+		Silences:    []string{"expired"},
+		PausedRules: map[string][]string{"folder.ended": {"rule"}},
+	}
+	content, err := json.Marshal(map[string][]grizzly.MaintenanceWindow{"other": {ended}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(file, content, 0644))
+
+	provider := &maintenanceProvider{}
+	registry := grizzly.Registry{Providers: []grizzly.Provider{provider}}
+	require.NoError(t, grizzly.MaintenanceStatus(file, "other"))
+	require.Empty(t, provider.stopped, "ended windows are only stopped explicitly")
+
+	windows, err := grizzly.MaintenanceWindows(file, "other")
+	require.NoError(t, err)
+	require.Equal(t, []grizzly.MaintenanceWindow{ended}, windows)
+
+	t.Run("a new window can start once the previous one ended", func(t *testing.T) {
+		err := grizzly.StartMaintenance(registry, file, "other", grizzly.MaintenanceOptions{Duration: time.Hour})
+		require.NoError(t, err)
+
+		err = grizzly.StartMaintenance(registry, file, "other", grizzly.MaintenanceOptions{Duration: time.Hour})
+		require.ErrorContains(t, err, "a maintenance window is already in progress in context other")
+
+		require.NoError(t, grizzly.StopMaintenance(registry, file, "other"))
+		require.Len(t, provider.stopped, 2, "stopping resumes the rules of ended windows too")
+	})
+}
@@ -0,0 +1,213 @@
+package grizzly
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// MetricLister describes a handler able to list the names of the metrics of
+// a datasource, such as a Prometheus datasource of Grafana
+type MetricLister interface {
+	// MetricNames lists the names of the metrics the datasource holds series of
+	MetricNames(datasource string) ([]string, error)
+}
+
+// MetricRecorder describes a handler whose resources record metrics, such as
+// the recording rules of a rule group
+type MetricRecorder interface {
+	// RecordedMetrics lists the names of the metrics a resource records
+	RecordedMetrics(resource Resource) []string
+}
+
+// metricPresence is the set of metrics known to exist: those of a datasource
+// along with those recorded by the resources being validated, which may not
+// have been applied yet
+type metricPresence struct {
+	datasource string
+	metrics    map[string]bool
+}
+
+// newMetricPresence lists the metrics of a datasource with the first handler
+// able to
+func newMetricPresence(registry Registry, resources Resources, datasource string) (*metricPresence, error) {
+	var lister MetricLister
+	for _, provider := range registry.Providers {
+		for _, handler := range provider.GetHandlers() {
+			if handlerLister, ok := handler.(MetricLister); ok && lister == nil {
+				lister = handlerLister
+			}
+		}
+	}
+	if lister == nil {
+		return nil, fmt.Errorf("no provider can list the metrics of datasource %s", datasource)
+	}
+
+	names, err := lister.MetricNames(datasource)
+	if err != nil {
+		return nil, fmt.Errorf("listing the metrics of datasource %s: %w", datasource, err)
+	}
+	presence := &metricPresence{datasource: datasource, metrics: map[string]bool{}}
+	for _, name := range names {
+		presence.metrics[name] = true
+	}
+	for _, resource := range resources.AsList() {
+		handler, err := registry.GetHandler(resource.Kind())
+		if err != nil {
+			return nil, err
+		}
+		if recorder, ok := handler.(MetricRecorder); ok {
+			for _, name := range recorder.RecordedMetrics(resource) {
+				presence.metrics[name] = true
+			}
+		}
+	}
+	return presence, nil
+}
+
+// problems returns the metrics referenced by the PromQL expressions of a rule
+// group that don't exist in the datasource, as rules on them can never fire
+func (p *metricPresence) problems(handler Handler, resource Resource) []string {
+	queryHandler, isQueryHandler := handler.(QueryHandler)
+	_, isRuleGroupHandler := handler.(RuleGroupHandler)
+	if p == nil || !isQueryHandler || !isRuleGroupHandler {
+		return nil
+	}
+
+	var problems []string
+	for _, query := range queryHandler.Queries(resource) {
+		if query.Language != QueryLanguagePromQL {
+			continue
+		}
+		value, _, _ := lookupPointer(resource.Spec(), query.Pointer)
+		expr, _ := value.(string)
+		for _, name := range PromQLMetricNames(expr) {
+			if !p.metrics[name] {
+				problems = append(problems, fmt.Sprintf("%s: metric %s doesn't exist in datasource %s", query.Name, name, p.datasource))
+			}
+		}
+	}
+	return problems
+}
+
+// promQLKeywords are the words of PromQL which aren't metric names when they
+// aren't followed by a parenthesis
+var promQLKeywords = []string{
+	"and", "or", "unless", "bool", "offset", "by", "without", "on", "ignoring",
+	"group_left", "group_right", "inf", "nan", "start", "end",
+}
+
+// promQLAggregations are the aggregation operators, which can be followed by
+// their grouping before their parenthesis. Ex: sum by (job) (...)
+var promQLAggregations = []string{
+	"sum", "min", "max", "avg", "group", "stddev", "stdvar", "count",
+	"count_values", "bottomk", "topk", "quantile", "limitk", "limit_ratio",
+}
+
+// promQLGroupings are the keywords followed by a list of labels
+var promQLGroupings = []string{"by", "without", "on", "ignoring", "group_left", "group_right"}
+
+var nameMatcher = regexp.MustCompile(`__name__\s*=\s*"([^"]+)"`)
+
+// PromQLMetricNames returns the names of the metrics selected by a PromQL
+// expression, sorted. Functions, keywords, labels and the variables of
+// dashboards, such as $job, are skipped.
+func PromQLMetricNames(expr string) []string {
+	runes := []rune(expr)
+	names := map[string]bool{}
+
+	// skipSpaces returns the position of the next character other than a space
+	skipSpaces := func(i int) int {
+		for i < len(runes) && unicode.IsSpace(runes[i]) {
+			i++
+		}
+		return i
+	}
+	// skipGroup returns the position after the closing character of a group
+	skipGroup := func(i int, closing rune) int {
+		for i++; i < len(runes) && runes[i] != closing; i++ {
+			if runes[i] == '"' || runes[i] == '\'' || runes[i] == '`' {
+				i = skipString(runes, i) - 1
+			}
+		}
+		return i + 1
+	}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '"' || r == '\'' || r == '`':
+			i = skipString(runes, i)
+		case r == '#':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '{':
+			end := min(skipGroup(i, '}'), len(runes))
+			for _, match := range nameMatcher.FindAllStringSubmatch(string(runes[i:end]), -1) {
+				names[match[1]] = true
+			}
+			i = end
+		case r == '[':
+			i = skipGroup(i, ']')
+		case r == '$':
+			i++
+			if i < len(runes) && runes[i] == '{' {
+				i = skipGroup(i, '}')
+			}
+			for i < len(runes) && isMetricNameRune(runes[i]) {
+				i++
+			}
+		case unicode.IsDigit(r) || r == '.':
+			for i < len(runes) && (isMetricNameRune(runes[i]) || runes[i] == '.') {
+				i++
+			}
+		case isMetricNameRune(r):
+			start := i
+			for i < len(runes) && isMetricNameRune(runes[i]) {
+				i++
+			}
+			word := string(runes[start:i])
+			next := skipSpaces(i)
+			switch {
+			case slices.Contains(promQLGroupings, word) && next < len(runes) && runes[next] == '(':
+				i = skipGroup(next, ')')
+			case next < len(runes) && runes[next] == '(':
+				// a function or an aggregation
+			case slices.Contains(promQLAggregations, word) && slices.ContainsFunc(promQLGroupings[:2], func(grouping string) bool {
+				return strings.HasPrefix(string(runes[next:]), grouping)
+			}):
+			case slices.Contains(promQLKeywords, strings.ToLower(word)):
+			default:
+				names[word] = true
+			}
+		default:
+			i++
+		}
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+func isMetricNameRune(r rune) bool {
+	return r == '_' || r == ':' || r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// skipString returns the position after the string starting at i
+func skipString(runes []rune, i int) int {
+	quote := runes[i]
+	for i++; i < len(runes) && runes[i] != quote; i++ {
+		if runes[i] == '\\' && quote != '`' {
+			i++
+		}
+	}
+	return i + 1
+}
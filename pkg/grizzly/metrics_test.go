@@ -0,0 +1,23 @@
+package grizzly_test
+
+import (
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromQLMetricNames(t *testing.T) {
+	for expr, expected := range map[string][]string{
+		`up == 0`: {"up"},
+		`sum by (job) (rate(http_requests_total{code=~"5.."}[5m])) / sum without (code) (rate(http_requests_total[5m]))`: {"http_requests_total"},
+		`sum(rate(errors_total[5m])) by (job) > bool 0.05`:                                                               {"errors_total"},
+		`job:errors:rate5m{env="prod"} > 0.05 and on(job) group_left(team) team_info offset 1h`:                          {"job:errors:rate5m", "team_info"},
+		`histogram_quantile(0.99, sum by (le) (rate(latency_bucket{job="$job"}[$__rate_interval])))`:                     {"latency_bucket"},
+		`{__name__="legacy_metric", job="api"} unless absent(other_metric)`:                                              {"legacy_metric", "other_metric"},
+		`count_values("version", build_info) # by version`:                                                               {"build_info"},
+		`vector(1) + scalar(${metric}) + 1e3`:                                                                            {},
+	} {
+		require.Equal(t, expected, grizzly.PromQLMetricNames(expr), expr)
+	}
+}
@@ -11,73 +11,78 @@ var (
 	red    = color.New(color.FgRed).SprintFunc()
 	yellow = color.New(color.FgYellow).SprintFunc()
 	green  = color.New(color.FgGreen).SprintFunc()
+	cyan   = color.New(color.FgCyan).SprintFunc()
+	bold   = color.New(color.Bold).SprintFunc()
 )
 
 // NoChanges announces that nothing has changed
 func NoChanges(obj fmt.Stringer) {
-	fmt.Printf("%s %s\n", obj.String(), yellow("no differences"))
+	announce(obj, "no differences", yellow)
 }
 
-// HasChanges announces that a resource has changed, and displays the differences
-func HasChanges(obj fmt.Stringer, diff string) {
-	fmt.Printf("%s %s\n", obj.String(), red("changes detected:"))
-	fmt.Println(diff)
+// HasChanges announces that a resource has changed, and displays the
+// differences, preceded by a summary of them if one is given. Differences
+// shown by other means, such as a difftool, are left empty.
+func HasChanges(obj fmt.Stringer, diff string, summary ...string) {
+	announce(obj, "changes detected:", red)
+	for _, line := range summary {
+		output.write("  * " + wrap(4, line) + "\n")
+	}
+	if diff != "" {
+		output.write(colorizeDiff(diff) + "\n")
+	}
 }
 
 // NotFound announces that a resource was not found on the remote endpoint
 func NotFound(obj fmt.Stringer) {
-	fmt.Printf("%s %s\n", obj.String(), yellow("not found"))
+	announce(obj, "not found", yellow)
 }
 
 // Added announces that a resource has been added to the remote endpoint
 func Added(obj fmt.Stringer) {
-	fmt.Printf("%s %s\n", obj.String(), green("added"))
+	announce(obj, "added", green)
 }
 
 // Updated announces that a resource has been updated at the remote endpoint
 func Updated(obj fmt.Stringer) {
-	fmt.Printf("%s %s\n", obj.String(), green("updated"))
+	announce(obj, "updated", green)
 }
 
 // NotSupported announces that a behaviour is not supported by a handler
 func NotSupported(obj fmt.Stringer, behaviour string) {
-	fmt.Printf("%s %s\n", obj.String(), red("does not support "+behaviour))
+	announce(obj, "does not support "+behaviour, red)
 }
 
 // Info announces a message in green
 func Info(obj fmt.Stringer, msg string) {
-	if obj == nil {
-		fmt.Println(green(msg))
-	} else {
-		fmt.Printf("%s %s\n", obj.String(), green(msg))
-	}
+	announce(obj, msg, green)
 }
 
 // Info announces a message in green (to stderr)
 func InfoStderr(obj fmt.Stringer, msg string) {
-	if obj == nil {
-		os.Stderr.WriteString(green(msg) + "\n")
-	} else {
-		os.Stderr.WriteString(fmt.Sprintf("%s %s\n", obj.String(), green(msg)))
-	}
+	os.Stderr.WriteString(format(obj, msg, green))
 }
 
 // Warn announces a message in yellow
 func Warn(obj fmt.Stringer, msg string) {
-	if obj == nil {
-		fmt.Println(yellow(msg))
-	} else {
-		fmt.Printf("%s %s\n", obj.String(), yellow(msg))
-	}
+	announce(obj, msg, yellow)
 }
 
 // Error announces a message in yellow
 func Error(obj fmt.Stringer, msg string) {
+	announce(obj, msg, red)
+}
+
+func announce(obj fmt.Stringer, msg string, colorize func(a ...any) string) {
+	output.write(format(obj, msg, colorize))
+}
+
+func format(obj fmt.Stringer, msg string, colorize func(a ...any) string) string {
 	if obj == nil {
-		fmt.Println(red(msg))
-	} else {
-		fmt.Printf("%s %s\n", obj.String(), red(msg))
+		return colorize(wrap(0, msg)) + "\n"
 	}
+	prefix := obj.String()
+	return fmt.Sprintf("%s %s\n", prefix, colorize(wrap(len(prefix)+1, msg)))
 }
 
 type SimpleString string
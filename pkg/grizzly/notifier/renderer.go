@@ -0,0 +1,183 @@
+package notifier
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/term"
+)
+
+// Options configures how output is rendered
+type Options struct {
+	// NoColor disables colored output. Color is also disabled when NO_COLOR
+	// is set or when the output is not a terminal.
+	NoColor bool
+	// NoPager disables paging long output, such as diffs
+	NoPager bool
+}
+
+// renderer is the single place where output is written to the terminal.
+type renderer struct {
+	out      io.Writer
+	terminal bool
+	noPager  bool
+	// paging buffers output while it is rendered, to decide whether it must be paged
+	paging *bytes.Buffer
+}
+
+//nolint:gochecknoglobals
+var output = &renderer{
+	out:      os.Stdout,
+	terminal: term.IsTerminal(int(os.Stdout.Fd())),
+}
+
+// Configure sets rendering options for all subsequent output
+func Configure(opts Options) {
+	if opts.NoColor {
+		color.NoColor = true
+	}
+	output.noPager = opts.NoPager
+}
+
+// ColorEnabled indicates whether output should be colored
+func ColorEnabled() bool {
+	return !color.NoColor
+}
+
+// Print outputs content as-is, such as rendered resources
+func Print(content string) {
+	output.write(content + "\n")
+}
+
+// Printf outputs formatted content as-is
+func Printf(format string, args ...any) {
+	output.write(fmt.Sprintf(format, args...))
+}
+
+// Paged renders the output of the given function through a pager, if it
+// doesn't fit in the terminal.
+func Paged(render func() error) error {
+	if output.paging != nil || output.noPager || !output.terminal {
+		return render()
+	}
+
+	output.paging = &bytes.Buffer{}
+	err := render()
+	content := output.paging.String()
+	output.paging = nil
+
+	_, height := Size()
+	if height == 0 || strings.Count(content, "\n") < height {
+		output.write(content)
+		return err
+	}
+
+	if pageErr := page(content); pageErr != nil {
+		log.Debugf("Paging failed, printing instead: %s", pageErr)
+		output.write(content)
+	}
+	return err
+}
+
+// Size returns the width and height of the terminal. When the output isn't a
+// terminal, the width is read from the COLUMNS environment variable, and the
+// height is 0.
+func Size() (int, int) {
+	if output.terminal {
+		width, height, err := term.GetSize(int(os.Stdout.Fd()))
+		if err == nil {
+			return width, height
+		}
+	}
+
+	width, _ := strconv.Atoi(os.Getenv("COLUMNS"))
+	return width, 0
+}
+
+func (r *renderer) write(content string) {
+	var err error
+	if r.paging != nil {
+		_, err = r.paging.WriteString(content)
+	} else {
+		_, err = io.WriteString(r.out, content)
+	}
+	if err != nil {
+		log.Debugf("writing output: %s", err)
+	}
+}
+
+func page(content string) error {
+	pager := os.Getenv("GRR_PAGER")
+	if pager == "" {
+		pager = os.Getenv("PAGER")
+	}
+	if pager == "" {
+		pager = "less -R"
+	}
+
+	fields := strings.Fields(pager)
+	cmd := exec.Command(fields[0], fields[1:]...)
+	cmd.Stdin = strings.NewReader(content)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// wrap wraps a message so that, once prefixed, it fits within the output width.
+// Continuation lines are indented to line up with the start of the message.
+func wrap(prefixLength int, msg string) string {
+	width, _ := Size()
+	available := width - prefixLength
+	if width <= 0 || available < 20 || len(msg) <= available {
+		return msg
+	}
+
+	var lines []string
+	for _, paragraph := range strings.Split(msg, "\n") {
+		if len(paragraph) <= available {
+			lines = append(lines, paragraph)
+			continue
+		}
+
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			if line != "" && len(line)+1+len(word) > available {
+				lines = append(lines, line)
+				line = word
+				continue
+			}
+			if line != "" {
+				line += " "
+			}
+			line += word
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n"+strings.Repeat(" ", prefixLength))
+}
+
+// colorizeDiff colors the lines of a unified diff
+func colorizeDiff(diff string) string {
+	lines := strings.Split(diff, "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			lines[i] = bold(line)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = cyan(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = green(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = red(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
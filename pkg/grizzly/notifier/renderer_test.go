@@ -0,0 +1,129 @@
+package notifier
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/require"
+)
+
+// captureOutput renders output to a buffer for the duration of a test
+func captureOutput(t *testing.T, terminal bool) *bytes.Buffer {
+	t.Helper()
+	previous, previousNoColor := output, color.NoColor
+	t.Cleanup(func() {
+		output, color.NoColor = previous, previousNoColor
+	})
+
+	buffer := &bytes.Buffer{}
+	output = &renderer{out: buffer, terminal: terminal}
+	return buffer
+}
+
+func TestWrap(t *testing.T) {
+	captureOutput(t, false)
+
+	t.Setenv("COLUMNS", "30")
+	wrapped := wrap(4, "the quick brown fox jumps over the lazy dog and keeps running")
+	lines := strings.Split(wrapped, "\n")
+	require.Greater(t, len(lines), 1)
+	for i, line := range lines {
+		require.LessOrEqual(t, len(line), 30, line)
+		if i > 0 {
+			require.True(t, strings.HasPrefix(line, "    "), "continuation lines are indented: %q", line)
+		}
+	}
+	require.Equal(t, "the quick brown fox jumps over the lazy dog and keeps running", strings.Join(strings.Fields(wrapped), " "), "no word is lost")
+
+	require.Equal(t, "short", wrap(4, "short"), "messages fitting are left as-is")
+
+	t.Setenv("COLUMNS", "")
+	long := strings.Repeat("word ", 50)
+	require.Equal(t, long, wrap(4, long), "messages aren't wrapped when the width is unknown")
+
+	t.Setenv("COLUMNS", "20")
+	require.Equal(t, long, wrap(4, long), "messages aren't wrapped in too narrow terminals")
+}
+
+func TestColorizeDiff(t *testing.T) {
+	captureOutput(t, false)
+	diff := "--- remote\n+++ local\n@@ -1 +1 @@\n-old\n+new\n same"
+
+	color.NoColor = true
+	require.Equal(t, diff, colorizeDiff(diff), "diffs aren't colored when color is disabled")
+
+	color.NoColor = false
+	lines := strings.Split(colorizeDiff(diff), "\n")
+	require.Equal(t, color.New(color.Bold).Sprint("--- remote"), lines[0])
+	require.Equal(t, color.New(color.Bold).Sprint("+++ local"), lines[1])
+	require.Equal(t, color.New(color.FgCyan).Sprint("@@ -1 +1 @@"), lines[2])
+	require.Equal(t, color.New(color.FgRed).Sprint("-old"), lines[3])
+	require.Equal(t, color.New(color.FgGreen).Sprint("+new"), lines[4])
+	require.Equal(t, " same", lines[5], "context lines aren't colored")
+}
+
+func TestConfigureNoColor(t *testing.T) {
+	buffer := captureOutput(t, false)
+
+	color.NoColor = false
+	require.True(t, ColorEnabled())
+	Configure(Options{NoColor: true})
+	require.False(t, ColorEnabled())
+
+	HasChanges(stringer("Dashboard.a"), "-old\n+new")
+	require.NotContains(t, buffer.String(), "\x1b[", "no escape sequences are written without color")
+	require.Contains(t, buffer.String(), "-old\n+new\n")
+}
+
+func TestPaged(t *testing.T) {
+	t.Run("output that isn't a terminal isn't paged", func(t *testing.T) {
+		buffer := captureOutput(t, false)
+		t.Setenv("GRR_PAGER", "false")
+
+		require.NoError(t, Paged(func() error {
+			Print("first")
+			require.Equal(t, "first\n", buffer.String(), "output is written as it is rendered")
+			Print("second")
+			return nil
+		}))
+		require.Equal(t, "first\nsecond\n", buffer.String())
+	})
+
+	t.Run("paging can be disabled", func(t *testing.T) {
+		buffer := captureOutput(t, true)
+		Configure(Options{NoPager: true})
+		t.Setenv("GRR_PAGER", "false")
+
+		require.NoError(t, Paged(func() error {
+			Print("content")
+			return nil
+		}))
+		require.Equal(t, "content\n", buffer.String())
+	})
+
+	t.Run("output fitting the terminal is written once rendered", func(t *testing.T) {
+		buffer := captureOutput(t, true)
+		t.Setenv("GRR_PAGER", "false")
+
+		require.NoError(t, Paged(func() error {
+			Print("outer")
+			// nested paged output is part of the outer one
+			require.NoError(t, Paged(func() error {
+				Print("inner")
+				return nil
+			}))
+			require.Empty(t, buffer.String(), "output is buffered while it is rendered")
+			return nil
+		}))
+		require.Equal(t, "outer\ninner\n", buffer.String())
+		require.Nil(t, output.paging)
+	})
+}
+
+type stringer string
+
+func (s stringer) String() string {
+	return string(s)
+}
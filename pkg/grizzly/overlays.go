@@ -0,0 +1,78 @@
+package grizzly
+
+import (
+	log "github.com/sirupsen/logrus"
+)
+
+// OverlayParser parses resources, then merges over them the resources of
+// overlays: objects are merged key by key, and other values replaced.
+// Resources of overlays matching none of the parsed resources are added.
+type OverlayParser struct {
+	decorated Parser
+	overlays  []string
+	logger    *log.Entry
+}
+
+func NewOverlayParser(decorated Parser, overlays []string) *OverlayParser {
+	return &OverlayParser{
+		decorated: decorated,
+		overlays:  overlays,
+		logger:    log.WithField("parser", "overlay"),
+	}
+}
+
+func (parser *OverlayParser) Accept(file string) bool {
+	return parser.decorated.Accept(file)
+}
+
+func (parser *OverlayParser) Parse(resourcePath string, options ParserOptions) (Resources, error) {
+	resources, err := parser.decorated.Parse(resourcePath, options)
+	if err != nil {
+		return resources, err
+	}
+
+	for _, overlay := range parser.overlays {
+		parser.logger.WithField("overlay", overlay).Debug("Parsing overlay")
+
+		overlayResources, err := parser.decorated.Parse(overlay, options)
+		if err != nil {
+			return resources, err
+		}
+		resources = ApplyOverlay(resources, overlayResources)
+	}
+
+	return resources, nil
+}
+
+// ApplyOverlay merges the resources of an overlay over resources. Merged
+// resources aren't rewritable, as their files no longer describe them.
+func ApplyOverlay(resources Resources, overlay Resources) Resources {
+	merged := NewResources(resources.AsList()...)
+	_ = overlay.ForEach(func(resource Resource) error {
+		base, found := resources.Find(resource.Ref())
+		if !found {
+			merged.Add(resource)
+			return nil
+		}
+
+		result := base.DeepCopy()
+		result.Body = mergeOverlayValue(result.Body, deepCopyValue(resource.Body)).(map[string]any)
+		result.Source.Rewritable = false
+		merged.Add(result)
+		return nil
+	})
+	return merged
+}
+
+func mergeOverlayValue(base, overlay any) any {
+	baseObject, baseIsObject := base.(map[string]any)
+	overlayObject, overlayIsObject := overlay.(map[string]any)
+	if !baseIsObject || !overlayIsObject {
+		return overlay
+	}
+
+	for key, value := range overlayObject {
+		baseObject[key] = mergeOverlayValue(baseObject[key], value)
+	}
+	return baseObject
+}
@@ -0,0 +1,59 @@
+package grizzly_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grafana"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverlays(t *testing.T) {
+	registry := grizzly.NewRegistry([]grizzly.Provider{grafana.NewProvider(&config.GrafanaConfig{})})
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "overlays", "prod"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dashboard.jsonnet"), []byte(`{
+  apiVersion: 'grizzly.grafana.com/v1alpha1',
+  kind: 'Dashboard',
+  metadata: { name: 'api', folder: 'general' },
+  spec: { uid: 'api', title: 'API (%s)' % std.extVar('cluster'), refresh: '1m', tags: ['api'] },
+}
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "overlays", "prod", "dashboards.yaml"), []byte(`apiVersion: grizzly.grafana.com/v1alpha1
+kind: Dashboard
+metadata:
+    name: api
+spec:
+    refresh: 5m
+    tags: [api, prod]
+---
+apiVersion: grizzly.grafana.com/v1alpha1
+kind: Dashboard
+metadata:
+    name: prod-only
+    folder: general
+spec:
+    uid: prod-only
+    title: Prod only
+`), 0644))
+
+	parser := grizzly.DefaultParser(registry, nil, nil,
+		grizzly.ParserOverlays([]string{filepath.Join(dir, "overlays", "prod")}),
+		grizzly.ParserExtVars(map[string]string{"cluster": "prod-eu"}),
+	)
+	resources, err := parser.Parse(filepath.Join(dir, "dashboard.jsonnet"), grizzly.ParserOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 2, resources.Len())
+
+	api, found := resources.Find(grizzly.NewResourceRef(grafana.DashboardKind, "api"))
+	require.True(t, found)
+	require.Equal(t, map[string]any{"uid": "api", "title": "API (prod-eu)", "refresh": "5m", "tags": []any{"api", "prod"}}, api.Spec())
+	require.Equal(t, "general", api.GetMetadata("folder"), "metadata not set by overlays is kept")
+
+	_, found = resources.Find(grizzly.NewResourceRef(grafana.DashboardKind, "prod-only"))
+	require.True(t, found, "resources only found in overlays are added")
+}
@@ -40,6 +40,8 @@ type Parser interface {
 
 type parsersConfig struct {
 	continueOnError bool
+	overlays        []string
+	jsonnetVars     JsonnetVars
 }
 
 type ParserOpt func(config *parsersConfig)
@@ -50,6 +52,42 @@ func ParserContinueOnError(continueOnError bool) ParserOpt {
 	}
 }
 
+// ParserOverlays merges the resources found at the given paths over the
+// parsed resources
+func ParserOverlays(overlays []string) ParserOpt {
+	return func(config *parsersConfig) {
+		config.overlays = append(config.overlays, overlays...)
+	}
+}
+
+// ParserExtVars sets external variables of Jsonnet, read with std.extVar()
+func ParserExtVars(extVars map[string]string) ParserOpt {
+	return ParserJsonnetVars(JsonnetVars{ExtStr: extVars})
+}
+
+// ParserJsonnetVars sets external variables and top-level arguments of
+// Jsonnet. Variables set by later options win.
+func ParserJsonnetVars(vars JsonnetVars) ParserOpt {
+	return func(config *parsersConfig) {
+		mergeVars(&config.jsonnetVars.ExtStr, vars.ExtStr)
+		mergeVars(&config.jsonnetVars.ExtCode, vars.ExtCode)
+		mergeVars(&config.jsonnetVars.TLAStr, vars.TLAStr)
+		mergeVars(&config.jsonnetVars.TLACode, vars.TLACode)
+	}
+}
+
+func mergeVars(vars *map[string]string, values map[string]string) {
+	if len(values) == 0 {
+		return
+	}
+	if *vars == nil {
+		*vars = map[string]string{}
+	}
+	for name, value := range values {
+		(*vars)[name] = value
+	}
+}
+
 func DefaultParser(registry Registry, targets []string, jsonnetPaths []string, opts ...ParserOpt) Parser {
 	config := &parsersConfig{}
 
@@ -57,15 +95,17 @@ func DefaultParser(registry Registry, targets []string, jsonnetPaths []string, o
 		opt(config)
 	}
 
-	return NewFilteredParser(
-		registry,
-		NewChainParser([]FormatParser{
-			NewJSONParser(registry),
-			NewYAMLParser(registry),
-			NewJsonnetParser(registry, jsonnetPaths),
-		}, config.continueOnError),
-		targets,
-	)
+	var parser Parser = NewChainParser([]FormatParser{
+		NewJSONParser(registry),
+		NewYAMLParser(registry),
+		NewJsonnetParser(registry, jsonnetPaths, config.jsonnetVars),
+	}, config.continueOnError)
+	if len(config.overlays) > 0 {
+		parser = NewOverlayParser(parser, config.overlays)
+	}
+	parser = NewFanOutParser(registry, parser)
+
+	return NewFilteredParser(registry, parser, targets)
 }
 
 type FilteredParser struct {
@@ -105,7 +145,8 @@ func (parser *FilteredParser) Parse(resourcePath string, options ParserOptions)
 		return result
 	})
 
-	return parser.registry.Sort(resources), nil
+	resources = parser.registry.Sort(resources)
+	return resources, parser.registry.CheckDependencies(resources)
 }
 
 type ChainParser struct {
@@ -150,7 +191,7 @@ func (parser *ChainParser) Parse(resourcePath string, options ParserOptions) (Re
 			return err
 		}
 
-		if info.IsDir() {
+		if info.IsDir() || isQueryFile(path) || isFilenameManifest(path) {
 			return nil
 		}
 
@@ -205,6 +246,8 @@ func parseAny(registry Registry, data any, resourceKind, folderUID string, sourc
 	hasEnvelope := DetectEnvelope(data)
 	if hasEnvelope {
 		m := data.(map[string]any)
+		registry.warnConversions(m, registry.ConvertLegacy(m), source)
+
 		err := ValidateEnvelope(m)
 		if err != nil {
 			return Resources{}, err
@@ -216,6 +259,10 @@ func parseAny(registry Registry, data any, resourceKind, folderUID string, sourc
 
 		source.WithEnvelope = true
 		resource.SetSource(source)
+		registry.inferMetadata(resource)
+		if err := inlineFileReferences(resource, filepath.Dir(source.Path)); err != nil {
+			return Resources{}, err
+		}
 
 		return NewResources(*resource), nil
 	}
@@ -252,6 +299,10 @@ func parseAny(registry Registry, data any, resourceKind, folderUID string, sourc
 		if handler.UsesFolders() {
 			resource.SetMetadata("folder", folderUID)
 		}
+		registry.inferMetadata(&resource)
+		if err := inlineFileReferences(&resource, filepath.Dir(source.Path)); err != nil {
+			return Resources{}, err
+		}
 
 		return NewResources(resource), nil
 	}
@@ -401,6 +452,8 @@ func (w *walker) walkObj(obj map[string]any, path trace) error {
 	if validateErr != nil {
 		// this is not an envelope, skip.
 	} else {
+		w.registry.warnConversions(obj, w.registry.ConvertLegacy(obj), w.source)
+
 		resource, err := ResourceFromMap(obj)
 		if err != nil {
 			return err
@@ -2,6 +2,8 @@ package grizzly_test
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/grafana/grizzly/pkg/grafana"
@@ -212,6 +214,16 @@ func TestParseKindDetection(t *testing.T) {
 				InputFile:     "testdata/parsing/datasource-without-envelope.json",
 				ExpectedError: "parse error in 'testdata/parsing/datasource-without-envelope.json': found invalid object (at .): errors parsing resource: kind missing, metadata missing, spec missing\n\naccess: proxy\nisDefault: true\njsonData:\n    httpMethod: GET\ntype: prometheus\nurl: http://localhost/prometheus/\n",
 			},
+			{
+				Name:         "yaml folder input, with legacy kind and apiVersion",
+				InputFile:    "testdata/parsing/legacy-folder.yaml",
+				ExpectedKind: "DashboardFolder",
+			},
+			{
+				Name:         "json datasource input, with legacy kind and no apiVersion",
+				InputFile:    "testdata/parsing/legacy-datasource.json",
+				ExpectedKind: "Datasource",
+			},
 		}
 
 		parser := grizzly.DefaultParser(registry, nil, nil)
@@ -241,3 +253,64 @@ func TestParseKindDetection(t *testing.T) {
 		}
 	})
 }
+
+func TestConvert(t *testing.T) {
+	registry := grizzly.NewRegistry(
+		[]grizzly.Provider{
+			&grafana.Provider{},
+		},
+	)
+
+	t.Run("yaml files keep their comments", func(t *testing.T) {
+		file := copyTestdata(t, "testdata/parsing/legacy-folder.yaml")
+
+		require.NoError(t, grizzly.Convert(registry, file))
+
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		require.Equal(t, `# A folder written for an older release of grizzly
+apiVersion: grizzly.grafana.com/v1alpha1
+kind: DashboardFolder
+metadata:
+  name: sample
+spec:
+  title: Sample
+`, string(content))
+	})
+
+	t.Run("missing apiVersion is added", func(t *testing.T) {
+		file := copyTestdata(t, "testdata/parsing/legacy-datasource.json")
+
+		require.NoError(t, grizzly.Convert(registry, file))
+
+		resources, err := grizzly.DefaultParser(registry, nil, nil).Parse(file, grizzly.ParserOptions{})
+		require.NoError(t, err)
+		require.Equal(t, 1, resources.Len())
+		resource := resources.AsList()[0]
+		require.Equal(t, "grizzly.grafana.com/v1alpha1", resource.APIVersion())
+		require.Equal(t, "Datasource", resource.Kind())
+	})
+
+	t.Run("up to date files are left untouched", func(t *testing.T) {
+		file := copyTestdata(t, "testdata/parsing/dashboard-with-envelope.yaml")
+		before, err := os.ReadFile(file)
+		require.NoError(t, err)
+
+		require.NoError(t, grizzly.Convert(registry, file))
+
+		after, err := os.ReadFile(file)
+		require.NoError(t, err)
+		require.Equal(t, before, after)
+	})
+}
+
+func copyTestdata(t *testing.T, source string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(source)
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), filepath.Base(source))
+	require.NoError(t, os.WriteFile(file, content, 0644))
+	return file
+}
@@ -0,0 +1,385 @@
+package grizzly
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PatchKind is the kind of resources patching a remote resource, rather than
+// describing a whole resource
+const PatchKind = "Patch"
+
+// patchSpec is the spec of a patch: the resource it targets, and the JSON
+// Patch (RFC 6902) operations applied to it
+type patchSpec struct {
+	Target struct {
+		Kind string `json:"kind"`
+		Name string `json:"name"`
+	} `json:"target"`
+	Operations []PatchOperation `json:"operations"`
+}
+
+// PatchOperation is a JSON Patch operation. Paths are JSON pointers into the
+// whole resource, such as /spec/title.
+type PatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	From  string `json:"from,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// ResolvePatches replaces the patches among resources with the remote
+// resources they target, once patched. Patched resources aren't rewritable, as
+// the files of the patches don't describe them. Several patches can target
+// the same resource, and are applied in turn. When a snapshot is given,
+// patches are applied to the resources it captured.
+func ResolvePatches(registry Registry, resources Resources, snapshot *StateSnapshot) (Resources, error) {
+	patches := resources.OfKind(PatchKind)
+	if patches.Len() == 0 {
+		return resources, nil
+	}
+
+	resolved := resources.Filter(func(resource Resource) bool {
+		return resource.Kind() != PatchKind
+	})
+	patched := map[ResourceRef]bool{}
+	for _, patch := range patches.AsList() {
+		spec, err := parsePatchSpec(patch)
+		if err != nil {
+			return resources, err
+		}
+		ref := NewResourceRef(spec.Target.Kind, spec.Target.Name)
+
+		target, found := resolved.Find(ref)
+		if found && !patched[ref] {
+			return resources, fmt.Errorf("patch %s targets %s, which is also described by %s", patch.Name(), ref, target.Source.Path)
+		}
+		if !found {
+			remote, err := getPatchTarget(registry, ref, snapshot)
+			if err != nil {
+				return resources, fmt.Errorf("patch %s: %w", patch.Name(), err)
+			}
+			target = *remote
+		}
+
+		body, err := ApplyPatch(target.Body, spec.Operations)
+		if err != nil {
+			return resources, fmt.Errorf("patch %s: %w", patch.Name(), err)
+		}
+		result := Resource{Body: body, Source: patch.Source}
+		result.Source.Rewritable = false
+		if result.Ref() != ref {
+			return resources, fmt.Errorf("patch %s: the kind and name of %s can't be patched", patch.Name(), ref)
+		}
+		resolved.Add(result)
+		patched[ref] = true
+	}
+	resolved = registry.Sort(resolved)
+	if err := registry.CheckDependencies(resolved); err != nil {
+		return resources, err
+	}
+	return resolved, nil
+}
+
+func parsePatchSpec(patch Resource) (patchSpec, error) {
+	var spec patchSpec
+	data, err := json.Marshal(patch.Spec())
+	if err != nil {
+		return spec, err
+	}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return spec, fmt.Errorf("invalid patch %s: %w", patch.Name(), err)
+	}
+	if spec.Target.Kind == "" || spec.Target.Name == "" {
+		return spec, fmt.Errorf("invalid patch %s: target.kind and target.name are required", patch.Name())
+	}
+	return spec, nil
+}
+
+// getPatchTarget retrieves the remote resource targeted by a patch, as it's
+// presented locally
+func getPatchTarget(registry Registry, ref ResourceRef, snapshot *StateSnapshot) (*Resource, error) {
+	handler, err := registry.GetHandler(ref.Kind)
+	if err != nil {
+		return nil, err
+	}
+	target, err := NewResource(handler.APIVersion(), ref.Kind, ref.Name, map[string]any{})
+	if err != nil {
+		return nil, err
+	}
+	var remote *Resource
+	if snapshot != nil {
+		remote, err = snapshot.Find(ref)
+	} else {
+		remote, err = handler.GetRemote(target)
+	}
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("%s doesn't exist remotely, and can't be patched: %w", ref, err)
+	}
+	if err != nil {
+		return nil, ClassifyError(handler, err)
+	}
+	return handler.Unprepare(*remote), nil
+}
+
+// ApplyPatch applies JSON Patch (RFC 6902) operations to a copy of a document
+func ApplyPatch(document map[string]any, operations []PatchOperation) (map[string]any, error) {
+	var result any = deepCopyValue(document)
+	for i, operation := range operations {
+		var err error
+		result, err = applyPatchOperation(result, operation)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i+1, operation.Op, operation.Path, err)
+		}
+	}
+	patched, ok := result.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("patched document isn't an object")
+	}
+	return patched, nil
+}
+
+func applyPatchOperation(document any, operation PatchOperation) (any, error) {
+	path, err := parsePointer(operation.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operation.Op {
+	case "add":
+		return updateAtPointer(document, path, func(container any, token string) (any, error) {
+			return addValue(container, token, deepCopyValue(operation.Value))
+		})
+	case "remove":
+		return updateAtPointer(document, path, removeValue)
+	case "replace":
+		return updateAtPointer(document, path, func(container any, token string) (any, error) {
+			if _, err := childValue(container, token); err != nil {
+				return nil, err
+			}
+			return setValue(container, token, deepCopyValue(operation.Value))
+		})
+	case "move", "copy":
+		from, err := parsePointer(operation.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := valueAtPointer(document, from)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		if operation.Op == "move" {
+			if document, err = updateAtPointer(document, from, removeValue); err != nil {
+				return nil, err
+			}
+		} else {
+			value = deepCopyValue(value)
+		}
+		return updateAtPointer(document, path, func(container any, token string) (any, error) {
+			return addValue(container, token, value)
+		})
+	case "test":
+		value, err := valueAtPointer(document, path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(value, operation.Value) {
+			return nil, fmt.Errorf("test failed: found %v", value)
+		}
+		return document, nil
+	default:
+		return nil, fmt.Errorf("unknown operation %q, expected add, remove, replace, move, copy or test", operation.Op)
+	}
+}
+
+// parsePointer splits a JSON pointer into its unescaped tokens. The root of
+// the document can't be patched.
+func parsePointer(pointer string) ([]string, error) {
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid path %q, expected a JSON pointer such as /spec/title", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = unescapePointerToken(token)
+	}
+	return tokens, nil
+}
+
+func valueAtPointer(document any, path []string) (any, error) {
+	value := document
+	for _, token := range path {
+		var err error
+		if value, err = childValue(value, token); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// updateAtPointer replaces the container holding the last token of a path
+// with the result of update, as updating arrays can replace them
+func updateAtPointer(document any, path []string, update func(container any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return update(document, path[0])
+	}
+	child, err := childValue(document, path[0])
+	if err != nil {
+		return nil, err
+	}
+	updated, err := updateAtPointer(child, path[1:], update)
+	if err != nil {
+		return nil, err
+	}
+	return setValue(document, path[0], updated)
+}
+
+func childValue(container any, token string) (any, error) {
+	switch c := container.(type) {
+	case map[string]any:
+		value, ok := c[token]
+		if !ok {
+			return nil, fmt.Errorf("%s not found", token)
+		}
+		return value, nil
+	case []any:
+		index, err := arrayIndex(c, token, false)
+		if err != nil {
+			return nil, err
+		}
+		return c[index], nil
+	default:
+		return nil, fmt.Errorf("%s not found, its parent isn't an object or an array", token)
+	}
+}
+
+func setValue(container any, token string, value any) (any, error) {
+	switch c := container.(type) {
+	case map[string]any:
+		c[token] = value
+		return c, nil
+	case []any:
+		index, err := arrayIndex(c, token, false)
+		if err != nil {
+			return nil, err
+		}
+		c[index] = value
+		return c, nil
+	default:
+		return nil, fmt.Errorf("%s can't be set, its parent isn't an object or an array", token)
+	}
+}
+
+func addValue(container any, token string, value any) (any, error) {
+	c, ok := container.([]any)
+	if !ok {
+		return setValue(container, token, value)
+	}
+	if token == "-" {
+		return append(c, value), nil
+	}
+	index, err := arrayIndex(c, token, true)
+	if err != nil {
+		return nil, err
+	}
+	c = append(c, nil)
+	copy(c[index+1:], c[index:])
+	c[index] = value
+	return c, nil
+}
+
+func removeValue(container any, token string) (any, error) {
+	switch c := container.(type) {
+	case map[string]any:
+		if _, ok := c[token]; !ok {
+			return nil, fmt.Errorf("%s not found", token)
+		}
+		delete(c, token)
+		return c, nil
+	case []any:
+		index, err := arrayIndex(c, token, false)
+		if err != nil {
+			return nil, err
+		}
+		return append(c[:index], c[index+1:]...), nil
+	default:
+		return nil, fmt.Errorf("%s not found, its parent isn't an object or an array", token)
+	}
+}
+
+// arrayIndex parses the index of an array item. When adding items, the
+// index can be the length of the array.
+func arrayIndex(array []any, token string, adding bool) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 {
+		return 0, fmt.Errorf("invalid array index %s", token)
+	}
+	if index > len(array) || (index == len(array) && !adding) {
+		return 0, fmt.Errorf("array index %d out of bounds, the array has %d items", index, len(array))
+	}
+	return index, nil
+}
+
+// jsonEqual compares values as JSON, so that numbers of different types are equal
+func jsonEqual(a, b any) bool {
+	return reflect.DeepEqual(NormaliseJSON(a), NormaliseJSON(b))
+}
+
+// DiffPatch returns the JSON Patch (RFC 6902) operations turning a document
+// into another. Objects are compared key by key, and arrays item by item when
+// their lengths match. Arrays whose length changed are replaced whole.
+func DiffPatch(before, after map[string]any) []PatchOperation {
+	return diffPatchValues("", NormaliseJSON(before), NormaliseJSON(after))
+}
+
+func diffPatchValues(path string, before, after any) []PatchOperation {
+	switch b := before.(type) {
+	case map[string]any:
+		a, ok := after.(map[string]any)
+		if !ok {
+			break
+		}
+		var operations []PatchOperation
+		for _, key := range sortedKeys(b) {
+			if _, ok := a[key]; !ok {
+				operations = append(operations, PatchOperation{Op: "remove", Path: path + "/" + escapePointerToken(key)})
+			}
+		}
+		for _, key := range sortedKeys(a) {
+			childPath := path + "/" + escapePointerToken(key)
+			if value, ok := b[key]; ok {
+				operations = append(operations, diffPatchValues(childPath, value, a[key])...)
+			} else {
+				operations = append(operations, PatchOperation{Op: "add", Path: childPath, Value: a[key]})
+			}
+		}
+		return operations
+	case []any:
+		a, ok := after.([]any)
+		if !ok || len(a) != len(b) {
+			break
+		}
+		var operations []PatchOperation
+		for i := range b {
+			operations = append(operations, diffPatchValues(path+"/"+strconv.Itoa(i), b[i], a[i])...)
+		}
+		return operations
+	}
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+	return []PatchOperation{{Op: "replace", Path: path, Value: after}}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
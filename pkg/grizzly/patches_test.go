@@ -0,0 +1,188 @@
+package grizzly_test
+
+import (
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grafana"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPatch(t *testing.T) {
+	document := func() map[string]any {
+		return map[string]any{
+			"spec": map[string]any{
+				"title": "API",
+				"tags":  []any{"a", "c"},
+				"a/b":   map[string]any{"c~d": 1},
+			},
+		}
+	}
+
+	cases := []struct {
+		name       string
+		operations []grizzly.PatchOperation
+		expected   map[string]any
+		err        string
+	}{
+		{
+			name:       "replace",
+			operations: []grizzly.PatchOperation{{Op: "replace", Path: "/spec/title", Value: "Renamed"}},
+			expected:   map[string]any{"title": "Renamed", "tags": []any{"a", "c"}, "a/b": map[string]any{"c~d": 1}},
+		},
+		{
+			name: "add to arrays",
+			operations: []grizzly.PatchOperation{
+				{Op: "add", Path: "/spec/tags/1", Value: "b"},
+				{Op: "add", Path: "/spec/tags/-", Value: "d"},
+			},
+			expected: map[string]any{"title": "API", "tags": []any{"a", "b", "c", "d"}, "a/b": map[string]any{"c~d": 1}},
+		},
+		{
+			name: "remove escaped keys",
+			operations: []grizzly.PatchOperation{
+				{Op: "remove", Path: "/spec/a~1b/c~0d"},
+				{Op: "remove", Path: "/spec/tags/0"},
+			},
+			expected: map[string]any{"title": "API", "tags": []any{"c"}, "a/b": map[string]any{}},
+		},
+		{
+			name: "move and copy",
+			operations: []grizzly.PatchOperation{
+				{Op: "copy", From: "/spec/title", Path: "/spec/description"},
+				{Op: "move", From: "/spec/tags", Path: "/spec/labels"},
+			},
+			expected: map[string]any{"title": "API", "description": "API", "labels": []any{"a", "c"}, "a/b": map[string]any{"c~d": 1}},
+		},
+		{
+			name: "test",
+			operations: []grizzly.PatchOperation{
+				{Op: "test", Path: "/spec/a~1b/c~0d", Value: 1.0},
+				{Op: "replace", Path: "/spec/title", Value: "Renamed"},
+			},
+			expected: map[string]any{"title": "Renamed", "tags": []any{"a", "c"}, "a/b": map[string]any{"c~d": 1}},
+		},
+		{
+			name:       "failed test",
+			operations: []grizzly.PatchOperation{{Op: "test", Path: "/spec/title", Value: "Other"}},
+			err:        "operation 1 (test /spec/title): test failed: found API",
+		},
+		{
+			name:       "replacing a missing value",
+			operations: []grizzly.PatchOperation{{Op: "replace", Path: "/spec/missing", Value: 1}},
+			err:        "missing not found",
+		},
+		{
+			name:       "out of bounds",
+			operations: []grizzly.PatchOperation{{Op: "add", Path: "/spec/tags/3", Value: "x"}},
+			err:        "array index 3 out of bounds",
+		},
+		{
+			name:       "unknown operation",
+			operations: []grizzly.PatchOperation{{Op: "merge", Path: "/spec"}},
+			err:        "unknown operation",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			original := document()
+			patched, err := grizzly.ApplyPatch(original, tc.operations)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, patched["spec"])
+			require.Equal(t, document(), original, "the document isn't modified")
+		})
+	}
+}
+
+func TestResolvePatches(t *testing.T) {
+	registry := grizzly.NewRegistry([]grizzly.Provider{grafana.NewProvider(&config.GrafanaConfig{})})
+
+	remote, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", grafana.DashboardKind, "api", map[string]any{
+		"uid":    "api",
+		"title":  "API",
+		"panels": []any{map[string]any{"title": "Latency", "thresholds": 80}},
+	})
+	require.NoError(t, err)
+	remote.SetMetadata("folder", "general")
+	snapshot := &grizzly.StateSnapshot{Resources: []map[string]any{remote.Body}}
+
+	patch := func(name string, operations ...any) grizzly.Resource {
+		resource, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", grizzly.PatchKind, name, map[string]any{
+			"target":     map[string]any{"kind": grafana.DashboardKind, "name": "api"},
+			"operations": operations,
+		})
+		require.NoError(t, err)
+		resource.Source = grizzly.Source{Path: name + ".yaml", Rewritable: true}
+		return resource
+	}
+	folder, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", grafana.DashboardFolderKind, "team-a", map[string]any{"title": "Team A"})
+	require.NoError(t, err)
+
+	resources := registry.Sort(grizzly.NewResources(
+		patch("latency-threshold", map[string]any{"op": "replace", "path": "/spec/panels/0/thresholds", "value": 90}),
+		folder,
+		patch("tags", map[string]any{"op": "add", "path": "/spec/tags", "value": []any{"patched"}}),
+	))
+	require.Equal(t, 3, resources.Len(), "patches are kept when sorting")
+
+	resolved, err := grizzly.ResolvePatches(registry, resources, snapshot)
+	require.NoError(t, err)
+	require.Equal(t, 2, resolved.Len())
+
+	dashboard, found := resolved.Find(remote.Ref())
+	require.True(t, found)
+	require.Equal(t, []any{map[string]any{"title": "Latency", "thresholds": float64(90)}}, dashboard.GetSpecValue("panels"))
+	require.Equal(t, []any{"patched"}, dashboard.GetSpecValue("tags"))
+	require.Equal(t, "general", dashboard.GetMetadata("folder"))
+	require.False(t, dashboard.Source.Rewritable)
+
+	t.Run("patching a resource described locally", func(t *testing.T) {
+		resources := grizzly.NewResources(remote, patch("tags", map[string]any{"op": "add", "path": "/spec/tags", "value": []any{"patched"}}))
+		_, err := grizzly.ResolvePatches(registry, resources, snapshot)
+		require.ErrorContains(t, err, "which is also described by")
+	})
+
+	t.Run("patching a missing resource", func(t *testing.T) {
+		_, err := grizzly.ResolvePatches(registry, grizzly.NewResources(patch("tags")), &grizzly.StateSnapshot{})
+		require.ErrorIs(t, err, grizzly.ErrNotFound)
+	})
+}
+
+func TestDiffPatch(t *testing.T) {
+	before := map[string]any{
+		"spec": map[string]any{
+			"title":  "API",
+			"tags":   []any{"a", "b"},
+			"panels": []any{map[string]any{"title": "Latency"}},
+			"a/b":    1,
+		},
+	}
+	after := map[string]any{
+		"spec": map[string]any{
+			"title":  "API",
+			"tags":   []any{"a", "b", "c"},
+			"panels": []any{map[string]any{"title": "Errors"}},
+			"c~d":    2,
+		},
+	}
+
+	operations := grizzly.DiffPatch(before, after)
+	require.Equal(t, []grizzly.PatchOperation{
+		{Op: "remove", Path: "/spec/a~1b"},
+		{Op: "add", Path: "/spec/c~0d", Value: float64(2)},
+		{Op: "replace", Path: "/spec/panels/0/title", Value: "Errors"},
+		{Op: "replace", Path: "/spec/tags", Value: []any{"a", "b", "c"}},
+	}, operations)
+
+	patched, err := grizzly.ApplyPatch(before, operations)
+	require.NoError(t, err)
+	require.Equal(t, "Errors", patched["spec"].(map[string]any)["panels"].([]any)[0].(map[string]any)["title"])
+	require.Empty(t, grizzly.DiffPatch(patched, after))
+	require.Empty(t, grizzly.DiffPatch(before, before))
+}
@@ -0,0 +1,232 @@
+package grizzly
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	log "github.com/sirupsen/logrus"
+)
+
+// PlanFormatVersion is the version of the format of plans written by
+// WritePlan, which also tells plans apart from resource files
+const PlanFormatVersion = 1
+
+// ErrPlanOutdated is returned when remote resources changed since a plan was made
+var ErrPlanOutdated = errors.New("remote resources changed since the plan was made")
+
+const (
+	PlanActionAdd    = "add"
+	PlanActionUpdate = "update"
+)
+
+// Plan is the set of changes applying resources to a context makes, with the
+// resources themselves, so that exactly these changes can be applied later on,
+// once approved
+type Plan struct {
+	FormatVersion int          `json:"formatVersion"`
+	Context       string       `json:"context"`
+	CreatedAt     time.Time    `json:"createdAt"`
+	Changes       []PlanChange `json:"changes"`
+}
+
+// PlanChange is the addition or update of a resource
+type PlanChange struct {
+	// Action is PlanActionAdd or PlanActionUpdate
+	Action   string         `json:"action"`
+	Resource map[string]any `json:"resource"`
+	// RemoteDigest is the SHA-256 digest of the remote resource updated by the
+	// change, as it was when the plan was made
+	RemoteDigest string `json:"remoteDigest,omitempty"`
+}
+
+// PlannedChange returns the change as it's approved
+func (c PlanChange) PlannedChange() PlannedChange {
+	change := PlannedChange{Type: ResourceUpdated, Resource: Resource{Body: c.Resource}}
+	if c.Action == PlanActionAdd {
+		change.Type = ResourceAdded
+	}
+	return change
+}
+
+// MakePlan compares resources to their remote versions, and returns the
+// changes applying them makes. Unchanged resources aren't part of plans.
+func MakePlan(registry Registry, resources Resources, contextName string) (Plan, error) {
+	plan := Plan{
+		FormatVersion: PlanFormatVersion,
+		Context:       contextName,
+		CreatedAt:     time.Now().UTC(),
+		Changes:       []PlanChange{},
+	}
+
+	var finalErr error
+	for _, resource := range resources.AsList() {
+		handler, err := registry.GetHandler(resource.Kind())
+		if err != nil {
+			return plan, err
+		}
+		change, err := planResource(registry, handler, resource, ApplyOptions{})
+		if err != nil {
+			finalErr = multierror.Append(finalErr, ClassifyError(handler, fmt.Errorf("planning changes to %s: %w", resource.Ref(), err)))
+			continue
+		}
+
+		planChange := PlanChange{Resource: resource.Body}
+		switch change.Type {
+		case ResourceAdded:
+			planChange.Action = PlanActionAdd
+		case ResourceUpdated:
+			planChange.Action = PlanActionUpdate
+			if planChange.RemoteDigest, err = remoteDigest(change.Remote); err != nil {
+				return plan, err
+			}
+		default:
+			continue
+		}
+		plan.Changes = append(plan.Changes, planChange)
+	}
+	return plan, finalErr
+}
+
+// WritePlan writes a plan to a JSON file. Keys are sorted, so that the same
+// plan is always written the same way.
+func WritePlan(file string, plan Plan) error {
+	content, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFile(file, append(content, '\n'))
+}
+
+// ReadPlan reads a plan written by WritePlan
+func ReadPlan(file string) (*Plan, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var plan Plan
+	if err := json.Unmarshal(content, &plan); err != nil {
+		return nil, fmt.Errorf("reading plan %s: %w", file, err)
+	}
+	if plan.FormatVersion != PlanFormatVersion {
+		return nil, fmt.Errorf("reading plan %s: unsupported format version %d", file, plan.FormatVersion)
+	}
+	return &plan, nil
+}
+
+// IsPlanFile returns whether a file is a plan written by WritePlan, rather
+// than resources
+func IsPlanFile(file string) bool {
+	info, err := os.Stat(file)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return false
+	}
+	var header struct {
+		FormatVersion int          `json:"formatVersion"`
+		Changes       []PlanChange `json:"changes"`
+		Kind          string       `json:"kind"`
+	}
+	if json.Unmarshal(content, &header) != nil {
+		return false
+	}
+	return header.FormatVersion > 0 && header.Changes != nil && header.Kind == ""
+}
+
+// ApplyPlan makes the changes of a plan. Nothing is applied when any of the
+// resources it changes was added, updated or deleted remotely since the plan
+// was made.
+func ApplyPlan(registry Registry, plan Plan, opts ApplyOptions, recorder EventsRecorder) error {
+	changes := make([]resourceChange, 0, len(plan.Changes))
+	var drifted error
+	for _, planChange := range plan.Changes {
+		resource := Resource{Body: planChange.Resource}
+		if resource.Kind() == "" || resource.Name() == "" {
+			return fmt.Errorf("invalid resource in plan of %s: missing kind or name", plan.Context)
+		}
+		handler, err := registry.GetHandler(resource.Kind())
+		if err != nil {
+			return err
+		}
+
+		change, err := planResource(registry, handler, resource, opts)
+		if err != nil {
+			return ClassifyError(handler, fmt.Errorf("checking %s: %w", resource.Ref(), err))
+		}
+		digest, err := remoteDigest(change.Remote)
+		if err != nil {
+			return err
+		}
+		switch {
+		case planChange.Action == PlanActionAdd && change.Remote != nil:
+			drifted = multierror.Append(drifted, fmt.Errorf("%s was added", resource.Ref()))
+		case planChange.Action == PlanActionUpdate && change.Remote == nil:
+			drifted = multierror.Append(drifted, fmt.Errorf("%s was deleted", resource.Ref()))
+		case digest != planChange.RemoteDigest:
+			drifted = multierror.Append(drifted, fmt.Errorf("%s was updated", resource.Ref()))
+		}
+		changes = append(changes, change)
+	}
+	if drifted != nil {
+		return fmt.Errorf("%w: %w", ErrPlanOutdated, drifted)
+	}
+
+	var finalErr error
+	for _, change := range changes {
+		handler, err := registry.GetHandler(change.Resource.Kind())
+		if err != nil {
+			return err
+		}
+		if err := applyPlannedChange(handler, change, opts); err != nil {
+			err = ClassifyError(handler, err)
+			recorder.Record(NewFailureEvent(change.Resource.Ref().String(), err.Error(), err))
+			finalErr = multierror.Append(finalErr, err)
+			if !opts.ContinueOnError {
+				return finalErr
+			}
+			continue
+		}
+		recorder.Record(Event{
+			Type:        change.Type,
+			ResourceRef: change.Resource.Ref().String(),
+		})
+	}
+	return finalErr
+}
+
+func applyPlannedChange(handler Handler, change resourceChange, opts ApplyOptions) error {
+	switch change.Type {
+	case ResourceAdded:
+		log.Debugf("Adding `%s`, as planned", change.Resource.Ref())
+		if err := checkPayloadSize(change.Resource, opts); err != nil {
+			return err
+		}
+		return handler.Add(change.Resource)
+	case ResourceUpdated:
+		log.Debugf("Updating `%s`, as planned", change.Resource.Ref())
+		return updateResource(handler, change, opts)
+	}
+	return nil
+}
+
+// remoteDigest returns the SHA-256 digest of an unprepared remote resource,
+// or nothing when there's no remote resource
+func remoteDigest(remote *Resource) (string, error) {
+	if remote == nil {
+		return "", nil
+	}
+	content, err := remote.YAML()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:]), nil
+}
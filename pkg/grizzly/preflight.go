@@ -0,0 +1,107 @@
+package grizzly
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	log "github.com/sirupsen/logrus"
+)
+
+// requestsPerResource is the number of requests applying a resource usually
+// takes, to retrieve its remote version and then write it
+const requestsPerResource = 2
+
+// CredentialsExpiryProvider describes a provider whose credentials may expire
+type CredentialsExpiryProvider interface {
+	// CredentialsExpiry returns when the configured credentials expire, and
+	// false if their expiry can't be determined (ex: opaque API tokens)
+	CredentialsExpiry() (time.Time, bool)
+}
+
+// TokenExpiry returns the expiry of a JSON Web Token, and false if the token
+// isn't a JWT or has no expiry
+func TokenExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Expiry int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Expiry == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Expiry, 0), true
+}
+
+// Preflight checks, with a cheap authenticated request, that the providers
+// of the given resources are reachable before they are applied. The duration
+// of the apply is estimated from the latency of these requests, so that
+// credentials expiring before its end are refused up front, rather than
+// failing part way through. The same goes for the quotas of the resources
+// to add.
+func Preflight(registry Registry, resources Resources) error {
+	counts := map[string]int{}
+	for _, resource := range resources.AsList() {
+		counts[resource.Kind()]++
+	}
+
+	var finalErr error
+	for _, provider := range registry.Providers {
+		count := 0
+		for _, handler := range provider.GetHandlers() {
+			count += counts[handler.Kind()]
+		}
+		if count == 0 {
+			continue
+		}
+		if err := preflightProvider(provider, count); err != nil {
+			finalErr = multierror.Append(finalErr, err)
+		}
+	}
+	if finalErr != nil {
+		return finalErr
+	}
+	return CheckQuotas(registry, resources)
+}
+
+func preflightProvider(provider Provider, count int) error {
+	start := time.Now()
+	status := provider.Status()
+	latency := time.Since(start)
+
+	if !status.Active {
+		return fmt.Errorf("%s isn't configured: %s", provider.Name(), status.ActiveReason)
+	}
+	if !status.Online {
+		return fmt.Errorf("pre-flight check of %s failed: %s", provider.Name(), status.OnlineReason)
+	}
+
+	expiring, ok := provider.(CredentialsExpiryProvider)
+	if !ok {
+		return nil
+	}
+	expiry, known := expiring.CredentialsExpiry()
+	if !known {
+		return nil
+	}
+
+	estimate := time.Duration(count*requestsPerResource) * latency
+	remaining := time.Until(expiry)
+	log.Debugf("Credentials of %s expire in %s, applying %s is estimated to take %s", provider.Name(), remaining.Round(time.Second), Pluraliser(count, "resource"), estimate.Round(time.Second))
+	if remaining <= 0 {
+		return fmt.Errorf("credentials of %s expired at %s, renew them before applying", provider.Name(), expiry.Format(time.RFC3339))
+	}
+	if remaining < estimate {
+		return fmt.Errorf("credentials of %s expire at %s, in %s, before the end of the apply estimated in %s. Renew them before applying", provider.Name(), expiry.Format(time.RFC3339), remaining.Round(time.Second), estimate.Round(time.Second))
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package grizzly
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// PreviewHandler describes a handler that can publish short-lived, labelled
+// previews of resources, and remove them once they are no longer needed
+type PreviewHandler interface {
+	// Preview publishes a resource as a preview, expiring after the given duration
+	Preview(resource Resource, label string, expires time.Duration) (*PublishedPreview, error)
+
+	// DeletePreviews removes all the previews published with the given label
+	DeletePreviews(label string) ([]PublishedPreview, error)
+}
+
+// PublishedPreview describes a preview of a resource
+type PublishedPreview struct {
+	Resource  string `yaml:"resource" json:"resource"`
+	Label     string `yaml:"label" json:"label"`
+	URL       string `yaml:"url" json:"url"`
+	DeleteURL string `yaml:"deleteUrl,omitempty" json:"deleteUrl,omitempty"`
+}
+
+// PreviewOptions controls which resources are previewed, and how
+type PreviewOptions struct {
+	// Label identifies the previews, so that they can be cleaned up together. Ex: pr-123
+	Label string
+	// Expires is the duration after which previews are deleted automatically. Zero means never
+	Expires time.Duration
+	// All previews every resource, rather than only those differing from their remote version
+	All bool
+}
+
+// Preview publishes resources that changed compared to their remote version as previews.
+func Preview(registry Registry, resources Resources, opts PreviewOptions) ([]PublishedPreview, error) {
+	if opts.Label == "" {
+		return nil, fmt.Errorf("a label is required to publish previews")
+	}
+
+	previews := []PublishedPreview{}
+	for _, resource := range resources.AsList() {
+		handler, err := registry.GetHandler(resource.Kind())
+		if err != nil {
+			return previews, err
+		}
+		previewHandler, ok := handler.(PreviewHandler)
+		if !ok {
+			notifier.NotSupported(resource, "preview")
+			continue
+		}
+
+		if !opts.All {
+			changed, err := hasChanged(handler, resource)
+			if err != nil {
+				return previews, err
+			}
+			if !changed {
+				notifier.NoChanges(resource)
+				continue
+			}
+		}
+
+		preview, err := previewHandler.Preview(resource, opts.Label, opts.Expires)
+		if err != nil {
+			return previews, err
+		}
+		notifier.Info(resource, "preview: "+preview.URL)
+		previews = append(previews, *preview)
+	}
+
+	return previews, nil
+}
+
+// CleanupPreviews removes the previews published with the given label by every handler supporting them.
+func CleanupPreviews(registry Registry, label string) ([]PublishedPreview, error) {
+	if label == "" {
+		return nil, fmt.Errorf("a label is required to clean up previews")
+	}
+
+	deleted := []PublishedPreview{}
+	for _, provider := range registry.Providers {
+		if err := provider.Validate(); err != nil {
+			log.Debugf("Skipping %s: %s", provider.Name(), err)
+			continue
+		}
+
+		for _, handler := range provider.GetHandlers() {
+			previewHandler, ok := handler.(PreviewHandler)
+			if !ok {
+				continue
+			}
+
+			log.Debugf("Deleting %s previews labelled %s", handler.Kind(), label)
+			previews, err := previewHandler.DeletePreviews(label)
+			if err != nil {
+				return deleted, err
+			}
+			for _, preview := range previews {
+				notifier.Info(notifier.SimpleString(preview.Resource), "preview deleted")
+			}
+			deleted = append(deleted, previews...)
+		}
+	}
+
+	if len(deleted) == 0 {
+		notifier.Info(nil, fmt.Sprintf("No previews labelled %s found", label))
+	}
+
+	return deleted, nil
+}
+
+// hasChanged indicates whether a resource differs from its remote version, or doesn't exist remotely.
+func hasChanged(handler Handler, resource Resource) (bool, error) {
+	local, err := handler.Unprepare(resource).YAML()
+	if err != nil {
+		return false, err
+	}
+
+	remote, err := handler.GetRemote(resource)
+	if errors.Is(err, ErrNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	remoteRepresentation, err := handler.Unprepare(*remote).YAML()
+	if err != nil {
+		return false, err
+	}
+
+	return local != remoteRepresentation, nil
+}
+
+// FormatPreviews renders previews in a machine readable block, or as a table by default.
+func FormatPreviews(previews []PublishedPreview, format string) ([]byte, error) {
+	switch format {
+	case formatYAML:
+		return yaml.Marshal(previews)
+	case formatJSON:
+		return json.MarshalIndent(previews, "", "  ")
+	case formatDefault:
+		var out bytes.Buffer
+		w := tabwriter.NewWriter(&out, 0, 0, 4, ' ', 0)
+
+		f := "%s\t%s\t%s\n"
+		fmt.Fprintf(w, f, "RESOURCE", "LABEL", "URL")
+		for _, preview := range previews {
+			fmt.Fprintf(w, f, preview.Resource, preview.Label, preview.URL)
+		}
+		err := w.Flush()
+		return out.Bytes(), err
+	default:
+		return nil, fmt.Errorf("unknown format %s", format)
+	}
+}
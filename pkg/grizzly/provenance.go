@@ -0,0 +1,150 @@
+package grizzly
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Annotations stamped on applied resources, to record the git commit they were applied from
+const (
+	GitCommitAnnotation = "git-sha"
+	GitBranchAnnotation = "git-branch"
+	GitRepoAnnotation   = "git-repo"
+)
+
+// provenanceAnnotations lists the annotations describing the provenance of resources, in display order
+var provenanceAnnotations = []string{GitCommitAnnotation, GitBranchAnnotation, GitRepoAnnotation}
+
+// dirtySuffix marks commits applied with uncommitted changes, which the commit alone doesn't describe
+const dirtySuffix = "-dirty"
+
+// GitProvenance describes the commit, branch and repository of the git working
+// tree containing path, as annotations. Annotations that can't be determined,
+// such as the branch of a detached HEAD, are left out.
+func GitProvenance(path string) (map[string]string, error) {
+	dir := path
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		dir = filepath.Dir(path)
+	}
+
+	commit, err := git(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("finding the git commit of %s: %w", path, err)
+	}
+	if status, err := git(dir, "status", "--porcelain"); err == nil && status != "" {
+		commit += dirtySuffix
+	}
+
+	annotations := map[string]string{GitCommitAnnotation: commit}
+	if branch, err := git(dir, "rev-parse", "--abbrev-ref", "HEAD"); err == nil && branch != "HEAD" {
+		annotations[GitBranchAnnotation] = branch
+	}
+	if remote, err := git(dir, "config", "--get", "remote.origin.url"); err == nil && remote != "" {
+		annotations[GitRepoAnnotation] = redactRepoURL(remote)
+	}
+	return annotations, nil
+}
+
+// GitCommitSummary describes a commit of the current git repository in one
+// line, or returns an empty string if the commit isn't known locally
+func GitCommitSummary(commit string) string {
+	summary, err := git(".", "show", "--no-patch", "--format=%h %an, %ar: %s", strings.TrimSuffix(commit, dirtySuffix))
+	if err != nil {
+		return ""
+	}
+	return summary
+}
+
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	out, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("%s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// redactRepoURL removes the credentials that may be embedded in the URL of a repository
+func redactRepoURL(remote string) string {
+	parsed, err := url.Parse(remote)
+	if err != nil || parsed.User == nil {
+		return remote
+	}
+	parsed.User = nil
+	return parsed.String()
+}
+
+// StampAnnotations adds annotations to resources, without overriding the ones they already have
+func StampAnnotations(resources Resources, annotations map[string]string) {
+	for _, resource := range resources.AsList() {
+		values, _ := resource.metadata()["annotations"].(map[string]any)
+		if values == nil {
+			values = map[string]any{}
+		}
+		for key, value := range annotations {
+			if _, found := values[key]; !found {
+				values[key] = value
+			}
+		}
+		resource.metadata()["annotations"] = values
+	}
+}
+
+// Whence looks up the git provenance of a remote resource, given as
+// <kind>.<uid>, from the annotations exposed when it was last applied
+func Whence(registry Registry, uid string) (map[string]string, error) {
+	resource, err := GetRemoteResource(registry, uid)
+	if err != nil {
+		return nil, err
+	}
+	handler, err := registry.GetHandler(resource.Kind())
+	if err != nil {
+		return nil, err
+	}
+	annotationHandler, ok := handler.(AnnotationHandler)
+	if !ok {
+		return nil, fmt.Errorf("%s can't expose annotations remotely, its provenance isn't recorded", resource.Kind())
+	}
+	target, err := annotationTarget(registry, annotationHandler, resource.Kind())
+	if err != nil {
+		return nil, err
+	}
+	if target == AnnotationsDisabled {
+		return nil, fmt.Errorf("annotations of %s aren't exposed remotely, its provenance isn't recorded", resource.Kind())
+	}
+
+	provenance := map[string]string{}
+	for key, value := range annotationHandler.ReadAnnotations(*resource, target) {
+		if slices.Contains(provenanceAnnotations, key) {
+			provenance[key] = value
+		}
+	}
+	if len(provenance) == 0 {
+		return nil, fmt.Errorf("no git provenance found for %s, was it applied with --git-provenance?", resource.Ref())
+	}
+	return provenance, nil
+}
+
+// FormatProvenance renders the git provenance of a resource, one annotation per line
+func FormatProvenance(provenance map[string]string) string {
+	var out strings.Builder
+	for _, key := range provenanceAnnotations {
+		if value, ok := provenance[key]; ok {
+			fmt.Fprintf(&out, "%-12s%s\n", key, value)
+		}
+	}
+	if commit, ok := provenance[GitCommitAnnotation]; ok {
+		if summary := GitCommitSummary(commit); summary != "" {
+			fmt.Fprintf(&out, "%-12s%s\n", "commit", summary)
+		}
+	}
+	return out.String()
+}
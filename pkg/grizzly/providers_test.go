@@ -0,0 +1,44 @@
+package grizzly
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvidersTable(t *testing.T) {
+	infos := []ProviderInfo{
+		{
+			Name:       "Grafana",
+			APIVersion: "grizzly.grafana.com/v1alpha1",
+			Active:     true,
+			Handlers: []HandlerInfo{
+				{Kind: "Dashboard", Operations: []Operation{OperationList, OperationGet, OperationApply, OperationDelete}},
+			},
+		},
+		{
+			Name:         "Mimir",
+			APIVersion:   "grizzly.grafana.com/v1alpha1",
+			ActiveReason: "mimir address is not set",
+			Handlers: []HandlerInfo{
+				{Kind: "PrometheusRuleGroup", Operations: []Operation{OperationList, OperationGet, OperationApply}},
+			},
+		},
+	}
+
+	table, err := providersTable(infos, false)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(table)), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, []string{"PROVIDER", "API", "VERSION", "KIND", "ACTIVE", "OPERATIONS"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"Grafana", "grizzly.grafana.com/v1alpha1", "Dashboard", "yes", "list,get,apply,delete"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{"Mimir", "grizzly.grafana.com/v1alpha1", "PrometheusRuleGroup", "no", "list,get,apply"}, strings.Fields(lines[2]))
+	require.NotContains(t, string(table), "REASON")
+
+	table, err = providersTable(infos, true)
+	require.NoError(t, err)
+	lines = strings.Split(strings.TrimSpace(string(table)), "\n")
+	require.Equal(t, "REASON", strings.Fields(lines[0])[6], "wide tables explain why providers aren't active")
+	require.True(t, strings.HasSuffix(lines[2], "mimir address is not set"))
+}
@@ -0,0 +1,106 @@
+package grizzly
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"gopkg.in/yaml.v3"
+)
+
+// PruneRule is a field removed from pulled resources, such as one set by
+// Grafana or by the users of a dashboard rather than by its authors
+type PruneRule struct {
+	// Path locates the field, from the spec of resources. A [] suffix selects
+	// every item of a list. Ex: spec.panels[].pluginVersion
+	Path []string
+	// Value, when set, restricts the removal to the fields holding it,
+	// such as empty lists. Ex: []
+	Value any
+	// HasValue tells whether the rule has a value, as nil, or null, is one
+	HasValue bool
+}
+
+// ParsePruneRule parses a prune rule, written <path>, or <path>=<value> to
+// only remove the field when it holds the value. Ex: spec.links=[]
+func ParsePruneRule(rule string) (PruneRule, error) {
+	path, value, hasValue := strings.Cut(rule, "=")
+	parsed := PruneRule{Path: strings.Split(strings.TrimSpace(path), "."), HasValue: hasValue}
+	if len(parsed.Path) < 2 || parsed.Path[0] != "spec" || slices.ContainsFunc(parsed.Path, isEmptySegment) {
+		return PruneRule{}, fmt.Errorf("invalid prune rule %q, the path should start with spec.", rule)
+	}
+	if hasValue {
+		if err := yaml.Unmarshal([]byte(value), &parsed.Value); err != nil {
+			return PruneRule{}, fmt.Errorf("invalid prune rule %q: %w", rule, err)
+		}
+		parsed.Value = NormaliseJSON(parsed.Value)
+	}
+	return parsed, nil
+}
+
+// ParsePruneRules parses the prune rules of each kind. Kinds are matched
+// case-insensitively, as configuration keys are lowercased.
+func ParsePruneRules(rules map[string][]string) (map[string][]PruneRule, error) {
+	var finalErr error
+	parsed := map[string][]PruneRule{}
+	for kind, kindRules := range rules {
+		for _, rule := range kindRules {
+			pruneRule, err := ParsePruneRule(rule)
+			if err != nil {
+				finalErr = multierror.Append(finalErr, fmt.Errorf("pull-prune of %s: %w", kind, err))
+				continue
+			}
+			parsed[strings.ToLower(kind)] = append(parsed[strings.ToLower(kind)], pruneRule)
+		}
+	}
+	if finalErr != nil {
+		return nil, finalErr
+	}
+	return parsed, nil
+}
+
+// pruneFields returns a copy of a resource without the fields matching the
+// prune rules of the registry for its kind
+func pruneFields(registry Registry, resource Resource) Resource {
+	rules := registry.PruneRules[strings.ToLower(resource.Kind())]
+	if len(rules) == 0 {
+		return resource
+	}
+	pruned := resource.DeepCopy()
+	for _, rule := range rules {
+		pruneField(pruned.Body, rule.Path, rule)
+	}
+	return pruned
+}
+
+func pruneField(object map[string]any, path []string, rule PruneRule) {
+	key, isList := strings.CutSuffix(path[0], "[]")
+
+	if isList {
+		items, _ := object[key].([]any)
+		for _, item := range items {
+			if itemObject, ok := item.(map[string]any); ok && len(path) > 1 {
+				pruneField(itemObject, path[1:], rule)
+			}
+		}
+		return
+	}
+
+	if len(path) == 1 {
+		value, set := object[key]
+		if set && (!rule.HasValue || reflect.DeepEqual(NormaliseJSON(value), rule.Value)) {
+			delete(object, key)
+		}
+		return
+	}
+
+	if child, ok := object[key].(map[string]any); ok {
+		pruneField(child, path[1:], rule)
+	}
+}
+
+func isEmptySegment(segment string) bool {
+	return segment == "" || segment == "[]"
+}
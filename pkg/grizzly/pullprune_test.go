@@ -0,0 +1,75 @@
+package grizzly
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePruneRule(t *testing.T) {
+	parsed, err := ParsePruneRule("spec.iteration")
+	require.NoError(t, err)
+	require.Equal(t, PruneRule{Path: []string{"spec", "iteration"}}, parsed)
+
+	parsed, err = ParsePruneRule("spec.links=[]")
+	require.NoError(t, err)
+	require.Equal(t, PruneRule{Path: []string{"spec", "links"}, Value: []any{}, HasValue: true}, parsed)
+
+	for _, invalid := range []string{"iteration", "metadata.folder", "spec..id", "spec.[].id", "spec.links=[", "=[]"} {
+		_, err := ParsePruneRule(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestPruneFields(t *testing.T) {
+	dashboard, err := NewResource("grizzly.grafana.com/v1alpha1", "Dashboard", "api", map[string]any{
+		"title":     "API",
+		"iteration": 1712345678901.0,
+		"links":     []any{},
+		"panels": []any{
+			map[string]any{"title": "Errors", "fieldConfig": map[string]any{"defaults": map[string]any{}, "overrides": []any{}}},
+			map[string]any{"title": "Latency", "fieldConfig": map[string]any{"defaults": map[string]any{"unit": "s"}, "overrides": []any{}}},
+		},
+		"templating": map[string]any{"list": []any{
+			map[string]any{"name": "cluster", "current": map[string]any{"text": "prod", "value": "prod"}},
+		}},
+	})
+	require.NoError(t, err)
+
+	rules, err := ParsePruneRules(map[string][]string{
+		"Dashboard": {
+			"spec.iteration",
+			"spec.links=[]",
+			"spec.panels[].fieldConfig.defaults={}",
+			"spec.panels[].fieldConfig.overrides=[]",
+			"spec.templating.list[].current",
+		},
+	})
+	require.NoError(t, err)
+	registry := Registry{PruneRules: rules}
+
+	pruned := pruneFields(registry, dashboard)
+	require.Equal(t, map[string]any{
+		"title": "API",
+		"panels": []any{
+			map[string]any{"title": "Errors", "fieldConfig": map[string]any{}},
+			map[string]any{"title": "Latency", "fieldConfig": map[string]any{"defaults": map[string]any{"unit": "s"}}},
+		},
+		"templating": map[string]any{"list": []any{
+			map[string]any{"name": "cluster"},
+		}},
+	}, pruned.Spec())
+	require.Contains(t, dashboard.Spec(), "iteration", "the resource pruned isn't changed")
+
+	t.Run("other kinds are kept whole", func(t *testing.T) {
+		folder, err := NewResource("grizzly.grafana.com/v1alpha1", "DashboardFolder", "shared", map[string]any{"iteration": 1})
+		require.NoError(t, err)
+		pruned := pruneFields(registry, folder)
+		require.Equal(t, folder.Spec(), pruned.Spec())
+	})
+
+	t.Run("invalid rules are reported", func(t *testing.T) {
+		_, err := ParsePruneRules(map[string][]string{"dashboard": {"iteration"}})
+		require.ErrorContains(t, err, "pull-prune of dashboard")
+	})
+}
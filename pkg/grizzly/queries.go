@@ -0,0 +1,171 @@
+package grizzly
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
+)
+
+// Languages of queries
+const (
+	QueryLanguagePromQL  = "promql"
+	QueryLanguageLogQL   = "logql"
+	QueryLanguageTraceQL = "traceql"
+	QueryLanguageSQL     = "sql"
+)
+
+// Query locates a query in the spec of a resource
+type Query struct {
+	// Pointer is the JSON pointer of the query in the spec. Ex: /panels/0/targets/1/expr
+	Pointer string
+	// Language of the query. Ex: promql
+	Language string
+	// Name identifies the query within its resource. Ex: panel-2-A
+	Name string
+}
+
+var unsafeFileCharacters = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// File returns the path, relative to the file of the resource, a query is
+// extracted to. Ex: queries/my-dashboard/panel-2-A.promql
+func (q Query) File(resource Resource) string {
+	name := unsafeFileCharacters.ReplaceAllString(q.Name, "-")
+	return filepath.Join("queries", unsafeFileCharacters.ReplaceAllString(resource.Name(), "-"), fmt.Sprintf("%s.%s", name, q.Language))
+}
+
+// QueryHandler describes a handler whose resources hold queries, such as the
+// queries of dashboard panels or the expressions of rules
+type QueryHandler interface {
+	// Queries lists the queries found in the spec of a resource
+	Queries(resource Resource) []Query
+}
+
+// Validate checks resources without contacting any endpoint: each is
+// validated by its handler and against the schema of its kind, rule groups are checked against the
+// rule-intervals policy, then queries are checked by the external linters
+// configured for their language. Linters are commands, given the path of a
+// file holding the query, that fail when the query is invalid.
+// Ex: sql: sqlfluff lint --dialect postgres
+// When a datasource is given, the metrics referenced by the expressions of
+// rule groups are also checked to exist in it, as rules on metrics that don't
+// exist can never fire.
+func Validate(registry Registry, resources Resources, linters map[string]string, datasource string) error {
+	invalid := 0
+	policy, err := registry.ruleIntervalPolicy()
+	if err != nil {
+		return err
+	}
+	var presence *metricPresence
+	if datasource != "" {
+		if presence, err = newMetricPresence(registry, resources, datasource); err != nil {
+			return err
+		}
+	}
+
+	for _, resource := range resources.AsList() {
+		handler, err := registry.GetHandler(resource.Kind())
+		if err != nil {
+			return err
+		}
+
+		problems := validateResource(handler, resource)
+		if problem := ruleIntervalProblem(policy, handler, resource); problem != "" {
+			problems = append(problems, problem)
+		}
+		if queryHandler, ok := handler.(QueryHandler); ok {
+			for _, query := range queryHandler.Queries(resource) {
+				if problem := lintQuery(resource, query, linters[query.Language]); problem != "" {
+					problems = append(problems, problem)
+				}
+			}
+		}
+		problems = append(problems, presence.problems(handler, resource)...)
+
+		if len(problems) == 0 {
+			notifier.Info(resource, "valid")
+			continue
+		}
+		invalid++
+		for _, problem := range problems {
+			notifier.Error(resource, problem)
+		}
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("found %s", Pluraliser(invalid, "invalid resource"))
+	}
+	return nil
+}
+
+// validateResource checks a resource with its handler, and against the
+// schema embedded for its kind. The handler's own schema checks only run
+// once the embedded schema is satisfied, as they'd report the same problems.
+func validateResource(handler Handler, resource Resource) []string {
+	var problems []string
+	if err := handler.Validate(resource); err != nil {
+		problems = append(problems, err.Error())
+	}
+	embedded, err := schemaProblems(handler, resource)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	problems = append(problems, embedded...)
+	if validator, ok := handler.(SchemaValidator); ok && len(embedded) == 0 {
+		if err := validator.ValidateSchema(resource); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	return problems
+}
+
+// lintQuery runs a linter against a query, and returns its output when it
+// fails. Queries read from other files are linted in place, so that linters
+// report the files users edit.
+func lintQuery(resource Resource, query Query, linter string) string {
+	command := strings.Fields(linter)
+	if len(command) == 0 {
+		return ""
+	}
+	value, _, ok := lookupPointer(resource.Spec(), query.Pointer)
+	if !ok {
+		return ""
+	}
+	content, ok := value.(string)
+	if !ok || content == "" {
+		return ""
+	}
+
+	file, ok := resource.Source.FileReferences[query.Pointer]
+	if ok {
+		file = resolveFileReference(filepath.Dir(resource.Source.Path), file)
+	} else {
+		temp, err := os.CreateTemp("", "grizzly-*."+query.Language)
+		if err != nil {
+			return fmt.Sprintf("%s: %s", query.Name, err)
+		}
+		defer os.Remove(temp.Name())
+		_, err = temp.WriteString(content + "\n")
+		if closeErr := temp.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Sprintf("%s: %s", query.Name, err)
+		}
+		file = temp.Name()
+	}
+
+	output, err := exec.Command(command[0], append(command[1:], file)...).CombinedOutput()
+	if err == nil {
+		return ""
+	}
+	details := strings.TrimSpace(string(output))
+	if details == "" {
+		details = err.Error()
+	}
+	return fmt.Sprintf("%s: %s", query.Name, details)
+}
@@ -0,0 +1,98 @@
+package grizzly
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/go-multierror"
+	log "github.com/sirupsen/logrus"
+)
+
+// quotaExceededMessage matches the messages of the errors returned by remote
+// endpoints when a quota or limit is reached. Ex: "Quota reached", "per-user
+// rule groups limit (limit: 70 actual: 71) exceeded"
+var quotaExceededMessage = regexp.MustCompile(`(?i)\bquota\b.*\b(reached|exceeded)\b|\blimit\b.*\b(reached|exceeded)\b|\bmaximum number of\b.*\breached\b`)
+
+// Quota is a limit on the resources of a kind
+type Quota struct {
+	// Limit is how many resources can exist. Negative when there is no limit
+	Limit int64
+	// Used is how many resources exist
+	Used int64
+	// Unit is what the quota counts. Ex: alert rules
+	Unit string
+}
+
+// Remaining returns how many more resources can be added
+func (q Quota) Remaining() int64 {
+	return max(q.Limit-q.Used, 0)
+}
+
+// QuotaHandler describes a handler whose resources count against a quota
+type QuotaHandler interface {
+	// Quota returns the quota of the resources of the handler, or nil when
+	// they have none
+	Quota() (*Quota, error)
+	// QuotaUsage returns how much of the quota a resource uses. Ex: the
+	// number of rules of an alert rule group counts against the quota of rules
+	QuotaUsage(resource Resource) int64
+}
+
+// CheckQuotas checks that the remaining quotas are enough to add the
+// resources not found remotely, so that applying many resources doesn't fail
+// part way through. Quotas that can't be read, often for lack of
+// permissions, aren't checked.
+func CheckQuotas(registry Registry, resources Resources) error {
+	var finalErr error
+	for kind, kindResources := range resources.GroupByKind() {
+		handler, err := registry.GetHandler(kind)
+		if err != nil {
+			return err
+		}
+		quotaHandler, ok := handler.(QuotaHandler)
+		if !ok {
+			continue
+		}
+		if err := checkQuota(handler, quotaHandler, kindResources); err != nil {
+			finalErr = multierror.Append(finalErr, err)
+		}
+	}
+	return finalErr
+}
+
+func checkQuota(handler Handler, quotaHandler QuotaHandler, resources Resources) error {
+	quota, err := quotaHandler.Quota()
+	if err != nil {
+		log.Debugf("Not checking the quota of %s: %s", handler.Kind(), err)
+		return nil
+	}
+	if quota == nil || quota.Limit < 0 {
+		return nil
+	}
+
+	remoteUIDs, err := handler.ListRemote()
+	if err != nil {
+		return ClassifyError(handler, fmt.Errorf("listing %s to check their quota: %w", handler.Kind(), err))
+	}
+	existing := make(map[string]bool, len(remoteUIDs))
+	for _, uid := range remoteUIDs {
+		existing[uid] = true
+	}
+
+	var needed int64
+	for _, resource := range resources.AsList() {
+		uid, err := handler.GetUID(resource)
+		if err != nil {
+			uid = resource.Name()
+		}
+		if !existing[uid] {
+			needed += quotaHandler.QuotaUsage(resource)
+		}
+	}
+
+	if needed > quota.Remaining() {
+		err := fmt.Errorf("adding %s needs %d %s, but only %d of the %d allowed remain", handler.Kind(), needed, quota.Unit, quota.Remaining(), quota.Limit)
+		return NewCodedError(ErrorCodeQuotaExceeded, err, errorHint(handler, ErrorCodeQuotaExceeded))
+	}
+	return nil
+}
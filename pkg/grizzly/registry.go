@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/gobwas/glob"
+	"github.com/grafana/grizzly/pkg/config"
 )
 
 type ProviderStatus struct {
@@ -41,6 +42,34 @@ type Registry struct {
 	Providers    []Provider
 	Handlers     map[string]Handler
 	HandlerOrder []Handler
+	// LegacyKinds maps kind names that are no longer in use to their current name
+	LegacyKinds map[string]string
+	// AnnotationTargets maps kinds, case-insensitively, to where the annotations
+	// of their resources are exposed remotely. Kinds not listed use the default target of their handler
+	AnnotationTargets map[string]string
+	// YAMLStyle is the style of the YAML resources are formatted with
+	YAMLStyle config.YAMLStyle
+	// RuleIntervals is the policy the evaluation intervals of rule groups must follow
+	RuleIntervals config.RuleIntervalsConfig
+	// DiffTool is the external command diffs are shown with, instead of unified diffs
+	DiffTool string
+	// DiffSummarySize is the size, in bytes, above which resources are diffed
+	// as a summary of the paths changed rather than in full. Zero means in full
+	DiffSummarySize int64
+	// Filenames is how UIDs are turned into filenames: legacy (default), escape or ascii
+	Filenames string
+	// PathTemplate is where resources are written, relative to their resource
+	// path, instead of the layout of their handler, if set
+	PathTemplate string
+	// FetchConcurrency is how many remote resources of a kind diffs and pulls
+	// retrieve at the same time. Zero means one at a time
+	FetchConcurrency int
+	// PruneRules maps kinds, lowercased, to the fields removed from the
+	// resources pulled, and from both sides of diffs
+	PruneRules map[string][]PruneRule
+	// RemoteCache, when set, keeps the remote resources retrieved by diffs and
+	// pulls, so that unchanged ones aren't retrieved again
+	RemoteCache *RemoteCache
 }
 
 // NewRegistry returns an empty registry
@@ -48,6 +77,7 @@ func NewRegistry(providers []Provider) Registry {
 	registry := Registry{
 		Handlers:     map[string]Handler{},
 		HandlerOrder: []Handler{},
+		LegacyKinds:  map[string]string{},
 	}
 
 	registry.Providers = providers
@@ -55,6 +85,12 @@ func NewRegistry(providers []Provider) Registry {
 		for _, handler := range provider.GetHandlers() {
 			registry.Handlers[handler.Kind()] = handler
 			registry.HandlerOrder = append(registry.HandlerOrder, handler)
+
+			if legacyHandler, ok := handler.(LegacyKindsHandler); ok {
+				for _, legacyKind := range legacyHandler.LegacyKinds() {
+					registry.LegacyKinds[legacyKind] = handler.Kind()
+				}
+			}
 		}
 	}
 	return registry
@@ -72,7 +108,9 @@ func (r *Registry) GetHandler(kind string) (Handler, error) {
 // HandlerMatchesTarget identifies whether a handler is in a target list
 func (r *Registry) HandlerMatchesTarget(handler Handler, targets []string) bool {
 	if len(targets) == 0 {
-		return true
+		// composite kinds duplicate other kinds, and are only matched explicitly
+		_, composite := handler.(CompositeHandler)
+		return !composite
 	}
 	key := handler.Kind()
 
@@ -113,18 +151,43 @@ func (r *Registry) ResourceMatchesTarget(kind string, uid string, targets []stri
 	return false
 }
 
+// Sort orders resources by kind, in the order of the registry, then moves
+// resources after the resources they depend on. Resources whose dependencies
+// can't be followed are left in the order of their kind, see
+// CheckDependencies.
 func (r *Registry) Sort(resources Resources) Resources {
 	sorted := NewResources()
 	resourceByKind := resources.GroupByKind()
 
 	for _, handler := range r.HandlerOrder {
-		handlerResources := resourceByKind[handler.Kind()]
+		// handlers are only called for their resources, so that the plugins
+		// providing the other kinds aren't started
+		handlerResources, ok := resourceByKind[handler.Kind()]
+		if !ok {
+			continue
+		}
 		sorted.Merge(handler.Sort(handlerResources))
 	}
+	// patches have no handler, and are resolved into the resources they target
+	sorted.Merge(resourceByKind[PatchKind])
 
+	if ordered, err := r.orderDependencies(sorted); err == nil {
+		return ordered
+	}
 	return sorted
 }
 
+// inferMetadata lets the handler of a parsed resource fill in the metadata
+// that can be derived from the file it was read from
+func (r *Registry) inferMetadata(resource *Resource) {
+	if resource.Source.Path == "" {
+		return
+	}
+	if inferrer, ok := r.Handlers[resource.Kind()].(MetadataInferrer); ok {
+		inferrer.InferMetadata(resource)
+	}
+}
+
 func (r *Registry) Detect(data any) string {
 	m, ok := data.(map[string]any)
 	if !ok {
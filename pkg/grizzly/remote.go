@@ -0,0 +1,192 @@
+package grizzly
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi"
+	"github.com/grafana/grizzly/internal/httputils"
+	"github.com/pmezard/go-difflib/difflib"
+	log "github.com/sirupsen/logrus"
+)
+
+// remoteTracker records the remote version of the resources displayed by the
+// server as of when their local file was loaded, so that concurrent changes
+// made remotely (e.g. by a colleague in the Grafana UI) can be detected.
+type remoteTracker struct {
+	mu        sync.Mutex
+	baselines map[ResourceRef]string
+}
+
+func newRemoteTracker() *remoteTracker {
+	return &remoteTracker{
+		baselines: map[ResourceRef]string{},
+	}
+}
+
+// changed reports whether the remote version of a resource differs from the
+// one recorded. The current version is recorded if none was.
+func (t *remoteTracker) changed(handler Handler, resource Resource) (bool, error) {
+	fingerprint, err := remoteFingerprint(handler, resource)
+	if err != nil {
+		return false, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	baseline, found := t.baselines[resource.Ref()]
+	if !found {
+		t.baselines[resource.Ref()] = fingerprint
+		return false, nil
+	}
+	return baseline != fingerprint, nil
+}
+
+// forget drops the recorded remote version of a resource, once its local file is reloaded
+func (t *remoteTracker) forget(ref ResourceRef) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.baselines, ref)
+}
+
+// remoteFingerprint identifies the current remote version of a resource. It
+// is empty if the resource doesn't exist remotely.
+func remoteFingerprint(handler Handler, resource Resource) (string, error) {
+	remote, err := handler.GetRemote(resource)
+	if errors.Is(err, ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	content, err := json.Marshal(handler.Unprepare(*remote).Spec())
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+type diffLine struct {
+	Class string
+	Text  string
+}
+
+// diffLines splits a unified diff into lines, classified for display
+func diffLines(diff string) []diffLine {
+	var lines []diffLine
+	for _, text := range strings.SplitAfter(diff, "\n") {
+		if text == "" {
+			continue
+		}
+		line := diffLine{Text: text}
+		switch {
+		case strings.HasPrefix(text, "+++"), strings.HasPrefix(text, "---"):
+		case strings.HasPrefix(text, "+"):
+			line.Class = "added"
+		case strings.HasPrefix(text, "-"):
+			line.Class = "removed"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+type remoteStatus struct {
+	Changed bool   `json:"changed"`
+	DiffURL string `json:"diffURL,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// localResource finds the local resource displayed at /grizzly/{kind}/{name}, along with its handler
+func (s *Server) localResource(r *http.Request) (Handler, Resource, error) {
+	kind := chi.URLParam(r, "kind")
+	name := chi.URLParam(r, "name")
+
+	handler, err := s.Registry.GetHandler(kind)
+	if err != nil {
+		return nil, Resource{}, err
+	}
+	resource, found := s.Resources.Find(ResourceRef{Kind: handler.Kind(), Name: name})
+	if !found {
+		return nil, Resource{}, fmt.Errorf("%s.%s not found locally", kind, name)
+	}
+	return handler, resource, nil
+}
+
+// remoteStatusHandler reports whether the remote version of a resource
+// changed since its local file was loaded
+func (s *Server) remoteStatusHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var status remoteStatus
+	handler, resource, err := s.localResource(r)
+	if err == nil {
+		status.Changed, err = s.remote.changed(handler, resource)
+	}
+	if err != nil {
+		log.Debugf("Checking the remote version of %s.%s: %s", chi.URLParam(r, "kind"), chi.URLParam(r, "name"), err)
+		status.Error = err.Error()
+	}
+	if status.Changed {
+		status.DiffURL = fmt.Sprintf("/grizzly/%s/%s/diff", resource.Kind(), resource.Name())
+	}
+
+	content, _ := json.Marshal(status)
+	httputils.Write(w, content)
+}
+
+// remoteDiffHandler shows the differences between the remote and local versions of a resource
+func (s *Server) remoteDiffHandler(w http.ResponseWriter, r *http.Request) {
+	handler, resource, err := s.localResource(r)
+	if err != nil {
+		httputils.Error(w, "Error finding resource", err, http.StatusNotFound)
+		return
+	}
+
+	local, _, _, err := Format(s.Registry, "", handler.Unprepare(resource), s.OutputFormat, false)
+	if err != nil {
+		httputils.Error(w, "Error formatting local resource", err, http.StatusInternalServerError)
+		return
+	}
+
+	var remoteContent []byte
+	remote, err := handler.GetRemote(resource)
+	switch {
+	case errors.Is(err, ErrNotFound):
+	case err != nil:
+		httputils.Error(w, "Error retrieving remote resource", err, http.StatusBadGateway)
+		return
+	default:
+		remoteContent, _, _, err = Format(s.Registry, "", handler.Unprepare(*remote), s.OutputFormat, false)
+		if err != nil {
+			httputils.Error(w, "Error formatting remote resource", err, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(remoteContent)),
+		B:        difflib.SplitLines(string(local)),
+		FromFile: "Remote",
+		ToFile:   "Local",
+		Context:  3,
+	})
+
+	templateVars := map[string]any{
+		"CurrentContext": s.CurrentContext,
+		"Resource":       resource.Ref().String(),
+		"Diff":           diffLines(diff),
+	}
+	if err := templates.ExecuteTemplate(w, "proxy/diff.html.tmpl", templateVars); err != nil {
+		httputils.Error(w, "Error while executing template", err, http.StatusInternalServerError)
+		return
+	}
+}
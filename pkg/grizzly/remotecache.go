@@ -0,0 +1,121 @@
+package grizzly
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// remoteCacheFormat changes whenever the resources returned by handlers do,
+// so that the resources cached by previous versions of Grizzly are ignored
+const remoteCacheFormat = "2"
+
+// RemoteCache keeps the remote resources retrieved by diffs and pulls on
+// disk, with their version, so that unchanged resources aren't retrieved
+// again. Only the resources of handlers implementing RemoteVersioner are
+// cached.
+type RemoteCache struct {
+	dir string
+}
+
+// remoteCacheEntry is a cached remote resource
+type remoteCacheEntry struct {
+	Format   string         `json:"format"`
+	Version  string         `json:"version"`
+	Resource map[string]any `json:"resource"`
+}
+
+// NewRemoteCache returns a cache kept in a directory, with the resources of
+// each namespace, such as a context, kept apart
+func NewRemoteCache(dir, namespace string) *RemoteCache {
+	return &RemoteCache{dir: filepath.Join(dir, unsafeFileCharacters.ReplaceAllString(namespace, "-"))}
+}
+
+// path returns the file a resource is cached in. A resource is cached in a
+// single file, whatever its version, so that the cache doesn't grow as
+// resources change.
+func (c *RemoteCache) path(kind, uid string) string {
+	sum := sha256.Sum256([]byte(uid))
+	return filepath.Join(c.dir, unsafeFileCharacters.ReplaceAllString(kind, "-"), hex.EncodeToString(sum[:])+".json")
+}
+
+// get returns the cached version of a resource, if any, and the version it
+// was cached with
+func (c *RemoteCache) get(kind, uid string) (*Resource, string, bool) {
+	data, err := os.ReadFile(c.path(kind, uid))
+	if err != nil {
+		return nil, "", false
+	}
+	var entry remoteCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Format != remoteCacheFormat || entry.Version == "" {
+		return nil, "", false
+	}
+	resource, err := ResourceFromMap(entry.Resource)
+	if err != nil {
+		return nil, "", false
+	}
+	return resource, entry.Version, true
+}
+
+// put caches a version of a resource. Failing to cache a resource doesn't
+// fail the command, which only gets slower.
+func (c *RemoteCache) put(kind, uid, version string, resource Resource) {
+	data, err := json.Marshal(remoteCacheEntry{Format: remoteCacheFormat, Version: version, Resource: resource.Body})
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(c.path(kind, uid)), 0700)
+	}
+	if err == nil {
+		err = os.WriteFile(c.path(kind, uid), data, 0600)
+	}
+	if err != nil {
+		log.Debugf("Caching %s.%s failed: %s", kind, uid, err)
+	}
+}
+
+// getCached retrieves a remote resource with get, unless its current
+// version is in the remote cache of the registry. The version of resources
+// that aren't cached yet isn't asked for, as there is nothing to compare it
+// with: they are cached with the version they are retrieved with, if it can
+// be told.
+func getCached(registry Registry, handler Handler, uid string, get func() (*Resource, error)) (*Resource, error) {
+	versioner, ok := handler.(RemoteVersioner)
+	if registry.RemoteCache == nil || !ok {
+		return get()
+	}
+
+	cached, cachedVersion, found := registry.RemoteCache.get(handler.Kind(), uid)
+	if !found {
+		resource, err := get()
+		if err != nil {
+			return nil, err
+		}
+		if version := versioner.ResourceVersion(*resource); version != "" {
+			registry.RemoteCache.put(handler.Kind(), uid, version, *resource)
+		}
+		return resource, nil
+	}
+
+	// resources whose version is unknown, including those that no longer
+	// exist, are retrieved as usual
+	version, err := versioner.RemoteVersion(uid)
+	if err != nil || version == "" {
+		if err != nil {
+			log.Debugf("Getting the version of %s.%s failed, it isn't cached: %s", handler.Kind(), uid, err)
+		}
+		return get()
+	}
+	if version == cachedVersion {
+		log.Debugf("Using the cached version %s of %s.%s", version, handler.Kind(), uid)
+		return cached, nil
+	}
+	resource, err := get()
+	if err != nil {
+		return nil, err
+	}
+	registry.RemoteCache.put(handler.Kind(), uid, version, *resource)
+	return resource, nil
+}
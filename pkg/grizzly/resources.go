@@ -40,6 +40,11 @@ type Source struct {
 	Rewritable bool
 	// WithEnvelope indicates whether the resource had an envelope or not.
 	WithEnvelope bool
+	// FanOut is the name of the fan-out the resource is an instance of, if any
+	FanOut string
+	// FileReferences maps the JSON pointers of values of the spec that were
+	// read from other files to the paths of these files.
+	FileReferences map[string]string
 }
 
 // Resource represents a single Resource destined for a single endpoint
@@ -132,6 +137,51 @@ func (r *Resource) SetMetadata(key, value string) {
 	r.Body["metadata"] = metadata
 }
 
+// Annotations returns the annotations of the resource, from its metadata
+func (r *Resource) Annotations() map[string]string {
+	values, _ := r.metadata()["annotations"].(map[string]any)
+	annotations := make(map[string]string, len(values))
+	for key, value := range values {
+		annotations[key] = fmt.Sprint(value)
+	}
+	return annotations
+}
+
+// Labels returns the labels of the resource, from its metadata
+func (r *Resource) Labels() map[string]string {
+	values, _ := r.metadata()["labels"].(map[string]any)
+	labels := make(map[string]string, len(values))
+	for key, value := range values {
+		labels[key] = fmt.Sprint(value)
+	}
+	return labels
+}
+
+// DeepCopy returns a copy of the resource that can be modified without changing the original
+func (r Resource) DeepCopy() Resource {
+	body, _ := deepCopyValue(r.Body).(map[string]any)
+	return Resource{Body: body, Source: r.Source}
+}
+
+func deepCopyValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		copied := make(map[string]any, len(v))
+		for key, item := range v {
+			copied[key] = deepCopyValue(item)
+		}
+		return copied
+	case []any:
+		copied := make([]any, len(v))
+		for i, item := range v {
+			copied[i] = deepCopyValue(item)
+		}
+		return copied
+	default:
+		return value
+	}
+}
+
 func (r *Resource) HasSpecString(key string) bool {
 	_, ok := r.Spec()[key]
 	return ok
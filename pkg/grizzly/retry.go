@@ -0,0 +1,131 @@
+package grizzly
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultRetryFile is where the resources that failed to apply are recorded,
+// per context, relative to the working directory
+const DefaultRetryFile = ".grizzly/failed.yaml"
+
+// FailedResources lists the resources that failed to apply to a context, as
+// targets, along with the resource path they were applied from
+type FailedResources struct {
+	ResourcePath string   `yaml:"resourcePath"`
+	Targets      []string `yaml:"targets"`
+}
+
+// FailureRecorder records the resources whose events are failures, before
+// passing events on to another recorder
+type FailureRecorder struct {
+	EventsRecorder
+	lock    sync.Mutex
+	targets []string
+}
+
+func NewFailureRecorder(recorder EventsRecorder) *FailureRecorder {
+	return &FailureRecorder{EventsRecorder: recorder}
+}
+
+func (r *FailureRecorder) Record(event Event) {
+	if event.Type == ResourceFailure {
+		// references are <kind>.<name>, and kinds have no dots
+		if kind, name, ok := strings.Cut(event.ResourceRef, "."); ok {
+			r.lock.Lock()
+			if target := kind + "/" + name; !slices.Contains(r.targets, target) {
+				r.targets = append(r.targets, target)
+			}
+			r.lock.Unlock()
+		}
+	}
+	r.EventsRecorder.Record(event)
+}
+
+// Failed returns the resources that failed, as targets
+func (r *FailureRecorder) Failed() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return slices.Sorted(slices.Values(r.targets))
+}
+
+// WriteFailedResources records the resources that failed to apply to a
+// context in a retry file, replacing the ones recorded before. The context is
+// removed from the file when no resources failed.
+func WriteFailedResources(path, context, resourcePath string, targets []string) error {
+	contextStateLock.Lock()
+	defer contextStateLock.Unlock()
+
+	failed, err := readFailedResources(path)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		if _, ok := failed[context]; !ok {
+			return nil
+		}
+		delete(failed, context)
+	} else {
+		failed[context] = FailedResources{ResourcePath: trackedSource(resourcePath), Targets: targets}
+	}
+
+	if len(failed) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	content, err := yaml.Marshal(failed)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
+
+// ReadFailedResources returns the resources that failed to apply to a context
+// from resourcePath, as recorded in a retry file
+func ReadFailedResources(path, context, resourcePath string) ([]string, error) {
+	contextStateLock.Lock()
+	defer contextStateLock.Unlock()
+
+	failed, err := readFailedResources(path)
+	if err != nil {
+		return nil, err
+	}
+	resources, ok := failed[context]
+	if !ok {
+		return nil, fmt.Errorf("no resources failed to apply to context %s", context)
+	}
+	if resources.ResourcePath != trackedSource(resourcePath) {
+		return nil, fmt.Errorf("the resources that failed to apply to context %s were applied from %s, not %s", context, resources.ResourcePath, resourcePath)
+	}
+	return resources.Targets, nil
+}
+
+func readFailedResources(path string) (map[string]FailedResources, error) {
+	failed := map[string]FailedResources{}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return failed, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(content, &failed); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if failed == nil {
+		failed = map[string]FailedResources{}
+	}
+	return failed, nil
+}
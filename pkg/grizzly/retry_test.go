@@ -0,0 +1,47 @@
+package grizzly
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailureRecorder(t *testing.T) {
+	recorder := NewFailureRecorder(NewWriterRecorder(io.Discard, EventToPlainText))
+	recorder.Record(Event{Type: ResourceAdded, ResourceRef: "Dashboard.ok"})
+	recorder.Record(Event{Type: ResourceFailure, ResourceRef: "Dashboard.broken"})
+	recorder.Record(Event{Type: ResourceFailure, ResourceRef: "AlertRuleGroup.folder.group"})
+	recorder.Record(Event{Type: ResourceFailure, ResourceRef: "Dashboard.broken"})
+
+	require.Equal(t, []string{"AlertRuleGroup/folder.group", "Dashboard/broken"}, recorder.Failed())
+	require.Equal(t, 3, recorder.Summary().EventCounts[ResourceFailure], "events are passed on")
+}
+
+func TestFailedResources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "failed.yaml")
+
+	_, err := ReadFailedResources(path, "prod", "dashboards")
+	require.ErrorContains(t, err, "no resources failed to apply to context prod")
+
+	require.NoError(t, WriteFailedResources(path, "prod", "dashboards/", []string{"Dashboard/broken"}))
+	require.NoError(t, WriteFailedResources(path, "staging", "./dashboards", []string{"Dashboard/flaky"}))
+
+	failed, err := ReadFailedResources(path, "prod", "./dashboards")
+	require.NoError(t, err)
+	require.Equal(t, []string{"Dashboard/broken"}, failed)
+
+	_, err = ReadFailedResources(path, "prod", "alerts")
+	require.ErrorContains(t, err, "were applied from dashboards, not alerts")
+
+	require.NoError(t, WriteFailedResources(path, "prod", "dashboards", nil))
+	_, err = ReadFailedResources(path, "prod", "dashboards")
+	require.Error(t, err, "contexts whose resources all applied are removed")
+	failed, err = ReadFailedResources(path, "staging", "dashboards")
+	require.NoError(t, err)
+	require.Equal(t, []string{"Dashboard/flaky"}, failed)
+
+	require.NoError(t, WriteFailedResources(path, "staging", "dashboards", nil))
+	require.NoFileExists(t, path)
+}
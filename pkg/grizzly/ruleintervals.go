@@ -0,0 +1,86 @@
+package grizzly
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
+)
+
+// RuleGroupHandler describes a handler of rule groups, evaluated at an interval
+type RuleGroupHandler interface {
+	// EvaluationInterval returns the evaluation interval of a rule group, if it sets one
+	EvaluationInterval(resource Resource) (time.Duration, bool, error)
+}
+
+// ruleIntervalPolicy is the parsed policy of the registry
+type ruleIntervalPolicy struct {
+	min            time.Duration
+	scrapeInterval time.Duration
+}
+
+func (r *Registry) ruleIntervalPolicy() (ruleIntervalPolicy, error) {
+	var policy ruleIntervalPolicy
+	var err error
+	if r.RuleIntervals.Min != "" {
+		if policy.min, err = time.ParseDuration(r.RuleIntervals.Min); err != nil {
+			return policy, fmt.Errorf("invalid rule-intervals.min %s: %w", r.RuleIntervals.Min, err)
+		}
+	}
+	if r.RuleIntervals.ScrapeInterval != "" {
+		if policy.scrapeInterval, err = time.ParseDuration(r.RuleIntervals.ScrapeInterval); err != nil {
+			return policy, fmt.Errorf("invalid rule-intervals.scrape-interval %s: %w", r.RuleIntervals.ScrapeInterval, err)
+		}
+	}
+	return policy, nil
+}
+
+// ruleIntervalProblem returns why the evaluation interval of a rule group
+// breaks the policy, if it does
+func ruleIntervalProblem(policy ruleIntervalPolicy, handler Handler, resource Resource) string {
+	ruleGroupHandler, ok := handler.(RuleGroupHandler)
+	if !ok || (policy.min == 0 && policy.scrapeInterval == 0) {
+		return ""
+	}
+	interval, set, err := ruleGroupHandler.EvaluationInterval(resource)
+	if err != nil {
+		return err.Error()
+	}
+	if !set {
+		return ""
+	}
+	if interval < policy.min {
+		return fmt.Sprintf("evaluation interval %s is below the minimum of %s", interval, policy.min)
+	}
+	if policy.scrapeInterval > 0 && interval%policy.scrapeInterval != 0 {
+		return fmt.Sprintf("evaluation interval %s isn't a multiple of the scrape interval, %s", interval, policy.scrapeInterval)
+	}
+	return ""
+}
+
+// CheckRuleIntervals rejects the rule groups whose evaluation interval breaks
+// the policy of the registry: below a minimum, or not a multiple of the scrape
+// interval, as rules evaluated too often overload rulers.
+func CheckRuleIntervals(registry Registry, resources Resources) error {
+	policy, err := registry.ruleIntervalPolicy()
+	if err != nil {
+		return err
+	}
+
+	rejected := 0
+	for _, resource := range resources.AsList() {
+		handler, err := registry.GetHandler(resource.Kind())
+		if err != nil {
+			return err
+		}
+		if problem := ruleIntervalProblem(policy, handler, resource); problem != "" {
+			notifier.Error(resource, problem)
+			rejected++
+		}
+	}
+
+	if rejected > 0 {
+		return fmt.Errorf("%s rejected by the rule-intervals policy", Pluraliser(rejected, "rule group"))
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package grizzly_test
+
+import (
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grafana"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/mimir"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRuleIntervals(t *testing.T) {
+	registry := grizzly.NewRegistry([]grizzly.Provider{
+		grafana.NewProvider(&config.GrafanaConfig{}),
+		mimir.NewProvider(&config.MimirConfig{}),
+	})
+
+	ruleGroup := func(kind, name string, interval any) grizzly.Resource {
+		spec := map[string]any{"name": name}
+		if interval != nil {
+			spec["interval"] = interval
+		}
+		resource, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", kind, name, spec)
+		require.NoError(t, err)
+		return resource
+	}
+
+	t.Run("no policy", func(t *testing.T) {
+		resources := grizzly.NewResources(ruleGroup(mimir.PrometheusRuleGroupKind, "fast", "10s"))
+		require.NoError(t, grizzly.CheckRuleIntervals(registry, resources))
+	})
+
+	registry.RuleIntervals = config.RuleIntervalsConfig{Min: "1m", ScrapeInterval: "15s"}
+
+	t.Run("intervals following the policy", func(t *testing.T) {
+		resources := grizzly.NewResources(
+			ruleGroup(mimir.PrometheusRuleGroupKind, "default", nil),
+			ruleGroup(mimir.PrometheusRuleGroupKind, "slow", "2m"),
+			ruleGroup(grafana.AlertRuleGroupKind, "folder.group", 60),
+		)
+		require.NoError(t, grizzly.CheckRuleIntervals(registry, resources))
+	})
+
+	t.Run("intervals breaking the policy", func(t *testing.T) {
+		resources := grizzly.NewResources(
+			ruleGroup(mimir.PrometheusRuleGroupKind, "fast", "10s"),
+			ruleGroup(mimir.PrometheusRuleGroupKind, "odd", "70s"),
+			ruleGroup(grafana.AlertRuleGroupKind, "folder.group", float64(30)),
+		)
+		err := grizzly.CheckRuleIntervals(registry, resources)
+		require.EqualError(t, err, "3 rule groups rejected by the rule-intervals policy")
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		registry.RuleIntervals = config.RuleIntervalsConfig{Min: "a minute"}
+		err := grizzly.CheckRuleIntervals(registry, grizzly.NewResources())
+		require.ErrorContains(t, err, "invalid rule-intervals.min")
+	})
+}
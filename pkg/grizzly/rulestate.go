@@ -0,0 +1,287 @@
+package grizzly
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	log "github.com/sirupsen/logrus"
+)
+
+// Health of evaluated rules, as reported by Prometheus compatible rules APIs
+const (
+	RuleHealthOK      = "ok"
+	RuleHealthError   = "error"
+	RuleHealthUnknown = "unknown"
+)
+
+// ruleStatePollInterval is how often rules are checked while waiting for their evaluation
+const ruleStatePollInterval = 5 * time.Second
+
+// RuleStateHandler describes a handler that knows how the rules of remote
+// resources are evaluated, to catch errors that only appear at evaluation time
+type RuleStateHandler interface {
+	// RuleStates returns the evaluation state of the rules of a remote resource
+	RuleStates(resource Resource) ([]RuleState, error)
+}
+
+// RuleState describes the last evaluation of a remote rule
+type RuleState struct {
+	Rule string
+	// State is inactive, pending or firing for alerting rules, empty for recording rules
+	State string
+	// Health is ok, error, unknown, or any other value specific to the remote endpoint (e.g. nodata)
+	Health    string
+	LastError string
+	// LastEvaluation is zero if the rule was never evaluated
+	LastEvaluation time.Time
+}
+
+// Failed reports whether the last evaluation of the rule failed
+func (s RuleState) Failed() bool {
+	return s.Health == RuleHealthError || s.LastError != ""
+}
+
+func (s RuleState) String() string {
+	state := s.State
+	if state == "" {
+		state = s.Health
+	}
+	if s.Failed() && s.LastError != "" {
+		return fmt.Sprintf("%s %s (%s)", s.Rule, state, s.LastError)
+	}
+	return fmt.Sprintf("%s %s", s.Rule, state)
+}
+
+// changeRecorder forwards events, and remembers which resources were added or updated
+type changeRecorder struct {
+	EventsRecorder
+	changed map[string]bool
+}
+
+// lockedRecorder serialises the events recorded by resources applied concurrently
+type lockedRecorder struct {
+	EventsRecorder
+	mu sync.Mutex
+}
+
+func (r *lockedRecorder) Record(event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.EventsRecorder.Record(event)
+}
+
+func (r *changeRecorder) Record(event Event) {
+	if event.Type == ResourceAdded || event.Type == ResourceUpdated {
+		r.changed[event.ResourceRef] = true
+	}
+	r.EventsRecorder.Record(event)
+}
+
+// checkRuleStates waits up to timeout for the rules of the given resources to
+// be evaluated since appliedAt, then records their state. Rules failing their
+// evaluation are reported as errors. Resources whose handler doesn't implement
+// RuleStateHandler are ignored.
+func checkRuleStates(registry Registry, resources []Resource, appliedAt time.Time, timeout time.Duration, eventsRecorder EventsRecorder) error {
+	type pendingResource struct {
+		handler  RuleStateHandler
+		resource Resource
+	}
+
+	var pending []pendingResource
+	for _, resource := range resources {
+		handler, err := registry.GetHandler(resource.Kind())
+		if err != nil {
+			return err
+		}
+		if stateHandler, ok := handler.(RuleStateHandler); ok {
+			pending = append(pending, pendingResource{handler: stateHandler, resource: resource})
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	var finalErr error
+	deadline := time.Now().Add(timeout)
+	for {
+		var notEvaluated []pendingResource
+		for _, p := range pending {
+			remaining := time.Until(deadline)
+			states, err := p.handler.RuleStates(p.resource)
+			// rules may take a moment to be scheduled once created
+			if errors.Is(err, ErrNotFound) {
+				if remaining > 0 {
+					notEvaluated = append(notEvaluated, p)
+				} else {
+					eventsRecorder.Record(Event{Type: RuleNotEvaluated, ResourceRef: p.resource.Ref().String(), Details: "rules not found"})
+				}
+				continue
+			}
+			if err != nil {
+				handler, _ := registry.GetHandler(p.resource.Kind())
+				err = ClassifyError(handler, fmt.Errorf("checking rule states: %w", err))
+				finalErr = multierror.Append(finalErr, err)
+				eventsRecorder.Record(NewFailureEvent(p.resource.Ref().String(), err.Error(), err))
+				continue
+			}
+
+			if !evaluatedSince(states, appliedAt) && remaining > 0 {
+				notEvaluated = append(notEvaluated, p)
+				continue
+			}
+			if err := recordRuleStates(p.resource, states, appliedAt, eventsRecorder); err != nil {
+				finalErr = multierror.Append(finalErr, err)
+			}
+		}
+		if len(notEvaluated) == 0 {
+			return finalErr
+		}
+
+		pending = notEvaluated
+		wait := min(ruleStatePollInterval, time.Until(deadline))
+		log.Debugf("Waiting %s for the evaluation of the rules of %d resources", wait.Round(time.Millisecond), len(pending))
+		time.Sleep(wait)
+	}
+}
+
+// tailRuleStates watches the rules of the given resources for window, polling
+// their state, and records each evaluation error as it first appears, as
+// errors such as many-to-many matching only show up with the series evaluated.
+// Evaluations older than appliedAt are ignored. Resources whose handler
+// doesn't implement RuleStateHandler are ignored.
+func tailRuleStates(registry Registry, resources []Resource, appliedAt time.Time, window time.Duration, eventsRecorder EventsRecorder) error {
+	type tailedResource struct {
+		handler  RuleStateHandler
+		resource Resource
+		// failed are the rules that failed their evaluation, with the errors already reported
+		failed map[string]map[string]bool
+	}
+
+	var tailed []*tailedResource
+	for _, resource := range resources {
+		handler, err := registry.GetHandler(resource.Kind())
+		if err != nil {
+			return err
+		}
+		if stateHandler, ok := handler.(RuleStateHandler); ok {
+			tailed = append(tailed, &tailedResource{handler: stateHandler, resource: resource, failed: map[string]map[string]bool{}})
+		}
+	}
+	if len(tailed) == 0 {
+		return nil
+	}
+
+	var finalErr error
+	deadline := time.Now().Add(window)
+	for len(tailed) > 0 {
+		var remaining []*tailedResource
+		for _, t := range tailed {
+			states, err := t.handler.RuleStates(t.resource)
+			// rules may take a moment to be scheduled once created
+			if errors.Is(err, ErrNotFound) {
+				remaining = append(remaining, t)
+				continue
+			}
+			if err != nil {
+				handler, _ := registry.GetHandler(t.resource.Kind())
+				err = ClassifyError(handler, fmt.Errorf("tailing rule states: %w", err))
+				finalErr = multierror.Append(finalErr, err)
+				eventsRecorder.Record(NewFailureEvent(t.resource.Ref().String(), err.Error(), err))
+				continue
+			}
+
+			sort.Slice(states, func(i, j int) bool {
+				return states[i].Rule < states[j].Rule
+			})
+			for _, state := range states {
+				if !state.Failed() || state.LastEvaluation.Before(appliedAt) || t.failed[state.Rule][state.LastError] {
+					continue
+				}
+				if t.failed[state.Rule] == nil {
+					t.failed[state.Rule] = map[string]bool{}
+				}
+				t.failed[state.Rule][state.LastError] = true
+				eventsRecorder.Record(Event{
+					Type:        RuleEvaluationFailed,
+					ResourceRef: t.resource.Ref().String(),
+					Details:     state.String(),
+				})
+			}
+			remaining = append(remaining, t)
+		}
+		tailed = remaining
+
+		wait := min(ruleStatePollInterval, time.Until(deadline))
+		if wait <= 0 {
+			break
+		}
+		log.Debugf("Tailing the evaluation of the rules of %d resources for %s", len(tailed), time.Until(deadline).Round(time.Second))
+		time.Sleep(wait)
+	}
+
+	for _, t := range tailed {
+		if len(t.failed) > 0 {
+			finalErr = multierror.Append(finalErr, fmt.Errorf("%s: %d rules failed their evaluation within %s of being applied", t.resource.Ref(), len(t.failed), window))
+		}
+	}
+	return finalErr
+}
+
+// evaluatedSince reports whether all rules were evaluated since the given time
+func evaluatedSince(states []RuleState, since time.Time) bool {
+	for _, state := range states {
+		if state.LastEvaluation.Before(since) {
+			return false
+		}
+	}
+	return true
+}
+
+// recordRuleStates records the evaluation state of the rules of a resource as a
+// single event, and returns an error if any of them failed
+func recordRuleStates(resource Resource, states []RuleState, appliedAt time.Time, eventsRecorder EventsRecorder) error {
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Rule < states[j].Rule
+	})
+
+	var evaluated, failed, notEvaluated []string
+	for _, state := range states {
+		switch {
+		case state.LastEvaluation.Before(appliedAt):
+			notEvaluated = append(notEvaluated, state.Rule)
+		case state.Failed():
+			failed = append(failed, state.String())
+		default:
+			evaluated = append(evaluated, state.String())
+		}
+	}
+
+	ref := resource.Ref().String()
+	if len(failed) > 0 {
+		eventsRecorder.Record(Event{
+			Type:        RuleEvaluationFailed,
+			ResourceRef: ref,
+			Details:     strings.Join(failed, ", "),
+		})
+		return fmt.Errorf("%s: %d rules failed their evaluation", ref, len(failed))
+	}
+	if len(notEvaluated) > 0 {
+		eventsRecorder.Record(Event{
+			Type:        RuleNotEvaluated,
+			ResourceRef: ref,
+			Details:     strings.Join(notEvaluated, ", "),
+		})
+		return nil
+	}
+	eventsRecorder.Record(Event{
+		Type:        RuleEvaluated,
+		ResourceRef: ref,
+		Details:     strings.Join(evaluated, ", "),
+	})
+	return nil
+}
@@ -0,0 +1,181 @@
+package grizzly
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
+)
+
+//go:embed embed/scaffold/*.tmpl
+//nolint:gochecknoglobals
+var scaffoldFS embed.FS
+
+// DefaultScaffoldKinds lists the kinds scaffolded when none are explicitly selected.
+var DefaultScaffoldKinds = []string{"DashboardFolder", "Dashboard"}
+
+type scaffoldFile struct {
+	template string
+	path     string
+	// kinds restricts the file to repositories managing at least one of these kinds.
+	// An empty list means the file is always written.
+	kinds []string
+}
+
+// scaffoldKinds maps the kinds supported by `grr init` to the provider their
+// resources are managed by.
+var scaffoldKinds = map[string]string{
+	"DashboardFolder":          "grafana",
+	"Dashboard":                "grafana",
+	"Datasource":               "grafana",
+	"AlertRuleGroup":           "grafana",
+	"PrometheusRuleGroup":      "mimir",
+	"SyntheticMonitoringCheck": "synthetic-monitoring",
+}
+
+var scaffoldFiles = []scaffoldFile{
+	{template: "README.md.tmpl", path: "README.md"},
+	{template: "gitignore.tmpl", path: ".gitignore"},
+	{template: "settings.yaml.tmpl", path: "settings.yaml.example"},
+	{template: "ci.yml.tmpl", path: ".github/workflows/grizzly.yml"},
+	{template: "jsonnetfile.json.tmpl", path: "jsonnetfile.json"},
+	{template: "main.jsonnet.tmpl", path: "main.jsonnet"},
+	{template: "lib-grizzly.libsonnet.tmpl", path: "lib/grizzly.libsonnet"},
+	{template: "folders.libsonnet.tmpl", path: "folders/folders.libsonnet", kinds: []string{"DashboardFolder"}},
+	{template: "dashboards.libsonnet.tmpl", path: "dashboards/dashboards.libsonnet", kinds: []string{"Dashboard"}},
+	{template: "datasources.libsonnet.tmpl", path: "datasources/datasources.libsonnet", kinds: []string{"Datasource"}},
+	{template: "alerts.libsonnet.tmpl", path: "alerts/alerts.libsonnet", kinds: []string{"AlertRuleGroup"}},
+	{template: "rules.libsonnet.tmpl", path: "rules/rules.libsonnet", kinds: []string{"PrometheusRuleGroup"}},
+	{template: "checks.libsonnet.tmpl", path: "synthetic-monitoring/checks.libsonnet", kinds: []string{"SyntheticMonitoringCheck"}},
+}
+
+// ScaffoldOptions controls what `grr init` generates.
+type ScaffoldOptions struct {
+	// Kinds selects the kinds for which sample resources are generated.
+	Kinds []string
+	// Context is the name of the context written in the settings template.
+	Context string
+	// Force allows existing files to be overwritten.
+	Force bool
+}
+
+type scaffoldData struct {
+	Kinds     []string
+	Context   string
+	FolderUID string
+}
+
+func (d scaffoldData) Has(kind string) bool {
+	return slices.Contains(d.Kinds, kind)
+}
+
+func (d scaffoldData) HasProvider(provider string) bool {
+	for _, kind := range d.Kinds {
+		if scaffoldKinds[kind] == provider {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportedScaffoldKinds returns the kinds `grr init` knows how to scaffold.
+func SupportedScaffoldKinds() []string {
+	kinds := make([]string, 0, len(scaffoldKinds))
+	for kind := range scaffoldKinds {
+		kinds = append(kinds, kind)
+	}
+	slices.Sort(kinds)
+	return kinds
+}
+
+// Scaffold writes a starter repository layout to the given directory.
+func Scaffold(dir string, opts ScaffoldOptions) error {
+	kinds, err := normaliseScaffoldKinds(opts.Kinds)
+	if err != nil {
+		return err
+	}
+
+	context := opts.Context
+	if context == "" {
+		context = "default"
+	}
+
+	data := scaffoldData{
+		Kinds:     kinds,
+		Context:   context,
+		FolderUID: "sample",
+	}
+
+	// Check for conflicts before writing anything, so that a failed init
+	// doesn't leave a half-scaffolded repository behind.
+	files := make([]scaffoldFile, 0, len(scaffoldFiles))
+	for _, file := range scaffoldFiles {
+		if len(file.kinds) != 0 && !slices.ContainsFunc(file.kinds, data.Has) {
+			continue
+		}
+		if !opts.Force {
+			if _, err := os.Stat(filepath.Join(dir, file.path)); err == nil {
+				return fmt.Errorf("%s already exists, use --force to overwrite it", filepath.Join(dir, file.path))
+			}
+		}
+		files = append(files, file)
+	}
+
+	for _, file := range files {
+		content, err := renderScaffoldTemplate(file.template, data)
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(dir, file.path)
+		if err := WriteFile(path, content); err != nil {
+			return err
+		}
+		notifier.Info(notifier.SimpleString(path), "created")
+	}
+
+	return nil
+}
+
+func renderScaffoldTemplate(name string, data scaffoldData) ([]byte, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").ParseFS(scaffoldFS, "embed/scaffold/"+name)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return nil, fmt.Errorf("rendering %s: %w", name, err)
+	}
+	return out.Bytes(), nil
+}
+
+func normaliseScaffoldKinds(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return DefaultScaffoldKinds, nil
+	}
+
+	kinds := make([]string, 0, len(requested))
+	for _, candidate := range requested {
+		found := false
+		for kind := range scaffoldKinds {
+			if strings.EqualFold(kind, candidate) {
+				if !slices.Contains(kinds, kind) {
+					kinds = append(kinds, kind)
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("kind %s can not be scaffolded. Supported kinds: %s", candidate, strings.Join(SupportedScaffoldKinds(), ", "))
+		}
+	}
+	return kinds, nil
+}
@@ -0,0 +1,45 @@
+package grizzly_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScaffold(t *testing.T) {
+	t.Run("only selected kinds are scaffolded", func(t *testing.T) {
+		dir := t.TempDir()
+
+		err := grizzly.Scaffold(dir, grizzly.ScaffoldOptions{Kinds: []string{"prometheusrulegroup"}})
+		require.NoError(t, err)
+
+		require.FileExists(t, filepath.Join(dir, "main.jsonnet"))
+		require.FileExists(t, filepath.Join(dir, "rules", "rules.libsonnet"))
+		require.NoFileExists(t, filepath.Join(dir, "dashboards", "dashboards.libsonnet"))
+
+		settings, err := os.ReadFile(filepath.Join(dir, "settings.yaml.example"))
+		require.NoError(t, err)
+		require.Contains(t, string(settings), "mimir:")
+		require.NotContains(t, string(settings), "grafana:")
+	})
+
+	t.Run("existing files are not overwritten", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "main.jsonnet"), []byte("{}"), 0644))
+
+		err := grizzly.Scaffold(dir, grizzly.ScaffoldOptions{})
+		require.ErrorContains(t, err, "already exists")
+
+		content, err := os.ReadFile(filepath.Join(dir, "main.jsonnet"))
+		require.NoError(t, err)
+		require.Equal(t, "{}", string(content))
+	})
+
+	t.Run("unknown kinds are rejected", func(t *testing.T) {
+		err := grizzly.Scaffold(t.TempDir(), grizzly.ScaffoldOptions{Kinds: []string{"Nope"}})
+		require.ErrorContains(t, err, "can not be scaffolded")
+	})
+}
@@ -0,0 +1,133 @@
+package grizzly
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Check returns the problems found in a value against the schema: fields of
+// the wrong type, values outside of the enum, and required fields that are
+// missing. Fields the schema doesn't describe aren't checked, nor are null
+// values, as exported resources often hold them. Problems are sorted, each
+// one starting with the path of its field.
+func (s *Schema) Check(path string, value any) []string {
+	var problems []string
+	s.check(path, value, &problems)
+	sort.Strings(problems)
+	return problems
+}
+
+func (s *Schema) check(path string, value any, problems *[]string) {
+	if s == nil || value == nil {
+		return
+	}
+	if s.Type != "" && !slices.ContainsFunc(strings.Split(s.Type, "|"), func(expected string) bool { return hasType(value, expected) }) {
+		*problems = append(*problems, fmt.Sprintf("%s must be of type %s, not %s", displayPath(path), s.Type, typeOf(value)))
+		return
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(allowed any) bool { return reflect.DeepEqual(NormaliseJSON(allowed), NormaliseJSON(value)) }) {
+		*problems = append(*problems, fmt.Sprintf("%s must be one of %v, not %v", displayPath(path), s.Enum, value))
+		return
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		for _, name := range s.Required {
+			if value[name] == nil {
+				*problems = append(*problems, fmt.Sprintf("%s is required", displayPath(joinPath(path, name))))
+			}
+		}
+		for name, field := range value {
+			if schema, ok := s.Properties[name]; ok {
+				schema.check(joinPath(path, name), field, problems)
+			} else if s.AdditionalProperties != nil {
+				s.AdditionalProperties.check(joinPath(path, name), field, problems)
+			}
+		}
+	case []any:
+		for i, item := range value {
+			s.Items.check(joinPath(path, fmt.Sprint(i)), item, problems)
+		}
+	}
+}
+
+// hasType tells whether a value, as decoded from YAML or JSON, is of a type of JSON Schema
+func hasType(value any, expected string) bool {
+	switch expected {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "number":
+		_, ok := toFloat(value)
+		return ok
+	case "integer":
+		number, ok := toFloat(value)
+		return ok && number == math.Trunc(number)
+	default:
+		return true
+	}
+}
+
+func toFloat(value any) (float64, bool) {
+	switch value := reflect.ValueOf(value); value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// typeOf returns the JSON Schema type of a value, for error messages
+func typeOf(value any) string {
+	for _, candidate := range []string{"string", "boolean", "integer", "number", "object", "array"} {
+		if hasType(value, candidate) {
+			return candidate
+		}
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "the resource"
+	}
+	return path
+}
+
+// schemaProblems checks a resource against the schema embedded for its kind,
+// if any
+func schemaProblems(handler Handler, resource Resource) ([]string, error) {
+	describer, ok := handler.(SchemaDescriber)
+	if !ok {
+		return nil, nil
+	}
+	kindSchema, err := describer.Schema()
+	if err != nil {
+		return nil, err
+	}
+	return resourceSchema(handler, kindSchema).Check("", resource.Body), nil
+}
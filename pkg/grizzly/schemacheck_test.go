@@ -0,0 +1,89 @@
+package grizzly_test
+
+import (
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grafana"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/mimir"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaCheck(t *testing.T) {
+	registry := grizzly.NewRegistry([]grizzly.Provider{
+		grafana.NewProvider(&config.GrafanaConfig{}),
+		mimir.NewProvider(&config.MimirConfig{}),
+	})
+	check := func(t *testing.T, resource grizzly.Resource) []string {
+		explanation, err := grizzly.Explain(registry, resource.Kind())
+		require.NoError(t, err)
+		return explanation.Schema.Check("", resource.Body)
+	}
+
+	dashboard, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", grafana.DashboardKind, "api", map[string]any{
+		"title":        "API",
+		"graphTooltip": 1,
+		"tags":         []any{"prod"},
+		"id":           nil,
+		"panels": []any{
+			map[string]any{"type": "timeseries", "gridPos": map[string]any{"h": 8, "w": 12, "x": 0, "y": 0}},
+		},
+	})
+	require.NoError(t, err)
+
+	rules, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", mimir.PrometheusRuleGroupKind, "alerts", map[string]any{
+		"rules": []any{
+			map[string]any{"alert": "Down", "expr": "up == 0", "for": "5m"},
+			map[string]any{"record": "job:up:sum", "expr": "sum by (job) (up)"},
+		},
+	})
+	require.NoError(t, err)
+	rules.SetMetadata("namespace", "infra")
+
+	t.Run("valid resources have no problems", func(t *testing.T) {
+		require.Empty(t, check(t, dashboard))
+		require.Empty(t, check(t, rules))
+	})
+
+	t.Run("fields of the wrong type are reported", func(t *testing.T) {
+		broken := dashboard.DeepCopy()
+		broken.SetSpecValue("title", 42)
+		broken.SetSpecValue("tags", "prod")
+		broken.SetSpecValue("graphTooltip", 1.5)
+		require.Equal(t, []string{
+			"spec.graphTooltip must be of type integer, not number",
+			"spec.tags must be of type array, not string",
+			"spec.title must be of type string, not integer",
+		}, check(t, broken))
+	})
+
+	t.Run("values outside of enums are reported", func(t *testing.T) {
+		broken := dashboard.DeepCopy()
+		broken.SetSpecValue("graphTooltip", 3)
+		require.Equal(t, []string{"spec.graphTooltip must be one of [0 1 2], not 3"}, check(t, broken))
+	})
+
+	t.Run("missing fields are reported, in list items too", func(t *testing.T) {
+		broken := rules.DeepCopy()
+		broken.SetSpecValue("rules", []any{
+			map[string]any{"alert": "Down", "expr": "up == 0"},
+			map[string]any{"record": "job:up:sum"},
+		})
+		delete(broken.Body, "apiVersion")
+		require.Equal(t, []string{
+			"apiVersion is required",
+			"spec.rules.1.expr is required",
+		}, check(t, broken))
+	})
+
+	t.Run("validation reports every invalid resource", func(t *testing.T) {
+		broken, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", mimir.PrometheusRuleGroupKind, "broken", map[string]any{
+			"rules": []any{map[string]any{"alert": "Down"}},
+		})
+		require.NoError(t, err)
+		broken.SetMetadata("namespace", "infra")
+		err = grizzly.Validate(registry, grizzly.NewResources(dashboard, rules, broken), nil, "")
+		require.EqualError(t, err, "found 1 invalid resource")
+	})
+}
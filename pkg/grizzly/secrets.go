@@ -0,0 +1,106 @@
+package grizzly
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
+	log "github.com/sirupsen/logrus"
+)
+
+// SecretSink receives the secrets remote endpoints only return once, when the
+// resources they belong to are created, such as the tokens agents
+// authenticate with
+type SecretSink interface {
+	StoreSecret(ref ResourceRef, name, value string) error
+}
+
+var secretSink SecretSink = stderrSecretSink{}
+
+// ConfigureSecretSink sets where the secrets of created resources are sent.
+// They are shown on stderr when no sink is configured.
+func ConfigureSecretSink(sink SecretSink) {
+	if sink == nil {
+		sink = stderrSecretSink{}
+	}
+	secretSink = sink
+}
+
+// StoreSecret sends a secret of a resource, identified by name, to the
+// configured sink
+func StoreSecret(resource Resource, name, value string) error {
+	if err := secretSink.StoreSecret(resource.Ref(), name, value); err != nil {
+		return fmt.Errorf("storing secret %s of %s: %w", name, resource.Ref(), err)
+	}
+	return nil
+}
+
+// NewSecretSink returns the sink writing secrets to the files of a
+// directory, or the one giving them to a command, whichever is set
+func NewSecretSink(directory, command string) (SecretSink, error) {
+	switch {
+	case directory != "" && command != "":
+		return nil, fmt.Errorf("secrets can be written to a directory or given to a command, not both")
+	case directory != "":
+		return DirectorySecretSink{Directory: directory}, nil
+	case command != "":
+		return CommandSecretSink{Command: command}, nil
+	default:
+		return stderrSecretSink{}, nil
+	}
+}
+
+// stderrSecretSink shows secrets, for them to be copied by hand
+type stderrSecretSink struct{}
+
+func (s stderrSecretSink) StoreSecret(ref ResourceRef, name, value string) error {
+	notifier.InfoStderr(ref, fmt.Sprintf("the secret %s is %s, which isn't shown again", name, value))
+	return nil
+}
+
+// DirectorySecretSink writes each secret to a file of a directory, readable
+// by its owner only. Ex: <directory>/PDCNetwork.on-prem.dc1-agent
+type DirectorySecretSink struct {
+	Directory string
+}
+
+func (s DirectorySecretSink) StoreSecret(ref ResourceRef, name, value string) error {
+	if err := os.MkdirAll(s.Directory, 0700); err != nil {
+		return err
+	}
+	filename := unsafeFileCharacters.ReplaceAllString(ref.Kind+"."+ref.Name+"."+name, "-")
+	path := filepath.Join(s.Directory, filename)
+	if err := os.WriteFile(path, []byte(value), 0600); err != nil {
+		return err
+	}
+	notifier.InfoStderr(ref, fmt.Sprintf("the secret %s was written to %s", name, path))
+	return nil
+}
+
+// CommandSecretSink gives each secret to a shell command on its standard
+// input, such as one storing it in a vault. The command is told which secret
+// it receives with the GRR_SECRET_KIND, GRR_SECRET_RESOURCE and
+// GRR_SECRET_NAME environment variables.
+type CommandSecretSink struct {
+	Command string
+}
+
+func (s CommandSecretSink) StoreSecret(ref ResourceRef, name, value string) error {
+	log.Debugf("Storing secret %s of %s with %s", name, ref, s.Command)
+	cmd := exec.Command("sh", "-c", s.Command)
+	cmd.Env = append(os.Environ(),
+		"GRR_SECRET_KIND="+ref.Kind,
+		"GRR_SECRET_RESOURCE="+ref.Name,
+		"GRR_SECRET_NAME="+name,
+	)
+	cmd.Stdin = bytes.NewBufferString(value)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("running secret sink command: %w", err)
+	}
+	return nil
+}
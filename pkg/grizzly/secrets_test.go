@@ -0,0 +1,50 @@
+package grizzly
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretSinks(t *testing.T) {
+	ref := NewResourceRef("PDCNetwork", "on-prem")
+
+	t.Run("secrets are written to files readable by their owner", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "secrets")
+		sink, err := NewSecretSink(dir, "")
+		require.NoError(t, err)
+		require.NoError(t, sink.StoreSecret(ref, "dc1-agent", "glc_secret"))
+
+		path := filepath.Join(dir, "PDCNetwork.on-prem.dc1-agent")
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, "glc_secret", string(content))
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("secrets are given to commands on their standard input", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "out")
+		sink, err := NewSecretSink("", `{ echo "$GRR_SECRET_KIND $GRR_SECRET_RESOURCE $GRR_SECRET_NAME"; cat; } > `+out)
+		require.NoError(t, err)
+		require.NoError(t, sink.StoreSecret(ref, "dc1-agent", "glc_secret"))
+
+		content, err := os.ReadFile(out)
+		require.NoError(t, err)
+		require.Equal(t, "PDCNetwork on-prem dc1-agent\nglc_secret", string(content))
+	})
+
+	t.Run("failing commands fail", func(t *testing.T) {
+		sink, err := NewSecretSink("", "exit 3")
+		require.NoError(t, err)
+		require.Error(t, sink.StoreSecret(ref, "dc1-agent", "glc_secret"))
+	})
+
+	t.Run("a directory and a command can't both be used", func(t *testing.T) {
+		_, err := NewSecretSink("secrets", "cat")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,89 @@
+package grizzly
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+// Selection narrows the resources a command works on to those of a kind, and
+// to those matching a selector
+type Selection struct {
+	// Kind, when set, is the only kind of resources selected
+	Kind string
+	// Selector is matched as by SelectorMatches, against the labels of
+	// resources first: team=payments selects the resources labelled so
+	Selector []Matcher
+}
+
+// NewSelection selects the resources of a kind, if given, matching every
+// matcher of a selector, such as team=payments
+func NewSelection(kind string, selector []string) (Selection, error) {
+	selection := Selection{Kind: kind}
+	for _, matcher := range selector {
+		parsed, err := ParseMatcher(matcher)
+		if err != nil {
+			return Selection{}, err
+		}
+		selection.Selector = append(selection.Selector, parsed)
+	}
+	return selection, nil
+}
+
+// Targets narrows targets to the kind of the selection, so that resources of
+// other kinds are neither parsed, pulled nor pruned
+func (s Selection) Targets(targets []string) ([]string, error) {
+	if s.Kind == "" {
+		return targets, nil
+	}
+	if len(targets) == 0 {
+		return []string{s.Kind}, nil
+	}
+
+	var narrowed []string
+	for _, target := range targets {
+		separator := strings.IndexAny(target, "/.")
+		if separator < 0 {
+			if strings.EqualFold(target, s.Kind) {
+				narrowed = append(narrowed, s.Kind)
+			}
+			continue
+		}
+		kindGlob, err := glob.Compile(target[:separator])
+		if err != nil {
+			return nil, fmt.Errorf("invalid target %s: %w", target, err)
+		}
+		if kindGlob.Match(s.Kind) {
+			narrowed = append(narrowed, s.Kind+target[separator:])
+		}
+	}
+	if len(narrowed) == 0 {
+		return nil, fmt.Errorf("none of the targets %s are of kind %s", strings.Join(targets, ", "), s.Kind)
+	}
+	return narrowed, nil
+}
+
+// Matches returns whether a resource matches the selector of the selection
+func (s Selection) Matches(registry Registry, resource Resource) bool {
+	if len(s.Selector) == 0 {
+		return true
+	}
+	var tags []string
+	if handler, err := registry.GetHandler(resource.Kind()); err == nil {
+		if tagHandler, ok := handler.(TagHandler); ok {
+			tags = tagHandler.Tags(resource)
+		}
+	}
+	return SelectorMatches(resource, tags, s.Selector)
+}
+
+// Filter keeps the resources matching the selector of the selection
+func (s Selection) Filter(registry Registry, resources Resources) Resources {
+	if len(s.Selector) == 0 {
+		return resources
+	}
+	return resources.Filter(func(resource Resource) bool {
+		return s.Matches(registry, resource)
+	})
+}
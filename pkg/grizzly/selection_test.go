@@ -0,0 +1,52 @@
+package grizzly_test
+
+import (
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grafana"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelection(t *testing.T) {
+	t.Run("narrowing targets to a kind", func(t *testing.T) {
+		selection, err := grizzly.NewSelection(grafana.DashboardKind, nil)
+		require.NoError(t, err)
+
+		targets, err := selection.Targets(nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"Dashboard"}, targets)
+
+		targets, err = selection.Targets([]string{"dashboard", "*/api", "Dashboard.web", "Datasource/*"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"Dashboard", "Dashboard/api", "Dashboard.web"}, targets)
+
+		_, err = selection.Targets([]string{"Datasource/*"})
+		require.ErrorContains(t, err, "none of the targets Datasource/* are of kind Dashboard")
+	})
+
+	t.Run("selecting resources by label", func(t *testing.T) {
+		registry := grizzly.NewRegistry([]grizzly.Provider{grafana.NewProvider(&config.GrafanaConfig{})})
+		newDashboard := func(uid string, labels map[string]any) grizzly.Resource {
+			dashboard, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", grafana.DashboardKind, uid, map[string]any{"uid": uid, "title": uid, "tags": []any{"prod"}})
+			require.NoError(t, err)
+			dashboard.Body["metadata"].(map[string]any)["labels"] = labels
+			return dashboard
+		}
+		resources := grizzly.NewResources(
+			newDashboard("payments", map[string]any{"team": "payments", "tier": "critical"}),
+			newDashboard("checkout", map[string]any{"team": "payments"}),
+			newDashboard("search", map[string]any{"team": "search"}),
+		)
+
+		selection, err := grizzly.NewSelection("", []string{"team=payments", "tier!=critical", "tag=prod"})
+		require.NoError(t, err)
+		selected := selection.Filter(registry, resources)
+		require.Equal(t, 1, selected.Len())
+		require.Equal(t, "checkout", selected.AsList()[0].Name())
+
+		_, err = grizzly.NewSelection("", []string{"team"})
+		require.ErrorContains(t, err, "invalid matcher")
+	})
+}
@@ -9,7 +9,9 @@ import (
 	"net/http/httputil"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
@@ -40,6 +42,14 @@ type Server struct {
 	OnlySpec       bool
 	OutputFormat   string
 	watch          bool
+	remoteInterval time.Duration
+	remote         *remoteTracker
+
+	// DatasourceMocks is the file of responses answering datasource queries
+	// in place of datasources, when set
+	DatasourceMocks string
+	// RecordDatasources records the responses of datasources to DatasourceMocks
+	RecordDatasources bool
 }
 
 var upgrader = &websocket.Upgrader{
@@ -70,6 +80,7 @@ func NewGrizzlyServer(registry Registry, resourcePath string, port int) (*Server
 		ResourcePath: resourcePath,
 		port:         port,
 		proxy:        proxy,
+		remote:       newRemoteTracker(),
 	}, nil
 }
 
@@ -95,6 +106,20 @@ func (s *Server) WatchScript(script string) {
 	s.watchScript = script
 }
 
+// WatchRemote checks the remote version of the displayed resources at the
+// given interval, to warn when they are changed remotely. Zero disables it.
+func (s *Server) WatchRemote(interval time.Duration) {
+	s.remoteInterval = interval
+}
+
+// MockDatasources answers datasource queries with the responses found in the
+// given file, so that resources can be previewed offline. With record, queries
+// are proxied to datasources, and their responses recorded in the file.
+func (s *Server) MockDatasources(file string, record bool) {
+	s.DatasourceMocks = file
+	s.RecordDatasources = record
+}
+
 func (s *Server) SetFormatting(onlySpec bool, outputFormat string) {
 	s.OnlySpec = onlySpec
 	s.OutputFormat = outputFormat
@@ -157,10 +182,12 @@ func (s *Server) applyStaticProxyConfig(r chi.Router, config StaticProxyConfig)
 	}
 }
 
-func (s *Server) Start() error {
+// Router returns the HTTP handler serving the Grizzly server's pages and
+// proxying Grafana.
+func (s *Server) Router() (http.Handler, error) {
 	assetsFS, err := fs.Sub(embedFS, "embed/assets")
 	if err != nil {
-		return fmt.Errorf("could not create a sub-tree from the embedded assets FS: %w", err)
+		return nil, fmt.Errorf("could not create a sub-tree from the embedded assets FS: %w", err)
 	}
 
 	r := chi.NewRouter()
@@ -191,7 +218,7 @@ func (s *Server) Start() error {
 			case http.MethodPost:
 				r.Post(endpoint.URL, endpoint.Handler)
 			default:
-				return fmt.Errorf("unknown endpoint method %s for handler %s", endpoint.Method, handler.Kind())
+				return nil, fmt.Errorf("unknown endpoint method %s for handler %s", endpoint.Method, handler.Kind())
 			}
 		}
 
@@ -200,8 +227,19 @@ func (s *Server) Start() error {
 
 	r.Get("/", s.rootHandler)
 	r.Get("/grizzly/{kind}/{name}", s.iframeHandler)
+	r.Get("/grizzly/{kind}/{name}/remote", s.remoteStatusHandler)
+	r.Get("/grizzly/{kind}/{name}/diff", s.remoteDiffHandler)
 	r.Get("/livereload", livereload.Handler(upgrader))
 
+	return r, nil
+}
+
+func (s *Server) Start() error {
+	r, err := s.Router()
+	if err != nil {
+		return err
+	}
+
 	if s.watchScript != "" {
 		var b []byte
 		b, err = s.executeWatchScript()
@@ -312,6 +350,7 @@ func (s *Server) updateWatchedResource(name string) error {
 	}
 
 	for _, resource := range resources.AsList() {
+		s.remote.forget(resource.Ref())
 		handler, err := s.Registry.GetHandler(resource.Kind())
 		if err != nil {
 			log.Warnf("[watcher] Error: %s", err)
@@ -378,6 +417,8 @@ func (s *Server) iframeHandler(w http.ResponseWriter, r *http.Request) {
 		"Port":           s.port,
 		"IframeURL":      proxyConfig.ProxyURL(name),
 		"CurrentContext": s.CurrentContext,
+		"RemoteURL":      fmt.Sprintf("/grizzly/%s/%s/remote", kind, name),
+		"RemoteInterval": s.remoteInterval.Milliseconds(),
 	}
 
 	if err := templates.ExecuteTemplate(w, "proxy/iframe.html.tmpl", templateVars); err != nil {
@@ -410,11 +451,6 @@ func (s *Server) rootHandler(w http.ResponseWriter, _ *http.Request) {
 }
 
 func (s *Server) UpdateResource(resource Resource) error {
-	out, _, _, err := Format(s.Registry, s.ResourcePath, &resource, resource.Source.Format, !resource.Source.WithEnvelope)
-	if err != nil {
-		return fmt.Errorf("error formatting content: %s", err)
-	}
-
 	existing, found := s.Resources.Find(resource.Ref())
 	if !found {
 		return fmt.Errorf("%s not found", resource.Ref())
@@ -424,5 +460,14 @@ func (s *Server) UpdateResource(resource Resource) error {
 		return fmt.Errorf("the source for this %s is not rewritable", resource.Kind())
 	}
 
+	resource, err := SplitFileReferences(resource, existing.Source.FileReferences, filepath.Dir(existing.Source.Path))
+	if err != nil {
+		return err
+	}
+	out, _, _, err := Format(s.Registry, s.ResourcePath, &resource, resource.Source.Format, !resource.Source.WithEnvelope)
+	if err != nil {
+		return fmt.Errorf("error formatting content: %s", err)
+	}
+
 	return WriteFile(existing.Source.Path, out)
 }
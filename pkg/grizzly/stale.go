@@ -0,0 +1,183 @@
+package grizzly
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// What the last use of a resource is based on
+const (
+	// UsageViewed means resources were last used when they were last viewed
+	UsageViewed = "viewed"
+	// UsageUpdated means usage isn't tracked, and resources were last used when they were last updated
+	UsageUpdated = "updated"
+)
+
+// formatCandidates lists the keys of stale resources only, as candidates for deletion
+const formatCandidates = "candidates"
+
+// UsageHandler describes a handler that knows when remote resources were last
+// used, to find those nobody relies on anymore
+type UsageHandler interface {
+	// LastUsed returns the usage of the remote resources with the given UIDs.
+	// Resources that don't exist remotely are left out.
+	LastUsed(UIDs []string) (map[string]ResourceUsage, error)
+}
+
+// ResourceUsage describes when a remote resource was last used
+type ResourceUsage struct {
+	// LastUsed is zero if the resource was never used
+	LastUsed time.Time
+	// Basis is what LastUsed is based on, UsageViewed or UsageUpdated
+	Basis string
+}
+
+// StaleOptions controls which resources are stale, and what is done with them
+type StaleOptions struct {
+	// MaxAge is the duration after which resources that weren't used are stale
+	MaxAge time.Duration
+	// ArchiveFolder is the UID of the folder stale resources are moved to. Empty means they aren't moved
+	ArchiveFolder string
+}
+
+// StaleResource describes a managed resource that wasn't used recently
+type StaleResource struct {
+	Resource string     `yaml:"resource" json:"resource"`
+	LastUsed *time.Time `yaml:"lastUsed,omitempty" json:"lastUsed,omitempty"`
+	Basis    string     `yaml:"basis" json:"basis"`
+	Archived bool       `yaml:"archived,omitempty" json:"archived,omitempty"`
+}
+
+// Stale finds the resources that weren't used remotely for longer than
+// opts.MaxAge, and moves them to opts.ArchiveFolder if set. Their source is
+// updated too, so that applying them doesn't move them back. Progress is
+// reported on stderr, so that the stale resources can be piped to other commands.
+func Stale(registry Registry, resources Resources, opts StaleOptions) ([]StaleResource, error) {
+	if opts.MaxAge <= 0 {
+		return nil, fmt.Errorf("the age after which resources are stale must be positive")
+	}
+	cutoff := time.Now().Add(-opts.MaxAge)
+
+	byKind := map[string][]Resource{}
+	for _, resource := range resources.AsList() {
+		byKind[resource.Kind()] = append(byKind[resource.Kind()], resource)
+	}
+	kinds := make([]string, 0, len(byKind))
+	for kind := range byKind {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	stale := []StaleResource{}
+	for _, kind := range kinds {
+		handler, err := registry.GetHandler(kind)
+		if err != nil {
+			return stale, err
+		}
+		usageHandler, ok := handler.(UsageHandler)
+		if !ok {
+			notifier.InfoStderr(notifier.SimpleString(kind), "does not support stale, skipped")
+			continue
+		}
+
+		UIDs := make([]string, 0, len(byKind[kind]))
+		for _, resource := range byKind[kind] {
+			UIDs = append(UIDs, resource.Name())
+		}
+		usages, err := usageHandler.LastUsed(UIDs)
+		if err != nil {
+			return stale, ClassifyError(handler, err)
+		}
+
+		for _, resource := range byKind[kind] {
+			usage, found := usages[resource.Name()]
+			if !found {
+				notifier.InfoStderr(resource, "not found remotely")
+				continue
+			}
+			if usage.LastUsed.After(cutoff) {
+				continue
+			}
+
+			entry := StaleResource{Resource: resource.Ref().String(), Basis: usage.Basis}
+			if !usage.LastUsed.IsZero() {
+				entry.LastUsed = &usage.LastUsed
+			}
+			if opts.ArchiveFolder != "" {
+				if err := archiveResource(registry, handler, resource, opts.ArchiveFolder); err != nil {
+					return stale, err
+				}
+				entry.Archived = true
+			}
+			stale = append(stale, entry)
+		}
+	}
+
+	return stale, nil
+}
+
+// archiveResource moves a remote resource to the archive folder, and updates its source accordingly
+func archiveResource(registry Registry, handler Handler, resource Resource, folderUID string) error {
+	if resource.GetMetadata("folder") == folderUID {
+		return nil
+	}
+
+	remote, err := handler.GetRemote(resource)
+	if err != nil {
+		return ClassifyError(handler, err)
+	}
+	archived := handler.Unprepare(*remote)
+	archived.SetMetadata("folder", folderUID)
+	if err := handler.Update(*remote, *handler.Prepare(remote, *archived)); err != nil {
+		return ClassifyError(handler, err)
+	}
+	notifier.InfoStderr(resource, fmt.Sprintf("moved to folder %s", folderUID))
+
+	resource.SetMetadata("folder", folderUID)
+	if err := rewriteResource(registry, resource, resource); err != nil {
+		log.Warnf("%s: %s, set its folder to %s so that it isn't moved back when applied", resource.Ref(), err, folderUID)
+	}
+	return nil
+}
+
+// FormatStale renders stale resources in the given format: default, candidates, json or yaml
+func FormatStale(stale []StaleResource, format string) ([]byte, error) {
+	switch format {
+	case formatYAML:
+		return yaml.Marshal(stale)
+	case formatJSON:
+		return json.MarshalIndent(stale, "", "  ")
+	case formatCandidates:
+		var out strings.Builder
+		for _, resource := range stale {
+			fmt.Fprintln(&out, resource.Resource)
+		}
+		return []byte(out.String()), nil
+	case formatDefault:
+		var out bytes.Buffer
+		w := tabwriter.NewWriter(&out, 0, 0, 4, ' ', 0)
+
+		f := "%s\t%s\t%s\n"
+		fmt.Fprintf(w, f, "RESOURCE", "LAST USED", "BASED ON")
+		for _, resource := range stale {
+			lastUsed := "never"
+			if resource.LastUsed != nil {
+				lastUsed = resource.LastUsed.Format(time.DateOnly)
+			}
+			fmt.Fprintf(w, f, resource.Resource, lastUsed, resource.Basis)
+		}
+		err := w.Flush()
+		return out.Bytes(), err
+	default:
+		return nil, fmt.Errorf("unknown format %s", format)
+	}
+}
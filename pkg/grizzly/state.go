@@ -0,0 +1,188 @@
+package grizzly
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
+	"github.com/hashicorp/go-multierror"
+	log "github.com/sirupsen/logrus"
+)
+
+// StateSnapshot is a point-in-time capture of the remote resources of a
+// context, against which local resources can be diffed offline
+type StateSnapshot struct {
+	Context    string    `json:"context"`
+	CapturedAt time.Time `json:"capturedAt"`
+	// Resources are stored as retrieved from remote endpoints
+	Resources []map[string]any `json:"resources"`
+}
+
+// SnapshotOptions controls which remote resources are captured
+type SnapshotOptions struct {
+	Targets []string
+	// ContinueOnError captures the remaining resources when one can't be retrieved
+	ContinueOnError bool
+}
+
+// SnapshotState captures the remote resources of every handler matching the targets
+func SnapshotState(registry Registry, contextName string, opts SnapshotOptions) (StateSnapshot, error) {
+	snapshot := StateSnapshot{
+		Context:    contextName,
+		CapturedAt: time.Now().UTC(),
+		Resources:  []map[string]any{},
+	}
+
+	names := make([]string, 0, len(registry.Handlers))
+	for name := range registry.Handlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var finalErr error
+	for _, name := range names {
+		handler := registry.Handlers[name]
+		if !registry.HandlerMatchesTarget(handler, opts.Targets) {
+			continue
+		}
+
+		log.Debugf("Listing remote values for handler %s", name)
+		UIDs, err := handler.ListRemote()
+		if err != nil {
+			finalErr = multierror.Append(finalErr, classifyListError(handler, err))
+			if opts.ContinueOnError {
+				continue
+			}
+			return snapshot, finalErr
+		}
+
+		matchingUIDs := make([]string, 0, len(UIDs))
+		for _, UID := range UIDs {
+			if registry.ResourceMatchesTarget(handler.Kind(), UID, opts.Targets) {
+				matchingUIDs = append(matchingUIDs, UID)
+			}
+		}
+		sort.Strings(matchingUIDs)
+
+		for _, result := range getByUIDs(registry, handler, matchingUIDs) {
+			if result.Err != nil {
+				finalErr = multierror.Append(finalErr, ClassifyError(handler, fmt.Errorf("%s: %w", NewResourceRef(handler.Kind(), result.UID), result.Err)))
+				if opts.ContinueOnError {
+					continue
+				}
+				return snapshot, finalErr
+			}
+			snapshot.Resources = append(snapshot.Resources, result.Resource.Body)
+		}
+		notifier.Info(notifier.SimpleString(handler.Kind()), fmt.Sprintf("%s captured", Pluraliser(len(matchingUIDs), "resource")))
+	}
+
+	return snapshot, finalErr
+}
+
+// WriteStateSnapshot writes a snapshot to a JSON file
+func WriteStateSnapshot(file string, snapshot StateSnapshot) error {
+	content, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFile(file, append(content, '\n'))
+}
+
+// ReadStateSnapshot reads a snapshot written by WriteStateSnapshot
+func ReadStateSnapshot(file string) (*StateSnapshot, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot StateSnapshot
+	if err := json.Unmarshal(content, &snapshot); err != nil {
+		return nil, fmt.Errorf("reading state snapshot %s: %w", file, err)
+	}
+	return &snapshot, nil
+}
+
+// Find returns the captured remote equivalent of a resource
+func (s *StateSnapshot) Find(ref ResourceRef) (*Resource, error) {
+	resources, err := s.resources()
+	if err != nil {
+		return nil, err
+	}
+	if resource, found := resources.Find(ref); found {
+		copied := resource.DeepCopy()
+		return &copied, nil
+	}
+	return nil, fmt.Errorf("%s isn't part of the snapshot: %w", ref, ErrNotFound)
+}
+
+// DiffSnapshots reports the changes made to remote resources between two
+// snapshots: resources added, deleted, or changed, whoever changed them
+func DiffSnapshots(registry Registry, before, after *StateSnapshot, targets []string, onlySpec bool, outputFormat string) error {
+	beforeResources, err := before.resources()
+	if err != nil {
+		return err
+	}
+	afterResources, err := after.resources()
+	if err != nil {
+		return err
+	}
+
+	refs := map[string]ResourceRef{}
+	for _, resources := range []Resources{beforeResources, afterResources} {
+		for _, resource := range resources.AsList() {
+			if !registry.ResourceMatchesTarget(resource.Kind(), resource.Name(), targets) {
+				continue
+			}
+			refs[resource.Ref().String()] = resource.Ref()
+		}
+	}
+	keys := make([]string, 0, len(refs))
+	for key := range refs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fromFile := before.CapturedAt.Format(time.RFC3339)
+	toFile := after.CapturedAt.Format(time.RFC3339)
+	for _, key := range keys {
+		ref := refs[key]
+		handler, err := registry.GetHandler(ref.Kind)
+		if err != nil {
+			return err
+		}
+
+		previous, existed := beforeResources.Find(ref)
+		current, exists := afterResources.Find(ref)
+		switch {
+		case !existed:
+			notifier.Added(ref)
+		case !exists:
+			notifier.Warn(ref, "deleted")
+		default:
+			previous = *handler.Unprepare(previous.DeepCopy())
+			current = *handler.Unprepare(current.DeepCopy())
+			if err := reportChanges(registry, handler, previous, current, fromFile, toFile, onlySpec, outputFormat); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resources returns the resources of a snapshot
+func (s *StateSnapshot) resources() (Resources, error) {
+	resources := NewResources()
+	for _, body := range s.Resources {
+		kind, _ := body["kind"].(string)
+		metadata, _ := body["metadata"].(map[string]any)
+		name, _ := metadata["name"].(string)
+		if kind == "" || name == "" {
+			return resources, fmt.Errorf("invalid resource in snapshot of %s: missing kind or name", s.Context)
+		}
+		resources.Add(Resource{Body: body})
+	}
+	return resources, nil
+}
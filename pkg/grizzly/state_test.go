@@ -0,0 +1,65 @@
+package grizzly_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grafana"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateSnapshot(t *testing.T) {
+	dashboard, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", grafana.DashboardKind, "api", map[string]any{"uid": "api", "title": "API"})
+	require.NoError(t, err)
+	dashboard.SetMetadata("folder", "general")
+
+	file := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, grizzly.WriteStateSnapshot(file, grizzly.StateSnapshot{
+		Context:    "prod",
+		CapturedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		Resources:  []map[string]any{dashboard.Body},
+	}))
+
+	snapshot, err := grizzly.ReadStateSnapshot(file)
+	require.NoError(t, err)
+	require.Equal(t, "prod", snapshot.Context)
+
+	captured, err := snapshot.Find(dashboard.Ref())
+	require.NoError(t, err)
+	require.Equal(t, "API", captured.GetSpecValue("title"))
+
+	_, err = snapshot.Find(grizzly.NewResourceRef(grafana.DashboardKind, "missing"))
+	require.ErrorIs(t, err, grizzly.ErrNotFound)
+
+	t.Run("diffing against a snapshot doesn't call remote endpoints", func(t *testing.T) {
+		registry := grizzly.NewRegistry([]grizzly.Provider{grafana.NewProvider(&config.GrafanaConfig{URL: "http://localhost:0"})})
+		changed := dashboard.DeepCopy()
+		changed.SetSpecValue("title", "Renamed")
+		missing, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", grafana.DashboardKind, "missing", map[string]any{"uid": "missing"})
+		require.NoError(t, err)
+
+		require.NoError(t, grizzly.DiffAgainst(registry, grizzly.NewResources(changed, missing), snapshot, false, "yaml"))
+	})
+
+	t.Run("diffing two snapshots", func(t *testing.T) {
+		registry := grizzly.NewRegistry([]grizzly.Provider{grafana.NewProvider(&config.GrafanaConfig{})})
+		changed := dashboard.DeepCopy()
+		changed.SetSpecValue("title", "Renamed")
+		added, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", grafana.DashboardFolderKind, "team-a", map[string]any{"uid": "team-a", "title": "Team A"})
+		require.NoError(t, err)
+		after := &grizzly.StateSnapshot{
+			Context:    "prod",
+			CapturedAt: time.Date(2026, 10, 2, 12, 0, 0, 0, time.UTC),
+			Resources:  []map[string]any{changed.Body, added.Body},
+		}
+
+		require.NoError(t, grizzly.DiffSnapshots(registry, snapshot, after, nil, false, "yaml"))
+		require.NoError(t, grizzly.DiffSnapshots(registry, snapshot, after, []string{"Dashboard/*"}, false, "yaml"))
+
+		after.Resources = append(after.Resources, map[string]any{"spec": map[string]any{}})
+		require.ErrorContains(t, grizzly.DiffSnapshots(registry, snapshot, after, nil, false, "yaml"), "missing kind or name")
+	})
+}
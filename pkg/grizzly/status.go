@@ -0,0 +1,226 @@
+package grizzly
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLastApplyFile is where the last apply to each context is recorded,
+// relative to the working directory
+const DefaultLastApplyFile = ".grizzly/last-apply.json"
+
+// LastApply describes the last apply to a context
+type LastApply struct {
+	At           time.Time `json:"at" yaml:"at"`
+	ResourcePath string    `json:"resourcePath" yaml:"resourcePath"`
+	// Commit is the git commit the resources were applied from, suffixed
+	// with -dirty when they had uncommitted changes
+	Commit  string `json:"commit,omitempty" yaml:"commit,omitempty"`
+	Summary string `json:"summary" yaml:"summary"`
+	Failed  bool   `json:"failed,omitempty" yaml:"failed,omitempty"`
+}
+
+// RecordLastApply records the last apply to a context
+func RecordLastApply(path, context string, apply LastApply) error {
+	return writeContextState(path, context, apply)
+}
+
+// ReadLastApply returns the last apply recorded for a context, if any
+func ReadLastApply(path, context string) (*LastApply, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state := map[string]*LastApply{}
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return state[context], nil
+}
+
+// WorkspaceStatus summarizes how the resources of a resource path compare
+// with those of a context
+type WorkspaceStatus struct {
+	Context      string `json:"context" yaml:"context"`
+	ResourcePath string `json:"resourcePath" yaml:"resourcePath"`
+	// Local counts the resources defined locally
+	Local int `json:"local" yaml:"local"`
+	// InSync, Drifted and Missing count the local resources unchanged
+	// remotely, changed remotely, and missing remotely
+	InSync  int `json:"inSync" yaml:"inSync"`
+	Drifted int `json:"drifted" yaml:"drifted"`
+	Missing int `json:"missing" yaml:"missing"`
+	// Unmanaged are the remote resources, of the kinds defined locally, that
+	// aren't defined locally
+	Unmanaged []string `json:"unmanaged" yaml:"unmanaged"`
+	// LastApply is the last apply to the context, when recorded
+	LastApply *LastApply `json:"lastApply,omitempty" yaml:"lastApply,omitempty"`
+	// GitChanges are the uncommitted changes to the resource path, as
+	// reported by git status --porcelain. Nil outside of git repositories.
+	GitChanges []string `json:"gitChanges" yaml:"gitChanges"`
+}
+
+// StatusOptions controls what Status looks at
+type StatusOptions struct {
+	Context      string
+	ResourcePath string
+	Targets      []string
+	// LastApplyFile is where the last applies were recorded
+	LastApplyFile string
+}
+
+// Status compares local resources with their remote versions, lists the
+// remote resources in scope that aren't defined locally, and reports the last
+// apply to the context and the uncommitted changes to the resource path, as
+// an at-a-glance view of the health of a repository
+func Status(registry Registry, resources Resources, opts StatusOptions) (WorkspaceStatus, error) {
+	status := WorkspaceStatus{
+		Context:      opts.Context,
+		ResourcePath: opts.ResourcePath,
+		Local:        resources.Len(),
+		Unmanaged:    []string{},
+	}
+
+	diffs, err := DiffReport(registry, resources, DiffReportOptions{OutputFormat: formatYAML})
+	if err != nil {
+		return status, err
+	}
+	counts := SummarizeDiff(diffs)
+	status.InSync, status.Drifted, status.Missing = counts.Unchanged, counts.Changed, counts.Added
+
+	if status.Unmanaged, err = unmanagedResources(registry, resources, opts.Targets); err != nil {
+		return status, err
+	}
+
+	if status.LastApply, err = ReadLastApply(opts.LastApplyFile, opts.Context); err != nil {
+		return status, err
+	}
+
+	dir, pathspec := opts.ResourcePath, "."
+	if info, err := os.Stat(opts.ResourcePath); err == nil && !info.IsDir() {
+		dir, pathspec = filepath.Dir(opts.ResourcePath), filepath.Base(opts.ResourcePath)
+	}
+	if changes, err := git(dir, "status", "--porcelain", "--", pathspec); err == nil {
+		status.GitChanges = []string{}
+		for _, line := range strings.Split(changes, "\n") {
+			if line != "" {
+				status.GitChanges = append(status.GitChanges, line)
+			}
+		}
+	}
+	return status, nil
+}
+
+// unmanagedResources lists the remote resources of the kinds of the local
+// resources, and matching targets, that aren't defined locally
+func unmanagedResources(registry Registry, resources Resources, targets []string) ([]string, error) {
+	local := map[string]map[string]bool{}
+	for _, resource := range resources.AsList() {
+		handler, err := registry.GetHandler(resource.Kind())
+		if err != nil {
+			return nil, err
+		}
+		uid, err := handler.GetUID(resource)
+		if err != nil {
+			uid = resource.Name()
+		}
+		if local[handler.Kind()] == nil {
+			local[handler.Kind()] = map[string]bool{}
+		}
+		local[handler.Kind()][uid] = true
+	}
+
+	unmanaged := []string{}
+	for kind, uids := range local {
+		handler, err := registry.GetHandler(kind)
+		if err != nil {
+			return nil, err
+		}
+		remoteUIDs, err := handler.ListRemote()
+		if err != nil {
+			return nil, classifyListError(handler, err)
+		}
+		for _, uid := range remoteUIDs {
+			if !uids[uid] && registry.ResourceMatchesTarget(kind, uid, targets) {
+				unmanaged = append(unmanaged, NewResourceRef(kind, uid).String())
+			}
+		}
+	}
+	sort.Strings(unmanaged)
+	return unmanaged, nil
+}
+
+// FormatStatus renders the status of a workspace in the given format:
+// default, json or yaml
+func FormatStatus(status WorkspaceStatus, format string) ([]byte, error) {
+	switch format {
+	case formatYAML:
+		return yaml.Marshal(status)
+	case formatJSON:
+		return json.MarshalIndent(status, "", "  ")
+	case formatDefault:
+		var out bytes.Buffer
+		w := tabwriter.NewWriter(&out, 0, 0, 2, ' ', 0)
+
+		fmt.Fprintf(w, "Context:\t%s\n", status.Context)
+		fmt.Fprintf(w, "Resources:\t%d defined in %s\n", status.Local, status.ResourcePath)
+		fmt.Fprintf(w, "In sync:\t%d\n", status.InSync)
+		fmt.Fprintf(w, "Drifted:\t%d\n", status.Drifted)
+		fmt.Fprintf(w, "Missing remotely:\t%d\n", status.Missing)
+		fmt.Fprintf(w, "Unmanaged remotely:\t%d\n", len(status.Unmanaged))
+
+		lastApply := "never recorded"
+		if apply := status.LastApply; apply != nil {
+			lastApply = fmt.Sprintf("%s from %s", apply.At.Local().Format(time.DateTime), apply.ResourcePath)
+			if apply.Commit != "" {
+				lastApply += fmt.Sprintf(" at %s", apply.Commit)
+			}
+			lastApply += ": " + apply.Summary
+			if apply.Failed {
+				lastApply += " (failed)"
+			}
+		}
+		fmt.Fprintf(w, "Last apply:\t%s\n", lastApply)
+
+		switch {
+		case status.GitChanges == nil:
+			fmt.Fprintf(w, "Git changes:\tnot a git repository\n")
+		case len(status.GitChanges) == 0:
+			fmt.Fprintf(w, "Git changes:\tnone\n")
+		default:
+			fmt.Fprintf(w, "Git changes:\t%s\n", Pluraliser(len(status.GitChanges), "uncommitted file"))
+		}
+		if err := w.Flush(); err != nil {
+			return nil, err
+		}
+
+		if len(status.Unmanaged) > 0 {
+			fmt.Fprintf(&out, "\nUnmanaged remotely:\n")
+			for _, ref := range status.Unmanaged {
+				fmt.Fprintf(&out, "  %s\n", ref)
+			}
+		}
+		if len(status.GitChanges) > 0 {
+			fmt.Fprintf(&out, "\nGit changes:\n")
+			for _, change := range status.GitChanges {
+				fmt.Fprintf(&out, "  %s\n", change)
+			}
+		}
+		return out.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unknown format %s", format)
+	}
+}
@@ -0,0 +1,155 @@
+package grizzly
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
+	"github.com/hashicorp/go-multierror"
+)
+
+// TagHandler describes a handler whose resources can be tagged, such as dashboards
+type TagHandler interface {
+	// Tags returns the tags of a resource
+	Tags(resource Resource) []string
+	// SetTags replaces the tags of a resource
+	SetTags(resource Resource, tags []string) Resource
+}
+
+// TagOptions describes the tags to add to, or remove from, the resources
+// matching a selector
+type TagOptions struct {
+	Add    []string
+	Remove []string
+	// Selector selects the resources to retag. Resources are matched on their
+	// labels, kind, name, metadata (ex: folder), top-level spec strings (ex:
+	// title), and on their tags: tag=team-a matches resources tagged team-a.
+	Selector []Matcher
+	// LocalOnly, when set, only rewrites the resource files
+	LocalOnly bool
+}
+
+// UpdateTags adds and removes tags on the resources matching a selector: the
+// files they were read from are rewritten, and their remote versions are
+// updated in place, so that changes to other fields aren't pushed along.
+func UpdateTags(registry Registry, resources Resources, opts TagOptions) error {
+	var finalErr error
+	selected := 0
+
+	for _, resource := range resources.AsList() {
+		handler, err := registry.GetHandler(resource.Kind())
+		if err != nil {
+			return err
+		}
+		tagHandler, ok := handler.(TagHandler)
+		if !ok {
+			continue
+		}
+		tags := tagHandler.Tags(resource)
+		if !SelectorMatches(resource, tags, opts.Selector) {
+			continue
+		}
+		selected++
+
+		changed := false
+		if retagged, ok := retag(tags, opts); ok {
+			if err := rewriteResource(registry, resource, tagHandler.SetTags(resource, retagged)); err != nil {
+				finalErr = multierror.Append(finalErr, fmt.Errorf("%s: %w", resource.Ref(), err))
+				continue
+			}
+			changed = true
+		}
+
+		if !opts.LocalOnly {
+			remoteChanged, err := retagRemote(handler, tagHandler, resource, opts)
+			if err != nil {
+				finalErr = multierror.Append(finalErr, fmt.Errorf("%s: %w", resource.Ref(), ClassifyError(handler, err)))
+				continue
+			}
+			changed = changed || remoteChanged
+		}
+
+		if changed {
+			notifier.Updated(resource)
+		} else {
+			notifier.NoChanges(resource)
+		}
+	}
+
+	if selected == 0 {
+		notifier.Info(nil, "No resources match the selector")
+	}
+	return finalErr
+}
+
+// retagRemote updates the tags of the remote version of a resource, if it exists
+func retagRemote(handler Handler, tagHandler TagHandler, resource Resource, opts TagOptions) (bool, error) {
+	remote, err := handler.GetRemote(resource)
+	if errors.Is(err, ErrNotFound) {
+		notifier.Warn(resource, "not found remotely, only the local file was retagged")
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	retagged, ok := retag(tagHandler.Tags(*remote), opts)
+	if !ok {
+		return false, nil
+	}
+	updated := tagHandler.SetTags(*handler.Unprepare(*remote), retagged)
+	return true, handler.Update(*remote, *handler.Prepare(remote, updated))
+}
+
+// retag returns the tags with those to add appended and those to remove
+// removed, and whether that changed them
+func retag(tags []string, opts TagOptions) ([]string, bool) {
+	retagged := make([]string, 0, len(tags)+len(opts.Add))
+	for _, tag := range tags {
+		if !slices.Contains(opts.Remove, tag) {
+			retagged = append(retagged, tag)
+		}
+	}
+	for _, tag := range opts.Add {
+		if !slices.Contains(retagged, tag) && !slices.Contains(opts.Remove, tag) {
+			retagged = append(retagged, tag)
+		}
+	}
+	return retagged, !slices.Equal(tags, retagged)
+}
+
+// SelectorMatches returns whether a resource, with the given tags, satisfies every matcher of a selector
+func SelectorMatches(resource Resource, tags []string, selector []Matcher) bool {
+	for _, matcher := range selector {
+		if matcher.Name == "tag" {
+			// tag=x matches resources with a tag x, tag!=x resources without
+			positive := matcher
+			positive.IsEqual = true
+			if slices.ContainsFunc(tags, positive.Matches) != matcher.IsEqual {
+				return false
+			}
+			continue
+		}
+		if !matcher.Matches(selectorValue(resource, matcher.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+func selectorValue(resource Resource, name string) string {
+	if value, ok := resource.Labels()[name]; ok {
+		return value
+	}
+	switch {
+	case name == "kind":
+		return resource.Kind()
+	case name == "name":
+		return resource.Name()
+	case resource.HasMetadata(name):
+		return resource.GetMetadata(name)
+	}
+	value, _ := resource.GetSpecString(name)
+	return value
+}
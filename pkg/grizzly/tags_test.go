@@ -0,0 +1,85 @@
+package grizzly_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grafana"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectorMatches(t *testing.T) {
+	resource, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", grafana.DashboardKind, "api", map[string]any{"title": "API"})
+	require.NoError(t, err)
+	resource.SetMetadata("folder", "team-a")
+	tags := []string{"api", "team-a"}
+
+	tests := []struct {
+		selector []string
+		expected bool
+	}{
+		{selector: nil, expected: true},
+		{selector: []string{"folder=team-a"}, expected: true},
+		{selector: []string{"folder=team-a", "title!=API"}, expected: false},
+		{selector: []string{"kind=Dashboard", `name=~"a.*"`}, expected: true},
+		{selector: []string{`name=~"p.*"`}, expected: false},
+		{selector: []string{`tag=~"team-.*"`}, expected: true},
+		{selector: []string{"tag=prod"}, expected: false},
+		{selector: []string{"tag!=prod"}, expected: true},
+		{selector: []string{"tag!=api"}, expected: false},
+	}
+	for _, test := range tests {
+		var selector []grizzly.Matcher
+		for _, matcher := range test.selector {
+			parsed, err := grizzly.ParseMatcher(matcher)
+			require.NoError(t, err)
+			selector = append(selector, parsed)
+		}
+		require.Equal(t, test.expected, grizzly.SelectorMatches(resource, tags, selector), "%v", test.selector)
+	}
+}
+
+func TestUpdateTags(t *testing.T) {
+	registry := grizzly.NewRegistry([]grizzly.Provider{grafana.NewProvider(&config.GrafanaConfig{})})
+
+	dir := t.TempDir()
+	dashboard := func(name, folder string, tags string) string {
+		return `apiVersion: grizzly.grafana.com/v1alpha1
+kind: Dashboard
+metadata:
+    folder: ` + folder + `
+    name: ` + name + `
+spec:
+    tags: ` + tags + `
+    title: ` + name + `
+    uid: ` + name + `
+`
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "api.yaml"), []byte(dashboard("api", "team-a", "[api, legacy]")), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db.yaml"), []byte(dashboard("db", "team-b", "[db]")), 0644))
+
+	parser := grizzly.DefaultParser(registry, nil, nil)
+	resources, err := parser.Parse(dir, grizzly.ParserOptions{})
+	require.NoError(t, err)
+
+	selector, err := grizzly.ParseMatcher("folder=team-a")
+	require.NoError(t, err)
+	err = grizzly.UpdateTags(registry, resources, grizzly.TagOptions{
+		Add:       []string{"team-a"},
+		Remove:    []string{"legacy"},
+		Selector:  []grizzly.Matcher{selector},
+		LocalOnly: true,
+	})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(dir, "api.yaml"))
+	require.NoError(t, err)
+	require.Contains(t, string(content), "tags:\n        - api\n        - team-a\n")
+
+	content, err = os.ReadFile(filepath.Join(dir, "db.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, dashboard("db", "team-b", "[db]"), string(content), "resources not matching the selector are left untouched")
+}
@@ -0,0 +1,91 @@
+package grizzly
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DefaultAppliedStateFile is where the resources applied from each resource
+// path are recorded, when resources are tracked, relative to the working
+// directory.
+const DefaultAppliedStateFile = ".grizzly/applied.json"
+
+// PruneApplied records the resources applied from a resource path, per
+// context, and deletes the resources applied previously from the same path
+// that no longer are, when prune is set. Resources applied from other paths
+// are left untouched, as are instances of fan-outs, which are pruned by
+// PruneFanOuts, and resources of kinds that can't be deleted.
+func PruneApplied(registry Registry, resources Resources, targets []string, resourcePath, path, context string, prune bool) error {
+	source := trackedSource(resourcePath)
+
+	applied := []stateRef{}
+	_ = resources.ForEach(func(resource Resource) error {
+		if resource.Source.FanOut != "" {
+			return nil
+		}
+		handler, err := registry.GetHandler(resource.Kind())
+		if err != nil {
+			return nil
+		}
+		if _, ok := handler.(DeleteHandler); ok {
+			applied = append(applied, stateRef{Kind: resource.Kind(), Name: resource.Name()})
+		}
+		return nil
+	})
+
+	state, err := readPruneState(path)
+	if err != nil {
+		return err
+	}
+	if state[context] == nil {
+		state[context] = map[string][]stateRef{}
+	}
+
+	kept, pruneErr := pruneUndeclared(registry, applied, state[context][source], targets, prune, "no longer found in "+source)
+	state[context][source] = kept
+	if err := writeContextState(path, context, state[context]); err != nil {
+		return err
+	}
+	return pruneErr
+}
+
+// trackedSource identifies a resource path in the state file, relative to
+// the working directory when possible, so that it's recorded the same way
+// however it's given
+func trackedSource(resourcePath string) string {
+	source := filepath.Clean(resourcePath)
+	wd, err := os.Getwd()
+	if err != nil {
+		return filepath.ToSlash(source)
+	}
+	abs, err := filepath.Abs(source)
+	if err != nil {
+		return filepath.ToSlash(source)
+	}
+	if rel, err := filepath.Rel(wd, abs); err == nil {
+		source = rel
+	}
+	return filepath.ToSlash(source)
+}
+
+// UndeclaredApplied returns the resources applied previously to a context
+// from a resource path, and matching targets, that are no longer declared
+// among resources: those applying them again with --prune would delete
+func UndeclaredApplied(registry Registry, resources Resources, targets []string, resourcePath, path, context string) ([]ResourceRef, error) {
+	state, err := readPruneState(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var undeclared []ResourceRef
+	for _, ref := range state[context][trackedSource(resourcePath)] {
+		resourceRef := NewResourceRef(ref.Kind, ref.Name)
+		if _, declared := resources.Find(resourceRef); declared {
+			continue
+		}
+		if registry.ResourceMatchesTarget(ref.Kind, ref.Name, targets) {
+			undeclared = append(undeclared, resourceRef)
+		}
+	}
+	return undeclared, nil
+}
@@ -0,0 +1,116 @@
+package grizzly
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// TypedSpec is the spec of a resource decoded as the struct T describing its
+// kind. Handlers, and library users, can read and modify the spec through
+// typed fields, without losing the fields T doesn't know about: when the spec
+// is encoded back, only the fields changed through T are written, and the
+// rest of the spec is kept as it was read.
+type TypedSpec[T any] struct {
+	Spec T
+
+	// raw is the spec as read
+	raw map[string]any
+	// decoded is Spec as decoded, encoded back, to find what was changed
+	decoded map[string]any
+}
+
+// DecodeSpec decodes the spec of a resource as T
+func DecodeSpec[T any](resource Resource) (*TypedSpec[T], error) {
+	raw, _ := deepCopyValue(resource.Spec()).(map[string]any)
+	typed := &TypedSpec[T]{raw: raw}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &typed.Spec); err != nil {
+		return nil, fmt.Errorf("spec doesn't match the schema of %s: %w", resource.Kind(), err)
+	}
+	if typed.decoded, err = encodeTyped(typed.Spec); err != nil {
+		return nil, err
+	}
+	return typed, nil
+}
+
+// Encode returns the spec, with the changes made to Spec
+func (s *TypedSpec[T]) Encode() (map[string]any, error) {
+	current, err := encodeTyped(s.Spec)
+	if err != nil {
+		return nil, err
+	}
+	spec, _ := mergeTyped(s.raw, s.decoded, current).(map[string]any)
+	if spec == nil {
+		spec = map[string]any{}
+	}
+	return spec, nil
+}
+
+// Apply replaces the spec of a resource with the encoded spec
+func (s *TypedSpec[T]) Apply(resource *Resource) error {
+	spec, err := s.Encode()
+	if err != nil {
+		return err
+	}
+	resource.SetSpec(spec)
+	return nil
+}
+
+func encodeTyped(spec any) (map[string]any, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	encoded := map[string]any{}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return nil, fmt.Errorf("typed spec must encode as an object: %w", err)
+	}
+	return encoded, nil
+}
+
+// mergeTyped applies the changes between the decoded and current encodings of
+// a typed value to its raw value. Values left unchanged are kept raw, so that
+// unknown fields, and the types the values were read with, are retained. Lists
+// changing length are replaced as a whole.
+func mergeTyped(raw, decoded, current any) any {
+	if reflect.DeepEqual(decoded, current) {
+		return raw
+	}
+
+	switch currentValue := current.(type) {
+	case map[string]any:
+		rawMap, rawOK := raw.(map[string]any)
+		decodedMap, decodedOK := decoded.(map[string]any)
+		if !rawOK || !decodedOK {
+			return current
+		}
+		merged := make(map[string]any, len(rawMap))
+		for key, value := range rawMap {
+			if _, known := decodedMap[key]; !known {
+				merged[key] = value
+			}
+		}
+		for key, value := range currentValue {
+			merged[key] = mergeTyped(rawMap[key], decodedMap[key], value)
+		}
+		return merged
+	case []any:
+		rawList, rawOK := raw.([]any)
+		decodedList, decodedOK := decoded.([]any)
+		if !rawOK || !decodedOK || len(rawList) != len(currentValue) || len(decodedList) != len(currentValue) {
+			return current
+		}
+		merged := make([]any, len(currentValue))
+		for i, value := range currentValue {
+			merged[i] = mergeTyped(rawList[i], decodedList[i], value)
+		}
+		return merged
+	default:
+		return current
+	}
+}
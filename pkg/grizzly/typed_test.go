@@ -0,0 +1,81 @@
+package grizzly_test
+
+import (
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+type testPanel struct {
+	ID    int    `json:"id"`
+	Title string `json:"title,omitempty"`
+}
+
+type testDashboard struct {
+	Title  string      `json:"title"`
+	Tags   []string    `json:"tags,omitempty"`
+	Panels []testPanel `json:"panels,omitempty"`
+}
+
+func TestTypedSpec(t *testing.T) {
+	spec := func() map[string]any {
+		return map[string]any{
+			"title":   "Dashboard",
+			"tags":    []any{"team"},
+			"unknown": map[string]any{"kept": true},
+			"panels": []any{
+				map[string]any{"id": 1, "title": "CPU", "options": map[string]any{"legend": "bottom"}},
+				map[string]any{"id": 2, "title": ""},
+			},
+		}
+	}
+	resource, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", "Dashboard", "dashboard", spec())
+	require.NoError(t, err)
+
+	t.Run("unchanged specs are encoded as read", func(t *testing.T) {
+		typed, err := grizzly.DecodeSpec[testDashboard](resource)
+		require.NoError(t, err)
+
+		encoded, err := typed.Encode()
+		require.NoError(t, err)
+		require.Equal(t, spec(), encoded)
+	})
+
+	t.Run("unknown fields are retained", func(t *testing.T) {
+		typed, err := grizzly.DecodeSpec[testDashboard](resource)
+		require.NoError(t, err)
+		require.Equal(t, "CPU", typed.Spec.Panels[0].Title)
+
+		typed.Spec.Title = "Renamed"
+		typed.Spec.Tags = nil
+		typed.Spec.Panels[0].Title = "Load"
+
+		encoded, err := typed.Encode()
+		require.NoError(t, err)
+		expected := spec()
+		expected["title"] = "Renamed"
+		delete(expected, "tags")
+		expected["panels"].([]any)[0].(map[string]any)["title"] = "Load"
+		require.Equal(t, expected, encoded)
+		require.Equal(t, spec(), resource.Spec(), "the resource is only changed when applying the spec")
+	})
+
+	t.Run("lists changing length are replaced", func(t *testing.T) {
+		typed, err := grizzly.DecodeSpec[testDashboard](resource)
+		require.NoError(t, err)
+		typed.Spec.Panels = typed.Spec.Panels[:1]
+
+		copied := resource.DeepCopy()
+		require.NoError(t, typed.Apply(&copied))
+		require.Equal(t, []any{map[string]any{"id": float64(1), "title": "CPU"}}, copied.GetSpecValue("panels"))
+	})
+
+	t.Run("specs not matching the type are refused", func(t *testing.T) {
+		malformed, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", "Dashboard", "dashboard", map[string]any{"title": 42})
+		require.NoError(t, err)
+
+		_, err = grizzly.DecodeSpec[testDashboard](malformed)
+		require.ErrorContains(t, err, "spec doesn't match the schema of Dashboard")
+	})
+}
@@ -0,0 +1,446 @@
+package grizzly
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
+	"github.com/hashicorp/go-multierror"
+)
+
+// DefaultUIDRemapFile is where rewritten UIDs are recorded, relative to the working directory.
+const DefaultUIDRemapFile = ".grizzly/uid-remap.json"
+
+// UID policies, deciding how invalid UIDs are rewritten
+const (
+	// UIDPolicySanitize replaces invalid characters with dashes, and shortens
+	// long UIDs, keeping them unique with a hash of the original UID
+	UIDPolicySanitize = "sanitize"
+	// UIDPolicyLowercase sanitizes UIDs, and lowercases them
+	UIDPolicyLowercase = "lowercase"
+)
+
+// UIDConstraints are the constraints the UIDs of a kind must meet
+type UIDConstraints struct {
+	MaxLength int
+	// Pattern matches valid UIDs
+	Pattern *regexp.Regexp
+}
+
+// UIDHandler describes a handler whose resources are identified by UIDs
+// that must meet constraints, such as the UIDs of Grafana dashboards
+type UIDHandler interface {
+	// UIDConstraints returns the constraints the UIDs of resources must meet
+	UIDConstraints() UIDConstraints
+	// SetUID changes the UID of a resource
+	SetUID(resource Resource, uid string) Resource
+}
+
+// NestedUID is the UID of an item held by a resource, such as a rule of an
+// alert rule group
+type NestedUID struct {
+	// Name identifies the item within its resource, such as the title of a rule
+	Name string
+	// UID is empty when the item lacks one
+	UID string
+}
+
+// NestedUIDHandler describes a handler whose resources hold items with UIDs
+// of their own, such as the rules of alert rule groups. The remote system
+// gives items lacking a UID a new one whenever they are added, so that
+// renaming or moving their resource duplicates them.
+type NestedUIDHandler interface {
+	// NestedUIDs returns the items of a resource, with their UID
+	NestedUIDs(resource Resource) []NestedUID
+	// PinNestedUIDs gives the named items of a resource a UID: the one of the
+	// remote item they match, unless taken by another item, or else a new one
+	PinNestedUIDs(resource Resource, names []string, taken map[string]bool) (Resource, error)
+}
+
+// UIDRemap records the UID a resource was given in place of its former UID
+type UIDRemap struct {
+	Kind string `json:"kind"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+// UIDProblem describes a UID that doesn't meet the constraints of its kind,
+// or that doesn't match the file of its resource
+type UIDProblem struct {
+	Resource Resource
+	Message  string
+	// Rewrite is set when the UID needs to change, rather than the file name
+	Rewrite bool
+	// Item is set when the UID of an item of the resource is missing, or
+	// shared with another item, and needs to be pinned
+	Item *NestedUID
+}
+
+// VerifyUIDs audits the UIDs of resources: their length, their characters,
+// collisions between UIDs differing only by case, files named after another
+// UID than the one of the resource they hold, and items of resources lacking
+// a UID of their own, or sharing it.
+func VerifyUIDs(registry Registry, resources Resources) ([]UIDProblem, error) {
+	var problems []UIDProblem
+
+	perFile := map[string]int{}
+	for _, resource := range resources.AsList() {
+		perFile[resource.Source.Path]++
+	}
+
+	for kind, ofKind := range resources.GroupByKind() {
+		handler, err := registry.GetHandler(kind)
+		if err != nil {
+			return nil, err
+		}
+		if nestedHandler, ok := handler.(NestedUIDHandler); ok {
+			problems = append(problems, verifyNestedUIDs(nestedHandler, ofKind)...)
+		}
+		uidHandler, ok := handler.(UIDHandler)
+		if !ok {
+			continue
+		}
+		constraints := uidHandler.UIDConstraints()
+
+		seen := map[string]string{}
+		for _, resource := range ofKind.AsList() {
+			uid := resource.Name()
+			if constraints.MaxLength > 0 && len(uid) > constraints.MaxLength {
+				problems = append(problems, UIDProblem{Resource: resource, Rewrite: true,
+					Message: fmt.Sprintf("UID is %d characters long, the limit is %d", len(uid), constraints.MaxLength)})
+			}
+			if constraints.Pattern != nil && !constraints.Pattern.MatchString(uid) {
+				problems = append(problems, UIDProblem{Resource: resource, Rewrite: true,
+					Message: fmt.Sprintf("UID has invalid characters, it should match %s", constraints.Pattern)})
+			}
+			if other, collides := seen[strings.ToLower(uid)]; collides {
+				problems = append(problems, UIDProblem{Resource: resource, Rewrite: true,
+					Message: fmt.Sprintf("UID collides with %s, they only differ by case", other)})
+			}
+			seen[strings.ToLower(uid)] = uid
+
+			if resource.Source.Path == "" || perFile[resource.Source.Path] > 1 {
+				continue
+			}
+			if !strings.Contains(filepath.Base(resource.Source.Path), uid) {
+				problems = append(problems, UIDProblem{Resource: resource,
+					Message: fmt.Sprintf("file %s isn't named after the UID", filepath.Base(resource.Source.Path))})
+			}
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Resource.Ref().String() < problems[j].Resource.Ref().String()
+	})
+	return problems, nil
+}
+
+// verifyNestedUIDs reports the items of resources of a kind lacking a UID, or
+// sharing it with another item
+func verifyNestedUIDs(handler NestedUIDHandler, resources Resources) []UIDProblem {
+	var problems []UIDProblem
+	owners := map[string]string{}
+	for _, resource := range resources.AsList() {
+		for _, item := range handler.NestedUIDs(resource) {
+			switch owner, shared := owners[item.UID]; {
+			case item.UID == "":
+				problems = append(problems, UIDProblem{Resource: resource, Item: &item,
+					Message: fmt.Sprintf("%q has no UID, it's duplicated remotely when %s is renamed or moved", item.Name, resource.Name())})
+			case shared:
+				problems = append(problems, UIDProblem{Resource: resource, Item: &item,
+					Message: fmt.Sprintf("%q shares the UID %s with %s", item.Name, item.UID, owner)})
+			default:
+				owners[item.UID] = fmt.Sprintf("%q of %s", item.Name, resource.Name())
+			}
+		}
+	}
+	return problems
+}
+
+// RewriteUIDs gives the resources with invalid UIDs a new UID, following a
+// policy, and renames the files of resources not named after their UID. The
+// files of the resources are rewritten, and the new UIDs are recorded in the
+// remap file, so that references can be updated and the former remote
+// resources deleted.
+func RewriteUIDs(registry Registry, resources Resources, problems []UIDProblem, policy, remapFile string) error {
+	if policy != UIDPolicySanitize && policy != UIDPolicyLowercase {
+		return fmt.Errorf("unknown UID policy %s, expected %s or %s", policy, UIDPolicySanitize, UIDPolicyLowercase)
+	}
+
+	var finalErr error
+	var remaps []UIDRemap
+	taken := map[string]bool{}
+	perFile := map[string]int{}
+	for _, resource := range resources.AsList() {
+		taken[strings.ToLower(resource.Ref().String())] = true
+		perFile[resource.Source.Path]++
+	}
+
+	if err := pinNestedUIDs(registry, resources, problems); err != nil {
+		finalErr = multierror.Append(finalErr, err)
+	}
+
+	done := map[string]bool{}
+	for _, problem := range problems {
+		resource := problem.Resource
+		if problem.Item != nil {
+			continue
+		}
+		if done[resource.Ref().String()] {
+			continue
+		}
+		done[resource.Ref().String()] = true
+		if perFile[resource.Source.Path] > 1 {
+			finalErr = multierror.Append(finalErr, fmt.Errorf("%s: not rewritten, as %s holds several resources", resource.Ref(), resource.Source.Path))
+			continue
+		}
+
+		handler, err := registry.GetHandler(resource.Kind())
+		if err != nil {
+			return err
+		}
+		uidHandler := handler.(UIDHandler)
+
+		oldUID := resource.Name()
+		newUID := oldUID
+		updated := resource
+		if problem.Rewrite {
+			newUID = rewriteUID(oldUID, uidHandler.UIDConstraints(), policy)
+			ref := NewResourceRef(resource.Kind(), newUID)
+			if taken[strings.ToLower(ref.String())] {
+				newUID = hashedUID(oldUID, newUID, uidHandler.UIDConstraints())
+				ref = NewResourceRef(resource.Kind(), newUID)
+			}
+			taken[strings.ToLower(ref.String())] = true
+			updated = uidHandler.SetUID(resource.DeepCopy(), newUID)
+		}
+
+		base := filepath.Base(resource.Source.Path)
+		if strings.Contains(base, oldUID) {
+			base = strings.ReplaceAll(base, oldUID, newUID)
+		} else {
+			path, err := resourceFilePath(registry, handler, updated, resource.Source.Format)
+			if err != nil {
+				finalErr = multierror.Append(finalErr, fmt.Errorf("%s: %w", resource.Ref(), err))
+				continue
+			}
+			base = filepath.Base(path)
+		}
+		if err := moveResource(registry, resource, updated, filepath.Join(filepath.Dir(resource.Source.Path), base)); err != nil {
+			finalErr = multierror.Append(finalErr, fmt.Errorf("%s: %w", resource.Ref(), err))
+			continue
+		}
+
+		if newUID == oldUID {
+			notifier.Info(resource, fmt.Sprintf("renamed to %s", base))
+			continue
+		}
+		notifier.Info(resource, fmt.Sprintf("UID rewritten to %s", newUID))
+		remaps = append(remaps, UIDRemap{Kind: resource.Kind(), Old: oldUID, New: newUID})
+	}
+
+	if len(remaps) > 0 {
+		existing, err := ReadUIDRemaps(remapFile)
+		if err != nil {
+			return multierror.Append(finalErr, err)
+		}
+		if err := writeUIDRemaps(remapFile, append(existing, remaps...)); err != nil {
+			return multierror.Append(finalErr, err)
+		}
+		notifier.Info(nil, fmt.Sprintf("%s recorded in %s", Pluraliser(len(remaps), "rewritten UID"), remapFile))
+	}
+	return finalErr
+}
+
+// pinNestedUIDs gives the items of resources whose UID is missing or shared
+// a UID of their own, and rewrites the files of their resources
+func pinNestedUIDs(registry Registry, resources Resources, problems []UIDProblem) error {
+	names := map[ResourceRef][]string{}
+	var refs []ResourceRef
+	for _, problem := range problems {
+		if problem.Item == nil {
+			continue
+		}
+		ref := problem.Resource.Ref()
+		if _, ok := names[ref]; !ok {
+			refs = append(refs, ref)
+		}
+		names[ref] = append(names[ref], problem.Item.Name)
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+
+	taken := map[string]bool{}
+	for _, resource := range resources.AsList() {
+		if handler, ok := registry.Handlers[resource.Kind()].(NestedUIDHandler); ok {
+			for _, item := range handler.NestedUIDs(resource) {
+				if item.UID != "" {
+					taken[item.UID] = true
+				}
+			}
+		}
+	}
+
+	var finalErr error
+	for _, ref := range refs {
+		resource, _ := resources.Find(ref)
+		handler, err := registry.GetHandler(resource.Kind())
+		if err != nil {
+			return err
+		}
+		pinned, err := handler.(NestedUIDHandler).PinNestedUIDs(resource.DeepCopy(), names[ref], taken)
+		if err != nil {
+			finalErr = multierror.Append(finalErr, fmt.Errorf("%s: %w", ref, ClassifyError(handler, err)))
+			continue
+		}
+		if err := rewriteResource(registry, resource, pinned); err != nil {
+			finalErr = multierror.Append(finalErr, fmt.Errorf("%s: %w", ref, err))
+			continue
+		}
+		notifier.Info(resource, fmt.Sprintf("pinned the UIDs of %s", Pluraliser(len(names[ref]), "item")))
+	}
+	return finalErr
+}
+
+// moveResource writes an updated resource to a new file, and removes the file it was read from
+func moveResource(registry Registry, original, updated Resource, path string) error {
+	if !original.Source.Rewritable || original.Source.Path == "" {
+		return fmt.Errorf("the source for this %s is not rewritable", original.Kind())
+	}
+	if path != original.Source.Path {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists", path)
+		}
+	}
+
+	moved := original
+	moved.Source.Path = path
+	if err := rewriteResource(registry, moved, updated); err != nil {
+		return err
+	}
+	if path == original.Source.Path {
+		return nil
+	}
+	return os.Remove(original.Source.Path)
+}
+
+var invalidUIDCharacters = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+func rewriteUID(uid string, constraints UIDConstraints, policy string) string {
+	rewritten := strings.Trim(invalidUIDCharacters.ReplaceAllString(uid, "-"), "-")
+	if policy == UIDPolicyLowercase {
+		rewritten = strings.ToLower(rewritten)
+	}
+	if constraints.MaxLength > 0 && len(rewritten) > constraints.MaxLength {
+		rewritten = hashedUID(uid, rewritten, constraints)
+	}
+	return rewritten
+}
+
+// hashedUID keeps a rewritten UID unique by suffixing it with a hash of the original UID
+func hashedUID(original, rewritten string, constraints UIDConstraints) string {
+	suffix := fmt.Sprintf("-%x", sha1.Sum([]byte(original)))[:9]
+	if constraints.MaxLength > 0 && len(rewritten)+len(suffix) > constraints.MaxLength {
+		rewritten = strings.TrimRight(rewritten[:constraints.MaxLength-len(suffix)], "-")
+	}
+	return rewritten + suffix
+}
+
+// DeleteRemappedUIDs deletes the remote resources whose UID was rewritten,
+// once a resource with the new UID exists remotely. Deleted resources are
+// removed from the remap file.
+func DeleteRemappedUIDs(registry Registry, remapFile string) error {
+	remaps, err := ReadUIDRemaps(remapFile)
+	if err != nil {
+		return err
+	}
+	if len(remaps) == 0 {
+		notifier.Info(nil, fmt.Sprintf("No rewritten UIDs found in %s", remapFile))
+		return nil
+	}
+
+	var finalErr error
+	var remaining []UIDRemap
+	for _, remap := range remaps {
+		if err := deleteRemapped(registry, remap); err != nil {
+			finalErr = multierror.Append(finalErr, err)
+			remaining = append(remaining, remap)
+		}
+	}
+
+	if len(remaining) == 0 {
+		if err := os.Remove(remapFile); err != nil {
+			finalErr = multierror.Append(finalErr, err)
+		}
+		return finalErr
+	}
+	if err := writeUIDRemaps(remapFile, remaining); err != nil {
+		finalErr = multierror.Append(finalErr, err)
+	}
+	return finalErr
+}
+
+func deleteRemapped(registry Registry, remap UIDRemap) error {
+	handler, err := registry.GetHandler(remap.Kind)
+	if err != nil {
+		return err
+	}
+	oldRef := NewResourceRef(remap.Kind, remap.Old)
+	deleteHandler, ok := handler.(DeleteHandler)
+	if !ok {
+		return fmt.Errorf("%s: kind %s does not support delete", oldRef, remap.Kind)
+	}
+
+	if _, err := handler.GetByUID(remap.New); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("%s: not deleted, as %s doesn't exist remotely yet", oldRef, NewResourceRef(remap.Kind, remap.New))
+		}
+		return ClassifyError(handler, err)
+	}
+
+	old, err := handler.GetByUID(remap.Old)
+	if errors.Is(err, ErrNotFound) {
+		notifier.Info(oldRef, "already deleted")
+		return nil
+	}
+	if err != nil {
+		return ClassifyError(handler, err)
+	}
+	if err := deleteHandler.Delete(*old); err != nil {
+		return ClassifyError(handler, err)
+	}
+	notifier.Info(oldRef, "deleted")
+	return nil
+}
+
+// ReadUIDRemaps reads the UIDs recorded in a remap file, if it exists
+func ReadUIDRemaps(file string) ([]UIDRemap, error) {
+	content, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var remaps []UIDRemap
+	if err := json.Unmarshal(content, &remaps); err != nil {
+		return nil, fmt.Errorf("reading rewritten UIDs from %s: %w", file, err)
+	}
+	return remaps, nil
+}
+
+func writeUIDRemaps(file string, remaps []UIDRemap) error {
+	content, err := json.MarshalIndent(remaps, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFile(file, append(content, '\n'))
+}
@@ -0,0 +1,150 @@
+package grizzly_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grafana"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyUIDs(t *testing.T) {
+	registry := grizzly.NewRegistry([]grizzly.Provider{grafana.NewProvider(&config.GrafanaConfig{})})
+
+	dir := t.TempDir()
+	dashboard := func(file, uid string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(`apiVersion: grizzly.grafana.com/v1alpha1
+kind: Dashboard
+metadata:
+    folder: general
+    name: `+uid+`
+spec:
+    title: `+uid+`
+    uid: `+uid+`
+`), 0644))
+	}
+	longUID := strings.Repeat("a", 45)
+	dashboard("dashboard-valid.yaml", "valid")
+	dashboard("dashboard-"+longUID+".yaml", longUID)
+	dashboard("dashboard-with spaces.yaml", "with spaces")
+	dashboard("dashboard-API.yaml", "API")
+	dashboard("dashboard-api.yaml", "api")
+	dashboard("renamed.yaml", "moved")
+
+	parse := func() grizzly.Resources {
+		resources, err := grizzly.DefaultParser(registry, nil, nil).Parse(dir, grizzly.ParserOptions{})
+		require.NoError(t, err)
+		return resources
+	}
+	resources := parse()
+
+	problems, err := grizzly.VerifyUIDs(registry, resources)
+	require.NoError(t, err)
+	messages := map[string]string{}
+	for _, problem := range problems {
+		messages[problem.Resource.Name()] = problem.Message
+	}
+	require.Len(t, messages, 4)
+	require.Equal(t, "UID is 45 characters long, the limit is 40", messages[longUID])
+	require.Contains(t, messages["with spaces"], "UID has invalid characters")
+	require.Contains(t, messages["moved"], "file renamed.yaml isn't named after the UID")
+	require.True(t, messages["api"] != "" || messages["API"] != "", "UIDs differing by case collide")
+
+	remapFile := filepath.Join(t.TempDir(), "uid-remap.json")
+	require.NoError(t, grizzly.RewriteUIDs(registry, resources, problems, grizzly.UIDPolicySanitize, remapFile))
+
+	remaps, err := grizzly.ReadUIDRemaps(remapFile)
+	require.NoError(t, err)
+	require.Len(t, remaps, 3, "renamed files aren't recorded")
+	for _, remap := range remaps {
+		require.LessOrEqual(t, len(remap.New), 40)
+		require.Regexp(t, `^[a-zA-Z0-9_-]+$`, remap.New)
+	}
+	require.Contains(t, remaps, grizzly.UIDRemap{Kind: grafana.DashboardKind, Old: "with spaces", New: "with-spaces"})
+
+	_, err = os.Stat(filepath.Join(dir, "dashboard-moved.yaml"))
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "dashboard-with-spaces.yaml"))
+	require.NoError(t, err)
+
+	problems, err = grizzly.VerifyUIDs(registry, parse())
+	require.NoError(t, err)
+	require.Empty(t, problems)
+}
+
+func TestVerifyNestedUIDs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v1/provisioning/alert-rules", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"uid": "renamed-uid", "title": "HighCPU", "folderUID": "infra", "ruleGroup": "cpu"},
+			{"uid": "remote-uid", "title": "DiskFull", "folderUID": "infra", "ruleGroup": "disk"}
+		]`))
+	}))
+	defer server.Close()
+	registry := grizzly.NewRegistry([]grizzly.Provider{grafana.NewProvider(&config.GrafanaConfig{URL: server.URL})})
+
+	dir := t.TempDir()
+	ruleGroup := func(file, folder, title, rules string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(`apiVersion: grizzly.grafana.com/v1alpha1
+kind: AlertRuleGroup
+metadata:
+    name: `+folder+`.`+title+`
+spec:
+    folderUid: `+folder+`
+    title: `+title+`
+    rules:
+`+rules), 0644))
+	}
+	ruleGroup("alertRuleGroup-platform.cpu.yaml", "platform", "cpu", `        - title: HighCPU
+        - title: HighLoad
+          uid: load-uid
+`)
+	ruleGroup("alertRuleGroup-infra.disk.yaml", "infra", "disk", `        - title: DiskFull
+        - title: DiskSlow
+          uid: load-uid
+`)
+	parse := func() grizzly.Resources {
+		resources, err := grizzly.DefaultParser(registry, nil, nil).Parse(dir, grizzly.ParserOptions{})
+		require.NoError(t, err)
+		return resources
+	}
+	resources := parse()
+
+	problems, err := grizzly.VerifyUIDs(registry, resources)
+	require.NoError(t, err)
+	var messages []string
+	for _, problem := range problems {
+		messages = append(messages, problem.Resource.Name()+": "+problem.Message)
+	}
+	require.ElementsMatch(t, []string{
+		`infra.disk: "DiskFull" has no UID, it's duplicated remotely when infra.disk is renamed or moved`,
+		`platform.cpu: "HighCPU" has no UID, it's duplicated remotely when platform.cpu is renamed or moved`,
+		`platform.cpu: "HighLoad" shares the UID load-uid with "DiskSlow" of infra.disk`,
+	}, messages)
+
+	require.NoError(t, grizzly.RewriteUIDs(registry, resources, problems, grizzly.UIDPolicySanitize, filepath.Join(t.TempDir(), "uid-remap.json")))
+
+	resources = parse()
+	uids := map[string]string{}
+	handler := registry.Handlers[grafana.AlertRuleGroupKind].(grizzly.NestedUIDHandler)
+	for _, resource := range resources.AsList() {
+		for _, item := range handler.NestedUIDs(resource) {
+			uids[item.Name] = item.UID
+		}
+	}
+	require.Equal(t, "remote-uid", uids["DiskFull"], "rules keep the UID of their remote rule")
+	require.Equal(t, "renamed-uid", uids["HighCPU"], "rules of moved groups keep their UID")
+	require.Equal(t, "load-uid", uids["DiskSlow"])
+	require.Len(t, uids["HighLoad"], 14, "rules sharing a UID are given a new one")
+
+	problems, err = grizzly.VerifyUIDs(registry, resources)
+	require.NoError(t, err)
+	require.Empty(t, problems)
+}
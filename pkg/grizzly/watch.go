@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/fsnotify.v1"
@@ -20,6 +21,10 @@ type Watcher struct {
 	watcher     *fsnotify.Watcher
 	watcherFunc func(string) error
 	watches     []watch
+	// Debounce coalesces the changes happening within this long of each
+	// other, such as the several writes of an editor saving a file, into a
+	// single call, with the last path changed
+	Debounce time.Duration
 }
 
 func NewWatcher(watcherFunc func(path string) error) (*Watcher, error) {
@@ -73,6 +78,9 @@ func (w *Watcher) Add(path string) error {
 func (w *Watcher) Watch() error {
 	go func() {
 		log.Info("[watcher] Watching for changes")
+		var debounced *time.Timer
+		var fire <-chan time.Time
+		var pending string
 		for {
 			select {
 			case event, ok := <-w.watcher.Events:
@@ -82,12 +90,21 @@ func (w *Watcher) Watch() error {
 				if event.Op&fsnotify.Write == fsnotify.Write || event.Op&fsnotify.Create == fsnotify.Create {
 					if w.isWatched(event.Name) {
 						log.Debugf("[watcher] Changes detected: %s %s ", event.Op.String(), event.Name)
-						err := w.watcherFunc(event.Name)
-						if err != nil {
-							log.Warn("[watcher] error: ", err)
+						if w.Debounce <= 0 {
+							w.changed(event.Name)
+							continue
+						}
+						pending = event.Name
+						if debounced == nil {
+							debounced = time.NewTimer(w.Debounce)
+							fire = debounced.C
+						} else {
+							debounced.Reset(w.Debounce)
 						}
 					}
 				}
+			case <-fire:
+				w.changed(pending)
 			case err, ok := <-w.watcher.Errors:
 				if !ok {
 					return
@@ -99,6 +116,12 @@ func (w *Watcher) Watch() error {
 	return nil
 }
 
+func (w *Watcher) changed(path string) {
+	if err := w.watcherFunc(path); err != nil {
+		log.Warn("[watcher] error: ", err)
+	}
+}
+
 func (w *Watcher) Wait() error {
 	done := make(chan bool)
 	<-done
@@ -0,0 +1,46 @@
+package grizzly
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcherDebounce(t *testing.T) {
+	dir := t.TempDir()
+	rules := filepath.Join(dir, "rules.yaml")
+	require.NoError(t, os.WriteFile(rules, []byte("groups: []"), 0644))
+
+	var lock sync.Mutex
+	var changed []string
+	watcher, err := NewWatcher(func(path string) error {
+		lock.Lock()
+		defer lock.Unlock()
+		changed = append(changed, path)
+		return nil
+	})
+	require.NoError(t, err)
+	watcher.Debounce = 200 * time.Millisecond
+	require.NoError(t, watcher.Add(dir))
+	require.NoError(t, watcher.Watch())
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, os.WriteFile(rules, []byte("groups: []\n"), 0644))
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	require.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(changed) > 0
+	}, 2*time.Second, 10*time.Millisecond)
+	time.Sleep(2 * watcher.Debounce)
+
+	lock.Lock()
+	defer lock.Unlock()
+	require.Equal(t, []string{rules}, changed, "changes are coalesced")
+}
@@ -7,8 +7,11 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"text/tabwriter"
+	"time"
 
 	"github.com/grafana/grizzly/pkg/grizzly/notifier"
 	"github.com/grafana/grizzly/pkg/term"
@@ -25,33 +28,46 @@ var interactive = terminal.IsTerminal(int(os.Stdout.Fd()))
 func Get(registry Registry, uid string, onlySpec bool, outputFormat string) error {
 	log.Info("Getting ", uid)
 
-	if strings.Count(uid, ".") == 0 {
-		return fmt.Errorf("UID must be <provider>.<uid>: %s", uid)
+	resource, err := GetRemoteResource(registry, uid)
+	if err != nil {
+		return err
 	}
 
-	parts := strings.SplitN(uid, ".", 2)
-	handlerName := parts[0]
-	resourceID := parts[1]
-
-	handler, err := registry.GetHandler(handlerName)
+	content, _, _, err := Format(registry, "", resource, outputFormat, onlySpec)
 	if err != nil {
 		return err
 	}
 
+	notifier.Print(string(content))
+	return nil
+}
+
+// GetRemoteResource retrieves a resource from a remote endpoint using its <kind>.<uid> reference
+func GetRemoteResource(registry Registry, uid string) (*Resource, error) {
+	handler, resourceID, err := getHandlerForUID(registry, uid)
+	if err != nil {
+		return nil, err
+	}
+
 	resource, err := handler.GetByUID(resourceID)
 	if err != nil {
-		return err
+		return nil, ClassifyError(handler, err)
 	}
+	return handler.Unprepare(*resource), nil
+}
 
-	resource = handler.Unprepare(*resource)
+// getHandlerForUID finds the handler for a <kind>.<uid> reference, and returns it along with the UID of the resource
+func getHandlerForUID(registry Registry, uid string) (Handler, string, error) {
+	handlerName, resourceID, found := strings.Cut(uid, ".")
+	if !found {
+		return nil, "", fmt.Errorf("UID must be <provider>.<uid>: %s", uid)
+	}
 
-	content, _, _, err := Format(registry, "", resource, outputFormat, onlySpec)
+	handler, err := registry.GetHandler(handlerName)
 	if err != nil {
-		return err
+		return nil, "", err
 	}
-
-	fmt.Println(string(content))
-	return nil
+	return handler, resourceID, nil
 }
 
 type listedResource struct {
@@ -106,7 +122,7 @@ func ListRemote(registry Registry, targets []string, format string) error {
 		log.Debugf("Listing remote values for handler %s", name)
 		IDs, err := handler.ListRemote()
 		if err != nil {
-			return err
+			return classifyListError(handler, err)
 		}
 		for _, id := range IDs {
 			listedResources = append(listedResources, listedResource{
@@ -135,7 +151,7 @@ func listResources(listedResources []listedResource, format string) error {
 	if err != nil {
 		return err
 	}
-	fmt.Println(string(output))
+	notifier.Print(string(output))
 	return nil
 }
 
@@ -176,10 +192,28 @@ func listWide(listedResources []listedResource) ([]byte, error) {
 	return out.Bytes(), err
 }
 
+// PullOptions describes how resources are pulled
+type PullOptions struct {
+	// OnlySpec writes the spec of resources, without their envelope
+	OnlySpec bool
+	// OutputFormat is the format of the files written: yaml or json
+	OutputFormat string
+	// Targets restricts the resources pulled
+	Targets []string
+	// ContinueOnError keeps pulling the remaining resources when one fails
+	ContinueOnError bool
+	// ExtractQueries writes the queries of resources to their own files, referenced with $file
+	ExtractQueries bool
+	// Selection restricts the resources pulled to those matching its selector
+	Selection Selection
+	// Prune deletes the files of previously pulled resources that no longer
+	// exist remotely, or that were pulled to another file
+	Prune bool
+}
+
 // Pull pulls remote resources and stores them in the local file system.
 // The given resourcePath must be a directory, where all resources will be stored.
-// If opts.JSONSpec is true, which is only applicable for dashboards, saves the spec as a JSON file.
-func Pull(registry Registry, resourcePath string, onlySpec bool, outputFormat string, targets []string, continueOnError bool, eventsRecorder EventsRecorder) error {
+func Pull(registry Registry, resourcePath string, opts PullOptions, eventsRecorder EventsRecorder) error {
 	resourcePathIsFile, err := isFile(resourcePath)
 	if err != nil {
 		return err
@@ -190,10 +224,16 @@ func Pull(registry Registry, resourcePath string, onlySpec bool, outputFormat st
 	}
 
 	var finalErr error
+	// files written, by lower-cased path, to detect UIDs sharing a file,
+	// including on case-insensitive file systems
+	written := map[string]Resource{}
+	manifest := map[string]ManifestEntry{}
+	// remote UIDs, by kind, of the kinds listed
+	listed := map[string]map[string]bool{}
 
 	log.Infof("Pulling resources to %s", resourcePath)
 	for name, handler := range registry.Handlers {
-		if !registry.HandlerMatchesTarget(handler, targets) {
+		if !registry.HandlerMatchesTarget(handler, opts.Targets) {
 			notifier.Info(notifier.SimpleString(handler.Kind()), "skipped")
 			continue
 		}
@@ -201,49 +241,50 @@ func Pull(registry Registry, resourcePath string, onlySpec bool, outputFormat st
 		log.Debugf("Listing remote values for handler %s", name)
 		UIDs, err := handler.ListRemote()
 		if err != nil {
+			err = classifyListError(handler, err)
 			finalErr = multierror.Append(finalErr, err)
-			eventsRecorder.Record(Event{
-				Type:        ResourceFailure,
-				ResourceRef: name,
-				Details:     fmt.Sprintf("failed listing remote values: %s", err),
-			})
+			eventsRecorder.Record(NewFailureEvent(name, fmt.Sprintf("failed listing remote values: %s", err), err))
 
-			if continueOnError {
+			if opts.ContinueOnError {
 				continue
 			}
 
 			return finalErr
 		}
+		listed[handler.Kind()] = map[string]bool{}
+		for _, UID := range UIDs {
+			listed[handler.Kind()][UID] = true
+		}
 		if len(UIDs) == 0 {
 			notifier.Info(nil, "No resources found")
 			continue
 		}
 
 		notifier.Warn(nil, fmt.Sprintf("Pulling %d resources", len(UIDs)))
+		matchingUIDs := make([]string, 0, len(UIDs))
 		for _, UID := range UIDs {
-			if !registry.ResourceMatchesTarget(handler.Kind(), UID, targets) {
-				continue
+			if registry.ResourceMatchesTarget(handler.Kind(), UID, opts.Targets) {
+				matchingUIDs = append(matchingUIDs, UID)
 			}
+		}
 
-			resource, err := handler.GetByUID(UID)
+		for _, result := range getByUIDs(registry, handler, matchingUIDs) {
+			UID, resource, err := result.UID, result.Resource, result.Err
 			if errors.Is(err, ErrNotFound) {
 				finalErr = multierror.Append(finalErr, err)
 				eventsRecorder.Record(Event{Type: ResourceNotFound, ResourceRef: UID})
-				if continueOnError {
+				if opts.ContinueOnError {
 					continue
 				}
 
 				return nil
 			}
 			if err != nil {
+				err = ClassifyError(handler, err)
 				finalErr = multierror.Append(finalErr, err)
-				eventsRecorder.Record(Event{
-					Type:        ResourceFailure,
-					ResourceRef: UID,
-					Details:     fmt.Sprintf("failed pulling resource: %s", err),
-				})
+				eventsRecorder.Record(NewFailureEvent(UID, fmt.Sprintf("failed pulling resource: %s", err), err))
 
-				if continueOnError {
+				if opts.ContinueOnError {
 					continue
 				}
 
@@ -251,8 +292,36 @@ func Pull(registry Registry, resourcePath string, onlySpec bool, outputFormat st
 			}
 
 			resource = handler.Unprepare(*resource)
+			if !opts.Selection.Matches(registry, *resource) {
+				log.Debugf("Omitting %s, not selected", resource.Ref())
+				continue
+			}
+			pruned := pruneFields(registry, *resource)
+			resource = &pruned
+
+			content, filename, _, err := Format(registry, resourcePath, resource, opts.OutputFormat, opts.OnlySpec)
+			if err == nil && !opts.OnlySpec {
+				if local, ok := localVersion(registry, filename, *resource); ok {
+					keptDescription := keepDescription(handler, local, resource)
+					if keepDependencies(local, resource) || keptDescription {
+						content, _, _, err = Format(registry, resourcePath, resource, opts.OutputFormat, opts.OnlySpec)
+					}
+				}
+			}
+			if other, ok := written[strings.ToLower(filename)]; err == nil && ok {
+				err = fmt.Errorf("%s shares the file %s with %s", resource.Ref(), filename, other.Ref())
+				finalErr = multierror.Append(finalErr, err)
+				eventsRecorder.Record(NewFailureEvent(resource.Ref().String(), fmt.Sprintf("failed writing resource to file: %s", err), err))
+
+				if opts.ContinueOnError {
+					continue
+				}
 
-			content, filename, _, err := Format(registry, resourcePath, resource, outputFormat, onlySpec)
+				return finalErr
+			}
+			if err == nil {
+				content, err = formatWithFileReferences(registry, handler, filename, *resource, opts, content)
+			}
 			if err != nil {
 				finalErr = multierror.Append(finalErr, err)
 				eventsRecorder.Record(Event{
@@ -261,7 +330,7 @@ func Pull(registry Registry, resourcePath string, onlySpec bool, outputFormat st
 					Details:     fmt.Sprintf("failed formatting resource: %s", err),
 				})
 
-				if continueOnError {
+				if opts.ContinueOnError {
 					continue
 				}
 
@@ -277,20 +346,110 @@ func Pull(registry Registry, resourcePath string, onlySpec bool, outputFormat st
 					Details:     fmt.Sprintf("failed writing resource to file: %s", err),
 				})
 
-				if continueOnError {
+				if opts.ContinueOnError {
 					continue
 				}
 
 				return finalErr
 			}
 
+			written[strings.ToLower(filename)] = *resource
+			if relative, err := filepath.Rel(resourcePath, filename); err == nil {
+				manifest[filepath.ToSlash(relative)] = ManifestEntry{Kind: resource.Kind(), UID: resource.Name()}
+			}
 			eventsRecorder.Record(Event{Type: ResourcePulled, ResourceRef: resource.Ref().String()})
 		}
 	}
 
+	pruned := false
+	if opts.Prune {
+		var err error
+		if pruned, err = prunePulled(registry, resourcePath, manifest, listed, opts, eventsRecorder); err != nil {
+			finalErr = multierror.Append(finalErr, err)
+		}
+	}
+	if len(manifest) > 0 || pruned {
+		if err := updateFilenameManifest(resourcePath, manifest); err != nil {
+			finalErr = multierror.Append(finalErr, err)
+		}
+	}
 	return finalErr
 }
 
+// prunePulled deletes the files previously pulled to a resource path, as
+// recorded in its manifest, whose resource no longer exists remotely, or was
+// pulled to another file. Files of kinds that weren't listed, or of resources
+// that weren't targeted, selected or retrieved, are kept. Returns whether any
+// file was deleted.
+func prunePulled(registry Registry, resourcePath string, written map[string]ManifestEntry, listed map[string]map[string]bool, opts PullOptions, eventsRecorder EventsRecorder) (bool, error) {
+	previous, err := ReadFilenameManifest(resourcePath)
+	if err != nil {
+		return false, err
+	}
+	pulled := map[ManifestEntry]bool{}
+	for _, entry := range written {
+		pulled[entry] = true
+	}
+
+	var finalErr error
+	pruned := false
+	for file, entry := range previous {
+		remoteUIDs, ok := listed[entry.Kind]
+		if _, rewritten := written[file]; rewritten || !ok || !registry.ResourceMatchesTarget(entry.Kind, entry.UID, opts.Targets) {
+			continue
+		}
+		if remoteUIDs[entry.UID] && !pulled[entry] {
+			continue
+		}
+
+		path := filepath.Join(resourcePath, filepath.FromSlash(file))
+		ref := NewResourceRef(entry.Kind, entry.UID).String()
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			finalErr = multierror.Append(finalErr, err)
+			eventsRecorder.Record(NewFailureEvent(ref, fmt.Sprintf("failed pruning %s: %s", file, err), err))
+			continue
+		}
+		removeEmptyDirs(resourcePath, filepath.Dir(path))
+		eventsRecorder.Record(Event{Type: ResourcePruned, ResourceRef: ref, Details: file})
+		pruned = true
+	}
+	return pruned, finalErr
+}
+
+// removeEmptyDirs removes a directory, and its parents, as long as they are
+// empty and within a resource path
+func removeEmptyDirs(resourcePath, dir string) {
+	root := filepath.Clean(resourcePath)
+	for dir = filepath.Clean(dir); dir != root && strings.HasPrefix(dir, root); dir = filepath.Dir(dir) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+	}
+}
+
+// getByUIDs retrieves the given resources, in batches when the handler
+// supports it, unless they're cached, or as many at the same time as the
+// registry and the handler allow.
+func getByUIDs(registry Registry, handler Handler, UIDs []string) []BatchGetResult {
+	_, versioned := handler.(RemoteVersioner)
+	if batchHandler, ok := handler.(BatchGetHandler); ok && (registry.RemoteCache == nil || !versioned) {
+		return batchHandler.GetByUIDs(UIDs)
+	}
+
+	results := make([]BatchGetResult, 0, len(UIDs))
+	get := func(i int) BatchGetResult {
+		resource, err := getCached(registry, handler, UIDs[i], func() (*Resource, error) {
+			return handler.GetByUID(UIDs[i])
+		})
+		return BatchGetResult{UID: UIDs[i], Resource: resource, Err: err}
+	}
+	_ = fetchOrdered(len(UIDs), fetchConcurrency(registry, handler), get, func(i int, result BatchGetResult) error {
+		results = append(results, result)
+		return nil
+	})
+	return results
+}
+
 // Show displays resources
 func Show(registry Registry, resources Resources, outputFormat string) error {
 	log.Infof("Showing %d resources", resources.Len())
@@ -314,8 +473,8 @@ func Show(registry Registry, resources Resources, outputFormat string) error {
 				Content: string(content),
 			})
 		} else {
-			fmt.Printf("%s:\n", resource.Ref().String())
-			fmt.Println(string(content))
+			notifier.Printf("%s:\n", resource.Ref().String())
+			notifier.Print(string(content))
 		}
 	}
 	if interactive {
@@ -326,56 +485,152 @@ func Show(registry Registry, resources Resources, outputFormat string) error {
 
 // Diff compares resources to those at the endpoints
 func Diff(registry Registry, resources Resources, onlySpec bool, outputFormat string) error {
+	return diff(registry, resources, remoteGetter(registry, nil), "Remote", onlySpec, outputFormat)
+}
+
+// DiffAgainst compares local resources with the remote resources captured in
+// a snapshot, without calling remote endpoints
+func DiffAgainst(registry Registry, resources Resources, snapshot *StateSnapshot, onlySpec bool, outputFormat string) error {
+	return diff(registry, resources, remoteGetter(registry, snapshot), snapshotLabel(snapshot), onlySpec, outputFormat)
+}
+
+func diff(registry Registry, resources Resources, getRemote func(Handler, Resource) (*Resource, error), from string, onlySpec bool, outputFormat string) error {
 	log.Infof("Diff-ing %d resources", resources.Len())
 
-	for _, resource := range resources.AsList() {
-		handler, err := registry.GetHandler(resource.Kind())
-		if err != nil {
-			return err
+	return forEachRemote(registry, resources, getRemote, func(handler Handler, resource Resource, remote *Resource) error {
+		if remote == nil {
+			notifier.NotFound(resource)
+			return nil
 		}
+		return reportChanges(registry, handler, *remote, resource, from, "Local", onlySpec, outputFormat)
+	})
+}
 
-		resource = *handler.Unprepare(resource)
-
-		local, _, _, err := Format(registry, "", &resource, outputFormat, onlySpec)
-		if err != nil {
-			return err
+// remoteGetter retrieves remote resources from their endpoints, through the
+// remote cache of the registry, or from a snapshot when given
+func remoteGetter(registry Registry, snapshot *StateSnapshot) func(Handler, Resource) (*Resource, error) {
+	if snapshot != nil {
+		return func(handler Handler, resource Resource) (*Resource, error) {
+			return snapshot.Find(resource.Ref())
 		}
+	}
+	return func(handler Handler, resource Resource) (*Resource, error) {
+		return getCached(registry, handler, resource.Name(), func() (*Resource, error) {
+			return handler.GetRemote(resource)
+		})
+	}
+}
 
-		uid := resource.Name()
+func snapshotLabel(snapshot *StateSnapshot) string {
+	return fmt.Sprintf("Snapshot (%s)", snapshot.CapturedAt.Format(time.RFC3339))
+}
 
-		log.Debugf("Getting the remote value for `%s`", resource.Ref())
-		remote, err := handler.GetRemote(resource)
-		if errors.Is(err, ErrNotFound) {
-			notifier.NotFound(resource)
-			continue
-		}
+// forEachRemote calls compare with each local resource, as presented locally,
+// and its remote version, or nil when it doesn't exist remotely. Remote
+// versions are retrieved ahead, as many at the same time as the registry and
+// the handler of their kind allow, but compared in order.
+func forEachRemote(registry Registry, resources Resources, getRemote func(Handler, Resource) (*Resource, error), compare func(handler Handler, resource Resource, remote *Resource) error) error {
+	type fetched struct {
+		remote *Resource
+		err    error
+	}
 
+	list := resources.AsList()
+	for start := 0; start < len(list); {
+		handler, err := registry.GetHandler(list[start].Kind())
 		if err != nil {
-			return fmt.Errorf("Error retrieving resource from %s %s: %v", resource.Kind(), uid, err)
+			return err
+		}
+		// resources of the same kind are retrieved together
+		end := start + 1
+		for end < len(list) && list[end].Kind() == list[start].Kind() {
+			end++
 		}
 
-		remote = handler.Unprepare(*remote)
+		local := make([]Resource, 0, end-start)
+		for _, resource := range list[start:end] {
+			resource, err = ExposeDescription(registry, resource)
+			if err != nil {
+				return err
+			}
+			resource, err = ExposeAnnotations(registry, resource)
+			if err != nil {
+				return err
+			}
+			resource, err = ExpandResource(registry, resource)
+			if err != nil {
+				return err
+			}
+			local = append(local, pruneFields(registry, *handler.Unprepare(withoutDependencies(resource))))
+		}
 
-		remoteRepresentation, _, _, err := Format(registry, "", remote, outputFormat, onlySpec)
+		get := func(i int) fetched {
+			resource := local[i]
+			log.Debugf("Getting the remote value for `%s`", resource.Ref())
+			remote, err := getRemote(handler, resource)
+			return fetched{remote: remote, err: err}
+		}
+		err = fetchOrdered(len(local), fetchConcurrency(registry, handler), get, func(i int, result fetched) error {
+			resource := local[i]
+			if errors.Is(result.err, ErrNotFound) {
+				return compare(handler, resource, nil)
+			}
+			if result.err != nil {
+				return ClassifyError(handler, fmt.Errorf("Error retrieving resource from %s %s: %w", resource.Kind(), resource.Name(), result.err))
+			}
+			remote := pruneFields(registry, *handler.Unprepare(*result.remote))
+			return compare(handler, resource, &remote)
+		})
 		if err != nil {
 			return err
 		}
+		start = end
+	}
 
-		if string(local) == string(remoteRepresentation) {
-			notifier.NoChanges(resource)
-		} else {
-			diff := difflib.UnifiedDiff{
-				A:        difflib.SplitLines(string(remoteRepresentation)),
-				B:        difflib.SplitLines(string(local)),
-				FromFile: "Remote",
-				ToFile:   "Local",
-				Context:  3,
-			}
-			difference, _ := difflib.GetUnifiedDiffString(diff)
-			notifier.HasChanges(resource, difference)
-		}
+	return nil
+}
+
+// reportChanges announces the differences between two versions of a resource
+func reportChanges(registry Registry, handler Handler, before, after Resource, fromFile, toFile string, onlySpec bool, outputFormat string) error {
+	afterRepresentation, _, _, err := Format(registry, "", &after, outputFormat, onlySpec)
+	if err != nil {
+		return err
+	}
+	beforeRepresentation, _, _, err := Format(registry, "", &before, outputFormat, onlySpec)
+	if err != nil {
+		return err
+	}
+
+	if string(afterRepresentation) == string(beforeRepresentation) {
+		notifier.NoChanges(after)
+		return nil
+	}
+
+	var summary []string
+	if summarizer, ok := handler.(DiffSummarizer); ok {
+		summary = summarizer.DiffSummary(after, before)
 	}
 
+	if registry.DiffTool != "" {
+		notifier.HasChanges(after, "", summary...)
+		return runDiffTool(registry.DiffTool, after.Ref(), beforeRepresentation, afterRepresentation, fromFile, toFile, outputFormat)
+	}
+
+	size := max(len(beforeRepresentation), len(afterRepresentation))
+	if registry.DiffSummarySize > 0 && int64(size) > registry.DiffSummarySize {
+		notifier.HasChanges(after, "", append(summary, summarizeLargeDiff(before, after, size, onlySpec)...)...)
+		return nil
+	}
+
+	diff := difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(beforeRepresentation)),
+		B:        difflib.SplitLines(string(afterRepresentation)),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  3,
+	}
+	difference, _ := difflib.GetUnifiedDiffString(diff)
+	notifier.HasChanges(after, difference, summary...)
 	return nil
 }
 
@@ -384,89 +639,415 @@ type EventsRecorder interface {
 	Summary() Summary
 }
 
+// ApplyOptions controls how resources are pushed to endpoints
+type ApplyOptions struct {
+	// ContinueOnError keeps applying the remaining resources when one fails
+	ContinueOnError bool
+	// FieldManager, when set, restricts updates to the fields present in the local resources
+	FieldManager *FieldManager
+	// Backup, when set, records the remote versions of the resources overwritten, and the resources added
+	Backup *Backup
+	// PayloadSizeWarning is the size, in bytes, above which a warning is emitted before applying a resource
+	PayloadSizeWarning int64
+	// MaxPayloadSize is the size, in bytes, above which resources are refused. Zero means no limit
+	MaxPayloadSize int64
+	// RuleEvaluationTimeout, when positive, is how long to wait for the rules of
+	// added or updated resources to be evaluated, so that their state is reported
+	RuleEvaluationTimeout time.Duration
+	// RuleTailWindow, when positive, is how long to keep watching the rules of
+	// added or updated resources, reporting evaluation errors as they appear
+	RuleTailWindow time.Duration
+	// ProtectedKinds are the kinds whose changes need to be approved
+	ProtectedKinds []string
+	// Approve, when set, is asked to approve the changes to protected kinds,
+	// and the deletion of the resources to prune, before any resource is applied
+	Approve Approver
+	// Prune are the resources deleted once resources are applied, such as
+	// the ones no longer declared when applying with --prune
+	Prune []ResourceRef
+	// Concurrency is how many resources of a kind are applied at the same
+	// time, within the limits of their handler. Kinds are still applied one
+	// after the other, in order. Zero or one applies resources sequentially.
+	Concurrency int
+	// DryRun reports the changes applying resources would make, once checked
+	// by their remote endpoint when their handler is a RemoteValidator,
+	// without making them. Approval and rule evaluation are skipped.
+	DryRun bool
+}
+
 // Apply pushes resources to endpoints
-func Apply(registry Registry, resources Resources, continueOnError bool, eventsRecorder EventsRecorder) error {
+func Apply(registry Registry, resources Resources, opts ApplyOptions, eventsRecorder EventsRecorder) error {
 	var finalErr error
 
+	if opts.Approve != nil && !opts.DryRun {
+		if err := approveApply(registry, resources, opts); err != nil {
+			return err
+		}
+	}
+
+	appliedAt := time.Now()
+	changes := &changeRecorder{EventsRecorder: eventsRecorder, changed: map[string]bool{}}
+	recorder := &lockedRecorder{EventsRecorder: changes}
+	for _, kindResources := range groupConsecutiveKinds(registry, resources.AsList()) {
+		if err := applyKind(registry, kindResources, opts, recorder); err != nil {
+			finalErr = multierror.Append(finalErr, err)
+			if !opts.ContinueOnError {
+				return finalErr
+			}
+		}
+	}
+
+	if opts.DryRun {
+		return finalErr
+	}
+	var changed []Resource
 	for _, resource := range resources.AsList() {
-		err := applyResource(registry, resource, eventsRecorder)
-		if err != nil {
+		if changes.changed[resource.Ref().String()] {
+			changed = append(changed, resource)
+		}
+	}
+	if opts.RuleEvaluationTimeout > 0 {
+		if err := checkRuleStates(registry, changed, appliedAt, opts.RuleEvaluationTimeout, eventsRecorder); err != nil {
+			finalErr = multierror.Append(finalErr, err)
+		}
+	}
+	if opts.RuleTailWindow > 0 {
+		if err := tailRuleStates(registry, changed, appliedAt, opts.RuleTailWindow, eventsRecorder); err != nil {
 			finalErr = multierror.Append(finalErr, err)
+		}
+	}
 
-			eventsRecorder.Record(Event{
-				Type:        ResourceFailure,
-				ResourceRef: resource.Ref().String(),
-				Details:     err.Error(),
-			})
+	return finalErr
+}
 
-			if !continueOnError {
-				return finalErr
-			}
+// groupConsecutiveKinds splits sorted resources into runs of resources of the
+// same kind, so that kinds are applied in the order of the registry. A
+// resource depending on another of its run starts a new run, so that it's
+// only applied once its dependency is.
+func groupConsecutiveKinds(registry Registry, resources []Resource) [][]Resource {
+	var groups [][]Resource
+	for i, resource := range resources {
+		if i == 0 || resource.Kind() != resources[i-1].Kind() || registry.dependsOnAny(resource, groups[len(groups)-1]) {
+			groups = append(groups, nil)
 		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], resource)
 	}
+	return groups
+}
+
+// applyKind applies resources of the same kind, as many at the same time as
+// the options and their handler allow. Without ContinueOnError, no resource
+// is applied after one fails, but the resources being applied are awaited.
+func applyKind(registry Registry, resources []Resource, opts ApplyOptions, recorder EventsRecorder) error {
+	concurrency := applyConcurrency(registry, resources[0].Kind(), opts)
+
+	var finalErr error
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+	for _, resource := range resources {
+		slots <- struct{}{}
+		mu.Lock()
+		stop := finalErr != nil && !opts.ContinueOnError
+		mu.Unlock()
+		if stop {
+			break
+		}
+
+		wg.Add(1)
+		go func(resource Resource) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			if err := applyResource(registry, resource, opts, recorder); err != nil {
+				handler, _ := registry.GetHandler(resource.Kind())
+				err = ClassifyError(handler, err)
+				recorder.Record(NewFailureEvent(resource.Ref().String(), err.Error(), err))
 
+				mu.Lock()
+				finalErr = multierror.Append(finalErr, err)
+				mu.Unlock()
+			}
+		}(resource)
+	}
+	wg.Wait()
 	return finalErr
 }
 
-func applyResource(registry Registry, resource Resource, trailRecorder EventsRecorder) error {
+// applyConcurrency is how many resources of a kind can be applied at the same time
+func applyConcurrency(registry Registry, kind string, opts ApplyOptions) int {
+	concurrency := max(opts.Concurrency, 1)
+	handler, err := registry.GetHandler(kind)
+	if err != nil {
+		return concurrency
+	}
+	if limiter, ok := handler.(ConcurrencyLimiter); ok && limiter.MaxConcurrency() > 0 {
+		concurrency = min(concurrency, limiter.MaxConcurrency())
+	}
+	return concurrency
+}
+
+func applyResource(registry Registry, resource Resource, opts ApplyOptions, trailRecorder EventsRecorder) error {
 	resourceRef := resource.Ref().String()
 
 	handler, err := registry.GetHandler(resource.Kind())
 	if err != nil {
 		return err
 	}
+	change, err := planResource(registry, handler, resource, opts)
+	if err != nil {
+		return err
+	}
 
-	log.Debugf("Getting the remote value for `%s`", resource.Ref())
-	existingResource, err := handler.GetRemote(resource)
-	if errors.Is(err, ErrNotFound) {
-		log.Debugf("`%s` was not found, adding it...", resource.Ref())
-
-		resource = *handler.Prepare(nil, resource)
-		if err := handler.Add(resource); err != nil {
+	if opts.DryRun {
+		eventType, err := dryRunResource(handler, change, opts)
+		if err != nil {
 			return err
 		}
-
 		trailRecorder.Record(Event{
-			Type:        ResourceAdded,
+			Type:        eventType,
 			ResourceRef: resourceRef,
 		})
 		return nil
 	}
-	if err != nil {
+
+	switch change.Type {
+	case ResourceAdded:
+		log.Debugf("`%s` was not found, adding it...", resource.Ref())
+		if err := checkPayloadSize(change.Resource, opts); err != nil {
+			return err
+		}
+		if err := handler.Add(change.Resource); err != nil {
+			return err
+		}
+	case ResourceUpdated:
+		log.Debugf("`%s` was found, updating it...", resource.Ref())
+		if err := updateResource(handler, change, opts); err != nil {
+			return err
+		}
+	}
+	if opts.FieldManager != nil {
+		opts.FieldManager.Record(change.Local)
+	}
+	if opts.Backup != nil {
+		opts.Backup.record(change)
+	}
+
+	trailRecorder.Record(Event{
+		Type:        change.Type,
+		ResourceRef: resourceRef,
+	})
+	return nil
+}
+
+// dryRunResource checks a change without making it, and returns the event
+// reporting it. Resources are checked against the schema of their kind, their
+// size, then by their remote endpoint when their handler can validate them.
+func dryRunResource(handler Handler, change resourceChange, opts ApplyOptions) (EventType, error) {
+	var eventType EventType
+	switch change.Type {
+	case ResourceAdded:
+		eventType = ResourceWouldBeAdded
+	case ResourceUpdated:
+		eventType = ResourceWouldBeUpdated
+	default:
+		return change.Type, nil
+	}
+
+	if validator, ok := handler.(SchemaValidator); ok {
+		if err := validator.ValidateSchema(change.Local); err != nil {
+			return eventType, err
+		}
+	}
+	if err := checkPayloadSize(change.Resource, opts); err != nil {
+		return eventType, err
+	}
+	if validator, ok := handler.(RemoteValidator); ok {
+		log.Debugf("Validating `%s` remotely", change.Resource.Ref())
+		if err := validator.ValidateRemote(change.Remote, change.Resource); err != nil {
+			return eventType, err
+		}
+	}
+	return eventType, nil
+}
+
+// updateResource pushes the update of a resource, with the lighter-weight
+// calls of its handler when only its metadata changed
+func updateResource(handler Handler, change resourceChange, opts ApplyOptions) error {
+	if updater, ok := handler.(MetadataUpdater); ok && sameSpec(change.Resource, *change.Remote) {
+		updated, err := updater.UpdateMetadata(*change.Remote, change.Resource)
+		if err != nil {
+			return err
+		}
+		if updated {
+			log.Debugf("Only the metadata of `%s` changed, updated it without pushing its spec", change.Resource.Ref())
+			return nil
+		}
+	}
+
+	if err := checkPayloadSize(change.Resource, opts); err != nil {
 		return err
 	}
+	return handler.Update(*change.Remote, change.Resource)
+}
 
-	log.Debugf("`%s` was found, updating it...", resource.Ref())
+// sameSpec returns whether two resources have the same spec
+func sameSpec(resource, other Resource) bool {
+	spec, err := json.Marshal(resource.Spec())
+	if err != nil {
+		return false
+	}
+	otherSpec, err := json.Marshal(other.Spec())
+	if err != nil {
+		return false
+	}
+	return string(spec) == string(otherSpec)
+}
+
+// resourceChange is the change applying a resource makes
+type resourceChange struct {
+	// Type is one of ResourceAdded, ResourceUpdated or ResourceNotChanged
+	Type EventType
+	// Local is the resource as parsed, before fields are merged
+	Local Resource
+	// Resource is the prepared resource to add or update
+	Resource Resource
+	// Remote is the existing resource, for updates
+	Remote *Resource
+}
+
+// planResource compares a resource to its remote version, and returns the
+// change applying it makes
+func planResource(registry Registry, handler Handler, resource Resource, opts ApplyOptions) (resourceChange, error) {
+	resource, err := ExposeDescription(registry, resource)
+	if err != nil {
+		return resourceChange{}, err
+	}
+	resource, err = ExposeAnnotations(registry, resource)
+	if err != nil {
+		return resourceChange{}, err
+	}
+	resource, err = ExpandResource(registry, resource)
+	if err != nil {
+		return resourceChange{}, err
+	}
+	resource = withoutDependencies(resource)
+
+	log.Debugf("Getting the remote value for `%s`", resource.Ref())
+	existingResource, err := handler.GetRemote(resource)
+	if errors.Is(err, ErrNotFound) {
+		return resourceChange{Type: ResourceAdded, Local: resource, Resource: *handler.Prepare(nil, resource)}, nil
+	}
+	if err != nil {
+		return resourceChange{}, err
+	}
+
+	local := resource
+	if opts.FieldManager != nil {
+		remote := Resource{Body: map[string]any{}}
+		for key, value := range existingResource.Body {
+			remote.Body[key] = value
+		}
+		remote.SetSpec(NormaliseJSON(existingResource.Spec()))
+
+		merged, err := opts.FieldManager.Merge(resource, *handler.Unprepare(remote))
+		if err != nil {
+			return resourceChange{}, err
+		}
+		resource = *merged
+	}
 
 	resourceRepresentation, err := resource.YAML()
 	if err != nil {
-		return err
+		return resourceChange{}, err
 	}
 
 	resource = *handler.Prepare(existingResource, resource)
 	existingResource = handler.Unprepare(*existingResource)
 	existingResourceRepresentation, err := existingResource.YAML()
 	if err != nil {
-		return err
+		return resourceChange{}, err
 	}
 
+	change := resourceChange{Type: ResourceUpdated, Local: local, Resource: resource, Remote: existingResource}
 	if resourceRepresentation == existingResourceRepresentation {
-		trailRecorder.Record(Event{
-			Type:        ResourceNotChanged,
-			ResourceRef: resourceRef,
-		})
+		change.Type = ResourceNotChanged
+	}
+	return change, nil
+}
+
+// checkPayloadSize warns about, or refuses, resources too large to be accepted
+// by the gateways commonly deployed in front of the remote endpoints.
+func checkPayloadSize(resource Resource, opts ApplyOptions) error {
+	if opts.PayloadSizeWarning <= 0 && opts.MaxPayloadSize <= 0 {
 		return nil
 	}
 
-	if err = handler.Update(*existingResource, resource); err != nil {
+	payload, err := json.Marshal(resource.Spec())
+	if err != nil {
+		return err
+	}
+	size := int64(len(payload))
+
+	if opts.MaxPayloadSize > 0 && size > opts.MaxPayloadSize {
+		return fmt.Errorf("payload of %d bytes exceeds the maximum size of %d bytes", size, opts.MaxPayloadSize)
+	}
+	if opts.PayloadSizeWarning > 0 && size > opts.PayloadSizeWarning {
+		notifier.Warn(resource, fmt.Sprintf("payload of %d bytes exceeds %d bytes and may be rejected by the remote endpoint. Use --max-size to refuse such resources, or raise the max-payload-size of the context to silence this warning", size, opts.PayloadSizeWarning))
+	}
+	return nil
+}
+
+// Delete removes resources from their endpoints, for the kinds supporting it
+func Delete(registry Registry, resources Resources, continueOnError bool, eventsRecorder EventsRecorder) error {
+	var finalErr error
+
+	for _, resource := range resources.AsList() {
+		handler, err := registry.GetHandler(resource.Kind())
+		if err == nil {
+			err = deleteResource(handler, resource, eventsRecorder)
+		}
+		if err != nil {
+			err = ClassifyError(handler, err)
+			finalErr = multierror.Append(finalErr, err)
+
+			eventsRecorder.Record(NewFailureEvent(resource.Ref().String(), err.Error(), err))
+
+			if !continueOnError {
+				return finalErr
+			}
+		}
+	}
+
+	return finalErr
+}
+
+func deleteResource(handler Handler, resource Resource, trailRecorder EventsRecorder) error {
+	deleteHandler, ok := handler.(DeleteHandler)
+	if !ok {
+		return fmt.Errorf("kind %s does not support delete", handler.Kind())
+	}
+
+	log.Debugf("Checking that `%s` exists remotely", resource.Ref())
+	if _, err := handler.GetRemote(resource); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			trailRecorder.Record(Event{
+				Type:        ResourceNotFound,
+				ResourceRef: resource.Ref().String(),
+			})
+			return nil
+		}
+		return err
+	}
+
+	if err := deleteHandler.Delete(resource); err != nil {
 		return err
 	}
 
 	trailRecorder.Record(Event{
-		Type:        ResourceUpdated,
-		ResourceRef: resourceRef,
+		Type:        ResourceDeleted,
+		ResourceRef: resource.Ref().String(),
 	})
-
 	return nil
 }
 
@@ -492,14 +1073,21 @@ func Snapshot(registry Registry, resources Resources, expiresSeconds int) error
 
 // Watch watches a directory for changes then pushes Jsonnet resource to endpoints
 // when changes are noticed.
-func Watch(registry Registry, watchDir string, resourcePath string, parser Parser, parserOpts ParserOptions, trailRecorder EventsRecorder) error {
+// WatchOptions configures how resources are applied when watched files change
+type WatchOptions struct {
+	// Debounce is how long to wait for changes to settle before applying
+	Debounce time.Duration
+	Apply    ApplyOptions
+}
+
+func Watch(registry Registry, watchDir string, resourcePath string, parser Parser, parserOpts ParserOptions, opts WatchOptions, trailRecorder EventsRecorder) error {
 	updateWatchedResource := func(path string) error {
 		log.Infof("Changes detected in %q. Applying %q", path, resourcePath)
 		resources, err := parser.Parse(resourcePath, parserOpts)
 		if err != nil {
 			log.Error("Error parsing resource file: ", err)
 		}
-		err = Apply(registry, resources, false, trailRecorder) // TODO?
+		err = Apply(registry, resources, opts.Apply, trailRecorder)
 		if err != nil {
 			log.Error("Error applying resources: ", err)
 		}
@@ -509,6 +1097,7 @@ func Watch(registry Registry, watchDir string, resourcePath string, parser Parse
 	if err != nil {
 		return err
 	}
+	watcher.Debounce = opts.Debounce
 	err = watcher.Add(watchDir)
 	if err != nil {
 		return err
@@ -572,6 +1161,45 @@ func Export(registry Registry, exportDir string, resources Resources, onlySpec b
 	return nil
 }
 
+// WriteResources writes resources converted from the configuration of other
+// tools to the files pull would write them to, so that they can be reviewed
+// and applied
+func WriteResources(registry Registry, resourcePath string, resources Resources, outputFormat string) error {
+	resourcePathIsFile, err := isFile(resourcePath)
+	if err != nil {
+		return err
+	}
+	if resourcePathIsFile {
+		return fmt.Errorf("<resource-path> must be a directory")
+	}
+
+	for _, resource := range resources.AsList() {
+		content, filename, _, err := Format(registry, resourcePath, &resource, outputFormat, false)
+		if err != nil {
+			return err
+		}
+
+		existing, err := os.ReadFile(filename)
+		isNotExist := os.IsNotExist(err)
+		if err != nil && !isNotExist {
+			return err
+		}
+		if string(existing) == string(content) {
+			notifier.NoChanges(resource)
+			continue
+		}
+		if err := WriteFile(filename, content); err != nil {
+			return err
+		}
+		if isNotExist {
+			notifier.Added(resource)
+		} else {
+			notifier.Updated(resource)
+		}
+	}
+	return nil
+}
+
 func isFile(resourcePath string) (bool, error) {
 	stat, err := os.Stat(resourcePath)
 	if err != nil {
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/grafana/grizzly/internal/httputils"
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/mimir/models"
+	"github.com/hashicorp/go-multierror"
+	"gopkg.in/yaml.v3"
+)
+
+var loadRulesEndpoint = "%s/loki/api/v1/rules/%s"
+var listRulesEndpoint = "%s/prometheus/api/v1/rules"
+var deleteRulesEndpoint = "%s/loki/api/v1/rules/%s/%s"
+var formatQueryEndpoint = "%s/loki/api/v1/format_query?query=%s"
+
+type ListGroupResponse struct {
+	Status string `yaml:"status"`
+	Data   struct {
+		DataGroups []DataGroups `yaml:"groups"`
+	} `yaml:"data"`
+}
+
+type DataGroups struct {
+	Name  string        `yaml:"name"`
+	File  string        `yaml:"file"`
+	Rules []interface{} `yaml:"rules"`
+}
+
+// StatusError is returned when Loki responds with an unsuccessful status
+type StatusError = httputils.StatusError
+
+type Client struct {
+	config    *config.LokiConfig
+	transport http.RoundTripper
+}
+
+var _ QueryValidator = &Client{}
+
+func NewHTTPClient(config *config.LokiConfig) Loki {
+	return &Client{config: config}
+}
+
+// NewHTTPClientWithTransport returns a client sending requests through the
+// given transport
+func NewHTTPClientWithTransport(config *config.LokiConfig, transport http.RoundTripper) Loki {
+	return &Client{config: config, transport: transport}
+}
+
+func (c *Client) ListRules() (map[string][]models.PrometheusRuleGroup, error) {
+	url := fmt.Sprintf(listRulesEndpoint, c.config.Address)
+	res, err := c.doRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response ListGroupResponse
+	if err := yaml.Unmarshal(res, &response); err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string][]models.PrometheusRuleGroup)
+	for _, g := range response.Data.DataGroups {
+		groups[g.File] = append(groups[g.File], models.PrometheusRuleGroup{
+			Name:  g.Name,
+			Rules: g.Rules,
+		})
+	}
+
+	return groups, nil
+}
+
+func (c *Client) CreateRules(resource models.PrometheusRuleGrouping) error {
+	endpoint := fmt.Sprintf(loadRulesEndpoint, c.config.Address, url.PathEscape(resource.Namespace))
+	for _, group := range resource.Groups {
+		out, err := yaml.Marshal(group)
+		if err != nil {
+			return fmt.Errorf("cannot marshall groups: %s", err)
+		}
+
+		if _, err = c.doRequest(http.MethodPost, endpoint, out); err != nil {
+			return multierror.Append(fmt.Errorf("error found creating rule group: %s", group.Name), err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) DeleteRules(namespace, group string) error {
+	endpoint := fmt.Sprintf(deleteRulesEndpoint, c.config.Address, url.PathEscape(namespace), url.PathEscape(group))
+	if _, err := c.doRequest(http.MethodDelete, endpoint, nil); err != nil {
+		return fmt.Errorf("error found deleting rule group %s: %w", group, err)
+	}
+
+	return nil
+}
+
+// ValidateQuery checks the syntax of a LogQL expression by having Loki
+// format it, which parses the expression without evaluating it
+func (c *Client) ValidateQuery(query string) error {
+	endpoint := fmt.Sprintf(formatQueryEndpoint, c.config.Address, url.QueryEscape(query))
+	_, err := c.doRequest(http.MethodGet, endpoint, nil)
+	var statusErr StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusBadRequest {
+		// Loki reports parse errors as plain text, or as JSON behind some gateways
+		var response struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal([]byte(statusErr.Body), &response) == nil && response.Error != "" {
+			return errors.New(response.Error)
+		}
+		if statusErr.Body != "" {
+			return errors.New(statusErr.Body)
+		}
+	}
+	return err
+}
+
+func (c *Client) doRequest(method string, url string, body []byte) ([]byte, error) {
+	if c.config.TenantID == "" {
+		return nil, errors.New("missing tenant-id")
+	}
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/yaml")
+	switch {
+	case c.config.APIKey != "":
+		req.SetBasicAuth(c.config.TenantID, c.config.APIKey)
+	case c.config.AuthToken != "":
+		req.Header.Set("Authorization", "Bearer "+c.config.AuthToken)
+	default:
+		req.Header.Set("X-Scope-OrgID", c.config.TenantID)
+	}
+
+	client, err := httputils.NewHTTPClientWithTransport(c.transport)
+	if err != nil {
+		return nil, err
+	}
+
+	return httputils.Do(client, req, "Loki")
+}
@@ -0,0 +1,19 @@
+package client
+
+import (
+	"github.com/grafana/grizzly/pkg/mimir/models"
+)
+
+// Loki is a client of the ruler API of Loki, which shares the format of its
+// rule groups with Prometheus
+type Loki interface {
+	ListRules() (map[string][]models.PrometheusRuleGroup, error)
+	CreateRules(resource models.PrometheusRuleGrouping) error
+	DeleteRules(namespace, group string) error
+}
+
+// QueryValidator describes a client that can check the syntax of LogQL
+// expressions without evaluating them
+type QueryValidator interface {
+	ValidateQuery(query string) error
+}
@@ -0,0 +1,95 @@
+package loki
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/loki/client"
+)
+
+// Provider is a grizzly.Provider implementation for Loki.
+type Provider struct {
+	config     *config.LokiConfig
+	clientTool client.Loki
+}
+
+// NewProvider instantiates a new Provider.
+func NewProvider(config *config.LokiConfig) *Provider {
+	clientTool := client.NewHTTPClient(config)
+	return &Provider{
+		config:     config,
+		clientTool: clientTool,
+	}
+}
+
+// WithTransport sends the requests of the provider through a transport of
+// its own, such as one signing requests or recording them
+func (p *Provider) WithTransport(transport http.RoundTripper) *Provider {
+	p.clientTool = client.NewHTTPClientWithTransport(p.config, transport)
+	return p
+}
+
+func (p *Provider) Validate() error {
+	if p.config.Address == "" {
+		return fmt.Errorf("loki address is not set")
+	}
+	if p.config.TenantID == "" {
+		return fmt.Errorf("loki tenant id is not set")
+	}
+	return nil
+}
+
+func (p *Provider) Status() grizzly.ProviderStatus {
+	status := grizzly.ProviderStatus{}
+
+	if err := p.Validate(); err != nil {
+		status.ActiveReason = err.Error()
+		return status
+	}
+
+	status.Active = true
+
+	if _, err := p.clientTool.ListRules(); err != nil {
+		status.OnlineReason = err.Error()
+		return status
+	}
+
+	status.Online = true
+
+	return status
+}
+
+// CredentialsExpiry implements grizzly.CredentialsExpiryProvider, for tokens that are JWTs
+func (p *Provider) CredentialsExpiry() (time.Time, bool) {
+	return grizzly.TokenExpiry(p.config.AuthToken)
+}
+
+func (p *Provider) Name() string {
+	return "Loki"
+}
+
+// Group returns the group name of the Loki provider
+func (p *Provider) Group() string {
+	return "grizzly.grafana.com"
+}
+
+// Version returns the version of this provider
+func (p *Provider) Version() string {
+	return "v1alpha1"
+}
+
+// APIVersion returns the group and version of this provider
+func (p *Provider) APIVersion() string {
+	return filepath.Join(p.Group(), p.Version())
+}
+
+// GetHandlers identifies the handlers for the Loki provider
+func (p *Provider) GetHandlers() []grizzly.Handler {
+	return []grizzly.Handler{
+		NewRuleHandler(p, p.clientTool),
+	}
+}
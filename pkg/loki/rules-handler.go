@@ -0,0 +1,350 @@
+package loki
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/loki/client"
+	"github.com/grafana/grizzly/pkg/mimir/models"
+)
+
+const LokiRuleGroupKind = "LokiRuleGroup"
+
+var _ grizzly.Handler = &RuleHandler{}
+var _ grizzly.RuleStateHandler = &RuleHandler{}
+var _ grizzly.QueryHandler = &RuleHandler{}
+var _ grizzly.MetadataInferrer = &RuleHandler{}
+var _ grizzly.RuleGroupHandler = &RuleHandler{}
+var _ grizzly.RemoteValidator = &RuleHandler{}
+
+// RuleHandler is a Grizzly Handler for Loki recording and alerting rules
+type RuleHandler struct {
+	grizzly.BaseHandler
+	clientTool client.Loki
+}
+
+// NewRuleHandler returns a new Grizzly Handler for Loki rules
+func NewRuleHandler(provider *Provider, clientTool client.Loki) *RuleHandler {
+	return &RuleHandler{
+		BaseHandler: grizzly.NewBaseHandler(provider, LokiRuleGroupKind, false).WithListCache(),
+		clientTool:  clientTool,
+	}
+}
+
+const (
+	lokiRuleGroupPattern           = "loki/rules-%s.%s"
+	lokiNamespacedRuleGroupPattern = "loki/rules/%s/%s.%s"
+)
+
+// ErrorHint implements grizzly.ErrorHinter
+func (h *RuleHandler) ErrorHint(code grizzly.ErrorCode) string {
+	switch code {
+	case grizzly.ErrorCodeAuthFailed:
+		return "check loki.tenant-id and loki.api-key (or loki.auth-token) with `grr config check`"
+	case grizzly.ErrorCodePermissionDenied:
+		return "the credentials lack the rules:read or rules:write permissions for the tenant"
+	case grizzly.ErrorCodeVersionUnsupported:
+		return "the ruler API wasn't found, check that loki.address points to a Loki instance with the ruler enabled"
+	case grizzly.ErrorCodeQuotaExceeded:
+		return "a ruler limit of the tenant was reached (rule groups per tenant, or rules per group): split or remove rule groups, or ask for the limits to be raised"
+	default:
+		return ""
+	}
+}
+
+// ResourceFilePath returns the location on disk where a resource should be
+// updated. Rule groups are written to one directory per namespace, so that
+// the namespace can be inferred back from the directory.
+func (h *RuleHandler) ResourceFilePath(resource grizzly.Resource, filetype string) string {
+	filename := strings.ReplaceAll(resource.Name(), string(os.PathSeparator), "-")
+	namespace := resource.GetMetadata("namespace")
+	if namespace == "" {
+		return fmt.Sprintf(lokiRuleGroupPattern, filename, filetype)
+	}
+	dirname := strings.ReplaceAll(namespace, string(os.PathSeparator), "-")
+	return fmt.Sprintf(lokiNamespacedRuleGroupPattern, dirname, filename, filetype)
+}
+
+// InferMetadata implements grizzly.MetadataInferrer. Rule groups without a
+// namespace take the name of the directory they were read from.
+func (h *RuleHandler) InferMetadata(resource *grizzly.Resource) {
+	if resource.GetMetadata("namespace") != "" {
+		return
+	}
+	dir := filepath.Base(filepath.Dir(resource.Source.Path))
+	if dir == "." || dir == string(os.PathSeparator) {
+		return
+	}
+	resource.SetMetadata("namespace", dir)
+}
+
+// Validate returns the uid of resource
+func (h *RuleHandler) Validate(resource grizzly.Resource) error {
+	uid, exist := resource.GetSpecString("uid")
+	if exist && uid != resource.Name() {
+		return fmt.Errorf("uid '%s' and name '%s', don't match", uid, resource.Name())
+	}
+	return nil
+}
+
+// EvaluationInterval implements grizzly.RuleGroupHandler. Groups without an
+// interval are evaluated at the default interval of the ruler.
+func (h *RuleHandler) EvaluationInterval(resource grizzly.Resource) (time.Duration, bool, error) {
+	interval, ok := resource.GetSpecString("interval")
+	if !ok || interval == "" {
+		return 0, false, nil
+	}
+	duration, err := time.ParseDuration(interval)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid interval %s: %w", interval, err)
+	}
+	return duration, true, nil
+}
+
+// GetUID returns the UID for a resource
+func (h *RuleHandler) GetUID(resource grizzly.Resource) (string, error) {
+	if !resource.HasMetadata("namespace") {
+		return "", fmt.Errorf("%s %s requires a namespace metadata entry", h.Kind(), resource.Name())
+	}
+	return fmt.Sprintf("%s.%s", resource.GetMetadata("namespace"), resource.Name()), nil
+}
+
+func (h *RuleHandler) GetSpecUID(resource grizzly.Resource) (string, error) {
+	return "", fmt.Errorf("GetSpecUID not implemented for loki rules")
+}
+
+// GetByUID retrieves JSON for a resource from an endpoint, by UID
+func (h *RuleHandler) GetByUID(uid string) (*grizzly.Resource, error) {
+	return h.getRemoteRuleGroup(uid)
+}
+
+// GetRemote retrieves a rule group as a Resource
+func (h *RuleHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
+	uid := fmt.Sprintf("%s.%s", resource.GetMetadata("namespace"), resource.Name())
+	return h.getRemoteRuleGroup(uid)
+}
+
+// ListRemote retrieves as list of UIDs of all remote resources
+func (h *RuleHandler) ListRemote() ([]string, error) {
+	return h.getRemoteRuleGroupList()
+}
+
+// Add pushes a rule group to Loki via the API
+func (h *RuleHandler) Add(resource grizzly.Resource) error {
+	return h.writeRuleGroup(resource)
+}
+
+// Update pushes a rule group to Loki via the API
+func (h *RuleHandler) Update(existing, resource grizzly.Resource) error {
+	return h.writeRuleGroup(resource)
+}
+
+// ValidateRemote implements grizzly.RemoteValidator. The ruler API can't
+// check rule groups without loading them, so the expressions of their rules
+// are checked by the query API instead.
+func (h *RuleHandler) ValidateRemote(existing *grizzly.Resource, resource grizzly.Resource) error {
+	if _, _, err := h.EvaluationInterval(resource); err != nil {
+		return err
+	}
+	validator, ok := h.clientTool.(client.QueryValidator)
+	if !ok {
+		return nil
+	}
+
+	var problems []string
+	for _, rule := range ruleExpressions(resource) {
+		if err := validator.ValidateQuery(rule.expr); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", rule.name, err))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid expressions:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// Delete removes a rule group from Loki
+func (h *RuleHandler) Delete(resource grizzly.Resource) error {
+	if err := h.clientTool.DeleteRules(resource.GetMetadata("namespace"), resource.Name()); err != nil {
+		return err
+	}
+	h.InvalidateListCache()
+	return nil
+}
+
+// RuleStates implements grizzly.RuleStateHandler. The rules listed by the
+// ruler include the state of their last evaluation, which isn't cached as it
+// changes as the rules are evaluated.
+func (h *RuleHandler) RuleStates(resource grizzly.Resource) ([]grizzly.RuleState, error) {
+	h.InvalidateListCache()
+	remote, err := h.GetRemote(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, _ := remote.Spec()["rules"].([]interface{})
+	states := make([]grizzly.RuleState, 0, len(rules))
+	for _, ruleIf := range rules {
+		rule, _ := ruleIf.(map[string]interface{})
+		state := grizzly.RuleState{}
+		for _, key := range []string{"name", "alert", "record"} {
+			if name, ok := rule[key].(string); ok {
+				state.Rule = name
+				break
+			}
+		}
+		state.State, _ = rule["state"].(string)
+		state.Health, _ = rule["health"].(string)
+		state.LastError, _ = rule["lastError"].(string)
+		switch lastEvaluation := rule["lastEvaluation"].(type) {
+		case time.Time:
+			state.LastEvaluation = lastEvaluation
+		case string:
+			state.LastEvaluation, _ = time.Parse(time.RFC3339Nano, lastEvaluation)
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// getRemoteRuleGroup retrieves a rule group from Loki
+func (h *RuleHandler) getRemoteRuleGroup(uid string) (*grizzly.Resource, error) {
+	namespace, name, ok := strings.Cut(uid, ".")
+	if !ok {
+		return nil, fmt.Errorf("invalid UID %s, expected <namespace>.<group>", uid)
+	}
+
+	groupings, err := h.listRules()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, group := range groupings[namespace] {
+		if group.Name != name {
+			continue
+		}
+		spec := map[string]interface{}{
+			"rules": group.Rules,
+		}
+		resource, err := grizzly.NewResource(h.APIVersion(), h.Kind(), group.Name, spec)
+		if err != nil {
+			return nil, err
+		}
+		resource.SetMetadata("namespace", namespace)
+		// the rules are shared with the cached list
+		resource = resource.DeepCopy()
+		return &resource, nil
+	}
+	return nil, grizzly.ErrNotFound
+}
+
+// getRemoteRuleGroupList retrieves the UIDs of all the rule groups of Loki
+func (h *RuleHandler) getRemoteRuleGroupList() ([]string, error) {
+	groupings, err := h.listRules()
+	if err != nil {
+		return nil, err
+	}
+
+	var IDs []string
+	for namespace, grouping := range groupings {
+		for _, group := range grouping {
+			IDs = append(IDs, fmt.Sprintf("%s.%s", namespace, group.Name))
+		}
+	}
+	return IDs, nil
+}
+
+// writeRuleGroup loads a rule group into the ruler. Rules read back from the
+// ruler API are named with name, typed with type and have their expression in
+// query, which the ruler only accepts as alert or record, and expr.
+func (h *RuleHandler) writeRuleGroup(resource grizzly.Resource) error {
+	newGroup := models.PrometheusRuleGroup{
+		Name:  resource.Name(),
+		Rules: []interface{}{},
+	}
+	rules, _ := resource.Spec()["rules"].([]interface{})
+	for _, ruleIf := range rules {
+		rule, ok := ruleIf.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("rules of %s must be objects", resource.Name())
+		}
+		switch rule["type"] {
+		case "recording":
+			rule["record"] = rule["name"]
+			delete(rule, "name")
+		case "alerting":
+			rule["alert"] = rule["name"]
+			delete(rule, "name")
+		}
+		if rule["query"] != nil {
+			rule["expr"] = rule["query"]
+			delete(rule, "query")
+		}
+		newGroup.Rules = append(newGroup.Rules, rule)
+	}
+	grouping := models.PrometheusRuleGrouping{
+		Namespace: resource.GetMetadata("namespace"),
+		Groups:    []models.PrometheusRuleGroup{newGroup},
+	}
+
+	if err := h.clientTool.CreateRules(grouping); err != nil {
+		return err
+	}
+	h.InvalidateListCache()
+	return nil
+}
+
+// listRules retrieves all the rule groups of the tenant. The ruler API can't
+// retrieve a single group by name, so the list is cached for the run.
+func (h *RuleHandler) listRules() (map[string][]models.PrometheusRuleGroup, error) {
+	return grizzly.CachedList(&h.BaseHandler, "rules", h.clientTool.ListRules)
+}
+
+// Queries implements grizzly.QueryHandler, listing the LogQL expressions of
+// the rules of a group
+func (h *RuleHandler) Queries(resource grizzly.Resource) []grizzly.Query {
+	var queries []grizzly.Query
+	for _, rule := range ruleExpressions(resource) {
+		queries = append(queries, grizzly.Query{
+			Pointer:  fmt.Sprintf("/rules/%d/expr", rule.index),
+			Language: grizzly.QueryLanguageLogQL,
+			Name:     rule.name,
+		})
+	}
+	return queries
+}
+
+// ruleExpression is the expression of a rule of a group
+type ruleExpression struct {
+	index int
+	// name identifies the rule within its group. Ex: HighErrorRate-2
+	name string
+	expr string
+}
+
+func ruleExpressions(resource grizzly.Resource) []ruleExpression {
+	var expressions []ruleExpression
+
+	rules, _ := resource.Spec()["rules"].([]interface{})
+	for i, ruleIf := range rules {
+		rule, _ := ruleIf.(map[string]interface{})
+		expr, ok := rule["expr"].(string)
+		if !ok || strings.TrimSpace(expr) == "" {
+			continue
+		}
+
+		name := fmt.Sprintf("rule-%d", i)
+		for _, key := range []string{"alert", "record"} {
+			if ruleName, ok := rule[key].(string); ok && ruleName != "" {
+				name = fmt.Sprintf("%s-%d", ruleName, i)
+				break
+			}
+		}
+		expressions = append(expressions, ruleExpression{index: i, name: name, expr: expr})
+	}
+	return expressions
+}
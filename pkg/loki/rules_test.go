@@ -0,0 +1,180 @@
+package loki
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/loki/client"
+	"github.com/grafana/grizzly/pkg/mimir/models"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// newRulerTestServer fakes the ruler API of Loki for a tenant, keeping the
+// rule groups loaded into it by namespace
+func newRulerTestServer(t *testing.T) *httptest.Server {
+	var mu sync.Mutex
+	namespaces := map[string][]models.PrometheusRuleGroup{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get("X-Scope-OrgID") != "tenant" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		path, ok := strings.CutPrefix(r.URL.Path, "/loki/api/v1/rules/")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/prometheus/api/v1/rules":
+			var groups []map[string]any
+			for namespace, nsGroups := range namespaces {
+				for _, group := range nsGroups {
+					groups = append(groups, map[string]any{"name": group.Name, "file": namespace, "rules": group.Rules})
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "success", "data": map[string]any{"groups": groups}})
+		case r.Method == http.MethodPost && ok:
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			var group models.PrometheusRuleGroup
+			require.NoError(t, yaml.Unmarshal(body, &group))
+			groups := namespaces[path]
+			for i := range groups {
+				if groups[i].Name == group.Name {
+					groups = append(groups[:i], groups[i+1:]...)
+					break
+				}
+			}
+			namespaces[path] = append(groups, group)
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodDelete && ok:
+			namespace, name, _ := strings.Cut(path, "/")
+			groups := namespaces[namespace]
+			for i := range groups {
+				if groups[i].Name == name {
+					namespaces[namespace] = append(groups[:i], groups[i+1:]...)
+					break
+				}
+			}
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRuleHandler(t *testing.T) {
+	server := newRulerTestServer(t)
+	provider := NewProvider(&config.LokiConfig{Address: server.URL, TenantID: "tenant"})
+	h := NewRuleHandler(provider, provider.clientTool)
+
+	group := func(expr string) grizzly.Resource {
+		resource, err := grizzly.NewResource(provider.APIVersion(), LokiRuleGroupKind, "errors", map[string]any{
+			"rules": []any{
+				map[string]any{"alert": "HighErrorRate", "expr": expr},
+			},
+		})
+		require.NoError(t, err)
+		resource.SetMetadata("namespace", "team-a")
+		return resource
+	}
+
+	t.Run("missing rule groups aren't found", func(t *testing.T) {
+		_, err := h.GetByUID("team-a.errors")
+		require.ErrorIs(t, err, grizzly.ErrNotFound)
+	})
+
+	t.Run("rule groups are added", func(t *testing.T) {
+		resource := group(`sum(rate({app="api"} |= "error" [5m])) > 10`)
+		require.NoError(t, h.Add(resource))
+
+		uids, err := h.ListRemote()
+		require.NoError(t, err)
+		require.Equal(t, []string{"team-a.errors"}, uids)
+
+		remote, err := h.GetRemote(resource)
+		require.NoError(t, err)
+		require.Equal(t, LokiRuleGroupKind, remote.Kind())
+		require.Equal(t, "team-a", remote.GetMetadata("namespace"))
+		require.Equal(t, resource.Spec(), remote.Spec())
+	})
+
+	t.Run("rule groups are updated", func(t *testing.T) {
+		resource := group(`sum(rate({app="api"} |= "error" [5m])) > 20`)
+		existing, err := h.GetRemote(resource)
+		require.NoError(t, err)
+		require.NoError(t, h.Update(*existing, resource))
+
+		remote, err := h.GetRemote(resource)
+		require.NoError(t, err)
+		require.Equal(t, resource.Spec(), remote.Spec())
+	})
+
+	t.Run("rule groups are deleted", func(t *testing.T) {
+		resource := group("")
+		require.NoError(t, h.Delete(resource))
+
+		_, err := h.GetRemote(resource)
+		require.ErrorIs(t, err, grizzly.ErrNotFound)
+	})
+
+	t.Run("rule groups need a namespace", func(t *testing.T) {
+		resource, err := grizzly.NewResource(provider.APIVersion(), LokiRuleGroupKind, "errors", map[string]any{})
+		require.NoError(t, err)
+		_, err = h.GetUID(resource)
+		require.ErrorContains(t, err, "requires a namespace")
+	})
+
+	t.Run("rule groups are written to a directory per namespace", func(t *testing.T) {
+		require.Equal(t, "loki/rules/team-a/errors.yaml", h.ResourceFilePath(group(""), "yaml"))
+	})
+}
+
+func TestRuleHandlerValidateRemote(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/loki/api/v1/format_query", r.URL.Path)
+		query := r.URL.Query().Get("query")
+		queries = append(queries, query)
+		if query == `sum(rate({app="api"}[5m])` {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("parse error at line 1, col 27: syntax error: unexpected $end"))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status": "success", "data": "formatted"}`))
+	}))
+	defer server.Close()
+
+	h := NewRuleHandler(&Provider{}, client.NewHTTPClient(&config.LokiConfig{Address: server.URL, TenantID: "tenant"}))
+	group := func(expr string) grizzly.Resource {
+		resource, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", LokiRuleGroupKind, "errors", map[string]any{
+			"rules": []any{
+				map[string]any{"record": "app:errors:rate5m", "expr": `sum(rate({app="api"} |= "error" [5m]))`},
+				map[string]any{"alert": "HighErrorRate", "expr": expr},
+			},
+		})
+		require.NoError(t, err)
+		resource.SetMetadata("namespace", "team-a")
+		return resource
+	}
+
+	require.NoError(t, h.ValidateRemote(nil, group(`sum(rate({app="api"}[5m])) > 10`)))
+	require.Equal(t, []string{`sum(rate({app="api"} |= "error" [5m]))`, `sum(rate({app="api"}[5m])) > 10`}, queries)
+
+	err := h.ValidateRemote(nil, group(`sum(rate({app="api"}[5m])`))
+	require.ErrorContains(t, err, "HighErrorRate-1: parse error at line 1, col 27")
+
+	ruleQueries := h.Queries(group("count_over_time({app=\"api\"}[1m])"))
+	require.Len(t, ruleQueries, 2)
+	require.Equal(t, grizzly.QueryLanguageLogQL, ruleQueries[1].Language)
+}
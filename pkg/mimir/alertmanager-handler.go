@@ -0,0 +1,186 @@
+package mimir
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/mimir/client"
+	"github.com/grafana/grizzly/pkg/mimir/models"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	AlertmanagerConfigKind = "AlertmanagerConfig"
+	// GlobalAlertmanagerConfigName is the name of the only Alertmanager
+	// configuration of a tenant
+	GlobalAlertmanagerConfigName = "global"
+)
+
+const alertmanagerConfigFile = "prometheus/alertmanager.yaml"
+
+var _ grizzly.Handler = &AlertmanagerConfigHandler{}
+
+// AlertmanagerConfigHandler is a Grizzly Handler for the Alertmanager
+// configuration of a Mimir tenant
+type AlertmanagerConfigHandler struct {
+	grizzly.BaseHandler
+	clientTool client.Mimir
+}
+
+// NewAlertmanagerConfigHandler returns a new Grizzly Handler for the
+// Alertmanager configuration of a Mimir tenant
+func NewAlertmanagerConfigHandler(provider *Provider, clientTool client.Mimir) *AlertmanagerConfigHandler {
+	return &AlertmanagerConfigHandler{
+		BaseHandler: grizzly.NewBaseHandler(provider, AlertmanagerConfigKind, false),
+		clientTool:  clientTool,
+	}
+}
+
+// ErrorHint implements grizzly.ErrorHinter
+func (h *AlertmanagerConfigHandler) ErrorHint(code grizzly.ErrorCode) string {
+	switch code {
+	case grizzly.ErrorCodeAuthFailed:
+		return "check mimir.tenant-id and mimir.api-key (or mimir.auth-token) with `grr config check`"
+	case grizzly.ErrorCodePermissionDenied:
+		return "the credentials lack the alerts:read or alerts:write permissions for the tenant"
+	case grizzly.ErrorCodeVersionUnsupported:
+		return "the Alertmanager API wasn't found, check that mimir.alertmanager-address (or mimir.address) points to a Mimir instance with the Alertmanager enabled"
+	default:
+		return ""
+	}
+}
+
+// ResourceFilePath returns the location on disk where a resource should be updated
+func (h *AlertmanagerConfigHandler) ResourceFilePath(resource grizzly.Resource, filetype string) string {
+	return alertmanagerConfigFile
+}
+
+// Validate checks that the configuration is the global one, and that the
+// Alertmanager configuration is an object rather than a YAML string
+func (h *AlertmanagerConfigHandler) Validate(resource grizzly.Resource) error {
+	if resource.Name() != GlobalAlertmanagerConfigName {
+		return fmt.Errorf("name of alertmanager configuration must be '%s', got '%s'", GlobalAlertmanagerConfigName, resource.Name())
+	}
+	if _, ok := resource.GetSpecValue("alertmanager_config").(map[string]any); !ok {
+		return fmt.Errorf("alertmanager_config of %s should be an object", resource.Ref())
+	}
+	return nil
+}
+
+func (h *AlertmanagerConfigHandler) GetSpecUID(resource grizzly.Resource) (string, error) {
+	return "", fmt.Errorf("GetSpecUID not implemented for alertmanager configurations")
+}
+
+// GetByUID retrieves JSON for a resource from an endpoint, by UID
+func (h *AlertmanagerConfigHandler) GetByUID(uid string) (*grizzly.Resource, error) {
+	return h.getRemoteAlertmanagerConfig()
+}
+
+// GetRemote retrieves the Alertmanager configuration as a Resource
+func (h *AlertmanagerConfigHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
+	return h.getRemoteAlertmanagerConfig()
+}
+
+// ListRemote retrieves as list of UIDs of all remote resources: the global
+// configuration, when the tenant has one
+func (h *AlertmanagerConfigHandler) ListRemote() ([]string, error) {
+	if _, err := h.getRemoteAlertmanagerConfig(); err != nil {
+		if errors.Is(err, grizzly.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return []string{GlobalAlertmanagerConfigName}, nil
+}
+
+// Add pushes the Alertmanager configuration to Mimir
+func (h *AlertmanagerConfigHandler) Add(resource grizzly.Resource) error {
+	return h.writeAlertmanagerConfig(resource)
+}
+
+// Update pushes the Alertmanager configuration to Mimir
+func (h *AlertmanagerConfigHandler) Update(existing, resource grizzly.Resource) error {
+	return h.writeAlertmanagerConfig(resource)
+}
+
+// Delete removes the Alertmanager configuration of the tenant
+func (h *AlertmanagerConfigHandler) Delete(resource grizzly.Resource) error {
+	amClient, err := h.alertmanagerClient()
+	if err != nil {
+		return err
+	}
+	return amClient.DeleteAlertmanagerConfig()
+}
+
+// getRemoteAlertmanagerConfig retrieves the Alertmanager configuration of
+// the tenant, decoding the configuration so that it's diffed field by field
+func (h *AlertmanagerConfigHandler) getRemoteAlertmanagerConfig() (*grizzly.Resource, error) {
+	amClient, err := h.alertmanagerClient()
+	if err != nil {
+		return nil, err
+	}
+	config, err := amClient.GetAlertmanagerConfig()
+	var statusErr client.StatusError
+	if errors.As(err, &statusErr) && statusErr.IsCode(http.StatusNotFound) {
+		return nil, grizzly.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	alertmanagerConfig := map[string]any{}
+	if err := yaml.Unmarshal([]byte(config.AlertmanagerConfig), &alertmanagerConfig); err != nil {
+		return nil, fmt.Errorf("cannot decode the alertmanager configuration: %w", err)
+	}
+	spec := map[string]any{
+		"alertmanager_config": alertmanagerConfig,
+	}
+	if len(config.TemplateFiles) > 0 {
+		templates := make(map[string]any, len(config.TemplateFiles))
+		for name, template := range config.TemplateFiles {
+			templates[name] = template
+		}
+		spec["template_files"] = templates
+	}
+
+	resource, err := grizzly.NewResource(h.APIVersion(), h.Kind(), GlobalAlertmanagerConfigName, spec)
+	if err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+func (h *AlertmanagerConfigHandler) writeAlertmanagerConfig(resource grizzly.Resource) error {
+	amClient, err := h.alertmanagerClient()
+	if err != nil {
+		return err
+	}
+
+	alertmanagerConfig, err := yaml.Marshal(resource.GetSpecValue("alertmanager_config"))
+	if err != nil {
+		return fmt.Errorf("cannot marshal the alertmanager configuration: %w", err)
+	}
+	config := models.AlertmanagerConfig{
+		AlertmanagerConfig: string(alertmanagerConfig),
+		TemplateFiles:      map[string]string{},
+	}
+	templates, _ := resource.GetSpecValue("template_files").(map[string]any)
+	for name, template := range templates {
+		content, ok := template.(string)
+		if !ok {
+			return fmt.Errorf("template %s of %s should be a string", name, resource.Ref())
+		}
+		config.TemplateFiles[name] = content
+	}
+	return amClient.SetAlertmanagerConfig(config)
+}
+
+func (h *AlertmanagerConfigHandler) alertmanagerClient() (client.AlertmanagerConfigClient, error) {
+	amClient, ok := h.clientTool.(client.AlertmanagerConfigClient)
+	if !ok {
+		return nil, fmt.Errorf("the mimir client can't manage alertmanager configurations")
+	}
+	return amClient, nil
+}
@@ -0,0 +1,98 @@
+package mimir
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/mimir/client"
+	"github.com/grafana/grizzly/pkg/mimir/models"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestAlertmanagerConfigHandler(t *testing.T) {
+	var stored *models.AlertmanagerConfig
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v1/alerts", r.URL.Path)
+		switch r.Method {
+		case http.MethodGet:
+			if stored == nil {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte("alertmanager storage object not found"))
+				return
+			}
+			out, err := yaml.Marshal(stored)
+			require.NoError(t, err)
+			_, _ = w.Write(out)
+		case http.MethodPost:
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			stored = &models.AlertmanagerConfig{}
+			require.NoError(t, yaml.Unmarshal(body, stored))
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodDelete:
+			stored = nil
+		}
+	}))
+	defer server.Close()
+
+	handler := NewAlertmanagerConfigHandler(&Provider{}, client.NewHTTPClient(&config.MimirConfig{AlertmanagerAddress: server.URL, TenantID: "tenant"}))
+	resource, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", AlertmanagerConfigKind, GlobalAlertmanagerConfigName, map[string]any{
+		"alertmanager_config": map[string]any{
+			"route":     map[string]any{"receiver": "team-a"},
+			"receivers": []any{map[string]any{"name": "team-a"}},
+			"templates": []any{"default.tmpl"},
+		},
+		"template_files": map[string]any{
+			"default.tmpl": `{{ define "title" }}{{ .CommonLabels.alertname }}{{ end }}`,
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("tenants without a configuration have none to list", func(t *testing.T) {
+		uids, err := handler.ListRemote()
+		require.NoError(t, err)
+		require.Empty(t, uids)
+
+		_, err = handler.GetRemote(resource)
+		require.ErrorIs(t, err, grizzly.ErrNotFound)
+	})
+
+	t.Run("configurations are written as YAML", func(t *testing.T) {
+		require.NoError(t, handler.Validate(resource))
+		require.NoError(t, handler.Add(resource))
+		require.Contains(t, stored.AlertmanagerConfig, "receiver: team-a")
+		require.Equal(t, map[string]string{"default.tmpl": `{{ define "title" }}{{ .CommonLabels.alertname }}{{ end }}`}, stored.TemplateFiles)
+	})
+
+	t.Run("configurations are read as objects", func(t *testing.T) {
+		uids, err := handler.ListRemote()
+		require.NoError(t, err)
+		require.Equal(t, []string{GlobalAlertmanagerConfigName}, uids)
+
+		remote, err := handler.GetRemote(resource)
+		require.NoError(t, err)
+		require.Equal(t, resource.Spec(), remote.Spec())
+	})
+
+	t.Run("configurations are deleted", func(t *testing.T) {
+		require.NoError(t, handler.Delete(resource))
+		require.Nil(t, stored)
+	})
+
+	t.Run("configurations must be global objects", func(t *testing.T) {
+		other, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", AlertmanagerConfigKind, "team-a", resource.Spec())
+		require.NoError(t, err)
+		require.EqualError(t, handler.Validate(other), "name of alertmanager configuration must be 'global', got 'team-a'")
+
+		raw, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", AlertmanagerConfigKind, GlobalAlertmanagerConfigName, map[string]any{
+			"alertmanager_config": "route:\n  receiver: team-a\n",
+		})
+		require.NoError(t, err)
+		require.EqualError(t, handler.Validate(raw), "alertmanager_config of AlertmanagerConfig.global should be an object")
+	})
+}
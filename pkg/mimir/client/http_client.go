@@ -4,22 +4,28 @@ import (
 	"bytes"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"net/url"
 	"os"
-	"strings"
 
 	"github.com/grafana/grizzly/internal/httputils"
 	"github.com/grafana/grizzly/pkg/config"
 	"github.com/grafana/grizzly/pkg/mimir/models"
 	"github.com/hashicorp/go-multierror"
+	log "github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
 )
 
 var loadRulesEndpoint = "%s/prometheus/config/v1/rules/%s"
 var listRulesEndpoint = "%s/prometheus/api/v1/rules"
+var listRuleConfigsEndpoint = "%s/prometheus/config/v1/rules"
+var deleteRulesEndpoint = "%s/prometheus/config/v1/rules/%s/%s"
+var allUserStatsEndpoint = "%s/distributor/all_user_stats"
+var formatQueryEndpoint = "%s/prometheus/api/v1/format_query?query=%s"
+var alertmanagerConfigEndpoint = "%s/api/v1/alerts"
 
 type ListGroupResponse struct {
 	Status string `yaml:"status"`
@@ -34,14 +40,47 @@ type DataGroups struct {
 	Rules []interface{} `yaml:"rules"`
 }
 
+// StatusError is returned when Mimir responds with an unsuccessful status
+type StatusError = httputils.StatusError
+
 type Client struct {
-	config *config.MimirConfig
+	config    *config.MimirConfig
+	transport http.RoundTripper
+	// otherTenant is set for clients of another tenant than the configured
+	// one, whose tenant is sent along with auth tokens
+	otherTenant bool
 }
 
+var _ TenantsLister = &Client{}
+var _ TenantClient = &Client{}
+var _ QueryValidator = &Client{}
+var _ AlertmanagerConfigClient = &Client{}
+
 func NewHTTPClient(config *config.MimirConfig) Mimir {
 	return &Client{config: config}
 }
 
+// NewHTTPClientWithTransport returns a client sending requests through the
+// given transport, which is in charge of TLS: the TLS settings of the
+// configuration don't apply to it
+func NewHTTPClientWithTransport(config *config.MimirConfig, transport http.RoundTripper) Mimir {
+	return &Client{config: config, transport: transport}
+}
+
+// Tenant returns the tenant the client is configured for
+func (c *Client) Tenant() string {
+	return c.config.TenantID
+}
+
+// WithTenant returns a client sending the same credentials on behalf of
+// another tenant, as the X-Scope-OrgID of requests, or the user of basic
+// authentication with API keys
+func (c *Client) WithTenant(tenant string) Mimir {
+	config := *c.config
+	config.TenantID = tenant
+	return &Client{config: &config, transport: c.transport, otherTenant: true}
+}
+
 func (c *Client) ListRules() (map[string][]models.PrometheusRuleGroup, error) {
 	url := fmt.Sprintf(listRulesEndpoint, c.config.Address)
 	res, err := c.doRequest(http.MethodGet, url, nil)
@@ -61,10 +100,42 @@ func (c *Client) ListRules() (map[string][]models.PrometheusRuleGroup, error) {
 			Rules: g.Rules,
 		})
 	}
+	if len(groups) > 0 {
+		if err := c.addGroupSettings(groups); err != nil {
+			log.Debugf("Retrieving the settings of rule groups failed, only their rules are compared: %s", err)
+		}
+	}
 
 	return groups, nil
 }
 
+// addGroupSettings sets the interval, limit and other settings of rule
+// groups, as configured. The rules API reports the interval groups are
+// evaluated at, even when it's the default interval of the ruler, and none
+// of their other settings.
+func (c *Client) addGroupSettings(groups map[string][]models.PrometheusRuleGroup) error {
+	res, err := c.doRequest(http.MethodGet, fmt.Sprintf(listRuleConfigsEndpoint, c.config.Address), nil)
+	if err != nil {
+		return err
+	}
+
+	var configured map[string][]models.PrometheusRuleGroup
+	if err := yaml.Unmarshal(res, &configured); err != nil {
+		return err
+	}
+	for namespace, namespaceGroups := range groups {
+		for i, group := range namespaceGroups {
+			for _, settings := range configured[namespace] {
+				if settings.Name == group.Name {
+					settings.Rules = group.Rules
+					namespaceGroups[i] = settings
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func (c *Client) CreateRules(resource models.PrometheusRuleGrouping) error {
 	url := fmt.Sprintf(loadRulesEndpoint, c.config.Address, resource.Namespace)
 	for _, group := range resource.Groups {
@@ -81,7 +152,115 @@ func (c *Client) CreateRules(resource models.PrometheusRuleGrouping) error {
 	return nil
 }
 
+func (c *Client) DeleteRules(namespace, group string) error {
+	endpoint := fmt.Sprintf(deleteRulesEndpoint, c.config.Address, url.PathEscape(namespace), url.PathEscape(group))
+	if _, err := c.doRequest(http.MethodDelete, endpoint, nil); err != nil {
+		return fmt.Errorf("error found deleting rule group %s: %w", group, err)
+	}
+
+	return nil
+}
+
+// ListTenants lists the tenants that sent series to the distributors, with
+// an admin API of Mimir
+func (c *Client) ListTenants() ([]string, error) {
+	url := fmt.Sprintf(allUserStatsEndpoint, c.config.Address)
+	req, err := c.newRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats []struct {
+		UserID string `json:"userID"`
+	}
+	if err := json.Unmarshal(res, &stats); err != nil {
+		return nil, fmt.Errorf("cannot decode the stats of tenants: %w", err)
+	}
+
+	tenants := make([]string, 0, len(stats))
+	for _, stat := range stats {
+		tenants = append(tenants, stat.UserID)
+	}
+	return tenants, nil
+}
+
+// ValidateQuery checks the syntax of a PromQL expression by having Mimir
+// format it, which parses the expression without evaluating it
+func (c *Client) ValidateQuery(query string) error {
+	endpoint := fmt.Sprintf(formatQueryEndpoint, c.config.Address, url.QueryEscape(query))
+	_, err := c.doRequest(http.MethodGet, endpoint, nil)
+	var statusErr StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusBadRequest {
+		var response struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal([]byte(statusErr.Body), &response) == nil && response.Error != "" {
+			return errors.New(response.Error)
+		}
+	}
+	return err
+}
+
+// GetAlertmanagerConfig retrieves the Alertmanager configuration of the
+// tenant. Tenants without one get a 404.
+func (c *Client) GetAlertmanagerConfig() (models.AlertmanagerConfig, error) {
+	var config models.AlertmanagerConfig
+	res, err := c.doRequest(http.MethodGet, fmt.Sprintf(alertmanagerConfigEndpoint, c.alertmanagerAddress()), nil)
+	if err != nil {
+		return config, err
+	}
+	if err := yaml.Unmarshal(res, &config); err != nil {
+		return config, fmt.Errorf("cannot decode the alertmanager configuration: %w", err)
+	}
+	return config, nil
+}
+
+// SetAlertmanagerConfig replaces the Alertmanager configuration of the
+// tenant, and its templates
+func (c *Client) SetAlertmanagerConfig(config models.AlertmanagerConfig) error {
+	out, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("cannot marshal the alertmanager configuration: %w", err)
+	}
+	if _, err := c.doRequest(http.MethodPost, fmt.Sprintf(alertmanagerConfigEndpoint, c.alertmanagerAddress()), out); err != nil {
+		return fmt.Errorf("error found setting the alertmanager configuration: %w", err)
+	}
+	return nil
+}
+
+// DeleteAlertmanagerConfig removes the Alertmanager configuration of the
+// tenant, which falls back to the default configuration of the cluster
+func (c *Client) DeleteAlertmanagerConfig() error {
+	if _, err := c.doRequest(http.MethodDelete, fmt.Sprintf(alertmanagerConfigEndpoint, c.alertmanagerAddress()), nil); err != nil {
+		return fmt.Errorf("error found deleting the alertmanager configuration: %w", err)
+	}
+	return nil
+}
+
+// alertmanagerAddress returns the address the Alertmanager of the tenant is
+// served at, which defaults to the address of Mimir
+func (c *Client) alertmanagerAddress() string {
+	if c.config.AlertmanagerAddress != "" {
+		return c.config.AlertmanagerAddress
+	}
+	return c.config.Address
+}
+
 func (c *Client) doRequest(method string, url string, body []byte) ([]byte, error) {
+	req, err := c.newRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(req)
+}
+
+func (c *Client) newRequest(method string, url string, body []byte) (*http.Request, error) {
 	if c.config.TenantID == "" {
 		return nil, errors.New("missing tenant-id")
 	}
@@ -96,45 +275,44 @@ func (c *Client) doRequest(method string, url string, body []byte) ([]byte, erro
 		req.SetBasicAuth(c.config.TenantID, c.config.APIKey)
 	case c.config.AuthToken != "":
 		req.Header.Set("Authorization", "Bearer "+c.config.AuthToken)
+		if c.otherTenant {
+			req.Header.Set("X-Scope-OrgID", c.config.TenantID)
+		}
 	default:
 		req.Header.Set("X-Scope-OrgID", c.config.TenantID)
 	}
 
+	return req, nil
+}
+
+func (c *Client) do(req *http.Request) ([]byte, error) {
 	client, err := c.createHTTPClient()
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request to load rules failed: %s", err)
-	}
-
-	b, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, fmt.Errorf("cannot read response body: %s", err)
-	}
-
-	if res.StatusCode >= 300 {
-		return nil, fmt.Errorf("error loading rules: %d, error: %s", res.StatusCode, strings.TrimSpace(string(b)))
-	}
-
-	return b, nil
+	return httputils.Do(client, req, "Mimir")
 }
 
 func (c *Client) createHTTPClient() (*http.Client, error) {
-	tlsConfig := &tls.Config{}
-	httpClient, err := httputils.NewHTTPClient()
-	if err != nil {
-		return nil, err
+	if c.transport != nil {
+		return httputils.NewHTTPClientWithTransport(c.transport)
 	}
 
-	httpClient.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+	// the proxy and CAs configured for every client apply on top of the TLS settings of Mimir
+	transport := httputils.NewTransport()
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	tlsConfig := transport.TLSClientConfig
 
 	if c.config.TLS.CAPath != "" {
-		certPool, err := x509.SystemCertPool()
-		if err != nil {
-			return nil, err
+		certPool := tlsConfig.RootCAs
+		if certPool == nil {
+			var err error
+			if certPool, err = x509.SystemCertPool(); err != nil {
+				return nil, err
+			}
 		}
 
 		caCertPEM, err := os.ReadFile(c.config.TLS.CAPath)
@@ -159,9 +337,5 @@ func (c *Client) createHTTPClient() (*http.Client, error) {
 		tlsConfig.Certificates = []tls.Certificate{clientTLSCert}
 	}
 
-	httpClient.Transport = &http.Transport{
-		TLSClientConfig: tlsConfig,
-	}
-
-	return httpClient, nil
+	return httputils.NewHTTPClientWithTransport(transport)
 }
@@ -7,4 +7,34 @@ import (
 type Mimir interface {
 	ListRules() (map[string][]models.PrometheusRuleGroup, error)
 	CreateRules(resource models.PrometheusRuleGrouping) error
+	DeleteRules(namespace, group string) error
+}
+
+// TenantsLister describes a client that can list all the tenants of a
+// cluster, which requires admin APIs
+type TenantsLister interface {
+	ListTenants() ([]string, error)
+}
+
+// TenantClient describes a client that can send requests on behalf of other
+// tenants than the configured one, with the same credentials
+type TenantClient interface {
+	// Tenant returns the configured tenant
+	Tenant() string
+	// WithTenant returns a client for another tenant
+	WithTenant(tenant string) Mimir
+}
+
+// QueryValidator describes a client that can check the syntax of PromQL
+// expressions without evaluating them
+type QueryValidator interface {
+	ValidateQuery(query string) error
+}
+
+// AlertmanagerConfigClient describes a client that can manage the
+// Alertmanager configuration of the tenant
+type AlertmanagerConfigClient interface {
+	GetAlertmanagerConfig() (models.AlertmanagerConfig, error)
+	SetAlertmanagerConfig(config models.AlertmanagerConfig) error
+	DeleteAlertmanagerConfig() error
 }
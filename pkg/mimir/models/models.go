@@ -1,9 +1,21 @@
 package models
 
-// PrometheusRuleGroup encapsulates a list of rules
+// PrometheusRuleGroup encapsulates a list of rules, and the settings of
+// their evaluation
 type PrometheusRuleGroup struct {
-	Name  string        `yaml:"name"`
-	Rules []interface{} `yaml:"rules"`
+	Name string `yaml:"name"`
+	// Interval is how often the rules are evaluated, the default interval of
+	// the ruler when empty
+	Interval string `yaml:"interval,omitempty"`
+	// Limit is the number of alerts or series a rule can produce, 0 for no limit
+	Limit int `yaml:"limit,omitempty"`
+	// EvaluationDelay is how far in the past rules are evaluated, to allow
+	// for late samples. It's been renamed QueryOffset.
+	EvaluationDelay string `yaml:"evaluation_delay,omitempty"`
+	QueryOffset     string `yaml:"query_offset,omitempty"`
+	// SourceTenants are the tenants federated rule groups query
+	SourceTenants []string      `yaml:"source_tenants,omitempty"`
+	Rules         []interface{} `yaml:"rules"`
 }
 
 // PrometheusRuleGrouping encapsulates a set of named rule groups
@@ -11,3 +23,11 @@ type PrometheusRuleGrouping struct {
 	Namespace string                `yaml:"namespace"`
 	Groups    []PrometheusRuleGroup `yaml:"groups"`
 }
+
+// AlertmanagerConfig is the Alertmanager configuration of a tenant, with the
+// templates it refers to
+type AlertmanagerConfig struct {
+	TemplateFiles map[string]string `yaml:"template_files"`
+	// AlertmanagerConfig is the YAML configuration of the Alertmanager
+	AlertmanagerConfig string `yaml:"alertmanager_config"`
+}
@@ -2,7 +2,9 @@ package mimir
 
 import (
 	"fmt"
+	"net/http"
 	"path/filepath"
+	"time"
 
 	"github.com/grafana/grizzly/pkg/config"
 	"github.com/grafana/grizzly/pkg/grizzly"
@@ -24,6 +26,14 @@ func NewProvider(config *config.MimirConfig) *Provider {
 	}
 }
 
+// WithTransport sends the requests of the provider through a transport of
+// its own, such as one signing requests or recording them. The transport is
+// in charge of TLS, so the TLS settings of the configuration don't apply to it.
+func (p *Provider) WithTransport(transport http.RoundTripper) *Provider {
+	p.clientTool = client.NewHTTPClientWithTransport(p.config, transport)
+	return p
+}
+
 func (p *Provider) Validate() error {
 	if p.config.Address == "" {
 		return fmt.Errorf("mimir address is not set")
@@ -54,6 +64,11 @@ func (p *Provider) Status() grizzly.ProviderStatus {
 	return status
 }
 
+// CredentialsExpiry implements grizzly.CredentialsExpiryProvider, for tokens that are JWTs
+func (p *Provider) CredentialsExpiry() (time.Time, bool) {
+	return grizzly.TokenExpiry(p.config.AuthToken)
+}
+
 func (p *Provider) Name() string {
 	return "Mimir"
 }
@@ -77,5 +92,6 @@ func (p *Provider) APIVersion() string {
 func (p *Provider) GetHandlers() []grizzly.Handler {
 	return []grizzly.Handler{
 		NewRuleHandler(p, p.clientTool),
+		NewAlertmanagerConfigHandler(p, p.clientTool),
 	}
 }
@@ -0,0 +1,81 @@
+package mimir
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grizzly/internal/httputils"
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingTransport struct {
+	paths []string
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.paths = append(t.paths, req.URL.Path)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestProviderWithTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status": "success", "data": {"groups": []}}`))
+	}))
+	defer server.Close()
+
+	transport := &recordingTransport{}
+	provider := NewProvider(&config.MimirConfig{Address: server.URL, TenantID: "tenant"}).WithTransport(transport)
+
+	require.True(t, provider.Status().Online)
+	require.Equal(t, []string{"/prometheus/api/v1/rules"}, transport.paths)
+}
+
+func TestProviderTransport(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, httputils.ConfigureTransport(httputils.Transport{})) })
+
+	t.Run("requests are sent through the proxy", func(t *testing.T) {
+		var proxied []string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxied = append(proxied, r.URL.String())
+			_, _ = w.Write([]byte(`{"status": "success", "data": {"groups": []}}`))
+		}))
+		defer proxy.Close()
+		require.NoError(t, httputils.ConfigureTransport(httputils.Transport{Proxy: proxy.URL}))
+
+		provider := NewProvider(&config.MimirConfig{Address: "http://mimir.example.com", TenantID: "tenant"})
+		require.True(t, provider.Status().Online)
+		require.Equal(t, []string{"http://mimir.example.com/prometheus/api/v1/rules"}, proxied)
+	})
+
+	t.Run("certificates are verified with the CAs given", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"status": "success", "data": {"groups": []}}`))
+		}))
+		defer server.Close()
+		provider := NewProvider(&config.MimirConfig{Address: server.URL, TenantID: "tenant"})
+
+		require.NoError(t, httputils.ConfigureTransport(httputils.Transport{}))
+		require.False(t, provider.Status().Online)
+
+		caPath := filepath.Join(t.TempDir(), "ca.pem")
+		require.NoError(t, os.WriteFile(caPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600))
+		require.NoError(t, httputils.ConfigureTransport(httputils.Transport{CAPath: caPath}))
+		require.True(t, provider.Status().Online)
+	})
+
+	t.Run("certificates aren't verified when insecure", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"status": "success", "data": {"groups": []}}`))
+		}))
+		defer server.Close()
+
+		require.NoError(t, httputils.ConfigureTransport(httputils.Transport{InsecureSkipVerify: true}))
+		provider := NewProvider(&config.MimirConfig{Address: server.URL, TenantID: "tenant"})
+		require.True(t, provider.Status().Online)
+	})
+}
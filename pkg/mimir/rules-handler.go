@@ -3,39 +3,108 @@ package mimir
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/grafana/grizzly/pkg/grizzly"
 	"github.com/grafana/grizzly/pkg/mimir/client"
 	"github.com/grafana/grizzly/pkg/mimir/models"
+	"gopkg.in/yaml.v3"
 )
 
 const PrometheusRuleGroupKind = "PrometheusRuleGroup"
 
 var _ grizzly.Handler = &RuleHandler{}
+var _ grizzly.LegacyKindsHandler = &RuleHandler{}
+var _ grizzly.RuleStateHandler = &RuleHandler{}
+var _ grizzly.QueryHandler = &RuleHandler{}
+var _ grizzly.MetadataInferrer = &RuleHandler{}
+var _ grizzly.RuleGroupHandler = &RuleHandler{}
+var _ grizzly.RemoteValidator = &RuleHandler{}
+var _ grizzly.MetricRecorder = &RuleHandler{}
 
 // RuleHandler is a Grizzly Handler for Prometheus Rules
 type RuleHandler struct {
 	grizzly.BaseHandler
 	clientTool client.Mimir
+
+	mu sync.Mutex
+	// tenants are the tenants other than the configured one of the rule
+	// groups given to the handler, whose UIDs are prefixed with them
+	tenants map[string]bool
 }
 
 // NewRuleHandler returns a new Grizzly Handler for Prometheus Rules
 func NewRuleHandler(provider *Provider, clientTool client.Mimir) *RuleHandler {
 	return &RuleHandler{
-		BaseHandler: grizzly.NewBaseHandler(provider, PrometheusRuleGroupKind, false),
+		BaseHandler: grizzly.NewBaseHandler(provider, PrometheusRuleGroupKind, false).WithListCache(),
 		clientTool:  clientTool,
+		tenants:     map[string]bool{},
 	}
 }
 
 const (
-	prometheusRuleGroupPattern = "prometheus/rules-%s.%s"
+	prometheusRuleGroupPattern           = "prometheus/rules-%s.%s"
+	prometheusNamespacedRuleGroupPattern = "prometheus/rules/%s/%s.%s"
+	prometheusTenantRuleGroupPattern     = "prometheus/%s/rules/%s/%s.%s"
 )
 
-// ResourceFilePath returns the location on disk where a resource should be updated
+// tenantSeparator separates the tenant of a rule group from its namespace in
+// its UID, for the groups of other tenants than the configured one. Ex:
+// team-b:infra.alerts. Namespaces can contain it too, so it's only read as a
+// tenant prefix for the tenants of the rule groups the handler was given.
+const tenantSeparator = ":"
+
+// LegacyKinds returns the kind names previously used for rule group resources
+func (h *RuleHandler) LegacyKinds() []string {
+	return []string{"CortexRuleGroup"}
+}
+
+// ErrorHint implements grizzly.ErrorHinter
+func (h *RuleHandler) ErrorHint(code grizzly.ErrorCode) string {
+	switch code {
+	case grizzly.ErrorCodeAuthFailed:
+		return "check mimir.tenant-id and mimir.api-key (or mimir.auth-token) with `grr config check`"
+	case grizzly.ErrorCodePermissionDenied:
+		return "the credentials lack the rules:read or rules:write permissions for the tenant"
+	case grizzly.ErrorCodeVersionUnsupported:
+		return "the ruler API wasn't found, check that mimir.address points to a Mimir instance with the ruler enabled"
+	case grizzly.ErrorCodeQuotaExceeded:
+		return "a ruler limit of the tenant was reached (rule groups per tenant, or rules per group): split or remove rule groups, or ask for the limits to be raised"
+	default:
+		return ""
+	}
+}
+
+// ResourceFilePath returns the location on disk where a resource should be
+// updated. Rule groups are written to one directory per namespace, so that
+// the namespace can be inferred back from the directory.
 func (h *RuleHandler) ResourceFilePath(resource grizzly.Resource, filetype string) string {
 	filename := strings.ReplaceAll(resource.Name(), string(os.PathSeparator), "-")
-	return fmt.Sprintf(prometheusRuleGroupPattern, filename, filetype)
+	namespace := resource.GetMetadata("namespace")
+	if namespace == "" {
+		return fmt.Sprintf(prometheusRuleGroupPattern, filename, filetype)
+	}
+	dirname := strings.ReplaceAll(namespace, string(os.PathSeparator), "-")
+	if tenant := h.tenant(resource); tenant != "" {
+		return fmt.Sprintf(prometheusTenantRuleGroupPattern, strings.ReplaceAll(tenant, string(os.PathSeparator), "-"), dirname, filename, filetype)
+	}
+	return fmt.Sprintf(prometheusNamespacedRuleGroupPattern, dirname, filename, filetype)
+}
+
+// InferMetadata implements grizzly.MetadataInferrer. Rule groups without a
+// namespace take the name of the directory they were read from.
+func (h *RuleHandler) InferMetadata(resource *grizzly.Resource) {
+	if resource.GetMetadata("namespace") != "" {
+		return
+	}
+	dir := filepath.Base(filepath.Dir(resource.Source.Path))
+	if dir == "." || dir == string(os.PathSeparator) {
+		return
+	}
+	resource.SetMetadata("namespace", dir)
 }
 
 // Validate returns the uid of resource
@@ -47,12 +116,26 @@ func (h *RuleHandler) Validate(resource grizzly.Resource) error {
 	return nil
 }
 
+// EvaluationInterval implements grizzly.RuleGroupHandler. Groups without an
+// interval are evaluated at the default interval of the ruler.
+func (h *RuleHandler) EvaluationInterval(resource grizzly.Resource) (time.Duration, bool, error) {
+	interval, ok := resource.GetSpecString("interval")
+	if !ok || interval == "" {
+		return 0, false, nil
+	}
+	duration, err := time.ParseDuration(interval)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid interval %s: %w", interval, err)
+	}
+	return duration, true, nil
+}
+
 // GetUID returns the UID for a resource
 func (h *RuleHandler) GetUID(resource grizzly.Resource) (string, error) {
 	if !resource.HasMetadata("namespace") {
 		return "", fmt.Errorf("%s %s requires a namespace metadata entry", h.Kind(), resource.Name())
 	}
-	return fmt.Sprintf("%s.%s", resource.GetMetadata("namespace"), resource.Name()), nil
+	return ruleGroupUID(h.tenant(resource), resource.GetMetadata("namespace"), resource.Name()), nil
 }
 
 func (h *RuleHandler) GetSpecUID(resource grizzly.Resource) (string, error) {
@@ -66,8 +149,16 @@ func (h *RuleHandler) GetByUID(uid string) (*grizzly.Resource, error) {
 
 // GetRemote retrieves a datasource as a Resource
 func (h *RuleHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
-	uid := fmt.Sprintf("%s.%s", resource.GetMetadata("namespace"), resource.Name())
-	return h.getRemoteRuleGroup(uid)
+	uid := ruleGroupUID(h.tenant(resource), resource.GetMetadata("namespace"), resource.Name())
+	remote, err := h.getRemoteRuleGroup(uid)
+	if err != nil {
+		return nil, err
+	}
+	// groups naming the configured tenant keep naming it
+	if resource.HasMetadata("tenant") {
+		remote.SetMetadata("tenant", resource.GetMetadata("tenant"))
+	}
+	return remote, nil
 }
 
 // ListRemote retrieves as list of UIDs of all remote resources
@@ -85,13 +176,86 @@ func (h *RuleHandler) Update(existing, resource grizzly.Resource) error {
 	return h.writeRuleGroup(resource)
 }
 
+// ValidateRemote implements grizzly.RemoteValidator. The ruler API can't
+// check rule groups without loading them, so the expressions of their rules
+// are checked by the query API instead.
+func (h *RuleHandler) ValidateRemote(existing *grizzly.Resource, resource grizzly.Resource) error {
+	if _, _, err := h.EvaluationInterval(resource); err != nil {
+		return err
+	}
+	validator, ok := h.clientTool.(client.QueryValidator)
+	if !ok {
+		return nil
+	}
+
+	var problems []string
+	for _, rule := range ruleExpressions(resource) {
+		if err := validator.ValidateQuery(rule.expr); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", rule.name, err))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid expressions:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// Delete removes a rule group from Mimir
+func (h *RuleHandler) Delete(resource grizzly.Resource) error {
+	clientTool, err := h.client(h.tenant(resource))
+	if err != nil {
+		return err
+	}
+	if err := clientTool.DeleteRules(resource.GetMetadata("namespace"), resource.Name()); err != nil {
+		return err
+	}
+	h.InvalidateListCache()
+	return nil
+}
+
+// RuleStates implements grizzly.RuleStateHandler. The rules listed by the
+// ruler include the state of their last evaluation, which isn't cached as it
+// changes as the rules are evaluated.
+func (h *RuleHandler) RuleStates(resource grizzly.Resource) ([]grizzly.RuleState, error) {
+	h.InvalidateListCache()
+	remote, err := h.GetRemote(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, _ := remote.Spec()["rules"].([]interface{})
+	states := make([]grizzly.RuleState, 0, len(rules))
+	for _, ruleIf := range rules {
+		rule, _ := ruleIf.(map[string]interface{})
+		state := grizzly.RuleState{}
+		for _, key := range []string{"name", "alert", "record"} {
+			if name, ok := rule[key].(string); ok {
+				state.Rule = name
+				break
+			}
+		}
+		state.State, _ = rule["state"].(string)
+		state.Health, _ = rule["health"].(string)
+		state.LastError, _ = rule["lastError"].(string)
+		switch lastEvaluation := rule["lastEvaluation"].(type) {
+		case time.Time:
+			state.LastEvaluation = lastEvaluation
+		case string:
+			state.LastEvaluation, _ = time.Parse(time.RFC3339Nano, lastEvaluation)
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
 // getRemoteRuleGroup retrieves a datasource object from Grafana
 func (h *RuleHandler) getRemoteRuleGroup(uid string) (*grizzly.Resource, error) {
-	parts := strings.SplitN(uid, ".", 2)
-	namespace := parts[0]
-	name := parts[1]
+	tenant, namespace, name, err := h.splitRuleGroupUID(uid)
+	if err != nil {
+		return nil, err
+	}
 
-	groupings, err := h.clientTool.ListRules()
+	groupings, err := h.listRules(tenant)
 	if err != nil {
 		return nil, err
 	}
@@ -100,14 +264,16 @@ func (h *RuleHandler) getRemoteRuleGroup(uid string) (*grizzly.Resource, error)
 		if key == namespace {
 			for _, group := range grouping {
 				if group.Name == name {
-					spec := map[string]interface{}{
-						"rules": group.Rules,
-					}
-					resource, err := grizzly.NewResource(h.APIVersion(), h.Kind(), group.Name, spec)
+					resource, err := grizzly.NewResource(h.APIVersion(), h.Kind(), group.Name, groupSpec(group))
 					if err != nil {
 						return nil, err
 					}
 					resource.SetMetadata("namespace", namespace)
+					if tenant != "" {
+						resource.SetMetadata("tenant", tenant)
+					}
+					// the rules are shared with the cached list
+					resource = resource.DeepCopy()
 					return &resource, nil
 				}
 			}
@@ -118,7 +284,7 @@ func (h *RuleHandler) getRemoteRuleGroup(uid string) (*grizzly.Resource, error)
 
 // getRemoteRuleGroupList retrieves a datasource object from Grafana
 func (h *RuleHandler) getRemoteRuleGroupList() ([]string, error) {
-	groupings, err := h.clientTool.ListRules()
+	groupings, err := h.listRules("")
 	if err != nil {
 		return nil, err
 	}
@@ -133,11 +299,46 @@ func (h *RuleHandler) getRemoteRuleGroupList() ([]string, error) {
 	return IDs, nil
 }
 
+// groupSpec returns the spec of a rule group, with the settings it has
+func groupSpec(group models.PrometheusRuleGroup) map[string]interface{} {
+	spec := map[string]interface{}{
+		"rules": group.Rules,
+	}
+	if group.Interval != "" {
+		spec["interval"] = group.Interval
+	}
+	if group.Limit != 0 {
+		spec["limit"] = group.Limit
+	}
+	if group.EvaluationDelay != "" {
+		spec["evaluation_delay"] = group.EvaluationDelay
+	}
+	if group.QueryOffset != "" {
+		spec["query_offset"] = group.QueryOffset
+	}
+	if len(group.SourceTenants) > 0 {
+		tenants := make([]interface{}, len(group.SourceTenants))
+		for i, tenant := range group.SourceTenants {
+			tenants[i] = tenant
+		}
+		spec["source_tenants"] = tenants
+	}
+	return spec
+}
+
 func (h *RuleHandler) writeRuleGroup(resource grizzly.Resource) error {
-	newGroup := models.PrometheusRuleGroup{
-		Name:  resource.Name(),
-		Rules: []interface{}{},
+	// the settings of the group are named as in rule files
+	var newGroup models.PrometheusRuleGroup
+	data, err := yaml.Marshal(resource.Spec())
+	if err != nil {
+		return err
 	}
+	if err := yaml.Unmarshal(data, &newGroup); err != nil {
+		return fmt.Errorf("invalid settings for rule group %s: %w", resource.Name(), err)
+	}
+	newGroup.Name = resource.Name()
+	newGroup.Rules = []interface{}{}
+
 	rules := resource.Spec()["rules"].([]interface{})
 	for _, ruleIf := range rules {
 		rule := ruleIf.(map[string]interface{})
@@ -162,5 +363,145 @@ func (h *RuleHandler) writeRuleGroup(resource grizzly.Resource) error {
 		Groups:    []models.PrometheusRuleGroup{newGroup},
 	}
 
-	return h.clientTool.CreateRules(grouping)
+	clientTool, err := h.client(h.tenant(resource))
+	if err != nil {
+		return err
+	}
+	if err := clientTool.CreateRules(grouping); err != nil {
+		return err
+	}
+	h.InvalidateListCache()
+	return nil
+}
+
+// listRules retrieves all the rule groups of a tenant, the configured one
+// when empty. The ruler API can't retrieve a single group by name, so the
+// list of each tenant is cached for the run.
+func (h *RuleHandler) listRules(tenant string) (map[string][]models.PrometheusRuleGroup, error) {
+	clientTool, err := h.client(tenant)
+	if err != nil {
+		return nil, err
+	}
+	key := "rules"
+	if tenant != "" {
+		key += tenantSeparator + tenant
+	}
+	return grizzly.CachedList(&h.BaseHandler, key, clientTool.ListRules)
+}
+
+// tenant returns the tenant of a rule group, set by its tenant metadata.
+// Groups of the configured tenant have none, so that they keep their UIDs.
+func (h *RuleHandler) tenant(resource grizzly.Resource) string {
+	tenant := resource.GetMetadata("tenant")
+	if tenantClient, ok := h.clientTool.(client.TenantClient); ok && tenant == tenantClient.Tenant() {
+		return ""
+	}
+	if tenant != "" {
+		h.mu.Lock()
+		h.tenants[tenant] = true
+		h.mu.Unlock()
+	}
+	return tenant
+}
+
+// client returns a client for a tenant, the configured one when empty
+func (h *RuleHandler) client(tenant string) (client.Mimir, error) {
+	if tenant == "" {
+		return h.clientTool, nil
+	}
+	tenantClient, ok := h.clientTool.(client.TenantClient)
+	if !ok {
+		return nil, fmt.Errorf("the rule groups of tenant %s can't be managed, as the client only supports the configured tenant", tenant)
+	}
+	return tenantClient.WithTenant(tenant), nil
+}
+
+// ruleGroupUID returns the UID of a rule group: <namespace>.<name>, prefixed
+// with the tenant of the groups of other tenants than the configured one
+func ruleGroupUID(tenant, namespace, name string) string {
+	uid := fmt.Sprintf("%s.%s", namespace, name)
+	if tenant != "" {
+		uid = tenant + tenantSeparator + uid
+	}
+	return uid
+}
+
+// splitRuleGroupUID returns the tenant, namespace and name of a rule group
+// from its UID. The tenant is empty for the configured tenant, and UIDs are
+// only read as prefixed with the tenants of the rule groups given to the
+// handler, so that namespaces containing the separator, such as
+// team:infra.alerts, are read as they are.
+func (h *RuleHandler) splitRuleGroupUID(uid string) (string, string, string, error) {
+	var tenant string
+	if before, after, found := strings.Cut(uid, tenantSeparator); found {
+		h.mu.Lock()
+		if h.tenants[before] {
+			tenant, uid = before, after
+		}
+		h.mu.Unlock()
+	}
+	namespace, name, found := strings.Cut(uid, ".")
+	if !found {
+		return "", "", "", fmt.Errorf("invalid rule group UID %s, expected <namespace>.<name>", uid)
+	}
+	return tenant, namespace, name, nil
+}
+
+// Queries implements grizzly.QueryHandler, listing the expressions of the
+// rules of a group
+func (h *RuleHandler) Queries(resource grizzly.Resource) []grizzly.Query {
+	var queries []grizzly.Query
+	for _, rule := range ruleExpressions(resource) {
+		queries = append(queries, grizzly.Query{
+			Pointer:  fmt.Sprintf("/rules/%d/expr", rule.index),
+			Language: grizzly.QueryLanguagePromQL,
+			Name:     rule.name,
+		})
+	}
+	return queries
+}
+
+// RecordedMetrics implements grizzly.MetricRecorder, listing the metrics
+// recorded by the rules of a group
+func (h *RuleHandler) RecordedMetrics(resource grizzly.Resource) []string {
+	var metrics []string
+	rules, _ := resource.Spec()["rules"].([]interface{})
+	for _, ruleIf := range rules {
+		rule, _ := ruleIf.(map[string]interface{})
+		if record, ok := rule["record"].(string); ok && record != "" {
+			metrics = append(metrics, record)
+		}
+	}
+	return metrics
+}
+
+// ruleExpression is the expression of a rule of a group
+type ruleExpression struct {
+	index int
+	// name identifies the rule within its group. Ex: HighErrorRate-2
+	name string
+	expr string
+}
+
+func ruleExpressions(resource grizzly.Resource) []ruleExpression {
+	var expressions []ruleExpression
+
+	rules, _ := resource.Spec()["rules"].([]interface{})
+	for i, ruleIf := range rules {
+		rule, _ := ruleIf.(map[string]interface{})
+		expr, ok := rule["expr"].(string)
+		if !ok || strings.TrimSpace(expr) == "" {
+			continue
+		}
+
+		name := fmt.Sprintf("rule-%d", i)
+		for _, key := range []string{"alert", "record"} {
+			if ruleName, ok := rule[key].(string); ok && ruleName != "" {
+				name = fmt.Sprintf("%s-%d", ruleName, i)
+				break
+			}
+		}
+		expressions = append(expressions, ruleExpression{index: i, name: name, expr: expr})
+	}
+	return expressions
 }
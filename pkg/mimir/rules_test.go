@@ -2,10 +2,19 @@ package mimir
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/grafana/grizzly/pkg/config"
 	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/mimir/client"
 	"github.com/grafana/grizzly/pkg/mimir/models"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
@@ -113,14 +122,127 @@ func TestRuleHandler_ResourceFilePath(t *testing.T) {
 
 		req.Equal("prometheus/rules-some-rule.yaml", handler.ResourceFilePath(resource, "yaml"))
 	})
+
+	t.Run("namespaces are mapped to directories", func(t *testing.T) {
+		req := require.New(t)
+
+		resource, err := grizzly.NewResource(handler.APIVersion(), handler.Kind(), "some-rule", map[string]interface{}{})
+		req.NoError(err)
+		resource.SetMetadata("namespace", "team/a")
+
+		req.Equal("prometheus/rules/team-a/some-rule.yaml", handler.ResourceFilePath(resource, "yaml"))
+	})
+}
+
+func TestRuleHandler_InferMetadata(t *testing.T) {
+	handler := NewRuleHandler(&Provider{}, &FakeClient{})
+
+	newResource := func(t *testing.T, path string) grizzly.Resource {
+		resource, err := grizzly.NewResource(handler.APIVersion(), handler.Kind(), "some-rule", map[string]interface{}{})
+		require.NoError(t, err)
+		resource.SetSource(grizzly.Source{Path: path})
+		return resource
+	}
+
+	t.Run("namespace is inferred from the directory", func(t *testing.T) {
+		resource := newResource(t, "prometheus/rules/first_rules/some-rule.yaml")
+		handler.InferMetadata(&resource)
+		require.Equal(t, "first_rules", resource.GetMetadata("namespace"))
+
+		uid, err := handler.GetUID(resource)
+		require.NoError(t, err)
+		require.Equal(t, "first_rules.some-rule", uid)
+	})
+
+	t.Run("namespace in metadata is kept", func(t *testing.T) {
+		resource := newResource(t, "prometheus/rules/first_rules/some-rule.yaml")
+		resource.SetMetadata("namespace", "other_rules")
+		handler.InferMetadata(&resource)
+		require.Equal(t, "other_rules", resource.GetMetadata("namespace"))
+	})
+
+	t.Run("files in the working directory aren't given a namespace", func(t *testing.T) {
+		resource := newResource(t, "some-rule.yaml")
+		handler.InferMetadata(&resource)
+		require.False(t, resource.HasMetadata("namespace"))
+	})
+}
+
+func TestRulesListCache(t *testing.T) {
+	client := &FakeClient{hasFile: true}
+	h := RuleHandler{
+		BaseHandler: grizzly.NewBaseHandler(&Provider{}, "PrometheusRuleGroup", false).WithListCache(),
+		clientTool:  client,
+	}
+
+	_, err := h.ListRemote()
+	require.NoError(t, err)
+	res, err := h.GetByUID("first_rules.grizzly_alerts")
+	require.NoError(t, err)
+	require.Equal(t, 1, client.listCalls)
+
+	res.Spec()["rules"] = []interface{}{}
+	res, err = h.GetByUID("first_rules.grizzly_alerts")
+	require.NoError(t, err)
+	require.NotEmpty(t, res.Spec()["rules"], "cached rules mustn't be modified through resources")
+
+	require.NoError(t, h.Update(*res, *res))
+	_, err = h.GetByUID("first_rules.grizzly_alerts")
+	require.NoError(t, err)
+	require.Equal(t, 2, client.listCalls, "writes invalidate the cache")
+
+	client.mockResponse(t, false, errMimirClient)
+	h.InvalidateListCache()
+	_, err = h.ListRemote()
+	require.Error(t, err)
+	client.mockResponse(t, true, nil)
+	_, err = h.ListRemote()
+	require.NoError(t, err, "errors aren't cached")
+}
+
+func TestRuleHandlerValidateRemote(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/prometheus/api/v1/format_query", r.URL.Path)
+		query := r.URL.Query().Get("query")
+		queries = append(queries, query)
+		if query == "sum(rate(errors[5m])" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status": "error", "errorType": "bad_data", "error": "1:20: parse error: unclosed left parenthesis"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status": "success", "data": "formatted"}`))
+	}))
+	defer server.Close()
+
+	h := NewRuleHandler(&Provider{}, client.NewHTTPClient(&config.MimirConfig{Address: server.URL, TenantID: "tenant"}))
+	group := func(expr string) grizzly.Resource {
+		resource, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", PrometheusRuleGroupKind, "errors", map[string]any{
+			"rules": []any{
+				map[string]any{"record": "job:errors:rate5m", "expr": "sum(rate(errors[5m]))"},
+				map[string]any{"alert": "HighErrorRate", "expr": expr},
+			},
+		})
+		require.NoError(t, err)
+		resource.SetMetadata("namespace", "team-a")
+		return resource
+	}
+
+	require.NoError(t, h.ValidateRemote(nil, group("job:errors:rate5m > 10")))
+	require.Equal(t, []string{"sum(rate(errors[5m]))", "job:errors:rate5m > 10"}, queries)
+
+	err := h.ValidateRemote(nil, group("sum(rate(errors[5m])"))
+	require.ErrorContains(t, err, "HighErrorRate-1: 1:20: parse error: unclosed left parenthesis")
 }
 
 type FakeClient struct {
 	hasFile       bool
 	expectedError error
+	listCalls     int
 }
 
 func (f *FakeClient) ListRules() (map[string][]models.PrometheusRuleGroup, error) {
+	f.listCalls++
 	if f.expectedError != nil {
 		return nil, f.expectedError
 	}
@@ -167,3 +289,158 @@ func (f *FakeClient) mockResponse(t *testing.T, hasFile bool, expectedError erro
 		f.expectedError = nil
 	})
 }
+
+func (f *FakeClient) DeleteRules(_, _ string) error {
+	return f.expectedError
+}
+
+func TestRuleGroupSettings(t *testing.T) {
+	var created []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/prometheus/api/v1/rules":
+			// the rules API reports the default interval of the ruler
+			_, _ = w.Write([]byte(`{"status": "success", "data": {"groups": [
+				{"name": "slow", "file": "team-a", "interval": 60, "rules": [{"record": "job:up:sum", "expr": "sum by (job) (up)"}]},
+				{"name": "fast", "file": "team-a", "interval": 60, "rules": [{"record": "job:up:max", "expr": "max by (job) (up)"}]}
+			]}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/prometheus/config/v1/rules":
+			_, _ = w.Write([]byte("team-a:\n" +
+				"  - name: slow\n    interval: 5m\n    limit: 10\n    evaluation_delay: 1m\n    rules: []\n" +
+				"  - name: fast\n    rules: []\n"))
+		case r.Method == http.MethodPost && r.URL.Path == "/prometheus/config/v1/rules/team-a":
+			created, _ = io.ReadAll(r.Body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	h := NewRuleHandler(&Provider{}, client.NewHTTPClient(&config.MimirConfig{Address: server.URL, TenantID: "tenant"}))
+
+	t.Run("pulled groups have their settings", func(t *testing.T) {
+		slow, err := h.GetByUID("team-a.slow")
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{
+			"interval":         "5m",
+			"limit":            10,
+			"evaluation_delay": "1m",
+			"rules":            []any{map[string]any{"record": "job:up:sum", "expr": "sum by (job) (up)"}},
+		}, slow.Spec())
+
+		fast, err := h.GetByUID("team-a.fast")
+		require.NoError(t, err)
+		require.NotContains(t, fast.Spec(), "interval", "groups evaluated at the default interval have none")
+	})
+
+	t.Run("applied groups keep their settings", func(t *testing.T) {
+		resource, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", PrometheusRuleGroupKind, "slow", map[string]any{
+			"interval":       "5m",
+			"limit":          10,
+			"query_offset":   "2m",
+			"source_tenants": []any{"team-b"},
+			"rules":          []any{map[string]any{"record": "job:up:sum", "expr": "sum by (job) (up)"}},
+		})
+		require.NoError(t, err)
+		resource.SetMetadata("namespace", "team-a")
+		require.NoError(t, h.Add(resource))
+
+		var group models.PrometheusRuleGroup
+		require.NoError(t, yaml.Unmarshal(created, &group))
+		require.Equal(t, models.PrometheusRuleGroup{
+			Name:          "slow",
+			Interval:      "5m",
+			Limit:         10,
+			QueryOffset:   "2m",
+			SourceTenants: []string{"team-b"},
+			Rules:         []any{map[string]any{"record": "job:up:sum", "expr": "sum by (job) (up)"}},
+		}, group)
+	})
+}
+
+func TestRuleGroupTenants(t *testing.T) {
+	var mu sync.Mutex
+	created := map[string][]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		tenant := r.Header.Get("X-Scope-OrgID")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/prometheus/api/v1/rules":
+			groups := []string{}
+			for _, name := range created[tenant] {
+				groups = append(groups, fmt.Sprintf(`{"name": %q, "file": "infra", "rules": [{"alert": "Down", "expr": "up == 0"}]}`, name))
+			}
+			_, _ = fmt.Fprintf(w, `{"status": "success", "data": {"groups": [%s]}}`, strings.Join(groups, ","))
+		case r.Method == http.MethodGet && r.URL.Path == "/prometheus/config/v1/rules":
+			_, _ = w.Write([]byte("{}"))
+		case r.Method == http.MethodPost && r.URL.Path == "/prometheus/config/v1/rules/infra":
+			var group models.PrometheusRuleGroup
+			body, _ := io.ReadAll(r.Body)
+			_ = yaml.Unmarshal(body, &group)
+			created[tenant] = append(created[tenant], group.Name)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	h := NewRuleHandler(&Provider{}, client.NewHTTPClient(&config.MimirConfig{Address: server.URL, TenantID: "team-a"}))
+	group := func(name, tenant string) grizzly.Resource {
+		resource, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", PrometheusRuleGroupKind, name, map[string]any{
+			"rules": []any{map[string]any{"alert": "Down", "expr": "up == 0"}},
+		})
+		require.NoError(t, err)
+		resource.SetMetadata("namespace", "infra")
+		if tenant != "" {
+			resource.SetMetadata("tenant", tenant)
+		}
+		return resource
+	}
+
+	t.Run("groups are written to their tenant", func(t *testing.T) {
+		require.NoError(t, h.Add(group("default", "")))
+		require.NoError(t, h.Add(group("configured", "team-a")))
+		require.NoError(t, h.Add(group("other", "team-b")))
+		require.Equal(t, map[string][]string{"team-a": {"default", "configured"}, "team-b": {"other"}}, created)
+	})
+
+	t.Run("groups of other tenants are named after their tenant", func(t *testing.T) {
+		uid, err := h.GetUID(group("other", "team-b"))
+		require.NoError(t, err)
+		require.Equal(t, "team-b:infra.other", uid)
+
+		uid, err = h.GetUID(group("configured", "team-a"))
+		require.NoError(t, err)
+		require.Equal(t, "infra.configured", uid, "groups of the configured tenant keep their UIDs")
+
+		require.Equal(t, filepath.Join("prometheus", "team-b", "rules", "infra", "other.yaml"), filepath.FromSlash(h.ResourceFilePath(group("other", "team-b"), "yaml")))
+	})
+
+	t.Run("groups are read from their tenant", func(t *testing.T) {
+		h.InvalidateListCache()
+		remote, err := h.GetByUID("team-b:infra.other")
+		require.NoError(t, err)
+		require.Equal(t, "team-b", remote.GetMetadata("tenant"))
+
+		namespaced := group("namespaced", "")
+		namespaced.SetMetadata("namespace", "team:infra")
+		uid, err := h.GetUID(namespaced)
+		require.NoError(t, err)
+		require.Equal(t, "team:infra.namespaced", uid)
+		tenant, namespace, name, err := h.splitRuleGroupUID(uid)
+		require.NoError(t, err)
+		require.Equal(t, []string{"", "team:infra", "namespaced"}, []string{tenant, namespace, name}, "namespaces containing the separator aren't read as tenants")
+
+		_, err = h.GetRemote(group("other", "team-a"))
+		require.ErrorIs(t, err, grizzly.ErrNotFound)
+
+		remote, err = h.GetRemote(group("configured", "team-a"))
+		require.NoError(t, err)
+		require.Equal(t, "team-a", remote.GetMetadata("tenant"))
+
+		uids, err := h.ListRemote()
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"infra.default", "infra.configured"}, uids, "only the groups of the configured tenant are listed")
+	})
+}
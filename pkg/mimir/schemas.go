@@ -0,0 +1,25 @@
+package mimir
+
+import (
+	"embed"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+// schemas documents the fields of the kinds of the provider, one file per kind
+//
+//go:embed schemas/*.yaml
+var schemas embed.FS
+
+var _ grizzly.SchemaDescriber = &RuleHandler{}
+var _ grizzly.SchemaDescriber = &AlertmanagerConfigHandler{}
+
+// Schema implements grizzly.SchemaDescriber
+func (h *RuleHandler) Schema() (*grizzly.Schema, error) {
+	return grizzly.LoadSchema(schemas, h.Kind())
+}
+
+// Schema implements grizzly.SchemaDescriber
+func (h *AlertmanagerConfigHandler) Schema() (*grizzly.Schema, error) {
+	return grizzly.LoadSchema(schemas, h.Kind())
+}
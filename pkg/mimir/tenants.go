@@ -0,0 +1,192 @@
+package mimir
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
+	"github.com/grafana/grizzly/pkg/mimir/client"
+	"github.com/hashicorp/go-multierror"
+	"gopkg.in/yaml.v3"
+)
+
+// AllTenants selects every tenant of a cluster in `grr tenants`
+const AllTenants = "all"
+
+// TenantRules counts the rule groups of each ruler namespace of a tenant
+type TenantRules struct {
+	// Context is the context the tenant is configured in. Empty for tenants found with admin APIs
+	Context    string         `json:"context,omitempty" yaml:"context,omitempty"`
+	Tenant     string         `json:"tenant" yaml:"tenant"`
+	Address    string         `json:"address" yaml:"address"`
+	Namespaces map[string]int `json:"namespaces" yaml:"namespaces"`
+}
+
+// RuleGroups returns the number of rule groups of the tenant
+func (t TenantRules) RuleGroups() int {
+	count := 0
+	for _, groups := range t.Namespaces {
+		count += groups
+	}
+	return count
+}
+
+// ConfiguredTenants lists the ruler namespaces of the tenants configured in
+// contexts. Tenants configured in several contexts are only listed once. An
+// empty tenant selects all the configured tenants.
+func ConfiguredTenants(contexts []config.Context, tenant string) ([]TenantRules, error) {
+	var tenants []TenantRules
+	var finalErr error
+	seen := map[string]bool{}
+
+	for _, context := range contexts {
+		mimirConfig := context.Mimir
+		if mimirConfig.Address == "" || mimirConfig.TenantID == "" {
+			continue
+		}
+		if tenant != "" && mimirConfig.TenantID != tenant {
+			continue
+		}
+		key := mimirConfig.Address + "/" + mimirConfig.TenantID
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		rules, err := tenantRules(client.NewHTTPClient(&mimirConfig), mimirConfig.Address, mimirConfig.TenantID)
+		if err != nil {
+			finalErr = multierror.Append(finalErr, fmt.Errorf("tenant %s of context %s: %w", mimirConfig.TenantID, context.Name, err))
+			continue
+		}
+		rules.Context = context.Name
+		tenants = append(tenants, rules)
+	}
+
+	if len(seen) == 0 {
+		if tenant != "" {
+			return nil, fmt.Errorf("tenant %s isn't configured in any context", tenant)
+		}
+		return nil, fmt.Errorf("no context configures mimir.address and mimir.tenant-id")
+	}
+
+	return tenants, finalErr
+}
+
+// ClusterTenants lists the ruler namespaces of every tenant of the cluster a
+// configuration points to. Tenants are found with the admin APIs of Mimir,
+// and their rules are listed with the credentials of the configuration.
+func ClusterTenants(mimirConfig config.MimirConfig) ([]TenantRules, error) {
+	lister, ok := client.NewHTTPClient(&mimirConfig).(client.TenantsLister)
+	if !ok {
+		return nil, fmt.Errorf("listing all tenants isn't supported by the client")
+	}
+	ids, err := lister.ListTenants()
+	if err != nil {
+		return nil, fmt.Errorf("listing all tenants requires the admin APIs of Mimir: %w", err)
+	}
+	sort.Strings(ids)
+
+	var tenants []TenantRules
+	var finalErr error
+	for _, id := range ids {
+		tenantConfig := mimirConfig
+		tenantConfig.TenantID = id
+
+		rules, err := tenantRules(client.NewHTTPClient(&tenantConfig), tenantConfig.Address, id)
+		if err != nil {
+			finalErr = multierror.Append(finalErr, fmt.Errorf("tenant %s: %w", id, err))
+			continue
+		}
+		tenants = append(tenants, rules)
+	}
+
+	return tenants, finalErr
+}
+
+func tenantRules(clientTool client.Mimir, address, tenant string) (TenantRules, error) {
+	groupings, err := clientTool.ListRules()
+	if err != nil {
+		return TenantRules{}, err
+	}
+
+	rules := TenantRules{
+		Tenant:     tenant,
+		Address:    address,
+		Namespaces: make(map[string]int, len(groupings)),
+	}
+	for namespace, groups := range groupings {
+		rules.Namespaces[namespace] = len(groups)
+	}
+	return rules, nil
+}
+
+// PrintTenants prints the ruler namespaces of tenants, in one of the
+// default, wide, json or yaml formats
+func PrintTenants(tenants []TenantRules, format string) error {
+	var output []byte
+	var err error
+	switch format {
+	case "yaml":
+		output, err = yaml.Marshal(tenants)
+	case "json":
+		output, err = json.MarshalIndent(tenants, "", "  ")
+	case "default":
+		output, err = tenantsTable(tenants, false)
+	case "wide":
+		output, err = tenantsTable(tenants, true)
+	default:
+		return fmt.Errorf("unknown format %s", format)
+	}
+	if err != nil {
+		return err
+	}
+	notifier.Print(string(output))
+	return nil
+}
+
+func tenantsTable(tenants []TenantRules, wide bool) ([]byte, error) {
+	var out bytes.Buffer
+	w := tabwriter.NewWriter(&out, 0, 0, 4, ' ', 0)
+
+	f := "%s\t%s\t%s\t%d\n"
+	header := "%s\t%s\t%s\t%s\n"
+	columns := []any{"CONTEXT", "TENANT", "NAMESPACE", "RULE GROUPS"}
+	if wide {
+		f = "%s\t%s\t%s\t%d\t%s\n"
+		header = "%s\t%s\t%s\t%s\t%s\n"
+		columns = append(columns, "ADDRESS")
+	}
+	fmt.Fprintf(w, header, columns...)
+
+	for _, tenant := range tenants {
+		context := tenant.Context
+		if context == "" {
+			context = "-"
+		}
+
+		namespaces := make([]string, 0, len(tenant.Namespaces))
+		for namespace := range tenant.Namespaces {
+			namespaces = append(namespaces, namespace)
+		}
+		sort.Strings(namespaces)
+
+		for _, namespace := range namespaces {
+			row := []any{context, tenant.Tenant, namespace, tenant.Namespaces[namespace]}
+			if wide {
+				row = append(row, tenant.Address)
+			}
+			fmt.Fprintf(w, f, row...)
+		}
+		total := []any{context, tenant.Tenant, "(total)", tenant.RuleGroups()}
+		if wide {
+			total = append(total, tenant.Address)
+		}
+		fmt.Fprintf(w, f, total...)
+	}
+	err := w.Flush()
+	return out.Bytes(), err
+}
@@ -0,0 +1,92 @@
+package mimir
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantRules(t *testing.T) {
+	client := &FakeClient{}
+
+	t.Run("rule groups are counted per namespace", func(t *testing.T) {
+		client.mockResponse(t, true, nil)
+		rules, err := tenantRules(client, "http://mimir", "tenant-1")
+		require.NoError(t, err)
+		require.Equal(t, "tenant-1", rules.Tenant)
+		require.Equal(t, map[string]int{"first_rules": 1}, rules.Namespaces)
+		require.Equal(t, 1, rules.RuleGroups())
+	})
+
+	t.Run("errors from the mimir client are returned", func(t *testing.T) {
+		client.mockResponse(t, false, errMimirClient)
+		_, err := tenantRules(client, "http://mimir", "tenant-1")
+		require.ErrorIs(t, err, errMimirClient)
+	})
+}
+
+func TestClusterTenants(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/distributor/all_user_stats":
+			require.Equal(t, "application/json", r.Header.Get("Accept"))
+			fmt.Fprint(w, `[{"userID": "tenant-2", "series": 10}, {"userID": "tenant-1", "series": 20}]`)
+		case "/prometheus/api/v1/rules":
+			fmt.Fprintf(w, `{"status": "success", "data": {"groups": [
+				{"name": "a", "file": "%[1]s-ns", "rules": []},
+				{"name": "b", "file": "%[1]s-ns", "rules": []},
+				{"name": "c", "file": "other", "rules": []}
+			]}}`, r.Header.Get("X-Scope-OrgID"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tenants, err := ClusterTenants(config.MimirConfig{Address: server.URL, TenantID: "admin"})
+	require.NoError(t, err)
+	require.Len(t, tenants, 2)
+	require.Equal(t, "tenant-1", tenants[0].Tenant)
+	require.Equal(t, map[string]int{"tenant-1-ns": 2, "other": 1}, tenants[0].Namespaces)
+	require.Equal(t, "tenant-2", tenants[1].Tenant)
+	require.Equal(t, 3, tenants[1].RuleGroups())
+
+	t.Run("admin APIs are required", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+
+		_, err := ClusterTenants(config.MimirConfig{Address: server.URL, TenantID: "admin"})
+		require.ErrorContains(t, err, "requires the admin APIs")
+	})
+}
+
+func TestConfiguredTenants(t *testing.T) {
+	t.Run("contexts without mimir are skipped", func(t *testing.T) {
+		_, err := ConfiguredTenants([]config.Context{{Name: "grafana-only"}}, "")
+		require.ErrorContains(t, err, "no context configures")
+	})
+
+	t.Run("unknown tenants are reported", func(t *testing.T) {
+		contexts := []config.Context{{Name: "prod", Mimir: config.MimirConfig{Address: "http://mimir", TenantID: "tenant-1"}}}
+		_, err := ConfiguredTenants(contexts, "tenant-2")
+		require.ErrorContains(t, err, "tenant tenant-2 isn't configured")
+	})
+}
+
+func TestTenantsTable(t *testing.T) {
+	tenants := []TenantRules{
+		{Context: "prod", Tenant: "tenant-1", Address: "http://mimir", Namespaces: map[string]int{"b": 2, "a": 1}},
+	}
+
+	out, err := tenantsTable(tenants, false)
+	require.NoError(t, err)
+	require.Equal(t, `CONTEXT    TENANT      NAMESPACE    RULE GROUPS
+prod       tenant-1    a            1
+prod       tenant-1    b            2
+prod       tenant-1    (total)      3
+`, string(out))
+}
@@ -0,0 +1,110 @@
+package oncall
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/grafana/grizzly/internal/httputils"
+)
+
+// Endpoints of the OnCall API, relative to the API URL
+const (
+	schedulesEndpoint          = "schedules"
+	escalationChainsEndpoint   = "escalation_chains"
+	escalationPoliciesEndpoint = "escalation_policies"
+	integrationsEndpoint       = "integrations"
+	routesEndpoint             = "routes"
+)
+
+// object is an object of the OnCall API, as JSON
+type object = map[string]any
+
+// page is a page of the objects listed by the OnCall API
+type page struct {
+	Next    string   `json:"next"`
+	Results []object `json:"results"`
+}
+
+// StatusError is returned when the OnCall API responds with an unsuccessful status
+type StatusError = httputils.StatusError
+
+// list retrieves every object of an endpoint, following the pages of the
+// results. Query filters the objects, such as by integration.
+func (p *Provider) list(endpoint string, query url.Values) ([]object, error) {
+	objects := []object{}
+	next := p.endpointURL(endpoint, "")
+	if len(query) > 0 {
+		next += "?" + query.Encode()
+	}
+	for next != "" {
+		var results page
+		if err := p.doRequest(http.MethodGet, next, nil, &results); err != nil {
+			return nil, err
+		}
+		objects = append(objects, results.Results...)
+		next = results.Next
+	}
+	return objects, nil
+}
+
+func (p *Provider) create(endpoint string, body object) (object, error) {
+	created := object{}
+	if err := p.doRequest(http.MethodPost, p.endpointURL(endpoint, ""), body, &created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (p *Provider) update(endpoint, id string, body object) error {
+	return p.doRequest(http.MethodPut, p.endpointURL(endpoint, id), body, nil)
+}
+
+func (p *Provider) delete(endpoint, id string) error {
+	return p.doRequest(http.MethodDelete, p.endpointURL(endpoint, id), nil, nil)
+}
+
+// endpointURL returns the URL of an endpoint, or of one of its objects. The
+// OnCall API expects trailing slashes.
+func (p *Provider) endpointURL(endpoint, id string) string {
+	address := strings.TrimSuffix(p.config.URL, "/") + "/api/v1/" + endpoint + "/"
+	if id != "" {
+		address += url.PathEscape(id) + "/"
+	}
+	return address
+}
+
+// doRequest sends a request to the OnCall API, and decodes its response into
+// result, when given
+func (p *Provider) doRequest(method, address string, body, result any) error {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, address, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", p.config.Token)
+
+	client, err := httputils.NewHTTPClientWithTransport(p.transport)
+	if err != nil {
+		return err
+	}
+	data, err := httputils.Do(client, req, "the OnCall API")
+	if err != nil {
+		return err
+	}
+	if result == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, result)
+}
@@ -0,0 +1,111 @@
+package oncall
+
+import (
+	"fmt"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+const OnCallEscalationChainKind = "OnCallEscalationChain"
+
+var _ grizzly.Handler = &EscalationChainHandler{}
+var _ grizzly.ErrorHinter = &EscalationChainHandler{}
+
+// EscalationChainHandler is a Grizzly Handler for the escalation chains of
+// Grafana OnCall. The escalation policies of a chain are managed with it, as
+// its policies field, in the order they're followed.
+type EscalationChainHandler struct {
+	objectHandler
+}
+
+// NewEscalationChainHandler returns a Grizzly Handler for OnCall escalation chains
+func NewEscalationChainHandler(provider *Provider) *EscalationChainHandler {
+	return &EscalationChainHandler{
+		objectHandler: newObjectHandler(provider, OnCallEscalationChainKind, escalationChainsEndpoint, "oncall/escalation-chain-%s.%s"),
+	}
+}
+
+// GetByUID retrieves JSON for a resource from an endpoint, by UID
+func (h *EscalationChainHandler) GetByUID(uid string) (*grizzly.Resource, error) {
+	chain, err := h.findObject(uid)
+	if err != nil {
+		return nil, err
+	}
+	policies, err := h.listChildren(escalationPoliciesEndpoint, "escalation_chain_id", objectID(chain))
+	if err != nil {
+		return nil, err
+	}
+
+	specPolicies := make([]any, 0, len(policies))
+	for _, policy := range policies {
+		for _, key := range []string{"id", "escalation_chain_id", "position"} {
+			delete(policy, key)
+		}
+		// schedules are referred to by name
+		if err := h.idToName(policy, "notify_on_call_from_schedule", "notify_on_call_from_schedule", schedulesEndpoint); err != nil {
+			return nil, err
+		}
+		specPolicies = append(specPolicies, policy)
+	}
+	chain["policies"] = specPolicies
+	return h.objectToResource(chain)
+}
+
+// GetRemote retrieves an escalation chain as a Resource
+func (h *EscalationChainHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
+	return h.GetByUID(resource.Name())
+}
+
+// Add creates an escalation chain, then its policies
+func (h *EscalationChainHandler) Add(resource grizzly.Resource) error {
+	chain, policies, err := h.chainAndPolicies(resource)
+	if err != nil {
+		return err
+	}
+	created, err := h.provider.create(h.endpoint, chain)
+	if err != nil {
+		return err
+	}
+	h.InvalidateListCache()
+	return h.replaceChildren(escalationPoliciesEndpoint, "escalation_chain_id", objectID(created), nil, policies)
+}
+
+// Update pushes an updated escalation chain to OnCall, replacing its policies
+func (h *EscalationChainHandler) Update(existing, resource grizzly.Resource) error {
+	remote, err := h.findObject(resource.Name())
+	if err != nil {
+		return err
+	}
+	chain, policies, err := h.chainAndPolicies(resource)
+	if err != nil {
+		return err
+	}
+	if err := h.provider.update(h.endpoint, objectID(remote), chain); err != nil {
+		return err
+	}
+	h.InvalidateListCache()
+
+	existingPolicies, err := h.listChildren(escalationPoliciesEndpoint, "escalation_chain_id", objectID(remote))
+	if err != nil {
+		return err
+	}
+	return h.replaceChildren(escalationPoliciesEndpoint, "escalation_chain_id", objectID(remote), existingPolicies, policies)
+}
+
+// chainAndPolicies splits a resource into the escalation chain and the
+// policies the API expects
+func (h *EscalationChainHandler) chainAndPolicies(resource grizzly.Resource) (object, []object, error) {
+	chain := h.specToObject(resource)
+	policies, err := objectList(chain, "policies")
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", resource.Ref(), err)
+	}
+	delete(chain, "policies")
+
+	for _, policy := range policies {
+		if err := h.nameToID(policy, "notify_on_call_from_schedule", "notify_on_call_from_schedule", schedulesEndpoint); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", resource.Ref(), err)
+		}
+	}
+	return chain, policies, nil
+}
@@ -0,0 +1,142 @@
+package oncall
+
+import (
+	"fmt"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+const OnCallIntegrationKind = "OnCallIntegration"
+
+var _ grizzly.Handler = &IntegrationHandler{}
+var _ grizzly.ErrorHinter = &IntegrationHandler{}
+
+// IntegrationHandler is a Grizzly Handler for the integrations of Grafana
+// OnCall, which receive alerts. The routes of an integration are managed with
+// it, as its routes field, in the order they're matched, and send alerts to
+// escalation chains referred to by name.
+type IntegrationHandler struct {
+	objectHandler
+}
+
+// NewIntegrationHandler returns a Grizzly Handler for OnCall integrations
+func NewIntegrationHandler(provider *Provider) *IntegrationHandler {
+	return &IntegrationHandler{
+		objectHandler: newObjectHandler(provider, OnCallIntegrationKind, integrationsEndpoint, "oncall/integration-%s.%s", "link", "inbound_email"),
+	}
+}
+
+// GetByUID retrieves JSON for a resource from an endpoint, by UID
+func (h *IntegrationHandler) GetByUID(uid string) (*grizzly.Resource, error) {
+	integration, err := h.findObject(uid)
+	if err != nil {
+		return nil, err
+	}
+	if defaultRoute, ok := integration["default_route"].(map[string]any); ok {
+		defaultRoute = copyObject(defaultRoute)
+		delete(defaultRoute, "id")
+		if err := h.idToName(defaultRoute, "escalation_chain_id", "escalation_chain", escalationChainsEndpoint); err != nil {
+			return nil, err
+		}
+		integration["default_route"] = defaultRoute
+	}
+
+	routes, err := h.listRoutes(objectID(integration))
+	if err != nil {
+		return nil, err
+	}
+	specRoutes := make([]any, 0, len(routes))
+	for _, route := range routes {
+		for _, key := range []string{"id", "integration_id", "position", "is_the_last_route"} {
+			delete(route, key)
+		}
+		if err := h.idToName(route, "escalation_chain_id", "escalation_chain", escalationChainsEndpoint); err != nil {
+			return nil, err
+		}
+		specRoutes = append(specRoutes, route)
+	}
+	integration["routes"] = specRoutes
+	return h.objectToResource(integration)
+}
+
+// GetRemote retrieves an integration as a Resource
+func (h *IntegrationHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
+	return h.GetByUID(resource.Name())
+}
+
+// Add creates an integration, then its routes
+func (h *IntegrationHandler) Add(resource grizzly.Resource) error {
+	integration, routes, err := h.integrationAndRoutes(resource)
+	if err != nil {
+		return err
+	}
+	created, err := h.provider.create(h.endpoint, integration)
+	if err != nil {
+		return err
+	}
+	h.InvalidateListCache()
+	return h.replaceChildren(routesEndpoint, "integration_id", objectID(created), nil, routes)
+}
+
+// Update pushes an updated integration to OnCall, replacing its routes
+func (h *IntegrationHandler) Update(existing, resource grizzly.Resource) error {
+	remote, err := h.findObject(resource.Name())
+	if err != nil {
+		return err
+	}
+	integration, routes, err := h.integrationAndRoutes(resource)
+	if err != nil {
+		return err
+	}
+	if err := h.provider.update(h.endpoint, objectID(remote), integration); err != nil {
+		return err
+	}
+	h.InvalidateListCache()
+
+	existingRoutes, err := h.listRoutes(objectID(remote))
+	if err != nil {
+		return err
+	}
+	return h.replaceChildren(routesEndpoint, "integration_id", objectID(remote), existingRoutes, routes)
+}
+
+// listRoutes retrieves the routes of an integration, but for its default
+// route, which is managed with the integration
+func (h *IntegrationHandler) listRoutes(integrationID string) ([]object, error) {
+	routes, err := h.listChildren(routesEndpoint, "integration_id", integrationID)
+	if err != nil {
+		return nil, err
+	}
+	var filtered []object
+	for _, route := range routes {
+		if last, _ := route["is_the_last_route"].(bool); !last {
+			filtered = append(filtered, route)
+		}
+	}
+	return filtered, nil
+}
+
+// integrationAndRoutes splits a resource into the integration and the routes
+// the API expects
+func (h *IntegrationHandler) integrationAndRoutes(resource grizzly.Resource) (object, []object, error) {
+	integration := h.specToObject(resource)
+	routes, err := objectList(integration, "routes")
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", resource.Ref(), err)
+	}
+	delete(integration, "routes")
+
+	if defaultRoute, ok := integration["default_route"].(map[string]any); ok {
+		defaultRoute = copyObject(defaultRoute)
+		if err := h.nameToID(defaultRoute, "escalation_chain", "escalation_chain_id", escalationChainsEndpoint); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", resource.Ref(), err)
+		}
+		integration["default_route"] = defaultRoute
+	}
+	for _, route := range routes {
+		if err := h.nameToID(route, "escalation_chain", "escalation_chain_id", escalationChainsEndpoint); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", resource.Ref(), err)
+		}
+	}
+	return integration, routes, nil
+}
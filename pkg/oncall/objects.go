@@ -0,0 +1,280 @@
+package oncall
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+// objectHandler implements what the handlers of OnCall objects share: objects
+// are identified by their name, which the API keeps unique, and are listed
+// once per run
+type objectHandler struct {
+	grizzly.BaseHandler
+	provider    *Provider
+	endpoint    string
+	filePattern string
+	// serverFields are set by the API, and removed from remote resources
+	serverFields []string
+}
+
+func newObjectHandler(provider *Provider, kind, endpoint, filePattern string, serverFields ...string) objectHandler {
+	return objectHandler{
+		BaseHandler:  grizzly.NewBaseHandler(provider, kind, false).WithListCache(),
+		provider:     provider,
+		endpoint:     endpoint,
+		filePattern:  filePattern,
+		serverFields: append([]string{"id"}, serverFields...),
+	}
+}
+
+// ErrorHint implements grizzly.ErrorHinter
+func (h *objectHandler) ErrorHint(code grizzly.ErrorCode) string {
+	switch code {
+	case grizzly.ErrorCodeAuthFailed:
+		return "check oncall.token with `grr config check`, it must be an OnCall API token"
+	case grizzly.ErrorCodePermissionDenied:
+		return "the OnCall API token lacks the permissions to manage " + objectNoun(h.endpoint) + "s"
+	case grizzly.ErrorCodeVersionUnsupported:
+		return "the OnCall API wasn't found, check that oncall.url is the API URL shown in the OnCall settings, ending with /oncall"
+	default:
+		return ""
+	}
+}
+
+// ResourceFilePath returns the location on disk where a resource should be updated
+func (h *objectHandler) ResourceFilePath(resource grizzly.Resource, filetype string) string {
+	filename := strings.ReplaceAll(resource.Name(), string(os.PathSeparator), "-")
+	return fmt.Sprintf(h.filePattern, filename, filetype)
+}
+
+// Unprepare removes unnecessary elements from a remote resource ready for presentation/comparison
+func (h *objectHandler) Unprepare(resource grizzly.Resource) *grizzly.Resource {
+	for _, key := range h.serverFields {
+		resource.DeleteSpecKey(key)
+	}
+	return &resource
+}
+
+// Prepare gets a resource ready for dispatch to the remote endpoint
+func (h *objectHandler) Prepare(existing *grizzly.Resource, resource grizzly.Resource) *grizzly.Resource {
+	if _, exists := resource.GetSpecString("name"); !exists {
+		resource.SetSpecString("name", resource.Name())
+	}
+	return &resource
+}
+
+// Validate checks that the name of the object matches its metadata
+func (h *objectHandler) Validate(resource grizzly.Resource) error {
+	name, exist := resource.GetSpecString("name")
+	if exist && name != resource.Name() {
+		return fmt.Errorf("name '%s' and metadata name '%s', don't match", name, resource.Name())
+	}
+	return nil
+}
+
+// GetUID returns the UID for a resource
+func (h *objectHandler) GetUID(resource grizzly.Resource) (string, error) {
+	return resource.Name(), nil
+}
+
+func (h *objectHandler) GetSpecUID(resource grizzly.Resource) (string, error) {
+	name, _ := resource.GetSpecString("name")
+	return name, nil
+}
+
+// ListRemote retrieves as list of UIDs of all remote resources
+func (h *objectHandler) ListRemote() ([]string, error) {
+	objects, err := h.listObjects()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		name, _ := obj["name"].(string)
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// Delete removes an object from OnCall
+func (h *objectHandler) Delete(resource grizzly.Resource) error {
+	remote, err := h.findObject(resource.Name())
+	if err != nil {
+		return err
+	}
+	if err := h.provider.delete(h.endpoint, objectID(remote)); err != nil {
+		return err
+	}
+	h.InvalidateListCache()
+	return nil
+}
+
+// listObjects retrieves the objects of the endpoint, cached for the run
+func (h *objectHandler) listObjects() ([]object, error) {
+	return grizzly.CachedList(&h.BaseHandler, h.endpoint, func() ([]object, error) {
+		return h.provider.list(h.endpoint, nil)
+	})
+}
+
+func (h *objectHandler) findObject(name string) (object, error) {
+	objects, err := h.listObjects()
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objects {
+		if obj["name"] == name {
+			return copyObject(obj), nil
+		}
+	}
+	return nil, grizzly.ErrNotFound
+}
+
+// specToObject returns the object the API expects for a resource
+func (h *objectHandler) specToObject(resource grizzly.Resource) object {
+	obj := copyObject(resource.Spec())
+	obj["name"] = resource.Name()
+	return obj
+}
+
+func (h *objectHandler) objectToResource(obj object) (*grizzly.Resource, error) {
+	name, _ := obj["name"].(string)
+	resource, err := grizzly.NewResource(h.APIVersion(), h.Kind(), name, obj)
+	if err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+// idToName replaces the ID of an object of another endpoint referenced by a
+// field with the name of the object, under nameField, so that resources
+// refer to each other by name, as IDs are assigned by the API
+func (h *objectHandler) idToName(obj object, idField, nameField, endpoint string) error {
+	id, _ := obj[idField].(string)
+	delete(obj, idField)
+	if id == "" {
+		return nil
+	}
+
+	find := func(objects []object) string {
+		for _, candidate := range objects {
+			if objectID(candidate) == id {
+				name, _ := candidate["name"].(string)
+				return name
+			}
+		}
+		return ""
+	}
+	objects, err := grizzly.CachedList(&h.BaseHandler, "references/"+endpoint, func() ([]object, error) {
+		return h.provider.list(endpoint, nil)
+	})
+	if err != nil {
+		return err
+	}
+	name := find(objects)
+	if name == "" {
+		// the object may have been added since the objects were listed
+		if objects, err = h.provider.list(endpoint, nil); err != nil {
+			return err
+		}
+		if name = find(objects); name == "" {
+			return fmt.Errorf("%s %s doesn't exist", objectNoun(endpoint), id)
+		}
+	}
+	obj[nameField] = name
+	return nil
+}
+
+// nameToID replaces the name of an object of another endpoint referenced by
+// a field with the ID of the object, under idField, as the API expects
+func (h *objectHandler) nameToID(obj object, nameField, idField, endpoint string) error {
+	name, _ := obj[nameField].(string)
+	delete(obj, nameField)
+	if name == "" {
+		return nil
+	}
+	objects, err := h.provider.list(endpoint, nil)
+	if err != nil {
+		return err
+	}
+	for _, candidate := range objects {
+		if candidate["name"] == name {
+			obj[idField] = objectID(candidate)
+			return nil
+		}
+	}
+	return fmt.Errorf("%s '%s' doesn't exist", objectNoun(endpoint), name)
+}
+
+// listChildren retrieves the objects belonging to another, such as the
+// routes of an integration, in the order of their position
+func (h *objectHandler) listChildren(endpoint, parentField, parentID string) ([]object, error) {
+	children, err := h.provider.list(endpoint, url.Values{parentField: {parentID}})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(children, func(i, j int) bool {
+		return position(children[i]) < position(children[j])
+	})
+	return children, nil
+}
+
+// replaceChildren replaces the objects belonging to another with the given
+// ones, in their order. Children are replaced whole rather than matched, as
+// they have no name to be matched by.
+func (h *objectHandler) replaceChildren(endpoint, parentField, parentID string, existing, children []object) error {
+	for _, child := range existing {
+		if err := h.provider.delete(endpoint, objectID(child)); err != nil {
+			return err
+		}
+	}
+	for i, child := range children {
+		child[parentField] = parentID
+		child["position"] = i
+		if _, err := h.provider.create(endpoint, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// objectList returns the objects of a list field of a spec, such as routes
+func objectList(spec object, field string) ([]object, error) {
+	items, _ := spec[field].([]any)
+	objects := make([]object, 0, len(items))
+	for i, item := range items {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s[%d] should be an object", field, i)
+		}
+		objects = append(objects, copyObject(obj))
+	}
+	return objects, nil
+}
+
+// objectNoun names the objects of an endpoint in messages. Ex: escalation chain
+func objectNoun(endpoint string) string {
+	return strings.ReplaceAll(strings.TrimSuffix(endpoint, "s"), "_", " ")
+}
+
+func objectID(obj object) string {
+	id, _ := obj["id"].(string)
+	return id
+}
+
+func position(obj object) float64 {
+	value, _ := obj["position"].(float64)
+	return value
+}
+
+func copyObject(obj map[string]any) object {
+	copied := make(object, len(obj))
+	for key, value := range obj {
+		copied[key] = value
+	}
+	return copied
+}
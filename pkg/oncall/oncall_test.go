@@ -0,0 +1,191 @@
+package oncall
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOnCall is an in-memory OnCall API, listing objects two per page
+type fakeOnCall struct {
+	mu      sync.Mutex
+	objects map[string]map[string]object
+	nextID  int
+}
+
+func (f *fakeOnCall) add(endpoint string, obj object) string {
+	f.nextID++
+	id := fmt.Sprintf("%s%d", strings.ToUpper(endpoint[:1]), f.nextID)
+	obj["id"] = id
+	if f.objects[endpoint] == nil {
+		f.objects[endpoint] = map[string]object{}
+	}
+	f.objects[endpoint][id] = obj
+	return id
+}
+
+func (f *fakeOnCall) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/oncall/api/v1/"), "/"), "/")
+	endpoint := path[0]
+	switch {
+	case r.Method == http.MethodGet && len(path) == 1:
+		filters := r.URL.Query()
+		pageNumber, _ := strconv.Atoi(filters.Get("page"))
+		filters.Del("page")
+		var matching []object
+		for _, obj := range f.objects[endpoint] {
+			for field := range filters {
+				if obj[field] != filters.Get(field) {
+					obj = nil
+					break
+				}
+			}
+			if obj != nil {
+				matching = append(matching, obj)
+			}
+		}
+		sort.Slice(matching, func(i, j int) bool { return objectID(matching[i]) < objectID(matching[j]) })
+
+		start := min(pageNumber*2, len(matching))
+		end := min(start+2, len(matching))
+		response := page{Results: matching[start:end]}
+		if end < len(matching) {
+			response.Next = fmt.Sprintf("http://%s%s?page=%d", r.Host, r.URL.Path, pageNumber+1)
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	case r.Method == http.MethodPost:
+		obj := object{}
+		_ = json.NewDecoder(r.Body).Decode(&obj)
+		if endpoint == integrationsEndpoint {
+			// integrations are created with a default route, the last one
+			defaultRoute, _ := obj["default_route"].(map[string]any)
+			route := object{"is_the_last_route": true, "position": 0, "escalation_chain_id": defaultRoute["escalation_chain_id"]}
+			routeID := f.add(routesEndpoint, route)
+			obj["default_route"] = object{"id": routeID, "escalation_chain_id": defaultRoute["escalation_chain_id"]}
+			obj["link"] = "https://oncall.example.com/integrations/v1/alertmanager/abc/"
+			route["integration_id"] = f.add(endpoint, obj)
+		} else {
+			f.add(endpoint, obj)
+		}
+		_ = json.NewEncoder(w).Encode(obj)
+	case r.Method == http.MethodPut:
+		obj := object{}
+		_ = json.NewDecoder(r.Body).Decode(&obj)
+		obj["id"] = path[1]
+		f.objects[endpoint][path[1]] = obj
+		_ = json.NewEncoder(w).Encode(obj)
+	case r.Method == http.MethodDelete:
+		if _, ok := f.objects[endpoint][path[1]]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(f.objects[endpoint], path[1])
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestOnCallHandlers(t *testing.T) {
+	fake := &fakeOnCall{objects: map[string]map[string]object{}}
+	scheduleID := fake.add(schedulesEndpoint, object{"name": "primary", "type": "ical", "time_zone": "UTC", "on_call_now": []any{"U1"}})
+	for _, name := range []string{"secondary", "tertiary"} {
+		fake.add(schedulesEndpoint, object{"name": name, "type": "web"})
+	}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	provider := NewProvider(&config.OnCallConfig{URL: server.URL + "/oncall", Token: "token"})
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+	schedules := NewScheduleHandler(provider)
+
+	t.Run("schedules are listed from every page", func(t *testing.T) {
+		names, err := schedules.ListRemote()
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"primary", "secondary", "tertiary"}, names)
+
+		remote, err := schedules.GetByUID("primary")
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"name": "primary", "type": "ical", "time_zone": "UTC"}, schedules.Unprepare(*remote).Spec())
+	})
+
+	chain, err := grizzly.NewResource(provider.APIVersion(), OnCallEscalationChainKind, "critical", map[string]any{
+		"policies": []any{
+			map[string]any{"type": "notify_on_call_from_schedule", "notify_on_call_from_schedule": "primary"},
+			map[string]any{"type": "wait", "duration": 300},
+		},
+	})
+	require.NoError(t, err)
+	integration, err := grizzly.NewResource(provider.APIVersion(), OnCallIntegrationKind, "alertmanager", map[string]any{
+		"type":          "alertmanager",
+		"default_route": map[string]any{"escalation_chain": "critical"},
+		"routes": []any{
+			map[string]any{"routing_type": "regex", "routing_regex": "severity=page", "escalation_chain": "critical"},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("resources refer to each other by name", func(t *testing.T) {
+		var out strings.Builder
+		recorder := grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText)
+		require.NoError(t, grizzly.Apply(registry, registry.Sort(grizzly.NewResources(integration, chain)), grizzly.ApplyOptions{}, recorder))
+		require.Equal(t, 2, recorder.Summary().EventCounts[grizzly.ResourceAdded])
+
+		var chainID string
+		for id, obj := range fake.objects[escalationChainsEndpoint] {
+			require.Equal(t, "critical", obj["name"])
+			chainID = id
+		}
+		var policies []string
+		for _, policy := range fake.objects[escalationPoliciesEndpoint] {
+			require.Equal(t, chainID, policy["escalation_chain_id"])
+			if policy["type"] == "notify_on_call_from_schedule" {
+				require.Equal(t, scheduleID, policy["notify_on_call_from_schedule"])
+			}
+			policies = append(policies, policy["type"].(string))
+		}
+		require.ElementsMatch(t, []string{"notify_on_call_from_schedule", "wait"}, policies)
+		for _, route := range fake.objects[routesEndpoint] {
+			require.Equal(t, chainID, route["escalation_chain_id"])
+		}
+	})
+
+	t.Run("applying again changes nothing", func(t *testing.T) {
+		var out strings.Builder
+		recorder := grizzly.NewWriterRecorder(&out, grizzly.EventToPlainText)
+		require.NoError(t, grizzly.Apply(registry, registry.Sort(grizzly.NewResources(chain, integration)), grizzly.ApplyOptions{}, recorder))
+		require.Equal(t, 2, recorder.Summary().EventCounts[grizzly.ResourceNotChanged], out.String())
+	})
+
+	t.Run("routes are replaced in order", func(t *testing.T) {
+		updated := integration.DeepCopy()
+		updated.SetSpecValue("routes", []any{
+			map[string]any{"routing_type": "regex", "routing_regex": "team=db", "escalation_chain": "critical"},
+			map[string]any{"routing_type": "regex", "routing_regex": "severity=page", "escalation_chain": "critical"},
+		})
+		handler := NewIntegrationHandler(provider)
+		require.NoError(t, handler.Update(integration, updated))
+
+		remote, err := handler.GetByUID("alertmanager")
+		require.NoError(t, err)
+		require.Equal(t, updated.GetSpecValue("routes"), handler.Unprepare(*remote).GetSpecValue("routes"))
+		require.Len(t, fake.objects[routesEndpoint], 3, "the default route is kept")
+	})
+
+	t.Run("escalation chains must exist", func(t *testing.T) {
+		broken := integration.DeepCopy()
+		broken.SetSpecValue("default_route", map[string]any{"escalation_chain": "missing"})
+		require.EqualError(t, NewIntegrationHandler(provider).Update(integration, broken), "OnCallIntegration.alertmanager: escalation chain 'missing' doesn't exist")
+	})
+}
@@ -0,0 +1,90 @@
+package oncall
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+// Provider is a grizzly.Provider implementation for Grafana OnCall.
+type Provider struct {
+	config    *config.OnCallConfig
+	transport http.RoundTripper
+}
+
+// NewProvider instantiates a new Provider.
+func NewProvider(config *config.OnCallConfig) *Provider {
+	return &Provider{
+		config: config,
+	}
+}
+
+// WithTransport sends the requests of the provider through a transport of
+// its own, such as one signing requests or recording them
+func (p *Provider) WithTransport(transport http.RoundTripper) *Provider {
+	p.transport = transport
+	return p
+}
+
+func (p *Provider) Validate() error {
+	if p.config.URL == "" {
+		return fmt.Errorf("oncall url is not set")
+	}
+	if p.config.Token == "" {
+		return fmt.Errorf("oncall token is not set")
+	}
+	return nil
+}
+
+func (p *Provider) Status() grizzly.ProviderStatus {
+	status := grizzly.ProviderStatus{}
+
+	if err := p.Validate(); err != nil {
+		status.ActiveReason = err.Error()
+		return status
+	}
+
+	status.Active = true
+
+	if _, err := p.list(schedulesEndpoint, nil); err != nil {
+		status.OnlineReason = err.Error()
+		return status
+	}
+
+	status.Online = true
+
+	return status
+}
+
+func (p *Provider) Name() string {
+	return "OnCall"
+}
+
+// Group returns the group name of the OnCall provider
+func (p *Provider) Group() string {
+	return "grizzly.grafana.com"
+}
+
+// Version returns the version of this provider
+func (p *Provider) Version() string {
+	return "v1alpha1"
+}
+
+// APIVersion returns the group and version of this provider
+func (p *Provider) APIVersion() string {
+	return filepath.Join(p.Group(), p.Version())
+}
+
+// GetHandlers identifies the handlers for the OnCall provider
+func (p *Provider) GetHandlers() []grizzly.Handler {
+	// escalation chains notify schedules, and integrations route alerts to
+	// escalation chains, so they're applied in that order
+	return []grizzly.Handler{
+		NewScheduleHandler(p),
+		NewEscalationChainHandler(p),
+		NewIntegrationHandler(p),
+	}
+}
@@ -0,0 +1,58 @@
+package oncall
+
+import (
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+const OnCallScheduleKind = "OnCallSchedule"
+
+var _ grizzly.Handler = &ScheduleHandler{}
+var _ grizzly.ErrorHinter = &ScheduleHandler{}
+
+// ScheduleHandler is a Grizzly Handler for the schedules of Grafana OnCall
+type ScheduleHandler struct {
+	objectHandler
+}
+
+// NewScheduleHandler returns a Grizzly Handler for OnCall schedules
+func NewScheduleHandler(provider *Provider) *ScheduleHandler {
+	return &ScheduleHandler{
+		objectHandler: newObjectHandler(provider, OnCallScheduleKind, schedulesEndpoint, "oncall/schedule-%s.%s", "on_call_now"),
+	}
+}
+
+// GetByUID retrieves JSON for a resource from an endpoint, by UID
+func (h *ScheduleHandler) GetByUID(uid string) (*grizzly.Resource, error) {
+	schedule, err := h.findObject(uid)
+	if err != nil {
+		return nil, err
+	}
+	return h.objectToResource(schedule)
+}
+
+// GetRemote retrieves a schedule as a Resource
+func (h *ScheduleHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
+	return h.GetByUID(resource.Name())
+}
+
+// Add creates a schedule
+func (h *ScheduleHandler) Add(resource grizzly.Resource) error {
+	if _, err := h.provider.create(h.endpoint, h.specToObject(resource)); err != nil {
+		return err
+	}
+	h.InvalidateListCache()
+	return nil
+}
+
+// Update pushes an updated schedule to OnCall
+func (h *ScheduleHandler) Update(existing, resource grizzly.Resource) error {
+	remote, err := h.findObject(resource.Name())
+	if err != nil {
+		return err
+	}
+	if err := h.provider.update(h.endpoint, objectID(remote), h.specToObject(resource)); err != nil {
+		return err
+	}
+	h.InvalidateListCache()
+	return nil
+}
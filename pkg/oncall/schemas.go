@@ -0,0 +1,31 @@
+package oncall
+
+import (
+	"embed"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+// schemas documents the fields of the kinds of the provider, one file per kind
+//
+//go:embed schemas/*.yaml
+var schemas embed.FS
+
+var _ grizzly.SchemaDescriber = &ScheduleHandler{}
+var _ grizzly.SchemaDescriber = &EscalationChainHandler{}
+var _ grizzly.SchemaDescriber = &IntegrationHandler{}
+
+// Schema implements grizzly.SchemaDescriber
+func (h *ScheduleHandler) Schema() (*grizzly.Schema, error) {
+	return grizzly.LoadSchema(schemas, h.Kind())
+}
+
+// Schema implements grizzly.SchemaDescriber
+func (h *EscalationChainHandler) Schema() (*grizzly.Schema, error) {
+	return grizzly.LoadSchema(schemas, h.Kind())
+}
+
+// Schema implements grizzly.SchemaDescriber
+func (h *IntegrationHandler) Schema() (*grizzly.Schema, error) {
+	return grizzly.LoadSchema(schemas, h.Kind())
+}
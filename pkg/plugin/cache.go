@@ -0,0 +1,134 @@
+package plugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/plugin/proto"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// pluginsCacheFormat changes whenever the information cached about plugins
+// does, so that the plugins cached by previous versions of Grizzly are
+// discovered again
+const pluginsCacheFormat = "1"
+
+// pluginsCache keeps the providers of plugins, as described by the plugins
+// once configured, so that plugins aren't started to discover their kinds on
+// every run of grr
+type pluginsCache struct {
+	Format  string                       `json:"format"`
+	Plugins map[string]pluginsCacheEntry `json:"plugins"`
+
+	changed bool
+}
+
+// pluginsCacheEntry is the provider of a plugin, configured with some
+// settings. The executable of the plugin is told apart by its size and
+// modification time, so that plugins are discovered again once updated.
+type pluginsCacheEntry struct {
+	Path     string          `json:"path"`
+	Size     int64           `json:"size"`
+	ModTime  time.Time       `json:"modTime"`
+	Provider json.RawMessage `json:"provider"`
+}
+
+// readPluginsCache reads the cache kept in file. Missing, outdated or
+// unreadable caches are empty, and the entries of plugins that changed since
+// they were cached are dropped.
+func readPluginsCache(file string) *pluginsCache {
+	cache := &pluginsCache{Format: pluginsCacheFormat, Plugins: map[string]pluginsCacheEntry{}}
+	if file == "" {
+		return cache
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return cache
+	}
+	var cached pluginsCache
+	if err := json.Unmarshal(data, &cached); err != nil || cached.Format != pluginsCacheFormat {
+		log.Debugf("Ignoring the plugins cached in %s", file)
+		cache.changed = true
+		return cache
+	}
+	for key, entry := range cached.Plugins {
+		if size, modTime, ok := executableOf(entry.Path); ok && size == entry.Size && modTime.Equal(entry.ModTime) {
+			cache.Plugins[key] = entry
+			continue
+		}
+		cache.changed = true
+	}
+	return cache
+}
+
+// executableOf returns the size and modification time of the executable of
+// a plugin, following links
+func executableOf(path string) (int64, time.Time, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	return info.Size(), info.ModTime(), true
+}
+
+// cacheKey returns the key of a plugin configured with some settings. The
+// settings are hashed, as they can hold credentials.
+func cacheKey(path string, settings map[string]string) string {
+	hash := sha256.New()
+	if len(settings) > 0 {
+		// maps are encoded with sorted keys
+		data, _ := json.Marshal(settings)
+		hash.Write(data)
+	}
+	return path + "#" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// get returns the cached provider of a plugin configured with settings, if any
+func (c *pluginsCache) get(path string, settings map[string]string) (*proto.ProviderInfo, bool) {
+	entry, ok := c.Plugins[cacheKey(path, settings)]
+	if !ok {
+		return nil, false
+	}
+	info := &proto.ProviderInfo{}
+	if err := protojson.Unmarshal(entry.Provider, info); err != nil {
+		return nil, false
+	}
+	return info, true
+}
+
+// put caches the provider of a plugin configured with settings
+func (c *pluginsCache) put(path string, settings map[string]string, info *proto.ProviderInfo) {
+	size, modTime, ok := executableOf(path)
+	if !ok {
+		return
+	}
+	data, err := protojson.Marshal(info)
+	if err != nil {
+		return
+	}
+	c.Plugins[cacheKey(path, settings)] = pluginsCacheEntry{Path: path, Size: size, ModTime: modTime, Provider: data}
+	c.changed = true
+}
+
+// write writes the cache to file, if it changed. Failing to write the cache
+// doesn't fail the command, which only starts plugins again next time.
+func (c *pluginsCache) write(file string) {
+	if file == "" || !c.changed {
+		return
+	}
+	data, err := json.Marshal(c)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(file), 0700)
+	}
+	if err == nil {
+		err = os.WriteFile(file, data, 0600)
+	}
+	if err != nil {
+		log.Debugf("Caching plugins failed: %s", err)
+	}
+}
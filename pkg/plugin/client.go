@@ -0,0 +1,90 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/plugin/proto"
+	"github.com/hashicorp/go-hclog"
+	goplugin "github.com/hashicorp/go-plugin"
+)
+
+// StartTimeout is how long plugins have to start
+var StartTimeout = 10 * time.Second
+
+// Client is a plugin run by grr. The plugin is only started, and configured
+// with its settings, once it is called.
+type Client struct {
+	path     string
+	settings map[string]string
+
+	mu       sync.Mutex
+	client   *goplugin.Client
+	provider proto.ProviderClient
+	info     *proto.ProviderInfo
+}
+
+// NewClient returns the client of the plugin at path, configured with the
+// given settings once started
+func NewClient(path string, settings map[string]string) *Client {
+	return &Client{path: path, settings: settings}
+}
+
+// connect starts the plugin, and configures it, unless it already is
+func (c *Client) connect() (proto.ProviderClient, *proto.ProviderInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.provider != nil {
+		return c.provider, c.info, nil
+	}
+
+	client := goplugin.NewClient(&goplugin.ClientConfig{
+		HandshakeConfig:  Handshake,
+		Plugins:          goplugin.PluginSet{pluginName: &providerPlugin{}},
+		Cmd:              exec.Command(c.path),
+		AllowedProtocols: []goplugin.Protocol{goplugin.ProtocolGRPC},
+		StartTimeout:     StartTimeout,
+		Managed:          true,
+		// the logs of plugins are shown as they are, rather than as debug logs of grr
+		Stderr: os.Stderr,
+		Logger: hclog.New(&hclog.LoggerOptions{Name: Name(c.path), Level: hclog.Error, Output: os.Stderr}),
+	})
+	rpcClient, err := client.Client()
+	if err != nil {
+		client.Kill()
+		return nil, nil, fmt.Errorf("starting plugin %s: %w", c.path, err)
+	}
+	raw, err := rpcClient.Dispense(pluginName)
+	if err != nil {
+		client.Kill()
+		return nil, nil, fmt.Errorf("starting plugin %s: %w", c.path, err)
+	}
+	provider := raw.(proto.ProviderClient)
+	info, err := provider.Configure(context.Background(), &proto.ConfigureRequest{Settings: c.settings})
+	if err != nil {
+		client.Kill()
+		return nil, nil, fmt.Errorf("configuring plugin %s: %w", c.path, fromStatus(err))
+	}
+
+	c.client, c.provider, c.info = client, provider, info
+	return provider, info, nil
+}
+
+// Kill stops the plugin, if it was started
+func (c *Client) Kill() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client != nil {
+		c.client.Kill()
+	}
+	c.client, c.provider, c.info = nil, nil, nil
+}
+
+// CleanupClients stops every plugin started
+func CleanupClients() {
+	goplugin.CleanupClients()
+}
@@ -0,0 +1,115 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	log "github.com/sirupsen/logrus"
+)
+
+// Discover returns the paths of the plugins of a directory: its executable
+// files, other than hidden ones, sorted. A missing directory has no plugins.
+func Discover(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		// plugins can be links to executables installed elsewhere
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() || !isExecutable(path, info) {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func isExecutable(path string, info os.FileInfo) bool {
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(path), ".exe")
+	}
+	return info.Mode().Perm()&0111 != 0
+}
+
+// Name returns the name of the plugin at path, which its settings are given
+// under: the name of its executable, without extension
+func Name(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// LoadProviders appends the providers of the plugins of a directory,
+// configured with their settings, to the given providers. The kinds of each
+// plugin are kept in cacheFile, unless it's empty, so that plugins are only
+// started to discover their kinds when they, or their settings, change, and
+// otherwise once their kinds are used. Plugins failing to start, or providing
+// kinds already provided, are skipped with a warning, so that they don't
+// prevent using other providers.
+func LoadProviders(providers []grizzly.Provider, dir, cacheFile string, settings map[string]map[string]string) []grizzly.Provider {
+	paths, err := Discover(dir)
+	if err != nil {
+		log.Warnf("Couldn't list the plugins of %s: %s", dir, err)
+		return providers
+	}
+
+	kinds := map[string]string{}
+	for _, provider := range providers {
+		for _, handler := range provider.GetHandlers() {
+			kinds[handler.Kind()] = provider.Name()
+		}
+	}
+
+	cache := readPluginsCache(cacheFile)
+	for _, path := range paths {
+		client := NewClient(path, settings[Name(path)])
+		info, found := cache.get(path, settings[Name(path)])
+		if !found {
+			_, info, err = client.connect()
+			if err != nil {
+				log.Warnf("Skipping plugin %s: %s", Name(path), err)
+				continue
+			}
+			cache.put(path, settings[Name(path)], info)
+		}
+		provider := NewProvider(client, info)
+		if err := claimKinds(kinds, provider); err != nil {
+			client.Kill()
+			log.Warnf("Skipping plugin %s: %s", Name(path), err)
+			continue
+		}
+		log.Debugf("Loaded plugin %s, providing %d kinds", Name(path), len(provider.GetHandlers()))
+		providers = append(providers, provider)
+	}
+	cache.write(cacheFile)
+	return providers
+}
+
+// claimKinds records the kinds of a provider, unless one of them is already
+// provided by another provider
+func claimKinds(kinds map[string]string, provider grizzly.Provider) error {
+	for _, handler := range provider.GetHandlers() {
+		if owner, ok := kinds[handler.Kind()]; ok {
+			return fmt.Errorf("kind %s is already provided by %s", handler.Kind(), owner)
+		}
+	}
+	for _, handler := range provider.GetHandlers() {
+		kinds[handler.Kind()] = provider.Name()
+	}
+	return nil
+}
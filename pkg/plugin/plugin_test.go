@@ -0,0 +1,318 @@
+package plugin_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/plugin"
+	"github.com/grafana/grizzly/pkg/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// The test binary is also the plugin of the tests: grr runs it with the magic
+// cookie set, and it then serves widgets
+func TestMain(m *testing.M) {
+	if os.Getenv(plugin.MagicCookieKey) == plugin.MagicCookieValue {
+		if err := plugin.Serve(newWidgetProvider); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+const widgetKind = "Widget"
+
+type widgetProvider struct {
+	directory string
+	handlers  []grizzly.Handler
+}
+
+func newWidgetProvider(settings map[string]string) (grizzly.Provider, error) {
+	if settings["directory"] == "" {
+		return nil, fmt.Errorf("the directory setting is required")
+	}
+	if started := settings["started"]; started != "" {
+		// records each start of the plugin, as each one configures it once
+		f, err := os.OpenFile(started, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if _, err := fmt.Fprintln(f, "started"); err != nil {
+			return nil, err
+		}
+	}
+	provider := &widgetProvider{directory: settings["directory"]}
+	provider.handlers = []grizzly.Handler{&widgetHandler{
+		BaseHandler: grizzly.NewBaseHandler(provider, widgetKind, false),
+		widgets:     map[string]map[string]any{},
+	}}
+	return provider, nil
+}
+
+func (p *widgetProvider) Name() string                   { return "Widgets" }
+func (p *widgetProvider) Group() string                  { return "widgets.example.com" }
+func (p *widgetProvider) Version() string                { return "v1" }
+func (p *widgetProvider) APIVersion() string             { return "widgets.example.com/v1" }
+func (p *widgetProvider) GetHandlers() []grizzly.Handler { return p.handlers }
+func (p *widgetProvider) Validate() error                { return nil }
+func (p *widgetProvider) Status() grizzly.ProviderStatus {
+	return grizzly.ProviderStatus{Active: true, Online: true}
+}
+
+// widgetHandler keeps widgets in memory
+type widgetHandler struct {
+	grizzly.BaseHandler
+
+	mu      sync.Mutex
+	widgets map[string]map[string]any
+}
+
+func (h *widgetHandler) ResourceFilePath(resource grizzly.Resource, filetype string) string {
+	return filepath.Join(h.Provider.(*widgetProvider).directory, resource.Name()+"."+filetype)
+}
+
+func (h *widgetHandler) GetSpecUID(resource grizzly.Resource) (string, error) {
+	id, _ := resource.GetSpecString("id")
+	return id, nil
+}
+
+func (h *widgetHandler) GetByUID(uid string) (*grizzly.Resource, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	body, ok := h.widgets[uid]
+	if !ok {
+		return nil, fmt.Errorf("widget %s: %w", uid, grizzly.ErrNotFound)
+	}
+	return &grizzly.Resource{Body: body}, nil
+}
+
+func (h *widgetHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
+	return h.GetByUID(resource.Name())
+}
+
+func (h *widgetHandler) ListRemote() ([]string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	uids := []string{}
+	for uid := range h.widgets {
+		uids = append(uids, uid)
+	}
+	return uids, nil
+}
+
+func (h *widgetHandler) Add(resource grizzly.Resource) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.widgets[resource.Name()] = resource.Body
+	return nil
+}
+
+func (h *widgetHandler) Update(_, resource grizzly.Resource) error {
+	return h.Add(resource)
+}
+
+func (h *widgetHandler) Delete(resource grizzly.Resource) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.widgets, resource.Name())
+	return nil
+}
+
+func (h *widgetHandler) Validate(resource grizzly.Resource) error {
+	if color, _ := resource.GetSpecString("color"); color == "" {
+		return fmt.Errorf("widget %s has no color", resource.Name())
+	}
+	return nil
+}
+
+func (h *widgetHandler) Sort(resources grizzly.Resources) grizzly.Resources {
+	list := resources.AsList()
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+	return grizzly.NewResources(list...)
+}
+
+func (h *widgetHandler) Detect(spec map[string]any) bool {
+	_, ok := spec["color"]
+	return ok
+}
+
+// pluginDir returns a directory holding the test binary as the widgets plugin
+func pluginDir(t *testing.T) string {
+	t.Helper()
+	executable, err := os.Executable()
+	require.NoError(t, err)
+	dir := t.TempDir()
+	require.NoError(t, os.Symlink(executable, filepath.Join(dir, "widgets")))
+	return dir
+}
+
+func widget(t *testing.T, name, color string) grizzly.Resource {
+	t.Helper()
+	resource, err := grizzly.NewResource("widgets.example.com/v1", widgetKind, name, map[string]any{"id": name, "color": color})
+	require.NoError(t, err)
+	return resource
+}
+
+func loadWidgets(t *testing.T) grizzly.Registry {
+	t.Helper()
+	t.Cleanup(plugin.CleanupClients)
+	providers := plugin.LoadProviders(nil, pluginDir(t), "", map[string]map[string]string{
+		"widgets": {"directory": "widgets"},
+	})
+	require.Len(t, providers, 1)
+	return grizzly.NewRegistry(providers)
+}
+
+func TestPluginHandler(t *testing.T) {
+	registry := loadWidgets(t)
+	provider := registry.Providers[0]
+	require.Equal(t, "Widgets", provider.Name())
+	require.Equal(t, "widgets.example.com/v1", provider.APIVersion())
+	require.NoError(t, provider.Validate())
+	require.Equal(t, grizzly.ProviderStatus{Active: true, Online: true}, provider.Status())
+
+	handler, err := registry.GetHandler(widgetKind)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join("widgets", "blue.yaml"), handler.ResourceFilePath(widget(t, "blue", "blue"), "yaml"), "settings are given to the plugin")
+	require.True(t, handler.Detect(map[string]any{"color": "red"}))
+	require.False(t, handler.Detect(map[string]any{"title": "red"}))
+	require.ErrorContains(t, handler.Validate(widget(t, "colorless", "")), "widget colorless has no color")
+
+	uid, err := handler.GetSpecUID(widget(t, "blue", "blue"))
+	require.NoError(t, err)
+	require.Equal(t, "blue", uid)
+
+	b, a := widget(t, "b", "red"), widget(t, "a", "red")
+	b.SetSource(grizzly.Source{Path: "b.yaml"})
+	sorted := handler.Sort(grizzly.NewResources(b, a)).AsList()
+	require.Equal(t, []string{"a", "b"}, []string{sorted[0].Name(), sorted[1].Name()})
+	require.Equal(t, "b.yaml", sorted[1].Source.Path, "sorted resources keep their source")
+
+	testutil.RunHandlerConformance(t, handler, testutil.HandlerFixture{
+		Resource: widget(t, "green", "green"),
+		Modify: func(resource grizzly.Resource) grizzly.Resource {
+			resource.SetSpecString("color", "dark-green")
+			return resource
+		},
+	})
+}
+
+func TestLoadProviders(t *testing.T) {
+	t.Cleanup(plugin.CleanupClients)
+
+	cacheFile := filepath.Join(t.TempDir(), "plugins.json")
+	providers := plugin.LoadProviders(nil, pluginDir(t), cacheFile, nil)
+	require.Empty(t, providers, "plugins failing to configure are skipped")
+	require.NoFileExists(t, cacheFile, "plugins failing to configure aren't cached")
+
+	builtin, err := newWidgetProvider(map[string]string{"directory": "widgets"})
+	require.NoError(t, err)
+	providers = plugin.LoadProviders([]grizzly.Provider{builtin}, pluginDir(t), "", map[string]map[string]string{
+		"widgets": {"directory": "widgets"},
+	})
+	require.Equal(t, []grizzly.Provider{builtin}, providers, "plugins can't provide kinds already provided")
+
+	require.Empty(t, plugin.LoadProviders(nil, filepath.Join(t.TempDir(), "missing"), "", nil))
+}
+
+func TestLoadProvidersLazily(t *testing.T) {
+	t.Cleanup(plugin.CleanupClients)
+	dir, tmp := pluginDir(t), t.TempDir()
+	cacheFile, started := filepath.Join(tmp, "plugins.json"), filepath.Join(tmp, "started")
+	settings := map[string]map[string]string{
+		"widgets": {"directory": "widgets", "started": started},
+	}
+	starts := func() int {
+		data, err := os.ReadFile(started)
+		if errors.Is(err, os.ErrNotExist) {
+			return 0
+		}
+		require.NoError(t, err)
+		return strings.Count(string(data), "\n")
+	}
+
+	providers := plugin.LoadProviders(nil, dir, cacheFile, settings)
+	require.Len(t, providers, 1)
+	require.Equal(t, 1, starts(), "plugins are started to discover their kinds")
+	handler := providers[0].GetHandlers()[0]
+	require.Equal(t, filepath.Join("widgets", "blue.yaml"), handler.ResourceFilePath(widget(t, "blue", "blue"), "yaml"))
+	require.Equal(t, 1, starts(), "plugins started to discover their kinds are reused")
+	plugin.CleanupClients()
+
+	providers = plugin.LoadProviders(nil, dir, cacheFile, settings)
+	require.Len(t, providers, 1)
+	require.Equal(t, "Widgets", providers[0].Name())
+	handler = providers[0].GetHandlers()[0]
+	require.Equal(t, widgetKind, handler.Kind())
+	_, deletes := handler.(grizzly.DeleteHandler)
+	require.True(t, deletes)
+	require.Equal(t, 1, starts(), "plugins whose kinds are cached aren't started")
+
+	registry := grizzly.NewRegistry(providers)
+	resourcesDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(resourcesDir, "red.yaml"), []byte(`apiVersion: widgets.example.com/v1
+kind: Widget
+metadata:
+  name: red
+spec:
+  id: red
+  color: red
+`), 0600))
+	parsed, err := grizzly.DefaultParser(registry, nil, nil).Parse(resourcesDir, grizzly.ParserOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, parsed.Len())
+	require.Zero(t, registry.Sort(grizzly.NewResources()).Len())
+	require.Equal(t, 1, starts(), "parsing and sorting resources doesn't start plugins")
+	require.True(t, handler.Detect(map[string]any{"color": "red"}))
+	require.True(t, handler.Detect(map[string]any{"color": "blue"}))
+	require.Equal(t, 2, starts(), "plugins are started once their kinds are used")
+	plugin.CleanupClients()
+
+	settings["widgets"]["directory"] = "gadgets"
+	providers = plugin.LoadProviders(nil, dir, cacheFile, settings)
+	require.Len(t, providers, 1)
+	require.Equal(t, 3, starts(), "plugins are discovered again once their settings change")
+	plugin.CleanupClients()
+
+	require.NoError(t, os.Remove(started))
+	require.NoError(t, os.Remove(filepath.Join(dir, "widgets")))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "widgets"), []byte("#!/bin/sh\nexit 1\n"), 0755))
+	require.Empty(t, plugin.LoadProviders(nil, dir, cacheFile, settings), "plugins are discovered again once updated")
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	for name, mode := range map[string]os.FileMode{
+		"widgets":     0755,
+		"alerts.sh":   0700,
+		"README.md":   0644,
+		".hidden":     0755,
+		"gadgets.bin": 0750,
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), mode))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "directory"), 0755))
+
+	paths, err := plugin.Discover(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(dir, "alerts.sh"),
+		filepath.Join(dir, "gadgets.bin"),
+		filepath.Join(dir, "widgets"),
+	}, paths)
+	require.Equal(t, "alerts", plugin.Name(paths[0]))
+}
+
+func TestServeOutsideOfGrr(t *testing.T) {
+	require.ErrorContains(t, plugin.Serve(newWidgetProvider), "run by grr rather than directly")
+}
@@ -0,0 +1,5 @@
+// Package proto holds the gRPC service served by the plugins of Grizzly,
+// generated from provider.proto
+package proto
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative provider.proto
@@ -0,0 +1,707 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: provider.proto
+
+package proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Provider_Configure_FullMethodName        = "/grizzly.plugin.v1.Provider/Configure"
+	Provider_Validate_FullMethodName         = "/grizzly.plugin.v1.Provider/Validate"
+	Provider_Status_FullMethodName           = "/grizzly.plugin.v1.Provider/Status"
+	Provider_ResourceFilePath_FullMethodName = "/grizzly.plugin.v1.Provider/ResourceFilePath"
+	Provider_Unprepare_FullMethodName        = "/grizzly.plugin.v1.Provider/Unprepare"
+	Provider_Prepare_FullMethodName          = "/grizzly.plugin.v1.Provider/Prepare"
+	Provider_GetUID_FullMethodName           = "/grizzly.plugin.v1.Provider/GetUID"
+	Provider_GetSpecUID_FullMethodName       = "/grizzly.plugin.v1.Provider/GetSpecUID"
+	Provider_GetByUID_FullMethodName         = "/grizzly.plugin.v1.Provider/GetByUID"
+	Provider_GetRemote_FullMethodName        = "/grizzly.plugin.v1.Provider/GetRemote"
+	Provider_ListRemote_FullMethodName       = "/grizzly.plugin.v1.Provider/ListRemote"
+	Provider_Add_FullMethodName              = "/grizzly.plugin.v1.Provider/Add"
+	Provider_Update_FullMethodName           = "/grizzly.plugin.v1.Provider/Update"
+	Provider_Delete_FullMethodName           = "/grizzly.plugin.v1.Provider/Delete"
+	Provider_ValidateResource_FullMethodName = "/grizzly.plugin.v1.Provider/ValidateResource"
+	Provider_Sort_FullMethodName             = "/grizzly.plugin.v1.Provider/Sort"
+	Provider_Detect_FullMethodName           = "/grizzly.plugin.v1.Provider/Detect"
+)
+
+// ProviderClient is the client API for Provider service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ProviderClient interface {
+	// Configure creates the provider of the plugin, given its settings in the
+	// current context of grr, and describes it
+	Configure(ctx context.Context, in *ConfigureRequest, opts ...grpc.CallOption) (*ProviderInfo, error)
+	Validate(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	Status(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ProviderStatus, error)
+	// The methods of handlers, given the kind of the handler in their request
+	ResourceFilePath(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error)
+	Unprepare(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error)
+	Prepare(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error)
+	GetUID(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error)
+	GetSpecUID(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error)
+	GetByUID(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error)
+	GetRemote(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error)
+	ListRemote(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error)
+	Add(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error)
+	Update(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error)
+	Delete(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error)
+	ValidateResource(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error)
+	Sort(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error)
+	Detect(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error)
+}
+
+type providerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewProviderClient(cc grpc.ClientConnInterface) ProviderClient {
+	return &providerClient{cc}
+}
+
+func (c *providerClient) Configure(ctx context.Context, in *ConfigureRequest, opts ...grpc.CallOption) (*ProviderInfo, error) {
+	out := new(ProviderInfo)
+	err := c.cc.Invoke(ctx, Provider_Configure_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *providerClient) Validate(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Provider_Validate_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *providerClient) Status(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ProviderStatus, error) {
+	out := new(ProviderStatus)
+	err := c.cc.Invoke(ctx, Provider_Status_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *providerClient) ResourceFilePath(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error) {
+	out := new(HandlerResponse)
+	err := c.cc.Invoke(ctx, Provider_ResourceFilePath_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *providerClient) Unprepare(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error) {
+	out := new(HandlerResponse)
+	err := c.cc.Invoke(ctx, Provider_Unprepare_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *providerClient) Prepare(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error) {
+	out := new(HandlerResponse)
+	err := c.cc.Invoke(ctx, Provider_Prepare_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *providerClient) GetUID(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error) {
+	out := new(HandlerResponse)
+	err := c.cc.Invoke(ctx, Provider_GetUID_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *providerClient) GetSpecUID(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error) {
+	out := new(HandlerResponse)
+	err := c.cc.Invoke(ctx, Provider_GetSpecUID_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *providerClient) GetByUID(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error) {
+	out := new(HandlerResponse)
+	err := c.cc.Invoke(ctx, Provider_GetByUID_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *providerClient) GetRemote(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error) {
+	out := new(HandlerResponse)
+	err := c.cc.Invoke(ctx, Provider_GetRemote_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *providerClient) ListRemote(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error) {
+	out := new(HandlerResponse)
+	err := c.cc.Invoke(ctx, Provider_ListRemote_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *providerClient) Add(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error) {
+	out := new(HandlerResponse)
+	err := c.cc.Invoke(ctx, Provider_Add_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *providerClient) Update(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error) {
+	out := new(HandlerResponse)
+	err := c.cc.Invoke(ctx, Provider_Update_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *providerClient) Delete(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error) {
+	out := new(HandlerResponse)
+	err := c.cc.Invoke(ctx, Provider_Delete_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *providerClient) ValidateResource(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error) {
+	out := new(HandlerResponse)
+	err := c.cc.Invoke(ctx, Provider_ValidateResource_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *providerClient) Sort(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error) {
+	out := new(HandlerResponse)
+	err := c.cc.Invoke(ctx, Provider_Sort_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *providerClient) Detect(ctx context.Context, in *HandlerRequest, opts ...grpc.CallOption) (*HandlerResponse, error) {
+	out := new(HandlerResponse)
+	err := c.cc.Invoke(ctx, Provider_Detect_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProviderServer is the server API for Provider service.
+// All implementations must embed UnimplementedProviderServer
+// for forward compatibility
+type ProviderServer interface {
+	// Configure creates the provider of the plugin, given its settings in the
+	// current context of grr, and describes it
+	Configure(context.Context, *ConfigureRequest) (*ProviderInfo, error)
+	Validate(context.Context, *Empty) (*Empty, error)
+	Status(context.Context, *Empty) (*ProviderStatus, error)
+	// The methods of handlers, given the kind of the handler in their request
+	ResourceFilePath(context.Context, *HandlerRequest) (*HandlerResponse, error)
+	Unprepare(context.Context, *HandlerRequest) (*HandlerResponse, error)
+	Prepare(context.Context, *HandlerRequest) (*HandlerResponse, error)
+	GetUID(context.Context, *HandlerRequest) (*HandlerResponse, error)
+	GetSpecUID(context.Context, *HandlerRequest) (*HandlerResponse, error)
+	GetByUID(context.Context, *HandlerRequest) (*HandlerResponse, error)
+	GetRemote(context.Context, *HandlerRequest) (*HandlerResponse, error)
+	ListRemote(context.Context, *HandlerRequest) (*HandlerResponse, error)
+	Add(context.Context, *HandlerRequest) (*HandlerResponse, error)
+	Update(context.Context, *HandlerRequest) (*HandlerResponse, error)
+	Delete(context.Context, *HandlerRequest) (*HandlerResponse, error)
+	ValidateResource(context.Context, *HandlerRequest) (*HandlerResponse, error)
+	Sort(context.Context, *HandlerRequest) (*HandlerResponse, error)
+	Detect(context.Context, *HandlerRequest) (*HandlerResponse, error)
+	mustEmbedUnimplementedProviderServer()
+}
+
+// UnimplementedProviderServer must be embedded to have forward compatible implementations.
+type UnimplementedProviderServer struct {
+}
+
+func (UnimplementedProviderServer) Configure(context.Context, *ConfigureRequest) (*ProviderInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Configure not implemented")
+}
+func (UnimplementedProviderServer) Validate(context.Context, *Empty) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Validate not implemented")
+}
+func (UnimplementedProviderServer) Status(context.Context, *Empty) (*ProviderStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (UnimplementedProviderServer) ResourceFilePath(context.Context, *HandlerRequest) (*HandlerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResourceFilePath not implemented")
+}
+func (UnimplementedProviderServer) Unprepare(context.Context, *HandlerRequest) (*HandlerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unprepare not implemented")
+}
+func (UnimplementedProviderServer) Prepare(context.Context, *HandlerRequest) (*HandlerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Prepare not implemented")
+}
+func (UnimplementedProviderServer) GetUID(context.Context, *HandlerRequest) (*HandlerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUID not implemented")
+}
+func (UnimplementedProviderServer) GetSpecUID(context.Context, *HandlerRequest) (*HandlerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSpecUID not implemented")
+}
+func (UnimplementedProviderServer) GetByUID(context.Context, *HandlerRequest) (*HandlerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetByUID not implemented")
+}
+func (UnimplementedProviderServer) GetRemote(context.Context, *HandlerRequest) (*HandlerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRemote not implemented")
+}
+func (UnimplementedProviderServer) ListRemote(context.Context, *HandlerRequest) (*HandlerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRemote not implemented")
+}
+func (UnimplementedProviderServer) Add(context.Context, *HandlerRequest) (*HandlerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Add not implemented")
+}
+func (UnimplementedProviderServer) Update(context.Context, *HandlerRequest) (*HandlerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedProviderServer) Delete(context.Context, *HandlerRequest) (*HandlerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedProviderServer) ValidateResource(context.Context, *HandlerRequest) (*HandlerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateResource not implemented")
+}
+func (UnimplementedProviderServer) Sort(context.Context, *HandlerRequest) (*HandlerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Sort not implemented")
+}
+func (UnimplementedProviderServer) Detect(context.Context, *HandlerRequest) (*HandlerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Detect not implemented")
+}
+func (UnimplementedProviderServer) mustEmbedUnimplementedProviderServer() {}
+
+// UnsafeProviderServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ProviderServer will
+// result in compilation errors.
+type UnsafeProviderServer interface {
+	mustEmbedUnimplementedProviderServer()
+}
+
+func RegisterProviderServer(s grpc.ServiceRegistrar, srv ProviderServer) {
+	s.RegisterService(&Provider_ServiceDesc, srv)
+}
+
+func _Provider_Configure_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigureRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServer).Configure(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Provider_Configure_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServer).Configure(ctx, req.(*ConfigureRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Provider_Validate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServer).Validate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Provider_Validate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServer).Validate(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Provider_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Provider_Status_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServer).Status(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Provider_ResourceFilePath_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HandlerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServer).ResourceFilePath(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Provider_ResourceFilePath_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServer).ResourceFilePath(ctx, req.(*HandlerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Provider_Unprepare_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HandlerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServer).Unprepare(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Provider_Unprepare_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServer).Unprepare(ctx, req.(*HandlerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Provider_Prepare_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HandlerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServer).Prepare(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Provider_Prepare_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServer).Prepare(ctx, req.(*HandlerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Provider_GetUID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HandlerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServer).GetUID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Provider_GetUID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServer).GetUID(ctx, req.(*HandlerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Provider_GetSpecUID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HandlerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServer).GetSpecUID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Provider_GetSpecUID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServer).GetSpecUID(ctx, req.(*HandlerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Provider_GetByUID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HandlerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServer).GetByUID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Provider_GetByUID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServer).GetByUID(ctx, req.(*HandlerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Provider_GetRemote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HandlerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServer).GetRemote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Provider_GetRemote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServer).GetRemote(ctx, req.(*HandlerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Provider_ListRemote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HandlerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServer).ListRemote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Provider_ListRemote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServer).ListRemote(ctx, req.(*HandlerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Provider_Add_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HandlerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServer).Add(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Provider_Add_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServer).Add(ctx, req.(*HandlerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Provider_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HandlerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Provider_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServer).Update(ctx, req.(*HandlerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Provider_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HandlerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Provider_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServer).Delete(ctx, req.(*HandlerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Provider_ValidateResource_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HandlerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServer).ValidateResource(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Provider_ValidateResource_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServer).ValidateResource(ctx, req.(*HandlerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Provider_Sort_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HandlerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServer).Sort(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Provider_Sort_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServer).Sort(ctx, req.(*HandlerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Provider_Detect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HandlerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServer).Detect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Provider_Detect_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServer).Detect(ctx, req.(*HandlerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Provider_ServiceDesc is the grpc.ServiceDesc for Provider service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Provider_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "grizzly.plugin.v1.Provider",
+	HandlerType: (*ProviderServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Configure",
+			Handler:    _Provider_Configure_Handler,
+		},
+		{
+			MethodName: "Validate",
+			Handler:    _Provider_Validate_Handler,
+		},
+		{
+			MethodName: "Status",
+			Handler:    _Provider_Status_Handler,
+		},
+		{
+			MethodName: "ResourceFilePath",
+			Handler:    _Provider_ResourceFilePath_Handler,
+		},
+		{
+			MethodName: "Unprepare",
+			Handler:    _Provider_Unprepare_Handler,
+		},
+		{
+			MethodName: "Prepare",
+			Handler:    _Provider_Prepare_Handler,
+		},
+		{
+			MethodName: "GetUID",
+			Handler:    _Provider_GetUID_Handler,
+		},
+		{
+			MethodName: "GetSpecUID",
+			Handler:    _Provider_GetSpecUID_Handler,
+		},
+		{
+			MethodName: "GetByUID",
+			Handler:    _Provider_GetByUID_Handler,
+		},
+		{
+			MethodName: "GetRemote",
+			Handler:    _Provider_GetRemote_Handler,
+		},
+		{
+			MethodName: "ListRemote",
+			Handler:    _Provider_ListRemote_Handler,
+		},
+		{
+			MethodName: "Add",
+			Handler:    _Provider_Add_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _Provider_Update_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _Provider_Delete_Handler,
+		},
+		{
+			MethodName: "ValidateResource",
+			Handler:    _Provider_ValidateResource_Handler,
+		},
+		{
+			MethodName: "Sort",
+			Handler:    _Provider_Sort_Handler,
+		},
+		{
+			MethodName: "Detect",
+			Handler:    _Provider_Detect_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "provider.proto",
+}
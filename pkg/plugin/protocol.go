@@ -0,0 +1,161 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/plugin/proto"
+	goplugin "github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Plugins are executables, run by grr with hashicorp/go-plugin, serving the
+// grizzly.plugin.v1.Provider gRPC service of proto/provider.proto: grr
+// configures the provider of the plugin, then calls its handlers. Resources
+// are encoded in JSON, so that they keep the shape they have in Grizzly.
+
+const (
+	// ProtocolVersion is the version of the protocol between grr and plugins.
+	// grr doesn't run plugins of other versions.
+	ProtocolVersion = 1
+
+	// MagicCookieKey and MagicCookieValue are set in the environment of plugins
+	MagicCookieKey   = "GRIZZLY_PLUGIN_MAGIC_COOKIE"
+	MagicCookieValue = "8a3f1d0c6e2b4f7a9c5e1b3d7f0a2c4e"
+
+	// pluginName is the name plugins serve their provider under
+	pluginName = "provider"
+)
+
+// Handshake is the handshake of grr and its plugins
+var Handshake = goplugin.HandshakeConfig{
+	ProtocolVersion:  ProtocolVersion,
+	MagicCookieKey:   MagicCookieKey,
+	MagicCookieValue: MagicCookieValue,
+}
+
+// The methods of the service of plugins
+const (
+	methodResourceFilePath = "ResourceFilePath"
+	methodUnprepare        = "Unprepare"
+	methodPrepare          = "Prepare"
+	methodGetUID           = "GetUID"
+	methodGetSpecUID       = "GetSpecUID"
+	methodGetByUID         = "GetByUID"
+	methodGetRemote        = "GetRemote"
+	methodListRemote       = "ListRemote"
+	methodAdd              = "Add"
+	methodUpdate           = "Update"
+	methodDelete           = "Delete"
+	methodValidateResource = "ValidateResource"
+	methodSort             = "Sort"
+	methodDetect           = "Detect"
+)
+
+// providerPlugin serves the provider returned by factory in plugins, and
+// returns the client of that provider in grr
+type providerPlugin struct {
+	goplugin.NetRPCUnsupportedPlugin
+	factory ProviderFactory
+}
+
+var _ goplugin.GRPCPlugin = &providerPlugin{}
+
+func (p *providerPlugin) GRPCServer(_ *goplugin.GRPCBroker, server *grpc.Server) error {
+	proto.RegisterProviderServer(server, &pluginServer{factory: p.factory})
+	return nil
+}
+
+func (p *providerPlugin) GRPCClient(_ context.Context, _ *goplugin.GRPCBroker, conn *grpc.ClientConn) (any, error) {
+	return proto.NewProviderClient(conn), nil
+}
+
+// toMessage encodes the body of a resource, if any
+func toMessage(body map[string]any) (*proto.Resource, error) {
+	if body == nil {
+		return nil, nil
+	}
+	content, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.Resource{Body: content}, nil
+}
+
+// fromMessage decodes the body of a resource, if any
+func fromMessage(resource *proto.Resource) (map[string]any, error) {
+	if resource == nil || len(resource.Body) == 0 {
+		return nil, nil
+	}
+	body := map[string]any{}
+	if err := json.Unmarshal(resource.Body, &body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// errorCodes maps the codes of the errors of handlers to gRPC codes, so that
+// they are still recognised once returned by a plugin
+var errorCodes = map[grizzly.ErrorCode]codes.Code{
+	grizzly.ErrorCodeNotFound:         codes.NotFound,
+	grizzly.ErrorCodeAuthFailed:       codes.Unauthenticated,
+	grizzly.ErrorCodePermissionDenied: codes.PermissionDenied,
+	grizzly.ErrorCodeConflict:         codes.AlreadyExists,
+	grizzly.ErrorCodeQuotaExceeded:    codes.ResourceExhausted,
+	grizzly.ErrorCodeServerError:      codes.Internal,
+}
+
+// toStatus turns an error of a handler into the status returned by a plugin
+func toStatus(err error) error {
+	if errors.Is(err, grizzly.ErrNotImplemented) {
+		return status.Error(codes.Unimplemented, err.Error())
+	}
+	if code, ok := errorCodes[grizzly.ErrorCodeOf(err)]; ok {
+		return status.Error(code, err.Error())
+	}
+	return status.Error(codes.Unknown, err.Error())
+}
+
+// remoteError is an error returned by a plugin, which keeps the message of
+// the handler while matching the errors of Grizzly it wrapped
+type remoteError struct {
+	message string
+	err     error
+}
+
+func (e remoteError) Error() string {
+	return e.message
+}
+
+func (e remoteError) Unwrap() error {
+	return e.err
+}
+
+// fromStatus turns the status returned by a plugin back into an error, which
+// matches grizzly.ErrNotFound or grizzly.ErrNotImplemented when the error of
+// the handler did
+func fromStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	s, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch s.Code() {
+	case codes.NotFound:
+		return remoteError{message: s.Message(), err: grizzly.ErrNotFound}
+	case codes.Unimplemented:
+		return remoteError{message: s.Message(), err: grizzly.ErrNotImplemented}
+	}
+	for errorCode, code := range errorCodes {
+		if s.Code() == code {
+			return grizzly.NewCodedError(errorCode, errors.New(s.Message()), "")
+		}
+	}
+	return errors.New(s.Message())
+}
@@ -0,0 +1,298 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/plugin/proto"
+	"google.golang.org/grpc"
+)
+
+// Provider is the provider served by a plugin. It's described by the
+// information the plugin gave when it was configured, so that the plugin is
+// only started once the provider, or one of its handlers, is called.
+type Provider struct {
+	client   *Client
+	info     *proto.ProviderInfo
+	handlers []grizzly.Handler
+}
+
+var _ grizzly.Provider = &Provider{}
+
+// NewProvider returns the provider of a plugin, as described by info
+func NewProvider(client *Client, info *proto.ProviderInfo) *Provider {
+	provider := &Provider{client: client, info: info}
+	for _, handlerInfo := range info.Handlers {
+		handler := &Handler{provider: provider, info: handlerInfo}
+		if handlerInfo.Deletes {
+			provider.handlers = append(provider.handlers, &DeleteHandler{Handler: handler})
+			continue
+		}
+		provider.handlers = append(provider.handlers, handler)
+	}
+	return provider
+}
+
+func (p *Provider) Name() string {
+	return p.info.Name
+}
+
+func (p *Provider) Group() string {
+	return p.info.Group
+}
+
+func (p *Provider) Version() string {
+	return p.info.Version
+}
+
+func (p *Provider) APIVersion() string {
+	return p.info.ApiVersion
+}
+
+func (p *Provider) GetHandlers() []grizzly.Handler {
+	return p.handlers
+}
+
+func (p *Provider) Validate() error {
+	client, _, err := p.client.connect()
+	if err != nil {
+		return err
+	}
+	_, err = client.Validate(context.Background(), &proto.Empty{})
+	return fromStatus(err)
+}
+
+func (p *Provider) Status() grizzly.ProviderStatus {
+	client, _, err := p.client.connect()
+	if err == nil {
+		var status *proto.ProviderStatus
+		if status, err = client.Status(context.Background(), &proto.Empty{}); err == nil {
+			return grizzly.ProviderStatus{
+				Active:       status.Active,
+				ActiveReason: status.ActiveReason,
+				Online:       status.Online,
+				OnlineReason: status.OnlineReason,
+			}
+		}
+		err = fromStatus(err)
+	}
+	return grizzly.ProviderStatus{ActiveReason: fmt.Sprintf("plugin %s: %s", p.client.path, err)}
+}
+
+// Handler calls a handler of a plugin
+type Handler struct {
+	provider *Provider
+	info     *proto.HandlerInfo
+}
+
+var _ grizzly.Handler = &Handler{}
+
+// handlerMethod is a method of the handlers of plugins
+type handlerMethod func(proto.ProviderClient, context.Context, *proto.HandlerRequest, ...grpc.CallOption) (*proto.HandlerResponse, error)
+
+// call calls a method of the handler with a request of its kind, starting the
+// plugin if needed
+func (h *Handler) call(method handlerMethod, req *proto.HandlerRequest) (*proto.HandlerResponse, error) {
+	client, _, err := h.provider.client.connect()
+	if err != nil {
+		return nil, err
+	}
+	req.Kind = h.info.Kind
+	resp, err := method(client, context.Background(), req)
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	return resp, nil
+}
+
+// callWith calls a method of the handler with a resource, and the existing
+// version of the resource, if any
+func (h *Handler) callWith(method handlerMethod, resource, existing *grizzly.Resource) (*proto.HandlerResponse, error) {
+	req := &proto.HandlerRequest{}
+	var err error
+	if resource != nil {
+		if req.Resource, err = toMessage(resource.Body); err != nil {
+			return nil, err
+		}
+	}
+	if existing != nil {
+		if req.Existing, err = toMessage(existing.Body); err != nil {
+			return nil, err
+		}
+	}
+	return h.call(method, req)
+}
+
+// resourceOf returns a resource returned by the plugin, with the source of
+// the resource it was given, if any
+func resourceOf(message *proto.Resource, source *grizzly.Resource) (*grizzly.Resource, error) {
+	body, err := fromMessage(message)
+	if err != nil {
+		return nil, err
+	}
+	resource := &grizzly.Resource{Body: body}
+	if source != nil {
+		resource.Source = source.Source
+	}
+	return resource, nil
+}
+
+func (h *Handler) APIVersion() string {
+	return h.provider.APIVersion()
+}
+
+func (h *Handler) Kind() string {
+	return h.info.Kind
+}
+
+func (h *Handler) UsesFolders() bool {
+	return h.info.UsesFolders
+}
+
+func (h *Handler) ResourceFilePath(resource grizzly.Resource, filetype string) string {
+	req := &proto.HandlerRequest{Filetype: filetype}
+	message, err := toMessage(resource.Body)
+	if err == nil {
+		req.Resource = message
+		var resp *proto.HandlerResponse
+		if resp, err = h.call(proto.ProviderClient.ResourceFilePath, req); err == nil {
+			return resp.Path
+		}
+	}
+	// the layout of resources doesn't depend on the plugin running
+	return fmt.Sprintf("%s/%s.%s", h.Kind(), resource.Name(), filetype)
+}
+
+func (h *Handler) Unprepare(resource grizzly.Resource) *grizzly.Resource {
+	resp, err := h.callWith(proto.ProviderClient.Unprepare, &resource, nil)
+	if err != nil || resp.Resource == nil {
+		return &resource
+	}
+	unprepared, err := resourceOf(resp.Resource, &resource)
+	if err != nil {
+		return &resource
+	}
+	return unprepared
+}
+
+func (h *Handler) Prepare(existing *grizzly.Resource, resource grizzly.Resource) *grizzly.Resource {
+	resp, err := h.callWith(proto.ProviderClient.Prepare, &resource, existing)
+	if err != nil || resp.Resource == nil {
+		return &resource
+	}
+	prepared, err := resourceOf(resp.Resource, &resource)
+	if err != nil {
+		return &resource
+	}
+	return prepared
+}
+
+func (h *Handler) GetUID(resource grizzly.Resource) (string, error) {
+	resp, err := h.callWith(proto.ProviderClient.GetUID, &resource, nil)
+	if err != nil {
+		return "", err
+	}
+	return resp.Uid, nil
+}
+
+func (h *Handler) GetSpecUID(resource grizzly.Resource) (string, error) {
+	resp, err := h.callWith(proto.ProviderClient.GetSpecUID, &resource, nil)
+	if err != nil {
+		return "", err
+	}
+	return resp.Uid, nil
+}
+
+func (h *Handler) GetByUID(uid string) (*grizzly.Resource, error) {
+	resp, err := h.call(proto.ProviderClient.GetByUID, &proto.HandlerRequest{Uid: uid})
+	if err != nil {
+		return nil, err
+	}
+	return resourceOf(resp.Resource, nil)
+}
+
+func (h *Handler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
+	resp, err := h.callWith(proto.ProviderClient.GetRemote, &resource, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resourceOf(resp.Resource, nil)
+}
+
+func (h *Handler) ListRemote() ([]string, error) {
+	resp, err := h.call(proto.ProviderClient.ListRemote, &proto.HandlerRequest{})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Uids == nil {
+		return []string{}, nil
+	}
+	return resp.Uids, nil
+}
+
+func (h *Handler) Add(resource grizzly.Resource) error {
+	_, err := h.callWith(proto.ProviderClient.Add, &resource, nil)
+	return err
+}
+
+func (h *Handler) Update(existing, resource grizzly.Resource) error {
+	_, err := h.callWith(proto.ProviderClient.Update, &resource, &existing)
+	return err
+}
+
+func (h *Handler) Validate(resource grizzly.Resource) error {
+	_, err := h.callWith(proto.ProviderClient.ValidateResource, &resource, nil)
+	return err
+}
+
+func (h *Handler) Sort(resources grizzly.Resources) grizzly.Resources {
+	list := resources.AsList()
+	// there is nothing to sort, and no reason to start the plugin
+	if len(list) < 2 {
+		return resources
+	}
+	req := &proto.HandlerRequest{}
+	for _, resource := range list {
+		message, err := toMessage(resource.Body)
+		if err != nil {
+			return resources
+		}
+		req.Resources = append(req.Resources, message)
+	}
+	resp, err := h.call(proto.ProviderClient.Sort, req)
+	if err != nil || len(resp.Order) != len(list) {
+		return resources
+	}
+
+	sorted := make([]grizzly.Resource, 0, len(list))
+	for _, i := range resp.Order {
+		if i < 0 || int(i) >= len(list) {
+			return resources
+		}
+		sorted = append(sorted, list[i])
+	}
+	return grizzly.NewResources(sorted...)
+}
+
+func (h *Handler) Detect(spec map[string]any) bool {
+	content, err := json.Marshal(spec)
+	if err != nil {
+		return false
+	}
+	resp, err := h.call(proto.ProviderClient.Detect, &proto.HandlerRequest{Spec: content})
+	return err == nil && resp.Detected
+}
+
+// DeleteHandler calls a handler of a plugin able to delete resources
+type DeleteHandler struct {
+	*Handler
+}
+
+var _ grizzly.DeleteHandler = &DeleteHandler{}
+
+func (h *DeleteHandler) Delete(resource grizzly.Resource) error {
+	_, err := h.callWith(proto.ProviderClient.Delete, &resource, nil)
+	return err
+}
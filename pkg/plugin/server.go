@@ -0,0 +1,314 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/plugin/proto"
+	"github.com/hashicorp/go-hclog"
+	goplugin "github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ProviderFactory returns the provider served by a plugin, given the settings
+// of the plugin in the current context of grr
+type ProviderFactory func(settings map[string]string) (grizzly.Provider, error)
+
+// Serve serves the handlers of the provider returned by factory to grr, and
+// returns once grr stops the plugin. It's called from the main function of
+// plugins:
+//
+//	func main() {
+//		if err := plugin.Serve(newProvider); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+func Serve(factory ProviderFactory) error {
+	if os.Getenv(MagicCookieKey) != MagicCookieValue {
+		return fmt.Errorf("this executable is a plugin of Grizzly, run by grr rather than directly")
+	}
+
+	goplugin.Serve(&goplugin.ServeConfig{
+		HandshakeConfig: Handshake,
+		Plugins:         goplugin.PluginSet{pluginName: &providerPlugin{factory: factory}},
+		GRPCServer:      goplugin.DefaultGRPCServer,
+		// stderr is left to the logs of the plugin, shown by grr as they are
+		Logger: hclog.New(&hclog.LoggerOptions{Level: hclog.Error, Output: os.Stderr, JSONFormat: true}),
+	})
+	return nil
+}
+
+// pluginServer calls the provider of a plugin, once configured
+type pluginServer struct {
+	proto.UnimplementedProviderServer
+	factory ProviderFactory
+
+	mu       sync.Mutex
+	provider grizzly.Provider
+	handlers map[string]grizzly.Handler
+}
+
+var _ proto.ProviderServer = &pluginServer{}
+
+func (s *pluginServer) Configure(_ context.Context, req *proto.ConfigureRequest) (*proto.ProviderInfo, error) {
+	provider, err := s.factory(req.Settings)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	info := &proto.ProviderInfo{
+		Name:       provider.Name(),
+		Group:      provider.Group(),
+		Version:    provider.Version(),
+		ApiVersion: provider.APIVersion(),
+	}
+	handlers := map[string]grizzly.Handler{}
+	for _, handler := range provider.GetHandlers() {
+		_, deletes := handler.(grizzly.DeleteHandler)
+		info.Handlers = append(info.Handlers, &proto.HandlerInfo{
+			Kind:        handler.Kind(),
+			UsesFolders: handler.UsesFolders(),
+			Deletes:     deletes,
+		})
+		handlers[handler.Kind()] = handler
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.provider, s.handlers = provider, handlers
+	return info, nil
+}
+
+func (s *pluginServer) Validate(context.Context, *proto.Empty) (*proto.Empty, error) {
+	provider, err := s.getProvider()
+	if err != nil {
+		return nil, err
+	}
+	if err := provider.Validate(); err != nil {
+		return nil, toStatus(err)
+	}
+	return &proto.Empty{}, nil
+}
+
+func (s *pluginServer) Status(context.Context, *proto.Empty) (*proto.ProviderStatus, error) {
+	provider, err := s.getProvider()
+	if err != nil {
+		return nil, err
+	}
+	providerStatus := provider.Status()
+	return &proto.ProviderStatus{
+		Active:       providerStatus.Active,
+		ActiveReason: providerStatus.ActiveReason,
+		Online:       providerStatus.Online,
+		OnlineReason: providerStatus.OnlineReason,
+	}, nil
+}
+
+func (s *pluginServer) ResourceFilePath(_ context.Context, req *proto.HandlerRequest) (*proto.HandlerResponse, error) {
+	return s.handle(methodResourceFilePath, req)
+}
+
+func (s *pluginServer) Unprepare(_ context.Context, req *proto.HandlerRequest) (*proto.HandlerResponse, error) {
+	return s.handle(methodUnprepare, req)
+}
+
+func (s *pluginServer) Prepare(_ context.Context, req *proto.HandlerRequest) (*proto.HandlerResponse, error) {
+	return s.handle(methodPrepare, req)
+}
+
+func (s *pluginServer) GetUID(_ context.Context, req *proto.HandlerRequest) (*proto.HandlerResponse, error) {
+	return s.handle(methodGetUID, req)
+}
+
+func (s *pluginServer) GetSpecUID(_ context.Context, req *proto.HandlerRequest) (*proto.HandlerResponse, error) {
+	return s.handle(methodGetSpecUID, req)
+}
+
+func (s *pluginServer) GetByUID(_ context.Context, req *proto.HandlerRequest) (*proto.HandlerResponse, error) {
+	return s.handle(methodGetByUID, req)
+}
+
+func (s *pluginServer) GetRemote(_ context.Context, req *proto.HandlerRequest) (*proto.HandlerResponse, error) {
+	return s.handle(methodGetRemote, req)
+}
+
+func (s *pluginServer) ListRemote(_ context.Context, req *proto.HandlerRequest) (*proto.HandlerResponse, error) {
+	return s.handle(methodListRemote, req)
+}
+
+func (s *pluginServer) Add(_ context.Context, req *proto.HandlerRequest) (*proto.HandlerResponse, error) {
+	return s.handle(methodAdd, req)
+}
+
+func (s *pluginServer) Update(_ context.Context, req *proto.HandlerRequest) (*proto.HandlerResponse, error) {
+	return s.handle(methodUpdate, req)
+}
+
+func (s *pluginServer) Delete(_ context.Context, req *proto.HandlerRequest) (*proto.HandlerResponse, error) {
+	return s.handle(methodDelete, req)
+}
+
+func (s *pluginServer) ValidateResource(_ context.Context, req *proto.HandlerRequest) (*proto.HandlerResponse, error) {
+	return s.handle(methodValidateResource, req)
+}
+
+func (s *pluginServer) Sort(_ context.Context, req *proto.HandlerRequest) (*proto.HandlerResponse, error) {
+	return s.handle(methodSort, req)
+}
+
+func (s *pluginServer) Detect(_ context.Context, req *proto.HandlerRequest) (*proto.HandlerResponse, error) {
+	return s.handle(methodDetect, req)
+}
+
+func (s *pluginServer) getProvider() (grizzly.Provider, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.provider == nil {
+		return nil, status.Error(codes.FailedPrecondition, "the plugin isn't configured")
+	}
+	return s.provider, nil
+}
+
+func (s *pluginServer) getHandler(kind string) (grizzly.Handler, error) {
+	if _, err := s.getProvider(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	handler, ok := s.handlers[kind]
+	if !ok {
+		return nil, toStatus(fmt.Errorf("couldn't find a handler for %s: %w", kind, grizzly.ErrHandlerNotFound))
+	}
+	return handler, nil
+}
+
+// handle calls a method of the handler of the kind of a request
+func (s *pluginServer) handle(method string, req *proto.HandlerRequest) (*proto.HandlerResponse, error) {
+	handler, err := s.getHandler(req.Kind)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.call(handler, method, req)
+	if err != nil {
+		if _, isStatus := status.FromError(err); isStatus {
+			return nil, err
+		}
+		return nil, toStatus(err)
+	}
+	return resp, nil
+}
+
+func (s *pluginServer) call(handler grizzly.Handler, method string, req *proto.HandlerRequest) (*proto.HandlerResponse, error) {
+	body, err := fromMessage(req.Resource)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "decoding resource: %s", err)
+	}
+	resource := grizzly.Resource{Body: body}
+	resp := &proto.HandlerResponse{}
+
+	switch method {
+	case methodResourceFilePath:
+		resp.Path = handler.ResourceFilePath(resource, req.Filetype)
+	case methodUnprepare:
+		resp.Resource, err = toMessage(bodyOf(handler.Unprepare(resource)))
+	case methodPrepare:
+		var existing *grizzly.Resource
+		if req.Existing != nil {
+			existingBody, err := fromMessage(req.Existing)
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "decoding existing resource: %s", err)
+			}
+			existing = &grizzly.Resource{Body: existingBody}
+		}
+		resp.Resource, err = toMessage(bodyOf(handler.Prepare(existing, resource)))
+	case methodGetUID:
+		resp.Uid, err = handler.GetUID(resource)
+	case methodGetSpecUID:
+		resp.Uid, err = handler.GetSpecUID(resource)
+	case methodGetByUID:
+		var remote *grizzly.Resource
+		if remote, err = handler.GetByUID(req.Uid); err == nil {
+			resp.Resource, err = toMessage(bodyOf(remote))
+		}
+	case methodGetRemote:
+		var remote *grizzly.Resource
+		if remote, err = handler.GetRemote(resource); err == nil {
+			resp.Resource, err = toMessage(bodyOf(remote))
+		}
+	case methodListRemote:
+		resp.Uids, err = handler.ListRemote()
+	case methodAdd:
+		err = handler.Add(resource)
+	case methodUpdate:
+		var existing map[string]any
+		if existing, err = fromMessage(req.Existing); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "decoding existing resource: %s", err)
+		}
+		err = handler.Update(grizzly.Resource{Body: existing}, resource)
+	case methodDelete:
+		deleteHandler, ok := handler.(grizzly.DeleteHandler)
+		if !ok {
+			return nil, fmt.Errorf("%s resources can't be deleted: %w", req.Kind, grizzly.ErrNotImplemented)
+		}
+		err = deleteHandler.Delete(resource)
+	case methodValidateResource:
+		err = handler.Validate(resource)
+	case methodSort:
+		var bodies []map[string]any
+		for _, message := range req.Resources {
+			body, err := fromMessage(message)
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "decoding resource: %s", err)
+			}
+			bodies = append(bodies, body)
+		}
+		resp.Order = sortOrder(handler, bodies)
+	case methodDetect:
+		spec := map[string]any{}
+		if len(req.Spec) > 0 {
+			if err := json.Unmarshal(req.Spec, &spec); err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "decoding spec: %s", err)
+			}
+		}
+		resp.Detected = handler.Detect(spec)
+	default:
+		return nil, status.Errorf(codes.Unimplemented, "unknown method %s", method)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// sortOrder sorts resources with a handler, and returns the position of each
+// sorted resource in the given list, so that grr sorts its own resources,
+// which know the files they come from
+func sortOrder(handler grizzly.Handler, bodies []map[string]any) []int32 {
+	positions := map[grizzly.ResourceRef]int{}
+	resources := make([]grizzly.Resource, 0, len(bodies))
+	for i, body := range bodies {
+		resource := grizzly.Resource{Body: body}
+		positions[resource.Ref()] = i
+		resources = append(resources, resource)
+	}
+
+	order := make([]int32, 0, len(bodies))
+	for _, resource := range handler.Sort(grizzly.NewResources(resources...)).AsList() {
+		if i, ok := positions[resource.Ref()]; ok {
+			order = append(order, int32(i))
+		}
+	}
+	return order
+}
+
+func bodyOf(resource *grizzly.Resource) map[string]any {
+	if resource == nil {
+		return nil
+	}
+	return resource.Body
+}
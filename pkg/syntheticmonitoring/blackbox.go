@@ -0,0 +1,334 @@
+package syntheticmonitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/synthetic-monitoring-agent/pkg/pb/synthetic_monitoring"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// Defaults of Prometheus and of the blackbox_exporter used for probes that
+// don't set them
+const (
+	blackboxDefaultInterval = time.Minute
+	blackboxDefaultTimeout  = 10 * time.Second
+	blackboxMetricsPath     = "/probe"
+)
+
+// BlackboxOptions configures the conversion of blackbox_exporter probes to checks
+type BlackboxOptions struct {
+	// Modules is the configuration of the blackbox_exporter, defining the modules
+	// probes use. Without it, the prober of modules is inferred from their name
+	Modules []byte
+	// Probes are the names of the Synthetic Monitoring probes the checks run from
+	Probes []string
+}
+
+type prometheusConfig struct {
+	ScrapeConfigs []scrapeConfig `yaml:"scrape_configs"`
+}
+
+type scrapeConfig struct {
+	JobName        string              `yaml:"job_name"`
+	ScrapeInterval string              `yaml:"scrape_interval"`
+	ScrapeTimeout  string              `yaml:"scrape_timeout"`
+	MetricsPath    string              `yaml:"metrics_path"`
+	Params         map[string][]string `yaml:"params"`
+	StaticConfigs  []staticConfig      `yaml:"static_configs"`
+}
+
+type staticConfig struct {
+	Targets []string          `yaml:"targets"`
+	Labels  map[string]string `yaml:"labels"`
+}
+
+type blackboxConfig struct {
+	Modules map[string]blackboxModule `yaml:"modules"`
+}
+
+type blackboxModule struct {
+	Prober  string `yaml:"prober"`
+	Timeout string `yaml:"timeout"`
+	HTTP    struct {
+		Method              string            `yaml:"method"`
+		Headers             map[string]string `yaml:"headers"`
+		Body                string            `yaml:"body"`
+		ValidStatusCodes    []int32           `yaml:"valid_status_codes"`
+		ValidHTTPVersions   []string          `yaml:"valid_http_versions"`
+		FollowRedirects     *bool             `yaml:"follow_redirects"`
+		FailIfSSL           bool              `yaml:"fail_if_ssl"`
+		FailIfNotSSL        bool              `yaml:"fail_if_not_ssl"`
+		PreferredIPProtocol string            `yaml:"preferred_ip_protocol"`
+	} `yaml:"http"`
+	TCP struct {
+		TLS                 bool   `yaml:"tls"`
+		PreferredIPProtocol string `yaml:"preferred_ip_protocol"`
+	} `yaml:"tcp"`
+	ICMP struct {
+		PreferredIPProtocol string `yaml:"preferred_ip_protocol"`
+	} `yaml:"icmp"`
+	DNS struct {
+		QueryName           string `yaml:"query_name"`
+		QueryType           string `yaml:"query_type"`
+		TransportProtocol   string `yaml:"transport_protocol"`
+		PreferredIPProtocol string `yaml:"preferred_ip_protocol"`
+	} `yaml:"dns"`
+}
+
+var invalidCheckNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// ConvertBlackbox converts the blackbox_exporter probes of a Prometheus
+// configuration to Synthetic Monitoring checks. Scrape configs are probes
+// when their metrics path is /probe; each of their static targets becomes a
+// check, named after the job and the target.
+func ConvertBlackbox(apiVersion string, config []byte, opts BlackboxOptions) (grizzly.Resources, error) {
+	if len(opts.Probes) == 0 {
+		return grizzly.Resources{}, fmt.Errorf("at least one probe is required to run the checks from")
+	}
+
+	var promConfig prometheusConfig
+	if err := yaml.Unmarshal(config, &promConfig); err != nil {
+		return grizzly.Resources{}, fmt.Errorf("reading Prometheus configuration: %w", err)
+	}
+
+	var modules blackboxConfig
+	if opts.Modules != nil {
+		if err := yaml.Unmarshal(opts.Modules, &modules); err != nil {
+			return grizzly.Resources{}, fmt.Errorf("reading blackbox_exporter configuration: %w", err)
+		}
+	}
+
+	resources := grizzly.NewResources()
+	for _, scrape := range promConfig.ScrapeConfigs {
+		if scrape.MetricsPath != blackboxMetricsPath {
+			continue
+		}
+
+		moduleName := ""
+		if names := scrape.Params["module"]; len(names) > 0 {
+			moduleName = names[0]
+		}
+		module, err := findBlackboxModule(modules, moduleName)
+		if err != nil {
+			return grizzly.Resources{}, fmt.Errorf("job %s: %w", scrape.JobName, err)
+		}
+
+		for _, static := range scrape.StaticConfigs {
+			for _, target := range static.Targets {
+				check, checkType, err := blackboxCheck(scrape, module, target, static.Labels)
+				if err != nil {
+					return grizzly.Resources{}, fmt.Errorf("job %s, target %s: %w", scrape.JobName, target, err)
+				}
+
+				resource, err := checkResource(apiVersion, check, checkType, opts.Probes)
+				if err != nil {
+					return grizzly.Resources{}, err
+				}
+				if _, exists := resources.Find(resource.Ref()); exists {
+					log.Warnf("%s: skipped, a check with the same name was already converted", resource.Ref())
+					continue
+				}
+				resources.Add(resource)
+			}
+		}
+	}
+
+	return resources, nil
+}
+
+// findBlackboxModule returns the module used by probes. When the modules
+// aren't known, the prober is inferred from the name of the module, as in
+// the example configuration of the blackbox_exporter (http_2xx, tcp_connect,
+// icmp, dns_udp...)
+func findBlackboxModule(modules blackboxConfig, name string) (blackboxModule, error) {
+	if name == "" {
+		return blackboxModule{}, fmt.Errorf("no module set in params")
+	}
+	if modules.Modules != nil {
+		module, ok := modules.Modules[name]
+		if !ok {
+			return blackboxModule{}, fmt.Errorf("module %s not found in the blackbox_exporter configuration", name)
+		}
+		return module, nil
+	}
+
+	module := blackboxModule{}
+	for _, prober := range []string{"http", "tcp", "icmp", "dns"} {
+		if strings.HasPrefix(name, prober) {
+			module.Prober = prober
+			return module, nil
+		}
+	}
+	return blackboxModule{}, fmt.Errorf("can't infer the prober of module %s from its name, use the blackbox_exporter configuration", name)
+}
+
+func blackboxCheck(scrape scrapeConfig, module blackboxModule, target string, labels map[string]string) (synthetic_monitoring.Check, string, error) {
+	check := synthetic_monitoring.Check{
+		Job:              scrape.JobName,
+		Target:           target,
+		Enabled:          true,
+		BasicMetricsOnly: true,
+		Labels:           []synthetic_monitoring.Label{},
+	}
+
+	interval, err := blackboxDuration(scrape.ScrapeInterval, blackboxDefaultInterval)
+	if err != nil {
+		return check, "", err
+	}
+	timeout, err := blackboxDuration(module.Timeout, blackboxDefaultTimeout)
+	if err != nil {
+		return check, "", err
+	}
+	if scrapeTimeout, err := blackboxDuration(scrape.ScrapeTimeout, timeout); err != nil {
+		return check, "", err
+	} else if scrapeTimeout < timeout {
+		timeout = scrapeTimeout
+	}
+	check.Frequency = interval.Milliseconds()
+	check.Timeout = timeout.Milliseconds()
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		check.Labels = append(check.Labels, synthetic_monitoring.Label{Name: name, Value: labels[name]})
+	}
+
+	var checkType string
+	switch module.Prober {
+	case "http":
+		checkType = "http"
+		settings := &synthetic_monitoring.HttpSettings{
+			IpVersion:         blackboxIPVersion(module.HTTP.PreferredIPProtocol),
+			Body:              module.HTTP.Body,
+			NoFollowRedirects: module.HTTP.FollowRedirects != nil && !*module.HTTP.FollowRedirects,
+			FailIfSSL:         module.HTTP.FailIfSSL,
+			FailIfNotSSL:      module.HTTP.FailIfNotSSL,
+			ValidStatusCodes:  module.HTTP.ValidStatusCodes,
+			ValidHTTPVersions: module.HTTP.ValidHTTPVersions,
+		}
+		if module.HTTP.Method != "" {
+			method, ok := synthetic_monitoring.HttpMethod_value[strings.ToUpper(module.HTTP.Method)]
+			if !ok {
+				return check, "", fmt.Errorf("unsupported HTTP method %s", module.HTTP.Method)
+			}
+			settings.Method = synthetic_monitoring.HttpMethod(method)
+		}
+		headers := make([]string, 0, len(module.HTTP.Headers))
+		for name, value := range module.HTTP.Headers {
+			headers = append(headers, fmt.Sprintf("%s: %s", name, value))
+		}
+		sort.Strings(headers)
+		settings.Headers = headers
+		check.Settings.Http = settings
+	case "tcp":
+		checkType = "tcp"
+		check.Settings.Tcp = &synthetic_monitoring.TcpSettings{
+			IpVersion: blackboxIPVersion(module.TCP.PreferredIPProtocol),
+			Tls:       module.TCP.TLS,
+		}
+	case "icmp":
+		checkType = "ping"
+		check.Settings.Ping = &synthetic_monitoring.PingSettings{
+			IpVersion: blackboxIPVersion(module.ICMP.PreferredIPProtocol),
+		}
+	case "dns":
+		// the target of the blackbox_exporter is the DNS server, while the
+		// target of checks is the name to resolve
+		checkType = "dns"
+		if module.DNS.QueryName == "" {
+			return check, "", fmt.Errorf("the module has no query_name to resolve")
+		}
+		settings := &synthetic_monitoring.DnsSettings{
+			IpVersion:  blackboxIPVersion(module.DNS.PreferredIPProtocol),
+			Server:     target,
+			Port:       53,
+			RecordType: synthetic_monitoring.DnsRecordType_A,
+			Protocol:   synthetic_monitoring.DnsProtocol_UDP,
+		}
+		if host, port, err := net.SplitHostPort(target); err == nil {
+			portNumber, err := strconv.ParseInt(port, 10, 32)
+			if err != nil {
+				return check, "", fmt.Errorf("invalid port %s", port)
+			}
+			settings.Server = host
+			settings.Port = int32(portNumber)
+		}
+		if module.DNS.QueryType != "" {
+			recordType, ok := synthetic_monitoring.DnsRecordType_value[strings.ToUpper(module.DNS.QueryType)]
+			if !ok {
+				return check, "", fmt.Errorf("unsupported DNS record type %s", module.DNS.QueryType)
+			}
+			settings.RecordType = synthetic_monitoring.DnsRecordType(recordType)
+		}
+		if strings.EqualFold(module.DNS.TransportProtocol, "tcp") {
+			settings.Protocol = synthetic_monitoring.DnsProtocol_TCP
+		}
+		check.Settings.Dns = settings
+		check.Target = module.DNS.QueryName
+	default:
+		return check, "", fmt.Errorf("unsupported prober %q", module.Prober)
+	}
+
+	return check, checkType, nil
+}
+
+func checkResource(apiVersion string, check synthetic_monitoring.Check, checkType string, probes []string) (grizzly.Resource, error) {
+	name := invalidCheckNameChars.ReplaceAllString(fmt.Sprintf("%s-%s", check.Job, check.Target), "-")
+	name = strings.Trim(name, "-")
+	check.Job = name
+
+	data, err := json.Marshal(check)
+	if err != nil {
+		return grizzly.Resource{}, err
+	}
+	var spec map[string]any
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return grizzly.Resource{}, err
+	}
+	for _, key := range []string{"id", "tenantId", "created", "modified"} {
+		delete(spec, key)
+	}
+	spec["probes"] = probes
+
+	resource, err := grizzly.NewResource(apiVersion, SyntheticMonitoringCheckKind, name, spec)
+	if err != nil {
+		return grizzly.Resource{}, err
+	}
+	resource.SetMetadata("type", checkType)
+	return resource, nil
+}
+
+func blackboxDuration(value string, defaultValue time.Duration) (time.Duration, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %s: %w", value, err)
+	}
+	return duration, nil
+}
+
+func blackboxIPVersion(protocol string) synthetic_monitoring.IpVersion {
+	switch protocol {
+	case "ip4":
+		return synthetic_monitoring.IpVersion_V4
+	case "ip6":
+		return synthetic_monitoring.IpVersion_V6
+	default:
+		// the blackbox_exporter prefers ip6, but falls back to ip4
+		return synthetic_monitoring.IpVersion_Any
+	}
+}
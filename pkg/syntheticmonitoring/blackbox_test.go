@@ -0,0 +1,89 @@
+package syntheticmonitoring
+
+import (
+	"os"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertBlackbox(t *testing.T) {
+	promConfig, err := os.ReadFile("testdata/prometheus.yml")
+	require.NoError(t, err)
+	modules, err := os.ReadFile("testdata/blackbox.yml")
+	require.NoError(t, err)
+
+	t.Run("probes are converted using the blackbox_exporter modules", func(t *testing.T) {
+		resources, err := ConvertBlackbox("grizzly.grafana.com/v1alpha1", promConfig, BlackboxOptions{Modules: modules, Probes: []string{"Paris"}})
+		require.NoError(t, err)
+		require.Equal(t, 4, resources.Len())
+
+		check, ok := resources.Find(grizzly.NewResourceRef(SyntheticMonitoringCheckKind, "website-https-grafana-com"))
+		require.True(t, ok)
+		require.Equal(t, "http", check.GetMetadata("type"))
+		require.Equal(t, "website-https-grafana-com", check.GetSpecValue("job"))
+		require.Equal(t, "https://grafana.com", check.GetSpecValue("target"))
+		require.EqualValues(t, 30000, check.GetSpecValue("frequency"))
+		require.EqualValues(t, 5000, check.GetSpecValue("timeout"))
+		require.Equal(t, []string{"Paris"}, check.GetSpecValue("probes"))
+		require.Equal(t, []any{map[string]any{"name": "team", "value": "web"}}, check.GetSpecValue("labels"))
+		http := check.GetSpecValue("settings").(map[string]any)["http"].(map[string]any)
+		require.Equal(t, "V4", http["ipVersion"])
+		require.Equal(t, "GET", http["method"])
+		require.Equal(t, true, http["noFollowRedirects"])
+		require.Equal(t, []any{float64(200), float64(204)}, http["validStatusCodes"])
+		require.Equal(t, []any{"Accept: text/html"}, http["headers"])
+
+		check, ok = resources.Find(grizzly.NewResourceRef(SyntheticMonitoringCheckKind, "resolver-grafana-com"))
+		require.True(t, ok)
+		require.Equal(t, "dns", check.GetMetadata("type"))
+		require.Equal(t, "grafana.com", check.GetSpecValue("target"))
+		dns := check.GetSpecValue("settings").(map[string]any)["dns"].(map[string]any)
+		require.Equal(t, "8.8.8.8", dns["server"])
+		require.EqualValues(t, 5353, dns["port"])
+		require.Equal(t, "AAAA", dns["recordType"])
+
+		check, ok = resources.Find(grizzly.NewResourceRef(SyntheticMonitoringCheckKind, "gateway-10-0-0-1"))
+		require.True(t, ok)
+		require.Equal(t, "ping", check.GetMetadata("type"))
+		require.EqualValues(t, 60000, check.GetSpecValue("frequency"))
+	})
+
+	t.Run("probers are inferred from module names", func(t *testing.T) {
+		config := []byte(`
+scrape_configs:
+  - job_name: api
+    metrics_path: /probe
+    params:
+      module: [tcp_connect]
+    static_configs:
+      - targets: [api:443]
+`)
+		resources, err := ConvertBlackbox("grizzly.grafana.com/v1alpha1", config, BlackboxOptions{Probes: []string{"Paris"}})
+		require.NoError(t, err)
+		require.Equal(t, 1, resources.Len())
+		check := resources.First()
+		require.Equal(t, "tcp", check.GetMetadata("type"))
+		require.Equal(t, "api-api-443", check.Name())
+	})
+
+	t.Run("modules missing from the blackbox_exporter configuration are reported", func(t *testing.T) {
+		config := []byte(`
+scrape_configs:
+  - job_name: api
+    metrics_path: /probe
+    params:
+      module: [unknown]
+    static_configs:
+      - targets: [api:443]
+`)
+		_, err := ConvertBlackbox("grizzly.grafana.com/v1alpha1", config, BlackboxOptions{Modules: modules, Probes: []string{"Paris"}})
+		require.ErrorContains(t, err, "module unknown not found")
+	})
+
+	t.Run("probes are required", func(t *testing.T) {
+		_, err := ConvertBlackbox("grizzly.grafana.com/v1alpha1", promConfig, BlackboxOptions{Modules: modules})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,267 @@
+package syntheticmonitoring
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/synthetic-monitoring-agent/pkg/pb/synthetic_monitoring"
+	smapi "github.com/grafana/synthetic-monitoring-api-go-client"
+)
+
+const SyntheticMonitoringCheckAlertsKind = "SyntheticMonitoringCheckAlerts"
+
+// CheckAlert is an alert of a check, firing when a metric of the check, such
+// as its failed executions, crosses a threshold over a period
+type CheckAlert struct {
+	Name      string  `json:"name"`
+	Threshold float64 `json:"threshold"`
+	Period    string  `json:"period,omitempty"`
+}
+
+type checkAlerts struct {
+	Alerts []CheckAlert `json:"alerts"`
+}
+
+var _ grizzly.Handler = &CheckAlertsHandler{}
+var _ grizzly.ConcurrencyLimiter = &CheckAlertsHandler{}
+
+// CheckAlertsHandler is a Grizzly Handler for the alerts of Synthetic
+// Monitoring checks. Alerts are named and typed after the check they belong
+// to.
+type CheckAlertsHandler struct {
+	grizzly.BaseHandler
+	checks *SyntheticMonitoringHandler
+}
+
+// NewCheckAlertsHandler returns a Grizzly Handler for the alerts of Synthetic
+// Monitoring checks
+func NewCheckAlertsHandler(provider grizzly.Provider) *CheckAlertsHandler {
+	return &CheckAlertsHandler{
+		BaseHandler: grizzly.NewBaseHandler(provider, SyntheticMonitoringCheckAlertsKind, false).WithListCache(),
+		checks:      NewSyntheticMonitoringHandler(provider),
+	}
+}
+
+// MaxConcurrency implements grizzly.ConcurrencyLimiter, as the API is rate
+// limited
+func (h *CheckAlertsHandler) MaxConcurrency() int {
+	return 1
+}
+
+const (
+	syntheticMonitoringCheckAlertsPattern = "synthetic-monitoring/alerts-%s.%s"
+)
+
+// ResourceFilePath returns the location on disk where a resource should be updated
+func (h *CheckAlertsHandler) ResourceFilePath(resource grizzly.Resource, filetype string) string {
+	filename := strings.ReplaceAll(resource.Name(), string(os.PathSeparator), "-")
+	return fmt.Sprintf(syntheticMonitoringCheckAlertsPattern, filename, filetype)
+}
+
+// Unprepare removes unnecessary elements from a remote resource ready for presentation/comparison
+func (h *CheckAlertsHandler) Unprepare(resource grizzly.Resource) *grizzly.Resource {
+	return &resource
+}
+
+// Prepare gets a resource ready for dispatch to the remote endpoint
+func (h *CheckAlertsHandler) Prepare(existing *grizzly.Resource, resource grizzly.Resource) *grizzly.Resource {
+	return &resource
+}
+
+// Validate returns the uid of resource
+func (h *CheckAlertsHandler) Validate(resource grizzly.Resource) error {
+	if !resource.HasMetadata("type") {
+		return fmt.Errorf("%s %s lacks a type metadata element", h.Kind(), resource.Name())
+	}
+	alerts, err := specToAlerts(resource)
+	if err != nil {
+		return err
+	}
+	for _, alert := range alerts {
+		if alert.Name == "" {
+			return fmt.Errorf("alerts of %s should be named", resource.Name())
+		}
+	}
+	return nil
+}
+
+// GetUID returns the UID for a resource
+func (h *CheckAlertsHandler) GetUID(resource grizzly.Resource) (string, error) {
+	return h.checks.GetUID(resource)
+}
+
+func (h *CheckAlertsHandler) GetSpecUID(resource grizzly.Resource) (string, error) {
+	return "", fmt.Errorf("GetSpecUID not implemented for Synthetic Monitoring")
+}
+
+// GetByUID retrieves JSON for a resource from an endpoint, by UID. Checks
+// without alerts aren't found.
+func (h *CheckAlertsHandler) GetByUID(uid string) (*grizzly.Resource, error) {
+	check, err := h.getCheck(uid)
+	if err != nil {
+		return nil, err
+	}
+	alerts, err := h.getAlerts(check.Id)
+	if err != nil {
+		return nil, err
+	}
+	if len(alerts) == 0 {
+		return nil, grizzly.ErrNotFound
+	}
+
+	resource, err := grizzly.NewResource(h.APIVersion(), h.Kind(), check.Job, map[string]any{
+		"alerts": alertsToSpec(alerts),
+	})
+	if err != nil {
+		return nil, err
+	}
+	resource.SetMetadata("type", h.checks.getType(check))
+	return &resource, nil
+}
+
+// GetRemote retrieves the alerts of a check as a Resource
+func (h *CheckAlertsHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
+	uid, err := h.GetUID(resource)
+	if err != nil {
+		return nil, err
+	}
+	return h.GetByUID(uid)
+}
+
+// ListRemote retrieves as list of UIDs of the checks with alerts
+func (h *CheckAlertsHandler) ListRemote() ([]string, error) {
+	checks, err := h.checks.listChecks()
+	if err != nil {
+		return nil, err
+	}
+	var uids []string
+	for _, check := range checks {
+		alerts, err := h.getAlerts(check.Id)
+		if err != nil {
+			return nil, err
+		}
+		if len(alerts) > 0 {
+			uids = append(uids, h.checks.getUID(check))
+		}
+	}
+	return uids, nil
+}
+
+// Add sets the alerts of a check
+func (h *CheckAlertsHandler) Add(resource grizzly.Resource) error {
+	alerts, err := specToAlerts(resource)
+	if err != nil {
+		return err
+	}
+	return h.putAlerts(resource, alerts)
+}
+
+// Update replaces the alerts of a check
+func (h *CheckAlertsHandler) Update(existing, resource grizzly.Resource) error {
+	return h.Add(resource)
+}
+
+// Delete removes the alerts of a check, leaving the check in place
+func (h *CheckAlertsHandler) Delete(resource grizzly.Resource) error {
+	return h.putAlerts(resource, []CheckAlert{})
+}
+
+// getCheck finds the check of an UID. The check may have been added earlier
+// in the run, so the checks are fetched again before giving up.
+func (h *CheckAlertsHandler) getCheck(uid string) (synthetic_monitoring.Check, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		if attempt > 0 {
+			h.checks.InvalidateListCache()
+		}
+		checks, err := h.checks.listChecks()
+		if err != nil {
+			return synthetic_monitoring.Check{}, err
+		}
+		for _, check := range checks {
+			if h.checks.getUID(check) == uid {
+				return check, nil
+			}
+		}
+	}
+	return synthetic_monitoring.Check{}, grizzly.ErrNotFound
+}
+
+// getAlerts retrieves the alerts of a check, cached for the run
+func (h *CheckAlertsHandler) getAlerts(checkID int64) ([]CheckAlert, error) {
+	return grizzly.CachedList(&h.BaseHandler, fmt.Sprintf("alerts-%d", checkID), func() ([]CheckAlert, error) {
+		smClient, err := h.Provider.(ClientProvider).Client()
+		if err != nil {
+			return nil, err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		resp, err := smClient.Get(ctx, fmt.Sprintf("/check/%d/alerts", checkID), true, nil)
+		if err != nil {
+			return nil, fmt.Errorf("sending check alerts get request: %w", err)
+		}
+		var result checkAlerts
+		if err := smapi.ValidateResponse("check alerts get request", resp, &result); err != nil {
+			return nil, err
+		}
+		return result.Alerts, nil
+	})
+}
+
+func (h *CheckAlertsHandler) putAlerts(resource grizzly.Resource, alerts []CheckAlert) error {
+	uid, err := h.GetUID(resource)
+	if err != nil {
+		return err
+	}
+	check, err := h.getCheck(uid)
+	if errors.Is(err, grizzly.ErrNotFound) {
+		return fmt.Errorf("no %s check named %s to set alerts of", resource.GetMetadata("type"), resource.Name())
+	}
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	path := fmt.Sprintf("/check/%d/alerts", check.Id)
+	var updated checkAlerts
+	if err := h.Provider.(*Provider).putJSON(ctx, "check alerts update request", path, checkAlerts{Alerts: alerts}, &updated); err != nil {
+		return err
+	}
+	h.InvalidateListCache()
+	return nil
+}
+
+func specToAlerts(resource grizzly.Resource) ([]CheckAlert, error) {
+	var spec checkAlerts
+	data, err := json.Marshal(resource.Spec())
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("input file is invalid: %v", err)
+	}
+	return spec.Alerts, nil
+}
+
+func alertsToSpec(alerts []CheckAlert) []any {
+	spec := make([]any, 0, len(alerts))
+	for _, alert := range alerts {
+		entry := map[string]any{
+			"name":      alert.Name,
+			"threshold": alert.Threshold,
+		}
+		if alert.Period != "" {
+			entry["period"] = alert.Period
+		}
+		spec = append(spec, entry)
+	}
+	return spec
+}
@@ -0,0 +1,73 @@
+package syntheticmonitoring
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/synthetic-monitoring-agent/pkg/pb/synthetic_monitoring"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckAlertsHandler(t *testing.T) {
+	var updated checkAlerts
+	provider := newTestProvider(t, map[string]http.HandlerFunc{
+		"GET /api/v1/check/list": respondJSON(t, []synthetic_monitoring.Check{
+			{Id: 5, Job: "website", Settings: synthetic_monitoring.CheckSettings{Http: &synthetic_monitoring.HttpSettings{}}},
+			{Id: 6, Job: "dns", Settings: synthetic_monitoring.CheckSettings{Dns: &synthetic_monitoring.DnsSettings{}}},
+		}),
+		"GET /api/v1/check/5/alerts": respondJSON(t, checkAlerts{Alerts: []CheckAlert{
+			{Name: "ProbeFailedExecutionsTooHigh", Threshold: 2, Period: "10m"},
+		}}),
+		"GET /api/v1/check/6/alerts": respondJSON(t, checkAlerts{Alerts: []CheckAlert{}}),
+		"PUT /api/v1/check/5/alerts": func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&updated))
+			require.NoError(t, json.NewEncoder(w).Encode(updated))
+		},
+	})
+	handler := NewCheckAlertsHandler(provider)
+
+	t.Run("only checks with alerts are listed", func(t *testing.T) {
+		uids, err := handler.ListRemote()
+		require.NoError(t, err)
+		require.Equal(t, []string{"http.website"}, uids)
+
+		_, err = handler.GetByUID("dns.dns")
+		require.ErrorIs(t, err, grizzly.ErrNotFound)
+	})
+
+	t.Run("alerts are named and typed after their check", func(t *testing.T) {
+		resource, err := handler.GetByUID("http.website")
+		require.NoError(t, err)
+		require.Equal(t, "website", resource.Name())
+		require.Equal(t, "http", resource.GetMetadata("type"))
+		require.Equal(t, []any{
+			map[string]any{"name": "ProbeFailedExecutionsTooHigh", "threshold": float64(2), "period": "10m"},
+		}, resource.GetSpecValue("alerts"))
+	})
+
+	t.Run("alerts are set on their check", func(t *testing.T) {
+		resource, err := grizzly.NewResource(handler.APIVersion(), handler.Kind(), "website", map[string]any{
+			"alerts": []any{map[string]any{"name": "TLSTargetCertificateCloseToExpiring", "threshold": 7}},
+		})
+		require.NoError(t, err)
+		resource.SetMetadata("type", "http")
+
+		require.NoError(t, handler.Validate(resource))
+		require.NoError(t, handler.Add(resource))
+		require.Equal(t, []CheckAlert{{Name: "TLSTargetCertificateCloseToExpiring", Threshold: 7}}, updated.Alerts)
+
+		require.NoError(t, handler.Delete(resource))
+		require.Empty(t, updated.Alerts)
+	})
+
+	t.Run("alerts of unknown checks are rejected", func(t *testing.T) {
+		resource, err := grizzly.NewResource(handler.APIVersion(), handler.Kind(), "website", map[string]any{"alerts": []any{}})
+		require.NoError(t, err)
+		resource.SetMetadata("type", "tcp")
+
+		require.ErrorContains(t, handler.Add(resource), "no tcp check named website")
+	})
+}
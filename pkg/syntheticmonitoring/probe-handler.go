@@ -0,0 +1,255 @@
+package syntheticmonitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/synthetic-monitoring-agent/pkg/pb/synthetic_monitoring"
+)
+
+const SyntheticMonitoringProbeKind = "SyntheticMonitoringProbe"
+
+var _ grizzly.Handler = &ProbeHandler{}
+var _ grizzly.ConcurrencyLimiter = &ProbeHandler{}
+
+// ProbeHandler is a Grizzly Handler for the private probes of Grafana
+// Synthetic Monitoring. Public probes are run by Grafana, and can't be managed.
+type ProbeHandler struct {
+	grizzly.BaseHandler
+}
+
+// NewProbeHandler returns a Grizzly Handler for Synthetic Monitoring probes
+func NewProbeHandler(provider grizzly.Provider) *ProbeHandler {
+	return &ProbeHandler{
+		BaseHandler: grizzly.NewBaseHandler(provider, SyntheticMonitoringProbeKind, false).WithListCache(),
+	}
+}
+
+// MaxConcurrency implements grizzly.ConcurrencyLimiter, as the API is rate
+// limited
+func (h *ProbeHandler) MaxConcurrency() int {
+	return 1
+}
+
+const (
+	syntheticMonitoringProbePattern = "synthetic-monitoring/probe-%s.%s"
+)
+
+// ResourceFilePath returns the location on disk where a resource should be updated
+func (h *ProbeHandler) ResourceFilePath(resource grizzly.Resource, filetype string) string {
+	filename := strings.ReplaceAll(resource.Name(), string(os.PathSeparator), "-")
+	return fmt.Sprintf(syntheticMonitoringProbePattern, filename, filetype)
+}
+
+// Unprepare removes unnecessary elements from a remote resource ready for presentation/comparison
+func (h *ProbeHandler) Unprepare(resource grizzly.Resource) *grizzly.Resource {
+	for _, key := range []string{"id", "tenantId", "public", "online", "onlineChange", "version", "commit", "buildstamp", "deprecated", "created", "modified"} {
+		resource.DeleteSpecKey(key)
+	}
+	return &resource
+}
+
+// Prepare gets a resource ready for dispatch to the remote endpoint
+func (h *ProbeHandler) Prepare(existing *grizzly.Resource, resource grizzly.Resource) *grizzly.Resource {
+	if _, exists := resource.GetSpecString("name"); !exists {
+		resource.SetSpecString("name", resource.Name())
+	}
+	return &resource
+}
+
+// Validate returns the uid of resource
+func (h *ProbeHandler) Validate(resource grizzly.Resource) error {
+	name, exist := resource.GetSpecString("name")
+	if exist && name != resource.Name() {
+		return fmt.Errorf("name '%s' and metadata name '%s', don't match", name, resource.Name())
+	}
+	return nil
+}
+
+// GetUID returns the UID for a resource
+func (h *ProbeHandler) GetUID(resource grizzly.Resource) (string, error) {
+	return resource.Name(), nil
+}
+
+func (h *ProbeHandler) GetSpecUID(resource grizzly.Resource) (string, error) {
+	name, _ := resource.GetSpecString("name")
+	return name, nil
+}
+
+// GetByUID retrieves JSON for a resource from an endpoint, by UID
+func (h *ProbeHandler) GetByUID(uid string) (*grizzly.Resource, error) {
+	probe, err := h.getPrivateProbe(uid)
+	if err != nil {
+		return nil, err
+	}
+	return h.probeToResource(probe)
+}
+
+// GetRemote retrieves a probe as a Resource
+func (h *ProbeHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
+	return h.GetByUID(resource.Name())
+}
+
+// ListRemote retrieves as list of UIDs of all remote resources
+func (h *ProbeHandler) ListRemote() ([]string, error) {
+	probes, err := h.listPrivateProbes()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(probes))
+	for _, probe := range probes {
+		names = append(names, probe.Name)
+	}
+	return names, nil
+}
+
+// Add registers a new private probe. The token the probe authenticates with
+// is only given once, when the probe is registered, and is sent to the
+// secret sink.
+func (h *ProbeHandler) Add(resource grizzly.Resource) error {
+	probe, err := h.specToProbe(resource)
+	if err != nil {
+		return err
+	}
+	smClient, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, token, err := smClient.AddProbe(ctx, probe)
+	if err != nil {
+		return err
+	}
+	h.InvalidateListCache()
+	return grizzly.StoreSecret(resource, "token", string(token))
+}
+
+// Update pushes an updated probe to the SyntheticMonitoring endpoint
+func (h *ProbeHandler) Update(existing, resource grizzly.Resource) error {
+	remote, err := h.getPrivateProbe(resource.Name())
+	if err != nil {
+		return err
+	}
+	probe, err := h.specToProbe(resource)
+	if err != nil {
+		return err
+	}
+	probe.Id = remote.Id
+	probe.TenantId = remote.TenantId
+
+	smClient, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := smClient.UpdateProbe(ctx, probe); err != nil {
+		return err
+	}
+	h.InvalidateListCache()
+	return nil
+}
+
+// Delete removes a probe from the SyntheticMonitoring endpoint
+func (h *ProbeHandler) Delete(resource grizzly.Resource) error {
+	remote, err := h.getPrivateProbe(resource.Name())
+	if err != nil {
+		return err
+	}
+	smClient, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := smClient.DeleteProbe(ctx, remote.Id); err != nil {
+		return err
+	}
+	h.InvalidateListCache()
+	return nil
+}
+
+// listPrivateProbes retrieves the probes of the tenant, cached for the run
+func (h *ProbeHandler) listPrivateProbes() ([]synthetic_monitoring.Probe, error) {
+	return grizzly.CachedList(&h.BaseHandler, "probes", func() ([]synthetic_monitoring.Probe, error) {
+		probes, err := listProbes(h.Provider)
+		if err != nil {
+			return nil, err
+		}
+		var private []synthetic_monitoring.Probe
+		for _, probe := range probes {
+			if !probe.Public {
+				private = append(private, probe)
+			}
+		}
+		return private, nil
+	})
+}
+
+func (h *ProbeHandler) getPrivateProbe(name string) (synthetic_monitoring.Probe, error) {
+	probes, err := h.listPrivateProbes()
+	if err != nil {
+		return synthetic_monitoring.Probe{}, err
+	}
+	for _, probe := range probes {
+		if probe.Name == name {
+			return probe, nil
+		}
+	}
+	return synthetic_monitoring.Probe{}, grizzly.ErrNotFound
+}
+
+func (h *ProbeHandler) probeToResource(probe synthetic_monitoring.Probe) (*grizzly.Resource, error) {
+	data, err := json.Marshal(probe)
+	if err != nil {
+		return nil, err
+	}
+	var specmap map[string]interface{}
+	if err := json.Unmarshal(data, &specmap); err != nil {
+		return nil, err
+	}
+	resource, err := grizzly.NewResource(h.APIVersion(), h.Kind(), probe.Name, specmap)
+	if err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+func (h *ProbeHandler) specToProbe(resource grizzly.Resource) (synthetic_monitoring.Probe, error) {
+	var probe synthetic_monitoring.Probe
+	data, err := json.Marshal(resource.Spec())
+	if err != nil {
+		return probe, err
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return probe, fmt.Errorf("input file is invalid: %v", err)
+	}
+	probe.Name = resource.Name()
+	return probe, nil
+}
+
+// listProbes retrieves every probe the tenant can run checks from, public
+// and private
+func listProbes(provider grizzly.Provider) ([]synthetic_monitoring.Probe, error) {
+	smClient, err := provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	probes, err := smClient.ListProbes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize probes list: %v", err)
+	}
+	return probes, nil
+}
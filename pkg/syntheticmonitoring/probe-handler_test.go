@@ -0,0 +1,111 @@
+package syntheticmonitoring
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/synthetic-monitoring-agent/pkg/pb/synthetic_monitoring"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestProvider returns a provider talking to a fake Synthetic Monitoring
+// API serving the given routes
+func newTestProvider(t *testing.T, routes map[string]http.HandlerFunc) *Provider {
+	t.Helper()
+	mux := http.NewServeMux()
+	for pattern, handler := range routes {
+		mux.HandleFunc(pattern, handler)
+	}
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return NewProvider(&config.SyntheticMonitoringConfig{URL: server.URL, AccessToken: "token"})
+}
+
+func respondJSON(t *testing.T, body any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewEncoder(w).Encode(body))
+	}
+}
+
+var testProbes = []synthetic_monitoring.Probe{
+	{Id: 1, TenantId: 10, Name: "Paris", Public: true, Online: true},
+	{Id: 2, TenantId: 10, Name: "office", Region: "EMEA", Latitude: 48.8, Longitude: 2.3, Online: false, Version: "v0.23.1"},
+}
+
+func TestProbeHandler(t *testing.T) {
+	provider := newTestProvider(t, map[string]http.HandlerFunc{
+		"GET /api/v1/probe/list": respondJSON(t, testProbes),
+	})
+	handler := NewProbeHandler(provider)
+
+	t.Run("only private probes are listed", func(t *testing.T) {
+		names, err := handler.ListRemote()
+		require.NoError(t, err)
+		require.Equal(t, []string{"office"}, names)
+	})
+
+	t.Run("public probes can't be retrieved", func(t *testing.T) {
+		_, err := handler.GetByUID("Paris")
+		require.ErrorIs(t, err, grizzly.ErrNotFound)
+	})
+
+	t.Run("server-side fields of probes are removed", func(t *testing.T) {
+		remote, err := handler.GetByUID("office")
+		require.NoError(t, err)
+
+		spec := handler.Unprepare(*remote).Spec()
+		require.Equal(t, "office", spec["name"])
+		require.Equal(t, "EMEA", spec["region"])
+		for _, key := range []string{"id", "tenantId", "online", "version", "public"} {
+			require.NotContains(t, spec, key)
+		}
+	})
+}
+
+func TestSyntheticMonitoringHandler_Probes(t *testing.T) {
+	provider := newTestProvider(t, map[string]http.HandlerFunc{
+		"GET /api/v1/probe/list": respondJSON(t, testProbes),
+		"GET /api/v1/check/list": respondJSON(t, []synthetic_monitoring.Check{
+			{
+				Id:     5,
+				Job:    "website",
+				Probes: []int64{1, 2},
+				Settings: synthetic_monitoring.CheckSettings{
+					Http: &synthetic_monitoring.HttpSettings{},
+				},
+			},
+		}),
+	})
+	handler := NewSyntheticMonitoringHandler(provider)
+
+	t.Run("checks keep the names of private and offline probes", func(t *testing.T) {
+		check, err := handler.GetByUID("http.website")
+		require.NoError(t, err)
+		require.Equal(t, []string{"Paris", "office"}, check.GetSpecValue("probes"))
+	})
+
+	t.Run("probe names are converted to IDs", func(t *testing.T) {
+		resource, err := grizzly.NewResource(handler.APIVersion(), handler.Kind(), "website", map[string]any{
+			"probes": []any{"office", "Paris"},
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, handler.convertProbeNameToID(&resource))
+		require.Equal(t, []int64{2, 1}, resource.GetSpecValue("probes"))
+	})
+
+	t.Run("unknown probes are rejected", func(t *testing.T) {
+		resource, err := grizzly.NewResource(handler.APIVersion(), handler.Kind(), "website", map[string]any{
+			"probes": []any{"Atlantis"},
+		})
+		require.NoError(t, err)
+
+		err = handler.convertProbeNameToID(&resource)
+		require.ErrorContains(t, err, `unknown probe "Atlantis"`)
+	})
+}
@@ -1,8 +1,11 @@
 package syntheticmonitoring
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"path/filepath"
 	"time"
 
@@ -14,7 +17,8 @@ import (
 
 // Provider is a grizzly.Provider implementation for Grafana.
 type Provider struct {
-	config *config.SyntheticMonitoringConfig
+	config    *config.SyntheticMonitoringConfig
+	transport http.RoundTripper
 }
 
 type ClientProvider interface {
@@ -28,6 +32,13 @@ func NewProvider(config *config.SyntheticMonitoringConfig) *Provider {
 	}
 }
 
+// WithTransport sends the requests of the provider through a transport of
+// its own, such as one signing requests or recording them
+func (p *Provider) WithTransport(transport http.RoundTripper) *Provider {
+	p.transport = transport
+	return p
+}
+
 func (p *Provider) Validate() error {
 	if p.config.URL == "" {
 		p.config.URL = "https://synthetic-monitoring-api.grafana.net"
@@ -74,6 +85,11 @@ func (p *Provider) Status() grizzly.ProviderStatus {
 	return status
 }
 
+// CredentialsExpiry implements grizzly.CredentialsExpiryProvider, for tokens that are JWTs
+func (p *Provider) CredentialsExpiry() (time.Time, bool) {
+	return grizzly.TokenExpiry(p.config.AccessToken)
+}
+
 func (p *Provider) Name() string {
 	return "Synthetic Monitoring"
 }
@@ -95,21 +111,33 @@ func (p *Provider) APIVersion() string {
 
 // GetHandlers identifies the handlers for the Grafana provider
 func (p *Provider) GetHandlers() []grizzly.Handler {
+	// probes come first, as checks run from them
 	return []grizzly.Handler{
+		NewProbeHandler(p),
 		NewSyntheticMonitoringHandler(p),
+		NewCheckAlertsHandler(p),
 	}
 }
 
 // NewClient creates a new client for synthetic monitoring go client
 func (p *Provider) Client() (*smapi.Client, error) {
-	client, err := httputils.NewHTTPClient()
+	client, err := httputils.NewHTTPClientWithTransport(p.transport)
 	if err != nil {
 		return nil, err
 	}
 
+	token, err := p.accessToken(client)
+	if err != nil {
+		return nil, err
+	}
+	return smapi.NewClient(p.config.URL, token, client), nil
+}
+
+// accessToken returns the access token of the API: the configured one, or
+// else one obtained by installing a client with the stack configuration
+func (p *Provider) accessToken(client *http.Client) (string, error) {
 	if p.config.AccessToken != "" {
-		smClient := smapi.NewClient(p.config.URL, p.config.AccessToken, client)
-		return smClient, nil
+		return p.config.AccessToken, nil
 	}
 
 	smClient := smapi.NewClient(p.config.URL, "", client)
@@ -117,10 +145,39 @@ func (p *Provider) Client() (*smapi.Client, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	_, err = smClient.Install(ctx, p.config.StackID, p.config.MetricsID, p.config.LogsID, p.config.Token)
+	installed, err := smClient.Install(ctx, p.config.StackID, p.config.MetricsID, p.config.LogsID, p.config.Token)
+	if err != nil {
+		return "", fmt.Errorf("failed to install synthetic monitoring client : %v", err)
+	}
+	return installed.AccessToken, nil
+}
+
+// putJSON sends a PUT request to the API, which the client has no method
+// for, and decodes its response into result
+func (p *Provider) putJSON(ctx context.Context, action, path string, body, result any) error {
+	client, err := httputils.NewHTTPClientWithTransport(p.transport)
+	if err != nil {
+		return err
+	}
+	token, err := p.accessToken(client)
 	if err != nil {
-		return nil, fmt.Errorf("failed to install synthetic monitoring client : %v", err)
+		return err
 	}
 
-	return smClient, nil
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, p.config.URL+"/api/v1"+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending %s: %w", action, err)
+	}
+	return smapi.ValidateResponse(action, resp, result)
 }
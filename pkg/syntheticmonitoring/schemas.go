@@ -0,0 +1,31 @@
+package syntheticmonitoring
+
+import (
+	"embed"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+// schemas documents the fields of the kinds of the provider, one file per kind
+//
+//go:embed schemas/*.yaml
+var schemas embed.FS
+
+var _ grizzly.SchemaDescriber = &SyntheticMonitoringHandler{}
+var _ grizzly.SchemaDescriber = &ProbeHandler{}
+var _ grizzly.SchemaDescriber = &CheckAlertsHandler{}
+
+// Schema implements grizzly.SchemaDescriber
+func (h *SyntheticMonitoringHandler) Schema() (*grizzly.Schema, error) {
+	return grizzly.LoadSchema(schemas, h.Kind())
+}
+
+// Schema implements grizzly.SchemaDescriber
+func (h *ProbeHandler) Schema() (*grizzly.Schema, error) {
+	return grizzly.LoadSchema(schemas, h.Kind())
+}
+
+// Schema implements grizzly.SchemaDescriber
+func (h *CheckAlertsHandler) Schema() (*grizzly.Schema, error) {
+	return grizzly.LoadSchema(schemas, h.Kind())
+}
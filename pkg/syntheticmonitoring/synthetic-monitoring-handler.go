@@ -35,6 +35,7 @@ type Probes struct {
 }
 
 var _ grizzly.Handler = &SyntheticMonitoringHandler{}
+var _ grizzly.ConcurrencyLimiter = &SyntheticMonitoringHandler{}
 
 // SyntheticMonitoringHandler is a Grizzly Handler for Grafana Synthetic Monitoring
 type SyntheticMonitoringHandler struct {
@@ -44,10 +45,25 @@ type SyntheticMonitoringHandler struct {
 // NewSyntheticMonitoringHandler returns a Grizzly Handler for Grafana Synthetic Monitoring
 func NewSyntheticMonitoringHandler(provider grizzly.Provider) *SyntheticMonitoringHandler {
 	return &SyntheticMonitoringHandler{
-		BaseHandler: grizzly.NewBaseHandler(provider, SyntheticMonitoringCheckKind, false),
+		BaseHandler: grizzly.NewBaseHandler(provider, SyntheticMonitoringCheckKind, false).WithListCache(),
 	}
 }
 
+// MaxConcurrency implements grizzly.ConcurrencyLimiter. Each request installs
+// a client with the API, which is rate limited, so checks are applied one at a
+// time.
+func (h *SyntheticMonitoringHandler) MaxConcurrency() int {
+	return 1
+}
+
+// ErrorHint implements grizzly.ErrorHinter
+func (h *SyntheticMonitoringHandler) ErrorHint(code grizzly.ErrorCode) string {
+	if code == grizzly.ErrorCodeQuotaExceeded {
+		return "the check quota of the stack was reached: remove unused checks, or ask for the quota to be raised"
+	}
+	return ""
+}
+
 const (
 	syntheticMonitoringPattern = "synthetic-monitoring/check-%s.%s"
 )
@@ -132,18 +148,61 @@ func (h *SyntheticMonitoringHandler) Update(existing, resource grizzly.Resource)
 	return h.updateCheck(resource)
 }
 
-// getProbeList retrieves the list of probe and grouped by id and name
-func (h *SyntheticMonitoringHandler) getProbeList() (Probes, error) {
+// Delete removes a check from the SyntheticMonitoring endpoint
+func (h *SyntheticMonitoringHandler) Delete(resource grizzly.Resource) error {
+	remote, err := h.GetRemote(resource)
+	if err != nil {
+		return err
+	}
+	theCheck, err := h.SpecToCheck(remote)
+	if err != nil {
+		return err
+	}
+
 	smClient, err := h.Provider.(ClientProvider).Client()
 	if err != nil {
-		return Probes{}, err
+		return err
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	probeList, err := smClient.ListProbes(ctx)
+	if err := smClient.DeleteCheck(ctx, theCheck.Id); err != nil {
+		return err
+	}
+	h.InvalidateListCache()
+	return nil
+}
+
+// listChecks retrieves all the checks of the tenant. The API can't retrieve a
+// single check, so the list is cached for the run.
+func (h *SyntheticMonitoringHandler) listChecks() ([]synthetic_monitoring.Check, error) {
+	return grizzly.CachedList(&h.BaseHandler, "checks", func() ([]synthetic_monitoring.Check, error) {
+		smClient, err := h.Provider.(ClientProvider).Client()
+		if err != nil {
+			return nil, err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		checks, err := smClient.ListChecks(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get checks list: %v", err)
+		}
+		return checks, nil
+	})
+}
+
+// getProbeList retrieves the list of probe and grouped by id and name
+func (h *SyntheticMonitoringHandler) getProbeList() (Probes, error) {
+	return grizzly.CachedList(&h.BaseHandler, "probes", h.fetchProbeList)
+}
+
+// fetchProbeList maps every probe checks can run from, including private and
+// offline ones, so that checks using them keep their probe names
+func (h *SyntheticMonitoringHandler) fetchProbeList() (Probes, error) {
+	probeList, err := listProbes(h.Provider)
 	if err != nil {
-		return Probes{}, fmt.Errorf("failed to initialize probes list: %v", err)
+		return Probes{}, err
 	}
 
 	probes := Probes{
@@ -152,27 +211,18 @@ func (h *SyntheticMonitoringHandler) getProbeList() (Probes, error) {
 	}
 
 	for _, probe := range probeList {
-		if probe.Online && probe.Public {
-			probes.ByID[probe.Id] = probe
-			probes.ByName[probe.Name] = probe
-		}
+		probes.ByID[probe.Id] = probe
+		probes.ByName[probe.Name] = probe
 	}
 	return probes, nil
 }
 
 // getRemoteCheck retrieves a check object from SM
 func (h *SyntheticMonitoringHandler) getRemoteCheckList() ([]string, error) {
-	smClient, err := h.Provider.(ClientProvider).Client()
+	checks, err := h.listChecks()
 	if err != nil {
 		return nil, err
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	checks, err := smClient.ListChecks(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get checks list: %v", err)
-	}
 	var checkIDs []string
 
 	for _, check := range checks {
@@ -183,19 +233,11 @@ func (h *SyntheticMonitoringHandler) getRemoteCheckList() ([]string, error) {
 
 // getRemoteCheck retrieves a check object from SM
 func (h *SyntheticMonitoringHandler) getRemoteCheck(uid string) (*grizzly.Resource, error) {
-	smClient, err := h.Provider.(ClientProvider).Client()
+	checkList, err := h.listChecks()
 	if err != nil {
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	checkList, err := smClient.ListChecks(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get checks list: %v", err)
-	}
-
 	probes, err := h.getProbeList()
 	if err != nil {
 		return nil, err
@@ -237,8 +279,18 @@ func (h *SyntheticMonitoringHandler) convertProbeNameToID(resource *grizzly.Reso
 
 	for _, probename := range (*resource).GetSpecValue("probes").([]interface{}) {
 		probeName := probename.(string)
-		id := probes.ByName[probeName].Id
-		probeIDs = append(probeIDs, id)
+		probe, ok := probes.ByName[probeName]
+		if !ok {
+			// the probe may have been added earlier in this run
+			h.InvalidateListCache()
+			if probes, err = h.getProbeList(); err != nil {
+				return err
+			}
+			if probe, ok = probes.ByName[probeName]; !ok {
+				return fmt.Errorf("%s uses unknown probe %q", resource.Ref(), probeName)
+			}
+		}
+		probeIDs = append(probeIDs, probe.Id)
 	}
 	(*resource).SetSpecValue("probes", probeIDs)
 	return nil
@@ -266,6 +318,7 @@ func (h *SyntheticMonitoringHandler) addCheck(resource grizzly.Resource) error {
 	if err != nil {
 		return err
 	}
+	h.InvalidateListCache()
 	return nil
 }
 
@@ -290,6 +343,7 @@ func (h *SyntheticMonitoringHandler) updateCheck(resource grizzly.Resource) erro
 	if err != nil {
 		return err
 	}
+	h.InvalidateListCache()
 
 	return nil
 }
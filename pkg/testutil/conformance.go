@@ -0,0 +1,142 @@
+package testutil
+
+import (
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+// DefaultMissingUID is the UID used to check how handlers report missing
+// remote resources, unless a fixture sets another one
+const DefaultMissingUID = "grizzly-conformance-missing"
+
+// HandlerFixture describes the resources RunHandlerConformance exercises a
+// handler with
+type HandlerFixture struct {
+	// Resource is a valid resource of the kind of the handler, which doesn't
+	// exist remotely yet. It's added, updated, then deleted remotely.
+	Resource grizzly.Resource
+	// Modify returns a copy of Resource with a different spec, to check that
+	// updates are applied. Updates aren't checked when it's nil.
+	Modify func(resource grizzly.Resource) grizzly.Resource
+	// MissingUID is the UID of no remote resource. Defaults to DefaultMissingUID.
+	MissingUID string
+}
+
+// RunHandlerConformance checks that a handler meets the behaviours Grizzly
+// relies on, against the remote endpoint it's configured with (such as a fake
+// server):
+//   - UIDs are read from the metadata of resources, and can be changed when the
+//     handler is a grizzly.UIDHandler;
+//   - missing remote resources are reported with grizzly.ErrNotFound;
+//   - resources read back after being added or updated are unchanged once
+//     unprepared, so that diffing them after an apply shows no changes;
+//   - added resources are listed, and deleted resources are no longer found,
+//     when the handler is a grizzly.DeleteHandler.
+//
+// It's meant to be called from the tests of built-in handlers, and of
+// handlers of other providers.
+func RunHandlerConformance(t *testing.T, handler grizzly.Handler, fixture HandlerFixture) {
+	t.Helper()
+
+	resource := fixture.Resource
+	missingUID := fixture.MissingUID
+	if missingUID == "" {
+		missingUID = DefaultMissingUID
+	}
+
+	t.Run("identity", func(t *testing.T) {
+		require.NotEmpty(t, handler.Kind())
+		require.NotEmpty(t, handler.APIVersion())
+		require.Equal(t, handler.Kind(), resource.Kind(), "the fixture must be of the kind of the handler")
+		require.NoError(t, handler.Validate(resource))
+
+		uid, err := handler.GetUID(resource)
+		require.NoError(t, err)
+		require.Equal(t, resource.Name(), uid, "the UID is the name of the resource")
+	})
+
+	if uidHandler, ok := handler.(grizzly.UIDHandler); ok {
+		t.Run("UIDs", func(t *testing.T) {
+			constraints := uidHandler.UIDConstraints()
+			if constraints.MaxLength > 0 {
+				require.LessOrEqual(t, len(resource.Name()), constraints.MaxLength, "the fixture must meet the UID constraints")
+			}
+			if constraints.Pattern != nil {
+				require.Regexp(t, constraints.Pattern, resource.Name(), "the fixture must meet the UID constraints")
+			}
+
+			renamed := uidHandler.SetUID(resource.DeepCopy(), resource.Name()+"-renamed")
+			require.Equal(t, resource.Name()+"-renamed", renamed.Name())
+			uid, err := handler.GetUID(renamed)
+			require.NoError(t, err)
+			require.Equal(t, resource.Name()+"-renamed", uid)
+			require.NoError(t, handler.Validate(renamed), "resources are valid once their UID is changed")
+		})
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := handler.GetByUID(missingUID)
+		require.ErrorIs(t, err, grizzly.ErrNotFound)
+
+		_, err = handler.GetRemote(resource)
+		require.ErrorIs(t, err, grizzly.ErrNotFound, "the fixture must not exist remotely yet")
+	})
+
+	added := t.Run("add", func(t *testing.T) {
+		require.NoError(t, handler.Add(*handler.Prepare(nil, resource)))
+		requireRoundTrip(t, handler, resource)
+
+		uids, err := handler.ListRemote()
+		require.NoError(t, err)
+		require.Contains(t, uids, resource.Name())
+	})
+	if !added {
+		return
+	}
+
+	if fixture.Modify != nil {
+		t.Run("update", func(t *testing.T) {
+			modified := fixture.Modify(resource.DeepCopy())
+			require.Equal(t, resource.Ref(), modified.Ref(), "modifying the fixture mustn't change its kind or name")
+
+			existing, err := handler.GetRemote(modified)
+			require.NoError(t, err)
+			require.NoError(t, handler.Update(*existing, *handler.Prepare(existing, modified)))
+			requireRoundTrip(t, handler, modified)
+		})
+	}
+
+	if deleteHandler, ok := handler.(grizzly.DeleteHandler); ok {
+		t.Run("delete", func(t *testing.T) {
+			remote, err := handler.GetRemote(resource)
+			require.NoError(t, err)
+			require.NoError(t, deleteHandler.Delete(*remote))
+
+			_, err = handler.GetRemote(resource)
+			require.ErrorIs(t, err, grizzly.ErrNotFound, "deleted resources aren't found")
+		})
+	}
+}
+
+// requireRoundTrip checks that a resource read back from the remote endpoint
+// is presented as it was applied
+func requireRoundTrip(t *testing.T, handler grizzly.Handler, resource grizzly.Resource) {
+	t.Helper()
+
+	expected, err := resource.YAML()
+	require.NoError(t, err)
+
+	remote, err := handler.GetRemote(resource)
+	require.NoError(t, err)
+	actual, err := handler.Unprepare(*remote).YAML()
+	require.NoError(t, err)
+	require.Equal(t, expected, actual, "GetRemote, once unprepared, returns the resource as applied")
+
+	byUID, err := handler.GetByUID(resource.Name())
+	require.NoError(t, err)
+	actual, err = handler.Unprepare(*byUID).YAML()
+	require.NoError(t, err)
+	require.Equal(t, expected, actual, "GetByUID, once unprepared, returns the resource as applied")
+}